@@ -0,0 +1,109 @@
+package engo
+
+// An InputAction is a named action of the game, such as jumping or firing, which can be triggered by any of its
+// bindings. Game code queries the action instead of the keys, so the keys can be changed while the game is running.
+type InputAction struct {
+	// Name is the name of the action (Jump, Fire)
+	Name string
+	// Bindings are the inputs that trigger the action
+	Bindings []ActionBinding
+}
+
+// JustPressed checks whether one of the bindings of the action was pressed in the previous frame.
+func (a InputAction) JustPressed() bool {
+	if Input.ignored || Input.consumed[a.Name] {
+		return false
+	}
+	for _, binding := range a.Bindings {
+		if binding.JustPressed() {
+			return true
+		}
+	}
+	return false
+}
+
+// JustReleased checks whether one of the bindings of the action was released in the previous frame.
+func (a InputAction) JustReleased() bool {
+	if Input.ignored || Input.consumed[a.Name] {
+		return false
+	}
+	for _, binding := range a.Bindings {
+		if binding.JustReleased() {
+			return true
+		}
+	}
+	return false
+}
+
+// Down checks whether one of the bindings of the action is being held down.
+func (a InputAction) Down() bool {
+	if Input.ignored || Input.consumed[a.Name] {
+		return false
+	}
+	for _, binding := range a.Bindings {
+		if binding.Down() {
+			return true
+		}
+	}
+	return false
+}
+
+// An ActionBinding is an input which can trigger an InputAction.
+type ActionBinding interface {
+	JustPressed() bool
+	JustReleased() bool
+	Down() bool
+}
+
+// An ActionKey binds a key of the keyboard to an InputAction.
+type ActionKey struct {
+	Key Key
+}
+
+// JustPressed checks whether the key was pressed in the previous frame.
+func (k ActionKey) JustPressed() bool {
+	return Input.keys.Get(k.Key).JustPressed()
+}
+
+// JustReleased checks whether the key was released in the previous frame.
+func (k ActionKey) JustReleased() bool {
+	return Input.keys.Get(k.Key).JustReleased()
+}
+
+// Down checks whether the key is being held down.
+func (k ActionKey) Down() bool {
+	return Input.keys.Get(k.Key).Down()
+}
+
+// An ActionGamepadButton binds a button of a registered Gamepad to an InputAction.
+type ActionGamepadButton struct {
+	// Gamepad is the name the Gamepad was registered with
+	Gamepad string
+	Button  GamepadButtonID
+}
+
+func (g ActionGamepadButton) button() *GamepadButton {
+	gamepad := Input.Gamepad(g.Gamepad)
+	if gamepad == nil {
+		return nil
+	}
+	return gamepad.Button(g.Button)
+}
+
+// JustPressed checks whether the button was pressed in the previous frame.
+func (g ActionGamepadButton) JustPressed() bool {
+	b := g.button()
+	return b != nil && b.JustPressed()
+}
+
+// JustReleased checks whether the button was released in the previous frame.
+func (g ActionGamepadButton) JustReleased() bool {
+	b := g.button()
+	return b != nil && b.JustReleased()
+}
+
+// Down checks whether the button is being held down.
+func (g ActionGamepadButton) Down() bool {
+	b := g.button()
+	return b != nil && b.Down()
+}
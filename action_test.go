@@ -0,0 +1,117 @@
+package engo
+
+import "testing"
+
+type actionState struct {
+	down     bool
+	justUp   bool
+	justDown bool
+}
+
+// Checks the state of an action against the expected state.
+func runActionCheck(msg string, t *testing.T, name string, exp actionState) {
+	action := Input.Action(name)
+	if exp.down != action.Down() {
+		t.Error(msg, " Invalid on: ", name, " - Down")
+	}
+	if exp.justUp != action.JustReleased() {
+		t.Error(msg, " Invalid on: ", name, " - Just Up")
+	}
+	if exp.justDown != action.JustPressed() {
+		t.Error(msg, " Invalid on: ", name, " - Just Down")
+	}
+}
+
+// Test an action bound to multiple keys.
+func TestActionKeys(t *testing.T) {
+	Input = NewInputManager()
+	Input.RegisterAction("Jump", ActionKey{KeySpace}, ActionKey{KeyW})
+
+	Input.update()
+	runActionCheck("Pass (0.0)", t, "Jump", actionState{})
+
+	Input.update()
+	Input.keys.Set(KeyW, true)
+	runActionCheck("Pass (1.0)", t, "Jump", actionState{justDown: true})
+
+	Input.update()
+	runActionCheck("Pass (2.0)", t, "Jump", actionState{down: true})
+
+	Input.update()
+	Input.keys.Set(KeyW, false)
+	runActionCheck("Pass (3.0)", t, "Jump", actionState{justUp: true})
+
+	Input.update()
+	runActionCheck("Pass (4.0)", t, "Jump", actionState{})
+	runActionCheck("Unknown", t, "Unknown", actionState{})
+}
+
+// Test changing the bindings of an action.
+func TestActionRebinding(t *testing.T) {
+	Input = NewInputManager()
+	Input.RegisterAction("Fire", ActionKey{KeyF})
+	Input.RegisterAction("Fire", ActionKey{KeyEnter})
+
+	Input.update()
+	Input.keys.Set(KeyF, true)
+	runActionCheck("Replaced", t, "Fire", actionState{})
+	Input.keys.Set(KeyEnter, true)
+	runActionCheck("Registered", t, "Fire", actionState{justDown: true})
+
+	Input.UnbindAction("Fire", ActionKey{KeyEnter})
+	if len(Input.Action("Fire").Bindings) != 0 {
+		t.Errorf("Bindings expected=0 ; got=%d", len(Input.Action("Fire").Bindings))
+	}
+	runActionCheck("Unbound", t, "Fire", actionState{})
+
+	Input.BindAction("Fire", ActionKey{KeyF})
+	runActionCheck("Bound", t, "Fire", actionState{justDown: true})
+	if Input.Action("Fire").Name != "Fire" {
+		t.Errorf("Name expected=Fire ; got=%s", Input.Action("Fire").Name)
+	}
+}
+
+// chordBinding is a binding which can't be compared with ==.
+type chordBinding struct {
+	keys []Key
+}
+
+func (c chordBinding) JustPressed() bool  { return false }
+func (c chordBinding) JustReleased() bool { return false }
+func (c chordBinding) Down() bool         { return false }
+
+// Test unbinding bindings of types which can't be compared with ==.
+func TestActionUnbindUncomparable(t *testing.T) {
+	Input = NewInputManager()
+	Input.RegisterAction("Dash", chordBinding{[]Key{KeyLeftShift, KeyD}}, ActionKey{KeyE})
+	Input.UnbindAction("Dash", chordBinding{[]Key{KeyLeftShift, KeyD}})
+	if bindings := Input.Action("Dash").Bindings; len(bindings) != 1 || bindings[0] != (ActionKey{KeyE}) {
+		t.Errorf("Only the chord should be unbound; got=%v", bindings)
+	}
+}
+
+// Test an action bound to a gamepad button.
+func TestActionGamepadButton(t *testing.T) {
+	Input = NewInputManager()
+	Input.gamepads.gamepads["Player 1"] = &Gamepad{}
+	Input.RegisterAction("Jump", ActionKey{KeySpace}, ActionGamepadButton{Gamepad: "Player 1", Button: GamepadA})
+	// Bindings of gamepads that aren't registered are ignored
+	Input.BindAction("Jump", ActionGamepadButton{Gamepad: "Player 2", Button: GamepadA})
+
+	Input.Gamepad("Player 1").A.set(true)
+	runActionCheck("Pressed", t, "Jump", actionState{justDown: true})
+	Input.Gamepad("Player 1").A.set(true)
+	runActionCheck("Held", t, "Jump", actionState{down: true})
+	Input.Gamepad("Player 1").A.set(false)
+	runActionCheck("Released", t, "Jump", actionState{justUp: true})
+
+	// Unplugging the controller releases its buttons and axes
+	Input.Gamepad("Player 1").A.set(true)
+	Input.Gamepad("Player 1").A.set(true)
+	Input.Gamepad("Player 1").LeftX.set(1)
+	Input.Gamepad("Player 1").release()
+	runActionCheck("Unplugged", t, "Jump", actionState{})
+	if v := Input.Gamepad("Player 1").LeftX.Value(); v != 0 {
+		t.Errorf("Axes should be neutral once the controller is unplugged. got=%v", v)
+	}
+}
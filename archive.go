@@ -0,0 +1,128 @@
+package engo
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// PackArchive writes every file within the root directory, such as the `assets` directory of a game, to w as a zip
+// archive. Files.MountArchive then loads the assets from the archive instead of the directory, so a game can be
+// shipped as its executable and a single archive. The paths within the archive are relative to root, in the same way
+// as the urls given to Files.Load.
+//
+// It's meant to be called by a small tool at build time, such as one run by `go generate`.
+func PackArchive(w io.Writer, root string) error {
+	zw := zip.NewWriter(w)
+	err := filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		header.Method = zip.Deflate
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(fw, f)
+		return err
+	})
+	if err != nil {
+		zw.Close()
+		return fmt.Errorf("unable to pack %q: %s", root, err)
+	}
+	return zw.Close()
+}
+
+// MountArchive opens the archive at url, which is relative to the working directory rather than the root, and loads
+// resources from it from then on. Resources which aren't in the archive are still loaded from the root directory.
+// Mounting another archive replaces the previous one. Run mounts the AssetsArchive of the RunOptions when it exists.
+func (formats *Formats) MountArchive(url string) error {
+	f, err := openFile(url)
+	if err != nil {
+		return fmt.Errorf("unable to open archive: %s", err)
+	}
+	return formats.mountArchive(f)
+}
+
+// mountArchive reads the archive from f, mounts it, and closes f.
+func (formats *Formats) mountArchive(f io.ReadCloser) error {
+	archive, err := readArchive(f)
+	if err != nil {
+		return err
+	}
+	formats.archive = archive
+	return nil
+}
+
+// MountArchiveData loads resources from the archive held in data from then on, such as an archive embedded within
+// the executable.
+func (formats *Formats) MountArchiveData(data []byte) error {
+	archive, err := archiveFiles(data)
+	if err != nil {
+		return err
+	}
+	formats.archive = archive
+	return nil
+}
+
+// readArchive reads the zip archive from f, closes f, and returns its files by url.
+func readArchive(f io.ReadCloser) (map[string]*zip.File, error) {
+	defer f.Close()
+
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read archive: %s", err)
+	}
+	return archiveFiles(data)
+}
+
+// archiveFiles returns the files of the zip archive held in data by url.
+func archiveFiles(data []byte) (map[string]*zip.File, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read archive: %s", err)
+	}
+	archive := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		archive[path.Clean(f.Name)] = f
+	}
+	return archive, nil
+}
+
+// UnmountArchive stops loading resources from the mounted archive.
+func (formats *Formats) UnmountArchive() {
+	formats.archive = nil
+}
+
+// Open opens the file of a resource, from the first of the directories and archives added with Mount that has it,
+// or else from the mounted archive if it's in there, or else from the root directory. FileLoaders that load other
+// files referenced by theirs, such as the tilesets of a TMX level, should open them with it as well.
+func (formats *Formats) Open(url string) (io.ReadCloser, error) {
+	clean := path.Clean(filepath.ToSlash(url))
+	if f, ok := formats.openMounted(clean); ok {
+		return f, nil
+	}
+	if f, ok := formats.archive[clean]; ok {
+		return f.Open()
+	}
+	return openFile(filepath.Join(formats.root, url))
+}
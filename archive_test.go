@@ -0,0 +1,69 @@
+package engo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test packing a directory and loading the files from the archive.
+func TestFilesArchive(t *testing.T) {
+	Run(RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &assetTestScene{})
+	Files.Register(".test", &testLoader{})
+
+	dir, err := ioutil.TempDir(".", "testing")
+	if err != nil {
+		t.Fatalf("failed to create temp directory for testing, error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	if err = os.MkdirAll(filepath.Join(dir, "sub"), 0777); err != nil {
+		t.Fatalf("failed to create temp directory for testing, error: %v", err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "sub", "packed.test"), []byte("packed"), 0666); err != nil {
+		t.Fatalf("failed to create temp file for testing, error: %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err = PackArchive(buf, dir); err != nil {
+		t.Fatalf("could not pack %v, error: %v", dir, err)
+	}
+	if err = os.RemoveAll(filepath.Join(dir, "sub")); err != nil {
+		t.Fatalf("failed to remove the packed files, error: %v", err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "loose.test"), []byte("loose"), 0666); err != nil {
+		t.Fatalf("failed to create temp file for testing, error: %v", err)
+	}
+
+	Files.SetRoot(dir)
+	if err = Files.MountArchiveData(buf.Bytes()); err != nil {
+		t.Fatalf("could not mount the archive, error: %v", err)
+	}
+	defer Files.UnmountArchive()
+
+	f, err := Files.Open("sub/packed.test")
+	if err != nil {
+		t.Fatalf("could not open a packed file, error: %v", err)
+	}
+	content, _ := ioutil.ReadAll(f)
+	f.Close()
+	if string(content) != "packed" {
+		t.Errorf("packed file content expected=%q ; got=%q", "packed", content)
+	}
+	if err = Files.Load("sub/packed.test", "loose.test"); err != nil {
+		t.Errorf("could not load from the archive and the root, error: %v", err)
+	}
+
+	Files.UnmountArchive()
+	if err = Files.Load("sub/packed.test"); err == nil || !strings.HasPrefix(err.Error(), "unable to open resource:") {
+		t.Errorf("packed files should not load once the archive is unmounted, got: %v", err)
+	}
+	if err = Files.MountArchiveData([]byte("not an archive")); err == nil {
+		t.Error("mounting something that isn't an archive should fail")
+	}
+}
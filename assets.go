@@ -1,10 +1,11 @@
 package engo
 
 import (
+	"archive/zip"
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
+	"time"
 )
 
 // FileLoader implements support for loading and releasing file resources.
@@ -49,6 +50,22 @@ type Formats struct {
 
 	// root is the directory which is prepended to every resource url internally.
 	root string
+
+	// archive maps the urls of the files in the mounted archive to them.
+	archive map[string]*zip.File
+
+	// mounts are the directories and archives added with Mount, in the order they're tried in.
+	mounts []mount
+
+	// watched maps the urls of the resources loaded from files to when their file was last modified, with the
+	// HotReload of the RunOptions.
+	watched map[string]time.Time
+
+	// scopes maps the names of the Scenes to the urls of the resources loaded for them, which are unloaded when the
+	// Scene is freed. The resources of the globalScope are never unloaded that way.
+	scopes map[string]map[string]struct{}
+	// scope is the name of the scope resources are loaded in, instead of the one of the current Scene
+	scope *string
 }
 
 // SetRoot can be used to change the default directory from `assets` to whatever you want.
@@ -87,7 +104,7 @@ func getExt(path string) string {
 func (formats *Formats) load(url string) error {
 	ext := getExt(url)
 	if loader, ok := Files.formats[ext]; ok {
-		f, err := openFile(filepath.Join(formats.root, url))
+		f, err := formats.Open(url)
 		if err != nil {
 			return fmt.Errorf("unable to open resource: %s", err)
 		}
@@ -99,18 +116,28 @@ func (formats *Formats) load(url string) error {
 			rl.SetRoot(formats.GetRoot())
 		}
 
-		return loader.Load(url, f)
+		if err := loader.Load(url, f); err != nil {
+			return err
+		}
+		if opts.HotReload {
+			formats.watch(url)
+		}
+		return nil
 	}
 	return fmt.Errorf("no `FileLoader` associated with this extension: %q in url %q", ext, url)
 }
 
-// Load loads the given resource(s) into memory, stopping at the first error.
+// Load loads the given resource(s) into memory, stopping at the first error. They belong to the Scene being set up, or
+// the current Scene, and are unloaded when it's freed with FreeScene, unless they were loaded with LoadGlobal or kept
+// with Keep. Switching to another Scene doesn't unload them, as the Scene keeps its world to be shown again, so
+// FreeScene has to be called once a Scene isn't needed anymore, unless the Scene is Disposable.
 func (formats *Formats) Load(urls ...string) error {
 	for _, url := range urls {
 		err := formats.load(url)
 		if err != nil {
 			return err
 		}
+		formats.track(url)
 	}
 	return nil
 }
@@ -124,7 +151,11 @@ func (formats *Formats) LoadReaderData(url string, f io.Reader) error {
 		if ok {
 			rl.SetRoot(formats.GetRoot())
 		}
-		return loader.Load(url, f)
+		if err := loader.Load(url, f); err != nil {
+			return err
+		}
+		formats.track(url)
+		return nil
 	}
 	return fmt.Errorf("no `FileLoader` associated with this extension: %q in url %q", ext, url)
 }
@@ -133,6 +164,8 @@ func (formats *Formats) LoadReaderData(url string, f io.Reader) error {
 func (formats *Formats) Unload(url string) error {
 	ext := getExt(url)
 	if loader, ok := Files.formats[ext]; ok {
+		formats.unwatch(url)
+		formats.untrack(url)
 		return loader.Unload(url)
 	}
 	return fmt.Errorf("no `FileLoader` associated with this extension: %q in url %q", ext, url)
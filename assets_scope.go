@@ -0,0 +1,94 @@
+package engo
+
+// globalScope is the scope of the resources which aren't unloaded when a Scene is freed.
+var globalScope = ""
+
+// LoadGlobal loads the given resource(s) into memory like Load, but they don't belong to any Scene, so they're never
+// unloaded when a Scene is freed. Use it for resources shared between Scenes, so they aren't loaded again for each of
+// them.
+func (formats *Formats) LoadGlobal(urls ...string) error {
+	prev := formats.scope
+	formats.scope = &globalScope
+	defer func() { formats.scope = prev }()
+	return formats.Load(urls...)
+}
+
+// Keep makes loaded resources survive the Scenes they were loaded for, as if they were loaded with LoadGlobal. They
+// stay loaded until they're unloaded with Unload.
+func (formats *Formats) Keep(urls ...string) {
+	for _, url := range urls {
+		formats.trackIn(globalScope, url)
+	}
+}
+
+// Scoped returns the urls of the resources loaded for the Scene with the given name, which are unloaded when it's
+// freed, unless another Scene loaded them as well or they are kept.
+func (formats *Formats) Scoped(name string) []string {
+	var urls []string
+	for url := range formats.scopes[name] {
+		if !formats.heldOutside(name, url) {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// track adds the resource to the scope it's loaded in: the one being set, or the one of the current Scene.
+func (formats *Formats) track(url string) {
+	switch {
+	case formats.scope != nil:
+		formats.trackIn(*formats.scope, url)
+	case currentScene != nil:
+		formats.trackIn(currentScene.Type(), url)
+	default:
+		formats.trackIn(globalScope, url)
+	}
+}
+
+func (formats *Formats) trackIn(scope, url string) {
+	if formats.scopes == nil {
+		formats.scopes = make(map[string]map[string]struct{})
+	}
+	if formats.scopes[scope] == nil {
+		formats.scopes[scope] = make(map[string]struct{})
+	}
+	formats.scopes[scope][url] = struct{}{}
+}
+
+// untrack removes the resource from every scope.
+func (formats *Formats) untrack(url string) {
+	for _, urls := range formats.scopes {
+		delete(urls, url)
+	}
+}
+
+// heldOutside returns whether the resource belongs to another scope than the given one.
+func (formats *Formats) heldOutside(scope, url string) bool {
+	for name, urls := range formats.scopes {
+		if _, ok := urls[url]; ok && name != scope {
+			return true
+		}
+	}
+	return false
+}
+
+// loadIn calls load with resources loaded in the scope of the Scene with the given name.
+func (formats *Formats) loadIn(name string, load func()) {
+	prev := formats.scope
+	formats.scope = &name
+	defer func() { formats.scope = prev }()
+	load()
+}
+
+// unloadScope unloads the resources loaded for the Scene with the given name, except the ones which belong to another
+// scope as well. It's called by FreeScene, including when SetScene or PopScene leave a Disposable Scene. Other Scenes
+// keep their world when they're left, and with it their resources.
+func (formats *Formats) unloadScope(name string) {
+	urls := formats.scopes[name]
+	delete(formats.scopes, name)
+	for url := range urls {
+		if !formats.heldOutside(name, url) {
+			formats.Unload(url)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package engo
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+type scopeTestLoader struct {
+	loaded map[string]bool
+}
+
+func (l *scopeTestLoader) Load(url string, data io.Reader) error {
+	l.loaded[url] = true
+	return nil
+}
+
+func (l *scopeTestLoader) Unload(url string) error {
+	delete(l.loaded, url)
+	return nil
+}
+
+func (l *scopeTestLoader) Resource(url string) (Resource, error) {
+	return testResource{url: url}, nil
+}
+
+type scopeTestScene struct {
+	name string
+	urls []string
+}
+
+func (s *scopeTestScene) Preload() {
+	for _, url := range s.urls {
+		Files.LoadReaderData(url, &bytes.Buffer{})
+	}
+}
+
+func (*scopeTestScene) Setup(Updater) {}
+
+func (s *scopeTestScene) Type() string { return s.name }
+
+func TestFilesSceneScope(t *testing.T) {
+	Run(RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &assetTestScene{})
+
+	loader := &scopeTestLoader{loaded: make(map[string]bool)}
+	Files.Register(".scoped", loader)
+
+	dir, err := ioutil.TempDir(".", "testing")
+	if err != nil {
+		t.Fatalf("failed to create temp directory for testing, error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	Files.SetRoot(dir)
+	if err = ioutil.WriteFile(filepath.Join(dir, "shared.scoped"), []byte("testing"), 0666); err != nil {
+		t.Fatalf("failed to create temp file for testing, error: %v", err)
+	}
+	if err = Files.LoadGlobal("shared.scoped"); err != nil {
+		t.Fatalf("could not load test file %v, error: %v", "shared.scoped", err)
+	}
+	Files.LoadReaderData("kept.scoped", &bytes.Buffer{})
+	Files.Keep("kept.scoped")
+
+	level := &scopeTestScene{name: "scopeLevel", urls: []string{"level.scoped", "both.scoped", "kept.scoped", "shared.scoped"}}
+	menu := &scopeTestScene{name: "scopeMenu", urls: []string{"menu.scoped", "both.scoped"}}
+	PreloadScene(level)
+	PreloadScene(menu)
+
+	scoped := Files.Scoped("scopeLevel")
+	sort.Strings(scoped)
+	if len(scoped) != 1 || scoped[0] != "level.scoped" {
+		t.Errorf("Only the resources of the level should be unloaded with it\nWanted: %v\nGot: %v", []string{"level.scoped"}, scoped)
+	}
+
+	if err := FreeScene("scopeLevel"); err != nil {
+		t.Fatalf("unable to free the level: %v", err)
+	}
+	if loader.loaded["level.scoped"] {
+		t.Error("The resources of a freed scene should be unloaded")
+	}
+	if !loader.loaded["both.scoped"] || !loader.loaded["kept.scoped"] {
+		t.Error("Resources loaded for another scene, or kept, should not be unloaded")
+	}
+
+	if err := FreeScene("scopeMenu"); err != nil {
+		t.Fatalf("unable to free the menu: %v", err)
+	}
+	if loader.loaded["both.scoped"] || loader.loaded["menu.scoped"] {
+		t.Error("Resources should be unloaded once every scene they were loaded for is freed")
+	}
+	if !loader.loaded["kept.scoped"] || !loader.loaded["shared.scoped"] {
+		t.Error("Kept and global resources should survive the scenes")
+	}
+}
+
+type disposableScopeTestScene struct {
+	scopeTestScene
+}
+
+func (*disposableScopeTestScene) Disposable() bool { return true }
+
+func TestFilesSceneScopeDisposable(t *testing.T) {
+	Run(RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &assetTestScene{})
+	defer SetScene(&assetTestScene{}, false)
+
+	loader := &scopeTestLoader{loaded: make(map[string]bool)}
+	Files.Register(".disposable", loader)
+
+	level := &disposableScopeTestScene{scopeTestScene{name: "disposableLevel", urls: []string{"level.disposable"}}}
+	menu := &scopeTestScene{name: "disposableMenu", urls: []string{"menu.disposable"}}
+	pause := &disposableScopeTestScene{scopeTestScene{name: "disposablePause", urls: []string{"pause.disposable"}}}
+
+	SetScene(level, false)
+	SetScene(menu, false)
+	if loader.loaded["level.disposable"] || ScenePreloaded("disposableLevel") {
+		t.Error("SetScene should free the disposable scene it leaves, and unload its resources")
+	}
+
+	PushScene(pause, false)
+	if !loader.loaded["menu.disposable"] || !loader.loaded["pause.disposable"] {
+		t.Error("The resources of the scenes on the stack should be kept")
+	}
+	if err := PopScene(); err != nil {
+		t.Fatalf("unable to pop the pause menu: %v", err)
+	}
+	if loader.loaded["pause.disposable"] {
+		t.Error("PopScene should free the disposable scene it pops, and unload its resources")
+	}
+
+	SetScene(level, false)
+	if !loader.loaded["menu.disposable"] || !ScenePreloaded("disposableMenu") {
+		t.Error("Scenes which aren't disposable should keep their world and resources when they're left")
+	}
+	if err := FreeScene("disposableMenu"); err != nil {
+		t.Errorf("Error when freeing a scene: %v", err)
+	}
+	if loader.loaded["menu.disposable"] {
+		t.Error("FreeScene should unload the resources of the scene")
+	}
+}
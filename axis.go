@@ -10,6 +10,9 @@ type Axis struct {
 
 // Value returns the value of an Axis.
 func (a Axis) Value() float32 {
+	if Input.ignored {
+		return AxisNeutral
+	}
 	for _, pair := range a.Pairs {
 		v := pair.Value()
 		if v != AxisNeutral {
@@ -92,6 +95,8 @@ func (am *AxisMouse) Value() float32 {
 
 type AxisGamepad struct {
 	value float32
+	// Deadzone is the amount the axis has to be tilted before it's no longer Neutral.
+	Deadzone float32
 }
 
 func (ag *AxisGamepad) set(v float32) {
@@ -99,7 +104,26 @@ func (ag *AxisGamepad) set(v float32) {
 }
 
 // Value returns the amount and direction the axis is "tilted" from -1 to 1
-// 0 being Neutral.
+// 0 being Neutral. Values within the Deadzone are Neutral, and the rest of the
+// range is scaled so the value still starts at 0 right outside of the Deadzone.
 func (ag *AxisGamepad) Value() float32 {
-	return ag.value
+	v := ag.value
+	if v > AxisMax {
+		v = AxisMax
+	} else if v < AxisMin {
+		v = AxisMin
+	}
+	if ag.Deadzone <= 0 {
+		return v
+	}
+	if ag.Deadzone >= 1 {
+		return AxisNeutral
+	}
+	switch {
+	case v > ag.Deadzone:
+		return (v - ag.Deadzone) / (1 - ag.Deadzone)
+	case v < -ag.Deadzone:
+		return (v + ag.Deadzone) / (1 - ag.Deadzone)
+	}
+	return AxisNeutral
 }
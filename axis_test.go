@@ -454,6 +454,40 @@ func TestAxisMouse(t *testing.T) {
 	runAxisMouse("Pass 7", t, 0.0, 0.0)
 }
 
+// Test the deadzone of gamepad axes.
+func TestAxisGamepadDeadzone(t *testing.T) {
+	Input = NewInputManager()
+	Input.gamepads.gamepads["Player 1"] = &Gamepad{connected: true}
+	Input.gamepads.gamepads["Player 2"] = &Gamepad{}
+
+	if names := Input.Gamepads(); len(names) != 1 || names[0] != "Player 1" {
+		t.Error("Only connected gamepads should be listed: ", names)
+	}
+	if !Input.Gamepad("Player 1").Connected() || Input.Gamepad("Player 2").Connected() {
+		t.Error("Invalid connected state")
+	}
+
+	gamepad := Input.Gamepad("Player 1")
+	gamepad.SetDeadzone(0.2)
+	for _, c := range []struct{ raw, expected float32 }{
+		{0, 0},
+		{0.1, 0},
+		{-0.2, 0},
+		{0.6, 0.5},
+		{-0.6, -0.5},
+		{1, 1},
+		{-1.5, -1},
+	} {
+		gamepad.LeftX.set(c.raw)
+		if v := gamepad.LeftX.Value(); !FloatEqual(v, c.expected) {
+			t.Errorf("Value for %f expected=%f ; got=%f", c.raw, c.expected, v)
+		}
+	}
+	if gamepad.RightTrigger.Deadzone != 0.2 {
+		t.Errorf("Deadzone expected=%f ; got=%f", 0.2, gamepad.RightTrigger.Deadzone)
+	}
+}
+
 // Used to store results when benchmarking.
 var axResult [6]axState
 
@@ -8,6 +8,9 @@ type Button struct {
 
 // JustPressed checks whether an input was pressed in the previous frame.
 func (b Button) JustPressed() bool {
+	if Input.ignored || Input.consumed[b.Name] {
+		return false
+	}
 	for _, trigger := range b.Triggers {
 		v := Input.keys.Get(trigger).JustPressed()
 		if v {
@@ -20,6 +23,9 @@ func (b Button) JustPressed() bool {
 
 // JustReleased checks whether an input was released in the previous frame.
 func (b Button) JustReleased() bool {
+	if Input.ignored || Input.consumed[b.Name] {
+		return false
+	}
 	for _, trigger := range b.Triggers {
 		v := Input.keys.Get(trigger).JustReleased()
 		if v {
@@ -32,6 +38,9 @@ func (b Button) JustReleased() bool {
 
 // Down checks whether the current input is being held down.
 func (b Button) Down() bool {
+	if Input.ignored || Input.consumed[b.Name] {
+		return false
+	}
 	for _, trigger := range b.Triggers {
 		v := Input.keys.Get(trigger).Down()
 		if v {
@@ -0,0 +1,80 @@
+package engo
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrClipboardDenied is returned when the platform doesn't allow reading or writing the clipboard, such as browsers
+// before the player allowed the page to.
+var ErrClipboardDenied = errors.New("clipboard: access denied")
+
+// errClipboardUnsupported is returned by the platforms without a clipboard, in which case the ClipboardManager keeps
+// the text itself.
+var errClipboardUnsupported = errors.New("clipboard: unsupported")
+
+// Clipboard is the clipboard of the platform. On platforms without a clipboard, and while headless, it only holds
+// the text set by the game itself.
+var Clipboard = &ClipboardManager{}
+
+// ClipboardManager reads and writes the clipboard of the platform. An empty clipboard, or one holding something
+// other than text, reads as an empty string without an error.
+//
+// Browsers only allow reading the clipboard asynchronously, after asking the player for permission. On the web, Get
+// returns the text last set by the game or pasted into the page, and GetAsync asks the browser for the current text.
+type ClipboardManager struct {
+	mutex sync.Mutex
+	// text is the last text set or pasted, for the platforms without a clipboard
+	text string
+}
+
+// Get returns the text on the clipboard.
+func (c *ClipboardManager) Get() (string, error) {
+	if !opts.HeadlessMode {
+		text, err := clipboardGet()
+		if err != errClipboardUnsupported {
+			return text, err
+		}
+	}
+	return c.stored(), nil
+}
+
+// GetAsync calls the callback with the text on the clipboard. On the web, the callback is called once the browser
+// read the clipboard, which may take until the player allowed it to. Everywhere else it's called right away, with
+// the result of Get.
+func (c *ClipboardManager) GetAsync(callback func(text string, err error)) {
+	if opts.HeadlessMode {
+		callback(c.Get())
+		return
+	}
+	clipboardGetAsync(func(text string, err error) {
+		if err == errClipboardUnsupported {
+			text, err = c.stored(), nil
+		}
+		callback(text, err)
+	})
+}
+
+// Set puts the text on the clipboard.
+func (c *ClipboardManager) Set(text string) error {
+	c.store(text)
+	if opts.HeadlessMode {
+		return nil
+	}
+	if err := clipboardSet(text); err != errClipboardUnsupported {
+		return err
+	}
+	return nil
+}
+
+func (c *ClipboardManager) stored() string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.text
+}
+
+func (c *ClipboardManager) store(text string) {
+	c.mutex.Lock()
+	c.text = text
+	c.mutex.Unlock()
+}
@@ -0,0 +1,34 @@
+package engo
+
+import "testing"
+
+// Test the clipboard while headless, where it only holds the text set by the game.
+func TestClipboard(t *testing.T) {
+	Run(RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &testScene{})
+	Clipboard = &ClipboardManager{}
+
+	if text, err := Clipboard.Get(); text != "" || err != nil {
+		t.Errorf("empty clipboard expected=%q, %v ; got=%q, %v", "", nil, text, err)
+	}
+
+	if err := Clipboard.Set("héllo"); err != nil {
+		t.Errorf("setting the clipboard should not fail, got %v", err)
+	}
+	if text, err := Clipboard.Get(); text != "héllo" || err != nil {
+		t.Errorf("clipboard expected=%q, %v ; got=%q, %v", "héllo", nil, text, err)
+	}
+
+	called := false
+	Clipboard.GetAsync(func(text string, err error) {
+		called = true
+		if text != "héllo" || err != nil {
+			t.Errorf("clipboard expected=%q, %v ; got=%q, %v", "héllo", nil, text, err)
+		}
+	})
+	if !called {
+		t.Error("GetAsync should call the callback right away while headless")
+	}
+}
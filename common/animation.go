@@ -11,6 +11,11 @@ type Animation struct {
 	Name   string
 	Frames []int
 	Loop   bool
+	// Durations optionally sets how long each frame is displayed, in seconds,
+	// indexed like Frames. When set, it is used instead of the
+	// AnimationComponent's Rate, and frames without a duration fall back to
+	// the Rate.
+	Durations []float32
 }
 
 // AnimationComponent tracks animations of an entity it is part of.
@@ -99,9 +104,39 @@ func (ac *AnimationComponent) NextFrame() {
 	}
 }
 
+// frameDuration returns how long the current frame should be displayed for.
+func (ac *AnimationComponent) frameDuration() float32 {
+	if ac.index < len(ac.CurrentAnimation.Durations) {
+		return ac.CurrentAnimation.Durations[ac.index]
+	}
+	return ac.Rate
+}
+
+// advance accumulates dt against the duration of the current frame, moving
+// on as many frames as have elapsed. Time left over counts towards the next
+// frame. It returns false if a non-looping animation has finished.
+func (ac *AnimationComponent) advance(dt float32) bool {
+	ac.change += dt
+	// Bounded by the number of frames so zero durations can't loop forever
+	for i := 0; i < len(ac.CurrentAnimation.Frames); i++ {
+		d := ac.frameDuration()
+		if ac.change < d {
+			break
+		}
+		left := ac.change - d
+		ac.NextFrame()
+		if ac.CurrentAnimation == nil {
+			return false
+		}
+		ac.change = left
+	}
+	return true
+}
+
 // AnimationSystem tracks AnimationComponents, advancing their current animation.
 type AnimationSystem struct {
 	entities map[uint64]animationEntity
+	states   *entityStates
 }
 
 type animationEntity struct {
@@ -109,6 +144,11 @@ type animationEntity struct {
 	*RenderComponent
 }
 
+// New initializes the AnimationSystem.
+func (a *AnimationSystem) New(w *ecs.World) {
+	a.states = worldEntityStates(w)
+}
+
 // Add starts tracking the given entity.
 func (a *AnimationSystem) Add(basic *ecs.BasicEntity, anim *AnimationComponent, render *RenderComponent) {
 	if a.entities == nil {
@@ -130,9 +170,17 @@ func (a *AnimationSystem) Remove(basic ecs.BasicEntity) {
 	}
 }
 
+// EntityCount returns the number of entities the AnimationSystem holds.
+func (a *AnimationSystem) EntityCount() int {
+	return len(a.entities)
+}
+
 // Update advances the animations of all tracked entities.
 func (a *AnimationSystem) Update(dt float32) {
-	for _, e := range a.entities {
+	for id, e := range a.entities {
+		if a.states.disabled(id) {
+			continue
+		}
 		if e.AnimationComponent.CurrentAnimation == nil {
 			if e.AnimationComponent.def == nil {
 				continue
@@ -140,6 +188,13 @@ func (a *AnimationSystem) Update(dt float32) {
 			e.AnimationComponent.SelectAnimationByAction(e.AnimationComponent.def)
 		}
 
+		if len(e.AnimationComponent.CurrentAnimation.Durations) > 0 {
+			if e.AnimationComponent.advance(dt) {
+				e.RenderComponent.Drawable = e.AnimationComponent.Cell()
+			}
+			continue
+		}
+
 		e.AnimationComponent.change += dt
 		if e.AnimationComponent.change >= e.AnimationComponent.Rate {
 			e.RenderComponent.Drawable = e.AnimationComponent.Cell()
@@ -494,3 +494,42 @@ func TestAnimationSystemIntegration(t *testing.T) {
 		return
 	}
 }
+
+func TestAnimationSystemFrameDurations(t *testing.T) {
+	drawables := []Drawable{
+		&TestDrawable{0},
+		&TestDrawable{1},
+		&TestDrawable{2},
+	}
+	ac := NewAnimationComponent(drawables, 1)
+	ac.AddDefaultAnimation(&Animation{
+		Name:      "durations",
+		Frames:    []int{0, 1, 2},
+		Loop:      true,
+		Durations: []float32{0.1, 0.5, 0.2},
+	})
+	rc := &RenderComponent{}
+	basic := ecs.NewBasic()
+	sys := &AnimationSystem{}
+	sys.Add(&basic, &ac, rc)
+
+	steps := []struct {
+		dt    float32
+		frame int
+	}{
+		{0.05, 0}, // 0.05 into frame 0
+		{0.1, 1},  // 0.05 into frame 1
+		{0.4, 1},  // 0.45 into frame 1
+		{0.1, 2},  // 0.05 into frame 2
+		{0.1, 2},  // 0.15 into frame 2
+		{0.1, 0},  // 0.05 into frame 0, looped
+		{0.7, 2},  // 0.1 into frame 2, skipping frame 1 entirely
+	}
+	for i, step := range steps {
+		sys.Update(step.dt)
+		td := rc.Drawable.(*TestDrawable)
+		if td.ID != step.frame {
+			t.Errorf("Wrong frame after step %d\nWanted: %v\nGot: %v", i, step.frame, td.ID)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/klopsch/ecs"
 	"github.com/klopsch/engo"
+	"github.com/klopsch/engo/math"
 
 	"github.com/hajimehoshi/oto"
 )
@@ -58,6 +59,50 @@ func (l *stepPlayer) Step() {
 // AudioComponent is a Component which is used by the AudioSystem
 type AudioComponent struct {
 	Player *Player
+
+	// Space makes the audio positional when set. Its volume and pan then depend on the position of the center of
+	// Space relative to the Listener of the AudioSystem.
+	Space *SpaceComponent
+	// MaxDistance is the distance from the listener at which positional audio becomes silent. It's also the
+	// horizontal distance at which the audio is only played on one side. If it's zero, positional audio is only
+	// panned by which side of the listener it's on.
+	MaxDistance float32
+	// Rolloff is how the volume of positional audio decreases with distance. Defaults to LinearRolloff.
+	Rolloff Rolloff
+}
+
+// Rolloff returns the volume of positional audio at the given distance from the listener, from 1 at the listener to
+// 0 at maxDistance.
+type Rolloff func(distance, maxDistance float32) float32
+
+// LinearRolloff decreases the volume evenly with distance.
+func LinearRolloff(distance, maxDistance float32) float32 {
+	return math.Clamp(1-distance/maxDistance, 0, 1)
+}
+
+// QuadraticRolloff decreases the volume quickly near the listener and slowly far away, which sounds more natural
+// than LinearRolloff.
+func QuadraticRolloff(distance, maxDistance float32) float32 {
+	v := LinearRolloff(distance, maxDistance)
+	return v * v
+}
+
+// spatialize returns the gain and pan of positional audio at pos, heard from listener.
+func spatialize(listener, pos engo.Point, maxDistance float32, rolloff Rolloff) (gain, pan float32) {
+	dx := pos.X - listener.X
+	if maxDistance <= 0 {
+		switch {
+		case dx < 0:
+			return 1, -1
+		case dx > 0:
+			return 1, 1
+		}
+		return 1, 0
+	}
+	if rolloff == nil {
+		rolloff = LinearRolloff
+	}
+	return rolloff(listener.PointDistance(pos), maxDistance), math.Clamp(dx/maxDistance, -1, 1)
 }
 
 type audioEntity struct {
@@ -67,15 +112,34 @@ type audioEntity struct {
 
 // AudioSystem is a System that allows for sound effects and / or music
 type AudioSystem struct {
+	// Listener is where positional audio is heard from. When nil, the center of the camera is used.
+	Listener *SpaceComponent
+
 	entities []audioEntity
+	camera   *CameraSystem
 
 	bufsize            int
 	pauseCh, restartCh chan struct{}
 	playerCh           chan []*Player
+
+	// buffers reused by read, which is only called from the audio thread
+	playing  []*Player
+	samples  []int16
+	busMixes map[string][]int
+	busNames []string
+	out      []int
+	busBuf   []byte
 }
 
 // New is called when the AudioSystem is added to the world.
 func (a *AudioSystem) New(w *ecs.World) {
+	for _, system := range w.Systems() {
+		switch sys := system.(type) {
+		case *CameraSystem:
+			a.camera = sys
+		}
+	}
+
 	switch engo.CurrentBackEnd {
 	case engo.BackEndMobile:
 		a.bufsize = 12288
@@ -106,8 +170,10 @@ func (a *AudioSystem) New(w *ecs.World) {
 	a.restartCh = make(chan struct{}, 1)
 	a.playerCh = make(chan []*Player, 25)
 	loopClosedCh = make(chan struct{})
+	setMixerRamp()
 	go func() {
 		players := make([]*Player, 0)
+		buf := make([]byte, 2048)
 	loop:
 		for {
 			select {
@@ -117,7 +183,6 @@ func (a *AudioSystem) New(w *ecs.World) {
 				<-a.restartCh
 			case players = <-a.playerCh:
 			default:
-				buf := make([]byte, 2048)
 				a.read(buf, players)
 
 				if _, err := otoPlayer.Write(buf); err != nil {
@@ -127,7 +192,7 @@ func (a *AudioSystem) New(w *ecs.World) {
 		}
 		loopClosedCh <- struct{}{}
 	}()
-	masterVolume = 1
+	SetMasterVolume(1)
 }
 
 // Add adds an entity to the AudioSystem
@@ -157,8 +222,24 @@ func (a *AudioSystem) Remove(basic ecs.BasicEntity) {
 	}
 }
 
-// Update doesn't do anything since audio is run on it's own thread
+// EntityCount returns the number of entities the AudioSystem holds.
+func (a *AudioSystem) EntityCount() int {
+	return len(a.entities)
+}
+
+// Update updates positional audio and passes the playing players to the audio thread.
 func (a *AudioSystem) Update(dt float32) {
+	a.spatialize()
+
+	for _, e := range a.entities {
+		if e.Player == nil {
+			continue
+		}
+		if end := e.Player.takeEnded(); end != 0 {
+			e.Player.notifyEnded(end)
+		}
+	}
+
 	if len(a.playerCh) >= 25 { //if the channel is full just return so we don't block the update loop
 		return
 	}
@@ -171,46 +252,98 @@ func (a *AudioSystem) Update(dt float32) {
 	a.playerCh <- players
 }
 
+// spatialize sets the gain and pan of the players of positional audio.
+func (a *AudioSystem) spatialize() {
+	var listener engo.Point
+	switch {
+	case a.Listener != nil:
+		listener = a.Listener.Center()
+	case a.camera != nil:
+		listener = engo.Point{X: a.camera.X(), Y: a.camera.Y()}
+	}
+
+	for _, e := range a.entities {
+		if e.Space == nil || e.Player == nil {
+			continue
+		}
+		gain, pan := spatialize(listener, e.Space.Center(), e.MaxDistance, e.Rolloff)
+		e.Player.gain.SetGain(float64(gain))
+		e.Player.pan.SetPan(float64(pan))
+	}
+}
+
 // Read reads from all the currently playing entities and combines them into a
 // single stream that is passed to the oto player.
 func (a *AudioSystem) read(b []byte, players []*Player) (int, error) {
 	l := len(b)
 	l &= mask
 
-	if len(players) == 0 {
-		copy(b, make([]byte, l))
+	// Players which were paused or stopped since the last update are silenced right away
+	a.playing = a.playing[:0]
+	for _, player := range players {
+		if player.isPlaying {
+			a.playing = append(a.playing, player)
+		}
+	}
+	players = a.playing
+
+	// Buses with effects are processed even when nothing is played through them, so echoes and reverberation fade out
+	a.busNames = appendEffectBuses(a.busNames[:0])
+	if len(players) == 0 && len(a.busNames) == 0 {
+		for i := range b[:l] {
+			b[i] = 0
+		}
 		return l, nil
 	}
+	for _, name := range a.busNames {
+		zeroInts(a.busMix(name, l/2))
+	}
 
-	b16s := [][]int16{}
+	// Players are mixed per bus, so the volume of each bus can be applied once
+	if cap(a.samples) < l/2 {
+		a.samples = make([]int16, l/2)
+	}
 	for _, player := range players {
-		buf, err := player.bufferToInt16(l)
+		buf, err := player.bufferToInt16(a.samples[:l/2])
 		if err != nil {
 			return 0, err
 		}
-		b16s = append(b16s, buf)
+		if !a.mixing(player.Bus) {
+			zeroInts(a.busMix(player.Bus, l/2))
+			a.busNames = append(a.busNames, player.Bus)
+		}
+		mix := a.busMixes[player.Bus]
+		for i, x := range buf {
+			mix[i] += int(x)
+		}
 	}
-	for i := 0; i < l/2; i++ {
-		x := 0
-		for _, b16 := range b16s {
-			x += int(b16[i])
+
+	if cap(a.out) < l/2 {
+		a.out = make([]int, l/2)
+		a.busBuf = make([]byte, l)
+	}
+	out, busBuf := zeroInts(a.out[:l/2]), a.busBuf[:l]
+	for _, name := range a.busNames {
+		clipInt16(busBuf, a.busMixes[name])
+		if name != "" {
+			bus(name).Process(busBuf)
 		}
-		if x > (1<<15)-1 {
-			x = (1 << 15) - 1
+		for _, e := range busEffects(name) {
+			e.Process(busBuf)
 		}
-		if x < -(1 << 15) {
-			x = -(1 << 15)
+		for i := range out {
+			out[i] += int(int16(busBuf[2*i]) | int16(busBuf[2*i+1])<<8)
 		}
-		b[2*i] = byte(x)
-		b[2*i+1] = byte(x >> 8)
 	}
+	clipInt16(b[:l], out)
+	mixer.master.Process(b[:l])
 
 	for _, player := range players {
 		if player.eof() {
 			if player.Repeat {
 				player.Rewind()
 			} else {
-				player.Pause()
+				player.finish()
 			}
 		}
 	}
@@ -218,6 +351,37 @@ func (a *AudioSystem) read(b []byte, players []*Player) (int, error) {
 	return l, nil
 }
 
+// mixing returns whether the named bus is mixed in the current read.
+func (a *AudioSystem) mixing(name string) bool {
+	for _, n := range a.busNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// busMix returns the buffer of n samples the players of the named bus are mixed in, which is reused by every read.
+func (a *AudioSystem) busMix(name string, n int) []int {
+	if a.busMixes == nil {
+		a.busMixes = make(map[string][]int)
+	}
+	mix := a.busMixes[name]
+	if cap(mix) < n {
+		mix = make([]int, n)
+	}
+	a.busMixes[name] = mix[:n]
+	return mix[:n]
+}
+
+// zeroInts sets the samples to 0, and returns them.
+func zeroInts(samples []int) []int {
+	for i := range samples {
+		samples[i] = 0
+	}
+	return samples
+}
+
 // Close closes the AudioSystem's loop. After this is called the AudioSystem
 // can no longer play audio.
 // Blocks until loop actually closes.
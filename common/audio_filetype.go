@@ -2,12 +2,14 @@ package common
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 
 	"github.com/klopsch/engo"
+	"github.com/klopsch/engo/common/internal/decode/convert"
 	"github.com/klopsch/engo/common/internal/decode/mp3"
 	"github.com/klopsch/engo/common/internal/decode/vorbis"
 	"github.com/klopsch/engo/common/internal/decode/wav"
@@ -26,44 +28,36 @@ func (a *audioLoader) Load(url string, data io.Reader) error {
 		return err
 	}
 
-	audioBuffer := bytes.NewReader(audioBytes)
+	player, err := decodeAudio(getExt(url), &readSeekCloserBuffer{bytes.NewReader(audioBytes)}, url)
+	if err != nil && err != errUnknownAudioFormat {
+		return err
+	}
 
-	var player *Player
-	switch getExt(url) {
-	case ".wav":
-		d, err := wav.Decode(&readSeekCloserBuffer{audioBuffer}, SampleRate)
-		if err != nil {
-			return err
-		}
+	a.audios[url] = player
+	return nil
+}
 
-		player, err = newPlayer(d, url)
-		if err != nil {
-			return err
-		}
-	case ".mp3":
-		d, err := mp3.Decode(&readSeekCloserBuffer{audioBuffer}, SampleRate)
-		if err != nil {
-			return err
-		}
+// errUnknownAudioFormat is returned by decodeAudio for extensions of formats which can't be decoded.
+var errUnknownAudioFormat = errors.New("unknown audio format")
 
-		player, err = newPlayer(d, url)
-		if err != nil {
-			return err
-		}
+// decodeAudio creates a Player decoding src as the format of the extension ext.
+func decodeAudio(ext string, src convert.ReadSeekCloser, url string) (*Player, error) {
+	var d convert.ReadSeekCloser
+	var err error
+	switch ext {
+	case ".wav":
+		d, err = wav.Decode(src, SampleRate)
+	case ".mp3":
+		d, err = mp3.Decode(src, SampleRate)
 	case ".ogg":
-		d, err := vorbis.Decode(&readSeekCloserBuffer{audioBuffer}, SampleRate)
-		if err != nil {
-			return err
-		}
-
-		player, err = newPlayer(d, url)
-		if err != nil {
-			return err
-		}
+		d, err = vorbis.Decode(src, SampleRate)
+	default:
+		return nil, errUnknownAudioFormat
 	}
-
-	a.audios[url] = player
-	return nil
+	if err != nil {
+		return nil, err
+	}
+	return newPlayer(d, url)
 }
 
 // Load removes the preloaded audio file from the cache
@@ -0,0 +1,151 @@
+package common
+
+import (
+	"log"
+	"sync"
+
+	"github.com/klopsch/engo/common/internal/decode/convert"
+)
+
+// volumeRamp is how long volume changes of the mixer take, in seconds, so they don't click.
+const volumeRamp = 0.01
+
+// audioEffect processes the mixed audio of a bus in place.
+type audioEffect interface {
+	Process(b []uint8)
+}
+
+// mixer holds the master volume, and the volumes and effects of the buses players are played through. Its volume
+// changes are ramped over ramp frames, which is set from the SampleRate when an AudioSystem is created.
+var mixer = struct {
+	sync.Mutex
+	master  *convert.Gain
+	buses   map[string]*convert.Gain
+	effects map[string][]audioEffect
+	ramp    int
+}{
+	master:  convert.NewGain(nil, 1),
+	buses:   make(map[string]*convert.Gain),
+	effects: make(map[string][]audioEffect),
+}
+
+// newMixerGain creates the gain of a bus. The mixer has to be locked.
+func newMixerGain() *convert.Gain {
+	g := convert.NewGain(nil, 1)
+	g.SetRamp(mixer.ramp)
+	return g
+}
+
+// setMixerRamp sets how long volume changes of the mixer take from the SampleRate, which can be changed until the
+// AudioSystem is created.
+func setMixerRamp() {
+	mixer.Lock()
+	defer mixer.Unlock()
+	mixer.ramp = int(float64(SampleRate) * volumeRamp)
+	mixer.master.SetRamp(mixer.ramp)
+	for _, g := range mixer.buses {
+		g.SetRamp(mixer.ramp)
+	}
+}
+
+// bus returns the gain of the named bus, creating it if needed.
+func bus(name string) *convert.Gain {
+	mixer.Lock()
+	defer mixer.Unlock()
+	g, ok := mixer.buses[name]
+	if !ok {
+		g = newMixerGain()
+		mixer.buses[name] = g
+	}
+	return g
+}
+
+// SetMasterVolume sets the master volume. The masterVolume is multiplied by all
+// the other volumes to get the volume of each entity played.
+// Value must be between 0 and 1 or else it doesn't set.
+func SetMasterVolume(volume float64) {
+	if volume < 0 || volume > 1 {
+		log.Println("Master Volume can only be set between zero and one. Volume was not set.")
+		return
+	}
+	mixer.master.SetGain(volume)
+}
+
+// GetMasterVolume gets the master volume of the audio system.
+func GetMasterVolume() float64 {
+	return mixer.master.Gain()
+}
+
+// SetBusVolume sets the volume of the named bus, such as "music", "sfx" or "ui". It's multiplied by the volume of
+// every Player with that Bus, and by the master volume. The change is smoothed over a few milliseconds. Value must
+// be between 0 and 1 or else it doesn't set.
+func SetBusVolume(name string, volume float64) {
+	if volume < 0 || volume > 1 {
+		log.Println("Bus Volume can only be set between zero and one. Volume was not set.")
+		return
+	}
+	bus(name).SetGain(volume)
+}
+
+// GetBusVolume gets the volume of the named bus. Buses which were never set have a volume of 1.
+func GetBusVolume(name string) float64 {
+	return bus(name).Gain()
+}
+
+// AddBusEcho adds an echo to the named bus, repeating what's played through it after delay seconds, every repetition
+// multiplied by feedback, which should be below 1 for them to fade out. mix is how much of the echo is heard, from 0
+// for only the original audio to 1 for only the echo. The empty name is the bus of the players without a Bus.
+func AddBusEcho(name string, delay float32, feedback, mix float64) {
+	addBusEffect(name, convert.NewEcho(nil, int64(delay*float32(SampleRate)), feedback, mix))
+}
+
+// AddBusReverb adds reverberation to the named bus, to make what's played through it sound like it's played in a
+// room, such as a cave or a hall. roomSize ranges from 0 for a small room to 1 for a large hall, and mix is how much
+// of the reverberation is heard, from 0 to 1. The empty name is the bus of the players without a Bus.
+func AddBusReverb(name string, roomSize, mix float64) {
+	addBusEffect(name, convert.NewReverb(nil, roomSize, mix))
+}
+
+// ClearBusEffects removes the echoes and reverberation added to the named bus.
+func ClearBusEffects(name string) {
+	mixer.Lock()
+	delete(mixer.effects, name)
+	mixer.Unlock()
+}
+
+func addBusEffect(name string, e audioEffect) {
+	mixer.Lock()
+	mixer.effects[name] = append(mixer.effects[name], e)
+	mixer.Unlock()
+}
+
+// busEffects returns the effects of the named bus.
+func busEffects(name string) []audioEffect {
+	mixer.Lock()
+	defer mixer.Unlock()
+	return mixer.effects[name]
+}
+
+// appendEffectBuses appends the names of the buses with effects to names.
+func appendEffectBuses(names []string) []string {
+	mixer.Lock()
+	defer mixer.Unlock()
+	for name := range mixer.effects {
+		names = append(names, name)
+	}
+	return names
+}
+
+// clipInt16 writes the samples to b as 16-bit little endian, clipping them to the range of int16.
+func clipInt16(b []byte, samples []int) {
+	for i, x := range samples {
+		if x > (1<<15)-1 {
+			x = (1 << 15) - 1
+		}
+		if x < -(1 << 15) {
+			x = -(1 << 15)
+		}
+		b[2*i] = byte(x)
+		b[2*i+1] = byte(x >> 8)
+	}
+}
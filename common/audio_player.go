@@ -5,6 +5,7 @@ import (
 	"io"
 	"log"
 	"runtime"
+	"sync/atomic"
 	"time"
 
 	"github.com/klopsch/engo"
@@ -16,10 +17,41 @@ import (
 // change the sample rate, you must do so BEFORE adding the audio system to the world.
 var SampleRate = 44100
 
+// meterWindow is how long the audio measured by the Peak and RMS of a Player is, in seconds.
+const meterWindow = 0.05
+
+// PlaybackEnd is how the playing of a Player ended.
+type PlaybackEnd int
+
+const (
+	// PlaybackFinished is when the player played to the end, and won't repeat or loop.
+	PlaybackFinished PlaybackEnd = iota + 1
+	// PlaybackStopped is when the player was stopped with Stop before reaching the end.
+	PlaybackStopped
+)
+
 // Player holds the underlying audio data and plays/pauses/stops/rewinds/seeks it.
 type Player struct {
 	isPlaying bool
-	Repeat    bool
+	// Repeat rewinds the player once it has finished.
+	//
+	// Deprecated: Use SetLoop, which loops without a gap.
+	Repeat bool
+	// Bus is the name of the mixer bus the player is played through, such as "music" or "sfx". Its volume is set
+	// with SetBusVolume. Players without a Bus are only affected by the master volume.
+	Bus string
+	// OnFinish is called by the AudioSystem when the player has played to the end, and won't repeat or loop. The
+	// player is then stopped, so Play plays it again from the start.
+	OnFinish func()
+	// OnEnd is called by the AudioSystem when the playback ended, either because the player has played to the end,
+	// like for OnFinish, or because it was stopped with Stop before that. The PlaybackEnd tells them apart.
+	OnEnd func(PlaybackEnd)
+
+	ended   int32 // a PlaybackEnd set by the audio thread or Stop, and handled by the AudioSystem
+	started bool  // whether the player was played since it was last stopped
+	done    chan PlaybackEnd
+
+	loop *convert.Loop
 
 	src        convert.ReadSeekCloser
 	url        string
@@ -30,6 +62,12 @@ type Player struct {
 	pos    int64
 	volume float64
 
+	// gain and pan are set by the AudioSystem for positional audio
+	gain *convert.Gain
+	pan  *convert.Pan
+	// meter measures the audio as it's played
+	meter *convert.Meter
+
 	closeCh         chan struct{}
 	closedCh        chan struct{}
 	readLoopEndedCh chan struct{}
@@ -56,12 +94,17 @@ func (p *Player) URL() string {
 }
 
 func newPlayer(src convert.ReadSeekCloser, url string) (*Player, error) {
+	loop := convert.NewLoop(src, 0)
 	p := &Player{
-		src:             src,
+		src:             loop,
+		loop:            loop,
 		url:             url,
 		sampleRate:      SampleRate,
 		buf:             []byte{},
 		volume:          1,
+		gain:            convert.NewGain(nil, 1),
+		pan:             convert.NewPan(nil, 0),
+		meter:           convert.NewMeter(nil, int(float64(SampleRate)*meterWindow)),
 		closeCh:         make(chan struct{}),
 		closedCh:        make(chan struct{}),
 		readLoopEndedCh: make(chan struct{}),
@@ -101,9 +144,12 @@ func (p *Player) Close() error {
 	}
 }
 
-func (p *Player) bufferToInt16(lengthInBytes int) ([]int16, error) {
+func (p *Player) bufferToInt16(buf []int16) ([]int16, error) {
+	for i := range buf {
+		buf[i] = 0
+	}
 	select {
-	case p.proceedCh <- make([]int16, lengthInBytes/2):
+	case p.proceedCh <- buf:
 		r := <-p.proceededCh
 		return r.buf, r.err
 	case <-p.readLoopEndedCh:
@@ -114,6 +160,7 @@ func (p *Player) bufferToInt16(lengthInBytes int) ([]int16, error) {
 // Play plays the player's audio.
 func (p *Player) Play() {
 	p.isPlaying = true
+	p.started = true
 }
 
 func (p *Player) readLoop() {
@@ -138,6 +185,7 @@ func (p *Player) readLoop() {
 			p.buf = nil
 			p.pos = pos
 			p.srcEOF = false
+			p.meter.Reset()
 			p.seekedCh <- err
 			t = time.After(time.Millisecond)
 			break
@@ -197,6 +245,10 @@ func (p *Player) readLoop() {
 			if l > len(p.buf) {
 				l = len(p.buf)
 			}
+			// Positional audio is applied while playing, so it doesn't lag behind the buffered audio
+			p.gain.Process(p.buf[:l])
+			p.pan.Process(p.buf[:l])
+			p.meter.Process(p.buf[:l])
 			for i := 0; i < l/2; i++ {
 				buf[i] = int16(p.buf[2*i]) | (int16(p.buf[2*i+1]) << 8)
 				buf[i] = int16(float64(buf[i]) * p.volume)
@@ -235,6 +287,20 @@ func (p *Player) eof() bool {
 	return r
 }
 
+// Peak returns the highest amplitude of the left and right channel of the audio the player played in the last 50
+// milliseconds, from 0 to 1, such as for music visualizers. It includes positional audio, but not the volume of the
+// player, its bus or the master volume. It's safe to call while the player is playing.
+func (p *Player) Peak() (left, right float64) {
+	return p.meter.Peak()
+}
+
+// RMS returns the root mean square amplitude of the left and right channel of the audio the player played in the
+// last 50 milliseconds, from 0 to 1, which follows the loudness as it's heard better than Peak, such as for
+// lip-sync. Like Peak, it doesn't include the volumes.
+func (p *Player) RMS() (left, right float64) {
+	return p.meter.RMS()
+}
+
 // IsPlaying returns boolean indicating whether the player is playing.
 func (p *Player) IsPlaying() bool {
 	return p.isPlaying
@@ -261,17 +327,101 @@ func (p *Player) Seek(offset time.Duration) error {
 	}
 }
 
-// Pause pauses the playing.
+// Pause pauses the playing. The position is kept, so Play continues from where it was paused.
 func (p *Player) Pause() {
 	p.isPlaying = false
 }
 
+// Stop stops the playing immediately, and rewinds the player to the start. If it was played since it was last
+// stopped, its playback ends with PlaybackStopped.
+//
+// Stop returns error when seeking the source stream returns error.
+func (p *Player) Stop() error {
+	if p.started {
+		atomic.StoreInt32(&p.ended, int32(PlaybackStopped))
+	}
+	return p.stop()
+}
+
+// stop stops the playing and rewinds the player to the start.
+func (p *Player) stop() error {
+	p.isPlaying = false
+	p.started = false
+	return p.Rewind()
+}
+
+// SetLoop makes the player loop forever, without a gap between repetitions, or stops it from looping. It replaces
+// the count set with SetRepeat. Stop or Pause the player to stop it immediately, or call SetLoop(false) to stop once
+// the current repetition has finished.
+func (p *Player) SetLoop(loop bool) {
+	if loop {
+		p.loop.SetCount(-1)
+	} else {
+		p.loop.SetCount(0)
+	}
+}
+
+// Looping returns whether the player loops forever.
+func (p *Player) Looping() bool {
+	return p.loop.Count() < 0
+}
+
+// SetRepeat makes the player play count more times after the current one, without a gap between repetitions. It
+// replaces SetLoop.
+func (p *Player) SetRepeat(count int) {
+	if count < 0 {
+		count = 0
+	}
+	p.loop.SetCount(count)
+}
+
+// finish stops the player once it has played to the end. It's called by the audio thread.
+func (p *Player) finish() {
+	p.stop()
+	atomic.StoreInt32(&p.ended, int32(PlaybackFinished))
+}
+
+// takeEnded returns how the playback ended since it was last called, or 0 if it didn't.
+func (p *Player) takeEnded() PlaybackEnd {
+	return PlaybackEnd(atomic.SwapInt32(&p.ended, 0))
+}
+
+// Done returns a channel receiving how the playback ended, every time it does. The AudioSystem sends to it during
+// its Update, on the goroutine of the game loop, and doesn't wait for it to be received: if the previous end wasn't
+// received yet, the new one is dropped.
+func (p *Player) Done() <-chan PlaybackEnd {
+	if p.done == nil {
+		p.done = make(chan PlaybackEnd, 1)
+	}
+	return p.done
+}
+
+// notifyEnded notifies that the playback ended, through OnFinish, OnEnd and Done.
+func (p *Player) notifyEnded(end PlaybackEnd) {
+	if end == PlaybackFinished && p.OnFinish != nil {
+		p.OnFinish()
+	}
+	if p.OnEnd != nil {
+		p.OnEnd(end)
+	}
+	if p.done != nil {
+		select {
+		case p.done <- end:
+		default:
+		}
+	}
+}
+
 // Current returns the current position.
 func (p *Player) Current() time.Duration {
 	sample := int64(0)
 	p.sync(func() {
 		sample = p.pos / bytesPerSample / channelNum
 	})
+	if length := p.loop.Length() / bytesPerSample / channelNum; length > 0 {
+		// Looping keeps reading, so the position has to wrap around
+		sample %= length
+	}
 	return time.Duration(sample) * time.Second / time.Duration(p.sampleRate)
 }
 
@@ -294,24 +444,6 @@ func (p *Player) SetVolume(volume float64) {
 	}
 
 	p.sync(func() {
-		p.volume = volume * masterVolume
+		p.volume = volume
 	})
 }
-
-var masterVolume float64
-
-// SetMasterVolume sets the master volume. The masterVolume is multiplied by all
-// the other volumes to get the volume of each entity played.
-// Value must be between 0 and 1 or else it doesn't set.
-func SetMasterVolume(volume float64) {
-	if volume < 0 || volume > 1 {
-		log.Println("Master Volume can only be set between zero and one. Volume was not set.")
-		return
-	}
-	masterVolume = volume
-}
-
-// GetMasterVolume gets the master volume of the audio system.
-func GetMasterVolume() float64 {
-	return masterVolume
-}
@@ -0,0 +1,204 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/klopsch/engo/common/internal/decode/convert"
+	"github.com/klopsch/engo/common/internal/decode/mp3"
+	"github.com/klopsch/engo/common/internal/decode/vorbis"
+)
+
+// DefaultStreamWindow is how many bytes a BufferedReader created by StreamPlayer keeps behind the current position,
+// which the decoders can seek back within.
+const DefaultStreamWindow = 256 * 1024
+
+// StreamTimeout is how long StreamPlayerURL waits for the response of the server. Reading the stream itself doesn't
+// time out, since streams such as internet radio never end.
+const StreamTimeout = 10 * time.Second
+
+// streamClient is the http.Client of StreamPlayerURL, which times out after StreamTimeout while waiting for the
+// response.
+var streamClient = func() *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.ResponseHeaderTimeout = StreamTimeout
+	return &http.Client{Transport: t}
+}()
+
+// ErrSeekOutsideBuffer is returned by BufferedReader.Seek when seeking back further than the buffered window, or
+// relative to the end before it's known.
+var ErrSeekOutsideBuffer = errors.New("seek outside of the buffered window of the stream")
+
+// BufferedReader makes a stream which can't seek, such as a network connection, seekable for the audio decoders. It
+// keeps up to window bytes that were read before the current position in memory, so small backward seeks within
+// them work, and seeking forward reads ahead. Seeking further back, or relative to the end before the stream has
+// ended, returns ErrSeekOutsideBuffer.
+type BufferedReader struct {
+	src    io.Reader
+	window int
+
+	buf   []byte
+	start int64 // offset of buf[0] within the stream
+	pos   int64
+	err   error // the error the source returned, once it did
+}
+
+// NewBufferedReader wraps r, keeping window bytes behind the current position to seek back within. Closing it closes r
+// if it's an io.Closer.
+func NewBufferedReader(r io.Reader, window int) *BufferedReader {
+	return &BufferedReader{src: r, window: window}
+}
+
+// fill reads from the source until the buffer holds the data up to the offset end, or the source fails. It forgets
+// what's further behind the current position than the window as it goes, moving the rest to the front of the buffer,
+// so the buffer only grows until it holds the window and what's read ahead of it.
+func (b *BufferedReader) fill(end int64) {
+	for b.err == nil && b.start+int64(len(b.buf)) < end {
+		n := end - b.start - int64(len(b.buf))
+		if n < 4096 {
+			n = 4096
+		} else if n > 64*1024 {
+			n = 64 * 1024
+		}
+		l := len(b.buf)
+		if cap(b.buf) < l+int(n) {
+			buf := make([]byte, l, 2*(l+int(n)))
+			copy(buf, b.buf)
+			b.buf = buf
+		}
+		read, err := b.src.Read(b.buf[l : l+int(n)])
+		b.buf = b.buf[:l+read]
+		b.err = err
+
+		if drop := b.pos - int64(b.window) - b.start; drop > 0 {
+			if drop > int64(len(b.buf)) {
+				drop = int64(len(b.buf))
+			}
+			b.buf = append(b.buf[:0], b.buf[drop:]...)
+			b.start += drop
+		}
+	}
+}
+
+// Read implements the io.Reader interface.
+func (b *BufferedReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	b.fill(b.pos + 1)
+	end := b.start + int64(len(b.buf))
+	if b.pos >= end {
+		return 0, b.err
+	}
+	n := copy(p, b.buf[b.pos-b.start:])
+	b.pos += int64(n)
+	return n, nil
+}
+
+// Seek implements the io.Seeker interface.
+func (b *BufferedReader) Seek(offset int64, whence int) (int64, error) {
+	var target int64
+	switch whence {
+	case io.SeekStart:
+		target = offset
+	case io.SeekCurrent:
+		target = b.pos + offset
+	case io.SeekEnd:
+		if b.err != io.EOF {
+			return b.pos, fmt.Errorf("%w: the length of the stream isn't known yet", ErrSeekOutsideBuffer)
+		}
+		target = b.start + int64(len(b.buf)) + offset
+	default:
+		return b.pos, fmt.Errorf("invalid whence %d", whence)
+	}
+	if target < 0 {
+		return b.pos, fmt.Errorf("negative position %d", target)
+	}
+	if target < b.start {
+		return b.pos, fmt.Errorf("%w: %d is before %d", ErrSeekOutsideBuffer, target, b.start)
+	}
+
+	b.pos = target
+	b.fill(target)
+	if end := b.start + int64(len(b.buf)); b.pos > end {
+		b.pos = end
+	}
+	return b.pos, nil
+}
+
+// Close closes the source if it's an io.Closer, and releases the buffer.
+func (b *BufferedReader) Close() error {
+	b.buf = nil
+	if c, ok := b.src.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// StreamPlayer creates a Player which plays the audio decoded from r while it's being read, such as from a network
+// connection, instead of reading it all up front like Files.Load does. format is the extension of the format of the
+// audio, such as ".ogg". The Player isn't a resource of engo.Files, close it once it's not used anymore.
+//
+// MP3 and Ogg/Vorbis streams are decoded strictly in order, so they may be endless like internet radio, but their
+// Player can't Seek, Rewind or loop. WAV streams are read through a BufferedReader.
+func StreamPlayer(r io.Reader, format string) (*Player, error) {
+	p, err := decodeStream(format, r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to stream %s audio: %s", format, err)
+	}
+	return p, nil
+}
+
+// decodeStream creates a Player decoding the audio of the format from r while it's read.
+func decodeStream(format string, r io.Reader) (*Player, error) {
+	src := NewBufferedReader(r, DefaultStreamWindow)
+	var d convert.ReadSeekCloser
+	var err error
+	switch format {
+	case ".mp3":
+		d, err = mp3.DecodeStream(src, SampleRate)
+	case ".ogg":
+		d, err = vorbis.DecodeStream(src, SampleRate)
+	default:
+		return decodeAudio(format, src, "")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newPlayer(d, "")
+}
+
+// StreamPlayerURL creates a Player streaming the audio at the network url, such as internet radio. The format of the
+// audio is the extension of the path of the url. It fails if the server doesn't respond within StreamTimeout.
+func StreamPlayerURL(url string) (*Player, error) {
+	return StreamPlayerURLContext(context.Background(), url)
+}
+
+// StreamPlayerURLContext is like StreamPlayerURL, but cancelling ctx stops connecting to the url, and once the
+// Player is created, stops streaming from it.
+func StreamPlayerURLContext(ctx context.Context, url string) (*Player, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to stream %q: %s", url, err)
+	}
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to stream %q: %s", url, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unable to stream %q: %s", url, resp.Status)
+	}
+	p, err := StreamPlayer(resp.Body, path.Ext(resp.Request.URL.Path))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	p.url = url
+	return p, nil
+}
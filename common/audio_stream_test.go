@@ -0,0 +1,121 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/klopsch/engo/common/internal/decode/mp3"
+	"github.com/klopsch/engo/common/internal/decode/vorbis"
+	"github.com/stretchr/testify/assert"
+)
+
+// onlyReader hides the Seek method of the reader it wraps.
+type onlyReader struct {
+	io.Reader
+}
+
+func TestBufferedReader(t *testing.T) {
+	data := make([]byte, 20000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	b := NewBufferedReader(onlyReader{bytes.NewReader(data)}, 1000)
+
+	p := make([]byte, 100)
+	n, err := io.ReadFull(b, p)
+	assert.NoError(t, err)
+	assert.Equal(t, data[:n], p)
+
+	pos, err := b.Seek(-50, io.SeekCurrent)
+	assert.NoError(t, err, "Seeking back within the window should work")
+	assert.Equal(t, int64(50), pos)
+	io.ReadFull(b, p)
+	assert.Equal(t, data[50:150], p)
+
+	pos, err = b.Seek(10000, io.SeekStart)
+	assert.NoError(t, err, "Seeking forward should read ahead")
+	assert.Equal(t, int64(10000), pos)
+	io.ReadFull(b, p)
+	assert.Equal(t, data[10000:10100], p)
+
+	_, err = b.Seek(8000, io.SeekStart)
+	assert.True(t, errors.Is(err, ErrSeekOutsideBuffer), "Seeking back further than the window should fail")
+	_, err = b.Seek(-10, io.SeekEnd)
+	assert.True(t, errors.Is(err, ErrSeekOutsideBuffer), "Seeking from the end should fail until it's known")
+
+	pos, err = b.Seek(9500, io.SeekStart)
+	assert.NoError(t, err, "Seeking back within the window should work after seeking forward")
+	assert.Equal(t, int64(9500), pos)
+
+	rest, err := ioutil.ReadAll(b)
+	assert.NoError(t, err)
+	assert.Equal(t, data[9500:], rest)
+
+	pos, err = b.Seek(-10, io.SeekEnd)
+	assert.NoError(t, err, "Seeking from the end should work once the stream ended")
+	assert.Equal(t, int64(len(data)-10), pos)
+}
+
+func TestBufferedReaderReusesBuffer(t *testing.T) {
+	b := NewBufferedReader(onlyReader{bytes.NewReader(make([]byte, 1<<20))}, 1000)
+	n, err := io.Copy(ioutil.Discard, b)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1<<20), n)
+	assert.Less(t, cap(b.buf), 256*1024, "The buffer should be reused instead of growing with the stream")
+}
+
+func TestStreamPlayerURLContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := StreamPlayerURLContext(ctx, srv.URL+"/radio.mp3")
+	assert.Error(t, err, "Streaming should stop once the context is cancelled")
+}
+
+// Test decoding streams larger than the window, which the decoders would fail to measure by seeking
+func TestStreamDecodeLargerThanWindow(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/TripleShot.mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Repeat the frames after the ID3 tag until the file is larger than the window
+	large := append([]byte{}, data...)
+	for len(large) <= DefaultStreamWindow {
+		large = append(large, data[70:]...)
+	}
+
+	d, err := mp3.DecodeStream(NewBufferedReader(onlyReader{bytes.NewReader(large)}, DefaultStreamWindow), SampleRate)
+	if !assert.NoError(t, err, "Streams larger than the window should decode") {
+		return
+	}
+	streamed, err := ioutil.ReadAll(d)
+	assert.NoError(t, err)
+
+	full, err := mp3.Decode(&readSeekCloserBuffer{bytes.NewReader(large)}, SampleRate)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, full.Length(), int64(len(streamed)), "Streams should decode to the same audio as files")
+
+	ogg, err := ioutil.ReadFile("testdata/1.ogg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err = vorbis.DecodeStream(NewBufferedReader(onlyReader{bytes.NewReader(ogg)}, 1024), SampleRate)
+	if !assert.NoError(t, err, "Streams larger than the window should decode") {
+		return
+	}
+	streamed, err = ioutil.ReadAll(d)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, streamed)
+}
@@ -305,6 +305,39 @@ func TestAudioPlayerSeek(t *testing.T) {
 	}
 }
 
+func TestAudioPlayerLevel(t *testing.T) {
+	engo.Files.SetRoot("testdata")
+	if err := engo.Files.Load("1.ogg"); err != nil {
+		t.Errorf("Could not load file. Error was: %v\n", err)
+	}
+	p, err := LoadedPlayer("1.ogg")
+	if err != nil {
+		t.Fatalf("Could not get player. Error was: %v\n", err)
+	}
+	if l, r := p.Peak(); l != 0 || r != 0 {
+		t.Errorf("A player which didn't play should have no peak, got: %v, %v", l, r)
+	}
+
+	// The audio is measured as it's played, which takes until the player has buffered enough of it
+	var peak, rms float64
+	for i := 0; i < 200 && peak == 0; i++ {
+		if _, err := p.bufferToInt16(make([]int16, 2048)); err != nil {
+			t.Fatalf("Could not play the player. Error was: %v\n", err)
+		}
+		peak, _ = p.Peak()
+		rms, _ = p.RMS()
+		time.Sleep(time.Millisecond)
+	}
+	if peak <= 0 || peak > 1 || rms <= 0 || rms > peak {
+		t.Errorf("The level of the played audio was not measured. Peak: %v, RMS: %v", peak, rms)
+	}
+
+	p.Rewind()
+	if l, r := p.Peak(); l != 0 || r != 0 {
+		t.Errorf("Seeking should reset the level, got: %v, %v", l, r)
+	}
+}
+
 func TestAudioPlayerVolume(t *testing.T) {
 	engo.Files.SetRoot("testdata")
 	if err := engo.Files.Load("1.ogg"); err != nil {
@@ -374,3 +407,148 @@ func TestAudioMasterVolume(t *testing.T) {
 		t.Errorf("Logged value was not what was expected. Got: %v\n", buf.String())
 	}
 }
+
+func TestAudioSpatialize(t *testing.T) {
+	listener := engo.Point{X: 100, Y: 100}
+	cases := []struct {
+		name        string
+		pos         engo.Point
+		maxDistance float32
+		rolloff     Rolloff
+		gain, pan   float32
+	}{
+		{"at the listener", listener, 200, nil, 1, 0},
+		{"to the left", engo.Point{X: 0, Y: 100}, 200, nil, 0.5, -0.5},
+		{"with QuadraticRolloff", engo.Point{X: 0, Y: 100}, 200, QuadraticRolloff, 0.25, -0.5},
+		{"beyond MaxDistance", engo.Point{X: 400, Y: 100}, 200, nil, 0, 1},
+		{"without MaxDistance", engo.Point{X: 400, Y: 100}, 0, nil, 1, 1},
+	}
+	for _, c := range cases {
+		gain, pan := spatialize(listener, c.pos, c.maxDistance, c.rolloff)
+		if gain != c.gain || pan != c.pan {
+			t.Errorf("Audio %v: wanted gain %v and pan %v\nGot: %v and %v\n", c.name, c.gain, c.pan, gain, pan)
+		}
+	}
+}
+
+func TestAudioBusVolume(t *testing.T) {
+	if v := GetBusVolume("test-unset"); v != 1 {
+		t.Errorf("Buses should start at full volume. Wanted: %v\nGot: %v\n", 1, v)
+	}
+	SetBusVolume("test-music", 0.25)
+	if v := GetBusVolume("test-music"); v != 0.25 {
+		t.Errorf("Bus volume was not set. Wanted: %v\nGot: %v\n", 0.25, v)
+	}
+	SetBusVolume("test-music", 2)
+	if v := GetBusVolume("test-music"); v != 0.25 {
+		t.Errorf("Bus volume outside of [0, 1] should not be set. Wanted: %v\nGot: %v\n", 0.25, v)
+	}
+	if v := GetBusVolume("test-sfx"); v != 1 {
+		t.Errorf("Buses should have their own volume. Wanted: %v\nGot: %v\n", 1, v)
+	}
+}
+
+func TestAudioBusEffects(t *testing.T) {
+	AddBusEcho("test-cave", 0.25, 0.5, 0.5)
+	AddBusReverb("test-cave", 0.8, 0.3)
+	if n := len(busEffects("test-cave")); n != 2 {
+		t.Errorf("Effects were not added. Wanted: %v\nGot: %v\n", 2, n)
+	}
+
+	// Buses with effects are mixed without players, for their tails to fade out
+	a := &AudioSystem{}
+	b := make([]byte, 64)
+	if n, err := a.read(b, nil); err != nil || n != 64 {
+		t.Errorf("Buses with effects should be mixed without players. Wanted: %v\nGot: %v, %v\n", 64, n, err)
+	}
+
+	ClearBusEffects("test-cave")
+	if n := len(busEffects("test-cave")); n != 0 {
+		t.Errorf("Effects were not cleared. Wanted: %v\nGot: %v\n", 0, n)
+	}
+}
+
+func TestAudioReadReusesBuffers(t *testing.T) {
+	AddBusEcho("test-reuse", 0.01, 0.5, 0.5)
+	defer ClearBusEffects("test-reuse")
+	a := &AudioSystem{}
+	b := make([]byte, 64)
+	a.read(b, nil)
+	if allocs := testing.AllocsPerRun(10, func() { a.read(b, nil) }); allocs != 0 {
+		t.Errorf("Mixing should reuse its buffers. Wanted: %v allocations\nGot: %v\n", 0, allocs)
+	}
+}
+
+func TestAudioMixerRamp(t *testing.T) {
+	defer func(rate int) {
+		SampleRate = rate
+		setMixerRamp()
+	}(SampleRate)
+	SampleRate = 22050
+	setMixerRamp()
+	if want := int(float64(SampleRate) * volumeRamp); mixer.ramp != want {
+		t.Errorf("The ramp of the mixer should follow the SampleRate. Wanted: %v\nGot: %v\n", want, mixer.ramp)
+	}
+}
+
+func TestAudioClipInt16(t *testing.T) {
+	b := make([]byte, 6)
+	clipInt16(b, []int{100, 40000, -40000})
+	exp := []byte{100, 0, 255, 127, 0, 128}
+	for i := range exp {
+		if b[i] != exp[i] {
+			t.Errorf("Mixed samples should be clipped. Wanted: %v\nGot: %v\n", exp, b)
+			break
+		}
+	}
+}
+
+func TestAudioPlaybackEnd(t *testing.T) {
+	engo.Files.SetRoot("testdata")
+	if err := engo.Files.Load("1.ogg"); err != nil {
+		t.Errorf("Could not load file. Error was: %v\n", err)
+	}
+	p, err := LoadedPlayer("1.ogg")
+	if err != nil {
+		t.Errorf("Could not get player. Error was: %v\n", err)
+	}
+	var ends []PlaybackEnd
+	finished := 0
+	p.OnEnd = func(end PlaybackEnd) { ends = append(ends, end) }
+	p.OnFinish = func() { finished++ }
+	done := p.Done()
+
+	a := &AudioSystem{playerCh: make(chan []*Player, 25)}
+	a.Add(&ecs.BasicEntity{}, &AudioComponent{Player: p})
+
+	// Stopping a player that isn't playing doesn't end anything
+	p.Stop()
+	a.Update(1)
+	if len(ends) != 0 {
+		t.Errorf("Stopping a player that wasn't played should not end its playback. Got: %v\n", ends)
+	}
+
+	p.Play()
+	p.Stop()
+	a.Update(1)
+	if len(ends) != 1 || ends[0] != PlaybackStopped || finished != 0 {
+		t.Errorf("Playback should end as stopped. Wanted: %v\nGot: %v, %v finished\n", PlaybackStopped, ends, finished)
+	}
+	if end := <-done; end != PlaybackStopped {
+		t.Errorf("Done should receive the end of the playback. Wanted: %v\nGot: %v\n", PlaybackStopped, end)
+	}
+
+	// The audio thread finishes players which have played to the end
+	p.Play()
+	p.finish()
+	a.Update(1)
+	if len(ends) != 2 || ends[1] != PlaybackFinished || finished != 1 {
+		t.Errorf("Playback should end as finished. Wanted: %v\nGot: %v, %v finished\n", PlaybackFinished, ends, finished)
+	}
+	if end := <-done; end != PlaybackFinished {
+		t.Errorf("Done should receive the end of the playback. Wanted: %v\nGot: %v\n", PlaybackFinished, end)
+	}
+	if p.IsPlaying() {
+		t.Error("Player was playing after it finished.")
+	}
+}
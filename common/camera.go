@@ -2,6 +2,7 @@ package common
 
 import (
 	"log"
+	"math/rand"
 	"sync"
 	"time"
 
@@ -52,6 +53,19 @@ type cameraEntity struct {
 // one CameraSystem can be in a World at a time. If more than one CameraSystem
 // is added to the World, it will panic.
 type CameraSystem struct {
+	// MinZoom and MaxZoom limit the zoom level of this camera. When zero, the
+	// package-level MinZoom and MaxZoom are used.
+	MinZoom, MaxZoom float32
+	// Bounds is the area of the world the camera is allowed to show. The
+	// camera is kept within it on every update, and centered on it whenever
+	// the visible area is larger than the Bounds. An empty Bounds allows the
+	// camera to show anything.
+	Bounds engo.AABB
+	// Rand moves the camera while it shakes. It's seeded with the current time
+	// when the CameraSystem is set up if it's nil; set it beforehand to shake
+	// the same way every time, such as in tests or replays.
+	Rand *rand.Rand
+
 	x, y, z       float32
 	tracking      cameraEntity // The entity that is currently being followed
 	trackRotation bool         // Rotate with the entity
@@ -60,6 +74,13 @@ type CameraSystem struct {
 	angle float32
 
 	longTasks map[CameraAxis]*CameraMessage
+
+	followLerp float32   // How quickly the tracked entity is followed, see CameraFollowMessage
+	deadzone   engo.AABB // Where the tracked entity moves without moving the camera, see CameraFollowMessage
+
+	shake        *CameraShakeMessage
+	shakeElapsed time.Duration // How long the current shake has been going on
+	shakeOffset  engo.Point    // The offset currently applied to the position
 }
 
 // New initializes the CameraSystem.
@@ -76,15 +97,7 @@ func (cam *CameraSystem) New(w *ecs.World) {
 		warning("More than one CameraSystem was added to the World. The RenderSystem adds a CameraSystem if none exist when it's added.")
 	}
 
-	if CameraBounds.Max.X == 0 && CameraBounds.Max.Y == 0 {
-		CameraBounds.Max = engo.Point{X: engo.GameWidth(), Y: engo.GameHeight()}
-	}
-
-	cam.x = CameraBounds.Max.X / 2
-	cam.y = CameraBounds.Max.Y / 2
-	cam.z = 1
-
-	cam.longTasks = make(map[CameraAxis]*CameraMessage)
+	cam.setup()
 
 	engo.Mailbox.Listen("CameraMessage", func(msg engo.Message) {
 		cammsg, ok := msg.(CameraMessage)
@@ -109,15 +122,75 @@ func (cam *CameraSystem) New(w *ecs.World) {
 		}
 	})
 
+	engo.Mailbox.Listen("CameraShakeMessage", func(msg engo.Message) {
+		shake, ok := msg.(CameraShakeMessage)
+		if !ok {
+			return
+		}
+		cam.shake = &shake
+		cam.shakeElapsed = 0
+	})
+
+	engo.Mailbox.Listen("CameraFollowMessage", func(msg engo.Message) {
+		follow, ok := msg.(CameraFollowMessage)
+		if !ok {
+			return
+		}
+		if follow.Entity == nil || follow.Space == nil {
+			cam.tracking = cameraEntity{}
+			return
+		}
+		cam.FollowEntity(follow.Entity, follow.Space, follow.TrackRotation)
+		cam.followLerp = follow.Lerp
+		cam.deadzone = follow.Deadzone
+	})
+
+	engo.Mailbox.Listen("CameraZoomAtMessage", func(msg engo.Message) {
+		zoom, ok := msg.(CameraZoomAtMessage)
+		if !ok {
+			return
+		}
+		cam.ZoomAt(zoom.Factor, zoom.Point)
+	})
+
 	engo.Mailbox.Dispatch(NewCameraMessage{})
 }
 
+// setup centers the camera within the CameraBounds. It is separate from New, so
+// cameras which aren't part of the World, such as those only used for a
+// CameraViewport, don't listen to CameraMessages.
+func (cam *CameraSystem) setup() {
+	if CameraBounds.Max.X == 0 && CameraBounds.Max.Y == 0 {
+		CameraBounds.Max = engo.Point{X: engo.GameWidth(), Y: engo.GameHeight()}
+	}
+
+	cam.x = CameraBounds.Max.X / 2
+	cam.y = CameraBounds.Max.Y / 2
+	cam.z = 1
+
+	cam.longTasks = make(map[CameraAxis]*CameraMessage)
+	if cam.Rand == nil {
+		cam.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+}
+
 // Remove does nothing since the CameraSystem has only one entity, the camera itself.
 // This is here to implement the ecs.System interface.
 func (cam *CameraSystem) Remove(ecs.BasicEntity) {}
 
 // Update updates the camera. lLong tasks are attempted to update incrementally in batches.
 func (cam *CameraSystem) Update(dt float32) {
+	// Remove the shake of the previous frame before moving the camera
+	cam.x -= cam.shakeOffset.X
+	cam.y -= cam.shakeOffset.Y
+	cam.shakeOffset = engo.Point{}
+
+	cam.updatePosition(dt)
+	cam.clampToBounds()
+	cam.updateShake(dt)
+}
+
+func (cam *CameraSystem) updatePosition(dt float32) {
 	for axis, longTask := range cam.longTasks {
 		if !longTask.Incremental {
 			longTask.Incremental = true
@@ -164,20 +237,116 @@ func (cam *CameraSystem) Update(dt float32) {
 		return
 	}
 
-	cam.centerCam(cam.tracking.SpaceComponent.Position.X+cam.tracking.SpaceComponent.Width/2,
-		cam.tracking.SpaceComponent.Position.Y+cam.tracking.SpaceComponent.Height/2,
-		cam.z,
-	)
+	targetX := cam.tracking.SpaceComponent.Position.X + cam.tracking.SpaceComponent.Width/2
+	targetY := cam.tracking.SpaceComponent.Position.Y + cam.tracking.SpaceComponent.Height/2
+	if cam.deadzone.Min != cam.deadzone.Max {
+		targetX, targetY = cam.deadzoneTarget(targetX, targetY)
+	}
+	if cam.followLerp > 0 && cam.followLerp < 1 {
+		// Ease towards the target, scaling the factor so it doesn't depend on the frame rate
+		step := 1 - math.Pow(1-cam.followLerp, dt*60)
+		x, y := cam.x/engo.GetGlobalScale().X, cam.y/engo.GetGlobalScale().Y
+		targetX = x + (targetX-x)*step
+		targetY = y + (targetY-y)*step
+	}
+	cam.centerCam(targetX, targetY, cam.z)
 	if cam.trackRotation {
 		cam.rotateTo(cam.tracking.SpaceComponent.Rotation)
 	}
 }
 
+// deadzoneTarget returns where to center the camera for the point in the world to be within the deadzone on the
+// screen, moving it only as far as the point is outside of it.
+func (cam *CameraSystem) deadzoneTarget(x, y float32) (float32, float32) {
+	scale := engo.GetGlobalScale()
+	centerX, centerY := cam.x/scale.X, cam.y/scale.Y
+
+	screen := cam.WorldToScreen(engo.Point{X: x, Y: y})
+	clamped := engo.Point{
+		X: mgl32.Clamp(screen.X, cam.deadzone.Min.X, cam.deadzone.Max.X),
+		Y: mgl32.Clamp(screen.Y, cam.deadzone.Min.Y, cam.deadzone.Max.Y),
+	}
+	if clamped == screen {
+		return centerX, centerY
+	}
+
+	// Move the camera by as much as the point is past the edge of the deadzone
+	edge := cam.ScreenToWorld(clamped)
+	return centerX + x - edge.X, centerY + y - edge.Y
+}
+
+// clampToBounds moves the camera so the visible area stays within the Bounds.
+func (cam *CameraSystem) clampToBounds() {
+	if cam.Bounds.Min == cam.Bounds.Max {
+		return
+	}
+
+	viewW, viewH := viewSize()
+	center, half := cam.visibleArea(viewW, viewH)
+
+	x := clampCentered(center.X, cam.Bounds.Min.X+half.X, cam.Bounds.Max.X-half.X)
+	y := clampCentered(center.Y, cam.Bounds.Min.Y+half.Y, cam.Bounds.Max.Y-half.Y)
+
+	scale := engo.GetGlobalScale()
+	sin, cos := math.Sincos(cam.angle * math.Pi / 180)
+	x, y = x*scale.X, y*scale.Y
+	cam.x, cam.y = x*cos-y*sin, x*sin+y*cos
+}
+
+// visibleArea returns the center of the area of the world the camera shows in a view of viewW by viewH, and half its
+// size, including rotation.
+func (cam *CameraSystem) visibleArea(viewW, viewH float32) (center, half engo.Point) {
+	scale := engo.GetGlobalScale()
+	sin, cos := math.Sincos(cam.angle * math.Pi / 180)
+
+	half.X = (math.Abs(cos)*viewW + math.Abs(sin)*viewH) * cam.z / 2 / scale.X
+	half.Y = (math.Abs(sin)*viewW + math.Abs(cos)*viewH) * cam.z / 2 / scale.Y
+
+	// The camera position is rotated and scaled, so first find the center in the world
+	x, y := cam.x*cos+cam.y*sin, cam.y*cos-cam.x*sin
+	center = engo.Point{X: x / scale.X, Y: y / scale.Y}
+	return center, half
+}
+
+// clampCentered clamps v to [low, high], or returns the center of the two when
+// low is larger than high.
+func clampCentered(v, low, high float32) float32 {
+	if low > high {
+		return (low + high) / 2
+	}
+	return mgl32.Clamp(v, low, high)
+}
+
+func (cam *CameraSystem) updateShake(dt float32) {
+	if cam.shake == nil {
+		return
+	}
+
+	cam.shakeElapsed += time.Duration(dt * float32(time.Second))
+	if cam.shakeElapsed >= cam.shake.Duration {
+		cam.shake = nil
+		return
+	}
+
+	// The trauma decays linearly, while the shake itself decays quadratically,
+	// which feels more natural than a linear fall-off.
+	trauma := 1 - float32(cam.shakeElapsed)/float32(cam.shake.Duration)
+	amplitude := cam.shake.Amplitude * trauma * trauma
+	cam.shakeOffset = engo.Point{
+		X: amplitude * (2*cam.Rand.Float32() - 1) * engo.GetGlobalScale().X,
+		Y: amplitude * (2*cam.Rand.Float32() - 1) * engo.GetGlobalScale().Y,
+	}
+	cam.x += cam.shakeOffset.X
+	cam.y += cam.shakeOffset.Y
+}
+
 // FollowEntity sets the camera to follow the entity with BasicEntity basic
 // and SpaceComponent space.
 func (cam *CameraSystem) FollowEntity(basic *ecs.BasicEntity, space *SpaceComponent, trackRotation bool) {
 	cam.tracking = cameraEntity{basic, space}
 	cam.trackRotation = trackRotation
+	cam.followLerp = 0
+	cam.deadzone = engo.AABB{}
 }
 
 // X returns the X-coordinate of the location of the Camera.
@@ -200,6 +369,86 @@ func (cam *CameraSystem) Angle() float32 {
 	return cam.angle
 }
 
+// ScreenToWorld converts a point on the screen, in the same coordinates as
+// engo.Input.Mouse, to the point in the world that the camera renders there.
+// It takes the position, zoom and rotation of the camera into account, as
+// well as the black bars of the ScaleLetterbox mode.
+func (cam *CameraSystem) ScreenToWorld(p engo.Point) engo.Point {
+	scale := engo.GetGlobalScale()
+	viewW, viewH := viewSize()
+
+	// Screen to view, relative to the center of the screen
+	fx, fy := screenToGame(p)
+	x := (fx - 0.5) * viewW
+	y := (fy - 0.5) * viewH
+
+	// View to world, undoing the zoom, translation and rotation
+	x = x*cam.z + cam.x
+	y = y*cam.z + cam.y
+	sin, cos := math.Sincos(cam.angle * math.Pi / 180)
+	x, y = x*cos+y*sin, y*cos-x*sin
+
+	return engo.Point{X: x / scale.X, Y: y / scale.Y}
+}
+
+// WorldToScreen converts a point in the world to the point on the screen at
+// which the camera renders it, in the same coordinates as engo.Input.Mouse.
+// It is the inverse of ScreenToWorld.
+func (cam *CameraSystem) WorldToScreen(p engo.Point) engo.Point {
+	scale := engo.GetGlobalScale()
+	viewW, viewH := viewSize()
+
+	// World to view, applying the rotation, translation and zoom
+	x, y := p.X*scale.X, p.Y*scale.Y
+	sin, cos := math.Sincos(cam.angle * math.Pi / 180)
+	x, y = x*cos-y*sin, x*sin+y*cos
+	x = (x - cam.x) / cam.z
+	y = (y - cam.y) / cam.z
+
+	// View to screen
+	game := engo.GameViewport()
+	x = ((x/viewW+0.5)*(game.Max.X-game.Min.X) + game.Min.X) * engo.WindowWidth()
+	y = ((y/viewH+0.5)*(game.Max.Y-game.Min.Y) + game.Min.Y) * engo.WindowHeight()
+
+	return engo.Point{X: x / scale.X, Y: y / scale.Y}
+}
+
+// ScreenToHUD converts a point on the screen, in the same coordinates as
+// engo.Input.Mouse, to the point on the HUD drawn there. They're the same,
+// unless the game is scaled by the ScaleMode.
+func ScreenToHUD(p engo.Point) engo.Point {
+	scale := engo.GetGlobalScale()
+	viewW, viewH := viewSize()
+	fx, fy := screenToGame(p)
+	return engo.Point{X: fx * viewW / scale.X, Y: fy * viewH / scale.Y}
+}
+
+// screenToGame converts a point on the screen to fractions of the part of the
+// window the game is drawn in.
+func screenToGame(p engo.Point) (float32, float32) {
+	scale := engo.GetGlobalScale()
+	game := engo.GameViewport()
+	fx := (p.X*scale.X/engo.WindowWidth() - game.Min.X) / (game.Max.X - game.Min.X)
+	fy := (p.Y*scale.Y/engo.WindowHeight() - game.Min.Y) / (game.Max.Y - game.Min.Y)
+	return fx, fy
+}
+
+// ZoomAt multiplies the zoom level of the camera by factor, while moving the
+// camera so that the world point under the given screen point stays in place.
+// The screen point is in the same coordinates as engo.Input.Mouse.
+func (cam *CameraSystem) ZoomAt(factor float32, screenPoint engo.Point) {
+	before := cam.ScreenToWorld(screenPoint)
+	cam.zoomTo(cam.z * factor)
+	after := cam.ScreenToWorld(screenPoint)
+
+	// The camera position is rotated and scaled, so the world offset has to be as well
+	scale := engo.GetGlobalScale()
+	dx, dy := (before.X-after.X)*scale.X, (before.Y-after.Y)*scale.Y
+	sin, cos := math.Sincos(cam.angle * math.Pi / 180)
+	cam.moveX((dx*cos - dy*sin) / scale.X)
+	cam.moveY((dx*sin + dy*cos) / scale.Y)
+}
+
 func (cam *CameraSystem) moveAxis(axis CameraAxis, value float32) {
 	switch axis {
 	case XAxis:
@@ -255,15 +504,24 @@ func (cam *CameraSystem) rotate(value float32) {
 }
 
 func (cam *CameraSystem) moveToX(location float32) {
+	cam.shakeOffset.X = 0 // the shake is relative to the old position
 	cam.x = mgl32.Clamp(location*engo.GetGlobalScale().X, CameraBounds.Min.X*engo.GetGlobalScale().X, CameraBounds.Max.X*engo.GetGlobalScale().X)
 }
 
 func (cam *CameraSystem) moveToY(location float32) {
+	cam.shakeOffset.Y = 0 // the shake is relative to the old position
 	cam.y = mgl32.Clamp(location*engo.GetGlobalScale().Y, CameraBounds.Min.Y*engo.GetGlobalScale().Y, CameraBounds.Max.Y*engo.GetGlobalScale().Y)
 }
 
 func (cam *CameraSystem) zoomTo(zoomLevel float32) {
-	cam.z = mgl32.Clamp(zoomLevel, MinZoom, MaxZoom)
+	minZoom, maxZoom := MinZoom, MaxZoom
+	if cam.MinZoom > 0 {
+		minZoom = cam.MinZoom
+	}
+	if cam.MaxZoom > 0 {
+		maxZoom = cam.MaxZoom
+	}
+	cam.z = mgl32.Clamp(zoomLevel, minZoom, maxZoom)
 }
 
 func (cam *CameraSystem) rotateTo(rotation float32) {
@@ -314,6 +572,61 @@ func (NewCameraMessage) Type() string {
 	return "NewCameraMessage"
 }
 
+// CameraShakeMessage is a message that can be sent to the Camera to shake it.
+// The shake is applied on top of any other movement of the camera, and fades
+// out over the Duration.
+type CameraShakeMessage struct {
+	// Amplitude is the largest distance the camera is moved from its position
+	// at the start of the shake, in world units.
+	Amplitude float32
+	// Duration is how long the shake lasts.
+	Duration time.Duration
+}
+
+// Type implements the engo.Message interface.
+func (CameraShakeMessage) Type() string {
+	return "CameraShakeMessage"
+}
+
+// CameraFollowMessage is a message that can be sent to the Camera to have it
+// follow an entity. Sending it with a nil Entity or Space stops following.
+type CameraFollowMessage struct {
+	Entity        *ecs.BasicEntity
+	Space         *SpaceComponent
+	TrackRotation bool
+	// Lerp is the fraction of the distance to the entity the camera covers
+	// every 1/60th of a second, between 0 and 1. A Lerp of 0 or 1 moves the
+	// camera directly onto the entity, like FollowEntity does.
+	Lerp float32
+	// Deadzone is the area of the screen, in the same coordinates as
+	// engo.Input.Mouse, within which the entity moves without moving the
+	// camera, such as for platformers. Once the entity leaves it, the camera
+	// only follows as far as the entity is past its edge, easing there with
+	// Lerp. An empty Deadzone keeps the entity centered. The camera still
+	// stays within its Bounds.
+	Deadzone engo.AABB
+}
+
+// Type implements the engo.Message interface.
+func (CameraFollowMessage) Type() string {
+	return "CameraFollowMessage"
+}
+
+// CameraZoomAtMessage is a message that can be sent to the Camera to zoom while
+// keeping a point on the screen anchored, see CameraSystem.ZoomAt.
+type CameraZoomAtMessage struct {
+	// Factor is the amount the current zoom level is multiplied by.
+	Factor float32
+	// Point is the point on the screen, in the same coordinates as
+	// engo.Input.Mouse, which should stay in place.
+	Point engo.Point
+}
+
+// Type implements the engo.Message interface.
+func (CameraZoomAtMessage) Type() string {
+	return "CameraZoomAtMessage"
+}
+
 // KeyboardScroller is a System that allows for scrolling when certain keys are pressed.
 type KeyboardScroller struct {
 	ScrollSpeed                  float32
@@ -494,9 +807,11 @@ func (*MouseRotator) Priority() int { return MouseRotatorPriority }
 // interface.
 func (*MouseRotator) Remove(ecs.BasicEntity) {}
 
-// Update rotates the camera if the scroll wheel is pressed down.
+// Update rotates the camera if the scroll wheel is pressed down, unless the press
+// was consumed by a System updated before it.
 func (c *MouseRotator) Update(float32) {
-	if engo.Input.Mouse.Button == engo.MouseButtonMiddle && engo.Input.Mouse.Action == engo.Press {
+	if engo.Input.Mouse.Button == engo.MouseButtonMiddle && engo.Input.Mouse.Action == engo.Press &&
+		!engo.Input.MouseConsumed(engo.MouseButtonMiddle) {
 		c.pressed = true
 	}
 
@@ -3,8 +3,10 @@ package common
 import (
 	"bytes"
 	"log"
+	"math/rand"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/klopsch/ecs"
 	"github.com/klopsch/engo"
@@ -25,9 +27,9 @@ func initialize() {
 
 type CameraTestScene struct{}
 
-func (*CameraTestScene) Preload()         {}
-func (*CameraTestScene) Setup(*ecs.World) {}
-func (*CameraTestScene) Type() string     { return "CameraTestScene" }
+func (*CameraTestScene) Preload()           {}
+func (*CameraTestScene) Setup(engo.Updater) {}
+func (*CameraTestScene) Type() string       { return "CameraTestScene" }
 
 func TestCameraMoveX(t *testing.T) {
 	initialize()
@@ -180,3 +182,204 @@ func TestCameraAddOnlyOne(t *testing.T) {
 		t.Error("adding more than one CameraSystem did not write expected output to log")
 	}
 }
+
+func TestCameraViewports(t *testing.T) {
+	initialize()
+
+	rs := &RenderSystem{}
+	left, right := &CameraSystem{}, &CameraSystem{}
+	rs.AddCameraViewport(left, engo.AABB{Max: engo.Point{X: 0.5, Y: 1}})
+	rs.AddCameraViewport(right, engo.AABB{Min: engo.Point{X: 0.5}, Max: engo.Point{X: 1, Y: 1}})
+
+	assert.Len(t, rs.CameraViewports(), 2, "Both viewports should have been registered")
+	assert.Equal(t, CameraBounds.Max.X/2, left.X(), "Cameras outside the World should be centered when registered")
+	assert.Equal(t, float32(1), right.Z(), "Cameras outside the World should be initialized when registered")
+
+	rs.RemoveCameraViewport(left)
+	assert.Len(t, rs.CameraViewports(), 1, "Removing a camera should remove its viewport")
+	assert.Equal(t, right, rs.CameraViewports()[0].Camera, "Removing a camera should keep the other viewports")
+	assert.False(t, rs.newCamera, "The World's camera is not needed while viewports remain")
+
+	rs.RemoveCameraViewport(right)
+	assert.True(t, rs.newCamera, "Removing the last viewport should switch back to the World's camera")
+}
+
+type cameraConversionScene struct{}
+
+func (*cameraConversionScene) Preload()           {}
+func (*cameraConversionScene) Setup(engo.Updater) {}
+func (*cameraConversionScene) Type() string       { return "cameraConversionScene" }
+
+func TestCameraScreenToWorld(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:         true,
+		HeadlessMode:  true,
+		Width:         800,
+		Height:        600,
+		ScaleOnResize: true,
+	}, &cameraConversionScene{})
+	initialize()
+
+	center := cam.ScreenToWorld(engo.Point{X: 400, Y: 300})
+	assert.True(t, center.Equal(engo.Point{X: cam.X(), Y: cam.Y()}), "The center of the screen should be the position of the camera")
+
+	cam.moveToX(120)
+	cam.moveToY(80)
+	cam.zoomTo(1.5)
+	cam.rotateTo(30)
+
+	for _, p := range []engo.Point{{X: 10, Y: 20}, {X: 400, Y: 300}, {X: 123, Y: 456}, {X: 800, Y: 600}} {
+		world := cam.ScreenToWorld(p)
+		screen := cam.WorldToScreen(world)
+		assert.True(t, screen.Equal(p), "Converting %v to the world and back should give the same point, got %v", p, screen)
+	}
+}
+
+func TestCameraShake(t *testing.T) {
+	initialize()
+
+	x, y := cam.X(), cam.Y()
+	engo.Mailbox.Dispatch(CameraShakeMessage{Amplitude: 10, Duration: time.Second})
+
+	for i := 0; i < 9; i++ {
+		cam.Update(0.1)
+		assert.InDelta(t, x, cam.X(), 10, "The shake should stay within its amplitude")
+		assert.InDelta(t, y, cam.Y(), 10, "The shake should stay within its amplitude")
+	}
+
+	cam.Update(0.2)
+	assert.InDelta(t, x, cam.X(), 1e-3, "The camera should be back in place after the shake")
+	assert.InDelta(t, y, cam.Y(), 1e-3, "The camera should be back in place after the shake")
+}
+
+func TestCameraShakeSeeded(t *testing.T) {
+	shake := func() []engo.Point {
+		initialize()
+		cam.Rand = rand.New(rand.NewSource(1))
+		engo.Mailbox.Dispatch(CameraShakeMessage{Amplitude: 10, Duration: time.Second})
+		var positions []engo.Point
+		for i := 0; i < 5; i++ {
+			cam.Update(0.1)
+			positions = append(positions, engo.Point{X: cam.X(), Y: cam.Y()})
+		}
+		return positions
+	}
+	assert.Equal(t, shake(), shake(), "Shakes with the same seed should be the same")
+
+	// Shaking again restarts the shake
+	engo.Mailbox.Dispatch(CameraShakeMessage{Amplitude: 10, Duration: time.Second})
+	cam.Update(0.9)
+	assert.NotNil(t, cam.shake, "Shaking again should restart the shake")
+}
+
+func TestCameraSmoothFollow(t *testing.T) {
+	initialize()
+
+	basic := ecs.NewBasic()
+	space := &SpaceComponent{Position: engo.Point{X: 190, Y: 140}, Width: 20, Height: 20}
+	startX := cam.X()
+	engo.Mailbox.Dispatch(CameraFollowMessage{Entity: &basic, Space: space, Lerp: 0.5})
+
+	cam.Update(1.0 / 60)
+	assert.InDelta(t, startX+(200-startX)*0.5, cam.X(), 1e-3, "One frame at 60 fps should cover Lerp of the distance")
+
+	cam.Update(1.0 / 30)
+	assert.InDelta(t, startX+(200-startX)*0.875, cam.X(), 1e-3, "One frame at 30 fps should cover as much as two at 60 fps")
+
+	engo.Mailbox.Dispatch(CameraFollowMessage{})
+	space.Position.X = 0
+	x := cam.X()
+	cam.Update(1.0 / 60)
+	assert.Equal(t, x, cam.X(), "Sending an empty CameraFollowMessage should stop following")
+}
+
+func TestCameraZoomAt(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:         true,
+		HeadlessMode:  true,
+		Width:         800,
+		Height:        600,
+		ScaleOnResize: true,
+	}, &cameraConversionScene{})
+	initialize()
+	CameraBounds = engo.AABB{Min: engo.Point{X: -1000, Y: -1000}, Max: engo.Point{X: 1000, Y: 1000}}
+	cam.rotateTo(20)
+
+	anchor := engo.Point{X: 600, Y: 150}
+	before := cam.ScreenToWorld(anchor)
+
+	cam.ZoomAt(2, anchor)
+	assert.Equal(t, float32(2), cam.Z(), "ZoomAt should multiply the zoom level")
+	after := cam.ScreenToWorld(anchor)
+	assert.True(t, before.Equal(after), "The point under the anchor should stay in place, was %v now %v", before, after)
+
+	engo.Mailbox.Dispatch(CameraZoomAtMessage{Factor: 100, Point: anchor})
+	assert.Equal(t, MaxZoom, cam.Z(), "ZoomAt should respect the zoom limits")
+}
+
+func TestCameraLimits(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:         true,
+		HeadlessMode:  true,
+		Width:         200,
+		Height:        100,
+		ScaleOnResize: true,
+	}, &cameraConversionScene{})
+	initialize()
+
+	cam.MinZoom, cam.MaxZoom = 0.5, 2
+	cam.zoomTo(10)
+	assert.Equal(t, float32(2), cam.Z(), "The camera's MaxZoom should be used instead of the global one")
+	cam.zoomTo(0.1)
+	assert.Equal(t, float32(0.5), cam.Z(), "The camera's MinZoom should be used instead of the global one")
+
+	cam.zoomTo(1)
+	cam.Bounds = engo.AABB{Max: engo.Point{X: 300, Y: 300}}
+	cam.moveToX(10)
+	cam.moveToY(290)
+	cam.Update(0)
+	assert.Equal(t, float32(100), cam.X(), "The visible area should be kept within the Bounds")
+	assert.Equal(t, float32(250), cam.Y(), "The visible area should be kept within the Bounds")
+
+	cam.zoomTo(2)
+	cam.Update(0)
+	assert.Equal(t, float32(150), cam.X(), "The camera should be centered when the visible area is wider than the Bounds")
+	assert.Equal(t, float32(200), cam.Y(), "The visible area should be kept within the Bounds")
+}
+
+func TestCameraDeadzone(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:         true,
+		HeadlessMode:  true,
+		Width:         200,
+		Height:        100,
+		ScaleOnResize: true,
+	}, &cameraConversionScene{})
+	initialize()
+
+	basic := ecs.NewBasic()
+	space := &SpaceComponent{Position: engo.Point{X: 150, Y: 150}}
+	engo.Mailbox.Dispatch(CameraFollowMessage{
+		Entity:   &basic,
+		Space:    space,
+		Deadzone: engo.AABB{Min: engo.Point{X: 80, Y: 30}, Max: engo.Point{X: 120, Y: 70}},
+	})
+
+	space.Position.X = 165
+	cam.Update(1.0 / 60)
+	assert.Equal(t, float32(150), cam.X(), "The camera should not move while the entity is within the deadzone")
+
+	space.Position.X = 180
+	cam.Update(1.0 / 60)
+	assert.InDelta(t, 160, cam.X(), 1e-3, "The camera should follow as far as the entity is past the edge of the deadzone")
+	assert.Equal(t, float32(150), cam.Y(), "The camera should only follow along the axis the entity left the deadzone")
+
+	space.Position.X = 100
+	cam.Update(1.0 / 60)
+	assert.InDelta(t, 120, cam.X(), 1e-3, "The camera should follow the entity past the other edge of the deadzone")
+
+	cam.Bounds = engo.AABB{Max: engo.Point{X: 300, Y: 300}}
+	space.Position.X = 0
+	cam.Update(1.0 / 60)
+	assert.Equal(t, float32(100), cam.X(), "The camera following the entity should stay within the Bounds")
+}
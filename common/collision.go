@@ -1,6 +1,8 @@
 package common
 
 import (
+	"sort"
+
 	"github.com/klopsch/ecs"
 	"github.com/klopsch/engo"
 	"github.com/klopsch/engo/math"
@@ -19,15 +21,16 @@ type Shape struct {
 // Project projects the shape onto the given vector.
 func (s Shape) Project(p engo.Point, sc SpaceComponent) (min, max float32) {
 	s.PolygonEllipse()
+	pos := sc.rotatedPosition()
 	sin, cos := math.Sincos(sc.Rotation * math.Pi / 180)
 	l := engo.Line{
 		P1: engo.Point{
-			X: sc.Position.X + s.Lines[0].P1.X*cos - s.Lines[0].P1.Y*sin,
-			Y: sc.Position.Y + s.Lines[0].P1.Y*cos + s.Lines[0].P1.X*sin,
+			X: pos.X + s.Lines[0].P1.X*cos - s.Lines[0].P1.Y*sin,
+			Y: pos.Y + s.Lines[0].P1.Y*cos + s.Lines[0].P1.X*sin,
 		},
 		P2: engo.Point{
-			X: sc.Position.X + s.Lines[0].P2.X*cos - s.Lines[0].P2.Y*sin,
-			Y: sc.Position.Y + s.Lines[0].P2.Y*cos + s.Lines[0].P2.X*sin,
+			X: pos.X + s.Lines[0].P2.X*cos - s.Lines[0].P2.Y*sin,
+			Y: pos.Y + s.Lines[0].P2.Y*cos + s.Lines[0].P2.X*sin,
 		},
 	}
 	min = l.P1.X*p.X + l.P1.Y*p.Y
@@ -35,12 +38,12 @@ func (s Shape) Project(p engo.Point, sc SpaceComponent) (min, max float32) {
 	for _, line := range s.Lines {
 		l = engo.Line{
 			P1: engo.Point{
-				X: sc.Position.X + line.P1.X*cos - line.P1.Y*sin,
-				Y: sc.Position.Y + line.P1.Y*cos + line.P1.X*sin,
+				X: pos.X + line.P1.X*cos - line.P1.Y*sin,
+				Y: pos.Y + line.P1.Y*cos + line.P1.X*sin,
 			},
 			P2: engo.Point{
-				X: sc.Position.X + line.P2.X*cos - line.P2.Y*sin,
-				Y: sc.Position.Y + line.P2.Y*cos + line.P2.X*sin,
+				X: pos.X + line.P2.X*cos - line.P2.Y*sin,
+				Y: pos.Y + line.P2.Y*cos + line.P2.X*sin,
 			},
 		}
 		dot := l.P2.X*p.X + l.P2.Y*p.Y
@@ -101,6 +104,23 @@ type SpaceComponent struct {
 	Rotation float32 // angle in degrees for the rotation to apply clockwise.
 
 	hitboxes []Shape
+	// pivot is the point the box rotates about, as a fraction of its size, which the RenderSystem sets to the Origin
+	// of the RenderComponent. The box rotates about Position when it's zero.
+	pivot engo.Point
+}
+
+// rotatedPosition returns the position of the top-left corner of the box once rotated about its pivot, which is the
+// Position unless the box is rotated about another point than its top-left corner.
+func (sc SpaceComponent) rotatedPosition() engo.Point {
+	if sc.Rotation == 0 || sc.pivot == (engo.Point{}) {
+		return sc.Position
+	}
+	sin, cos := math.Sincos(sc.Rotation * math.Pi / 180)
+	x, y := sc.pivot.X*sc.Width, sc.pivot.Y*sc.Height
+	return engo.Point{
+		X: sc.Position.X + x - (x*cos - y*sin),
+		Y: sc.Position.Y + y - (y*cos + x*sin),
+	}
 }
 
 // AddShape adds a shape to the SpaceComponent for use as a hitbox. A SpaceComponent
@@ -124,8 +144,9 @@ func (sc *SpaceComponent) SetCenter(p engo.Point) {
 		return
 	}
 	sin, cos := math.Sincos(sc.Rotation * math.Pi / 180)
-	xDelta = (sc.Width*cos - sc.Height*sin) / 2
-	yDelta = (sc.Height*cos + sc.Width*sin) / 2
+	shift := sc.rotatedPosition()
+	xDelta = (sc.Width*cos-sc.Height*sin)/2 + shift.X - sc.Position.X
+	yDelta = (sc.Height*cos+sc.Width*sin)/2 + shift.Y - sc.Position.Y
 	sc.Position.X = p.X - xDelta
 	sc.Position.Y = p.Y - yDelta
 }
@@ -135,7 +156,7 @@ func (sc *SpaceComponent) SetCenter(p engo.Point) {
 func (sc *SpaceComponent) Center() engo.Point {
 	xDelta := sc.Width / 2
 	yDelta := sc.Height / 2
-	p := sc.Position
+	p := sc.rotatedPosition()
 	if sc.Rotation == 0 {
 		return engo.Point{X: p.X + xDelta, Y: p.Y + yDelta}
 	}
@@ -172,7 +193,8 @@ func (sc SpaceComponent) AABB() engo.AABB {
 	for i := 0; i < 4; i++ {
 		if corners[i].X < xMin {
 			xMin = corners[i].X
-		} else if corners[i].X > xMax {
+		}
+		if corners[i].X > xMax {
 			xMax = corners[i].X
 		}
 		if corners[i].Y < yMin {
@@ -189,8 +211,7 @@ func (sc SpaceComponent) AABB() engo.AABB {
 // Corners returns the location of the four corners of the rectangular plane defined by the `SpaceComponent`, taking
 // into account any possible rotation.
 func (sc SpaceComponent) Corners() (points [4]engo.Point) {
-	points[0].X = sc.Position.X
-	points[0].Y = sc.Position.Y
+	points[0] = sc.rotatedPosition()
 
 	sin, cos := math.Sincos(sc.Rotation * math.Pi / 180)
 
@@ -233,12 +254,13 @@ func (sc SpaceComponent) Contains(p engo.Point) bool {
 			Y: 1e10,
 		},
 	}
+	pos := sc.rotatedPosition()
 	sin, cos := math.Sincos(sc.Rotation * math.Pi / 180)
 	// test point for ellipse testing. It is rotated and translated such that the
 	// axis is that of the unrotated / translated AABB
 	testpoint := engo.Point{
-		X: (p.X-sc.Position.X)*cos + (p.Y-sc.Position.Y)*sin,
-		Y: (p.Y-sc.Position.Y)*cos - (p.X-sc.Position.X)*sin,
+		X: (p.X-pos.X)*cos + (p.Y-pos.Y)*sin,
+		Y: (p.Y-pos.Y)*cos - (p.X-pos.X)*sin,
 	}
 	i := 0
 	for _, hb := range sc.hitboxes {
@@ -254,12 +276,12 @@ func (sc SpaceComponent) Contains(p engo.Point) bool {
 		for _, line := range hb.Lines {
 			l := engo.Line{
 				P1: engo.Point{
-					X: sc.Position.X + line.P1.X*cos - line.P1.Y*sin,
-					Y: sc.Position.Y + line.P1.Y*cos + line.P1.X*sin,
+					X: pos.X + line.P1.X*cos - line.P1.Y*sin,
+					Y: pos.Y + line.P1.Y*cos + line.P1.X*sin,
 				},
 				P2: engo.Point{
-					X: sc.Position.X + line.P2.X*cos - line.P2.Y*sin,
-					Y: sc.Position.Y + line.P2.Y*cos + line.P2.X*sin,
+					X: pos.X + line.P2.X*cos - line.P2.Y*sin,
+					Y: pos.Y + line.P2.Y*cos + line.P2.X*sin,
 				},
 			}
 			if _, ok := engo.LineIntersection(l, testline); ok {
@@ -274,6 +296,80 @@ func (sc SpaceComponent) Contains(p engo.Point) bool {
 	return false
 }
 
+// Intersects tells whether the boxes of the two SpaceComponents overlap. Boxes that only touch at an edge don't
+// intersect. Rotated boxes are tested with the separating axis theorem, so they're exact rather than using their
+// AABB. Hitboxes added with AddShape are ignored, use Overlaps for those.
+func (sc *SpaceComponent) Intersects(other *SpaceComponent) bool {
+	if sc.Rotation == 0 && other.Rotation == 0 {
+		return IsIntersecting(sc.AABB(), other.AABB())
+	}
+	_, ok := boxTranslation(sc.Corners(), other.Corners())
+	return ok
+}
+
+// MinimumTranslation returns the shortest vector to move sc by so its box doesn't intersect the box of other
+// anymore, such as to push an entity out of a wall. It's the zero vector if they don't intersect. Like Intersects,
+// rotated boxes are separated along the axis of one of their sides, as found with the separating axis theorem, and
+// hitboxes are ignored.
+func (sc *SpaceComponent) MinimumTranslation(other *SpaceComponent) engo.Point {
+	if sc.Rotation == 0 && other.Rotation == 0 {
+		a, b := sc.AABB(), other.AABB()
+		if !IsIntersecting(a, b) {
+			return engo.Point{}
+		}
+		return MinimumTranslation(a, b)
+	}
+	mtv, _ := boxTranslation(sc.Corners(), other.Corners())
+	return mtv
+}
+
+// boxTranslation projects the corners of the boxes a and b onto the normals of their sides. If there's a gap on any
+// of them, the boxes don't intersect. Otherwise, it returns the shortest vector to move a by to separate them.
+func boxTranslation(a, b [4]engo.Point) (engo.Point, bool) {
+	axes := [4]engo.Point{
+		{X: a[1].X - a[0].X, Y: a[1].Y - a[0].Y},
+		{X: a[2].X - a[0].X, Y: a[2].Y - a[0].Y},
+		{X: b[1].X - b[0].X, Y: b[1].Y - b[0].Y},
+		{X: b[2].X - b[0].X, Y: b[2].Y - b[0].Y},
+	}
+
+	var mtv engo.Point
+	shortest := float32(math.MaxFloat32)
+	for _, side := range axes {
+		axis, length := side.Normalize()
+		if length == 0 {
+			continue
+		}
+		minA, maxA := projectCorners(a, axis)
+		minB, maxB := projectCorners(b, axis)
+
+		// How far a has to move along the axis, forwards or backwards, to leave b
+		forward, backward := maxB-minA, maxA-minB
+		if forward <= 0 || backward <= 0 {
+			return engo.Point{}, false
+		}
+		if forward < shortest {
+			shortest = forward
+			mtv = engo.Point{X: axis.X * forward, Y: axis.Y * forward}
+		}
+		if backward < shortest {
+			shortest = backward
+			mtv = engo.Point{X: -axis.X * backward, Y: -axis.Y * backward}
+		}
+	}
+	return mtv, true
+}
+
+// projectCorners returns the minimum and maximum of the corners projected onto the axis.
+func projectCorners(corners [4]engo.Point, axis engo.Point) (min, max float32) {
+	min, max = math.MaxFloat32, -math.MaxFloat32
+	for _, c := range corners {
+		d := engo.DotProduct(c, axis)
+		min, max = math.Min(min, d), math.Max(max, d)
+	}
+	return
+}
+
 // Overlaps tells whether the two given space components overlap with the given
 // tolerance. Uses hitboxes if available, then tries AABB.
 // Algorithm used is the [Separation of Axis](http://www.dyn4j.org/2010/01/sat)
@@ -495,6 +591,32 @@ type CollisionComponent struct {
 	Main, Group CollisionGroup
 	Extra       engo.Point
 	Collides    CollisionGroup
+
+	// Layer is the set of layers the entity is on, and Mask the set of layers it can collide with. Two entities are
+	// only tested against each other if a.Mask & (bitwise) b.Layer. A value of 0 means all layers, so entities that
+	// don't set them collide as before.
+	Layer, Mask uint32
+
+	// Swept enables continuous collision detection for the entity. Besides testing the current position, the movement
+	// since the previous Update is swept against the other entities, so fast entities can't pass through thin ones
+	// between frames. The sweep uses the AABB of the entities, and is more expensive than the regular test.
+	Swept bool
+}
+
+// layers returns the Layer of the component, where 0 means all layers.
+func (c *CollisionComponent) layers() uint32 {
+	if c.Layer == 0 {
+		return ^uint32(0)
+	}
+	return c.Layer
+}
+
+// mask returns the Mask of the component, where 0 means all layers.
+func (c *CollisionComponent) mask() uint32 {
+	if c.Mask == 0 {
+		return ^uint32(0)
+	}
+	return c.Mask
 }
 
 // CollisionMessage is sent whenever a collision is detected by the CollisionSystem.
@@ -502,6 +624,12 @@ type CollisionMessage struct {
 	Entity collisionEntity
 	To     collisionEntity
 	Groups CollisionGroup
+
+	// TimeOfImpact and Normal are only set when the collision was found by the swept test of a Swept entity.
+	// TimeOfImpact is the fraction of the movement of Entity since the previous Update at which it touched To, and
+	// Normal is the normal of the side of To that was hit.
+	TimeOfImpact float32
+	Normal       engo.Point
 }
 
 // CollisionGroup is intended to be used in bitwise comparisons
@@ -516,6 +644,22 @@ type collisionEntity struct {
 	*ecs.BasicEntity
 	*CollisionComponent
 	*SpaceComponent
+
+	// previous is the position of the SpaceComponent at the end of the previous Update
+	previous engo.Point
+}
+
+// aabb returns the AABB of the entity when it would be at the given position, including the Extra of the
+// CollisionComponent.
+func (e collisionEntity) aabb(position engo.Point) engo.AABB {
+	sc := *e.SpaceComponent
+	sc.Position = position
+	aabb := sc.AABB()
+	aabb.Min.X -= e.CollisionComponent.Extra.X / 2
+	aabb.Min.Y -= e.CollisionComponent.Extra.Y / 2
+	aabb.Max.X += e.CollisionComponent.Extra.X / 2
+	aabb.Max.Y += e.CollisionComponent.Extra.Y / 2
+	return aabb
 }
 
 // CollisionSystem is a system that detects collisions between entities, sends a message if collisions
@@ -525,12 +669,38 @@ type CollisionSystem struct {
 	// if a.Main & b.Group & sys.Solids{ Collisions are treated as solid.  }
 	Solids CollisionGroup
 
+	// CellSize is the size of the cells of the spatial hash used to find the entities that may collide. Only entities
+	// sharing a cell are tested against each other. If it's 0, every entity is tested against all other entities. A
+	// good CellSize is about twice the size of a typical entity.
+	CellSize float32
+
 	entities []collisionEntity
+	states   *entityStates
+
+	cells      map[collisionCell][]int
+	candidates []int
+	seen       []int
+	stamp      int
+}
+
+// collisionCell is the position of a cell in the spatial hash of the CollisionSystem.
+type collisionCell struct {
+	x, y int
+}
+
+// New initializes the CollisionSystem.
+func (c *CollisionSystem) New(w *ecs.World) {
+	c.states = worldEntityStates(w)
 }
 
 // Add adds an entity to the CollisionSystem. To be added, the entity has to have a basic, collision, and space component.
 func (c *CollisionSystem) Add(basic *ecs.BasicEntity, collision *CollisionComponent, space *SpaceComponent) {
-	c.entities = append(c.entities, collisionEntity{basic, collision, space})
+	c.entities = append(c.entities, collisionEntity{
+		BasicEntity:        basic,
+		CollisionComponent: collision,
+		SpaceComponent:     space,
+		previous:           space.Position,
+	})
 }
 
 // AddByInterface Provides a simple way to add an entity to the system that satisfies Collisionable. Any entity containing, BasicEntity,CollisionComponent, and SpaceComponent anonymously, automatically does this.
@@ -553,25 +723,43 @@ func (c *CollisionSystem) Remove(basic ecs.BasicEntity) {
 	}
 }
 
+// EntityCount returns the number of entities the CollisionSystem holds.
+func (c *CollisionSystem) EntityCount() int {
+	return len(c.entities)
+}
+
 // Update checks the entities for collision with eachother. Only Main entities are check for collision explicitly.
 // If one of the entities are solid, the SpaceComponent is adjusted so that the other entities don't pass through it.
 func (c *CollisionSystem) Update(dt float32) {
+	c.updateCells()
+
 	for i1, e1 := range c.entities {
 		if e1.CollisionComponent.Main == 0 {
 			//Main cannot pass bitwise comparison with any other items. Do not loop.
 			continue // with other entities
 		}
+		if c.states.disabled(e1.BasicEntity.ID()) {
+			e1.CollisionComponent.Collides = 0
+			continue
+		}
 
 		var collided CollisionGroup
 
-		for i2, e2 := range c.entities {
+		for _, i2 := range c.candidatesFor(i1) {
+			e2 := c.entities[i2]
 			if i1 == i2 {
 				continue // with other entities, because we won't collide with ourselves
 			}
+			if c.states.disabled(e2.BasicEntity.ID()) {
+				continue
+			}
 			cgroup := e1.CollisionComponent.Main & e2.CollisionComponent.Group
 			if cgroup == 0 {
 				continue //Items are not in a comparible group dont bother
 			}
+			if e1.CollisionComponent.mask()&e2.CollisionComponent.layers() == 0 {
+				continue //Items are not on layers that can collide
+			}
 
 			offsetA := engo.Point{X: e1.CollisionComponent.Extra.X / 2, Y: e1.CollisionComponent.Extra.Y / 2}
 			offsetB := engo.Point{X: e2.CollisionComponent.Extra.X / 2, Y: e2.CollisionComponent.Extra.Y / 2}
@@ -604,11 +792,114 @@ func (c *CollisionSystem) Update(dt float32) {
 				entityAABB.Min.Y -= offset.Y
 				entityAABB.Max.X += offset.X
 				entityAABB.Max.Y += offset.Y
+			} else if e1.CollisionComponent.Swept {
+				// Sweep the movement of e1 relative to e2, starting from where both were in the previous Update
+				delta := engo.Point{
+					X: e1.SpaceComponent.Position.X - e1.previous.X - (e2.SpaceComponent.Position.X - e2.previous.X),
+					Y: e1.SpaceComponent.Position.Y - e1.previous.Y - (e2.SpaceComponent.Position.Y - e2.previous.Y),
+				}
+				toi, normal, hit := SweptAABB(e1.aabb(e1.previous), delta, e2.aabb(e2.previous))
+				if !hit {
+					continue
+				}
+				if cgroup&c.Solids > 0 {
+					// Move e1 back to where it touched e2
+					e1.SpaceComponent.Position.X -= delta.X * (1 - toi)
+					e1.SpaceComponent.Position.Y -= delta.Y * (1 - toi)
+				}
+
+				collided = collided | cgroup
+				engo.Mailbox.Dispatch(CollisionMessage{Entity: e1, To: e2, Groups: cgroup, TimeOfImpact: toi, Normal: normal})
 			}
 		}
 
 		e1.CollisionComponent.Collides = collided
 	}
+
+	for i := range c.entities {
+		c.entities[i].previous = c.entities[i].SpaceComponent.Position
+	}
+}
+
+// sweptBounds returns the AABB of the entity covering both its previous and its current position.
+func (e collisionEntity) sweptBounds() engo.AABB {
+	aabb := e.aabb(e.SpaceComponent.Position)
+	if e.previous == e.SpaceComponent.Position {
+		return aabb
+	}
+	prev := e.aabb(e.previous)
+	aabb.Min.X = math.Min(aabb.Min.X, prev.Min.X)
+	aabb.Min.Y = math.Min(aabb.Min.Y, prev.Min.Y)
+	aabb.Max.X = math.Max(aabb.Max.X, prev.Max.X)
+	aabb.Max.Y = math.Max(aabb.Max.Y, prev.Max.Y)
+	return aabb
+}
+
+// cellRange returns the first and last cell covered by the AABB.
+func (c *CollisionSystem) cellRange(aabb engo.AABB) (collisionCell, collisionCell) {
+	return collisionCell{int(math.Floor(aabb.Min.X / c.CellSize)), int(math.Floor(aabb.Min.Y / c.CellSize))},
+		collisionCell{int(math.Floor(aabb.Max.X / c.CellSize)), int(math.Floor(aabb.Max.Y / c.CellSize))}
+}
+
+// updateCells puts all entities in the cells of the spatial hash they cover.
+func (c *CollisionSystem) updateCells() {
+	if c.CellSize <= 0 {
+		return
+	}
+	if c.cells == nil {
+		c.cells = make(map[collisionCell][]int)
+	}
+	for cell, indices := range c.cells {
+		if len(indices) == 0 {
+			// The cell wasn't used the last Update either
+			delete(c.cells, cell)
+			continue
+		}
+		c.cells[cell] = indices[:0]
+	}
+
+	for i, e := range c.entities {
+		min, max := c.cellRange(e.sweptBounds())
+		for x := min.x; x <= max.x; x++ {
+			for y := min.y; y <= max.y; y++ {
+				cell := collisionCell{x, y}
+				c.cells[cell] = append(c.cells[cell], i)
+			}
+		}
+	}
+}
+
+// candidatesFor returns the indices of the entities that may collide with the entity at index i, in the order they
+// were added. Entities sharing multiple cells with it are only returned once.
+func (c *CollisionSystem) candidatesFor(i int) []int {
+	c.candidates = c.candidates[:0]
+	if c.CellSize <= 0 {
+		for j := range c.entities {
+			c.candidates = append(c.candidates, j)
+		}
+		return c.candidates
+	}
+
+	if len(c.seen) < len(c.entities) {
+		c.seen = make([]int, len(c.entities))
+		c.stamp = 0
+	}
+	c.stamp++
+
+	min, max := c.cellRange(c.entities[i].sweptBounds())
+	for x := min.x; x <= max.x; x++ {
+		for y := min.y; y <= max.y; y++ {
+			for _, j := range c.cells[collisionCell{x, y}] {
+				if c.seen[j] == c.stamp {
+					continue
+				}
+				c.seen[j] = c.stamp
+				c.candidates = append(c.candidates, j)
+			}
+		}
+	}
+	sort.Ints(c.candidates)
+	return c.candidates
 }
 
 // IsIntersecting tells if two engo.AABBs intersect.
@@ -620,6 +911,57 @@ func IsIntersecting(rect1 engo.AABB, rect2 engo.AABB) bool {
 	return false
 }
 
+// SweptAABB tells if rect, when moved by delta, hits obstacle. If it does, it returns the fraction of delta at which
+// they first touch, and the normal of the side of obstacle that was hit. Rects that already overlap at the start are
+// not considered a hit, as those are found by IsIntersecting.
+func SweptAABB(rect engo.AABB, delta engo.Point, obstacle engo.AABB) (float32, engo.Point, bool) {
+	entryX, exitX, ok := sweptAxis(rect.Min.X, rect.Max.X, delta.X, obstacle.Min.X, obstacle.Max.X)
+	if !ok {
+		return 0, engo.Point{}, false
+	}
+	entryY, exitY, ok := sweptAxis(rect.Min.Y, rect.Max.Y, delta.Y, obstacle.Min.Y, obstacle.Max.Y)
+	if !ok {
+		return 0, engo.Point{}, false
+	}
+
+	entry := math.Max(entryX, entryY)
+	exit := math.Min(exitX, exitY)
+	if entry > exit || entry < 0 || entry > 1 {
+		return 0, engo.Point{}, false
+	}
+
+	normal := engo.Point{}
+	if entryX > entryY {
+		if delta.X > 0 {
+			normal.X = -1
+		} else {
+			normal.X = 1
+		}
+	} else {
+		if delta.Y > 0 {
+			normal.Y = -1
+		} else {
+			normal.Y = 1
+		}
+	}
+	return entry, normal, true
+}
+
+// sweptAxis returns the fractions of delta at which the range min-max, moving by delta, enters and exits the range
+// otherMin-otherMax. It returns false if they never overlap on this axis.
+func sweptAxis(min, max, delta, otherMin, otherMax float32) (float32, float32, bool) {
+	switch {
+	case delta > 0:
+		return (otherMin - max) / delta, (otherMax - min) / delta, true
+	case delta < 0:
+		return (otherMax - min) / delta, (otherMin - max) / delta, true
+	}
+	if max <= otherMin || min >= otherMax {
+		return 0, 0, false
+	}
+	return math.Inf(-1), math.Inf(1), true
+}
+
 // MinimumTranslation tells how much an entity has to move to no longer overlap another entity.
 func MinimumTranslation(rect1 engo.AABB, rect2 engo.AABB) engo.Point {
 	mtd := engo.Point{}
@@ -2,6 +2,7 @@ package common
 
 import (
 	"fmt"
+	"math/rand"
 	"testing"
 
 	"github.com/klopsch/ecs"
@@ -46,6 +47,43 @@ func TestSpaceComponent_Contains(t *testing.T) {
 	}
 }
 
+func TestSpaceComponent_Contains_Rotated(t *testing.T) {
+	// A diamond with its top corner at 0, 0, and its center at 0, 70.71
+	space := SpaceComponent{Width: 100, Height: 100, Rotation: 45}
+	pass := []engo.Point{
+		{X: 0, Y: 70},
+		{X: 0, Y: 5},
+		{X: 0, Y: 135},
+		{X: 65, Y: 70},
+		{X: -65, Y: 70},
+		{X: 30, Y: 40},
+	}
+	fail := []engo.Point{
+		// Within the AABB, but not the rotated box:
+		{X: 60, Y: 5},
+		{X: -60, Y: 5},
+		{X: 60, Y: 135},
+		{X: -60, Y: 135},
+
+		// Within the unrotated box, but not the rotated one:
+		{X: 90, Y: 10},
+		{X: 90, Y: 90},
+
+		// Totally not within:
+		{X: 0, Y: -5},
+		{X: 0, Y: 150},
+		{X: 80, Y: 70},
+	}
+
+	for _, p := range pass {
+		assert.True(t, space.Contains(p), fmt.Sprintf("point %v should be within rotated area", p))
+	}
+
+	for _, f := range fail {
+		assert.False(t, space.Contains(f), fmt.Sprintf("point %v should not be within rotated area", f))
+	}
+}
+
 func TestSpaceComponent_Contains_Hitboxes(t *testing.T) {
 	scs := []SpaceComponent{}
 	space0 := SpaceComponent{Width: 5, Height: 5, Position: engo.Point{X: 0, Y: 0}}  //AABB
@@ -190,6 +228,58 @@ func TestSpaceComponent_Overlaps(t *testing.T) {
 	}
 }
 
+func TestSpaceComponent_Intersects(t *testing.T) {
+	box := &SpaceComponent{Width: 100, Height: 100}
+	data := []struct {
+		name  string
+		other *SpaceComponent
+		exp   bool
+		mtv   engo.Point
+	}{
+		{"partial", &SpaceComponent{Position: engo.Point{X: 98, Y: 10}, Width: 10, Height: 10}, true, engo.Point{X: 2}},
+		{"contained", &SpaceComponent{Position: engo.Point{X: 20, Y: 10}, Width: 5, Height: 5}, true, engo.Point{Y: -15}},
+		{"containing", &SpaceComponent{Position: engo.Point{X: -10, Y: -5}, Width: 200, Height: 200}, true, engo.Point{Y: 105}},
+		{"touching edge", &SpaceComponent{Position: engo.Point{X: 100, Y: 10}, Width: 10, Height: 10}, false, engo.Point{}},
+		{"touching corner", &SpaceComponent{Position: engo.Point{X: 100, Y: 100}, Width: 10, Height: 10}, false, engo.Point{}},
+		{"apart", &SpaceComponent{Position: engo.Point{X: 200, Y: 200}, Width: 10, Height: 10}, false, engo.Point{}},
+	}
+	for _, d := range data {
+		// The translation moves the other box out of box, so it's the opposite of what box needs
+		assert.Equal(t, d.exp, d.other.Intersects(box), d.name)
+		assert.Equal(t, d.exp, box.Intersects(d.other), d.name)
+		mtv := d.other.MinimumTranslation(box)
+		assert.True(t, mtv.Equal(d.mtv), fmt.Sprintf("%s: minimum translation should be %v, got %v", d.name, d.mtv, mtv))
+	}
+}
+
+func TestSpaceComponent_Intersects_Rotated(t *testing.T) {
+	// A diamond with its top corner at 0, 0, and its center at 0, 70.71
+	diamond := &SpaceComponent{Width: 100, Height: 100, Rotation: 45}
+
+	// Within the AABB of the diamond, but not the diamond itself
+	corner := &SpaceComponent{Position: engo.Point{X: 50, Y: 0}, Width: 20, Height: 20}
+	assert.True(t, IsIntersecting(diamond.AABB(), corner.AABB()), "AABBs should intersect")
+	assert.False(t, diamond.Intersects(corner), "box in the corner of the AABB should not intersect the diamond")
+	assert.True(t, diamond.MinimumTranslation(corner).Equal(engo.Point{}), "separate boxes should not be translated")
+
+	inside := &SpaceComponent{Position: engo.Point{X: 20, Y: 40}, Width: 40, Height: 20, Rotation: 30}
+	assert.True(t, diamond.Intersects(inside), "rotated box should intersect the diamond")
+	assert.True(t, inside.Intersects(diamond), "rotated box should intersect the diamond")
+
+	mtv := inside.MinimumTranslation(diamond)
+	assert.False(t, mtv.Equal(engo.Point{}), "intersecting boxes should be translated")
+
+	// Moving the box by the translation separates it, and moving it any less doesn't
+	moved := *inside
+	moved.Position.X += mtv.X * 1.01
+	moved.Position.Y += mtv.Y * 1.01
+	assert.False(t, moved.Intersects(diamond), "translated box should not intersect the diamond")
+	moved = *inside
+	moved.Position.X += mtv.X * 0.9
+	moved.Position.Y += mtv.Y * 0.9
+	assert.True(t, moved.Intersects(diamond), "box translated by less should still intersect the diamond")
+}
+
 func TestSpaceComponent_Corners(t *testing.T) {
 	space1 := SpaceComponent{Width: 1, Height: 1}
 	exp1 := [4]engo.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}}
@@ -220,6 +310,21 @@ func TestSpaceComponent_AABB(t *testing.T) {
 	if !exp2.Min.Equal(act2.Min) || !exp2.Max.Equal(act2.Max) {
 		t.Errorf("Space2's AABB %v did not match expected %v", act2, exp2)
 	}
+
+	// The first corner is the right-most one
+	space3 := SpaceComponent{Width: 1, Height: 1, Rotation: 135}
+	exp3 := engo.AABB{Min: engo.Point{X: -1.4142135, Y: -0.70710677}, Max: engo.Point{X: 0, Y: 0.70710677}}
+	act3 := space3.AABB()
+	if !exp3.Min.Equal(act3.Min) || !exp3.Max.Equal(act3.Max) {
+		t.Errorf("Space3's AABB %v did not match expected %v", act3, exp3)
+	}
+
+	space4 := SpaceComponent{Position: engo.Point{X: 10, Y: 20}, Width: 4, Height: 2, Rotation: 45}
+	exp4 := engo.AABB{Min: engo.Point{X: 8.585787, Y: 20}, Max: engo.Point{X: 12.828427, Y: 24.242641}}
+	act4 := space4.AABB()
+	if !exp4.Min.Equal(act4.Min) || !exp4.Max.Equal(act4.Max) {
+		t.Errorf("Space4's AABB %v did not match expected %v", act4, exp4)
+	}
 }
 
 const (
@@ -276,6 +381,162 @@ func Test_GroupSolid(t *testing.T) {
 	}
 }
 
+const (
+	playerLayer = 1 << iota
+	wallLayer
+	pickupLayer
+)
+
+// Test Layer and Mask filtering
+func Test_LayerMask(t *testing.T) {
+	engo.Mailbox = &engo.MessageManager{}
+	//All items in same place, would all collide without filtering
+	CE := func(layer, mask uint32) collisionEntity {
+		nb := ecs.NewBasic()
+		return collisionEntity{
+			BasicEntity: &nb,
+			CollisionComponent: &CollisionComponent{
+				Main:  1,
+				Group: 1,
+				Layer: layer,
+				Mask:  mask,
+			},
+			SpaceComponent: &SpaceComponent{
+				Position: engo.Point{X: 10, Y: 10},
+				Width:    50,
+				Height:   50,
+			},
+		}
+	}
+	ents := []collisionEntity{
+		CE(playerLayer, wallLayer),             //The Player only collides with the wall layer
+		CE(wallLayer, 0),                       //The Wall collides with everything
+		CE(pickupLayer, playerLayer|wallLayer), //The Pickup
+		CE(0, 0),                               //Defaults collide with everything
+	}
+	collisions := make(map[uint64][]uint64)
+	engo.Mailbox.Listen("CollisionMessage", func(msg engo.Message) {
+		m := msg.(CollisionMessage)
+		collisions[m.Entity.ID()] = append(collisions[m.Entity.ID()], m.To.ID())
+	})
+	sys := CollisionSystem{entities: ents}
+	sys.Update(0.01)
+
+	expected := [][]int{
+		{1, 3},
+		{0, 2, 3},
+		{0, 1, 3},
+		{0, 1, 2},
+	}
+	for i, e := range ents {
+		var ids []uint64
+		for _, j := range expected[i] {
+			ids = append(ids, ents[j].ID())
+		}
+		assert.Equal(t, ids, collisions[e.ID()], "entity %d collided with the wrong entities", i)
+	}
+}
+
+// Test fast entities hitting a thin wall
+func Test_Swept(t *testing.T) {
+	for _, swept := range []bool{false, true} {
+		engo.Mailbox = &engo.MessageManager{}
+		var msgs []CollisionMessage
+		engo.Mailbox.Listen("CollisionMessage", func(msg engo.Message) {
+			msgs = append(msgs, msg.(CollisionMessage))
+		})
+
+		sys := CollisionSystem{Solids: Ball}
+		bullet, wall := ecs.NewBasic(), ecs.NewBasic()
+		bulletSpace := &SpaceComponent{Position: engo.Point{X: 0, Y: 10}, Width: 4, Height: 4}
+		sys.Add(&bullet, &CollisionComponent{Main: Ball, Swept: swept}, bulletSpace)
+		sys.Add(&wall, &CollisionComponent{Group: Ball}, &SpaceComponent{Position: engo.Point{X: 100, Y: 0}, Width: 2, Height: 50})
+
+		sys.Update(0.01)
+		assert.Empty(t, msgs, "Nothing should collide before moving")
+
+		// Move past the wall in a single frame
+		bulletSpace.Position.X = 200
+		sys.Update(0.01)
+
+		if !swept {
+			assert.Empty(t, msgs, "Without Swept the bullet should pass through the wall")
+			continue
+		}
+		if assert.Len(t, msgs, 1, "With Swept the bullet should hit the wall") {
+			assert.InDelta(t, 0.48, msgs[0].TimeOfImpact, 1e-6)
+			assert.Equal(t, engo.Point{X: -1, Y: 0}, msgs[0].Normal)
+		}
+		assert.InDelta(t, 96, bulletSpace.Position.X, 1e-4, "Solid collisions should move the bullet back to the wall")
+	}
+}
+
+// newCollisionBenchSystem creates a CollisionSystem with n entities spread over a large area.
+func newCollisionBenchSystem(n int, cellSize float32) *CollisionSystem {
+	sys := &CollisionSystem{CellSize: cellSize}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < n; i++ {
+		basic := ecs.NewBasic()
+		sys.Add(&basic, &CollisionComponent{Main: 1, Group: 1}, &SpaceComponent{
+			Position: engo.Point{X: rng.Float32() * 4000, Y: rng.Float32() * 4000},
+			Width:    20,
+			Height:   20,
+		})
+	}
+	return sys
+}
+
+// Test the spatial hash finds the same collisions as testing all pairs
+func Test_SpatialHash(t *testing.T) {
+	engo.Mailbox = &engo.MessageManager{}
+	var pairs [][2]uint64
+	engo.Mailbox.Listen("CollisionMessage", func(msg engo.Message) {
+		m := msg.(CollisionMessage)
+		pairs = append(pairs, [2]uint64{m.Entity.ID(), m.To.ID()})
+	})
+
+	sys := newCollisionBenchSystem(500, 0)
+	sys.Update(0.01)
+	expected := pairs
+	assert.NotEmpty(t, expected, "Some entities should collide")
+
+	for _, size := range []float32{5, 40, 1000} {
+		pairs = nil
+		sys.CellSize = size
+		sys.Update(0.01)
+		assert.Equal(t, expected, pairs, "Cell size %f should find the same collisions", size)
+	}
+
+	// A large entity spanning many cells collides only once with each other entity
+	pairs = nil
+	big, small := ecs.NewBasic(), ecs.NewBasic()
+	sys = &CollisionSystem{CellSize: 10}
+	sys.Add(&big, &CollisionComponent{Main: 1, Group: 1}, &SpaceComponent{Width: 100, Height: 100})
+	sys.Add(&small, &CollisionComponent{Main: 1, Group: 1}, &SpaceComponent{Position: engo.Point{X: 5, Y: 5}, Width: 90, Height: 90})
+	sys.Update(0.01)
+	assert.Equal(t, [][2]uint64{{big.ID(), small.ID()}, {small.ID(), big.ID()}}, pairs)
+}
+
+func BenchmarkCollisionSystem_AllPairs(b *testing.B) {
+	engo.Mailbox = &engo.MessageManager{}
+	sys := newCollisionBenchSystem(2000, 0)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		sys.Update(0.01)
+	}
+}
+
+func BenchmarkCollisionSystem_SpatialHash(b *testing.B) {
+	engo.Mailbox = &engo.MessageManager{}
+	sys := newCollisionBenchSystem(2000, 40)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		sys.Update(0.01)
+	}
+}
+
 func TestSpaceComponent_Center(t *testing.T) {
 	components := []SpaceComponent{
 		{Width: 0, Height: 0},
@@ -614,3 +875,21 @@ func TestSpaceComponentAddShape(t *testing.T) {
 		}
 	}
 }
+
+func TestSpaceComponent_Pivot(t *testing.T) {
+	space := SpaceComponent{Width: 10, Height: 20, Rotation: 90, pivot: engo.Point{X: 0.5, Y: 1}}
+
+	// Rotated about the middle of its bottom edge, at (5, 20), the box lies on its left side
+	exp := [4]engo.Point{{X: 25, Y: 15}, {X: 25, Y: 25}, {X: 5, Y: 15}, {X: 5, Y: 25}}
+	act := space.Corners()
+	for i := 0; i < 4; i++ {
+		assert.True(t, exp[i].Equal(act[i]), fmt.Sprintf("corner %d did not match (got %v expected %v)", i, act[i], exp[i]))
+	}
+	center := space.Center()
+	assert.True(t, center.Equal(engo.Point{X: 15, Y: 20}), "Center should account for the pivot")
+	assert.True(t, space.Contains(engo.Point{X: 15, Y: 20}), "Contains should account for the pivot")
+	assert.False(t, space.Contains(engo.Point{X: 2, Y: 2}), "Contains should account for the pivot")
+
+	space.SetCenter(engo.Point{X: 115, Y: 20})
+	assert.True(t, space.Position.Equal(engo.Point{X: 100}), "SetCenter should account for the pivot")
+}
@@ -0,0 +1,131 @@
+package common
+
+import (
+	"image/color"
+
+	"github.com/klopsch/engo/math"
+)
+
+// unpremultiply returns the straight, non-premultiplied, components of the color in the range [0, 1].
+func unpremultiply(c color.Color) (r, g, b, a float32) {
+	cr, cg, cb, ca := c.RGBA()
+	if ca == 0 {
+		return 0, 0, 0, 0
+	}
+	a = float32(ca)
+	return float32(cr) / a, float32(cg) / a, float32(cb) / a, a / 0xffff
+}
+
+// straightColor returns the color with the given straight components, which are clamped to [0, 1].
+func straightColor(r, g, b, a float32) color.NRGBA {
+	return color.NRGBA{
+		R: uint8(math.Clamp(r, 0, 1)*0xff + 0.5),
+		G: uint8(math.Clamp(g, 0, 1)*0xff + 0.5),
+		B: uint8(math.Clamp(b, 0, 1)*0xff + 0.5),
+		A: uint8(math.Clamp(a, 0, 1)*0xff + 0.5),
+	}
+}
+
+// hue returns the hue of the straight components in degrees, in the range [0, 360), given their maximum and the
+// difference between their maximum and minimum.
+func hue(r, g, b, max, delta float32) float32 {
+	var h float32
+	switch {
+	case delta == 0:
+		return 0
+	case max == r:
+		h = (g - b) / delta
+	case max == g:
+		h = (b-r)/delta + 2
+	default:
+		h = (r-g)/delta + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
+
+// hueToRGB returns the straight components for the hue in degrees, with the given chroma and the offset m added to
+// every component.
+func hueToRGB(h, chroma, m float32) (r, g, b float32) {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	h /= 60
+	x := chroma * (1 - math.Abs(math.Mod(h, 2)-1))
+	switch {
+	case h < 1:
+		r, g, b = chroma, x, 0
+	case h < 2:
+		r, g, b = x, chroma, 0
+	case h < 3:
+		r, g, b = 0, chroma, x
+	case h < 4:
+		r, g, b = 0, x, chroma
+	case h < 5:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+	return r + m, g + m, b + m
+}
+
+// ColorToHSV returns the hue in degrees, in the range [0, 360), and the saturation, value and alpha, in the range
+// [0, 1], of the color.
+func ColorToHSV(c color.Color) (h, s, v, a float32) {
+	r, g, b, a := unpremultiply(c)
+	max := math.Max(r, math.Max(g, b))
+	delta := max - math.Min(r, math.Min(g, b))
+	if max > 0 {
+		s = delta / max
+	}
+	return hue(r, g, b, max, delta), s, max, a
+}
+
+// HSVToColor returns the color with the hue in degrees and the saturation, value and alpha in the range [0, 1].
+// Values outside of those ranges are wrapped for the hue and clamped otherwise.
+func HSVToColor(h, s, v, a float32) color.Color {
+	s, v = math.Clamp(s, 0, 1), math.Clamp(v, 0, 1)
+	chroma := v * s
+	r, g, b := hueToRGB(h, chroma, v-chroma)
+	return straightColor(r, g, b, a)
+}
+
+// ColorToHSL returns the hue in degrees, in the range [0, 360), and the saturation, lightness and alpha, in the range
+// [0, 1], of the color.
+func ColorToHSL(c color.Color) (h, s, l, a float32) {
+	r, g, b, a := unpremultiply(c)
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	delta := max - min
+	l = (max + min) / 2
+	if delta > 0 {
+		s = delta / (1 - math.Abs(2*l-1))
+	}
+	return hue(r, g, b, max, delta), s, l, a
+}
+
+// HSLToColor returns the color with the hue in degrees and the saturation, lightness and alpha in the range [0, 1].
+// Values outside of those ranges are wrapped for the hue and clamped otherwise.
+func HSLToColor(h, s, l, a float32) color.Color {
+	s, l = math.Clamp(s, 0, 1), math.Clamp(l, 0, 1)
+	chroma := (1 - math.Abs(2*l-1)) * s
+	r, g, b := hueToRGB(h, chroma, l-chroma/2)
+	return straightColor(r, g, b, a)
+}
+
+// LerpColor returns the linear interpolation between the colors a and b at t, which is clamped to [0, 1]. The colors
+// are interpolated premultiplied by their alpha, so fading to a transparent color doesn't darken or tint the color
+// halfway through.
+func LerpColor(a, b color.Color, t float32) color.Color {
+	t = math.Clamp(t, 0, 1)
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	lerp := func(x, y uint32) uint16 {
+		return uint16(math.Clamp(math.Lerp(float32(x), float32(y), t)+0.5, 0, 0xffff))
+	}
+	return color.RGBA64{R: lerp(ar, br), G: lerp(ag, bg), B: lerp(ab, bb), A: lerp(aa, ba)}
+}
@@ -0,0 +1,53 @@
+package common
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLerpColor(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+
+	assert.Equal(t, color.RGBA64{R: 0xffff, A: 0xffff}, LerpColor(red, blue, 0))
+	assert.Equal(t, color.RGBA64{R: 0x8000, B: 0x8000, A: 0xffff}, LerpColor(red, blue, 0.5))
+	assert.Equal(t, color.RGBA64{B: 0xffff, A: 0xffff}, LerpColor(red, blue, 1))
+	assert.Equal(t, LerpColor(red, blue, 1), LerpColor(red, blue, 2), "t should be clamped")
+
+	// Fading out keeps the hue, instead of going through black
+	half := LerpColor(red, color.Transparent, 0.5)
+	r, g, b, a := half.RGBA()
+	assert.Equal(t, []uint32{0x8000, 0, 0, 0x8000}, []uint32{r, g, b, a})
+	assert.Equal(t, color.NRGBA{R: 255, A: 128}, color.NRGBAModel.Convert(half))
+}
+
+func TestHSV(t *testing.T) {
+	h, s, v, a := ColorToHSV(color.NRGBA{R: 255, G: 128, A: 255})
+	assert.InDelta(t, 30, h, 0.5)
+	assert.InDelta(t, 1, s, 1e-5)
+	assert.InDelta(t, 1, v, 1e-5)
+	assert.InDelta(t, 1, a, 1e-5)
+
+	assert.Equal(t, color.NRGBA{G: 255, A: 255}, HSVToColor(120, 1, 1, 1))
+	assert.Equal(t, color.NRGBA{R: 255, A: 255}, HSVToColor(360, 1, 1, 1), "The hue should wrap around")
+	assert.Equal(t, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, HSVToColor(0, -1, 2, 1), "Values should be clamped")
+
+	// Premultiplied colors are converted to their straight color
+	h, s, v, a = ColorToHSV(color.RGBA{B: 128, A: 128})
+	assert.InDelta(t, 240, h, 1e-5)
+	assert.InDelta(t, 1, v, 1e-5)
+	assert.InDelta(t, 128.0/255, a, 1e-5)
+}
+
+func TestHSL(t *testing.T) {
+	c := color.NRGBA{R: 64, G: 128, B: 192, A: 255}
+	h, s, l, a := ColorToHSL(c)
+	assert.InDelta(t, 210, h, 0.5)
+	assert.InDelta(t, 0.5, s, 0.01)
+	assert.InDelta(t, 0.5, l, 0.01)
+	assert.Equal(t, c, HSLToColor(h, s, l, a), "Converting back should give the same color")
+
+	assert.Equal(t, color.NRGBA{R: 128, G: 128, B: 128, A: 255}, HSLToColor(0, 0, 0.5, 1))
+}
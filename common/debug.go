@@ -0,0 +1,138 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"reflect"
+	"strings"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+
+	"golang.org/x/image/font/gofont/gomonobold"
+)
+
+// EntityCounter is implemented by Systems that can tell how many entities they hold, which the DebugOverlaySystem
+// displays. All the Systems of this package holding entities implement it.
+type EntityCounter interface {
+	EntityCount() int
+}
+
+// DebugOverlaySystem is a system for debugging that displays the FPS, the frame time, the number of entities of
+// every System and the RenderStats of the RenderSystem on the HUD, to see what's slowing the game down
+// without a profiler. It's meant to be added during development only.
+type DebugOverlaySystem struct {
+	// ToggleKey shows and hides the overlay. It defaults to F3.
+	ToggleKey engo.Key
+	// Hidden is whether the overlay is hidden, until ToggleKey is pressed.
+	Hidden bool
+	// Position is where the top-left corner of the overlay is on the HUD.
+	Position engo.Point
+	// Font is used to display the overlay, defaults to gomonobold.
+	Font *Font
+
+	entity struct {
+		*ecs.BasicEntity
+		*RenderComponent
+		*SpaceComponent
+	}
+	world  *ecs.World
+	render *RenderSystem
+}
+
+// New is called when DebugOverlaySystem is added to the world
+func (d *DebugOverlaySystem) New(w *ecs.World) {
+	d.world = w
+	if d.ToggleKey == 0 {
+		d.ToggleKey = engo.KeyF3
+	}
+	if d.Font == nil {
+		if err := engo.Files.LoadReaderData("gomonobold_debug.ttf", bytes.NewReader(gomonobold.TTF)); err != nil {
+			panic("unable to load gomonobold.ttf for the debug overlay system! Error was: " + err.Error())
+		}
+
+		d.Font = &Font{
+			URL:  "gomonobold_debug.ttf",
+			FG:   color.White,
+			BG:   color.RGBA{A: 160},
+			Size: 16,
+		}
+
+		if err := d.Font.CreatePreloaded(); err != nil {
+			panic("unable to create gomonobold.ttf for the debug overlay system! Error was: " + err.Error())
+		}
+	}
+
+	b := ecs.NewBasic()
+	d.entity.BasicEntity = &b
+	d.entity.RenderComponent = &RenderComponent{
+		Drawable: Text{Font: d.Font},
+		Hidden:   d.Hidden,
+	}
+	d.entity.RenderComponent.SetShader(HUDShader)
+	d.entity.RenderComponent.SetZIndex(1000)
+	d.entity.SpaceComponent = &SpaceComponent{Position: d.Position}
+	for _, system := range w.Systems() {
+		switch sys := system.(type) {
+		case *RenderSystem:
+			d.render = sys
+			sys.Add(d.entity.BasicEntity, d.entity.RenderComponent, d.entity.SpaceComponent)
+		}
+	}
+}
+
+// Add doesn't do anything since New creates the only entity used
+func (*DebugOverlaySystem) Add() {}
+
+// Remove doesn't do anything since New creates the only entity used
+func (*DebugOverlaySystem) Remove(b ecs.BasicEntity) {}
+
+// Update toggles the overlay when the ToggleKey is pressed, and updates the displayed statistics every frame while
+// it's shown.
+func (d *DebugOverlaySystem) Update(dt float32) {
+	if engo.Input.Key(d.ToggleKey).JustPressed() {
+		d.Hidden = !d.Hidden
+	}
+	d.entity.Hidden = d.Hidden
+	if d.Hidden {
+		return
+	}
+	d.entity.Position = d.Position
+	d.entity.Drawable = Text{
+		Font: d.Font,
+		Text: d.DisplayString(dt),
+	}
+}
+
+// DisplayString returns the displayed statistics, one per line, given the duration of the frame in seconds.
+func (d *DebugOverlaySystem) DisplayString(dt float32) string {
+	var b strings.Builder
+	if engo.Time != nil {
+		fmt.Fprintf(&b, "FPS: %g\n", engo.Time.FPS())
+	}
+	fmt.Fprintf(&b, "Frame: %.2fms\n", dt*1000)
+	if d.render != nil {
+		stats := d.render.Stats()
+		fmt.Fprintf(&b, "Draw calls: %d\n", stats.DrawCalls)
+		fmt.Fprintf(&b, "Batches: %d\n", stats.Batches)
+		fmt.Fprintf(&b, "Sprites: %d (%d culled)\n", stats.Sprites, stats.Culled)
+	}
+	if d.world != nil {
+		for _, system := range d.world.Systems() {
+			if counter, ok := system.(EntityCounter); ok {
+				fmt.Fprintf(&b, "%s: %d\n", systemName(system), counter.EntityCount())
+			}
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// systemName returns the name of the type of the System, without its package.
+func systemName(system ecs.System) string {
+	t := reflect.TypeOf(system)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
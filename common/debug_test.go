@@ -0,0 +1,40 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugOverlaySystem(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &CameraTestScene{})
+
+	w := &ecs.World{}
+	input := &TextInputSystem{}
+	w.AddSystem(input)
+	input.Add(&ecs.BasicEntity{}, &TextInputComponent{}, &RenderComponent{})
+	input.Add(&ecs.BasicEntity{}, &TextInputComponent{}, &RenderComponent{})
+
+	d := &DebugOverlaySystem{Font: &Font{}, Position: engo.Point{X: 5, Y: 10}}
+	d.New(w)
+	d.render = &RenderSystem{stats: RenderStats{Sprites: 40, Culled: 2, Batches: 2, DrawCalls: 3}}
+	assert.Equal(t, engo.KeyF3, d.ToggleKey, "The overlay should be toggled with F3 by default")
+
+	d.Update(0.016)
+	txt := d.entity.Drawable.(Text).Text
+	assert.Contains(t, txt, "Frame: 16.00ms")
+	assert.Contains(t, txt, "Draw calls: 3", "The draw calls of the RenderSystem should be displayed")
+	assert.Contains(t, txt, "Sprites: 40 (2 culled)")
+	assert.Contains(t, txt, "TextInputSystem: 2", "The entities of every System should be counted")
+	assert.Equal(t, engo.Point{X: 5, Y: 10}, d.entity.Position)
+	assert.False(t, d.entity.Hidden)
+
+	d.Hidden = true
+	d.Update(0.016)
+	assert.True(t, d.entity.Hidden, "The overlay should be hidden")
+}
@@ -0,0 +1,167 @@
+package common
+
+import (
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/klopsch/engo/math"
+)
+
+// DragSystemPriority is the priority of the DragSystem. It runs right after
+// the MouseSystem, so entities follow the cursor in the same frame.
+const DragSystemPriority = MouseSystemPriority - 1
+
+// DraggableComponent makes an entity draggable with the left mouse button. It
+// needs a MouseComponent, which the MouseSystem updates, and the entity has to
+// be in both the MouseSystem and the DragSystem.
+type DraggableComponent struct {
+	// Disabled stops the entity from being dragged, without removing it from
+	// the DragSystem. An ongoing drag ends.
+	Disabled bool
+	// Snap rounds the position of the dragged entity to multiples of it, per
+	// axis, such as the size of the tiles of a grid. An axis of 0 doesn't snap.
+	Snap engo.Point
+	// Bounds keeps the whole entity within it while it's dragged. It's ignored
+	// if it's empty.
+	Bounds engo.AABB
+
+	dragging bool
+	// grab is where the entity was grabbed, relative to its position
+	grab  engo.Point
+	start engo.Point
+}
+
+// Dragging reports whether the entity is being dragged.
+func (c *DraggableComponent) Dragging() bool {
+	return c.dragging
+}
+
+// DragStartMessage is dispatched when an entity starts being dragged.
+type DragStartMessage struct {
+	Entity *ecs.BasicEntity
+	// Position is the position of the entity before it was dragged
+	Position engo.Point
+}
+
+// Type implements the engo.Message interface.
+func (DragStartMessage) Type() string { return "DragStartMessage" }
+
+// DragEndMessage is dispatched when an entity is dropped.
+type DragEndMessage struct {
+	Entity *ecs.BasicEntity
+	// Start is the position of the entity before it was dragged
+	Start engo.Point
+	// Position is where the entity was dropped
+	Position engo.Point
+}
+
+// Type implements the engo.Message interface.
+func (DragEndMessage) Type() string { return "DragEndMessage" }
+
+type dragEntity struct {
+	*ecs.BasicEntity
+	*DraggableComponent
+	*MouseComponent
+	*SpaceComponent
+}
+
+// DragSystem moves the entities with a DraggableComponent along with the
+// cursor while they're dragged.
+type DragSystem struct {
+	entities []dragEntity
+}
+
+// Priority implements the ecs.Prioritizer interface.
+func (*DragSystem) Priority() int { return DragSystemPriority }
+
+// Pausable implements the engo.Pauser interface. Like the MouseSystem, the
+// DragSystem keeps running while the game is paused.
+func (*DragSystem) Pausable() bool { return false }
+
+// Add starts tracking the given entity.
+func (d *DragSystem) Add(basic *ecs.BasicEntity, draggable *DraggableComponent, mouse *MouseComponent, space *SpaceComponent) {
+	d.entities = append(d.entities, dragEntity{basic, draggable, mouse, space})
+}
+
+// AddByInterface allows an Entity to be added directly using the Draggable
+// interface, which every entity containing the BasicEntity,
+// DraggableComponent, MouseComponent and SpaceComponent anonymously
+// automatically satisfies.
+func (d *DragSystem) AddByInterface(i ecs.Identifier) {
+	o, _ := i.(Draggable)
+	d.Add(o.GetBasicEntity(), o.GetDraggableComponent(), o.GetMouseComponent(), o.GetSpaceComponent())
+}
+
+// Remove stops tracking the given entity.
+func (d *DragSystem) Remove(basic ecs.BasicEntity) {
+	delete := -1
+	for index, e := range d.entities {
+		if e.BasicEntity.ID() == basic.ID() {
+			delete = index
+			break
+		}
+	}
+	if delete >= 0 {
+		d.entities = append(d.entities[:delete], d.entities[delete+1:]...)
+	}
+}
+
+// EntityCount returns the number of entities the DragSystem holds.
+func (d *DragSystem) EntityCount() int {
+	return len(d.entities)
+}
+
+// Update moves the dragged entities to the cursor. While an entity is dragged,
+// the left mouse button is consumed, so Systems updated after the DragSystem
+// don't react to it as well.
+func (d *DragSystem) Update(float32) {
+	dragging := false
+	for _, e := range d.entities {
+		cursor := engo.Point{X: e.MouseComponent.MouseX, Y: e.MouseComponent.MouseY}
+
+		if !e.dragging {
+			if e.Clicked && !e.Disabled {
+				e.dragging = true
+				e.start = e.Position
+				e.grab = engo.Point{X: cursor.X - e.Position.X, Y: cursor.Y - e.Position.Y}
+				engo.Mailbox.Dispatch(DragStartMessage{Entity: e.BasicEntity, Position: e.start})
+				dragging = true
+			}
+			continue
+		}
+
+		// The MouseSystem keeps updating the cursor of the entity until the
+		// button is released
+		if !e.startedDragging || e.Disabled {
+			e.dragging = false
+			engo.Mailbox.Dispatch(DragEndMessage{Entity: e.BasicEntity, Start: e.start, Position: e.Position})
+			// The release ending the drag is consumed as well
+			dragging = true
+			continue
+		}
+
+		e.Position = e.DraggableComponent.constrain(engo.Point{X: cursor.X - e.grab.X, Y: cursor.Y - e.grab.Y}, e.Width, e.Height)
+		dragging = true
+	}
+
+	if dragging {
+		engo.Input.ConsumeMouse(engo.MouseButtonLeft)
+	}
+}
+
+// constrain snaps the position and keeps a w by h large entity there within
+// the bounds.
+func (c *DraggableComponent) constrain(p engo.Point, w, h float32) engo.Point {
+	if c.Snap.X != 0 {
+		p.X = math.Floor(p.X/c.Snap.X+0.5) * c.Snap.X
+	}
+	if c.Snap.Y != 0 {
+		p.Y = math.Floor(p.Y/c.Snap.Y+0.5) * c.Snap.Y
+	}
+	if c.Bounds.Max.X > c.Bounds.Min.X {
+		p.X = math.Clamp(p.X, c.Bounds.Min.X, math.Max(c.Bounds.Min.X, c.Bounds.Max.X-w))
+	}
+	if c.Bounds.Max.Y > c.Bounds.Min.Y {
+		p.Y = math.Clamp(p.Y, c.Bounds.Min.Y, math.Max(c.Bounds.Min.Y, c.Bounds.Max.Y-h))
+	}
+	return p
+}
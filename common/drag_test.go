@@ -0,0 +1,80 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDragSystem(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+		Width:        800,
+		Height:       600,
+	}, &cameraConversionScene{})
+	initialize()
+
+	e := newMouseTestEntity(100, 100, 0)
+	draggable := &DraggableComponent{}
+	mouse := &MouseSystem{camera: cam}
+	mouse.Add(&e.BasicEntity, &e.MouseComponent, &e.SpaceComponent, &e.RenderComponent)
+	drag := &DragSystem{}
+	drag.Add(&e.BasicEntity, draggable, &e.MouseComponent, &e.SpaceComponent)
+
+	var started, ended []engo.Message
+	engo.Mailbox.Listen("DragStartMessage", func(m engo.Message) { started = append(started, m) })
+	engo.Mailbox.Listen("DragEndMessage", func(m engo.Message) { ended = append(ended, m) })
+
+	update := func(x, y float32, action engo.Action) {
+		p := cam.WorldToScreen(engo.Point{X: x, Y: y})
+		engo.Input.Mouse.X, engo.Input.Mouse.Y = p.X, p.Y
+		engo.Input.Mouse.Action = action
+		engo.Input.Mouse.Button = engo.MouseButtonLeft
+		mouse.Update(0)
+		drag.Update(0)
+	}
+
+	// Grab the entity 5 units from its corner, and move it
+	update(105, 105, engo.Press)
+	assert.True(t, draggable.Dragging(), "Clicking the entity should start dragging it")
+	assert.Len(t, started, 1, "Starting to drag should dispatch a DragStartMessage")
+
+	engo.Input = engo.NewInputManager()
+	update(155, 125, engo.Move)
+	assert.True(t, e.Position.Equal(engo.Point{X: 150, Y: 120}), "The entity should follow the cursor, got %v", e.Position)
+	assert.True(t, engo.Input.MouseConsumed(engo.MouseButtonLeft), "Dragging should consume the mouse")
+
+	// The cursor can leave the entity while dragging
+	update(300, 300, engo.Move)
+	assert.True(t, e.Position.Equal(engo.Point{X: 295, Y: 295}), "The entity should follow the cursor, got %v", e.Position)
+
+	update(300, 300, engo.Release)
+	update(300, 300, engo.Neutral)
+	assert.False(t, draggable.Dragging(), "Releasing the button should drop the entity")
+	if assert.Len(t, ended, 1, "Dropping the entity should dispatch a DragEndMessage") {
+		msg := ended[0].(DragEndMessage)
+		assert.True(t, msg.Start.Equal(engo.Point{X: 100, Y: 100}), "The drag should start at the original position")
+		assert.True(t, msg.Position.Equal(engo.Point{X: 295, Y: 295}), "The drag should end at the dropped position")
+	}
+
+	update(400, 400, engo.Move)
+	assert.True(t, e.Position.Equal(engo.Point{X: 295, Y: 295}), "The dropped entity should not follow the cursor, got %v", e.Position)
+
+	draggable.Disabled = true
+	update(300, 300, engo.Press)
+	assert.False(t, draggable.Dragging(), "A disabled entity should not be dragged")
+}
+
+func TestDraggableConstrain(t *testing.T) {
+	c := DraggableComponent{Snap: engo.Point{X: 16, Y: 0}}
+	assert.Equal(t, engo.Point{X: 32, Y: 13}, c.constrain(engo.Point{X: 25, Y: 13}, 10, 10), "X should snap to the nearest multiple")
+	assert.Equal(t, engo.Point{X: 16, Y: 13}, c.constrain(engo.Point{X: 23, Y: 13}, 10, 10), "X should snap to the nearest multiple")
+	assert.Equal(t, engo.Point{X: -16, Y: 13}, c.constrain(engo.Point{X: -20, Y: 13}, 10, 10), "Negative positions should snap too")
+
+	c = DraggableComponent{Bounds: engo.AABB{Max: engo.Point{X: 100, Y: 50}}}
+	assert.Equal(t, engo.Point{X: 0, Y: 40}, c.constrain(engo.Point{X: -5, Y: 45}, 10, 10), "The entity should stay within the bounds")
+	assert.Equal(t, engo.Point{X: 90, Y: 0}, c.constrain(engo.Point{X: 95, Y: -10}, 10, 10), "The entity should stay within the bounds")
+	assert.Equal(t, engo.Point{X: 0, Y: 0}, c.constrain(engo.Point{X: 50, Y: 20}, 200, 100), "Entities larger than the bounds should stay at their minimum")
+}
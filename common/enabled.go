@@ -0,0 +1,96 @@
+package common
+
+import (
+	"sync"
+
+	"github.com/klopsch/ecs"
+)
+
+// entityStates is a System holding the entities of its World which are disabled with SetEntityEnabled. The World
+// removes an entity from all of its Systems with RemoveEntity, which is when entityStates forgets it, and entityStates
+// is discarded along with the World, such as when its Scene is freed.
+type entityStates struct {
+	mu  sync.RWMutex
+	ids map[uint64]struct{}
+}
+
+// Update does nothing, as entityStates only holds the disabled entities.
+func (*entityStates) Update(float32) {}
+
+// Remove forgets the entity, which is removed from the World.
+func (s *entityStates) Remove(basic ecs.BasicEntity) {
+	s.mu.Lock()
+	delete(s.ids, basic.ID())
+	s.mu.Unlock()
+}
+
+// disabled returns whether the entity with the id is disabled. It's called on the nil entityStates of Systems which
+// aren't in a World, for which every entity is enabled.
+func (s *entityStates) disabled(id uint64) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.ids[id]
+	return ok
+}
+
+// findEntityStates returns the entityStates of the World, or nil if it doesn't have any yet.
+func findEntityStates(w *ecs.World) *entityStates {
+	if w == nil {
+		return nil
+	}
+	for _, system := range w.Systems() {
+		if s, ok := system.(*entityStates); ok {
+			return s
+		}
+	}
+	return nil
+}
+
+// worldEntityStates returns the entityStates of the World, adding them to it if it doesn't have any yet.
+func worldEntityStates(w *ecs.World) *entityStates {
+	if s := findEntityStates(w); s != nil {
+		return s
+	}
+	s := &entityStates{ids: make(map[uint64]struct{})}
+	w.AddSystem(s)
+	return s
+}
+
+// SetEntityEnabled enables or disables the entity in the World, without removing it from the Systems it's in. Entities
+// are enabled by default. A disabled entity is skipped by the RenderSystem, the AnimationSystem, the SkeletonSystem,
+// the CollisionSystem, the MouseSystem, the QuadtreeSystem and the ScriptSystem of the World: it isn't drawn, its
+// animation is paused where it is, it doesn't collide with anything, it doesn't react to the mouse, it isn't found by
+// queries and its script isn't run. Enabling it again resumes all of that where it left off.
+//
+// Since entities don't listen to messages themselves, disabling one doesn't stop messages about it from being
+// dispatched by other code and delivered to the handlers of the Mailbox; however, the Systems above don't send any
+// CollisionMessage for a disabled entity.
+//
+// The World keeps the entity disabled until it's enabled again or removed from the World with RemoveEntity, so
+// removing it from only some of its Systems keeps it disabled in the others. The disabled entities of a World are
+// discarded along with it, such as when its Scene is freed.
+func SetEntityEnabled(w *ecs.World, basic *ecs.BasicEntity, enabled bool) {
+	if enabled {
+		s := findEntityStates(w)
+		if s == nil {
+			return
+		}
+		s.mu.Lock()
+		delete(s.ids, basic.ID())
+		s.mu.Unlock()
+		return
+	}
+	s := worldEntityStates(w)
+	s.mu.Lock()
+	s.ids[basic.ID()] = struct{}{}
+	s.mu.Unlock()
+}
+
+// EntityEnabled returns whether the entity is enabled in the World, which it is unless it was disabled with
+// SetEntityEnabled.
+func EntityEnabled(w *ecs.World, basic *ecs.BasicEntity) bool {
+	return !findEntityStates(w).disabled(basic.ID())
+}
@@ -0,0 +1,129 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetEntityEnabled(t *testing.T) {
+	w := &ecs.World{}
+	basic := ecs.NewBasic()
+	assert.True(t, EntityEnabled(w, &basic), "Entities should be enabled by default")
+	assert.Nil(t, findEntityStates(w), "Checking an entity shouldn't add any state to the World")
+	SetEntityEnabled(w, &basic, false)
+	assert.False(t, EntityEnabled(w, &basic))
+	SetEntityEnabled(w, &basic, true)
+	assert.True(t, EntityEnabled(w, &basic))
+	assert.Empty(t, findEntityStates(w).ids, "Enabled entities should not be kept")
+
+	other := &ecs.World{}
+	SetEntityEnabled(w, &basic, false)
+	assert.True(t, EntityEnabled(other, &basic), "Disabling an entity in a World shouldn't disable it in the others")
+	assert.True(t, EntityEnabled(nil, &basic), "Entities should be enabled without a World")
+}
+
+func TestDisabledEntityRemoved(t *testing.T) {
+	w := &ecs.World{}
+	anim := &AnimationSystem{}
+	coll := &CollisionSystem{}
+	w.AddSystem(anim)
+	w.AddSystem(coll)
+	basic := ecs.NewBasic()
+	render := &RenderComponent{Drawable: Texture{}}
+	ac := NewAnimationComponent([]Drawable{&TestDrawable{0}}, 0.1)
+	anim.Add(&basic, &ac, render)
+	coll.Add(&basic, &CollisionComponent{}, &SpaceComponent{})
+	SetEntityEnabled(w, &basic, false)
+
+	anim.Remove(basic)
+	assert.False(t, EntityEnabled(w, &basic), "Removing the entity from one System should keep it disabled in the others")
+
+	w.RemoveEntity(basic)
+	assert.True(t, EntityEnabled(w, &basic), "Removing the entity from the World should forget it")
+	assert.Empty(t, findEntityStates(w).ids)
+
+	unadded := ecs.NewBasic()
+	SetEntityEnabled(w, &unadded, false)
+	w.RemoveEntity(unadded)
+	assert.Empty(t, findEntityStates(w).ids, "Removing an entity which wasn't added should forget it too")
+}
+
+func TestDisabledEntityNotDrawn(t *testing.T) {
+	engo.Mailbox = &engo.MessageManager{}
+	w := &ecs.World{}
+	rs := &RenderSystem{ids: make(map[uint64]struct{}), states: worldEntityStates(w)}
+	var basics []ecs.BasicEntity
+	for i := 0; i < 3; i++ {
+		basics = append(basics, ecs.NewBasic())
+		render := &RenderComponent{Drawable: Texture{}}
+		render.SetShader(cullingTestShader{})
+		rs.Add(&basics[i], render, &SpaceComponent{})
+	}
+	SetEntityEnabled(w, &basics[1], false)
+
+	start := frameStats
+	rs.render(nil)
+	assert.Equal(t, 2, frameStats.sub(start).Sprites, "Disabled entities should not be drawn")
+
+	SetEntityEnabled(w, &basics[1], true)
+	start = frameStats
+	rs.render(nil)
+	assert.Equal(t, 3, frameStats.sub(start).Sprites, "Enabled entities should be drawn again")
+}
+
+func TestDisabledEntityNotAnimated(t *testing.T) {
+	ac := NewAnimationComponent([]Drawable{&TestDrawable{0}, &TestDrawable{1}}, 0.1)
+	ac.AddDefaultAnimation(&Animation{Name: "loop", Frames: []int{0, 1}, Loop: true, Durations: []float32{0.1, 0.1}})
+	rc := &RenderComponent{}
+	basic := ecs.NewBasic()
+	w := &ecs.World{}
+	sys := &AnimationSystem{}
+	w.AddSystem(sys)
+	sys.Add(&basic, &ac, rc)
+
+	sys.Update(0.05)
+	assert.Equal(t, 0, rc.Drawable.(*TestDrawable).ID)
+
+	SetEntityEnabled(w, &basic, false)
+	sys.Update(0.5)
+	assert.Equal(t, 0, rc.Drawable.(*TestDrawable).ID, "Disabled entities should not be animated")
+
+	SetEntityEnabled(w, &basic, true)
+	sys.Update(0.1)
+	assert.Equal(t, 1, rc.Drawable.(*TestDrawable).ID, "Enabled entities should resume where they were paused")
+}
+
+func TestDisabledEntityNotColliding(t *testing.T) {
+	engo.Mailbox = &engo.MessageManager{}
+	collisions := 0
+	engo.Mailbox.Listen(CollisionMessage{}.Type(), func(engo.Message) {
+		collisions++
+	})
+
+	player, wall := ecs.NewBasic(), ecs.NewBasic()
+	playerSpace := &SpaceComponent{Width: 10, Height: 10}
+	playerCollision := &CollisionComponent{Main: 1}
+	w := &ecs.World{}
+	sys := &CollisionSystem{Solids: 1}
+	w.AddSystem(sys)
+	sys.Add(&player, playerCollision, playerSpace)
+	sys.Add(&wall, &CollisionComponent{Group: 1}, &SpaceComponent{Position: engo.Point{X: 5}, Width: 10, Height: 10})
+
+	SetEntityEnabled(w, &wall, false)
+	sys.Update(0.01)
+	assert.Equal(t, 0, collisions, "Disabled entities should not be collided with")
+	assert.Equal(t, engo.Point{}, playerSpace.Position)
+
+	SetEntityEnabled(w, &wall, true)
+	SetEntityEnabled(w, &player, false)
+	sys.Update(0.01)
+	assert.Equal(t, 0, collisions, "Disabled entities should not collide")
+	assert.Equal(t, CollisionGroup(0), playerCollision.Collides)
+
+	SetEntityEnabled(w, &player, true)
+	sys.Update(0.01)
+	assert.Equal(t, 1, collisions, "Enabled entities should collide again")
+}
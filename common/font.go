@@ -10,6 +10,7 @@ import (
 	"log"
 
 	"github.com/klopsch/engo"
+	"github.com/klopsch/engo/math"
 	"github.com/klopsch/gl"
 	"github.com/golang/freetype"
 	"github.com/golang/freetype/truetype"
@@ -32,9 +33,26 @@ type Font struct {
 	BG   color.Color
 	FG   color.Color
 	TTF  *truetype.Font
+	// SDF opts the Font into signed distance field rendering when set. Its FontAtlas then stores the distance to the
+	// edge of the glyphs instead of their pixels, and Text in the Font is drawn by the SDFTextShader, which keeps it
+	// crisp at any scale. BG is not used by SDF fonts.
+	SDF  *SDFStyle
 	face font.Face
 }
 
+// SDFStyle describes how the SDFTextShader draws the Text of a signed distance field Font. The widths are in pixels
+// at the Size of the Font, and together they are limited to SDFSpread.
+type SDFStyle struct {
+	// OutlineWidth is the width of the outline drawn around the glyphs, 0 disables it.
+	OutlineWidth float32
+	// OutlineColor is the color of the outline.
+	OutlineColor color.Color
+	// GlowWidth is how far the glow around the glyphs, and their outline, fades out, 0 disables it.
+	GlowWidth float32
+	// GlowColor is the color of the glow where it's closest to the glyphs.
+	GlowColor color.Color
+}
+
 // LoadedFont returns a Font that was previously loaded via CreatePreloaded
 func LoadedFont(url string, size float64, bg, fg color.Color) (*Font, error) {
 	idx := -1
@@ -208,6 +226,13 @@ func (f *Font) generateFontAtlas(c int) FontAtlas {
 	currentX := float32(0)
 	currentY := float32(0)
 
+	// SDF glyphs are padded so their distance field has room around them
+	var pad float32
+	if f.SDF != nil {
+		pad = SDFSpread
+		atlas.Padding = pad
+	}
+
 	// Default colors
 	if f.FG == nil {
 		f.FG = color.NRGBA{0, 0, 0, 0}
@@ -259,26 +284,28 @@ func (f *Font) generateFontAtlas(c int) FontAtlas {
 			currentX -= atlas.OffsetX[i]
 		}
 
-		if currentX+advance > 1024 {
+		if currentX+advance+2*pad > 1024 {
 			currentX = 0
-			currentY += float32(lineHeight.Ceil())
-			atlas.TotalHeight += float32(lineHeight.Ceil())
+			currentY += float32(lineHeight.Ceil()) + 2*pad
+			atlas.TotalHeight += float32(lineHeight.Ceil()) + 2*pad
 			prev = 0
 		}
 
-		if currentX+advance > atlas.TotalWidth {
-			atlas.TotalWidth = currentX + advance
+		if currentX+advance+2*pad > atlas.TotalWidth {
+			atlas.TotalWidth = currentX + advance + 2*pad
 		}
 
-		atlas.XLocation[i] = currentX
-		atlas.YLocation[i] = currentY
-		currentX += advance
+		atlas.XLocation[i] = currentX + pad
+		atlas.YLocation[i] = currentY + pad
+		currentX += advance + 2*pad
 		prev = i
 	}
 
 	// Create texture
 	actual := image.NewNRGBA(image.Rect(0, 0, int(atlas.TotalWidth), int(atlas.TotalHeight)))
-	draw.Draw(actual, actual.Bounds(), image.NewUniform(f.BG), image.ZP, draw.Src)
+	if f.SDF == nil {
+		draw.Draw(actual, actual.Bounds(), image.NewUniform(f.BG), image.ZP, draw.Src)
+	}
 	d.Dst = actual
 
 	for i := 0; i < c; i++ {
@@ -293,12 +320,69 @@ func (f *Font) generateFontAtlas(c int) FontAtlas {
 		atlas.YLocation[i] += atlas.OffsetY[i]
 	}
 
+	if f.SDF != nil {
+		actual = distanceField(actual, SDFSpread, color.NRGBAModel.Convert(f.FG).(color.NRGBA))
+	}
+
 	imObj := NewImageObject(actual)
 	atlas.Texture = NewTextureSingle(imObj).id
 
+	// The distance field is interpolated between texels, that's what keeps it sharp when scaled up
+	if f.SDF != nil && !engo.Headless() {
+		engo.Gl.BindTexture(engo.Gl.TEXTURE_2D, atlas.Texture)
+		engo.Gl.TexParameteri(engo.Gl.TEXTURE_2D, engo.Gl.TEXTURE_MAG_FILTER, engo.Gl.LINEAR)
+		engo.Gl.BindTexture(engo.Gl.TEXTURE_2D, nil)
+	}
+
 	return atlas
 }
 
+// distanceField turns the coverage in the alpha channel of src into a signed distance field of the given spread in
+// pixels. An alpha of 0.5 lies on the edge of the glyphs, larger values are inside of them, and the distance is
+// clamped to spread on either side. All pixels get the color c.
+func distanceField(src *image.NRGBA, spread int, c color.NRGBA) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(b)
+	inside := func(x, y int) bool {
+		return src.Pix[src.PixOffset(x, y)+3] >= 128
+	}
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			in := inside(x, y)
+
+			// Find the closest pixel on the other side of the edge, anything beyond the spread is clamped anyway
+			nearest := float32((spread + 1) * (spread + 1))
+			for dy := -spread; dy <= spread; dy++ {
+				for dx := -spread; dx <= spread; dx++ {
+					px, py := x+dx, y+dy
+					other := false
+					if image.Pt(px, py).In(b) {
+						other = inside(px, py) != in
+					} else {
+						// Everything beyond the image is outside
+						other = in
+					}
+					if d := float32(dx*dx + dy*dy); other && d < nearest {
+						nearest = d
+					}
+				}
+			}
+
+			// The edge lies halfway between the pixel centers
+			dist := math.Sqrt(nearest) - 0.5
+			if !in {
+				dist = -dist
+			}
+			v := math.Clamp(0.5+dist/float32(2*spread), 0, 1)
+
+			c.A = uint8(v*255 + 0.5)
+			dst.SetNRGBA(x, y, c)
+		}
+	}
+	return dst
+}
+
 // GenerateFontAtlas generates the font atlas for this given font, using the first `c` Unicode characters.
 // This should only be used if you are writing your own custom text shader.
 func (f *Font) GenerateFontAtlas(c int) FontAtlas {
@@ -328,6 +412,9 @@ type FontAtlas struct {
 	// TotalHeight is the total amount of pixels the `FontAtlas` is high; useful for determining the `Viewport`,
 	// which is relative to this value.
 	TotalHeight float32
+	// Padding is the number of pixels around every character that belong to it without being part of its Width
+	// and Height. Only signed distance field fonts have padding, it holds the distance field outside of the glyphs.
+	Padding float32
 }
 
 // Text represents a string drawn onto the screen, as used by the `TextShader`.
@@ -340,7 +427,8 @@ type Text struct {
 	// relative to the `Size` of the `Font`.
 	LineSpacing float32
 	// LetterSpacing is the amount of additional spacing there is between the characters, relative to the `Size` of
-	// the `Font`.
+	// the `Font`. Negative values move the characters closer together. It is applied on top of the kerning of the
+	// `Font`.
 	LetterSpacing float32
 	// RightToLeft is an experimental variable used to indicate that subsequent characters come to the left of the
 	// previous character.
@@ -414,6 +502,86 @@ func (t Text) Height() float32 {
 	return totalY + tallest
 }
 
+// Measure returns the size of the Text as it's drawn by the TextShader, without drawing it. The width is the
+// widest line, using the advances of its characters, and the height is from the top of the first line to the bottom
+// of the lowest character.
+func (t Text) Measure() (width, height float32) {
+	atlas, ok := atlasCache[*t.Font]
+	if !ok {
+		// Generate texture first
+		atlas = t.Font.generateFontAtlas(UnicodeCap)
+		atlasCache[*t.Font] = atlas
+	}
+	return t.layout(atlas, nil)
+}
+
+// layout positions the characters of the Text the way the TextShader draws them. If glyph is not nil, it is called
+// for every drawn character with its byte index in the Text and the position of the character. It returns the size
+// of the laid out Text, as described by Measure.
+func (t Text) layout(atlas FontAtlas, glyph func(index int, char rune, x, y float32)) (width, height float32) {
+	var currentX, currentY, lineWidth float32
+	var prev rune
+
+	var modifier float32 = 1
+	if t.RightToLeft {
+		modifier = -1
+	}
+
+	letterSpace := float32(t.Font.Size) * t.LetterSpacing
+	lineSpace := t.LineSpacing * atlas.Height['X']
+
+	for index, char := range t.Text {
+		// TODO: this might not work for all characters
+		switch {
+		case char == '\n':
+			currentX, lineWidth = 0, 0
+			currentY += atlas.Height['X'] + lineSpace
+			prev = 0
+			continue
+		case char < 32 || int(char) >= len(atlas.Width): // all system stuff should be ignored
+			continue
+		}
+
+		if prev != 0 {
+			kern := t.Font.kern(prev, char)
+			currentX += modifier * kern
+			lineWidth += kern
+		}
+		prev = char
+
+		if glyph != nil {
+			glyph(index, char, currentX, currentY)
+		}
+		if bottom := currentY + atlas.OffsetY[char] + atlas.Height[char]; bottom > height {
+			height = bottom
+		}
+
+		advance := atlas.Width[char] + atlas.OffsetX[char] + atlas.RightSide[char]
+		currentX += modifier * (advance + letterSpace)
+		// The spacing after the last character of a line is not part of its width
+		lineWidth += advance
+		if lineWidth > width {
+			width = lineWidth
+		}
+		lineWidth += letterSpace
+	}
+	return width, height
+}
+
+// kern returns the kerning between the characters a and b, in pixels. It's zero if the Font has no kerning pairs.
+func (f *Font) kern(a, b rune) float32 {
+	if f.face == nil {
+		return 0
+	}
+	return float32(f.face.Kern(a, b)) / 64
+}
+
+// MeasureText returns the size of the text as it's drawn by the TextShader in the Font, without drawing it and
+// without additional spacing. Use Text.Measure to take LetterSpacing and LineSpacing into account.
+func (f *Font) MeasureText(text string) (width, height float32) {
+	return Text{Font: f, Text: text}.Measure()
+}
+
 // View returns 0, 0, 1, 1 because the Text is generated from a FontAtlas. This implements the common.Drawable interface.
 func (t Text) View() (float32, float32, float32, float32) { return 0, 0, 1, 1 }
 
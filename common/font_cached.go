@@ -0,0 +1,144 @@
+package common
+
+import (
+	"image/color"
+
+	"github.com/klopsch/engo"
+	"github.com/klopsch/engo/math"
+	"github.com/klopsch/gl"
+)
+
+// pendingTexts are the CachedTexts to rasterize before the next frame is drawn.
+var pendingTexts []*CachedText
+
+// CachedText is a Drawable showing a Text which is rasterized once into a texture, so it's drawn as a single quad by
+// the DefaultShader or HUDShader like any sprite, instead of a quad per character by the TextShader. Use it for text
+// which rarely changes, such as titles, or scores which only change now and then.
+//
+// The texture takes 4 bytes of GPU memory for every pixel the Text covers, while a Text only needs the vertices of
+// its characters and shares the FontAtlas with all other Texts in its Font. Every change rasterizes the Text again,
+// which draws it into the texture, so Text stays the better choice for text changing every frame. The texture has
+// the size of the Text in pixels, so scaling the CachedText up, by the RenderComponent or the global scale, blurs it.
+type CachedText struct {
+	text          Text
+	width, height float32
+
+	target *RenderTarget
+	// ren holds the buffer the Text is drawn from when rasterizing it
+	ren     RenderComponent
+	pending bool
+}
+
+// NewCachedText creates a CachedText showing the Text, whose Font must be set. It's rasterized by the RenderSystem
+// before the next frame is drawn.
+func NewCachedText(text Text) *CachedText {
+	c := &CachedText{text: text}
+	c.invalidate()
+	return c
+}
+
+// Text returns the Text shown by the CachedText.
+func (c *CachedText) Text() Text {
+	return c.text
+}
+
+// SetText changes the string shown by the CachedText. It's only rasterized again if the string is different.
+func (c *CachedText) SetText(text string) {
+	if text == c.text.Text {
+		return
+	}
+	c.text.Text = text
+	c.invalidate()
+}
+
+// invalidate measures the Text, and queues it to be rasterized.
+func (c *CachedText) invalidate() {
+	c.width, c.height = c.text.Measure()
+	if !c.pending {
+		c.pending = true
+		pendingTexts = append(pendingTexts, c)
+	}
+}
+
+// Texture returns the OpenGL ID of the texture the Text is rasterized into.
+func (c *CachedText) Texture() *gl.Texture {
+	if c.target == nil {
+		return nil
+	}
+	return c.target.Texture()
+}
+
+// Width returns the width of the Text, as measured by Text.Measure.
+func (c *CachedText) Width() float32 {
+	return c.width
+}
+
+// Height returns the height of the Text, as measured by Text.Measure.
+func (c *CachedText) Height() float32 {
+	return c.height
+}
+
+// View returns the viewport properties of the texture, which is drawn flipped like a RenderTarget.
+func (c *CachedText) View() (float32, float32, float32, float32) {
+	return 0, 1, 1, 0
+}
+
+// Close deletes the texture of the CachedText. It's rasterized again if its string is changed afterwards.
+func (c *CachedText) Close() {
+	c.pending = false
+	if c.target != nil {
+		c.target.Close()
+		c.target = nil
+	}
+	if c.ren.Buffer != nil && !engo.Headless() {
+		engo.Gl.DeleteBuffer(c.ren.Buffer)
+		c.ren.Buffer = nil
+	}
+}
+
+// rasterize draws the Text into the texture, resizing it to the size of the Text.
+func (c *CachedText) rasterize() {
+	w, h := int(math.Max(math.Ceil(c.width), 1)), int(math.Max(math.Ceil(c.height), 1))
+	if c.target == nil {
+		c.target = NewRenderTarget(w, h)
+	} else {
+		c.target.Resize(w, h)
+	}
+
+	var shader Shader = TextHUDShader
+	if c.text.Font.SDF != nil {
+		shader = SDFTextHUDShader
+	}
+
+	// The HUD covers the texture, and the Text isn't scaled by the global scale, as the sprite showing it is
+	viewportScale = engo.Point{X: 1, Y: 1}
+	viewW, viewH := viewSize()
+	viewportScale = engo.Point{X: float32(w) / viewW, Y: float32(h) / viewH}
+	scale := engo.GetGlobalScale()
+	c.ren.Drawable = c.text
+	c.ren.Color = color.White
+	c.ren.Scale = engo.Point{X: 1 / scale.X, Y: 1 / scale.Y}
+
+	c.target.fb.Open(w, h)
+	c.target.tex.Bind()
+	setClearColor(color.Transparent)
+	engo.Gl.Clear(engo.Gl.COLOR_BUFFER_BIT)
+	shader.Pre()
+	shader.Draw(&c.ren, &SpaceComponent{})
+	shader.Post()
+	c.target.fb.Close()
+
+	viewportScale = engo.Point{X: 1, Y: 1}
+	setClearColor(background)
+}
+
+// rasterizeTexts rasterizes the CachedTexts which changed since the last frame.
+func rasterizeTexts() {
+	for _, c := range pendingTexts {
+		if c.pending {
+			c.rasterize()
+			c.pending = false
+		}
+	}
+	pendingTexts = pendingTexts[:0]
+}
@@ -0,0 +1,158 @@
+package common
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func newTestFont(t *testing.T) *Font {
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &CameraTestScene{})
+
+	ttf, err := truetype.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &Font{TTF: ttf, Size: 24}
+	f.face = truetype.NewFace(ttf, &truetype.Options{Size: f.Size, DPI: dpi, Hinting: font.HintingFull})
+	return f
+}
+
+// renderedSize returns the size of the area covered by the characters the TextShader draws for the Text.
+func renderedSize(txt Text) (width, height float32) {
+	s := &textShader{}
+	buf := make([]float32, 20*len(txt.Text))
+	s.generateBufferContent(&RenderComponent{Drawable: txt, Opacity: 1}, &SpaceComponent{}, buf)
+	for i := 0; i < len(buf); i += 5 {
+		if buf[i] > width {
+			width = buf[i]
+		}
+		if buf[i+1] > height {
+			height = buf[i+1]
+		}
+	}
+	return width, height
+}
+
+func TestMeasureText(t *testing.T) {
+	f := newTestFont(t)
+
+	for _, txt := range []Text{
+		{Font: f, Text: "Hello World"},
+		{Font: f, Text: "gypsy jumps"},
+		{Font: f, Text: "two\nlines of text"},
+		{Font: f, Text: "spaced out", LetterSpacing: 0.25},
+		{Font: f, Text: "spaced\nlines", LineSpacing: 0.5},
+	} {
+		width, height := txt.Measure()
+		renderedWidth, renderedHeight := renderedSize(txt)
+		assert.InDelta(t, renderedWidth, width, 3, "Width of %q should match the drawn text", txt.Text)
+		assert.Equal(t, renderedHeight, height, "Height of %q should match the drawn text", txt.Text)
+	}
+
+	w1, h1 := f.MeasureText("a")
+	w2, h2 := f.MeasureText("aa")
+	assert.True(t, w2 > w1, "Longer text should be wider")
+	assert.Equal(t, h1, h2)
+
+	_, h3 := f.MeasureText("a\na")
+	assert.True(t, h3 > h1, "Every line should add to the height")
+}
+
+func TestTextSpacing(t *testing.T) {
+	f := newTestFont(t)
+
+	a, _ := f.MeasureText("A")
+	v, _ := f.MeasureText("V")
+	av, _ := f.MeasureText("AV")
+	assert.InDelta(t, a+v+f.kern('A', 'V'), av, 1e-4, "Kerning pairs of the font should be applied")
+
+	normal, _ := Text{Font: f, Text: "tight"}.Measure()
+	tight, _ := Text{Font: f, Text: "tight", LetterSpacing: -0.1}.Measure()
+	assert.InDelta(t, normal-4*0.1*float32(f.Size), tight, 1e-4, "Negative LetterSpacing should move characters closer together")
+}
+
+func TestDistanceField(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+	draw.Draw(src, image.Rect(5, 5, 15, 15), image.White, image.Point{}, draw.Src)
+
+	fg := color.NRGBA{R: 255, G: 128, B: 0, A: 255}
+	sdf := distanceField(src, 4, fg)
+
+	center := sdf.NRGBAAt(10, 10)
+	assert.Equal(t, uint8(255), center.A, "Pixels deep inside should be at the max distance")
+	assert.Equal(t, fg.R, center.R, "The color of the Font should be kept")
+	assert.Equal(t, fg.G, center.G, "The color of the Font should be kept")
+	assert.Equal(t, uint8(0), sdf.NRGBAAt(0, 0).A, "Pixels far outside should be at the min distance")
+
+	inside, outside := sdf.NRGBAAt(5, 10).A, sdf.NRGBAAt(4, 10).A
+	assert.True(t, inside > 128 && outside < 128, "The edge should lie between %d and %d", inside, outside)
+	assert.True(t, sdf.NRGBAAt(6, 10).A > inside, "Distance should grow towards the inside")
+}
+
+func TestSDFFontAtlas(t *testing.T) {
+	f := newTestFont(t)
+	bitmap := f.generateFontAtlas(UnicodeCap)
+
+	f.SDF = &SDFStyle{OutlineWidth: 2}
+	sdf := f.generateFontAtlas(UnicodeCap)
+
+	assert.Equal(t, float32(0), bitmap.Padding)
+	assert.Equal(t, float32(SDFSpread), sdf.Padding)
+	assert.Equal(t, bitmap.Width['A'], sdf.Width['A'], "Padding should not change the size of the characters")
+	assert.True(t, sdf.TotalHeight > bitmap.TotalHeight, "Padded characters should take up more of the atlas")
+
+	txt := Text{Font: f, Text: "A"}
+	assert.Equal(t, SDFTextShader, (&RenderComponent{Drawable: txt}).Shader(), "SDF fonts should use the SDFTextShader")
+	f.SDF = nil
+	assert.Equal(t, TextShader, (&RenderComponent{Drawable: txt}).Shader())
+}
+
+func TestSDFUniforms(t *testing.T) {
+	assert.Equal(t, float32(0), sdfWidth(0))
+	assert.Equal(t, float32(0.25), sdfWidth(SDFSpread/2))
+	assert.Equal(t, float32(0.5), sdfWidth(SDFSpread*2), "Widths should be limited to the SDFSpread")
+
+	assert.True(t, sdfSmoothing(4) < sdfSmoothing(1), "Scaled up text should have sharper edges")
+
+	r, g, b, a := colorComponents(color.NRGBA{R: 255, A: 255})
+	assert.Equal(t, [4]float32{1, 0, 0, 1}, [4]float32{r, g, b, a})
+	r, g, b, a = colorComponents(nil)
+	assert.Equal(t, [4]float32{}, [4]float32{r, g, b, a})
+}
+
+func TestCachedText(t *testing.T) {
+	f := newTestFont(t)
+	defer func() { pendingTexts = nil }()
+	pendingTexts = nil
+
+	c := NewCachedText(Text{Font: f, Text: "Score: 1"})
+	width, height := Text{Font: f, Text: "Score: 1"}.Measure()
+	assert.Equal(t, width, c.Width())
+	assert.Equal(t, height, c.Height())
+	assert.Equal(t, []*CachedText{c}, pendingTexts, "a new CachedText should be rasterized")
+
+	pendingTexts = pendingTexts[:0]
+	c.pending = false
+	c.SetText("Score: 1")
+	assert.Empty(t, pendingTexts, "setting the same string shouldn't rasterize the text again")
+
+	c.SetText("Score: 10")
+	c.SetText("Score: 100")
+	assert.Equal(t, []*CachedText{c}, pendingTexts, "changing the string should rasterize the text once")
+	width, _ = f.MeasureText("Score: 100")
+	assert.Equal(t, width, c.Width())
+
+	c.Close()
+	assert.False(t, c.pending, "a closed CachedText shouldn't be rasterized")
+}
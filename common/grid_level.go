@@ -0,0 +1,246 @@
+package common
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/klopsch/engo"
+)
+
+// GridLayer is a single layer of tile GIDs, stored row by row. A GID of 0
+// marks an empty cell, and GID n refers to cell n-1 of the tileset, the same
+// convention Tiled uses for a tileset with a FirstGID of 1.
+type GridLayer struct {
+	// Name is the name given to the resulting TileLayer
+	Name string `json:"name"`
+	// Data contains the rows of tile GIDs. Every row must be the same length.
+	Data [][]uint32 `json:"data"`
+}
+
+// gridMap is the JSON schema read by NewLevelFromJSON and the ".tilemap.json"
+// loader.
+type gridMap struct {
+	Tileset     string      `json:"tileset"`
+	TileWidth   int         `json:"tilewidth"`
+	TileHeight  int         `json:"tileheight"`
+	Spacing     int         `json:"spacing"`
+	Orientation string      `json:"orientation"`
+	Layers      []GridLayer `json:"layers"`
+}
+
+// NewLevelFromGrid creates a Level from one or more layers of tile GIDs, using
+// the cells of tileset as the tile images. The Level is orthogonal and uses the
+// right-down render order, so it can be consumed just like one loaded from a
+// TMX file.
+func NewLevelFromGrid(tileset *Spritesheet, tileWidth, tileHeight int, layers ...GridLayer) (*Level, error) {
+	if tileset == nil {
+		return nil, errors.New("grid level needs a tileset")
+	}
+	if tileWidth <= 0 || tileHeight <= 0 {
+		return nil, fmt.Errorf("invalid tile size %dx%d", tileWidth, tileHeight)
+	}
+	if len(layers) == 0 {
+		return nil, errors.New("grid level has no layers")
+	}
+
+	level := &Level{
+		Orientation: orth,
+		RenderOrder: "right-down",
+		TileWidth:   tileWidth,
+		TileHeight:  tileHeight,
+	}
+	level.resourceMap = make(map[uint32]Texture)
+	level.pointMap = make(map[mapPoint]*Tile)
+	level.framesMap = make(map[uint32][]uint32)
+	for i, tex := range tileset.Cells() {
+		level.resourceMap[uint32(i)+1] = tex
+	}
+
+	for i, layer := range layers {
+		w, h, err := gridSize(layer.Data, tileset.CellCount())
+		if err != nil {
+			return nil, fmt.Errorf("layer %d (%q): %v", i, layer.Name, err)
+		}
+		if i == 0 {
+			level.width, level.height = w, h
+		} else if w != level.width || h != level.height {
+			return nil, fmt.Errorf("layer %d (%q) is %dx%d, expected %dx%d", i, layer.Name, w, h, level.width, level.height)
+		}
+
+		tl := &TileLayer{
+			Name:    layer.Name,
+			Width:   w,
+			Height:  h,
+			Opacity: 1,
+			Visible: true,
+		}
+		for y, row := range layer.Data {
+			for x, gid := range row {
+				tile := level.tileFromGID(gid, level.screenPoint(engo.Point{
+					X: float32(x),
+					Y: float32(y),
+				}))
+				tl.Tiles = append(tl.Tiles, tile)
+				level.pointMap[mapPoint{X: x, Y: y}] = tile
+			}
+		}
+		level.TileLayers = append(level.TileLayers, tl)
+	}
+
+	return level, nil
+}
+
+// NewLevelFromCSV creates a single layer Level from comma separated tile GIDs,
+// one row of the map per line. Empty cells are read as GID 0.
+func NewLevelFromCSV(r io.Reader, tileset *Spritesheet, tileWidth, tileHeight int) (*Level, error) {
+	data, err := parseCSVGrid(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewLevelFromGrid(tileset, tileWidth, tileHeight, GridLayer{Data: data})
+}
+
+// NewLevelFromJSON creates a Level from a JSON document of the form
+//
+//	{
+//		"tilewidth": 32,
+//		"tileheight": 32,
+//		"layers": [
+//			{"name": "ground", "data": [[1, 2, 2], [3, 0, 4]]}
+//		]
+//	}
+//
+// The "tileset" and "spacing" fields are only used by the ".tilemap.json"
+// loader, here the tileset is passed in directly.
+func NewLevelFromJSON(r io.Reader, tileset *Spritesheet) (*Level, error) {
+	m, err := parseGridMap(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewLevelFromGrid(tileset, m.TileWidth, m.TileHeight, m.Layers...)
+}
+
+func parseGridMap(r io.Reader) (*gridMap, error) {
+	m := &gridMap{}
+	if err := json.NewDecoder(r).Decode(m); err != nil {
+		return nil, err
+	}
+	if m.Orientation != "" && m.Orientation != orth {
+		return nil, fmt.Errorf("unsupported orientation %q, only %q grids are supported", m.Orientation, orth)
+	}
+	return m, nil
+}
+
+func parseCSVGrid(r io.Reader) ([][]uint32, error) {
+	cr := csv.NewReader(r)
+	// Row lengths are checked by gridSize, which reports them more clearly.
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([][]uint32, len(records))
+	for y, record := range records {
+		data[y] = make([]uint32, len(record))
+		for x, field := range record {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			gid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("row %d, column %d: invalid tile GID %q", y+1, x+1, field)
+			}
+			data[y][x] = uint32(gid)
+		}
+	}
+	return data, nil
+}
+
+// gridSize validates that data is a non-empty rectangle of GIDs within the
+// tileset and returns its width and height in tiles.
+func gridSize(data [][]uint32, cells int) (int, int, error) {
+	if len(data) == 0 || len(data[0]) == 0 {
+		return 0, 0, errors.New("grid is empty")
+	}
+
+	w := len(data[0])
+	for y, row := range data {
+		if len(row) != w {
+			return 0, 0, fmt.Errorf("row %d has %d tiles, expected %d like row 1", y+1, len(row), w)
+		}
+		for x, gid := range row {
+			if gid > uint32(cells) {
+				return 0, 0, fmt.Errorf("row %d, column %d: tile GID %d is outside the tileset (%d tiles)", y+1, x+1, gid, cells)
+			}
+		}
+	}
+	return w, len(data), nil
+}
+
+// gridLoader is responsible for managing '.tilemap.json' files within
+// 'engo.Files'. The tileset image referenced by the file is loaded relative to
+// it, and the resulting level is stored as a TMXResource so it can be used
+// anywhere a TMX level is.
+type gridLoader struct {
+	levels map[string]TMXResource
+}
+
+// Load will load the tilemap file and the tileset image it references
+func (g *gridLoader) Load(url string, data io.Reader) error {
+	m, err := parseGridMap(data)
+	if err != nil {
+		return err
+	}
+	if m.Tileset == "" {
+		return fmt.Errorf("tilemap %q does not reference a tileset", url)
+	}
+
+	tilesetURL := path.Join(path.Dir(url), m.Tileset)
+	if _, err := LoadedSprite(tilesetURL); err != nil {
+		if !strings.HasPrefix(err.Error(), "resource not loaded") {
+			return err
+		}
+		if err = engo.Files.Load(tilesetURL); err != nil {
+			return err
+		}
+	}
+	tileset := NewSpritesheetWithBorderFromFile(tilesetURL, m.TileWidth, m.TileHeight, m.Spacing, m.Spacing)
+
+	lvl, err := NewLevelFromGrid(tileset, m.TileWidth, m.TileHeight, m.Layers...)
+	if err != nil {
+		return fmt.Errorf("tilemap %q: %v", url, err)
+	}
+
+	g.levels[url] = TMXResource{Level: lvl, url: url}
+	return nil
+}
+
+// Unload removes the preloaded level from the cache
+func (g *gridLoader) Unload(url string) error {
+	delete(g.levels, url)
+	return nil
+}
+
+// Resource retrieves and returns the preloaded level of type 'TMXResource'
+func (g *gridLoader) Resource(url string) (engo.Resource, error) {
+	lvl, ok := g.levels[url]
+	if !ok {
+		return nil, fmt.Errorf("resource not loaded by `FileLoader`: %q", url)
+	}
+
+	return lvl, nil
+}
+
+func init() {
+	engo.Files.Register(".tilemap.json", &gridLoader{levels: make(map[string]TMXResource)})
+}
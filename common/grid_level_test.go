@@ -0,0 +1,93 @@
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func newGridTestTileset() *Spritesheet {
+	tr := &TextureResource{Width: 64, Height: 32, url: "grid_test.png"}
+	return NewSpritesheetFromTexture(tr, 16, 16)
+}
+
+func TestNewLevelFromCSV(t *testing.T) {
+	tileset := newGridTestTileset()
+	lvl, err := NewLevelFromCSV(strings.NewReader("1,2,3\n0, 8,\n"), tileset, 16, 16)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, 3, lvl.Width())
+	assert.Equal(t, 2, lvl.Height())
+	if !assert.Len(t, lvl.TileLayers, 1) {
+		return
+	}
+	layer := lvl.TileLayers[0]
+	assert.Equal(t, 3, layer.Width)
+	assert.Equal(t, 2, layer.Height)
+	assert.Len(t, layer.Tiles, 6)
+
+	tile := lvl.GetTile(engo.Point{X: 20, Y: 20})
+	if assert.NotNil(t, tile) {
+		assert.Equal(t, engo.Point{X: 16, Y: 16}, tile.Point)
+		assert.Equal(t, tileset.Cell(7), *tile.Image, "GID 8 should be the 8th cell of the tileset")
+	}
+	assert.Equal(t, Texture{}, *layer.Tiles[5].Image, "Empty cells should have GID 0")
+}
+
+func TestNewLevelFromJSON(t *testing.T) {
+	doc := `{
+		"tilewidth": 16,
+		"tileheight": 16,
+		"layers": [
+			{"name": "ground", "data": [[1, 1], [2, 2]]},
+			{"name": "props", "data": [[0, 5], [0, 0]]}
+		]
+	}`
+	lvl, err := NewLevelFromJSON(strings.NewReader(doc), newGridTestTileset())
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Len(t, lvl.TileLayers, 2) {
+		assert.Equal(t, "ground", lvl.TileLayers[0].Name)
+		assert.Equal(t, "props", lvl.TileLayers[1].Name)
+		assert.True(t, lvl.TileLayers[1].Visible)
+		assert.Equal(t, float32(1), lvl.TileLayers[1].Opacity)
+	}
+}
+
+func TestNewLevelFromGridErrors(t *testing.T) {
+	tileset := newGridTestTileset()
+
+	_, err := NewLevelFromCSV(strings.NewReader("1,2,3\n4,5\n"), tileset, 16, 16)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "row 2 has 2 tiles, expected 3")
+	}
+
+	_, err = NewLevelFromCSV(strings.NewReader("1,x\n"), tileset, 16, 16)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "row 1, column 2")
+	}
+
+	_, err = NewLevelFromCSV(strings.NewReader("1,9\n"), tileset, 16, 16)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "outside the tileset")
+	}
+
+	_, err = NewLevelFromGrid(tileset, 16, 16,
+		GridLayer{Name: "a", Data: [][]uint32{{1, 1}}},
+		GridLayer{Name: "b", Data: [][]uint32{{1, 1, 1}}},
+	)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "is 3x1, expected 2x1")
+	}
+
+	_, err = NewLevelFromGrid(tileset, 16, 16, GridLayer{})
+	assert.Error(t, err, "Empty grids should be rejected")
+
+	_, err = NewLevelFromJSON(strings.NewReader(`{"orientation": "isometric", "layers": []}`), tileset)
+	assert.Error(t, err, "Only orthogonal grids are supported")
+}
@@ -39,6 +39,16 @@ func (c *AnimationComponent) GetAnimationComponent() *AnimationComponent {
 	return c
 }
 
+// GetSkeletonComponent Provides container classes ability to fulfil the interface and be accessed more simply by systems, eg in AddByInterface Methods
+func (c *SkeletonComponent) GetSkeletonComponent() *SkeletonComponent {
+	return c
+}
+
+// GetScriptComponent Provides container classes ability to fulfil the interface and be accessed more simply by systems, eg in AddByInterface Methods
+func (c *ScriptComponent) GetScriptComponent() *ScriptComponent {
+	return c
+}
+
 // GetMouseComponent Provides container classes ability to fulfil the interface and be accessed more simply by systems, eg in AddByInterface Methods
 func (c *MouseComponent) GetMouseComponent() *MouseComponent {
 	return c
@@ -64,6 +74,46 @@ func (c *CollisionComponent) GetCollisionComponent() *CollisionComponent {
 	return c
 }
 
+// GetTweenComponent Provides container classes ability to fulfil the interface and be accessed more simply by systems, eg in AddByInterface Methods
+func (c *TweenComponent) GetTweenComponent() *TweenComponent {
+	return c
+}
+
+// GetParallaxComponent Provides container classes ability to fulfil the interface and be accessed more simply by systems, eg in AddByInterface Methods
+func (c *ParallaxComponent) GetParallaxComponent() *ParallaxComponent {
+	return c
+}
+
+// GetLightComponent Provides container classes ability to fulfil the interface and be accessed more simply by systems, eg in AddByInterface Methods
+func (c *LightComponent) GetLightComponent() *LightComponent {
+	return c
+}
+
+// GetOccluderComponent Provides container classes ability to fulfil the interface and be accessed more simply by systems, eg in AddByInterface Methods
+func (c *OccluderComponent) GetOccluderComponent() *OccluderComponent {
+	return c
+}
+
+// GetParticleComponent Provides container classes ability to fulfil the interface and be accessed more simply by systems, eg in AddByInterface Methods
+func (c *ParticleComponent) GetParticleComponent() *ParticleComponent {
+	return c
+}
+
+// GetDraggableComponent Provides container classes ability to fulfil the interface and be accessed more simply by systems, eg in AddByInterface Methods
+func (c *DraggableComponent) GetDraggableComponent() *DraggableComponent {
+	return c
+}
+
+// GetTextInputComponent Provides container classes ability to fulfil the interface and be accessed more simply by systems, eg in AddByInterface Methods
+func (c *TextInputComponent) GetTextInputComponent() *TextInputComponent {
+	return c
+}
+
+// GetInterpolationComponent Provides container classes ability to fulfil the interface and be accessed more simply by systems, eg in AddByInterface Methods
+func (c *InterpolationComponent) GetInterpolationComponent() *InterpolationComponent {
+	return c
+}
+
 // Faces
 
 // BasicFace is the means of accessing the ecs.BasicEntity class , it also has the ID method, to simplify, finding an item within a system
@@ -77,6 +127,16 @@ type AnimationFace interface {
 	GetAnimationComponent() *AnimationComponent
 }
 
+// SkeletonFace allows typesafe access to an anonymous SkeletonComponent
+type SkeletonFace interface {
+	GetSkeletonComponent() *SkeletonComponent
+}
+
+// ScriptFace allows typesafe access to an anonymous ScriptComponent
+type ScriptFace interface {
+	GetScriptComponent() *ScriptComponent
+}
+
 // MouseFace allows typesafe access to an Anonymous child MouseComponent
 type MouseFace interface {
 	GetMouseComponent() *MouseComponent
@@ -102,6 +162,46 @@ type CollisionFace interface {
 	GetCollisionComponent() *CollisionComponent
 }
 
+// TweenFace allows typesafe access to an anonymous TweenComponent
+type TweenFace interface {
+	GetTweenComponent() *TweenComponent
+}
+
+// ParallaxFace allows typesafe access to an anonymous ParallaxComponent
+type ParallaxFace interface {
+	GetParallaxComponent() *ParallaxComponent
+}
+
+// LightFace allows typesafe access to an anonymous LightComponent
+type LightFace interface {
+	GetLightComponent() *LightComponent
+}
+
+// OccluderFace allows typesafe access to an anonymous OccluderComponent
+type OccluderFace interface {
+	GetOccluderComponent() *OccluderComponent
+}
+
+// ParticleFace allows typesafe access to an anonymous ParticleComponent
+type ParticleFace interface {
+	GetParticleComponent() *ParticleComponent
+}
+
+// DraggableFace allows typesafe access to an anonymous DraggableComponent
+type DraggableFace interface {
+	GetDraggableComponent() *DraggableComponent
+}
+
+// TextInputFace allows typesafe access to an anonymous TextInputComponent
+type TextInputFace interface {
+	GetTextInputComponent() *TextInputComponent
+}
+
+// InterpolationFace allows typesafe access to an anonymous InterpolationComponent
+type InterpolationFace interface {
+	GetInterpolationComponent() *InterpolationComponent
+}
+
 // Combined for systems
 
 // Animationable is the required interface for AnimationSystem.AddByInterface method
@@ -111,6 +211,25 @@ type Animationable interface {
 	RenderFace
 }
 
+// Skeletonable is the required interface for the SkeletonSystem.AddByInterface method
+type Skeletonable interface {
+	BasicFace
+	SkeletonFace
+	RenderFace
+}
+
+// Scriptable is the required interface for the ScriptSystem.AddByInterface method
+type Scriptable interface {
+	BasicFace
+	ScriptFace
+}
+
+// Quadtreeable is the required interface for the QuadtreeSystem.AddByInterface method
+type Quadtreeable interface {
+	BasicFace
+	SpaceFace
+}
+
 // Mouseable is the required interface for the MouseSystem AddByInterface method
 type Mouseable interface {
 	BasicFace
@@ -139,6 +258,68 @@ type Collisionable interface {
 	SpaceFace
 }
 
+// Tweenable is the required interface for the TweenSystem.AddByInterface method
+type Tweenable interface {
+	BasicFace
+	TweenFace
+}
+
+// Parallaxable is the required interface for the ParallaxSystem.AddByInterface method
+type Parallaxable interface {
+	BasicFace
+	ParallaxFace
+	RenderFace
+	SpaceFace
+}
+
+// Lightable is the required interface for the LightSystem.AddByInterface method
+type Lightable interface {
+	BasicFace
+	LightFace
+	SpaceFace
+}
+
+// Occludable is the interface for adding occluders with the LightSystem.AddByInterface method
+type Occludable interface {
+	BasicFace
+	OccluderFace
+	SpaceFace
+}
+
+// Particleable is the required interface for the ParticleSystem.AddByInterface method
+type Particleable interface {
+	BasicFace
+	ParticleFace
+	SpaceFace
+}
+
+// Draggable is the required interface for the DragSystem.AddByInterface method
+type Draggable interface {
+	BasicFace
+	DraggableFace
+	MouseFace
+	SpaceFace
+}
+
+// TextInputable is the required interface for the TextInputSystem.AddByInterface method
+type TextInputable interface {
+	BasicFace
+	TextInputFace
+	RenderFace
+}
+
+// Interpolationable is the required interface for the InterpolationSystem.AddByInterface method
+type Interpolationable interface {
+	BasicFace
+	InterpolationFace
+	SpaceFace
+}
+
+// Saveable is the required interface for the SaveSystem.AddByInterface method
+type Saveable interface {
+	BasicFace
+}
+
 // Not-Ables
 
 // NotAnimationComponent is used to flag an entity as not in the AnimationSystem
@@ -156,6 +337,51 @@ type NotAnimationable interface {
 	GetNotAnimationComponent() *NotAnimationComponent
 }
 
+// NotSkeletonComponent is used to flag an entity as not in the SkeletonSystem
+// even if it has the proper components
+type NotSkeletonComponent struct{}
+
+// GetNotSkeletonComponent implements the NotSkeletonable interface
+func (n *NotSkeletonComponent) GetNotSkeletonComponent() *NotSkeletonComponent {
+	return n
+}
+
+// NotSkeletonable is an interface used to flag an entity as not in the
+// SkeletonSystem even if it has the proper components
+type NotSkeletonable interface {
+	GetNotSkeletonComponent() *NotSkeletonComponent
+}
+
+// NotScriptComponent is used to flag an entity as not in the ScriptSystem
+// even if it has the proper components
+type NotScriptComponent struct{}
+
+// GetNotScriptComponent implements the NotScriptable interface
+func (n *NotScriptComponent) GetNotScriptComponent() *NotScriptComponent {
+	return n
+}
+
+// NotScriptable is an interface used to flag an entity as not in the
+// ScriptSystem even if it has the proper components
+type NotScriptable interface {
+	GetNotScriptComponent() *NotScriptComponent
+}
+
+// NotQuadtreeComponent is used to flag an entity as not in the QuadtreeSystem
+// even if it has the proper components
+type NotQuadtreeComponent struct{}
+
+// GetNotQuadtreeComponent implements the NotQuadtreeable interface
+func (n *NotQuadtreeComponent) GetNotQuadtreeComponent() *NotQuadtreeComponent {
+	return n
+}
+
+// NotQuadtreeable is an interface used to flag an entity as not in the
+// QuadtreeSystem even if it has the proper components
+type NotQuadtreeable interface {
+	GetNotQuadtreeComponent() *NotQuadtreeComponent
+}
+
 // NotMouseComponent is used to flag an entity as not in the MouseSystem even if
 // it has the proper components
 type NotMouseComponent struct{}
@@ -215,3 +441,138 @@ func (n *NotCollisionComponent) GetNotCollisionComponent() *NotCollisionComponen
 type NotCollisionable interface {
 	GetNotCollisionComponent() *NotCollisionComponent
 }
+
+// NotTweenComponent is used to flag an entity as not in the TweenSystem even if
+// it has the proper components
+type NotTweenComponent struct{}
+
+// GetNotTweenComponent implements the NotTweenable interface
+func (n *NotTweenComponent) GetNotTweenComponent() *NotTweenComponent {
+	return n
+}
+
+// NotTweenable is an interface used to flag an entity as not in the TweenSystem
+// even if it has the proper components
+type NotTweenable interface {
+	GetNotTweenComponent() *NotTweenComponent
+}
+
+// NotParallaxComponent is used to flag an entity as not in the ParallaxSystem
+// even if it has the proper components
+type NotParallaxComponent struct{}
+
+// GetNotParallaxComponent implements the NotParallaxable interface
+func (n *NotParallaxComponent) GetNotParallaxComponent() *NotParallaxComponent {
+	return n
+}
+
+// NotParallaxable is an interface used to flag an entity as not in the
+// ParallaxSystem even if it has the proper components
+type NotParallaxable interface {
+	GetNotParallaxComponent() *NotParallaxComponent
+}
+
+// NotLightComponent is used to flag an entity as not in the LightSystem even
+// if it has the proper components
+type NotLightComponent struct{}
+
+// GetNotLightComponent implements the NotLightable interface
+func (n *NotLightComponent) GetNotLightComponent() *NotLightComponent {
+	return n
+}
+
+// NotLightable is an interface used to flag an entity as not in the
+// LightSystem even if it has the proper components
+type NotLightable interface {
+	GetNotLightComponent() *NotLightComponent
+}
+
+// NotOccluderComponent is used to flag an entity as not an occluder in the
+// LightSystem even if it has the proper components
+type NotOccluderComponent struct{}
+
+// GetNotOccluderComponent implements the NotOccludable interface
+func (n *NotOccluderComponent) GetNotOccluderComponent() *NotOccluderComponent {
+	return n
+}
+
+// NotOccludable is an interface used to flag an entity as not an occluder in
+// the LightSystem even if it has the proper components
+type NotOccludable interface {
+	GetNotOccluderComponent() *NotOccluderComponent
+}
+
+// NotParticleComponent is used to flag an entity as not in the ParticleSystem
+// even if it has the proper components
+type NotParticleComponent struct{}
+
+// GetNotParticleComponent implements the NotParticleable interface
+func (n *NotParticleComponent) GetNotParticleComponent() *NotParticleComponent {
+	return n
+}
+
+// NotParticleable is an interface used to flag an entity as not in the
+// ParticleSystem even if it has the proper components
+type NotParticleable interface {
+	GetNotParticleComponent() *NotParticleComponent
+}
+
+// NotDraggableComponent is used to flag an entity as not in the DragSystem
+// even if it has the proper components
+type NotDraggableComponent struct{}
+
+// GetNotDraggableComponent implements the NotDraggable interface
+func (n *NotDraggableComponent) GetNotDraggableComponent() *NotDraggableComponent {
+	return n
+}
+
+// NotDraggable is an interface used to flag an entity as not in the
+// DragSystem even if it has the proper components
+type NotDraggable interface {
+	GetNotDraggableComponent() *NotDraggableComponent
+}
+
+// NotTextInputComponent is used to flag an entity as not in the
+// TextInputSystem even if it has the proper components
+type NotTextInputComponent struct{}
+
+// GetNotTextInputComponent implements the NotTextInputable interface
+func (n *NotTextInputComponent) GetNotTextInputComponent() *NotTextInputComponent {
+	return n
+}
+
+// NotTextInputable is an interface used to flag an entity as not in the
+// TextInputSystem even if it has the proper components
+type NotTextInputable interface {
+	GetNotTextInputComponent() *NotTextInputComponent
+}
+
+// NotInterpolationComponent is used to flag an entity as not in the
+// InterpolationSystem even if it has the proper components
+type NotInterpolationComponent struct{}
+
+// GetNotInterpolationComponent implements the NotInterpolationable interface
+func (n *NotInterpolationComponent) GetNotInterpolationComponent() *NotInterpolationComponent {
+	return n
+}
+
+// NotInterpolationable is an interface used to flag an entity as not in the
+// InterpolationSystem even if it has the proper components
+type NotInterpolationable interface {
+	GetNotInterpolationComponent() *NotInterpolationComponent
+}
+
+// NotSaveComponent is used to flag an entity as not in the SaveSystem even if
+// it has the proper components
+type NotSaveComponent struct{}
+
+// GetNotSaveComponent implements the NotSaveable interface
+func (n *NotSaveComponent) GetNotSaveComponent() *NotSaveComponent {
+	return n
+}
+
+// NotSaveable is an interface used to flag an entity as not in the SaveSystem
+// even if it has the proper components
+type NotSaveable interface {
+	GetNotSaveComponent() *NotSaveComponent
+}
@@ -61,6 +61,9 @@ type Resampling struct {
 	srcBufL      map[int64][]float64
 	srcBufR      map[int64][]float64
 	lruSrcBlocks []int64
+	// srcEnd is the amount of frames in the source once it has been read to
+	// its end, or -1 before, which ends streams of an unknown size
+	srcEnd int64
 }
 
 func NewResampling(source ReadSeekCloser, size int64, from, to int) *Resampling {
@@ -72,13 +75,20 @@ func NewResampling(source ReadSeekCloser, size int64, from, to int) *Resampling
 		srcBlock: -1,
 		srcBufL:  map[int64][]float64{},
 		srcBufR:  map[int64][]float64{},
+		srcEnd:   -1,
 	}
 	return r
 }
 
+// frames returns the amount of stereo 16-bit frames in the source. A trailing
+// partial frame counts as a full one and is zero-padded when read.
+func (r *Resampling) frames() int64 {
+	return (r.size + 3) / 4
+}
+
 func (r *Resampling) Length() int64 {
-	s := int64(float64(r.size) * float64(r.to) / float64(r.from))
-	return s / 4 * 4
+	s := int64(float64(r.frames()) * float64(r.to) / float64(r.from))
+	return s * 4
 }
 
 func (r *Resampling) src(i int64) (float64, float64, error) {
@@ -87,7 +97,7 @@ func (r *Resampling) src(i int64) (float64, float64, error) {
 	if i < 0 {
 		return 0, 0, nil
 	}
-	if r.size/4 <= i {
+	if r.frames() <= i {
 		return 0, 0, nil
 	}
 	nextPos := i / resamplingBufferSize
@@ -104,12 +114,14 @@ func (r *Resampling) src(i int64) (float64, float64, error) {
 			c += n
 			if err != nil {
 				if err == io.EOF {
+					r.srcEnd = nextPos*resamplingBufferSize + int64(c+3)/4
 					break
 				}
 				return 0, 0, err
 			}
 		}
-		buf = buf[:c]
+		// Pad a partial final frame with zeros instead of dropping it.
+		buf = buf[:(c+3)/4*4]
 		sl := make([]float64, resamplingBufferSize)
 		sr := make([]float64, resamplingBufferSize)
 		for i := 0; i < len(buf)/4; i++ {
@@ -153,12 +165,12 @@ func (r *Resampling) at(t int64) (float64, float64, error) {
 	if startN < 0 {
 		startN = 0
 	}
-	if r.size/4 <= startN {
-		startN = r.size/4 - 1
+	if r.frames() <= startN {
+		startN = r.frames() - 1
 	}
 	endN := int64(tInSrc + windowSize)
-	if r.size/4 <= endN {
-		endN = r.size/4 - 1
+	if r.frames() <= endN {
+		endN = r.frames() - 1
 	}
 	lv := 0.0
 	rv := 0.0
@@ -188,15 +200,33 @@ func (r *Resampling) at(t int64) (float64, float64, error) {
 	return lv, rv, nil
 }
 
+// end returns the length of the resampled source, or where it ends if it's
+// shorter than its size, as streams are.
+func (r *Resampling) end() int64 {
+	if r.srcEnd < 0 {
+		return r.Length()
+	}
+	end := int64(float64(r.srcEnd)*float64(r.to)/float64(r.from)) * 4
+	if end > r.Length() {
+		return r.Length()
+	}
+	return end
+}
+
 func (r *Resampling) Read(b []uint8) (int, error) {
-	if r.pos == r.Length() {
+	if r.pos >= r.end() {
 		return 0, io.EOF
 	}
 	n := len(b) / 4 * 4
-	if r.Length()-r.pos <= int64(n) {
-		n = int(r.Length() - r.pos)
+	if r.end()-r.pos <= int64(n) {
+		n = int(r.end() - r.pos)
 	}
 	for i := 0; i < n/4; i++ {
+		// The end of a stream is only known once it's read
+		if r.pos+int64(4*i) >= r.end() {
+			n = 4 * i
+			break
+		}
 		l, r, err := r.at(r.pos/4 + int64(i))
 		if err != nil {
 			return 0, err
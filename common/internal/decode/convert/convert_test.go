@@ -0,0 +1,46 @@
+package convert
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+func TestResamplingUnalignedSize(t *testing.T) {
+	const (
+		frames = 10
+		left   = 1000
+		right  = 2000
+	)
+	// frames complete frames followed by a partial frame holding only the
+	// left sample.
+	b := make([]uint8, 0, 4*frames+2)
+	for i := 0; i < frames; i++ {
+		b = append(b, uint8(left&0xff), uint8(left>>8), uint8(right&0xff), uint8(right>>8))
+	}
+	b = append(b, uint8(left&0xff), uint8(left>>8))
+
+	r := NewResampling(nopCloser{bytes.NewReader(b)}, int64(len(b)), 44100, 44100)
+	if expected := int64(4 * (frames + 1)); r.Length() != expected {
+		t.Fatalf("Length expected=%d ; got=%d", expected, r.Length())
+	}
+
+	out, err := ioutil.ReadAll(r)
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	if len(out) != 4*(frames+1) {
+		t.Fatalf("read expected=%d bytes ; got=%d", 4*(frames+1), len(out))
+	}
+
+	last := out[4*frames:]
+	l := int16(last[0]) | int16(last[1])<<8
+	rr := int16(last[2]) | int16(last[3])<<8
+	if l < left-5 || l > left+5 {
+		t.Errorf("partial frame left sample expected=%d ; got=%d", left, l)
+	}
+	if rr < -5 || rr > 5 {
+		t.Errorf("partial frame right sample expected=0 ; got=%d", rr)
+	}
+}
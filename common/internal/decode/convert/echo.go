@@ -0,0 +1,265 @@
+package convert
+
+import (
+	"sync"
+)
+
+// readSample returns the 16-bit sample at the start of b.
+func readSample(b []uint8) float64 {
+	return float64(int16(b[0]) | int16(b[1])<<8)
+}
+
+// writeSample writes v at the start of b as a 16-bit sample, clipping it to the range of int16.
+func writeSample(b []uint8, v float64) {
+	if v > 1<<15-1 {
+		v = 1<<15 - 1
+	}
+	if v < -(1 << 15) {
+		v = -(1 << 15)
+	}
+	s := int16(v)
+	b[0] = uint8(s)
+	b[1] = uint8(s >> 8)
+}
+
+// clampMix clamps a dry/wet mix to [0, 1].
+func clampMix(mix float64) float64 {
+	if mix < 0 {
+		return 0
+	}
+	if mix > 1 {
+		return 1
+	}
+	return mix
+}
+
+// delayLine is a ring buffer holding the last samples of a channel.
+type delayLine struct {
+	buf []float64
+	pos int
+}
+
+func newDelayLine(length int64) delayLine {
+	if length < 1 {
+		length = 1
+	}
+	return delayLine{buf: make([]float64, length)}
+}
+
+// comb returns the sample delayed by the length of the line, and feeds x plus the delayed sample times the feedback
+// back into it.
+func (d *delayLine) comb(x, feedback float64) float64 {
+	delayed := d.buf[d.pos]
+	d.buf[d.pos] = x + delayed*feedback
+	d.pos++
+	if d.pos == len(d.buf) {
+		d.pos = 0
+	}
+	return delayed
+}
+
+// allpass passes x through an allpass filter, which smears it in time without changing its frequencies.
+func (d *delayLine) allpass(x, feedback float64) float64 {
+	delayed := d.buf[d.pos]
+	d.buf[d.pos] = x + delayed*feedback
+	d.pos++
+	if d.pos == len(d.buf) {
+		d.pos = 0
+	}
+	return delayed - x*feedback
+}
+
+func (d *delayLine) reset() {
+	for i := range d.buf {
+		d.buf[i] = 0
+	}
+	d.pos = 0
+}
+
+// Echo repeats stereo 16-bit audio after a delay, every repetition quieter than the previous one. The mix can be
+// changed while the audio is being read.
+type Echo struct {
+	frames
+
+	mu            sync.Mutex
+	left, right   delayLine
+	feedback, mix float64
+}
+
+// NewEcho creates an Echo that reads from source and repeats it every delay frames, multiplying the repetitions by
+// feedback, which should be below 1 for them to fade out. mix is how much of the echo is heard, from 0 for only the
+// original audio to 1 for only the echo. The source may be nil if the Echo is only used through Process.
+func NewEcho(source ReadSeekCloser, delay int64, feedback, mix float64) *Echo {
+	return &Echo{
+		frames:   frames{source: source},
+		left:     newDelayLine(delay),
+		right:    newDelayLine(delay),
+		feedback: feedback,
+		mix:      clampMix(mix),
+	}
+}
+
+// SetMix changes how much of the echo is heard, from 0 to 1.
+func (e *Echo) SetMix(mix float64) {
+	e.mu.Lock()
+	e.mix = clampMix(mix)
+	e.mu.Unlock()
+}
+
+// Mix returns how much of the echo is heard.
+func (e *Echo) Mix() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.mix
+}
+
+// Process applies the echo to the whole frames in b, in place.
+func (e *Echo) Process(b []uint8) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i := 0; i+4 <= len(b); i += 4 {
+		l, r := readSample(b[i:]), readSample(b[i+2:])
+		writeSample(b[i:], l*(1-e.mix)+e.left.comb(l, e.feedback)*e.mix)
+		writeSample(b[i+2:], r*(1-e.mix)+e.right.comb(r, e.feedback)*e.mix)
+	}
+}
+
+// Read reads whole frames from the underlying source and applies the echo to them.
+func (e *Echo) Read(b []uint8) (int, error) {
+	n, err := e.read(b)
+	e.Process(b[:n])
+	return n, err
+}
+
+// Seek seeks the underlying source, and forgets the echo of the audio before.
+func (e *Echo) Seek(offset int64, whence int) (int64, error) {
+	e.mu.Lock()
+	e.left.reset()
+	e.right.reset()
+	e.mu.Unlock()
+	return e.seek(offset, whence)
+}
+
+// Close closes the underlying source.
+func (e *Echo) Close() error {
+	return e.source.Close()
+}
+
+// The delays of the comb and allpass filters of the Reverb in frames at 44100Hz, from Freeverb. The right channel
+// uses slightly longer ones, so it doesn't sound the same as the left one.
+var (
+	reverbCombs     = []int64{1116, 1188, 1277, 1356, 1422, 1491, 1557, 1617}
+	reverbAllpasses = []int64{556, 441, 341, 225}
+)
+
+const reverbStereoSpread = 23
+
+// reverbChannel is the filters of a channel of a Reverb.
+type reverbChannel struct {
+	combs     []delayLine
+	allpasses []delayLine
+}
+
+func newReverbChannel(spread int64) reverbChannel {
+	var c reverbChannel
+	for _, d := range reverbCombs {
+		c.combs = append(c.combs, newDelayLine(d+spread))
+	}
+	for _, d := range reverbAllpasses {
+		c.allpasses = append(c.allpasses, newDelayLine(d+spread))
+	}
+	return c
+}
+
+func (c *reverbChannel) process(x, feedback float64) float64 {
+	// Scale the input down, as the combs add up
+	in := x * 0.015
+	var out float64
+	for i := range c.combs {
+		out += c.combs[i].comb(in, feedback)
+	}
+	for i := range c.allpasses {
+		out = c.allpasses[i].allpass(out, 0.5)
+	}
+	return out * 3
+}
+
+func (c *reverbChannel) reset() {
+	for i := range c.combs {
+		c.combs[i].reset()
+	}
+	for i := range c.allpasses {
+		c.allpasses[i].reset()
+	}
+}
+
+// Reverb makes stereo 16-bit audio sound like it's played in a room, such as a cave or a hall, with a Schroeder
+// reverberator made of parallel comb filters followed by allpass filters. The mix can be changed while the audio is
+// being read.
+type Reverb struct {
+	frames
+
+	mu          sync.Mutex
+	left, right reverbChannel
+	feedback    float64
+	mix         float64
+}
+
+// NewReverb creates a Reverb that reads from source. roomSize ranges from 0 for a small room to 1 for a large hall,
+// which reverberates longer. mix is how much of the reverberation is heard, from 0 for only the original audio to 1
+// for only the reverberation. The source may be nil if the Reverb is only used through Process.
+func NewReverb(source ReadSeekCloser, roomSize, mix float64) *Reverb {
+	return &Reverb{
+		frames:   frames{source: source},
+		left:     newReverbChannel(0),
+		right:    newReverbChannel(reverbStereoSpread),
+		feedback: 0.7 + 0.28*clampMix(roomSize),
+		mix:      clampMix(mix),
+	}
+}
+
+// SetMix changes how much of the reverberation is heard, from 0 to 1.
+func (r *Reverb) SetMix(mix float64) {
+	r.mu.Lock()
+	r.mix = clampMix(mix)
+	r.mu.Unlock()
+}
+
+// Mix returns how much of the reverberation is heard.
+func (r *Reverb) Mix() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.mix
+}
+
+// Process applies the reverberation to the whole frames in b, in place.
+func (r *Reverb) Process(b []uint8) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := 0; i+4 <= len(b); i += 4 {
+		left, right := readSample(b[i:]), readSample(b[i+2:])
+		writeSample(b[i:], left*(1-r.mix)+r.left.process(left, r.feedback)*r.mix)
+		writeSample(b[i+2:], right*(1-r.mix)+r.right.process(right, r.feedback)*r.mix)
+	}
+}
+
+// Read reads whole frames from the underlying source and applies the reverberation to them.
+func (r *Reverb) Read(b []uint8) (int, error) {
+	n, err := r.read(b)
+	r.Process(b[:n])
+	return n, err
+}
+
+// Seek seeks the underlying source, and forgets the reverberation of the audio before.
+func (r *Reverb) Seek(offset int64, whence int) (int64, error) {
+	r.mu.Lock()
+	r.left.reset()
+	r.right.reset()
+	r.mu.Unlock()
+	return r.seek(offset, whence)
+}
+
+// Close closes the underlying source.
+func (r *Reverb) Close() error {
+	return r.source.Close()
+}
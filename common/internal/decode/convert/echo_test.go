@@ -0,0 +1,80 @@
+package convert
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// impulseSource returns a source of stereo 16-bit frames which are silent, except for the first one.
+func impulseSource(sample int16, frames int) ReadSeekCloser {
+	b := make([]uint8, 4*frames)
+	b[0], b[1] = uint8(sample), uint8(sample>>8)
+	b[2], b[3] = uint8(sample), uint8(sample>>8)
+	return nopCloser{bytes.NewReader(b)}
+}
+
+func TestEcho(t *testing.T) {
+	e := NewEcho(impulseSource(10000, 10), 3, 0.5, 0.5)
+	left, right := readFrames(t, e)
+	expected := []int16{5000, 0, 0, 5000, 0, 0, 2500, 0, 0, 1250}
+	for i := range expected {
+		if left[i] != expected[i] || right[i] != expected[i] {
+			t.Errorf("frame %d expected=%d ; got=%d, %d", i, expected[i], left[i], right[i])
+		}
+	}
+
+	// Seeking forgets the echo
+	if _, err := e.Seek(4, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	left, _ = readFrames(t, e)
+	for i, l := range left {
+		if l != 0 {
+			t.Errorf("frame %d after seeking expected=0 ; got=%d", i+1, l)
+		}
+	}
+
+	e.SetMix(2)
+	if e.Mix() != 1 {
+		t.Errorf("mix expected=1 ; got=%v", e.Mix())
+	}
+}
+
+func TestReverb(t *testing.T) {
+	r := NewReverb(impulseSource(20000, 5000), 0.5, 0.5)
+	first, _ := readFrames(t, r)
+	if first[0] != 10000 {
+		t.Errorf("dry frame expected=10000 ; got=%d", first[0])
+	}
+	tail := false
+	for _, l := range first[2000:] {
+		if l != 0 {
+			tail = true
+			break
+		}
+	}
+	if !tail {
+		t.Error("The reverberation should last after the longest delay")
+	}
+
+	// Seeking back to the start plays the same reverberation
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	second, _ := readFrames(t, r)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("frame %d after seeking expected=%d ; got=%d", i, first[i], second[i])
+		}
+	}
+
+	// Without mix, the audio is unchanged
+	r = NewReverb(impulseSource(20000, 2000), 1, 0)
+	left, _ := readFrames(t, r)
+	for i, l := range left {
+		if (i == 0 && l != 20000) || (i > 0 && l != 0) {
+			t.Fatalf("frame %d without mix expected=%d ; got=%d", i, map[bool]int16{true: 20000}[i == 0], l)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package convert
+
+import (
+	"sync"
+)
+
+// frames reads whole stereo 16-bit frames from a source. The bytes of a frame
+// that is split across reads are kept and returned with the next Read.
+type frames struct {
+	source  ReadSeekCloser
+	partial [4]uint8
+	pending int
+}
+
+func (f *frames) read(b []uint8) (int, error) {
+	b = b[:len(b)&^3]
+	if len(b) == 0 {
+		return 0, nil
+	}
+	copy(b, f.partial[:f.pending])
+	n, err := f.source.Read(b[f.pending:])
+	n += f.pending
+	whole := n &^ 3
+	f.pending = copy(f.partial[:], b[whole:n])
+	return whole, err
+}
+
+func (f *frames) seek(offset int64, whence int) (int64, error) {
+	f.pending = 0
+	return f.source.Seek(offset, whence)
+}
+
+// scaleFrames multiplies the left and right samples of every whole stereo
+// 16-bit frame in b, clipping them to the range of int16.
+func scaleFrames(b []uint8, left, right float64) {
+	if left == 1 && right == 1 {
+		return
+	}
+	for i := 0; i+4 <= len(b); i += 4 {
+		scaleSample(b[i:i+2], left)
+		scaleSample(b[i+2:i+4], right)
+	}
+}
+
+func scaleSample(b []uint8, gain float64) {
+	writeSample(b, readSample(b)*gain)
+}
+
+// Gain scales the volume of stereo 16-bit audio. The gain can be changed while
+// the audio is being read, and changes can be ramped over a few frames to
+// avoid clicks.
+type Gain struct {
+	frames
+
+	mu     sync.Mutex
+	gain   float64 // the gain currently applied
+	target float64 // the gain set with SetGain
+	step   float64 // change of the gain per frame, until it reaches target
+	ramp   int
+}
+
+// NewGain creates a Gain that reads from source and multiplies every sample
+// by gain. The source may be nil if the Gain is only used through Process.
+func NewGain(source ReadSeekCloser, gain float64) *Gain {
+	return &Gain{frames: frames{source: source}, gain: gain, target: gain}
+}
+
+// SetRamp makes later calls to SetGain change the gain gradually over the
+// given amount of frames, instead of right away.
+func (g *Gain) SetRamp(frames int) {
+	g.mu.Lock()
+	g.ramp = frames
+	g.mu.Unlock()
+}
+
+// SetGain changes the gain. A gain of 1 leaves the audio unchanged and a gain
+// of 0 silences it. Samples which would exceed the range of int16 are
+// clipped.
+func (g *Gain) SetGain(gain float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.target = gain
+	if g.ramp <= 0 {
+		g.gain = gain
+		g.step = 0
+		return
+	}
+	g.step = (gain - g.gain) / float64(g.ramp)
+}
+
+// Gain returns the gain set with SetGain. While ramping, the applied gain may
+// still differ from it.
+func (g *Gain) Gain() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.target
+}
+
+// Process applies the gain to the whole frames in b, in place.
+func (g *Gain) Process(b []uint8) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.step == 0 {
+		scaleFrames(b, g.gain, g.gain)
+		return
+	}
+	for i := 0; i+4 <= len(b); i += 4 {
+		g.gain += g.step
+		if (g.step > 0 && g.gain >= g.target) || (g.step < 0 && g.gain <= g.target) {
+			g.gain = g.target
+			g.step = 0
+		}
+		scaleFrames(b[i:i+4], g.gain, g.gain)
+	}
+}
+
+// Read reads whole frames from the underlying source and applies the gain to
+// them.
+func (g *Gain) Read(b []uint8) (int, error) {
+	n, err := g.read(b)
+	g.Process(b[:n])
+	return n, err
+}
+
+// Seek seeks the underlying source.
+func (g *Gain) Seek(offset int64, whence int) (int64, error) {
+	return g.seek(offset, whence)
+}
+
+// Close closes the underlying source.
+func (g *Gain) Close() error {
+	return g.source.Close()
+}
+
+// Pan moves stereo 16-bit audio between the left and right speaker. The pan
+// can be changed while the audio is being read.
+type Pan struct {
+	frames
+
+	mu  sync.Mutex
+	pan float64
+}
+
+// NewPan creates a Pan that reads from source with the given pan. The source
+// may be nil if the Pan is only used through Process.
+func NewPan(source ReadSeekCloser, pan float64) *Pan {
+	p := &Pan{frames: frames{source: source}}
+	p.SetPan(pan)
+	return p
+}
+
+// SetPan changes the pan, which is clamped to [-1, 1]. -1 only plays the
+// left channel, 1 only the right channel, and 0 leaves the audio unchanged.
+// In between, the opposite channel is faded out, so sounds in the center
+// aren't quieter than without panning.
+func (p *Pan) SetPan(pan float64) {
+	if pan < -1 {
+		pan = -1
+	}
+	if pan > 1 {
+		pan = 1
+	}
+	p.mu.Lock()
+	p.pan = pan
+	p.mu.Unlock()
+}
+
+// Pan returns the current pan.
+func (p *Pan) Pan() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pan
+}
+
+// Process applies the pan to the whole frames in b, in place.
+func (p *Pan) Process(b []uint8) {
+	pan := p.Pan()
+	left, right := 1.0, 1.0
+	if pan > 0 {
+		left = 1 - pan
+	} else {
+		right = 1 + pan
+	}
+	scaleFrames(b, left, right)
+}
+
+// Read reads whole frames from the underlying source and applies the pan to
+// them.
+func (p *Pan) Read(b []uint8) (int, error) {
+	n, err := p.read(b)
+	p.Process(b[:n])
+	return n, err
+}
+
+// Seek seeks the underlying source.
+func (p *Pan) Seek(offset int64, whence int) (int64, error) {
+	return p.seek(offset, whence)
+}
+
+// Close closes the underlying source.
+func (p *Pan) Close() error {
+	return p.source.Close()
+}
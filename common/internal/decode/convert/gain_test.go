@@ -0,0 +1,100 @@
+package convert
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// frameSource returns a source of stereo 16-bit frames with the given left and
+// right samples.
+func frameSource(left, right int16, frames int) ReadSeekCloser {
+	b := make([]uint8, 4*frames)
+	for i := 0; i < frames; i++ {
+		b[4*i] = uint8(left)
+		b[4*i+1] = uint8(left >> 8)
+		b[4*i+2] = uint8(right)
+		b[4*i+3] = uint8(right >> 8)
+	}
+	return nopCloser{bytes.NewReader(b)}
+}
+
+// readFrames reads all frames from r with an odd buffer size, so frames are
+// split across reads, and returns the left and right samples.
+func readFrames(t *testing.T, r io.Reader) (left, right []int16) {
+	var out []uint8
+	buf := make([]uint8, 7)
+	for {
+		n, err := r.Read(buf)
+		out = append(out, buf[:n]...)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(out)%4 != 0 {
+		t.Fatalf("Read expected whole frames ; got=%d bytes", len(out))
+	}
+	for i := 0; i < len(out); i += 4 {
+		left = append(left, int16(out[i])|int16(out[i+1])<<8)
+		right = append(right, int16(out[i+2])|int16(out[i+3])<<8)
+	}
+	return left, right
+}
+
+func TestGain(t *testing.T) {
+	g := NewGain(frameSource(1000, -2000, 10), 0.5)
+	left, right := readFrames(t, g)
+	if len(left) != 10 {
+		t.Fatalf("frames expected=%d ; got=%d", 10, len(left))
+	}
+	for i := range left {
+		if left[i] != 500 || right[i] != -1000 {
+			t.Errorf("frame %d expected=500, -1000 ; got=%d, %d", i, left[i], right[i])
+		}
+	}
+
+	// Samples are clipped instead of wrapping around
+	g = NewGain(frameSource(20000, -20000, 1), 2)
+	left, right = readFrames(t, g)
+	if left[0] != 1<<15-1 || right[0] != -(1<<15) {
+		t.Errorf("clipped frame expected=%d, %d ; got=%d, %d", 1<<15-1, -(1 << 15), left[0], right[0])
+	}
+}
+
+func TestPan(t *testing.T) {
+	for _, c := range []struct {
+		pan         float64
+		left, right int16
+	}{
+		{0, 1000, 1000},
+		{-1, 1000, 0},
+		{1, 0, 1000},
+		{0.5, 500, 1000},
+		{-2, 1000, 0},
+	} {
+		p := NewPan(frameSource(1000, 1000, 3), c.pan)
+		left, right := readFrames(t, p)
+		if left[0] != c.left || right[0] != c.right {
+			t.Errorf("pan %v expected=%d, %d ; got=%d, %d", c.pan, c.left, c.right, left[0], right[0])
+		}
+	}
+}
+
+func TestGainRamp(t *testing.T) {
+	g := NewGain(frameSource(1000, 1000, 8), 0)
+	g.SetRamp(4)
+	g.SetGain(1)
+	if g.Gain() != 1 {
+		t.Errorf("Gain expected=%v ; got=%v", 1, g.Gain())
+	}
+
+	left, _ := readFrames(t, g)
+	expected := []int16{250, 500, 750, 1000, 1000, 1000, 1000, 1000}
+	for i := range expected {
+		if left[i] != expected[i] {
+			t.Errorf("frame %d expected=%d ; got=%d", i, expected[i], left[i])
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package convert
+
+import (
+	"io"
+	"sync"
+)
+
+// Loop reads a source repeatedly, seeking back to its start as soon as it
+// ends, so there is no gap between repetitions.
+type Loop struct {
+	source ReadSeekCloser
+
+	mu     sync.Mutex
+	count  int
+	length int64
+}
+
+// NewLoop creates a Loop that reads source count more times after the first
+// time. A negative count loops forever.
+func NewLoop(source ReadSeekCloser, count int) *Loop {
+	return &Loop{source: source, count: count}
+}
+
+// SetCount changes how many more times the source is read after the current
+// time. A negative count loops forever and 0 stops looping.
+func (l *Loop) SetCount(count int) {
+	l.mu.Lock()
+	l.count = count
+	l.mu.Unlock()
+}
+
+// Count returns how many more times the source is read after the current
+// time, or a negative number if it loops forever.
+func (l *Loop) Count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.count
+}
+
+// Length returns the length of the source in bytes, or 0 if it hasn't been
+// read to the end yet.
+func (l *Loop) Length() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.length
+}
+
+// next is called when the source has ended, and returns whether it should be
+// read again.
+func (l *Loop) next(length int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if length > 0 {
+		l.length = length
+	}
+	if l.count == 0 {
+		return false
+	}
+	if l.count > 0 {
+		l.count--
+	}
+	return true
+}
+
+// Read reads from the underlying source. When it ends and should be read
+// again, it seeks back to the start and continues filling b.
+func (l *Loop) Read(b []uint8) (int, error) {
+	total := 0
+	wrapped := -1
+	for total < len(b) {
+		n, err := l.source.Read(b[total:])
+		total += n
+		if err == nil && n > 0 {
+			continue
+		}
+		if err != io.EOF {
+			return total, err
+		}
+		// An empty source would loop forever without making progress
+		if wrapped == total {
+			return total, io.EOF
+		}
+		length, err := l.source.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return total, err
+		}
+		if !l.next(length) {
+			return total, io.EOF
+		}
+		if _, err := l.source.Seek(0, io.SeekStart); err != nil {
+			return total, err
+		}
+		wrapped = total
+	}
+	return total, nil
+}
+
+// Seek seeks the underlying source.
+func (l *Loop) Seek(offset int64, whence int) (int64, error) {
+	return l.source.Seek(offset, whence)
+}
+
+// Close closes the underlying source.
+func (l *Loop) Close() error {
+	return l.source.Close()
+}
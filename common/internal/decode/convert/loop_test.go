@@ -0,0 +1,57 @@
+package convert
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+func TestLoopCount(t *testing.T) {
+	src := []uint8{1, 2, 3, 4, 5, 6, 7, 8}
+	l := NewLoop(nopCloser{bytes.NewReader(src)}, 2)
+
+	out, err := ioutil.ReadAll(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := append(append(append([]uint8{}, src...), src...), src...)
+	if !bytes.Equal(out, expected) {
+		t.Errorf("Read expected=%v ; got=%v", expected, out)
+	}
+	if l.Length() != int64(len(src)) {
+		t.Errorf("Length expected=%d ; got=%d", len(src), l.Length())
+	}
+	if l.Count() != 0 {
+		t.Errorf("Count expected=%d ; got=%d", 0, l.Count())
+	}
+}
+
+func TestLoopSeamless(t *testing.T) {
+	l := NewLoop(nopCloser{bytes.NewReader([]uint8{1, 2, 3})}, -1)
+
+	// A single Read continues across the end of the source
+	buf := make([]uint8, 8)
+	n, err := l.Read(buf)
+	if err != nil || n != len(buf) {
+		t.Fatalf("Read expected=%d, nil ; got=%d, %v", len(buf), n, err)
+	}
+	if expected := []uint8{1, 2, 3, 1, 2, 3, 1, 2}; !bytes.Equal(buf, expected) {
+		t.Errorf("Read expected=%v ; got=%v", expected, buf)
+	}
+
+	// Stopping the loop ends at the end of the current repetition
+	l.SetCount(0)
+	rest, _ := ioutil.ReadAll(l)
+	if expected := []uint8{3}; !bytes.Equal(rest, expected) {
+		t.Errorf("Read after stopping expected=%v ; got=%v", expected, rest)
+	}
+}
+
+func TestLoopEmpty(t *testing.T) {
+	l := NewLoop(nopCloser{bytes.NewReader(nil)}, -1)
+	n, err := l.Read(make([]uint8, 4))
+	if n != 0 || err != io.EOF {
+		t.Errorf("Read expected=0, EOF ; got=%d, %v", n, err)
+	}
+}
@@ -0,0 +1,191 @@
+package convert
+
+import (
+	"math"
+	"sync"
+)
+
+// Meter passes stereo 16-bit audio through unchanged, while keeping track of
+// the rolling peak and RMS amplitude of each channel over the last window
+// frames that were read.
+type Meter struct {
+	source ReadSeekCloser
+
+	mu      sync.Mutex
+	squares [2][]float64
+	next    int
+	filled  int
+	sum     [2]float64
+	partial [4]uint8
+	pending int
+
+	// frames counts the frames measured since the last reset. peaks holds, per
+	// channel, the frame numbers of the window whose square isn't exceeded by a
+	// later one, from the loudest to the latest, so the peak is the first one.
+	frames    int
+	peaks     [2][]int
+	peakFirst [2]int
+	peakLen   [2]int
+}
+
+// NewMeter creates a Meter that reads from source and measures over a window
+// of the given amount of frames. A window smaller than one frame is treated
+// as one frame. The source may be nil if the Meter is only used through
+// Process.
+func NewMeter(source ReadSeekCloser, window int) *Meter {
+	if window < 1 {
+		window = 1
+	}
+	return &Meter{
+		source:  source,
+		squares: [2][]float64{make([]float64, window), make([]float64, window)},
+		peaks:   [2][]int{make([]int, window), make([]int, window)},
+	}
+}
+
+// Process measures the audio in b, which is left unchanged, like Read does
+// with the audio it reads.
+func (m *Meter) Process(b []uint8) {
+	m.mu.Lock()
+	m.measure(b)
+	m.mu.Unlock()
+}
+
+// Read reads from the underlying source and updates the meter with every
+// complete frame that passes through.
+func (m *Meter) Read(b []uint8) (int, error) {
+	n, err := m.source.Read(b)
+	if n > 0 {
+		m.mu.Lock()
+		m.measure(b[:n])
+		m.mu.Unlock()
+	}
+	return n, err
+}
+
+func (m *Meter) measure(b []uint8) {
+	i := 0
+	// Complete a frame that was split across the previous Read.
+	if m.pending > 0 {
+		for m.pending < 4 && i < len(b) {
+			m.partial[m.pending] = b[i]
+			m.pending++
+			i++
+		}
+		if m.pending < 4 {
+			return
+		}
+		m.add(m.partial[:])
+		m.pending = 0
+	}
+	for ; i+4 <= len(b); i += 4 {
+		m.add(b[i : i+4])
+	}
+	for ; i < len(b); i++ {
+		m.partial[m.pending] = b[i]
+		m.pending++
+	}
+}
+
+func (m *Meter) add(frame []uint8) {
+	window := len(m.squares[0])
+	for c := 0; c < 2; c++ {
+		v := float64(int16(frame[2*c])|(int16(frame[2*c+1])<<8)) / (1<<15 - 1)
+		sq := v * v
+		m.addPeak(c, sq)
+		m.sum[c] += sq - m.squares[c][m.next]
+		m.squares[c][m.next] = sq
+	}
+	m.next = (m.next + 1) % window
+	m.frames++
+	if m.filled < window {
+		m.filled++
+	}
+}
+
+// addPeak adds the square of the next frame of channel c to its peaks, before
+// it's stored in the squares.
+func (m *Meter) addPeak(c int, sq float64) {
+	window := len(m.squares[0])
+	peaks := m.peaks[c]
+	// The frame leaving the window can only be the first one.
+	if m.peakLen[c] > 0 && peaks[m.peakFirst[c]] <= m.frames-window {
+		m.peakFirst[c] = (m.peakFirst[c] + 1) % window
+		m.peakLen[c]--
+	}
+	// Frames that are quieter than the new one can't be the peak anymore.
+	for m.peakLen[c] > 0 {
+		last := peaks[(m.peakFirst[c]+m.peakLen[c]-1)%window]
+		if m.squares[c][last%window] > sq {
+			break
+		}
+		m.peakLen[c]--
+	}
+	peaks[(m.peakFirst[c]+m.peakLen[c])%window] = m.frames
+	m.peakLen[c]++
+}
+
+// Peak returns the highest absolute amplitude of the left and right channel
+// within the current window, in the range [0, 1].
+func (m *Meter) Peak() (float64, float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var peak [2]float64
+	for c := 0; c < 2; c++ {
+		if m.peakLen[c] > 0 {
+			window := len(m.squares[c])
+			peak[c] = math.Sqrt(m.squares[c][m.peaks[c][m.peakFirst[c]]%window])
+		}
+	}
+	return peak[0], peak[1]
+}
+
+// RMS returns the root mean square amplitude of the left and right channel
+// within the current window, in the range [0, 1].
+func (m *Meter) RMS() (float64, float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.filled == 0 {
+		return 0, 0
+	}
+	var rms [2]float64
+	for c := 0; c < 2; c++ {
+		// The running sum can drift slightly below zero due to rounding.
+		rms[c] = math.Sqrt(math.Max(m.sum[c], 0) / float64(m.filled))
+	}
+	return rms[0], rms[1]
+}
+
+// Seek seeks the underlying source and resets the meter.
+func (m *Meter) Seek(offset int64, whence int) (int64, error) {
+	pos, err := m.source.Seek(offset, whence)
+	m.Reset()
+	return pos, err
+}
+
+// Reset clears the measurements, such as when the audio passed to Process
+// jumps to another position.
+func (m *Meter) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for c := 0; c < 2; c++ {
+		for i := range m.squares[c] {
+			m.squares[c][i] = 0
+		}
+		m.sum[c] = 0
+		m.peakFirst[c] = 0
+		m.peakLen[c] = 0
+	}
+	m.next = 0
+	m.filled = 0
+	m.pending = 0
+	m.frames = 0
+}
+
+// Close closes the underlying source.
+func (m *Meter) Close() error {
+	return m.source.Close()
+}
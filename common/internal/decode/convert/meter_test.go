@@ -0,0 +1,95 @@
+package convert
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"testing"
+)
+
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error { return nil }
+
+// sineSource returns frames stereo 16-bit frames of a sine wave with the given
+// amplitude, where a full period takes period frames.
+func sineSource(amplitude float64, period, frames int) ReadSeekCloser {
+	b := make([]uint8, 4*frames)
+	for i := 0; i < frames; i++ {
+		v := int16(amplitude * math.Sin(2*math.Pi*float64(i)/float64(period)) * (1<<15 - 1))
+		b[4*i] = uint8(v)
+		b[4*i+1] = uint8(v >> 8)
+		b[4*i+2] = uint8(v)
+		b[4*i+3] = uint8(v >> 8)
+	}
+	return nopCloser{bytes.NewReader(b)}
+}
+
+func TestMeterSine(t *testing.T) {
+	const (
+		amplitude = 0.5
+		period    = 100
+		tolerance = 1e-3
+	)
+	m := NewMeter(sineSource(amplitude, period, 50*period), 10*period)
+
+	// An odd buffer size makes sure frames split across reads are measured.
+	buf := make([]uint8, 1023)
+	for {
+		if _, err := m.Read(buf); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l, r := m.RMS()
+	if expected := amplitude / math.Sqrt2; math.Abs(l-expected) > tolerance || math.Abs(r-expected) > tolerance {
+		t.Errorf("RMS expected=%f ; got=%f, %f", expected, l, r)
+	}
+	l, r = m.Peak()
+	if math.Abs(l-amplitude) > tolerance || math.Abs(r-amplitude) > tolerance {
+		t.Errorf("Peak expected=%f ; got=%f, %f", amplitude, l, r)
+	}
+
+	if _, err := m.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	l, r = m.RMS()
+	if l != 0 || r != 0 {
+		t.Errorf("RMS after Seek expected=0 ; got=%f, %f", l, r)
+	}
+	l, r = m.Peak()
+	if l != 0 || r != 0 {
+		t.Errorf("Peak after Seek expected=0 ; got=%f, %f", l, r)
+	}
+}
+
+func TestMeterPeakWindow(t *testing.T) {
+	m := NewMeter(nil, 4)
+	frame := func(v int16) []uint8 {
+		return []uint8{uint8(v), uint8(v >> 8), uint8(v), uint8(v >> 8)}
+	}
+
+	m.Process(frame(1<<15 - 1))
+	for i := 0; i < 3; i++ {
+		m.Process(frame(1 << 13))
+	}
+	if l, r := m.Peak(); l != 1 || r != 1 {
+		t.Errorf("Peak expected=1 ; got=%f, %f", l, r)
+	}
+
+	// The loud frame leaves the window
+	m.Process(frame(1 << 12))
+	expected := float64(1<<13) / (1<<15 - 1)
+	if l, r := m.Peak(); math.Abs(l-expected) > 1e-9 || math.Abs(r-expected) > 1e-9 {
+		t.Errorf("Peak expected=%f ; got=%f, %f", expected, l, r)
+	}
+
+	m.Reset()
+	if l, r := m.Peak(); l != 0 || r != 0 {
+		t.Errorf("Peak after Reset expected=0 ; got=%f, %f", l, r)
+	}
+}
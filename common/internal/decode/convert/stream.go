@@ -0,0 +1,65 @@
+package convert
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNotSeekable is returned by Stream.Seek for any position but the current
+// one.
+var ErrNotSeekable = errors.New("audio: streams can only be read in order")
+
+// streamSize is the size a Resampling of a Stream assumes until the Stream
+// ends, as its length isn't known up front.
+const streamSize = 1 << 50
+
+// Stream reads 16-bit stereo PCM which can only be read in order, such as
+// audio decoded while it's downloaded, as a ReadSeekCloser. Seeking only works
+// to the current position, which is enough to ask for the position.
+type Stream struct {
+	source io.Reader
+	closer io.Closer
+	pos    int64
+}
+
+// NewStream creates a ReadSeekCloser reading the PCM of source, at the sample
+// rate from, resampled to the sample rate to. Closing it closes closer, if
+// it's not nil.
+func NewStream(source io.Reader, closer io.Closer, from, to int) ReadSeekCloser {
+	s := &Stream{source: source, closer: closer}
+	if from == to {
+		return s
+	}
+	return NewResampling(s, streamSize, from, to)
+}
+
+// Read reads the PCM in order.
+func (s *Stream) Read(b []uint8) (int, error) {
+	n, err := s.source.Read(b)
+	s.pos += int64(n)
+	return n, err
+}
+
+// Seek returns the current position, and fails with ErrNotSeekable for any
+// other position.
+func (s *Stream) Seek(offset int64, whence int) (int64, error) {
+	target := offset
+	switch whence {
+	case io.SeekCurrent:
+		target += s.pos
+	case io.SeekEnd:
+		return s.pos, ErrNotSeekable
+	}
+	if target != s.pos {
+		return s.pos, ErrNotSeekable
+	}
+	return s.pos, nil
+}
+
+// Close closes the closer of the Stream.
+func (s *Stream) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
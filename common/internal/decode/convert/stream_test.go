@@ -0,0 +1,45 @@
+package convert
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	const frames = 10000
+	b := make([]uint8, 4*frames)
+	s := NewStream(bytes.NewReader(b), nil, 44100, 44100)
+	if _, err := s.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Seeking to the current position should work: %v", err)
+	}
+	out, err := ioutil.ReadAll(s)
+	if err != nil || len(out) != len(b) {
+		t.Fatalf("read expected=%d bytes ; got=%d, %v", len(b), len(out), err)
+	}
+	if pos, err := s.Seek(0, io.SeekCurrent); err != nil || pos != int64(len(b)) {
+		t.Fatalf("position expected=%d ; got=%d, %v", len(b), pos, err)
+	}
+	if _, err := s.Seek(0, io.SeekStart); err != ErrNotSeekable {
+		t.Fatalf("Seeking back expected=%v ; got=%v", ErrNotSeekable, err)
+	}
+}
+
+func TestStreamResampling(t *testing.T) {
+	const frames = 10000
+	b := make([]uint8, 4*frames)
+	s := NewStream(bytes.NewReader(b), nil, 44100, 22050)
+	// A large buffer reads past the end of the source, which is only known then
+	out := make([]uint8, 1<<20)
+	n, err := s.Read(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := 4 * frames / 2; n != expected {
+		t.Fatalf("read expected=%d bytes ; got=%d", expected, n)
+	}
+	if _, err := s.Read(out); err != io.EOF {
+		t.Fatalf("read after the end expected=%v ; got=%v", io.EOF, err)
+	}
+}
@@ -1,6 +1,8 @@
 package mp3
 
 import (
+	"io"
+
 	"github.com/hajimehoshi/go-mp3"
 
 	"github.com/klopsch/engo/common/internal/decode/convert"
@@ -69,3 +71,21 @@ func Decode(src convert.ReadSeekCloser, sr int) (*Stream, error) {
 	}
 	return stream, nil
 }
+
+// readerOnly hides the Seek method of the reader it wraps, so the decoder
+// doesn't read the whole source to measure it.
+type readerOnly struct {
+	io.Reader
+}
+
+// DecodeStream decodes MP3 source while it's read, in order, such as from a
+// network connection. The stream can't seek, and its length isn't known.
+//
+// DecodeStream automatically resamples the stream to fit with the audio context if necessary.
+func DecodeStream(src io.ReadCloser, sr int) (convert.ReadSeekCloser, error) {
+	d, err := mp3.NewDecoder(readerOnly{src})
+	if err != nil {
+		return nil, err
+	}
+	return convert.NewStream(d, src, d.SampleRate(), sr), nil
+}
@@ -185,3 +185,54 @@ func Decode(src convert.ReadSeekCloser, sr int) (*Stream, error) {
 	}
 	return &Stream{decoded: s, size: size}, nil
 }
+
+// readerOnly hides the Seek method of the reader it wraps, so the decoder
+// doesn't read the whole source to measure it.
+type readerOnly struct {
+	io.Reader
+}
+
+// streamed converts the samples of a decoder to 16-bit stereo PCM as they're
+// decoded.
+type streamed struct {
+	decoder *oggvorbis.Reader
+	samples []float32
+	// pending holds the converted bytes which didn't fit in the last Read
+	pending []uint8
+}
+
+func (s *streamed) Read(b []uint8) (int, error) {
+	for len(s.pending) == 0 {
+		s.pending = s.pending[:0]
+		n, err := s.decoder.Read(s.samples)
+		channels := s.decoder.Channels()
+		for _, f := range s.samples[:n] {
+			v := int16(f * (1<<15 - 1))
+			for i := 0; i < 3-channels; i++ {
+				s.pending = append(s.pending, uint8(v), uint8(v>>8))
+			}
+		}
+		if len(s.pending) == 0 && err != nil {
+			return 0, err
+		}
+	}
+	n := copy(b, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// DecodeStream decodes Ogg/Vorbis data while it's read, in order, such as from
+// a network connection. The stream can't seek, and its length isn't known.
+//
+// DecodeStream automatically resamples the stream to fit with the audio context if necessary.
+func DecodeStream(src io.ReadCloser, sr int) (convert.ReadSeekCloser, error) {
+	r, err := oggvorbis.NewReader(readerOnly{src})
+	if err != nil {
+		return nil, err
+	}
+	if channelNum := r.Channels(); channelNum != 1 && channelNum != 2 {
+		return nil, fmt.Errorf("vorbis: number of channels must be 1 or 2 but was %d", channelNum)
+	}
+	s := &streamed{decoder: r, samples: make([]float32, 8192)}
+	return convert.NewStream(s, src, r.SampleRate(), sr), nil
+}
@@ -0,0 +1,187 @@
+package common
+
+import (
+	"sort"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/klopsch/engo/math"
+)
+
+// InterpolationSystemPriority is the priority of the InterpolationSystem. It runs before the MouseSystem and the
+// camera systems, so they see the interpolated positions.
+const InterpolationSystemPriority = 200
+
+// Snapshot is the state of a networked entity at a point in time, as received from the server.
+type Snapshot struct {
+	// Time is when the snapshot was taken, in seconds, on the same clock as the Time of the InterpolationSystem.
+	Time     float32
+	Position engo.Point
+	Rotation float32
+}
+
+// InterpolationComponent buffers the snapshots of a networked entity, from which the InterpolationSystem sets its
+// SpaceComponent every frame.
+type InterpolationComponent struct {
+	snapshots []Snapshot
+}
+
+// AddSnapshot buffers the position and rotation of the space, as received for the time. Snapshots may be added out
+// of order; a snapshot with the same time as a buffered one replaces it.
+func (c *InterpolationComponent) AddSnapshot(time float32, space SpaceComponent) {
+	s := Snapshot{Time: time, Position: space.Position, Rotation: space.Rotation}
+	i := sort.Search(len(c.snapshots), func(i int) bool {
+		return c.snapshots[i].Time >= time
+	})
+	if i < len(c.snapshots) && c.snapshots[i].Time == time {
+		c.snapshots[i] = s
+		return
+	}
+	c.snapshots = append(c.snapshots, Snapshot{})
+	copy(c.snapshots[i+1:], c.snapshots[i:])
+	c.snapshots[i] = s
+}
+
+// Snapshots returns the buffered snapshots, oldest first.
+func (c *InterpolationComponent) Snapshots() []Snapshot {
+	return c.snapshots
+}
+
+// Sample returns the state of the entity at the time, interpolated between the snapshots around it. After the last
+// snapshot, the movement between the last two is extrapolated for up to maxExtrapolation seconds, after which the
+// entity stays where it was extrapolated to. It returns false if there are no snapshots.
+func (c *InterpolationComponent) Sample(time, maxExtrapolation float32) (Snapshot, bool) {
+	n := len(c.snapshots)
+	if n == 0 {
+		return Snapshot{}, false
+	}
+	if time <= c.snapshots[0].Time {
+		return c.snapshots[0], true
+	}
+
+	a, b := c.snapshots[n-1], c.snapshots[n-1]
+	if time >= b.Time {
+		if n == 1 {
+			return b, true
+		}
+		// Extrapolate from the last two snapshots
+		a = c.snapshots[n-2]
+		time = math.Min(time, b.Time+maxExtrapolation)
+	} else {
+		i := sort.Search(n, func(i int) bool {
+			return c.snapshots[i].Time > time
+		})
+		a, b = c.snapshots[i-1], c.snapshots[i]
+	}
+
+	t := math.InverseLerp(a.Time, b.Time, time)
+	return Snapshot{
+		Time:     time,
+		Position: engo.LerpPoint(a.Position, b.Position, t),
+		Rotation: math.LerpAngle(a.Rotation, b.Rotation, t),
+	}, true
+}
+
+// discardBefore removes the snapshots which aren't needed anymore to sample from the time onwards, keeping the last
+// one before it.
+func (c *InterpolationComponent) discardBefore(time float32) {
+	i := sort.Search(len(c.snapshots), func(i int) bool {
+		return c.snapshots[i].Time > time
+	})
+	// Keep one before the time, and two to extrapolate from
+	keep := i - 1
+	if keep > len(c.snapshots)-2 {
+		keep = len(c.snapshots) - 2
+	}
+	if keep > 0 {
+		c.snapshots = append(c.snapshots[:0], c.snapshots[keep:]...)
+	}
+}
+
+type interpolationEntity struct {
+	*ecs.BasicEntity
+	*InterpolationComponent
+	*SpaceComponent
+}
+
+// InterpolationSystem smooths the movement of networked entities, by setting their SpaceComponent every frame to
+// their state Delay seconds ago, interpolated between the snapshots received around that time. Rendering slightly in
+// the past means there's usually a snapshot on both sides, so the entities move smoothly even though snapshots arrive
+// at a lower rate than frames are drawn, and with jitter. When the snapshots are late, their movement is
+// extrapolated for up to MaxExtrapolation seconds. It's independent of how snapshots are sent.
+type InterpolationSystem struct {
+	// Time is the current time in seconds, on the same clock as the time of the snapshots. It advances with every
+	// Update; games should set it to their estimate of the clock of the server once they have one.
+	Time float32
+	// Delay is how far in the past the entities are shown, in seconds. It defaults to 0.1, which is enough for
+	// snapshots sent 20 times a second with a lost one here and there.
+	Delay float32
+	// MaxExtrapolation is how long the movement of entities is extrapolated when the snapshots are late, in seconds.
+	// It defaults to 0.25.
+	MaxExtrapolation float32
+
+	entities []interpolationEntity
+}
+
+// Priority implements the ecs.Prioritizer interface.
+func (*InterpolationSystem) Priority() int { return InterpolationSystemPriority }
+
+// New initializes the InterpolationSystem. It is run before any updates.
+func (s *InterpolationSystem) New(w *ecs.World) {
+	if s.Delay == 0 {
+		s.Delay = 0.1
+	}
+	if s.MaxExtrapolation == 0 {
+		s.MaxExtrapolation = 0.25
+	}
+}
+
+// Add adds an entity to the InterpolationSystem.
+func (s *InterpolationSystem) Add(basic *ecs.BasicEntity, interpolation *InterpolationComponent, space *SpaceComponent) {
+	s.entities = append(s.entities, interpolationEntity{basic, interpolation, space})
+}
+
+// AddByInterface adds any Interpolationable to the InterpolationSystem.
+func (s *InterpolationSystem) AddByInterface(i ecs.Identifier) {
+	o, _ := i.(Interpolationable)
+	s.Add(o.GetBasicEntity(), o.GetInterpolationComponent(), o.GetSpaceComponent())
+}
+
+// Remove removes an entity from the InterpolationSystem.
+func (s *InterpolationSystem) Remove(basic ecs.BasicEntity) {
+	delete := -1
+	for index, e := range s.entities {
+		if e.BasicEntity.ID() == basic.ID() {
+			delete = index
+			break
+		}
+	}
+	if delete >= 0 {
+		s.entities = append(s.entities[:delete], s.entities[delete+1:]...)
+	}
+}
+
+// EntityCount returns the number of entities the InterpolationSystem holds.
+func (s *InterpolationSystem) EntityCount() int {
+	return len(s.entities)
+}
+
+// RenderTime returns the time the entities are shown at, which is Time minus Delay.
+func (s *InterpolationSystem) RenderTime() float32 {
+	return s.Time - s.Delay
+}
+
+// Update advances the Time and sets the SpaceComponents of the entities to their state at the RenderTime.
+func (s *InterpolationSystem) Update(dt float32) {
+	s.Time += dt
+	t := s.RenderTime()
+	for _, e := range s.entities {
+		snapshot, ok := e.InterpolationComponent.Sample(t, s.MaxExtrapolation)
+		if !ok {
+			continue
+		}
+		e.SpaceComponent.Position = snapshot.Position
+		e.SpaceComponent.Rotation = snapshot.Rotation
+		e.InterpolationComponent.discardBefore(t)
+	}
+}
@@ -0,0 +1,60 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolationComponentSample(t *testing.T) {
+	c := &InterpolationComponent{}
+	_, ok := c.Sample(0, 0.25)
+	assert.False(t, ok, "Nothing should be sampled without snapshots")
+
+	c.AddSnapshot(1, SpaceComponent{Position: engo.Point{X: 10, Y: 20}, Rotation: 10})
+	c.AddSnapshot(0, SpaceComponent{Rotation: 350})
+	assert.Equal(t, []float32{0, 1}, []float32{c.Snapshots()[0].Time, c.Snapshots()[1].Time}, "Snapshots should be sorted")
+
+	data := []struct {
+		time     float32
+		position engo.Point
+	}{
+		{-1, engo.Point{}},              // before the first snapshot
+		{0.5, engo.Point{X: 5, Y: 10}},  // interpolated
+		{1.2, engo.Point{X: 12, Y: 24}}, // extrapolated
+		{2, engo.Point{X: 12.5, Y: 25}}, // extrapolated up to MaxExtrapolation
+	}
+	for _, d := range data {
+		s, ok := c.Sample(d.time, 0.25)
+		assert.True(t, ok)
+		assert.InDelta(t, d.position.X, s.Position.X, 0.001, "time %v", d.time)
+		assert.InDelta(t, d.position.Y, s.Position.Y, 0.001, "time %v", d.time)
+	}
+
+	s, _ := c.Sample(0.5, 0.25)
+	assert.InDelta(t, 360, s.Rotation, 0.001, "Rotation should be interpolated the shortest way")
+}
+
+func TestInterpolationSystem(t *testing.T) {
+	sys := &InterpolationSystem{}
+	sys.New(nil)
+	basic := ecs.NewBasic()
+	c := &InterpolationComponent{}
+	space := &SpaceComponent{}
+	sys.Add(&basic, c, space)
+	for i := 0; i < 4; i++ {
+		c.AddSnapshot(float32(i), SpaceComponent{Position: engo.Point{X: float32(i) * 10}})
+	}
+
+	sys.Update(0.6)
+	assert.InDelta(t, 5, space.Position.X, 0.001, "Entities should be shown Delay seconds in the past")
+
+	sys.Update(2)
+	assert.InDelta(t, 25, space.Position.X, 0.001)
+	assert.Len(t, c.Snapshots(), 2, "Snapshots which aren't needed anymore should be discarded")
+
+	sys.Remove(basic)
+	assert.Equal(t, 0, sys.EntityCount())
+}
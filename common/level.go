@@ -1,6 +1,10 @@
 package common
 
 import (
+	"image/color"
+	"sort"
+	"strconv"
+
 	"github.com/klopsch/engo"
 	"github.com/klopsch/engo/math"
 	"github.com/klopsch/gl"
@@ -19,6 +23,9 @@ type Level struct {
 	RenderOrder string
 	width       int
 	height      int
+	// origin is the top left map point, which is negative when an infinite
+	// map has chunks left of or above the map origin
+	origin mapPoint
 	// TileWidth defines the width of each tile in the level
 	TileWidth int
 	// TileHeight defines the height of each tile in the level
@@ -36,6 +43,8 @@ type Level struct {
 	resourceMap map[uint32]Texture
 	pointMap    map[mapPoint]*Tile
 	framesMap   map[uint32][]uint32
+	durationMap map[uint32][]float32
+	propertyMap map[uint32][]Property
 }
 
 // Property is any custom property. The Type corresponds to the type (int,
@@ -68,6 +77,22 @@ type TileLayer struct {
 	OffSetY float32
 	// Properties are the custom properties of the layer
 	Properties []Property
+	// Tint is the color the tiles of the layer are multiplied with, or nil if the layer isn't tinted
+	Tint color.Color
+}
+
+// RenderComponent returns a RenderComponent drawing a tile of the layer, or a TileMesh baked from it, with the opacity
+// and the tint of the layer. It's hidden if the layer isn't visible, so it can be shown by toggling Hidden. As the
+// RenderSystem treats an Opacity of 0 as unset, the tiles of fully transparent layers are hidden as well.
+func (l *TileLayer) RenderComponent(d Drawable, zIndex float32) RenderComponent {
+	return RenderComponent{
+		Drawable:    d,
+		Scale:       engo.Point{X: 1, Y: 1},
+		StartZIndex: zIndex,
+		Color:       l.Tint,
+		Opacity:     l.Opacity,
+		Hidden:      !l.Visible || l.Opacity <= 0,
+	}
 }
 
 // ImageLayer contains a list of its images plus all default Tiled attributes
@@ -175,17 +200,19 @@ type TMXText struct {
 
 // Bounds returns the level boundaries as an engo.AABB object
 func (l *Level) Bounds() engo.AABB {
+	x0, y0 := float32(l.origin.X), float32(l.origin.Y)
+	x1, y1 := x0+float32(l.width), y0+float32(l.height)
 	switch l.Orientation {
 	case orth:
 		return engo.AABB{
-			Min: l.screenPoint(engo.Point{X: 0, Y: 0}),
-			Max: l.screenPoint(engo.Point{X: float32(l.width), Y: float32(l.height)}),
+			Min: l.screenPoint(engo.Point{X: x0, Y: y0}),
+			Max: l.screenPoint(engo.Point{X: x1, Y: y1}),
 		}
 	case iso:
-		xMin := l.screenPoint(engo.Point{X: 0, Y: float32(l.height)}).X + float32(l.TileWidth)/2
-		xMax := l.screenPoint(engo.Point{X: float32(l.width), Y: 0}).X + float32(l.TileWidth)/2
-		yMin := l.screenPoint(engo.Point{X: 0, Y: 0}).Y
-		yMax := l.screenPoint(engo.Point{X: float32(l.width), Y: float32(l.height)}).Y + float32(l.TileHeight)/2
+		xMin := l.screenPoint(engo.Point{X: x0, Y: y1}).X + float32(l.TileWidth)/2
+		xMax := l.screenPoint(engo.Point{X: x1, Y: y0}).X + float32(l.TileWidth)/2
+		yMin := l.screenPoint(engo.Point{X: x0, Y: y0}).Y
+		yMax := l.screenPoint(engo.Point{X: x1, Y: y1}).Y + float32(l.TileHeight)/2
 		return engo.AABB{
 			Min: engo.Point{X: xMin, Y: yMin},
 			Max: engo.Point{X: xMax, Y: yMax},
@@ -194,6 +221,26 @@ func (l *Level) Bounds() engo.AABB {
 	return engo.AABB{}
 }
 
+// grow extends the level so it covers the map points from min up to, but not
+// including, max.
+func (l *Level) grow(min, max mapPoint) {
+	x1, y1 := l.origin.X+l.width, l.origin.Y+l.height
+	if min.X < l.origin.X {
+		l.origin.X = min.X
+	}
+	if min.Y < l.origin.Y {
+		l.origin.Y = min.Y
+	}
+	if max.X > x1 {
+		x1 = max.X
+	}
+	if max.Y > y1 {
+		y1 = max.Y
+	}
+	l.width = x1 - l.origin.X
+	l.height = y1 - l.origin.Y
+}
+
 // mapPoint returns the map point of the passed in screen point
 func (l *Level) mapPoint(screenPt engo.Point) engo.Point {
 	switch l.Orientation {
@@ -240,6 +287,127 @@ func (l *Level) GetTile(pt engo.Point) *Tile {
 	return t
 }
 
+// TileAt returns the column and row of the tile at the point in the world, as placed by the loader. On isometric
+// levels, that's the tile whose diamond contains the point. The column and row may be outside of the level, see
+// InBounds, and are negative left of and above the origin of infinite maps.
+func (l *Level) TileAt(pt engo.Point) (col, row int) {
+	if l.Orientation == iso {
+		// Tiles are placed at the top left of their image, while their diamond starts half a tile to the right
+		pt.X -= float32(l.TileWidth) / 2
+	}
+	mp := l.mapPoint(pt)
+	return int(math.Floor(mp.X)), int(math.Floor(mp.Y))
+}
+
+// WorldOf returns the point in the world the tile at the column and row is placed at, which is the top left of its
+// image, like the Point of the Tile.
+func (l *Level) WorldOf(col, row int) engo.Point {
+	return l.screenPoint(engo.Point{X: float32(col), Y: float32(row)})
+}
+
+// InBounds returns whether the column and row are within the level.
+func (l *Level) InBounds(col, row int) bool {
+	return col >= l.origin.X && col < l.origin.X+l.width && row >= l.origin.Y && row < l.origin.Y+l.height
+}
+
+// CollisionSpaces returns SpaceComponents covering every tile, on any tile
+// layer, whose tileset sets the given bool property (such as "solid") to true.
+// Adjacent tiles are merged into larger rectangles to keep the number of
+// collision entities down. Only orthogonal levels are supported, other
+// orientations return nil.
+func (l *Level) CollisionSpaces(property string) []SpaceComponent {
+	if l.Orientation != orth {
+		return nil
+	}
+
+	solid := make(map[mapPoint]bool)
+	for _, layer := range l.TileLayers {
+		for _, t := range layer.Tiles {
+			if !t.flag(property) {
+				continue
+			}
+			mp := l.mapPoint(t.Point)
+			solid[mapPoint{X: int(math.Floor(mp.X)), Y: int(math.Floor(mp.Y))}] = true
+		}
+	}
+
+	cells := make([]mapPoint, 0, len(solid))
+	for c := range solid {
+		cells = append(cells, c)
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].Y != cells[j].Y {
+			return cells[i].Y < cells[j].Y
+		}
+		return cells[i].X < cells[j].X
+	})
+
+	// Greedily grow each rectangle right, then down, from its top left cell.
+	// Merged cells are removed from solid so they aren't used twice.
+	var spaces []SpaceComponent
+	for _, c := range cells {
+		if !solid[c] {
+			continue
+		}
+		w := 1
+		for solid[mapPoint{X: c.X + w, Y: c.Y}] {
+			w++
+		}
+		h := 1
+	rows:
+		for {
+			for x := c.X; x < c.X+w; x++ {
+				if !solid[mapPoint{X: x, Y: c.Y + h}] {
+					break rows
+				}
+			}
+			h++
+		}
+		for y := c.Y; y < c.Y+h; y++ {
+			for x := c.X; x < c.X+w; x++ {
+				delete(solid, mapPoint{X: x, Y: y})
+			}
+		}
+		spaces = append(spaces, SpaceComponent{
+			Position: l.screenPoint(engo.Point{X: float32(c.X), Y: float32(c.Y)}),
+			Width:    float32(w * l.TileWidth),
+			Height:   float32(h * l.TileHeight),
+		})
+	}
+	return spaces
+}
+
+// Walkable returns a grid of the level, row by row, which is false for every
+// tile on any tile layer whose tileset sets the given bool property (such as
+// "solid") to true. The first tile of the grid is the one at the minimum of
+// Bounds. Only orthogonal levels are supported, other orientations return nil.
+func (l *Level) Walkable(property string) [][]bool {
+	if l.Orientation != orth {
+		return nil
+	}
+
+	grid := make([][]bool, l.height)
+	for y := range grid {
+		grid[y] = make([]bool, l.width)
+		for x := range grid[y] {
+			grid[y][x] = true
+		}
+	}
+	for _, layer := range l.TileLayers {
+		for _, t := range layer.Tiles {
+			if !t.flag(property) {
+				continue
+			}
+			mp := l.mapPoint(t.Point)
+			x, y := int(math.Floor(mp.X))-l.origin.X, int(math.Floor(mp.Y))-l.origin.Y
+			if y >= 0 && y < l.height && x >= 0 && x < l.width {
+				grid[y][x] = false
+			}
+		}
+	}
+	return grid
+}
+
 // Width returns the integer width of the level
 func (l *Level) Width() int {
 	return l.width
@@ -275,6 +443,18 @@ func (t *Tile) View() (float32, float32, float32, float32) {
 	return t.Image.View()
 }
 
+// flag reports whether the tile has a property with the given name that is
+// set to true.
+func (t *Tile) flag(name string) bool {
+	for _, p := range t.Properties {
+		if p.Name == name {
+			b, _ := strconv.ParseBool(p.Value)
+			return b
+		}
+	}
+	return false
+}
+
 // Tile represents a tile in the TMX map.
 type Tile struct {
 	engo.Point
@@ -283,4 +463,6 @@ type Tile struct {
 	Animation *Animation
 	// Rotation of the Tile in degrees
 	Rotation float32
+	// Properties are the custom properties set on the tile in its tileset
+	Properties []Property
 }
@@ -0,0 +1,194 @@
+package common
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/klopsch/engo"
+)
+
+func TestLevelCollisionSpaces(t *testing.T) {
+	l := &Level{
+		Orientation: orth,
+		TileWidth:   16,
+		TileHeight:  16,
+	}
+	solid := []Property{{Name: "solid", Type: "bool", Value: "true"}}
+	open := []Property{{Name: "solid", Type: "bool", Value: "false"}}
+	layer := func(props map[mapPoint][]Property) *TileLayer {
+		tl := &TileLayer{}
+		for mp, p := range props {
+			tl.Tiles = append(tl.Tiles, &Tile{
+				Point:      l.screenPoint(engo.Point{X: float32(mp.X), Y: float32(mp.Y)}),
+				Properties: p,
+			})
+		}
+		return tl
+	}
+
+	// S S .
+	// S S .
+	// . . S
+	l.TileLayers = []*TileLayer{
+		layer(map[mapPoint][]Property{
+			{X: 0, Y: 0}: solid,
+			{X: 1, Y: 0}: solid,
+			{X: 2, Y: 0}: open,
+			{X: 0, Y: 1}: solid,
+			{X: 2, Y: 2}: solid,
+		}),
+		layer(map[mapPoint][]Property{
+			{X: 1, Y: 0}: solid,
+			{X: 1, Y: 1}: solid,
+			{X: 2, Y: 1}: nil,
+		}),
+	}
+
+	spaces := l.CollisionSpaces("solid")
+	expected := []SpaceComponent{
+		{Position: engo.Point{X: 0, Y: 0}, Width: 32, Height: 32},
+		{Position: engo.Point{X: 32, Y: 32}, Width: 16, Height: 16},
+	}
+	if len(spaces) != len(expected) {
+		t.Fatalf("adjacent solid tiles should be merged expected=%v ; got=%v", expected, spaces)
+	}
+	for i := range expected {
+		if spaces[i].Position != expected[i].Position || spaces[i].Width != expected[i].Width || spaces[i].Height != expected[i].Height {
+			t.Errorf("wrong collision space expected=%v ; got=%v", expected[i], spaces[i])
+		}
+	}
+
+	if spaces := l.CollisionSpaces("water"); len(spaces) != 0 {
+		t.Errorf("tiles without the property should not collide expected=%v ; got=%v", 0, len(spaces))
+	}
+
+	l.Orientation = iso
+	if spaces := l.CollisionSpaces("solid"); spaces != nil {
+		t.Errorf("isometric levels are not supported expected=%v ; got=%v", nil, spaces)
+	}
+}
+
+func TestLevelWalkable(t *testing.T) {
+	l := &Level{
+		Orientation: orth,
+		TileWidth:   16,
+		TileHeight:  16,
+	}
+	l.grow(mapPoint{X: 0, Y: 0}, mapPoint{X: 3, Y: 2})
+	solid := []Property{{Name: "solid", Type: "bool", Value: "true"}}
+	l.TileLayers = []*TileLayer{{Tiles: []*Tile{
+		{Point: engo.Point{X: 16, Y: 0}, Properties: solid},
+		{Point: engo.Point{X: 32, Y: 16}, Properties: solid},
+		{Point: engo.Point{X: 0, Y: 16}},
+	}}}
+
+	// . S .
+	// . . S
+	expected := [][]bool{{true, false, true}, {true, true, false}}
+	grid := l.Walkable("solid")
+	if len(grid) != len(expected) {
+		t.Fatalf("wrong number of rows expected=%v ; got=%v", len(expected), len(grid))
+	}
+	for y := range expected {
+		for x := range expected[y] {
+			if grid[y][x] != expected[y][x] {
+				t.Errorf("wrong walkability at %v,%v expected=%v ; got=%v", x, y, expected[y][x], grid[y][x])
+			}
+		}
+	}
+
+	l.Orientation = iso
+	if grid := l.Walkable("solid"); grid != nil {
+		t.Errorf("isometric levels are not supported expected=%v ; got=%v", nil, grid)
+	}
+}
+
+func TestTileLayerRenderComponent(t *testing.T) {
+	tile := &Tile{Image: &Texture{width: 16, height: 16}}
+	tint := color.NRGBA{R: 0xff, A: 0xff}
+	layer := &TileLayer{Opacity: 0.5, Visible: true, Tint: tint}
+
+	rc := layer.RenderComponent(tile.Image, 2)
+	if rc.Drawable != tile.Image || rc.StartZIndex != 2 {
+		t.Errorf("Tile was not drawn at the z-index\nWanted: %v, %v\nGot: %v, %v", tile.Image, 2, rc.Drawable, rc.StartZIndex)
+	}
+	if rc.Opacity != 0.5 || rc.Color != tint || rc.Hidden {
+		t.Errorf("Layer opacity and tint were not applied\nWanted: %v, %v, %v\nGot: %v, %v, %v", 0.5, tint, false, rc.Opacity, rc.Color, rc.Hidden)
+	}
+
+	layer.Visible = false
+	if rc := layer.RenderComponent(tile.Image, 2); !rc.Hidden {
+		t.Error("Tiles of invisible layers should be hidden")
+	}
+	layer.Visible, layer.Opacity = true, 0
+	if rc := layer.RenderComponent(tile.Image, 2); !rc.Hidden {
+		t.Error("Tiles of fully transparent layers should be hidden")
+	}
+}
+
+func TestLevelTileAtOrthogonal(t *testing.T) {
+	l := &Level{Orientation: orth, TileWidth: 16, TileHeight: 8, width: 4, height: 3}
+
+	tests := []struct {
+		pt       engo.Point
+		col, row int
+	}{
+		{engo.Point{X: 0, Y: 0}, 0, 0},
+		{engo.Point{X: 15.9, Y: 7.9}, 0, 0},
+		{engo.Point{X: 16, Y: 8}, 1, 1},
+		{engo.Point{X: 63, Y: 23}, 3, 2},
+		{engo.Point{X: -1, Y: -1}, -1, -1},
+	}
+	for _, test := range tests {
+		col, row := l.TileAt(test.pt)
+		if col != test.col || row != test.row {
+			t.Errorf("TileAt(%v) was not returned correctly\nWanted: %v, %v\nGot: %v, %v", test.pt, test.col, test.row, col, row)
+		}
+	}
+
+	if pt := l.WorldOf(3, 2); pt != (engo.Point{X: 48, Y: 16}) {
+		t.Errorf("WorldOf was not returned correctly\nWanted: %v\nGot: %v", engo.Point{X: 48, Y: 16}, pt)
+	}
+	if col, row := l.TileAt(l.WorldOf(2, 1)); col != 2 || row != 1 {
+		t.Errorf("TileAt should return the tile placed at WorldOf\nWanted: %v, %v\nGot: %v, %v", 2, 1, col, row)
+	}
+
+	if !l.InBounds(0, 0) || !l.InBounds(3, 2) {
+		t.Error("Tiles within the level should be in bounds")
+	}
+	if l.InBounds(4, 0) || l.InBounds(0, 3) || l.InBounds(-1, 0) {
+		t.Error("Tiles outside of the level should not be in bounds")
+	}
+	l.origin = mapPoint{X: -2, Y: -1}
+	if !l.InBounds(-2, -1) || l.InBounds(2, 0) {
+		t.Error("Bounds should start at the origin of infinite levels")
+	}
+}
+
+func TestLevelTileAtIsometric(t *testing.T) {
+	l := &Level{Orientation: iso, TileWidth: 32, TileHeight: 16, width: 3, height: 3}
+
+	// The tile at column 1 and row 0 is placed at (16, 8), so its diamond has its corners at (32, 8), (48, 16),
+	// (32, 24) and (16, 16)
+	if pt := l.WorldOf(1, 0); pt != (engo.Point{X: 16, Y: 8}) {
+		t.Errorf("WorldOf was not returned correctly\nWanted: %v\nGot: %v", engo.Point{X: 16, Y: 8}, pt)
+	}
+	tests := []struct {
+		pt       engo.Point
+		col, row int
+	}{
+		{engo.Point{X: 32, Y: 16}, 1, 0},
+		{engo.Point{X: 32, Y: 9}, 1, 0},
+		{engo.Point{X: 47, Y: 16}, 1, 0},
+		{engo.Point{X: 17, Y: 16}, 1, 0},
+		{engo.Point{X: 32, Y: 23}, 1, 0},
+		{engo.Point{X: 16, Y: 8}, 0, 0},
+		{engo.Point{X: 16, Y: 24}, 1, 1},
+	}
+	for _, test := range tests {
+		col, row := l.TileAt(test.pt)
+		if col != test.col || row != test.row {
+			t.Errorf("TileAt(%v) was not returned correctly\nWanted: %v, %v\nGot: %v, %v", test.pt, test.col, test.row, col, row)
+		}
+	}
+}
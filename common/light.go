@@ -0,0 +1,236 @@
+package common
+
+import (
+	"image/color"
+	"sort"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/klopsch/engo/math"
+)
+
+// LightSystemPriority is the priority of the LightSystem. It runs after the
+// ParallaxSystem has moved the entities, and before the RenderSystem draws.
+const LightSystemPriority = -950
+
+// LightComponent makes an entity a point light, which lights the entities
+// drawn by the LightingShader around it.
+//
+// This is a first version of lighting: there are only point lights, of which
+// the LightingShader applies up to MaxLights, and they're summed per pixel in
+// the shader rather than accumulated in a framebuffer. The rotation of sprites
+// doesn't rotate their normals.
+type LightComponent struct {
+	// Color is the color of the light. It defaults to white.
+	Color color.Color
+	// Intensity multiplies the Color. It defaults to 1.
+	Intensity float32
+	// Radius is the distance in world units at which the light fades out.
+	Radius float32
+	// Height is how far above the sprites the light is, which makes it light
+	// the surfaces facing the camera more than the ones facing it sideways.
+	// It defaults to a quarter of the Radius.
+	Height float32
+	// Offset is the position of the light relative to the center of the
+	// SpaceComponent of the entity.
+	Offset engo.Point
+	// Shadows makes the entities with an OccluderComponent block the light.
+	Shadows bool
+	// Size is the width of the light in world units when it casts shadows.
+	// Shadows of a light with a size are soft, fading out at their edges, at
+	// the cost of testing the occluders three times per pixel.
+	Size float32
+}
+
+// OccluderComponent makes an entity block the lights casting shadows, as if
+// its SpaceComponent was a wall as high as the lights.
+//
+// Shadows are computed as 2D shadow volumes rather than shadow maps: the
+// LightSystem passes the edges of the rectangles of the occluders nearest to
+// each light to the LightingShader, up to MaxOccluders of them, and each pixel
+// is in the shadow of a light if the line to the light crosses an edge facing
+// away from it. The edges facing the light don't block it, so occluders are lit
+// on the side of the light. The Shape of the SpaceComponent is ignored.
+type OccluderComponent struct{}
+
+type occluderEntity struct {
+	*ecs.BasicEntity
+	*OccluderComponent
+	*SpaceComponent
+}
+
+type lightEntity struct {
+	*ecs.BasicEntity
+	*LightComponent
+	*SpaceComponent
+}
+
+// LightSystem passes the lights of the entities with a LightComponent, and the
+// entities with an OccluderComponent blocking them, to the LightingShader
+// every frame. Entities are lit by setting the Shader of their
+// RenderComponent to the LightingShader, and their NormalMap for the shape of
+// their surface. Without any lights, the LightingShader draws them unlit.
+type LightSystem struct {
+	// Ambient is the light added to every pixel drawn by the LightingShader,
+	// regardless of the lights. It defaults to black, so only the lights
+	// light the sprites.
+	Ambient color.Color
+
+	entities  []lightEntity
+	occluders []occluderEntity
+	lights    []light
+	edges     []edge
+}
+
+// Priority implements the ecs.Prioritizer interface.
+func (*LightSystem) Priority() int { return LightSystemPriority }
+
+// Add starts tracking the given entity, filling in the defaults of its
+// LightComponent.
+func (l *LightSystem) Add(basic *ecs.BasicEntity, light *LightComponent, space *SpaceComponent) {
+	if light.Color == nil {
+		light.Color = color.White
+	}
+	if light.Intensity == 0 {
+		light.Intensity = 1
+	}
+	if light.Height == 0 {
+		light.Height = light.Radius / 4
+	}
+	l.entities = append(l.entities, lightEntity{basic, light, space})
+}
+
+// AddOccluder starts tracking the given entity as an occluder.
+func (l *LightSystem) AddOccluder(basic *ecs.BasicEntity, occluder *OccluderComponent, space *SpaceComponent) {
+	l.occluders = append(l.occluders, occluderEntity{basic, occluder, space})
+}
+
+// AddByInterface allows an Entity to be added directly using the Lightable
+// interface, which every entity containing the BasicEntity, LightComponent and
+// SpaceComponent anonymously automatically satisfies, or the Occludable
+// interface to add it as an occluder. An entity satisfying both is added as a
+// light and as an occluder.
+func (l *LightSystem) AddByInterface(i ecs.Identifier) {
+	if o, ok := i.(Lightable); ok {
+		l.Add(o.GetBasicEntity(), o.GetLightComponent(), o.GetSpaceComponent())
+	}
+	if o, ok := i.(Occludable); ok {
+		l.AddOccluder(o.GetBasicEntity(), o.GetOccluderComponent(), o.GetSpaceComponent())
+	}
+}
+
+// Remove stops tracking the given entity, as a light or an occluder.
+func (l *LightSystem) Remove(basic ecs.BasicEntity) {
+	delete := -1
+	for index, e := range l.entities {
+		if e.BasicEntity.ID() == basic.ID() {
+			delete = index
+			break
+		}
+	}
+	if delete >= 0 {
+		l.entities = append(l.entities[:delete], l.entities[delete+1:]...)
+	}
+	for index, e := range l.occluders {
+		if e.BasicEntity.ID() == basic.ID() {
+			l.occluders = append(l.occluders[:index], l.occluders[index+1:]...)
+			return
+		}
+	}
+}
+
+// EntityCount returns the number of lights the LightSystem holds.
+func (l *LightSystem) EntityCount() int {
+	return len(l.entities)
+}
+
+// Update passes the lights at the current position of their entities to the
+// LightingShader, along with the occluders nearest to each light casting
+// shadows.
+func (l *LightSystem) Update(float32) {
+	scale := engo.GetGlobalScale()
+	l.lights = l.lights[:0]
+	for _, e := range l.entities {
+		center := e.SpaceComponent.Center()
+		r, g, b, _ := colorComponents(linearColor(e.Color))
+		l.lights = append(l.lights, light{
+			x:       (center.X + e.Offset.X) * scale.X,
+			y:       (center.Y + e.Offset.Y) * scale.Y,
+			height:  e.LightComponent.Height * scale.X,
+			r:       r * e.Intensity,
+			g:       g * e.Intensity,
+			b:       b * e.Intensity,
+			radius:  e.Radius * scale.X,
+			shadows: e.Shadows,
+			size:    e.Size * scale.X,
+		})
+	}
+	if len(l.lights) > MaxLights {
+		l.lights = l.lights[:MaxLights]
+	}
+	l.updateEdges(scale)
+	LightingShader.setLights(l.Ambient, l.lights, l.edges)
+}
+
+// updateEdges collects the edges of the occluders within the radius of a light
+// casting shadows, up to MaxOccluders of them. Each light gets its nearest
+// occluders in turn, so a light far from the others still casts shadows when
+// many occluders are around them.
+func (l *LightSystem) updateEdges(scale engo.Point) {
+	type nearOccluder struct {
+		index    int
+		distance float32
+	}
+	var perLight [][]nearOccluder
+	for _, lt := range l.lights {
+		if !lt.shadows {
+			continue
+		}
+		p := engo.Point{X: lt.x / scale.X, Y: lt.y / scale.Y}
+		var near []nearOccluder
+		for i, o := range l.occluders {
+			if d := aabbDistance(o.SpaceComponent.AABB(), p); d < lt.radius/scale.X {
+				near = append(near, nearOccluder{i, d})
+			}
+		}
+		sort.SliceStable(near, func(i, j int) bool { return near[i].distance < near[j].distance })
+		perLight = append(perLight, near)
+	}
+
+	var selected []*SpaceComponent
+	chosen := make(map[int]bool)
+	for rank, more := 0, true; more && len(selected) < MaxOccluders; rank++ {
+		more = false
+		for _, near := range perLight {
+			if rank >= len(near) {
+				continue
+			}
+			more = true
+			if o := near[rank]; !chosen[o.index] && len(selected) < MaxOccluders {
+				chosen[o.index] = true
+				selected = append(selected, l.occluders[o.index].SpaceComponent)
+			}
+		}
+	}
+
+	l.edges = l.edges[:0]
+	for _, space := range selected {
+		c := space.Corners()
+		// Clockwise on the screen, so the outside is on the left of every edge
+		loop := [4]engo.Point{c[0], c[1], c[3], c[2]}
+		for i, a := range loop {
+			b := loop[(i+1)%4]
+			l.edges = append(l.edges, edge{a.X * scale.X, a.Y * scale.Y, b.X * scale.X, b.Y * scale.Y})
+		}
+	}
+}
+
+// aabbDistance returns the distance from the point to the nearest point of the
+// AABB, which is 0 if the point is inside it.
+func aabbDistance(aabb engo.AABB, p engo.Point) float32 {
+	nearest := engo.Point{
+		X: math.Clamp(p.X, aabb.Min.X, aabb.Max.X),
+		Y: math.Clamp(p.Y, aabb.Min.Y, aabb.Max.Y),
+	}
+	return nearest.PointDistance(p)
+}
@@ -0,0 +1,113 @@
+package common
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLightDefaults(t *testing.T) {
+	sys := &LightSystem{}
+	basic := ecs.NewBasic()
+	l := &LightComponent{Radius: 100}
+	sys.Add(&basic, l, &SpaceComponent{})
+
+	assert.Equal(t, color.White, l.Color, "Lights should be white by default")
+	assert.Equal(t, float32(1), l.Intensity, "Lights should have an intensity of 1 by default")
+	assert.Equal(t, float32(25), l.Height, "Lights should be a quarter of their radius high by default")
+
+	sys.Remove(basic)
+	assert.Equal(t, 0, sys.EntityCount())
+}
+
+func TestLightUpdate(t *testing.T) {
+	engo.SetGlobalScale(engo.Point{X: 1, Y: 1})
+	defer LightingShader.setLights(nil, nil, nil)
+
+	sys := &LightSystem{Ambient: color.NRGBA{R: 51, G: 51, B: 51, A: 255}}
+	for i := 0; i < MaxLights+2; i++ {
+		basic := ecs.NewBasic()
+		space := &SpaceComponent{Position: engo.Point{X: float32(i * 10), Y: 20}, Width: 10, Height: 10}
+		sys.Add(&basic, &LightComponent{
+			Color:     color.NRGBA{R: 255, A: 255},
+			Intensity: 2,
+			Radius:    50,
+			Height:    10,
+			Offset:    engo.Point{X: 1},
+		}, space)
+	}
+	sys.Update(0)
+
+	assert.Len(t, LightingShader.lights, MaxLights, "Lights beyond MaxLights should be left out")
+	assert.Equal(t, light{x: 6, y: 25, height: 10, r: 2, radius: 50}, LightingShader.lights[0],
+		"Lights should be at the center of their entity plus their Offset, and their color multiplied by their Intensity")
+	assert.Equal(t, [3]float32{0.2, 0.2, 0.2}, LightingShader.ambient)
+
+	sys.entities = nil
+	sys.Update(0)
+	assert.Empty(t, LightingShader.lights, "Without lights, the LightingShader should draw unlit")
+}
+
+func TestLightOccluders(t *testing.T) {
+	engo.SetGlobalScale(engo.Point{X: 1, Y: 1})
+	defer LightingShader.setLights(nil, nil, nil)
+
+	sys := &LightSystem{}
+	basic := ecs.NewBasic()
+	sys.Add(&basic, &LightComponent{Radius: 100, Shadows: true}, &SpaceComponent{})
+
+	far := ecs.NewBasic()
+	sys.AddOccluder(&far, &OccluderComponent{}, &SpaceComponent{Position: engo.Point{X: 500}, Width: 10, Height: 10})
+	for i := 0; i < MaxOccluders+1; i++ {
+		near := ecs.NewBasic()
+		sys.AddOccluder(&near, &OccluderComponent{}, &SpaceComponent{Position: engo.Point{X: float32(20 + i)}, Width: 10, Height: 20})
+	}
+	sys.Update(0)
+
+	assert.Len(t, LightingShader.edges, 4*MaxOccluders, "Only the nearest MaxOccluders occluders should cast shadows")
+	assert.Equal(t, []edge{
+		{20, 0, 30, 0},
+		{30, 0, 30, 20},
+		{30, 20, 20, 20},
+		{20, 20, 20, 0},
+	}, LightingShader.edges[:4], "The edges of the nearest occluder should go around it clockwise")
+
+	sys.entities[0].Shadows = false
+	sys.Update(0)
+	assert.Empty(t, LightingShader.edges, "Occluders should be left out without lights casting shadows")
+
+	sys.Remove(far)
+	assert.Len(t, sys.occluders, MaxOccluders+1)
+
+	// A light away from the crowded one still gets its nearest occluder
+	sys.entities[0].Shadows = true
+	other := ecs.NewBasic()
+	sys.Add(&other, &LightComponent{Radius: 100, Shadows: true}, &SpaceComponent{Position: engo.Point{X: 1000}})
+	lonely := ecs.NewBasic()
+	sys.AddOccluder(&lonely, &OccluderComponent{}, &SpaceComponent{Position: engo.Point{X: 1020}, Width: 10, Height: 10})
+	sys.Update(0)
+	assert.Len(t, LightingShader.edges, 4*MaxOccluders, "The occluders of all lights together should be capped to MaxOccluders")
+	assert.Contains(t, LightingShader.edges, edge{1020, 0, 1030, 0}, "Every light should get its nearest occluders")
+}
+
+type lightOccluderEntity struct {
+	ecs.BasicEntity
+	LightComponent
+	OccluderComponent
+	SpaceComponent
+}
+
+func TestLightAddByInterface(t *testing.T) {
+	sys := &LightSystem{}
+	e := &lightOccluderEntity{BasicEntity: ecs.NewBasic(), LightComponent: LightComponent{Radius: 100}}
+	sys.AddByInterface(e)
+	assert.Equal(t, 1, sys.EntityCount(), "Entities that are lights and occluders should be added as lights")
+	assert.Len(t, sys.occluders, 1, "Entities that are lights and occluders should be added as occluders")
+
+	sys.Remove(e.BasicEntity)
+	assert.Equal(t, 0, sys.EntityCount())
+	assert.Empty(t, sys.occluders, "Removing the entity should remove it as both")
+}
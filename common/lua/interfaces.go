@@ -0,0 +1,35 @@
+package lua
+
+import "github.com/klopsch/engo/common"
+
+// GetLuaComponent Provides container classes ability to fulfil the interface and be accessed more simply by systems, eg in AddByInterface Methods
+func (c *Component) GetLuaComponent() *Component {
+	return c
+}
+
+// Face allows typesafe access to an anonymous Component
+type Face interface {
+	GetLuaComponent() *Component
+}
+
+// Scriptable is the required interface for the System.AddByInterface method
+type Scriptable interface {
+	common.BasicFace
+	Face
+	common.SpaceFace
+}
+
+// NotComponent is used to flag an entity as not in the System even if it has
+// the proper components
+type NotComponent struct{}
+
+// GetNotLuaComponent implements the NotScriptable interface
+func (n *NotComponent) GetNotLuaComponent() *NotComponent {
+	return n
+}
+
+// NotScriptable is an interface used to flag an entity as not in the System
+// even if it has the proper components
+type NotScriptable interface {
+	GetNotLuaComponent() *NotComponent
+}
@@ -0,0 +1,475 @@
+// Package lua drives the gameplay of entities with Lua scripts, so their behavior can be tweaked without recompiling
+// the game. It's optional: only games importing it depend on the Lua interpreter, gopher-lua.
+//
+// Every entity with a Component runs its own script, once when it's added to the System. The script sees the entity
+// as the global entity, with these fields:
+//
+//	entity.id                     -- the ID of the entity, read-only
+//	entity.x, entity.y            -- the Position of its SpaceComponent
+//	entity.width, entity.height   -- the size of its SpaceComponent
+//	entity.rotation               -- the Rotation of its SpaceComponent, in degrees
+//	entity.hidden                 -- the Hidden of its RenderComponent, if it has one
+//	entity:remove()               -- removes the entity from the World
+//
+// The script may define a global function update(dt), which the System calls every frame, and use these functions:
+//
+//	subscribe(type, handler)      -- calls handler(msg) for every message of the type dispatched to engo.Mailbox
+//	dispatch(type, fields)        -- dispatches a Message of the type with the fields of the table to engo.Mailbox
+//
+// Messages are passed to handlers as tables of the exported fields of their struct, along with their type, so
+// a WindowResizeMessage is {type = "WindowResizeMessage", OldWidth = ..., OldHeight = ..., NewWidth = ...,
+// NewHeight = ...}.
+//
+// Scripts are sandboxed: they can only use the base, string, table and math libraries, without the functions
+// loading code from files or strings. They can't access files or the OS, but nothing stops a script from looping
+// forever. The globals of each script are its own, and all scripts run in a single Lua state, so the System must only
+// be used from the goroutine updating the World.
+//
+// This is a first cut, binding the transform of entities and messages. Spawning entities and the rest of the
+// RenderComponent are left for later.
+package lua
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/klopsch/engo/common"
+	glua "github.com/yuin/gopher-lua"
+)
+
+// SystemPriority is the priority of the System, the same as the ScriptSystem's.
+const SystemPriority = common.ScriptSystemPriority
+
+// entityTypeName is the name of the metatable of the entity userdata.
+const entityTypeName = "engo.entity"
+
+// Component holds the Lua script of an entity.
+type Component struct {
+	// Source is the Lua code of the script.
+	Source string
+	// Name identifies the script in errors and the output of print, such as the name of its file.
+	Name string
+
+	env  *glua.LTable
+	subs []*engo.Subscription
+	err  error
+}
+
+// Err returns the error the script failed with, while loading or in update, or nil. A script with an error isn't
+// updated anymore.
+func (c *Component) Err() error {
+	return c.err
+}
+
+// Message is a message dispatched by a script, of the type and with the fields it was given.
+type Message struct {
+	Name   string
+	Fields map[string]interface{}
+}
+
+// Type implements the engo.Message interface.
+func (m Message) Type() string { return m.Name }
+
+type scriptEntity struct {
+	*ecs.BasicEntity
+	*Component
+	*common.SpaceComponent
+	render *common.RenderComponent
+}
+
+// System runs the Lua scripts of the entities with a Component.
+//
+// Like with the ScriptSystem, scripts may remove entities, including their own: an entity removed by a script isn't
+// updated anymore in that frame, and an entity added while scripts run is updated from the next frame on.
+type System struct {
+	state *glua.LState
+	world *ecs.World
+
+	entities []*scriptEntity
+	// running holds the entities being updated, so scripts can add and remove entities while they run
+	running []*scriptEntity
+	ids     map[uint64]struct{}
+}
+
+// Priority implements the ecs.Prioritizer interface.
+func (*System) Priority() int { return SystemPriority }
+
+// New initializes the System. It is run before any updates.
+func (s *System) New(w *ecs.World) {
+	s.world = w
+	s.init()
+}
+
+// init creates the sandboxed Lua state, if it doesn't exist yet.
+func (s *System) init() {
+	if s.state != nil {
+		return
+	}
+	s.ids = make(map[uint64]struct{})
+	L := glua.NewState(glua.Options{SkipOpenLibs: true})
+	for _, lib := range []struct {
+		name string
+		open glua.LGFunction
+	}{
+		{glua.BaseLibName, glua.OpenBase},
+		{glua.TabLibName, glua.OpenTable},
+		{glua.StringLibName, glua.OpenString},
+		{glua.MathLibName, glua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(glua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range []string{"dofile", "loadfile", "load", "loadstring", "require", "module", "collectgarbage",
+		"getfenv", "setfenv", "newproxy", "_printregs"} {
+		L.SetGlobal(name, glua.LNil)
+	}
+	L.SetGlobal("dispatch", L.NewFunction(luaDispatch))
+	// Strings share their metatable, which scripts shouldn't reach the string library through
+	if mt, ok := L.GetMetatable(glua.LString("")).(*glua.LTable); ok {
+		mt.RawSetString("__metatable", glua.LString("string"))
+	}
+
+	mt := L.NewTypeMetatable(entityTypeName)
+	mt.RawSetString("__metatable", glua.LString(entityTypeName))
+	mt.RawSetString("__index", L.NewFunction(s.luaIndex))
+	mt.RawSetString("__newindex", L.NewFunction(s.luaNewIndex))
+	s.state = L
+}
+
+// Close frees the Lua state. The System can't be used afterwards.
+func (s *System) Close() {
+	for _, e := range s.entities {
+		e.unsubscribe()
+	}
+	s.entities = nil
+	if s.state != nil {
+		s.state.Close()
+	}
+}
+
+// Add starts tracking the given entity and runs its script. The render may be nil, for entities which aren't drawn.
+// If the script fails to load, the error is logged and returned by the Err of the Component.
+func (s *System) Add(basic *ecs.BasicEntity, script *Component, space *common.SpaceComponent, render *common.RenderComponent) {
+	s.init()
+	e := &scriptEntity{basic, script, space, render}
+	s.entities = append(s.entities, e)
+	s.ids[basic.ID()] = struct{}{}
+
+	L := s.state
+	script.err = nil
+	script.env = s.newEnv()
+
+	ud := L.NewUserData()
+	ud.Value = e
+	L.SetMetatable(ud, L.GetTypeMetatable(entityTypeName))
+	script.env.RawSetString("entity", ud)
+	script.env.RawSetString("subscribe", L.NewFunction(e.luaSubscribe))
+	script.env.RawSetString("print", L.NewFunction(e.luaPrint))
+
+	fn, err := L.Load(strings.NewReader(script.Source), script.name())
+	if err == nil {
+		fn.Env = script.env
+		L.Push(fn)
+		err = L.PCall(0, 0, nil)
+	}
+	if err != nil {
+		e.fail(err)
+	}
+}
+
+// newEnv returns a table with copies of the globals, and of the library tables among them, for a script to run in.
+// Scripts only change their own copies, so they can't break the globals of the others.
+func (s *System) newEnv() *glua.LTable {
+	L := s.state
+	env := L.NewTable()
+	L.G.Global.ForEach(func(k, v glua.LValue) {
+		if lib, ok := v.(*glua.LTable); ok && lib != L.G.Global {
+			c := L.NewTable()
+			lib.ForEach(c.RawSet)
+			v = c
+		}
+		env.RawSet(k, v)
+	})
+	env.RawSetString("_G", env)
+	return env
+}
+
+// AddByInterface allows an Entity to be added directly using the Scriptable interface, which every entity containing
+// the BasicEntity, Component and SpaceComponent anonymously automatically satisfies. Its RenderComponent is bound
+// too, if it has one.
+func (s *System) AddByInterface(i ecs.Identifier) {
+	o, _ := i.(Scriptable)
+	var render *common.RenderComponent
+	if r, ok := i.(common.RenderFace); ok {
+		render = r.GetRenderComponent()
+	}
+	s.Add(o.GetBasicEntity(), o.GetLuaComponent(), o.GetSpaceComponent(), render)
+}
+
+// Remove stops tracking the given entity, and its handlers of messages.
+func (s *System) Remove(basic ecs.BasicEntity) {
+	for index, e := range s.entities {
+		if e.BasicEntity.ID() == basic.ID() {
+			e.unsubscribe()
+			s.entities = append(s.entities[:index], s.entities[index+1:]...)
+			delete(s.ids, basic.ID())
+			return
+		}
+	}
+}
+
+// EntityCount returns the number of entities the System holds.
+func (s *System) EntityCount() int {
+	return len(s.entities)
+}
+
+// Update calls the update function of the scripts of the enabled entities.
+func (s *System) Update(dt float32) {
+	s.running = append(s.running[:0], s.entities...)
+	for _, e := range s.running {
+		if _, ok := s.ids[e.BasicEntity.ID()]; !ok || e.err != nil || !common.EntityEnabled(s.world, e.BasicEntity) {
+			continue
+		}
+		update, ok := e.env.RawGetString("update").(*glua.LFunction)
+		if !ok {
+			continue
+		}
+		if err := s.state.CallByParam(glua.P{Fn: update, Protect: true}, glua.LNumber(dt)); err != nil {
+			e.fail(err)
+		}
+	}
+}
+
+// name returns the name of the script, for errors.
+func (c *Component) name() string {
+	if c.Name == "" {
+		return "script"
+	}
+	return c.Name
+}
+
+// fail logs the error of the script, which stops it from being updated.
+func (e *scriptEntity) fail(err error) {
+	e.err = fmt.Errorf("lua: %s of entity %d: %w", e.name(), e.BasicEntity.ID(), err)
+	log.Println(e.err)
+}
+
+// unsubscribe stops the handlers of messages of the script.
+func (e *scriptEntity) unsubscribe() {
+	for _, sub := range e.subs {
+		sub.Unsubscribe()
+	}
+	e.subs = nil
+}
+
+// luaSubscribe implements subscribe(type, handler) for the script of the entity.
+func (e *scriptEntity) luaSubscribe(L *glua.LState) int {
+	messageType := L.CheckString(1)
+	handler := L.CheckFunction(2)
+	if engo.Mailbox == nil {
+		L.RaiseError("there is no engo.Mailbox to subscribe to")
+	}
+	e.subs = append(e.subs, engo.Mailbox.Subscribe(messageType, func(msg engo.Message) {
+		if e.err != nil {
+			return
+		}
+		if err := L.CallByParam(glua.P{Fn: handler, Protect: true}, messageToLua(L, msg)); err != nil {
+			e.fail(err)
+		}
+	}))
+	return 0
+}
+
+// luaPrint implements print for the script of the entity, logging the values.
+func (e *scriptEntity) luaPrint(L *glua.LState) int {
+	values := []interface{}{e.name() + ":"}
+	for i := 1; i <= L.GetTop(); i++ {
+		values = append(values, L.ToStringMeta(L.Get(i)).String())
+	}
+	log.Println(values...)
+	return 0
+}
+
+// luaDispatch implements dispatch(type, fields).
+func luaDispatch(L *glua.LState) int {
+	msg := Message{Name: L.CheckString(1), Fields: make(map[string]interface{})}
+	if fields := L.OptTable(2, nil); fields != nil {
+		fields.ForEach(func(k, v glua.LValue) {
+			if key, ok := k.(glua.LString); ok {
+				msg.Fields[string(key)] = luaToGo(v, maxMessageDepth)
+			}
+		})
+	}
+	if engo.Mailbox == nil {
+		L.RaiseError("there is no engo.Mailbox to dispatch to")
+	}
+	engo.Mailbox.Dispatch(msg)
+	return 0
+}
+
+// checkEntity returns the entity the userdata at n of the stack holds.
+func checkEntity(L *glua.LState, n int) *scriptEntity {
+	if e, ok := L.CheckUserData(n).Value.(*scriptEntity); ok {
+		return e
+	}
+	L.ArgError(n, "entity expected")
+	return nil
+}
+
+// luaIndex implements reading the fields of entity.
+func (s *System) luaIndex(L *glua.LState) int {
+	e := checkEntity(L, 1)
+	switch key := L.CheckString(2); key {
+	case "id":
+		L.Push(glua.LNumber(e.BasicEntity.ID()))
+	case "x":
+		L.Push(glua.LNumber(e.Position.X))
+	case "y":
+		L.Push(glua.LNumber(e.Position.Y))
+	case "width":
+		L.Push(glua.LNumber(e.Width))
+	case "height":
+		L.Push(glua.LNumber(e.Height))
+	case "rotation":
+		L.Push(glua.LNumber(e.Rotation))
+	case "hidden":
+		L.Push(glua.LBool(e.render != nil && e.render.Hidden))
+	case "remove":
+		L.Push(L.NewFunction(s.luaRemove))
+	default:
+		L.Push(glua.LNil)
+	}
+	return 1
+}
+
+// luaNewIndex implements writing the fields of entity.
+func (s *System) luaNewIndex(L *glua.LState) int {
+	e := checkEntity(L, 1)
+	key := L.CheckString(2)
+	if key == "hidden" {
+		if e.render == nil {
+			L.RaiseError("entity %d has no RenderComponent", e.BasicEntity.ID())
+		}
+		e.render.Hidden = L.ToBool(3)
+		return 0
+	}
+	value := float32(L.CheckNumber(3))
+	switch key {
+	case "x":
+		e.Position.X = value
+	case "y":
+		e.Position.Y = value
+	case "width":
+		e.Width = value
+	case "height":
+		e.Height = value
+	case "rotation":
+		e.Rotation = value
+	default:
+		L.RaiseError("entity has no field %q to set", key)
+	}
+	return 0
+}
+
+// luaRemove implements entity:remove().
+func (s *System) luaRemove(L *glua.LState) int {
+	e := checkEntity(L, 1)
+	if s.world == nil {
+		L.RaiseError("the System isn't in a World")
+	}
+	s.world.RemoveEntity(*e.BasicEntity)
+	return 0
+}
+
+// maxMessageDepth is how deep the fields of messages are converted to tables, which stops cycles of pointers.
+const maxMessageDepth = 8
+
+// messageToLua converts the message to a table of its exported fields and its type.
+func messageToLua(L *glua.LState, msg engo.Message) glua.LValue {
+	t, ok := goToLua(L, reflect.ValueOf(msg), maxMessageDepth).(*glua.LTable)
+	if !ok {
+		t = L.NewTable()
+	}
+	t.RawSetString("type", glua.LString(msg.Type()))
+	return t
+}
+
+// goToLua converts the value to Lua: numbers, strings and booleans as such, structs as tables of their exported
+// fields, slices and arrays as sequences, and maps with string keys as tables. Anything else is nil.
+func goToLua(L *glua.LState, v reflect.Value, depth int) glua.LValue {
+	if depth == 0 || !v.IsValid() {
+		return glua.LNil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return glua.LBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return glua.LNumber(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return glua.LNumber(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return glua.LNumber(v.Float())
+	case reflect.String:
+		return glua.LString(v.String())
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return glua.LNil
+		}
+		return goToLua(L, v.Elem(), depth)
+	case reflect.Struct:
+		t := L.NewTable()
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Type().Field(i); f.IsExported() {
+				t.RawSetString(f.Name, goToLua(L, v.Field(i), depth-1))
+			}
+		}
+		return t
+	case reflect.Slice, reflect.Array:
+		t := L.NewTable()
+		for i := 0; i < v.Len(); i++ {
+			t.Append(goToLua(L, v.Index(i), depth-1))
+		}
+		return t
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return glua.LNil
+		}
+		t := L.NewTable()
+		iter := v.MapRange()
+		for iter.Next() {
+			t.RawSetString(iter.Key().String(), goToLua(L, iter.Value(), depth-1))
+		}
+		return t
+	}
+	return glua.LNil
+}
+
+// luaToGo converts the value of a field of a dispatched message to Go: numbers to float64, strings, booleans, and
+// tables to maps of their string keys. Anything else is nil.
+func luaToGo(v glua.LValue, depth int) interface{} {
+	if depth == 0 {
+		return nil
+	}
+	switch v := v.(type) {
+	case glua.LNumber:
+		return float64(v)
+	case glua.LString:
+		return string(v)
+	case glua.LBool:
+		return bool(v)
+	case *glua.LTable:
+		m := make(map[string]interface{})
+		v.ForEach(func(k, value glua.LValue) {
+			if key, ok := k.(glua.LString); ok {
+				m[string(key)] = luaToGo(value, depth-1)
+			}
+		})
+		return m
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package lua
+
+import (
+	"testing"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/klopsch/engo/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type scripted struct {
+	ecs.BasicEntity
+	Component
+	common.SpaceComponent
+	common.RenderComponent
+}
+
+func newScriptWorld() (*ecs.World, *System) {
+	engo.Mailbox = &engo.MessageManager{}
+	w := &ecs.World{}
+	sys := &System{}
+	var scriptable *Scriptable
+	var notScriptable *NotScriptable
+	w.AddSystemInterface(sys, scriptable, notScriptable)
+	return w, sys
+}
+
+func TestSystemTransform(t *testing.T) {
+	w, sys := newScriptWorld()
+	defer sys.Close()
+
+	e := &scripted{BasicEntity: ecs.NewBasic(), Component: Component{Source: `
+		entity.width = 10
+		function update(dt)
+			entity.x = entity.x + 100 * dt
+			entity.rotation = entity.rotation + 90 * dt
+			if entity.x >= 50 then
+				entity.hidden = true
+			end
+		end
+	`}}
+	w.AddEntity(e)
+	assert.NoError(t, e.Err())
+	assert.Equal(t, float32(10), e.Width, "Scripts should run when the entity is added")
+
+	w.Update(0.25)
+	assert.Equal(t, float32(25), e.Position.X)
+	assert.Equal(t, float32(22.5), e.Rotation)
+	assert.False(t, e.Hidden)
+	w.Update(0.25)
+	assert.True(t, e.Hidden, "Scripts should set the RenderComponent")
+}
+
+func TestSystemMessages(t *testing.T) {
+	w, sys := newScriptWorld()
+	defer sys.Close()
+
+	e := &scripted{BasicEntity: ecs.NewBasic(), Component: Component{Source: `
+		subscribe("WindowResizeMessage", function(msg)
+			entity.width = msg.NewWidth
+			dispatch("Resized", {width = msg.NewWidth, by = msg.type})
+		end)
+	`}}
+	w.AddEntity(e)
+	assert.NoError(t, e.Err())
+
+	var got Message
+	engo.Mailbox.Listen("Resized", func(msg engo.Message) {
+		got = msg.(Message)
+	})
+	engo.Mailbox.Dispatch(engo.WindowResizeMessage{NewWidth: 640, NewHeight: 480})
+	assert.Equal(t, float32(640), e.Width, "Scripts should receive the fields of messages")
+	assert.Equal(t, Message{Name: "Resized", Fields: map[string]interface{}{"width": 640.0, "by": "WindowResizeMessage"}}, got,
+		"Scripts should dispatch messages")
+
+	w.RemoveEntity(e.BasicEntity)
+	engo.Mailbox.Dispatch(engo.WindowResizeMessage{NewWidth: 800, NewHeight: 600})
+	assert.Equal(t, float32(640), e.Width, "Removed entities should stop receiving messages")
+}
+
+func TestSystemRemove(t *testing.T) {
+	w, sys := newScriptWorld()
+	defer sys.Close()
+
+	e := &scripted{BasicEntity: ecs.NewBasic(), Component: Component{Source: `
+		function update(dt)
+			entity:remove()
+		end
+	`}}
+	w.AddEntity(e)
+	assert.Equal(t, 1, sys.EntityCount())
+	w.Update(1)
+	assert.NoError(t, e.Err())
+	assert.Equal(t, 0, sys.EntityCount(), "Scripts should be able to remove their entity")
+}
+
+func TestSystemSandbox(t *testing.T) {
+	w, sys := newScriptWorld()
+	defer sys.Close()
+
+	for _, source := range []string{
+		`io.open("file")`,
+		`os.exit(1)`,
+		`dofile("script.lua")`,
+		`loadstring("return 1")`,
+		`require("os")`,
+	} {
+		e := &scripted{BasicEntity: ecs.NewBasic(), Component: Component{Source: source}}
+		w.AddEntity(e)
+		assert.Error(t, e.Err(), "Scripts shouldn't be able to run %s", source)
+	}
+
+	e := &scripted{BasicEntity: ecs.NewBasic(), Component: Component{Name: "broken.lua", Source: `
+		function update(dt)
+			entity.speed = 1
+		end
+	`}}
+	w.AddEntity(e)
+	assert.NoError(t, e.Err())
+	w.Update(1)
+	assert.Error(t, e.Err(), "Scripts shouldn't set unknown fields of entities")
+	assert.Contains(t, e.Err().Error(), "broken.lua")
+}
+
+func TestSystemIsolation(t *testing.T) {
+	w, sys := newScriptWorld()
+	defer sys.Close()
+
+	e := &scripted{BasicEntity: ecs.NewBasic(), Component: Component{Source: `
+		pairs = nil
+		math.floor = nil
+		_G.dispatch = nil
+		string.upper = nil
+		local mt = getmetatable("")
+		if type(mt) == "table" then
+			mt.__index = {}
+		end
+	`}}
+	w.AddEntity(e)
+	assert.NoError(t, e.Err())
+
+	other := &scripted{BasicEntity: ecs.NewBasic(), Component: Component{Source: `
+		for _ in pairs({}) do end
+		entity.width = math.floor(10.5)
+		assert(dispatch ~= nil)
+		assert(string.upper("a") == "A")
+		assert(("a"):upper() == "A")
+	`}}
+	w.AddEntity(other)
+	assert.NoError(t, other.Err(), "Scripts shouldn't change the globals of other scripts")
+	assert.Equal(t, float32(10), other.Width)
+}
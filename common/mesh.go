@@ -0,0 +1,130 @@
+package common
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/klopsch/engo"
+	"github.com/klopsch/engo/math"
+	"github.com/klopsch/gl"
+)
+
+var (
+	// whitePixel is the texture of Meshes without one, a single white pixel which the Color of the RenderComponent
+	// tints.
+	whitePixel *gl.Texture
+	// whitePixelContext is the OpenGL context whitePixel was uploaded to. A new context, such as after the window
+	// was recreated, needs a new upload.
+	whitePixelContext *gl.Context
+)
+
+// Mesh is a Drawable made of arbitrary triangles, such as polygons or deformed sprites, drawn by the DefaultShader or
+// HUDShader like sprites. Each triangle is batched as a quad with its last corner repeated, so Meshes share batches
+// with the sprites drawn from the same texture.
+//
+// A Mesh is either textured, mapping its vertices to points of its texture, or solid, filled with the Color of its
+// RenderComponent.
+type Mesh struct {
+	positions []engo.Point
+	uvs       []engo.Point
+	indices   []uint16
+	texture   Drawable
+
+	width, height float32
+	// vertices holds the triangles as quads, laid out like the tiles of a TileMesh
+	vertices []float32
+}
+
+// NewMesh creates a Mesh from the positions of its vertices, relative to the SpaceComponent of the entity drawing it,
+// and the indices of the vertices of its triangles, three per triangle. If texture isn't nil, uvs are the points of
+// the texture mapped to each vertex, from {0, 0} at its top-left to {1, 1} at its bottom-right, such as a sprite of a
+// Spritesheet. Without a texture, the Mesh is solid and uvs are ignored.
+func NewMesh(positions, uvs []engo.Point, indices []uint16, texture Drawable) (*Mesh, error) {
+	if len(indices)%3 != 0 {
+		return nil, fmt.Errorf("%d indices don't make whole triangles", len(indices))
+	}
+	if texture != nil && len(uvs) != len(positions) {
+		return nil, fmt.Errorf("%d texture coordinates don't match the %d vertices", len(uvs), len(positions))
+	}
+	m := &Mesh{uvs: uvs, indices: indices, texture: texture}
+	if err := m.SetPositions(positions); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SetPositions moves the vertices of the Mesh, such as to deform it, keeping its triangles and texture coordinates.
+// It returns an error, leaving the Mesh as it was, if an index of the Mesh is out of range of the positions, or if
+// the Mesh is textured and the positions don't match its texture coordinates.
+func (m *Mesh) SetPositions(positions []engo.Point) error {
+	for _, i := range m.indices {
+		if int(i) >= len(positions) {
+			return fmt.Errorf("index %d is out of range of the %d vertices", i, len(positions))
+		}
+	}
+	if m.texture != nil && len(m.uvs) != len(positions) {
+		return fmt.Errorf("%d positions don't match the %d texture coordinates", len(positions), len(m.uvs))
+	}
+	m.positions = positions
+
+	var u, v, u2, v2 float32 = 0, 0, 1, 1
+	if m.texture != nil {
+		u, v, u2, v2 = m.texture.View()
+	}
+	m.vertices = m.vertices[:0]
+	for i := 0; i < len(m.indices); i += 3 {
+		for _, index := range [4]uint16{m.indices[i], m.indices[i+1], m.indices[i+2], m.indices[i+2]} {
+			p := m.positions[index]
+			var uv engo.Point
+			if m.texture != nil {
+				uv = engo.Point{X: u + (u2-u)*m.uvs[index].X, Y: v + (v2-v)*m.uvs[index].Y}
+			}
+			m.vertices = append(m.vertices, p.X, p.Y, uv.X, uv.Y)
+		}
+	}
+
+	m.width, m.height = 0, 0
+	for _, p := range m.positions {
+		m.width = math.Max(m.width, p.X)
+		m.height = math.Max(m.height, p.Y)
+	}
+	return nil
+}
+
+// TriangleCount returns the number of triangles in the Mesh.
+func (m *Mesh) TriangleCount() int {
+	return len(m.indices) / 3
+}
+
+// Texture returns the OpenGL ID of the texture of the Mesh, or of a white pixel for a solid Mesh.
+func (m *Mesh) Texture() *gl.Texture {
+	if m.texture != nil {
+		return m.texture.Texture()
+	}
+	if (whitePixel == nil || whitePixelContext != engo.Gl) && !engo.Headless() {
+		img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+		img.SetNRGBA(0, 0, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+		whitePixel = UploadTexture(NewImageObject(img))
+		whitePixelContext = engo.Gl
+	}
+	return whitePixel
+}
+
+// Width returns the right-most position of the vertices.
+func (m *Mesh) Width() float32 {
+	return m.width
+}
+
+// Height returns the bottom-most position of the vertices.
+func (m *Mesh) Height() float32 {
+	return m.height
+}
+
+// View returns the whole texture, as the texture coordinates are set per vertex.
+func (m *Mesh) View() (float32, float32, float32, float32) {
+	return 0, 0, 1, 1
+}
+
+// Close does nothing, as the texture belongs to the Drawable the Mesh was created with.
+func (m *Mesh) Close() {}
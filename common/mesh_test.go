@@ -0,0 +1,50 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMeshValidation(t *testing.T) {
+	positions := []engo.Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 0, Y: 10}}
+
+	_, err := NewMesh(positions, nil, []uint16{0, 1}, nil)
+	assert.Error(t, err, "Indices should make whole triangles")
+
+	_, err = NewMesh(positions, nil, []uint16{0, 1, 3}, nil)
+	assert.Error(t, err, "Indices should be in range of the vertices")
+
+	_, err = NewMesh(positions, []engo.Point{{}}, []uint16{0, 1, 2}, skeletonSprite(10, 10))
+	assert.Error(t, err, "Textured meshes should have texture coordinates for every vertex")
+
+	_, err = NewMesh(positions, nil, []uint16{0, 1, 2}, nil)
+	assert.NoError(t, err, "Solid meshes don't need texture coordinates")
+}
+
+func TestMeshVertices(t *testing.T) {
+	sprite := Texture{width: 10, height: 10, viewport: engo.AABB{Min: engo.Point{X: 0.5}, Max: engo.Point{X: 1, Y: 0.5}}}
+	positions := []engo.Point{{X: 0, Y: 0}, {X: 20, Y: 0}, {X: 20, Y: 15}, {X: 0, Y: 15}}
+	uvs := []engo.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}}
+	mesh, err := NewMesh(positions, uvs, []uint16{0, 1, 2, 0, 2, 3}, sprite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, mesh.TriangleCount())
+	assert.Equal(t, float32(20), mesh.Width())
+	assert.Equal(t, float32(15), mesh.Height())
+	assert.Equal(t, []float32{
+		0, 0, 0.5, 0, 20, 0, 1, 0, 20, 15, 1, 0.5, 20, 15, 1, 0.5,
+		0, 0, 0.5, 0, 20, 15, 1, 0.5, 0, 15, 0.5, 0.5, 0, 15, 0.5, 0.5,
+	}, mesh.vertices, "Triangles should be quads with their last corner repeated, mapped into the view of the texture")
+
+	assert.Error(t, mesh.SetPositions(positions[:3]), "Positions should cover every index")
+	assert.Error(t, mesh.SetPositions(append(positions, engo.Point{})), "Positions should match the texture coordinates")
+	assert.Equal(t, float32(20), mesh.Width(), "Invalid positions should leave the Mesh as it was")
+
+	assert.NoError(t, mesh.SetPositions([]engo.Point{{X: 0, Y: 0}, {X: 30, Y: 0}, {X: 30, Y: 5}, {X: 0, Y: 5}}))
+	assert.Equal(t, float32(30), mesh.Width(), "SetPositions should update the size")
+	assert.Equal(t, float32(5), mesh.Height(), "SetPositions should update the size")
+	assert.Equal(t, []float32{30, 5, 1, 0.5}, mesh.vertices[8:12], "SetPositions should keep the texture coordinates")
+}
@@ -5,7 +5,6 @@ import (
 
 	"github.com/klopsch/ecs"
 	"github.com/klopsch/engo"
-	"github.com/klopsch/engo/math"
 )
 
 // Cursor is a reference to a GLFW-cursor - to be used with the `SetCursor` method.
@@ -28,7 +27,15 @@ const (
 	CursorVResize
 )
 
-// MouseSystemPriority is the priority of the MouseSystem
+// MouseSystemPriority is the priority of the MouseSystem. Systems are updated
+// from the highest priority to the lowest, and the MouseSystem consumes the
+// clicks on its entities, so a System handling clicks on a custom HUD before
+// the entities needs a higher priority, and a System handling the clicks on
+// the world, such as one moving a unit to where it's clicked, needs a lower
+// one. The camera Systems from the MouseZoomer to the EntityScroller have
+// higher priorities, and the MouseRotator has the same one, so they're not
+// guaranteed to skip the clicks on entities; wrap them in a System with a
+// lower priority if they should.
 const MouseSystemPriority = 100
 
 // Mouse is the representation of the physical mouse
@@ -111,6 +118,14 @@ type MouseComponent struct {
 	rightStartedMoving bool
 }
 
+// clearEvents clears the flags which are only set in the frame the event
+// happened.
+func (mc *MouseComponent) clearEvents() {
+	mc.Clicked, mc.Released = false, false
+	mc.RightClicked, mc.RightReleased = false, false
+	mc.Enter, mc.Leave = false, false
+}
+
 type mouseEntity struct {
 	*ecs.BasicEntity
 	*MouseComponent
@@ -123,6 +138,7 @@ type MouseSystem struct {
 	entities []mouseEntity
 	world    *ecs.World
 	camera   *CameraSystem
+	states   *entityStates
 
 	mouseX    float32
 	mouseY    float32
@@ -132,9 +148,14 @@ type MouseSystem struct {
 // Priority returns a priority higher than most, to ensure that this System runs before all others
 func (m *MouseSystem) Priority() int { return MouseSystemPriority }
 
+// Pausable implements the engo.Pauser interface. The MouseSystem keeps running while the game is paused, so menus
+// can still be clicked.
+func (m *MouseSystem) Pausable() bool { return false }
+
 // New initializes the MouseSystem. It is run before any updates.
 func (m *MouseSystem) New(w *ecs.World) {
 	m.world = w
+	m.states = worldEntityStates(w)
 
 	// First check if the CameraSystem is available
 	for _, system := range m.world.Systems() {
@@ -180,25 +201,50 @@ func (m *MouseSystem) Remove(basic ecs.BasicEntity) {
 	}
 }
 
+// EntityCount returns the number of entities the MouseSystem holds.
+func (m *MouseSystem) EntityCount() int {
+	return len(m.entities)
+}
+
 // Update updates all the entities in the MouseSystem.
 func (m *MouseSystem) Update(dt float32) {
+	if engo.Input.Ignored() {
+		// The events of the last frame are over, even though no new ones are
+		// seen while input is ignored
+		for _, e := range m.entities {
+			e.MouseComponent.clearEvents()
+		}
+		return
+	}
+
 	// Translate Mouse.X and Mouse.Y into "game coordinates"
-	switch engo.CurrentBackEnd {
-	case engo.BackEndGLFW, engo.BackEndSDL, engo.BackEndVulkan:
-		m.mouseX = ((engo.Input.Mouse.X * m.camera.Z() * engo.GameWidth() / engo.WindowWidth()) + (m.camera.X()-(engo.GameWidth()/2)*m.camera.Z())/engo.GetGlobalScale().X)
-		m.mouseY = ((engo.Input.Mouse.Y * m.camera.Z() * engo.GameHeight() / engo.WindowHeight()) + (m.camera.Y()-(engo.GameHeight()/2)*m.camera.Z())/engo.GetGlobalScale().Y)
-	case engo.BackEndMobile, engo.BackEndWeb:
-		m.mouseX = engo.Input.Mouse.X*m.camera.Z() + (m.camera.X()-(engo.GameWidth()/2)*m.camera.Z()+(engo.ResizeXOffset/2))/engo.GetGlobalScale().X
-		m.mouseY = engo.Input.Mouse.Y*m.camera.Z() + (m.camera.Y()-(engo.GameHeight()/2)*m.camera.Z()+(engo.ResizeYOffset/2))/engo.GetGlobalScale().Y
+	world := m.camera.ScreenToWorld(engo.Point{X: engo.Input.Mouse.X, Y: engo.Input.Mouse.Y})
+	m.mouseX, m.mouseY = world.X, world.Y
+
+	// Only the topmost entity under the cursor is hovered, so overlapping
+	// entities below it don't react as well
+	top := -1
+	for i, e := range m.entities {
+		if e.SpaceComponent == nil || (e.RenderComponent != nil && e.RenderComponent.Hidden) || m.states.disabled(e.BasicEntity.ID()) {
+			continue
+		}
+		if !e.SpaceComponent.Contains(m.mousePoint(e)) {
+			continue
+		}
+		if top < 0 || mouseAbove(e, m.entities[top]) {
+			top = i
+		}
 	}
 
-	// Rotate if needed
-	if m.camera.angle != 0 {
-		sin, cos := math.Sincos(m.camera.angle * math.Pi / 180)
-		m.mouseX, m.mouseY = m.mouseX*cos+m.mouseY*sin, m.mouseY*cos-m.mouseX*sin
+	// A System with a higher priority, such as one handling a custom HUD, may
+	// have handled the click already
+	action := engo.Input.Mouse.Action
+	consumed := engo.Input.MouseConsumed(engo.Input.Mouse.Button)
+	if consumed && (action == engo.Press || action == engo.Release) {
+		action = engo.Neutral
 	}
 
-	for _, e := range m.entities {
+	for i, e := range m.entities {
 		// Reset all values except these
 		*e.MouseComponent = MouseComponent{
 			Track:                e.MouseComponent.Track,
@@ -218,30 +264,19 @@ func (m *MouseSystem) Update(dt float32) {
 			e.MouseComponent.MouseY = m.mouseY
 		}
 
-		mx := m.mouseX
-		my := m.mouseY
-
 		if e.SpaceComponent == nil {
 			continue // with other entities
 		}
 
-		if e.RenderComponent != nil {
-			// Hardcoded special case for the HUD | TODO: make generic instead of hardcoding
-			if e.RenderComponent.shader == HUDShader || e.RenderComponent.shader == LegacyHUDShader {
-				mx = engo.Input.Mouse.X
-				my = engo.Input.Mouse.Y
-			}
-
-			if e.RenderComponent.Hidden {
-				continue // skip hidden components
-			}
+		if e.RenderComponent != nil && e.RenderComponent.Hidden {
+			continue // skip hidden components
 		}
+		p := m.mousePoint(e)
+		mx, my := p.X, p.Y
 
-		// If the Mouse component is a tracker we always update it
-		// Check if the X-value is within range
-		// and if the Y-value is within range
-		if e.MouseComponent.Track || e.MouseComponent.startedDragging ||
-			e.SpaceComponent.Contains(engo.Point{X: mx, Y: my}) {
+		// If the Mouse component is a tracker we always update it, otherwise
+		// only if it's the topmost entity under the cursor
+		if e.MouseComponent.Track || e.MouseComponent.startedDragging || i == top {
 
 			e.MouseComponent.Enter = !e.MouseComponent.Hovered
 			e.MouseComponent.Hovered = true
@@ -253,7 +288,7 @@ func (m *MouseSystem) Update(dt float32) {
 				e.MouseComponent.MouseY = my
 			}
 
-			switch engo.Input.Mouse.Action {
+			switch action {
 			case engo.Press:
 				switch engo.Input.Mouse.Button {
 				case engo.MouseButtonLeft:
@@ -315,4 +350,47 @@ func (m *MouseSystem) Update(dt float32) {
 		// implementers can take different decisions based on those
 		e.MouseComponent.Modifier = engo.Input.Mouse.Modifer
 	}
+
+	// Clicks on an entity don't go through to the Systems updated after this
+	// one, such as ones moving a unit to where the world is clicked
+	if top >= 0 && (action == engo.Press || action == engo.Release) {
+		engo.Input.ConsumeMouse(engo.Input.Mouse.Button)
+	}
+}
+
+// mousePoint returns the position of the cursor for the entity, which is in
+// world coordinates unless the entity is drawn on the HUD.
+func (m *MouseSystem) mousePoint(e mouseEntity) engo.Point {
+	if e.RenderComponent != nil && isHUD(e.RenderComponent) {
+		return ScreenToHUD(engo.Point{X: engo.Input.Mouse.X, Y: engo.Input.Mouse.Y})
+	}
+	return engo.Point{X: m.mouseX, Y: m.mouseY}
+}
+
+// isHUD reports whether the entity is drawn on the HUD, above the world.
+// TODO: make generic instead of hardcoding
+func isHUD(r *RenderComponent) bool {
+	return r.shader == HUDShader || r.shader == LegacyHUDShader
+}
+
+// mouseAbove reports whether a is drawn above b, so it's hovered rather than
+// b when the cursor is above both. HUD entities are above the world, then the
+// higher Z-Index wins, and entities on the same Z-Index are drawn in the order
+// they were created.
+func mouseAbove(a, b mouseEntity) bool {
+	var aHUD, bHUD bool
+	var aZ, bZ float32
+	if a.RenderComponent != nil {
+		aHUD, aZ = isHUD(a.RenderComponent), a.RenderComponent.zIndex
+	}
+	if b.RenderComponent != nil {
+		bHUD, bZ = isHUD(b.RenderComponent), b.RenderComponent.zIndex
+	}
+	if aHUD != bHUD {
+		return aHUD
+	}
+	if aZ != bZ {
+		return aZ > bZ
+	}
+	return a.ID() > b.ID()
 }
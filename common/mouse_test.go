@@ -0,0 +1,158 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+type mouseTestEntity struct {
+	ecs.BasicEntity
+	MouseComponent
+	SpaceComponent
+	RenderComponent
+}
+
+func newMouseTestEntity(x, y, z float32) *mouseTestEntity {
+	e := &mouseTestEntity{BasicEntity: ecs.NewBasic()}
+	e.SpaceComponent = SpaceComponent{Position: engo.Point{X: x, Y: y}, Width: 20, Height: 20}
+	e.RenderComponent.SetZIndex(z)
+	return e
+}
+
+func TestMouseSystemTopmost(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+		Width:        800,
+		Height:       600,
+	}, &cameraConversionScene{})
+	initialize()
+
+	below := newMouseTestEntity(100, 100, 0)
+	above := newMouseTestEntity(110, 110, 1)
+	apart := newMouseTestEntity(200, 200, 0)
+	sys := &MouseSystem{camera: cam}
+	for _, e := range []*mouseTestEntity{above, below, apart} {
+		sys.Add(&e.BasicEntity, &e.MouseComponent, &e.SpaceComponent, &e.RenderComponent)
+	}
+
+	// Over both below and above
+	p := cam.WorldToScreen(engo.Point{X: 115, Y: 115})
+	engo.Input.Mouse.X, engo.Input.Mouse.Y = p.X, p.Y
+	engo.Input.Mouse.Action = engo.Press
+	engo.Input.Mouse.Button = engo.MouseButtonLeft
+	sys.Update(0)
+	assert.True(t, above.Hovered, "The topmost entity under the cursor should be hovered")
+	assert.True(t, above.Clicked, "The topmost entity under the cursor should be clicked")
+	assert.False(t, below.Hovered, "An entity below the topmost one should not be hovered")
+	assert.False(t, below.Clicked, "An entity below the topmost one should not be clicked")
+	assert.False(t, apart.Hovered, "An entity not under the cursor should not be hovered")
+
+	engo.Input.Mouse.Action = engo.Release
+	sys.Update(0)
+	assert.True(t, above.Released, "The topmost entity under the cursor should be released")
+	assert.False(t, below.Released, "An entity below the topmost one should not be released")
+
+	// Only over below
+	p = cam.WorldToScreen(engo.Point{X: 105, Y: 105})
+	engo.Input.Mouse.X, engo.Input.Mouse.Y = p.X, p.Y
+	engo.Input.Mouse.Action = engo.Neutral
+	sys.Update(0)
+	assert.True(t, below.Hovered, "The only entity under the cursor should be hovered")
+	assert.True(t, below.Enter, "The entity should be entered")
+	assert.False(t, above.Hovered, "An entity not under the cursor should not be hovered")
+	assert.True(t, above.Leave, "The entity should be left")
+}
+
+func TestMouseAbove(t *testing.T) {
+	world := newMouseTestEntity(0, 0, 5)
+	hud := newMouseTestEntity(0, 0, 0)
+	hud.RenderComponent.SetShader(HUDShader)
+	higher := newMouseTestEntity(0, 0, 6)
+	later := newMouseTestEntity(0, 0, 5)
+	noRender := &mouseTestEntity{BasicEntity: ecs.NewBasic()}
+
+	entity := func(e *mouseTestEntity, render bool) mouseEntity {
+		m := mouseEntity{&e.BasicEntity, &e.MouseComponent, &e.SpaceComponent, nil}
+		if render {
+			m.RenderComponent = &e.RenderComponent
+		}
+		return m
+	}
+
+	assert.True(t, mouseAbove(entity(hud, true), entity(world, true)), "HUD entities should be above the world")
+	assert.False(t, mouseAbove(entity(world, true), entity(hud, true)), "The world should be below HUD entities")
+	assert.True(t, mouseAbove(entity(higher, true), entity(world, true)), "A higher Z-Index should be above")
+	assert.True(t, mouseAbove(entity(later, true), entity(world, true)), "Later entities should be above on the same Z-Index")
+	assert.True(t, mouseAbove(entity(world, true), entity(noRender, false)), "Entities without a RenderComponent should be at Z-Index 0")
+}
+
+func TestMouseSystemConsume(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+		Width:        800,
+		Height:       600,
+	}, &cameraConversionScene{})
+	initialize()
+
+	e := newMouseTestEntity(100, 100, 0)
+	sys := &MouseSystem{camera: cam}
+	sys.Add(&e.BasicEntity, &e.MouseComponent, &e.SpaceComponent, &e.RenderComponent)
+
+	p := cam.WorldToScreen(engo.Point{X: 105, Y: 105})
+	engo.Input.Mouse.X, engo.Input.Mouse.Y = p.X, p.Y
+	engo.Input.Mouse.Action = engo.Press
+	engo.Input.Mouse.Button = engo.MouseButtonLeft
+
+	// A HUD System updated before the MouseSystem handled the click
+	engo.Input.ConsumeMouse(engo.MouseButtonLeft)
+	sys.Update(0)
+	assert.True(t, e.Hovered, "The entity should still be hovered")
+	assert.False(t, e.Clicked, "A consumed click should not click the entity")
+
+	// Clicking the entity consumes the click for the Systems updated after it
+	engo.Input.Mouse.Button = engo.MouseButtonRight
+	sys.Update(0)
+	assert.True(t, e.RightClicked, "The entity should be clicked")
+	assert.True(t, engo.Input.MouseConsumed(engo.MouseButtonRight), "Clicking an entity should consume the click")
+
+	// Clicking next to the entity doesn't
+	p = cam.WorldToScreen(engo.Point{X: 50, Y: 50})
+	engo.Input.Mouse.X, engo.Input.Mouse.Y = p.X, p.Y
+	engo.Input.Mouse.Button = engo.MouseButtonMiddle
+	sys.Update(0)
+	assert.False(t, engo.Input.MouseConsumed(engo.MouseButtonMiddle), "Clicking next to an entity should not consume the click")
+}
+
+func TestMouseSystemIgnored(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+		Width:        800,
+		Height:       600,
+	}, &cameraConversionScene{})
+	initialize()
+
+	e := newMouseTestEntity(100, 100, 0)
+	sys := &MouseSystem{camera: cam}
+	sys.Add(&e.BasicEntity, &e.MouseComponent, &e.SpaceComponent, &e.RenderComponent)
+
+	p := cam.WorldToScreen(engo.Point{X: 105, Y: 105})
+	engo.Input.Mouse.X, engo.Input.Mouse.Y = p.X, p.Y
+	engo.Input.Mouse.Action = engo.Press
+	engo.Input.Mouse.Button = engo.MouseButtonLeft
+	sys.Update(0)
+	assert.True(t, e.Clicked)
+	assert.True(t, e.Enter)
+
+	engo.Input.SetIgnored(true)
+	defer engo.Input.SetIgnored(false)
+	sys.Update(0)
+	assert.False(t, e.Clicked, "Clicks should only last one frame while input is ignored")
+	assert.False(t, e.Enter, "Entering should only last one frame while input is ignored")
+	assert.True(t, e.Hovered, "The entity should stay hovered while input is ignored")
+}
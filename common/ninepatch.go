@@ -0,0 +1,106 @@
+package common
+
+import (
+	"github.com/klopsch/engo"
+	"github.com/klopsch/gl"
+)
+
+// NinePatch is a Drawable that splits a texture into nine regions, so that it can be scaled without stretching its
+// corners. The corners are always drawn at their original size, the edges are stretched along one axis, and the
+// center is stretched along both axes to fill the SpaceComponent of the entity.
+//
+// NinePatches are drawn by the DefaultShader and HUDShader.
+type NinePatch struct {
+	// Left, Right, Top and Bottom are the insets of the borders, in pixels of the source texture.
+	Left, Right, Top, Bottom float32
+
+	source Texture
+}
+
+// NewNinePatch creates a NinePatch from the texture, with the given border insets in pixels.
+func NewNinePatch(tex Texture, left, right, top, bottom float32) *NinePatch {
+	return &NinePatch{
+		Left:   left,
+		Right:  right,
+		Top:    top,
+		Bottom: bottom,
+		source: tex,
+	}
+}
+
+// Texture returns the OpenGL ID of the source texture.
+func (n *NinePatch) Texture() *gl.Texture {
+	return n.source.Texture()
+}
+
+// Width returns the width of the source texture. The NinePatch is drawn at the width of the SpaceComponent.
+func (n *NinePatch) Width() float32 {
+	return n.source.Width()
+}
+
+// Height returns the height of the source texture. The NinePatch is drawn at the height of the SpaceComponent.
+func (n *NinePatch) Height() float32 {
+	return n.source.Height()
+}
+
+// View returns the viewport properties of the source texture.
+func (n *NinePatch) View() (float32, float32, float32, float32) {
+	return n.source.View()
+}
+
+// Close removes the source texture data from the GPU.
+func (n *NinePatch) Close() {
+	n.source.Close()
+}
+
+// ninePatchRegion is one of the nine regions of a NinePatch, with its position and texture coordinates.
+type ninePatchRegion struct {
+	x, y, x2, y2 float32
+	u, v, u2, v2 float32
+}
+
+// regions splits a NinePatch of width w and height h into its nine regions, ordered from the top-left to the
+// bottom-right. When the size is smaller than the borders, the borders are shrunk proportionally and the regions in
+// between become empty.
+func (n *NinePatch) regions(w, h float32) [9]ninePatchRegion {
+	left, right := ninePatchBorders(n.Left, n.Right, w)
+	top, bottom := ninePatchBorders(n.Top, n.Bottom, h)
+
+	u, v, u2, v2 := n.source.View()
+	du := (u2 - u) / n.source.Width()
+	dv := (v2 - v) / n.source.Height()
+
+	xs := [4]float32{0, left, w - right, w}
+	ys := [4]float32{0, top, h - bottom, h}
+	us := [4]float32{u, u + n.Left*du, u2 - n.Right*du, u2}
+	vs := [4]float32{v, v + n.Top*dv, v2 - n.Bottom*dv, v2}
+
+	var r [9]ninePatchRegion
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			r[row*3+col] = ninePatchRegion{
+				x: xs[col], y: ys[row], x2: xs[col+1], y2: ys[row+1],
+				u: us[col], v: vs[row], u2: us[col+1], v2: vs[row+1],
+			}
+		}
+	}
+	return r
+}
+
+// ninePatchBorders returns the size of two opposing borders within the given size, shrinking them proportionally if
+// they don't fit.
+func ninePatchBorders(a, b, size float32) (float32, float32) {
+	if a+b <= size || a+b == 0 {
+		return a, b
+	}
+	if size < 0 {
+		size = 0
+	}
+	f := size / (a + b)
+	return a * f, b * f
+}
+
+// ninePatchSize returns the size a NinePatch is drawn at, before the Scale of the RenderComponent is applied.
+func ninePatchSize(ren *RenderComponent, space *SpaceComponent) engo.Point {
+	return engo.Point{X: space.Width / ren.Scale.X, Y: space.Height / ren.Scale.Y}
+}
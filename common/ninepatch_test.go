@@ -0,0 +1,43 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func testNinePatch() *NinePatch {
+	tex := Texture{width: 30, height: 30, viewport: engo.AABB{Max: engo.Point{X: 1, Y: 1}}}
+	return NewNinePatch(tex, 10, 10, 10, 10)
+}
+
+func assertNinePatchRegion(t *testing.T, expected, actual ninePatchRegion, msg string) {
+	assert.Equal(t, []float32{expected.x, expected.y, expected.x2, expected.y2}, []float32{actual.x, actual.y, actual.x2, actual.y2}, msg)
+	assert.InDeltaSlice(t, []float32{expected.u, expected.v, expected.u2, expected.v2}, []float32{actual.u, actual.v, actual.u2, actual.v2}, 1e-6, msg)
+}
+
+func TestNinePatchRegions(t *testing.T) {
+	r := testNinePatch().regions(100, 50)
+
+	assertNinePatchRegion(t, ninePatchRegion{x: 0, y: 0, x2: 10, y2: 10, u: 0, v: 0, u2: 1.0 / 3, v2: 1.0 / 3}, r[0], "The top-left corner should keep its size")
+	assertNinePatchRegion(t, ninePatchRegion{x: 10, y: 10, x2: 90, y2: 40, u: 1.0 / 3, v: 1.0 / 3, u2: 2.0 / 3, v2: 2.0 / 3}, r[4], "The center should stretch to fill the rest")
+	assertNinePatchRegion(t, ninePatchRegion{x: 90, y: 40, x2: 100, y2: 50, u: 2.0 / 3, v: 2.0 / 3, u2: 1, v2: 1}, r[8], "The bottom-right corner should keep its size")
+}
+
+func TestNinePatchRegionsSmallerThanBorders(t *testing.T) {
+	r := testNinePatch().regions(10, 4)
+
+	assert.Equal(t, float32(5), r[0].x2, "Borders that don't fit should shrink proportionally")
+	assert.Equal(t, float32(2), r[0].y2, "Borders that don't fit should shrink proportionally")
+	assert.Equal(t, r[4].x, r[4].x2, "The center should be empty when the borders don't fit")
+	assert.Equal(t, r[4].y, r[4].y2, "The center should be empty when the borders don't fit")
+	assert.Equal(t, float32(10), r[8].x2, "The NinePatch should not be larger than its size")
+	assert.Equal(t, float32(4), r[8].y2, "The NinePatch should not be larger than its size")
+
+	r = testNinePatch().regions(0, 0)
+	for i := range r {
+		assert.Equal(t, float32(0), r[i].x2, "A NinePatch without a size should be empty")
+		assert.Equal(t, float32(0), r[i].y2, "A NinePatch without a size should be empty")
+	}
+}
@@ -0,0 +1,147 @@
+package common
+
+import (
+	"log"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/klopsch/engo/math"
+)
+
+// ParallaxSystemPriority is the priority of the ParallaxSystem. It runs after
+// the CameraSystem has moved, and before the RenderSystem draws.
+const ParallaxSystemPriority = -900
+
+// ParallaxComponent makes an entity scroll at a different speed than the
+// camera, such as for backgrounds that appear far away.
+type ParallaxComponent struct {
+	// Factor is how much the entity moves with the world, per axis. A factor
+	// of 0 pins the entity to the camera, like a HUD, while a factor of 1 moves
+	// it with the world as if it weren't in the ParallaxSystem.
+	Factor engo.Point
+	// Anchor is the position of the camera at which the entity is drawn at its
+	// original position. If not set, the position of the camera when the
+	// entity is added is used.
+	Anchor engo.Point
+	// WrapX and WrapY repeat the texture of the entity infinitely along the
+	// axis, so it always covers the visible area of the camera. The Repeat of
+	// the RenderComponent is set to Repeat if it was NoRepeat.
+	WrapX, WrapY bool
+
+	origin engo.Point
+}
+
+type parallaxEntity struct {
+	*ecs.BasicEntity
+	*ParallaxComponent
+	*RenderComponent
+	*SpaceComponent
+}
+
+// ParallaxSystem moves the entities with a ParallaxComponent along with the
+// camera, according to their Factor. It needs a CameraSystem in the World,
+// which the RenderSystem adds if there isn't one.
+type ParallaxSystem struct {
+	entities []parallaxEntity
+	camera   *CameraSystem
+}
+
+// Priority implements the ecs.Prioritizer interface.
+func (*ParallaxSystem) Priority() int { return ParallaxSystemPriority }
+
+// New initializes the ParallaxSystem. It is run before any updates.
+func (p *ParallaxSystem) New(w *ecs.World) {
+	for _, system := range w.Systems() {
+		switch sys := system.(type) {
+		case *CameraSystem:
+			p.camera = sys
+		}
+	}
+
+	if p.camera == nil {
+		log.Println("ERROR: CameraSystem not found - have you added the `RenderSystem` before the `ParallaxSystem`?")
+	}
+}
+
+// Add starts tracking the given entity. The position of its SpaceComponent is
+// where it's drawn when the camera is at the Anchor.
+func (p *ParallaxSystem) Add(basic *ecs.BasicEntity, parallax *ParallaxComponent, render *RenderComponent, space *SpaceComponent) {
+	parallax.origin = space.Position
+	if parallax.Anchor == (engo.Point{}) && p.camera != nil {
+		parallax.Anchor = engo.Point{X: p.camera.X(), Y: p.camera.Y()}
+	}
+	if (parallax.WrapX || parallax.WrapY) && render.Repeat == NoRepeat {
+		render.Repeat = Repeat
+	}
+	p.entities = append(p.entities, parallaxEntity{basic, parallax, render, space})
+}
+
+// AddByInterface allows an Entity to be added directly using the Parallaxable
+// interface, which every entity containing the BasicEntity, ParallaxComponent,
+// RenderComponent and SpaceComponent anonymously automatically satisfies.
+func (p *ParallaxSystem) AddByInterface(i ecs.Identifier) {
+	o, _ := i.(Parallaxable)
+	p.Add(o.GetBasicEntity(), o.GetParallaxComponent(), o.GetRenderComponent(), o.GetSpaceComponent())
+}
+
+// Remove stops tracking the given entity.
+func (p *ParallaxSystem) Remove(basic ecs.BasicEntity) {
+	delete := -1
+	for index, e := range p.entities {
+		if e.BasicEntity.ID() == basic.ID() {
+			delete = index
+			break
+		}
+	}
+	if delete >= 0 {
+		p.entities = append(p.entities[:delete], p.entities[delete+1:]...)
+	}
+}
+
+// EntityCount returns the number of entities the ParallaxSystem holds.
+func (p *ParallaxSystem) EntityCount() int {
+	return len(p.entities)
+}
+
+// Update moves the entities according to the position of the camera.
+func (p *ParallaxSystem) Update(float32) {
+	if p.camera == nil {
+		return
+	}
+
+	cam := engo.Point{X: p.camera.X(), Y: p.camera.Y()}
+	scale := engo.GetGlobalScale()
+	viewW, viewH := viewSize()
+	viewW *= p.camera.Z() / scale.X
+	viewH *= p.camera.Z() / scale.Y
+
+	for _, e := range p.entities {
+		pos := engo.Point{
+			X: e.origin.X + (cam.X-e.Anchor.X)*(1-e.Factor.X),
+			Y: e.origin.Y + (cam.Y-e.Anchor.Y)*(1-e.Factor.Y),
+		}
+
+		if e.WrapX && e.Drawable != nil {
+			tile := e.Drawable.Width() * e.Scale.X
+			left := cam.X - viewW/2
+			pos.X = left - wrap(left-pos.X, tile)
+			e.SpaceComponent.Width = viewW + tile
+		}
+		if e.WrapY && e.Drawable != nil {
+			tile := e.Drawable.Height() * e.Scale.Y
+			top := cam.Y - viewH/2
+			pos.Y = top - wrap(top-pos.Y, tile)
+			e.SpaceComponent.Height = viewH + tile
+		}
+
+		e.SpaceComponent.Position = pos
+	}
+}
+
+// wrap returns v modulo size, in the range [0, size).
+func wrap(v, size float32) float32 {
+	if size <= 0 {
+		return 0
+	}
+	return v - size*math.Floor(v/size)
+}
@@ -0,0 +1,35 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallaxFactor(t *testing.T) {
+	cam := &CameraSystem{x: 100, y: 100, z: 1}
+	sys := &ParallaxSystem{camera: cam}
+
+	factors := []float32{0, 0.5, 1}
+	spaces := make([]*SpaceComponent, len(factors))
+	for i, f := range factors {
+		basic := ecs.NewBasic()
+		spaces[i] = &SpaceComponent{Position: engo.Point{X: 10, Y: 20}}
+		sys.Add(&basic, &ParallaxComponent{Factor: engo.Point{X: f, Y: f}}, &RenderComponent{}, spaces[i])
+	}
+
+	cam.x, cam.y = 200, 140
+	sys.Update(0)
+	assert.Equal(t, engo.Point{X: 110, Y: 60}, spaces[0].Position, "A factor of 0 should move with the camera")
+	assert.Equal(t, engo.Point{X: 60, Y: 40}, spaces[1].Position, "A factor of 0.5 should move at half the speed of the camera")
+	assert.Equal(t, engo.Point{X: 10, Y: 20}, spaces[2].Position, "A factor of 1 should stay in place in the world")
+}
+
+func TestParallaxWrap(t *testing.T) {
+	assert.Equal(t, float32(5), wrap(25, 10))
+	assert.Equal(t, float32(5), wrap(-5, 10))
+	assert.Equal(t, float32(0), wrap(-20, 10))
+	assert.Equal(t, float32(0), wrap(3, 0))
+}
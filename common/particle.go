@@ -0,0 +1,258 @@
+package common
+
+import (
+	"image/color"
+	"log"
+	"math/rand"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/klopsch/engo/math"
+)
+
+// DefaultMaxParticles is the amount of particles an emitter can have alive at
+// once, if its MaxParticles is not set.
+const DefaultMaxParticles = 100
+
+// ParticleComponent describes an emitter of particles, such as smoke, sparks
+// or rain. Particles are emitted continuously at Rate, and in bursts with
+// Burst.
+type ParticleComponent struct {
+	// Drawable is drawn for every particle. When nil, particles are solid
+	// squares of their color.
+	Drawable Drawable
+	// Rate is how many particles are emitted per second. A Rate of 0 only
+	// emits particles in bursts.
+	Rate float32
+	// Lifetime is how long particles live, in seconds.
+	Lifetime float32
+	// StartColor and EndColor are the colors of a particle when it's emitted
+	// and when it dies. StartColor defaults to white, and EndColor to the
+	// StartColor.
+	StartColor, EndColor color.Color
+	// StartSize and EndSize are the width and height of a particle when it's
+	// emitted and when it dies.
+	StartSize, EndSize float32
+	// Speed is the initial speed of particles, in units per second.
+	Speed float32
+	// Direction is the angle, in degrees, particles are emitted at, and Spread
+	// the range of angles around it particles are randomly emitted at.
+	Direction, Spread float32
+	// Gravity is the acceleration of particles, in units per second squared.
+	Gravity engo.Point
+	// Offset is where particles are emitted, relative to the center of the
+	// emitter's SpaceComponent.
+	Offset engo.Point
+	// MaxParticles is the amount of particles which can be alive at once.
+	// Particles are not emitted while the pool is full. Defaults to
+	// DefaultMaxParticles.
+	MaxParticles int
+	// ZIndex is the Z-Index the particles are drawn at.
+	ZIndex float32
+
+	particles []particle
+	burst     int
+	emit      float32 // particles to be emitted, carried over between updates
+}
+
+type particle struct {
+	ecs.BasicEntity
+	RenderComponent
+	SpaceComponent
+
+	position, velocity engo.Point
+	age                float32
+	alive              bool
+}
+
+// Burst emits n particles at once on the next update.
+func (pc *ParticleComponent) Burst(n int) {
+	pc.burst += n
+}
+
+// Alive returns the amount of particles currently alive.
+func (pc *ParticleComponent) Alive() int {
+	alive := 0
+	for i := range pc.particles {
+		if pc.particles[i].alive {
+			alive++
+		}
+	}
+	return alive
+}
+
+func (pc *ParticleComponent) maxParticles() int {
+	if pc.MaxParticles <= 0 {
+		return DefaultMaxParticles
+	}
+	return pc.MaxParticles
+}
+
+// spawn emits a particle at the given position, and returns false if the pool
+// is full.
+func (pc *ParticleComponent) spawn(at engo.Point) bool {
+	for i := range pc.particles {
+		p := &pc.particles[i]
+		if p.alive {
+			continue
+		}
+		angle := (pc.Direction + (rand.Float32()-0.5)*pc.Spread) * math.Pi / 180
+		sin, cos := math.Sincos(angle)
+		p.position = at
+		p.velocity = engo.Point{X: cos * pc.Speed, Y: sin * pc.Speed}
+		p.age = 0
+		p.alive = true
+		return true
+	}
+	return false
+}
+
+// step advances the particle by dt seconds, and updates how it's drawn.
+func (pc *ParticleComponent) step(p *particle, dt float32) {
+	p.age += dt
+	if p.age >= pc.Lifetime {
+		p.alive = false
+		p.RenderComponent.Hidden = true
+		return
+	}
+	p.velocity.X += pc.Gravity.X * dt
+	p.velocity.Y += pc.Gravity.Y * dt
+	p.position.X += p.velocity.X * dt
+	p.position.Y += p.velocity.Y * dt
+
+	t := p.age / pc.Lifetime
+	start := pc.StartColor
+	if start == nil {
+		start = color.White
+	}
+	end := pc.EndColor
+	if end == nil {
+		end = start
+	}
+	size := math.Lerp(pc.StartSize, pc.EndSize, t)
+
+	p.RenderComponent.Hidden = false
+	p.RenderComponent.Color = LerpColor(start, end, t)
+	if d := p.RenderComponent.Drawable; d != nil && d.Width() > 0 && d.Height() > 0 {
+		p.RenderComponent.Scale = engo.Point{X: size / d.Width(), Y: size / d.Height()}
+	}
+	p.SpaceComponent.Width, p.SpaceComponent.Height = size, size
+	p.SpaceComponent.Position = engo.Point{X: p.position.X - size/2, Y: p.position.Y - size/2}
+}
+
+type particleEntity struct {
+	*ecs.BasicEntity
+	*ParticleComponent
+	*SpaceComponent
+}
+
+// ParticleSystem emits and moves the particles of every ParticleComponent.
+// The particles of an emitter are a pool of entities which are added to the
+// RenderSystem, so they're drawn in batches like any other sprite.
+type ParticleSystem struct {
+	entities []particleEntity
+	render   *RenderSystem
+}
+
+// New initializes the ParticleSystem. It is run before any updates.
+func (ps *ParticleSystem) New(w *ecs.World) {
+	for _, system := range w.Systems() {
+		switch sys := system.(type) {
+		case *RenderSystem:
+			ps.render = sys
+		}
+	}
+
+	if ps.render == nil {
+		log.Println("ERROR: RenderSystem not found - have you added the `RenderSystem` before the `ParticleSystem`?")
+	}
+}
+
+// Add starts emitting particles for the given entity. The pool of particles is
+// allocated and added to the RenderSystem right away.
+func (ps *ParticleSystem) Add(basic *ecs.BasicEntity, particles *ParticleComponent, space *SpaceComponent) {
+	d := particles.Drawable
+	if d == nil && ps.render != nil && !engo.Headless() {
+		d = solidTexture()
+	}
+
+	particles.particles = make([]particle, particles.maxParticles())
+	for i := range particles.particles {
+		p := &particles.particles[i]
+		p.BasicEntity = ecs.NewBasic()
+		p.RenderComponent = RenderComponent{
+			Drawable:    d,
+			Hidden:      true,
+			StartZIndex: particles.ZIndex,
+		}
+		if ps.render != nil {
+			ps.render.Add(&p.BasicEntity, &p.RenderComponent, &p.SpaceComponent)
+		}
+	}
+	ps.entities = append(ps.entities, particleEntity{basic, particles, space})
+}
+
+// AddByInterface allows an Entity to be added directly using the Particleable
+// interface, which every entity containing the BasicEntity, ParticleComponent
+// and SpaceComponent anonymously automatically satisfies.
+func (ps *ParticleSystem) AddByInterface(i ecs.Identifier) {
+	o, _ := i.(Particleable)
+	ps.Add(o.GetBasicEntity(), o.GetParticleComponent(), o.GetSpaceComponent())
+}
+
+// Remove stops emitting particles for the given entity, and removes its
+// particles from the RenderSystem.
+func (ps *ParticleSystem) Remove(basic ecs.BasicEntity) {
+	delete := -1
+	for index, e := range ps.entities {
+		if e.BasicEntity.ID() == basic.ID() {
+			delete = index
+			break
+		}
+	}
+	if delete < 0 {
+		return
+	}
+	if ps.render != nil {
+		for _, p := range ps.entities[delete].particles {
+			ps.render.Remove(p.BasicEntity)
+		}
+	}
+	ps.entities[delete].particles = nil
+	ps.entities = append(ps.entities[:delete], ps.entities[delete+1:]...)
+}
+
+// EntityCount returns the number of entities the ParticleSystem holds.
+func (ps *ParticleSystem) EntityCount() int {
+	return len(ps.entities)
+}
+
+// Update emits new particles and moves the living ones.
+func (ps *ParticleSystem) Update(dt float32) {
+	for _, e := range ps.entities {
+		for i := range e.particles {
+			if e.particles[i].alive {
+				e.step(&e.particles[i], dt)
+			}
+		}
+
+		e.emit += e.Rate * dt
+		n := int(e.emit)
+		e.emit -= float32(n)
+		n += e.burst
+		e.burst = 0
+
+		at := e.SpaceComponent.Center()
+		at.Add(e.Offset)
+		for ; n > 0; n-- {
+			if !e.spawn(at) {
+				break
+			}
+		}
+		for i := range e.particles {
+			if p := &e.particles[i]; p.alive && p.age == 0 {
+				e.step(p, 0)
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package common
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParticleSystemEmission(t *testing.T) {
+	basic := ecs.NewBasic()
+	pc := &ParticleComponent{
+		Rate:         10,
+		Lifetime:     1,
+		MaxParticles: 5,
+	}
+	sys := &ParticleSystem{}
+	sys.Add(&basic, pc, &SpaceComponent{})
+	assert.Len(t, pc.particles, 5, "The pool should be allocated once")
+
+	sys.Update(0.25)
+	assert.Equal(t, 2, pc.Alive(), "2.5 particles should be emitted, carrying the half over")
+	sys.Update(0.05)
+	assert.Equal(t, 3, pc.Alive())
+
+	pc.Burst(10)
+	sys.Update(0)
+	assert.Equal(t, 5, pc.Alive(), "No more particles than MaxParticles should be alive")
+
+	pc.Rate = 0
+	sys.Update(1)
+	assert.Equal(t, 0, pc.Alive(), "Particles should die after their Lifetime")
+}
+
+func TestParticleSystemStep(t *testing.T) {
+	basic := ecs.NewBasic()
+	pc := &ParticleComponent{
+		Lifetime:   2,
+		StartColor: color.NRGBA{R: 255, A: 255},
+		EndColor:   color.NRGBA{B: 255, A: 255},
+		StartSize:  4,
+		EndSize:    8,
+		Speed:      10,
+		Gravity:    engo.Point{Y: 10},
+		Offset:     engo.Point{X: 5},
+	}
+	sys := &ParticleSystem{}
+	sys.Add(&basic, pc, &SpaceComponent{Position: engo.Point{X: 10, Y: 10}, Width: 10, Height: 10})
+
+	pc.Burst(1)
+	sys.Update(0)
+	p := &pc.particles[0]
+	assert.True(t, p.alive)
+	assert.False(t, p.RenderComponent.Hidden, "Emitted particles should be drawn")
+	assert.Equal(t, engo.Point{X: 18, Y: 13}, p.SpaceComponent.Position, "Particles should be emitted at the center of the emitter plus the offset")
+
+	sys.Update(1)
+	assert.InDelta(t, 30, p.position.X, 1e-4)
+	assert.InDelta(t, 25, p.position.Y, 1e-4, "Gravity should accelerate the particle")
+	assert.Equal(t, float32(6), p.SpaceComponent.Width)
+	assert.Equal(t, color.RGBA64{R: 0x8000, B: 0x8000, A: 0xffff}, p.RenderComponent.Color)
+
+	sys.Update(1)
+	assert.False(t, p.alive)
+	assert.True(t, p.RenderComponent.Hidden, "Dead particles should not be drawn")
+}
@@ -0,0 +1,184 @@
+package pathfinding
+
+import (
+	"container/heap"
+	"errors"
+	"image"
+
+	"github.com/klopsch/engo"
+	"github.com/klopsch/engo/math"
+)
+
+var (
+	// ErrOutOfBounds is returned by FindPath when the start or the target is
+	// outside of the grid.
+	ErrOutOfBounds = errors.New("pathfinding: tile is outside of the grid")
+	// ErrUnreachable is returned by FindPath when the start or the target isn't
+	// walkable, or when no walkable path connects them.
+	ErrUnreachable = errors.New("pathfinding: target is unreachable")
+)
+
+// FindPath returns the cheapest path from the tile from to the tile to, as the
+// world positions of the centers of the tiles along it, including both ends.
+// When there's no path it returns nil, along with ErrOutOfBounds or
+// ErrUnreachable.
+func (g *Grid) FindPath(from, to image.Point) ([]engo.Point, error) {
+	tiles, err := g.FindTiles(from, to)
+	if err != nil {
+		return nil, err
+	}
+	path := make([]engo.Point, len(tiles))
+	for i, t := range tiles {
+		path[i] = g.TileCenter(t.X, t.Y)
+	}
+	return path, nil
+}
+
+// FindTiles is like FindPath, but returns the tiles along the path.
+func (g *Grid) FindTiles(from, to image.Point) ([]image.Point, error) {
+	if !g.Contains(from.X, from.Y) || !g.Contains(to.X, to.Y) {
+		return nil, ErrOutOfBounds
+	}
+	if !g.Walkable(from.X, from.Y) || !g.Walkable(to.X, to.Y) {
+		return nil, ErrUnreachable
+	}
+
+	// The heuristic assumes every remaining tile is as cheap as the cheapest
+	// one, so it never overestimates and the path found is the cheapest.
+	minCost := float32(0)
+	for _, c := range g.costs {
+		if c > 0 && (minCost == 0 || c < minCost) {
+			minCost = c
+		}
+	}
+
+	start, goal := g.index(from), g.index(to)
+	nodes := make(map[int]*node)
+	open := &openList{}
+	nodes[start] = &node{index: start, estimate: g.heuristic(from, to) * minCost}
+	heap.Push(open, nodes[start])
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(*node)
+		if current.index == goal {
+			return g.tiles(nodes, current), nil
+		}
+		current.closed = true
+
+		p := g.point(current.index)
+		for _, d := range g.directions() {
+			n := p.Add(d)
+			if !g.Walkable(n.X, n.Y) {
+				continue
+			}
+			step := g.Cost(n.X, n.Y)
+			if d.X != 0 && d.Y != 0 {
+				if !g.Walkable(p.X+d.X, p.Y) || !g.Walkable(p.X, p.Y+d.Y) {
+					continue
+				}
+				step *= math.Sqrt2
+			}
+
+			cost := current.cost + step
+			i := g.index(n)
+			next, seen := nodes[i]
+			if seen && (next.closed || cost >= next.cost) {
+				continue
+			}
+			if !seen {
+				next = &node{index: i}
+				nodes[i] = next
+			}
+			next.parent = current
+			next.cost = cost
+			next.estimate = cost + g.heuristic(n, to)*minCost
+			if seen {
+				heap.Fix(open, next.heapIndex)
+			} else {
+				heap.Push(open, next)
+			}
+		}
+	}
+	return nil, ErrUnreachable
+}
+
+var (
+	straight = []image.Point{{X: 1}, {X: -1}, {Y: 1}, {Y: -1}}
+	diagonal = append(straight[:4:4], image.Point{X: 1, Y: 1}, image.Point{X: 1, Y: -1}, image.Point{X: -1, Y: 1}, image.Point{X: -1, Y: -1})
+)
+
+func (g *Grid) directions() []image.Point {
+	if g.Diagonal {
+		return diagonal
+	}
+	return straight
+}
+
+// heuristic is the number of steps between the tiles if they were all
+// walkable: the manhattan distance for 4-connected grids, and the octile
+// distance for 8-connected ones.
+func (g *Grid) heuristic(a, b image.Point) float32 {
+	dx, dy := math.Abs(float32(a.X-b.X)), math.Abs(float32(a.Y-b.Y))
+	if !g.Diagonal {
+		return dx + dy
+	}
+	return math.Max(dx, dy) + (math.Sqrt2-1)*math.Min(dx, dy)
+}
+
+func (g *Grid) index(p image.Point) int {
+	return p.Y*g.width + p.X
+}
+
+func (g *Grid) point(i int) image.Point {
+	return image.Point{X: i % g.width, Y: i / g.width}
+}
+
+// tiles walks back from the last node to the start of the path.
+func (g *Grid) tiles(nodes map[int]*node, last *node) []image.Point {
+	var path []image.Point
+	for n := last; n != nil; n = n.parent {
+		path = append(path, g.point(n.index))
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// node is a tile visited by the search.
+type node struct {
+	index    int
+	parent   *node
+	cost     float32
+	estimate float32
+	closed   bool
+	// heapIndex is the position of the node in the openList, or -1 once it's
+	// been removed from it
+	heapIndex int
+}
+
+// openList is a priority queue of the nodes to visit, cheapest estimate first.
+type openList []*node
+
+func (o openList) Len() int           { return len(o) }
+func (o openList) Less(i, j int) bool { return o[i].estimate < o[j].estimate }
+
+func (o openList) Swap(i, j int) {
+	o[i], o[j] = o[j], o[i]
+	o[i].heapIndex = i
+	o[j].heapIndex = j
+}
+
+func (o *openList) Push(x interface{}) {
+	n := x.(*node)
+	n.heapIndex = len(*o)
+	*o = append(*o, n)
+}
+
+func (o *openList) Pop() interface{} {
+	old := *o
+	n := old[len(old)-1]
+	n.heapIndex = -1
+	*o = old[:len(old)-1]
+	return n
+}
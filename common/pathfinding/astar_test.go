@@ -0,0 +1,149 @@
+package pathfinding
+
+import (
+	"image"
+	"testing"
+
+	"github.com/klopsch/engo"
+)
+
+// parseGrid creates a Grid with 10x10 tiles from rows of text, where '#' isn't
+// walkable, a digit is a tile with that cost, and anything else costs 1.
+func parseGrid(rows ...string) *Grid {
+	g := NewGrid(len(rows[0]), len(rows), 10, 10)
+	for y, row := range rows {
+		for x, c := range row {
+			switch {
+			case c == '#':
+				g.SetWalkable(x, y, false)
+			case c >= '0' && c <= '9':
+				g.SetCost(x, y, float32(c-'0'))
+			}
+		}
+	}
+	return g
+}
+
+func TestFindTilesStraight(t *testing.T) {
+	g := parseGrid(
+		".....",
+		".###.",
+		".#...",
+		".#.#.",
+		"#..#.",
+	)
+	tiles, err := g.FindTiles(image.Pt(2, 2), image.Pt(0, 0))
+	if err != nil {
+		t.Fatalf("path should be found, got error %v", err)
+	}
+	expected := []image.Point{{2, 2}, {3, 2}, {4, 2}, {4, 1}, {4, 0}, {3, 0}, {2, 0}, {1, 0}, {0, 0}}
+	if len(tiles) != len(expected) {
+		t.Fatalf("wrong path expected=%v ; got=%v", expected, tiles)
+	}
+	for i := range expected {
+		if tiles[i] != expected[i] {
+			t.Errorf("wrong path expected=%v ; got=%v", expected, tiles)
+			break
+		}
+	}
+}
+
+func TestFindTilesDiagonal(t *testing.T) {
+	g := parseGrid(
+		"....",
+		"....",
+		"....",
+		"....",
+	)
+	g.Diagonal = true
+	tiles, err := g.FindTiles(image.Pt(0, 0), image.Pt(3, 3))
+	if err != nil {
+		t.Fatalf("path should be found, got error %v", err)
+	}
+	if len(tiles) != 4 {
+		t.Errorf("diagonal path should take 4 tiles, got=%v", tiles)
+	}
+
+	// Diagonal moves can't squeeze between two blocked tiles
+	g = parseGrid(
+		".#",
+		"#.",
+	)
+	g.Diagonal = true
+	if tiles, err := g.FindTiles(image.Pt(0, 0), image.Pt(1, 1)); err != ErrUnreachable || tiles != nil {
+		t.Errorf("corners should not be cut expected=%v ; got=%v, %v", ErrUnreachable, tiles, err)
+	}
+}
+
+func TestFindTilesCost(t *testing.T) {
+	g := parseGrid(
+		".....",
+		".999.",
+		".....",
+	)
+	tiles, err := g.FindTiles(image.Pt(0, 1), image.Pt(4, 1))
+	if err != nil {
+		t.Fatalf("path should be found, got error %v", err)
+	}
+	if len(tiles) != 7 {
+		t.Errorf("expensive tiles should be avoided, got=%v", tiles)
+	}
+	for _, tile := range tiles {
+		if tile.Y == 1 && tile.X > 0 && tile.X < 4 {
+			t.Errorf("expensive tile %v should be avoided, got=%v", tile, tiles)
+		}
+	}
+
+	// Going through is cheaper than a long way around
+	g = parseGrid(
+		"22222",
+		".222.",
+		"22222",
+	)
+	tiles, _ = g.FindTiles(image.Pt(0, 1), image.Pt(4, 1))
+	if len(tiles) != 5 {
+		t.Errorf("cheap enough tiles should be crossed, got=%v", tiles)
+	}
+}
+
+func TestFindPathUnreachable(t *testing.T) {
+	g := parseGrid(
+		"..#..",
+		"..#..",
+		"..#..",
+	)
+	if path, err := g.FindPath(image.Pt(0, 0), image.Pt(4, 0)); err != ErrUnreachable || path != nil {
+		t.Errorf("walled off target expected=%v ; got=%v, %v", ErrUnreachable, path, err)
+	}
+	if path, err := g.FindPath(image.Pt(0, 0), image.Pt(2, 0)); err != ErrUnreachable || path != nil {
+		t.Errorf("blocked target expected=%v ; got=%v, %v", ErrUnreachable, path, err)
+	}
+	if path, err := g.FindPath(image.Pt(0, 0), image.Pt(5, 0)); err != ErrOutOfBounds || path != nil {
+		t.Errorf("target outside of the grid expected=%v ; got=%v, %v", ErrOutOfBounds, path, err)
+	}
+}
+
+func TestFindPathWorld(t *testing.T) {
+	g := parseGrid(
+		"...",
+	)
+	g.Origin = engo.Point{X: 100, Y: 50}
+	path, err := g.FindPath(g.TileAt(engo.Point{X: 101, Y: 59}), g.TileAt(engo.Point{X: 129, Y: 50}))
+	if err != nil {
+		t.Fatalf("path should be found, got error %v", err)
+	}
+	expected := []engo.Point{{X: 105, Y: 55}, {X: 115, Y: 55}, {X: 125, Y: 55}}
+	if len(path) != len(expected) {
+		t.Fatalf("wrong path expected=%v ; got=%v", expected, path)
+	}
+	for i := range expected {
+		if path[i] != expected[i] {
+			t.Errorf("wrong path expected=%v ; got=%v", expected, path)
+			break
+		}
+	}
+
+	if tile := g.TileAt(engo.Point{X: 99, Y: 49}); tile != image.Pt(-1, -1) {
+		t.Errorf("points before the origin should be in negative tiles expected=%v ; got=%v", image.Pt(-1, -1), tile)
+	}
+}
@@ -0,0 +1,128 @@
+// Package pathfinding finds paths through tile grids, such as the levels loaded
+// by the common package, using A*.
+package pathfinding
+
+import (
+	"image"
+
+	"github.com/klopsch/engo"
+	"github.com/klopsch/engo/common"
+	"github.com/klopsch/engo/math"
+)
+
+// Grid is a rectangle of tiles with a movement cost each, placed in the world.
+// Tiles are addressed by their column and row, starting at 0, 0 in the top
+// left corner.
+type Grid struct {
+	// Origin is the world position of the top left corner of the grid
+	Origin engo.Point
+	// TileWidth and TileHeight are the size of every tile in the world
+	TileWidth, TileHeight float32
+	// Diagonal allows paths to move diagonally between tiles, making the tiles
+	// 8-connected instead of 4-connected. Paths never cut the corner of a tile
+	// that isn't walkable.
+	Diagonal bool
+
+	width, height int
+	costs         []float32
+}
+
+// NewGrid creates a width by height Grid of tiles that are all walkable with a
+// cost of 1.
+func NewGrid(width, height int, tileWidth, tileHeight float32) *Grid {
+	g := &Grid{
+		TileWidth:  tileWidth,
+		TileHeight: tileHeight,
+		width:      width,
+		height:     height,
+		costs:      make([]float32, width*height),
+	}
+	for i := range g.costs {
+		g.costs[i] = 1
+	}
+	return g
+}
+
+// NewGridFromLevel creates a Grid covering the level, where every tile whose
+// tileset sets the given bool property (such as "solid") to true isn't
+// walkable. Only orthogonal levels are supported, other orientations return
+// nil.
+func NewGridFromLevel(level *common.Level, property string) *Grid {
+	walkable := level.Walkable(property)
+	if walkable == nil {
+		return nil
+	}
+
+	g := NewGrid(level.Width(), level.Height(), float32(level.TileWidth), float32(level.TileHeight))
+	g.Origin = level.Bounds().Min
+	for y, row := range walkable {
+		for x, w := range row {
+			g.SetWalkable(x, y, w)
+		}
+	}
+	return g
+}
+
+// Width returns the number of columns of the grid.
+func (g *Grid) Width() int {
+	return g.width
+}
+
+// Height returns the number of rows of the grid.
+func (g *Grid) Height() int {
+	return g.height
+}
+
+// Contains reports whether the tile at x, y is part of the grid.
+func (g *Grid) Contains(x, y int) bool {
+	return x >= 0 && y >= 0 && x < g.width && y < g.height
+}
+
+// SetCost sets the cost of moving onto the tile at x, y, such as to have paths
+// avoid swamps. A cost of 0 or less means the tile isn't walkable. Tiles
+// outside of the grid are ignored.
+func (g *Grid) SetCost(x, y int, cost float32) {
+	if g.Contains(x, y) {
+		g.costs[y*g.width+x] = cost
+	}
+}
+
+// Cost returns the cost of moving onto the tile at x, y. It's 0 for tiles
+// outside of the grid.
+func (g *Grid) Cost(x, y int) float32 {
+	if !g.Contains(x, y) {
+		return 0
+	}
+	return g.costs[y*g.width+x]
+}
+
+// SetWalkable makes the tile at x, y walkable with a cost of 1, or blocks it.
+func (g *Grid) SetWalkable(x, y int, walkable bool) {
+	if walkable {
+		g.SetCost(x, y, 1)
+	} else {
+		g.SetCost(x, y, 0)
+	}
+}
+
+// Walkable reports whether the tile at x, y can be moved onto.
+func (g *Grid) Walkable(x, y int) bool {
+	return g.Cost(x, y) > 0
+}
+
+// TileAt returns the tile containing the world point. It may be outside of the
+// grid.
+func (g *Grid) TileAt(pt engo.Point) image.Point {
+	return image.Point{
+		X: int(math.Floor((pt.X - g.Origin.X) / g.TileWidth)),
+		Y: int(math.Floor((pt.Y - g.Origin.Y) / g.TileHeight)),
+	}
+}
+
+// TileCenter returns the world point at the center of the tile at x, y.
+func (g *Grid) TileCenter(x, y int) engo.Point {
+	return engo.Point{
+		X: g.Origin.X + (float32(x)+0.5)*g.TileWidth,
+		Y: g.Origin.Y + (float32(y)+0.5)*g.TileHeight,
+	}
+}
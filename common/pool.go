@@ -0,0 +1,73 @@
+package common
+
+import "github.com/klopsch/ecs"
+
+// EntityPool reuses entities which are frequently spawned and removed, such as bullets, particles and enemies, so
+// they're not allocated and garbage collected every time. Entities are taken from the pool with Get, which adds them
+// to the World, and given back with Put, which removes them from it.
+//
+// T is usually a pointer to the struct of the entity, such as *Bullet, so that its components are reused as well.
+type EntityPool[T BasicFace] struct {
+	world    *ecs.World
+	newFunc  func() T
+	reset    func(T)
+	free     []T
+	acquired map[uint64]struct{}
+}
+
+// NewEntityPool creates a pool of entities for the World, and allocates size entities up front with newFunc. When
+// more entities are needed than the pool holds, newFunc is called again and the pool grows. reset is called on every
+// entity Get hands out, before it's added to the World, to reset its components to their initial values; it may be
+// nil.
+func NewEntityPool[T BasicFace](w *ecs.World, size int, newFunc func() T, reset func(T)) *EntityPool[T] {
+	p := &EntityPool[T]{
+		world:    w,
+		newFunc:  newFunc,
+		reset:    reset,
+		free:     make([]T, 0, size),
+		acquired: make(map[uint64]struct{}, size),
+	}
+	for i := 0; i < size; i++ {
+		p.free = append(p.free, newFunc())
+	}
+	return p
+}
+
+// Get takes an entity from the pool, resets it and adds it to the Systems of the World.
+func (p *EntityPool[T]) Get() T {
+	var e T
+	if n := len(p.free); n > 0 {
+		e = p.free[n-1]
+		p.free = p.free[:n-1]
+	} else {
+		e = p.newFunc()
+	}
+	if p.reset != nil {
+		p.reset(e)
+	}
+	SetEntityEnabled(p.world, e.GetBasicEntity(), true)
+	p.acquired[e.ID()] = struct{}{}
+	p.world.AddEntity(e)
+	return e
+}
+
+// Put removes an entity taken with Get from the Systems of the World and gives it back to the pool. Putting back an
+// entity which isn't taken from the pool, or which is already back, does nothing.
+func (p *EntityPool[T]) Put(e T) {
+	if _, ok := p.acquired[e.ID()]; !ok {
+		return
+	}
+	delete(p.acquired, e.ID())
+	p.world.RemoveEntity(*e.GetBasicEntity())
+	p.free = append(p.free, e)
+}
+
+// Free returns the number of entities in the pool, ready to be handed out by Get without allocating.
+func (p *EntityPool[T]) Free() int {
+	return len(p.free)
+}
+
+// InUse returns the number of entities taken with Get which aren't back in the pool.
+func (p *EntityPool[T]) InUse() int {
+	return len(p.acquired)
+}
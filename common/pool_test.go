@@ -0,0 +1,89 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+type pooledBullet struct {
+	ecs.BasicEntity
+	CollisionComponent
+	SpaceComponent
+}
+
+func newPooledBullet() *pooledBullet {
+	return &pooledBullet{
+		BasicEntity:        ecs.NewBasic(),
+		CollisionComponent: CollisionComponent{Main: 1},
+		SpaceComponent:     SpaceComponent{Width: 4, Height: 4},
+	}
+}
+
+func resetPooledBullet(b *pooledBullet) {
+	b.SpaceComponent.Position = engo.Point{}
+	b.CollisionComponent.Collides = 0
+}
+
+func newPoolWorld() (*ecs.World, *CollisionSystem) {
+	w := &ecs.World{}
+	sys := &CollisionSystem{}
+	var c *Collisionable
+	var notc *NotCollisionable
+	w.AddSystemInterface(sys, c, notc)
+	return w, sys
+}
+
+func TestEntityPool(t *testing.T) {
+	w, sys := newPoolWorld()
+	pool := NewEntityPool(w, 2, newPooledBullet, resetPooledBullet)
+	assert.Equal(t, 2, pool.Free(), "Entities should be allocated up front")
+
+	b := pool.Get()
+	assert.Equal(t, 1, pool.Free())
+	assert.Equal(t, 1, pool.InUse())
+	assert.Equal(t, 1, sys.EntityCount(), "Get should add the entity to the systems")
+
+	b.Position = engo.Point{X: 10, Y: 20}
+	SetEntityEnabled(w, &b.BasicEntity, false)
+	pool.Put(b)
+	assert.Equal(t, 2, pool.Free())
+	assert.Equal(t, 0, pool.InUse())
+	assert.Equal(t, 0, sys.EntityCount(), "Put should remove the entity from the systems")
+
+	pool.Put(b)
+	assert.Equal(t, 2, pool.Free(), "Putting an entity back twice should do nothing")
+
+	again := pool.Get()
+	assert.True(t, again == b, "Entities put back should be reused")
+	assert.Equal(t, engo.Point{}, again.Position, "Reused entities should be reset")
+	assert.True(t, EntityEnabled(w, &again.BasicEntity), "Reused entities should be enabled")
+
+	pool.Get()
+	pool.Get()
+	assert.Equal(t, 0, pool.Free())
+	assert.Equal(t, 3, pool.InUse(), "The pool should grow when it's empty")
+	assert.Equal(t, 3, sys.EntityCount())
+}
+
+func BenchmarkEntityAllocation(b *testing.B) {
+	w, _ := newPoolWorld()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		bullet := newPooledBullet()
+		w.AddEntity(bullet)
+		w.RemoveEntity(bullet.BasicEntity)
+	}
+}
+
+func BenchmarkEntityPool(b *testing.B) {
+	w, _ := newPoolWorld()
+	pool := NewEntityPool(w, 1, newPooledBullet, resetPooledBullet)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pool.Put(pool.Get())
+	}
+}
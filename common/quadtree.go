@@ -0,0 +1,156 @@
+package common
+
+import (
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/klopsch/engo/math"
+)
+
+// QuadtreeSystemPriority is the priority of the QuadtreeSystem. It runs after the InterpolationSystem has moved the
+// networked entities, and before the MouseSystem, the camera systems and the ScriptSystem, so they query the positions
+// of the current frame.
+const QuadtreeSystemPriority = 150
+
+// quadtreeEntity is an entity in the quadtree, which finds it by the AABB of its SpaceComponent.
+type quadtreeEntity struct {
+	*ecs.BasicEntity
+	*SpaceComponent
+	// aabb is the AABB of the SpaceComponent when it was put in the quadtree, so it's removed from where it was put
+	aabb engo.AABB
+}
+
+// AABB implements the engo.AABBer interface.
+func (e *quadtreeEntity) AABB() engo.AABB {
+	return e.aabb
+}
+
+// QuadtreeSystem finds the entities near a point or within a rectangle, such as for an area of effect, a minimap or
+// a selection box, faster than testing every entity. It puts the SpaceComponents of its entities in an
+// engo.Quadtree every frame, so queries find the entities where they were when the QuadtreeSystem was updated;
+// entities added since aren't found until the next Update. Disabled entities aren't found.
+type QuadtreeSystem struct {
+	// Bounds is the area the quadtree is split over. Entities outside of it are still found, but slower. It defaults
+	// to the area covering all entities, computed every frame.
+	Bounds engo.AABB
+	// MaxObjects is how many entities a node of the quadtree holds before it's split into four. It defaults to 8.
+	MaxObjects int
+	// MaxLevels is how many times the quadtree may be split. It defaults to splitting until the nodes are as small as
+	// a hundredth of a unit.
+	MaxLevels int
+
+	entities []*quadtreeEntity
+	tree     *engo.Quadtree
+	states   *entityStates
+}
+
+// Priority implements the ecs.Prioritizer interface.
+func (*QuadtreeSystem) Priority() int { return QuadtreeSystemPriority }
+
+// New initializes the QuadtreeSystem.
+func (q *QuadtreeSystem) New(w *ecs.World) {
+	q.states = worldEntityStates(w)
+}
+
+// Add starts tracking the given entity.
+func (q *QuadtreeSystem) Add(basic *ecs.BasicEntity, space *SpaceComponent) {
+	q.entities = append(q.entities, &quadtreeEntity{BasicEntity: basic, SpaceComponent: space})
+}
+
+// AddByInterface allows an Entity to be added directly using the Quadtreeable interface, which every entity
+// containing the BasicEntity and SpaceComponent anonymously automatically satisfies.
+func (q *QuadtreeSystem) AddByInterface(i ecs.Identifier) {
+	o, _ := i.(Quadtreeable)
+	q.Add(o.GetBasicEntity(), o.GetSpaceComponent())
+}
+
+// Remove stops tracking the given entity. It isn't found by queries anymore, even before the next Update.
+func (q *QuadtreeSystem) Remove(basic ecs.BasicEntity) {
+	for index, e := range q.entities {
+		if e.BasicEntity.ID() == basic.ID() {
+			if q.tree != nil {
+				q.tree.Remove(e)
+			}
+			q.entities = append(q.entities[:index], q.entities[index+1:]...)
+			return
+		}
+	}
+}
+
+// EntityCount returns the number of entities the QuadtreeSystem holds.
+func (q *QuadtreeSystem) EntityCount() int {
+	return len(q.entities)
+}
+
+// Update rebuilds the quadtree from the current SpaceComponents of the entities.
+func (q *QuadtreeSystem) Update(float32) {
+	bounds := q.Bounds
+	if bounds == (engo.AABB{}) {
+		bounds = q.entitiesBounds()
+	}
+	maxObjects := q.MaxObjects
+	if maxObjects <= 0 {
+		maxObjects = 8
+	}
+
+	if q.tree != nil {
+		q.tree.Destroy()
+	}
+	q.tree = engo.NewQuadtree(bounds, true, maxObjects)
+	if q.MaxLevels > 0 {
+		q.tree.MaxLevels = q.MaxLevels
+	}
+	for _, e := range q.entities {
+		if !q.states.disabled(e.BasicEntity.ID()) {
+			e.aabb = e.SpaceComponent.AABB()
+			q.tree.Insert(e)
+		}
+	}
+}
+
+// entitiesBounds returns the area covering all entities.
+func (q *QuadtreeSystem) entitiesBounds() engo.AABB {
+	if len(q.entities) == 0 {
+		return engo.AABB{}
+	}
+	bounds := q.entities[0].SpaceComponent.AABB()
+	for _, e := range q.entities[1:] {
+		aabb := e.SpaceComponent.AABB()
+		bounds.Min.X = math.Min(bounds.Min.X, aabb.Min.X)
+		bounds.Min.Y = math.Min(bounds.Min.Y, aabb.Min.Y)
+		bounds.Max.X = math.Max(bounds.Max.X, aabb.Max.X)
+		bounds.Max.Y = math.Max(bounds.Max.Y, aabb.Max.Y)
+	}
+	return bounds
+}
+
+// QueryRange returns the entities whose AABB overlaps the rectangle, in no particular order.
+func (q *QuadtreeSystem) QueryRange(rect engo.AABB) []*ecs.BasicEntity {
+	if q.tree == nil {
+		return nil
+	}
+	return quadtreeEntities(q.tree.Retrieve(rect, nil))
+}
+
+// QueryPoint returns the entities containing the point, taking their rotation and Shape into account, in no
+// particular order. Like QueryRange, it only finds entities whose AABB contained the point when the QuadtreeSystem was
+// updated.
+func (q *QuadtreeSystem) QueryPoint(p engo.Point) []*ecs.BasicEntity {
+	if q.tree == nil {
+		return nil
+	}
+	return quadtreeEntities(q.tree.Retrieve(engo.AABB{Min: p, Max: p}, func(item engo.AABBer) bool {
+		return item.(*quadtreeEntity).SpaceComponent.Contains(p)
+	}))
+}
+
+// quadtreeEntities returns the entities of the items of the quadtree.
+func quadtreeEntities(items []engo.AABBer) []*ecs.BasicEntity {
+	if len(items) == 0 {
+		return nil
+	}
+	entities := make([]*ecs.BasicEntity, len(items))
+	for i, item := range items {
+		entities[i] = item.(*quadtreeEntity).BasicEntity
+	}
+	return entities
+}
@@ -0,0 +1,116 @@
+package common
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+type quadtreeBenchEntity struct {
+	basic ecs.BasicEntity
+	space SpaceComponent
+}
+
+func newQuadtreeBenchSystem(count int, maxObjects int) (*QuadtreeSystem, []*quadtreeBenchEntity) {
+	rng := rand.New(rand.NewSource(1))
+	sys := &QuadtreeSystem{MaxObjects: maxObjects}
+	entities := make([]*quadtreeBenchEntity, count)
+	for i := range entities {
+		e := &quadtreeBenchEntity{basic: ecs.NewBasic(), space: SpaceComponent{
+			Position: engo.Point{X: rng.Float32() * 4000, Y: rng.Float32() * 4000},
+			Width:    20,
+			Height:   20,
+			Rotation: rng.Float32() * 90,
+		}}
+		entities[i] = e
+		sys.Add(&e.basic, &e.space)
+	}
+	sys.Update(0)
+	return sys, entities
+}
+
+// linearQueryRange finds the entities overlapping the rectangle by testing all of them
+func linearQueryRange(entities []*quadtreeBenchEntity, rect engo.AABB) []*ecs.BasicEntity {
+	var found []*ecs.BasicEntity
+	for _, e := range entities {
+		aabb := e.space.AABB()
+		if aabb.Min.X <= rect.Max.X && aabb.Max.X >= rect.Min.X && aabb.Min.Y <= rect.Max.Y && aabb.Max.Y >= rect.Min.Y {
+			found = append(found, &e.basic)
+		}
+	}
+	return found
+}
+
+func sortedIDs(entities []*ecs.BasicEntity) []uint64 {
+	ids := make([]uint64, len(entities))
+	for i, e := range entities {
+		ids[i] = e.ID()
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Test the quadtree finds the same entities as testing all of them
+func TestQuadtreeSystem_QueryRange(t *testing.T) {
+	for _, maxObjects := range []int{1, 8, 100} {
+		sys, entities := newQuadtreeBenchSystem(1000, maxObjects)
+		for _, rect := range []engo.AABB{
+			{Min: engo.Point{X: 100, Y: 100}, Max: engo.Point{X: 400, Y: 300}},
+			{Min: engo.Point{X: 1990, Y: 0}, Max: engo.Point{X: 2010, Y: 4000}},
+			{Min: engo.Point{X: -100, Y: -100}, Max: engo.Point{X: 5000, Y: 5000}},
+			{Min: engo.Point{X: 5000, Y: 5000}, Max: engo.Point{X: 6000, Y: 6000}},
+		} {
+			assert.Equal(t, sortedIDs(linearQueryRange(entities, rect)), sortedIDs(sys.QueryRange(rect)),
+				"Range %v with %d objects per node should find the same entities", rect, maxObjects)
+		}
+	}
+}
+
+func TestQuadtreeSystem_QueryPoint(t *testing.T) {
+	w := &ecs.World{}
+	sys := &QuadtreeSystem{}
+	w.AddSystem(sys)
+	a, b := ecs.NewBasic(), ecs.NewBasic()
+	sys.Add(&a, &SpaceComponent{Width: 100, Height: 100})
+	sys.Add(&b, &SpaceComponent{Position: engo.Point{X: 50, Y: 50}, Width: 100, Height: 100, Rotation: 45})
+	sys.Update(0)
+
+	assert.Equal(t, []uint64{a.ID()}, sortedIDs(sys.QueryPoint(engo.Point{X: 10, Y: 10})))
+	assert.Equal(t, []uint64{a.ID(), b.ID()}, sortedIDs(sys.QueryPoint(engo.Point{X: 60, Y: 90})))
+	assert.Empty(t, sys.QueryPoint(engo.Point{X: 110, Y: 60}), "Points in the AABB but outside of a rotated entity shouldn't find it")
+
+	sys.Remove(b)
+	assert.Equal(t, []uint64{a.ID()}, sortedIDs(sys.QueryPoint(engo.Point{X: 60, Y: 90})), "Removed entities shouldn't be found")
+
+	SetEntityEnabled(w, &a, false)
+	sys.Update(0)
+	SetEntityEnabled(w, &a, true)
+	assert.Empty(t, sys.QueryPoint(engo.Point{X: 10, Y: 10}), "Disabled entities shouldn't be found")
+}
+
+func BenchmarkQueryRange(b *testing.B) {
+	rect := engo.AABB{Min: engo.Point{X: 1000, Y: 1000}, Max: engo.Point{X: 1200, Y: 1200}}
+	for _, count := range []int{100, 1000, 10000} {
+		sys, entities := newQuadtreeBenchSystem(count, 8)
+		b.Run(fmt.Sprintf("Linear/%d", count), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				linearQueryRange(entities, rect)
+			}
+		})
+		b.Run(fmt.Sprintf("Quadtree/%d", count), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				sys.QueryRange(rect)
+			}
+		})
+		b.Run(fmt.Sprintf("QuadtreeBuild/%d", count), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				sys.Update(0)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/klopsch/ecs"
 	"github.com/klopsch/engo"
+	"github.com/klopsch/engo/math"
 	"github.com/klopsch/gl"
 )
 
@@ -65,8 +66,17 @@ type RenderComponent struct {
 	Scale engo.Point
 	// Color defines how much of the color-components of the texture get used
 	Color color.Color
+	// Opacity multiplies the final alpha of the entity, independently of Color. It ranges from 0 to 1. Not defining
+	// Opacity will default to 1 when the entity is added to the RenderSystem; set it to 0 afterwards to make the
+	// entity fully transparent.
+	Opacity float32
 	// Drawable refers to the Texture that should be drawn
 	Drawable Drawable
+	// NormalMap holds the normals of the Drawable, for the LightingShader. It's sampled at the same texture
+	// coordinates as the Drawable, so it must have the same layout, such as a spritesheet of the normals of the
+	// sprites in the spritesheet of the Drawable. Without it, the entity is lit as if it's flat. When rendering in
+	// linear space with engo.RunOptions.SRGB, upload it with UploadLinearTexture.
+	NormalMap Drawable
 	// Repeat defines how to repeat the Texture if the SpaceComponent of the entity
 	// is larger than the texture itself, after applying scale. Defaults to NoRepeat
 	// which allows the texture to draw entirely without regard to th SpaceComponent
@@ -79,6 +89,19 @@ type RenderComponent struct {
 	// BufferContent contains the buffer data
 	// Avoid using it unless your are writing a custom shader
 	BufferContent []float32
+	// FlipX and FlipY mirror the texture horizontally and vertically. Unlike a negative Scale, the position and size
+	// of the entity stay the same.
+	FlipX, FlipY bool
+	// BlendMode is how the entity is blended with what's drawn below it. It defaults to BlendNormal. Entities with
+	// different modes can't share a batch, so mixing them in the same Z-Index costs draw calls.
+	BlendMode BlendMode
+	// Mask clips the entity to the area of the Mask, if it's not nil.
+	Mask *Mask
+	// Origin is the point the entity rotates and scales about, as a fraction of the size of its SpaceComponent, such
+	// as {0.5, 1} for the feet of a character. It defaults to the top-left corner. The RenderSystem passes it on to
+	// the SpaceComponent when the entity is added and every frame, so its Corners, AABB and Contains rotate about it
+	// as well. Along an axis the SpaceComponent has no size, it's a fraction of the size the entity is drawn at.
+	Origin engo.Point
 	// StartZIndex defines the initial Z-Index. Z-Index defines the order which the content is drawn to the
 	// screen. Higher z-indices are drawn on top of lower ones. Beware that you must use `SetZIndex` function to change
 	// the Z-Index.
@@ -86,8 +109,11 @@ type RenderComponent struct {
 
 	magFilter, minFilter ZoomFilter
 
-	shader Shader
-	zIndex float32
+	shader   Shader
+	zIndex   float32
+	uniforms map[string]float32
+	// keepOpacity stops the RenderSystem from defaulting an Opacity of 0 to 1, for entities loaded transparent
+	keepOpacity bool
 }
 
 // SetShader sets the shader used by the RenderComponent.
@@ -110,6 +136,9 @@ func (r *RenderComponent) ensureShader() {
 			r.shader = LegacyShader
 		case Text:
 			r.shader = TextShader
+			if txt := r.Drawable.(Text); txt.Font != nil && txt.Font.SDF != nil {
+				r.shader = SDFTextShader
+			}
 		case Blendmap:
 			r.shader = BlendmapShader
 		default:
@@ -125,12 +154,21 @@ func (r *RenderComponent) Shader() Shader {
 }
 
 // SetZIndex sets the order that the RenderComponent is drawn to the screen. Higher z-indices are drawn on top of
-// lower ones if they overlap.
+// lower ones if they overlap. The RenderSystem is only notified when the index actually changes, so calling it every
+// frame for entities that rarely change their Z-Index is cheap.
 func (r *RenderComponent) SetZIndex(index float32) {
+	if r.zIndex == index {
+		return
+	}
 	r.zIndex = index
 	engo.Mailbox.Dispatch(&renderChangeMessage{})
 }
 
+// ZIndex returns the current Z-Index of the RenderComponent.
+func (r *RenderComponent) ZIndex() float32 {
+	return r.zIndex
+}
+
 // SetMinFilter sets the ZoomFilter used for minimizing the RenderComponent
 func (r *RenderComponent) SetMinFilter(z ZoomFilter) {
 	r.minFilter = z
@@ -143,6 +181,21 @@ func (r *RenderComponent) SetMagFilter(z ZoomFilter) {
 	engo.Mailbox.Dispatch(renderChangeMessage{})
 }
 
+// SetUniform sets a value the shader of the RenderComponent can use while drawing it, such as the threshold of the
+// DissolveShader. Which names are used depends on the shader, the built-in ones ignore uniforms they don't know.
+func (r *RenderComponent) SetUniform(name string, value float32) {
+	if r.uniforms == nil {
+		r.uniforms = make(map[string]float32)
+	}
+	r.uniforms[name] = value
+}
+
+// Uniform returns the value set with SetUniform, and whether it was set at all.
+func (r *RenderComponent) Uniform(name string) (float32, bool) {
+	v, ok := r.uniforms[name]
+	return v, ok
+}
+
 type renderEntity struct {
 	*ecs.BasicEntity
 	*RenderComponent
@@ -206,37 +259,130 @@ func (r renderEntityList) Swap(i, j int) {
 // a CameraSystem to work. If a CameraSystem is not in the World when you add RenderSystem
 // one is automatically added to the world.
 type RenderSystem struct {
+	// ViewEvents makes the RenderSystem send an EnterViewMessage when an entity comes into the view of the camera,
+	// or of the camera of any of the CameraViewports, and an ExitViewMessage when it leaves it. Each is sent once
+	// when the entity changes between them, such as to spawn enemies or pause the AI of entities offscreen.
+	ViewEvents bool
+	// ViewMargin extends the view by that many world units on every side for the ViewEvents, so an entity enters
+	// the view slightly before it's actually on the screen.
+	ViewMargin float32
+
 	entities renderEntityList
 	ids      map[uint64]struct{}
 	world    *ecs.World
+	states   *entityStates
 
 	sortingNeeded, newCamera bool
+
+	viewports []CameraViewport
+	targets   []*RenderTarget
+
+	postProcesses []*PostProcess
+	// postBuffers are drawn into in turns while applying the postProcesses
+	postBuffers [2]*RenderTarget
+	// postMSAA is drawn into instead of the first postBuffer when MSAA is on, and postMSAAFailed is set when it
+	// couldn't be created
+	postMSAA       *multisampledBuffer
+	postMSAAFailed bool
+
+	// stats are the statistics of the last frame
+	stats RenderStats
+
+	// camera is the CameraSystem of the World, and inView holds the entities in its view, for the ViewEvents
+	camera *CameraSystem
+	inView map[uint64]struct{}
 }
 
+// CameraViewport is an area of the window that is rendered through its own
+// CameraSystem, such as one half of a split-screen game.
+type CameraViewport struct {
+	// Camera is the CameraSystem the viewport is rendered with.
+	Camera *CameraSystem
+	// Viewport is the area of the window to render to, relative to the size of
+	// the window. (0, 0) is the top-left corner and (1, 1) the bottom-right one.
+	Viewport engo.AABB
+
+	// update is set for cameras which are not part of the World, so the
+	// RenderSystem has to update them.
+	update bool
+}
+
+// viewportScale is the size of the viewport of the current render pass,
+// relative to the size of the window.
+var viewportScale = engo.Point{X: 1, Y: 1}
+
+// RenderStats are the statistics of a frame drawn by the RenderSystem, as
+// returned by Stats. They include the RenderTargets and PostProcesses.
+type RenderStats struct {
+	// Sprites is the number of entities submitted to the shaders to be drawn.
+	Sprites int
+	// Culled is the number of entities which weren't drawn because their
+	// shader found them outside of the view.
+	Culled int
+	// Batches is the number of batches of sprites drawn by the batching
+	// shaders, such as the DefaultShader. Entities sharing a texture and a
+	// shader are drawn in the same batch.
+	Batches int
+	// DrawCalls is the number of OpenGL draw calls made by all the shaders,
+	// which is at least the number of Batches.
+	DrawCalls int
+}
+
+// sub returns the statistics counted since prev.
+func (s RenderStats) sub(prev RenderStats) RenderStats {
+	return RenderStats{
+		Sprites:   s.Sprites - prev.Sprites,
+		Culled:    s.Culled - prev.Culled,
+		Batches:   s.Batches - prev.Batches,
+		DrawCalls: s.DrawCalls - prev.DrawCalls,
+	}
+}
+
+// frameStats counts the statistics of all the frames drawn, for Stats and
+// benchmarks.
+var frameStats RenderStats
+
 // Priority implements the ecs.Prioritizer interface.
 func (*RenderSystem) Priority() int { return RenderSystemPriority }
 
+// Pausable implements the engo.Pauser interface. The RenderSystem keeps drawing while the game is paused.
+func (*RenderSystem) Pausable() bool { return false }
+
+// Draws implements the engo.Drawer interface. The RenderSystem keeps drawing its Scene while it's below the current
+// one on the scene stack, if it's kept drawn.
+func (*RenderSystem) Draws() bool { return true }
+
 // New initializes the RenderSystem
 func (rs *RenderSystem) New(w *ecs.World) {
 	rs.world = w
 	rs.ids = make(map[uint64]struct{})
+	rs.states = worldEntityStates(w)
 
 	engo.Mailbox.Listen("NewCameraMessage", func(engo.Message) {
 		rs.newCamera = true
 	})
 
 	addCameraSystemOnce(w)
+	for _, system := range w.Systems() {
+		if cam, ok := system.(*CameraSystem); ok {
+			rs.camera = cam
+		}
+	}
 
 	if !engo.Headless() {
 		if err := initShaders(w); err != nil {
 			panic(err)
 		}
 		engo.Gl.Enable(engo.Gl.MULTISAMPLE)
+		if engo.SRGB() {
+			engo.Gl.Enable(engo.Gl.FRAMEBUFFER_SRGB)
+		}
 	}
 
 	engo.Mailbox.Listen("renderChangeMessage", func(engo.Message) {
 		rs.sortingNeeded = true
 	})
+	rs.listenTransitions()
 }
 
 var cameraInitMutex sync.Mutex
@@ -267,6 +413,7 @@ func (rs *RenderSystem) Add(basic *ecs.BasicEntity, render *RenderComponent, spa
 	rs.ids[basic.ID()] = struct{}{}
 
 	render.ensureShader()
+	space.pivot = render.Origin
 
 	// This is to prevent users from using the wrong one
 	if render.shader == HUDShader {
@@ -294,6 +441,12 @@ func (rs *RenderSystem) Add(basic *ecs.BasicEntity, render *RenderComponent, spa
 		render.Scale.Y = 1
 	}
 
+	// If the opacity is zero, set it to one.
+	if render.Opacity == 0 && !render.keepOpacity {
+		render.Opacity = 1
+	}
+	render.keepOpacity = false
+
 	if render.zIndex == 0 {
 		render.zIndex = render.StartZIndex
 	}
@@ -327,36 +480,200 @@ func (rs *RenderSystem) Remove(basic ecs.BasicEntity) {
 		rs.sortingNeeded = true
 	}
 	delete(rs.ids, basic.ID())
+	delete(rs.inView, basic.ID())
 }
 
-// Update draws the entities in the RenderSystem to the OpenGL Surface.
-func (rs *RenderSystem) Update(dt float32) {
-	if engo.Headless() {
-		return
+// EntityCount returns the number of entities the RenderSystem holds.
+func (rs *RenderSystem) EntityCount() int {
+	return len(rs.entities)
+}
+
+// AddCameraViewport registers cam to be rendered to the given viewport of the
+// window. Once a viewport is registered, the RenderSystem draws all entities
+// once per viewport instead of once with the World's CameraSystem. Cameras
+// which are not added to the World are initialized and updated by the
+// RenderSystem.
+func (rs *RenderSystem) AddCameraViewport(cam *CameraSystem, viewport engo.AABB) {
+	vp := CameraViewport{Camera: cam, Viewport: viewport, update: true}
+	if rs.world != nil {
+		for _, system := range rs.world.Systems() {
+			if system == cam {
+				vp.update = false
+			}
+		}
+	}
+	if vp.update && cam.longTasks == nil {
+		cam.setup()
+	}
+	rs.viewports = append(rs.viewports, vp)
+}
+
+// RemoveCameraViewport removes the viewports rendered with cam. When no
+// viewports are left, the RenderSystem renders the whole window with the
+// World's CameraSystem again.
+func (rs *RenderSystem) RemoveCameraViewport(cam *CameraSystem) {
+	vps := rs.viewports[:0]
+	for _, vp := range rs.viewports {
+		if vp.Camera != cam {
+			vps = append(vps, vp)
+		}
+	}
+	rs.viewports = vps
+	if len(rs.viewports) == 0 {
+		rs.newCamera = true
 	}
+}
+
+// CameraViewports returns the viewports registered with AddCameraViewport.
+func (rs *RenderSystem) CameraViewports() []CameraViewport {
+	return rs.viewports
+}
+
+// MarkOrderDirty makes the RenderSystem sort its entities before drawing the next frame. Entities are only sorted
+// when their draw order may have changed, such as when they're added or when `SetZIndex` is used. Call this when
+// something else the order depends on, like the position of entities with the same Z-Index, has changed.
+func (rs *RenderSystem) MarkOrderDirty() {
+	rs.sortingNeeded = true
+}
 
+// sortEntities sorts the entities in draw order, if it may have changed since the last sort.
+func (rs *RenderSystem) sortEntities() {
 	if rs.sortingNeeded {
 		sort.Sort(rs.entities)
 		rs.sortingNeeded = false
 	}
+}
 
-	if rs.newCamera {
+// Update draws the entities in the RenderSystem to the OpenGL Surface.
+func (rs *RenderSystem) Update(dt float32) {
+	if rs.ViewEvents {
+		rs.updateView()
+	}
+	if engo.Headless() {
+		return
+	}
+
+	// The shaders are shared with the RenderSystems of the other Scenes on the scene stack
+	if rs.newCamera || lastRenderSystem != rs {
 		newCamera(rs.world)
 		rs.newCamera = false
 	}
+	lastRenderSystem = rs
+	rs.sortEntities()
+
+	start := frameStats
+	defer func() { rs.stats = frameStats.sub(start) }()
+
+	DefaultAtlas.upload()
+	rasterizeTexts()
+	rs.drawTargets(dt)
+	if rs.beginPostProcess() {
+		rs.draw(dt)
+		rs.endPostProcess()
+	} else {
+		rs.draw(dt)
+	}
+	rs.drawTransition(dt)
+}
+
+// DrawCalls returns the number of OpenGL draw calls made to draw the last frame, including the RenderTargets and
+// PostProcesses. Each batch of entities sharing a texture and a shader takes one draw call, so comparing it to the
+// number of entities shows how well they're batched.
+func (rs *RenderSystem) DrawCalls() int {
+	return rs.stats.DrawCalls
+}
+
+// Stats returns the statistics of the last frame, to measure the effect of batching and culling.
+func (rs *RenderSystem) Stats() RenderStats {
+	return rs.stats
+}
+
+// draw clears the screen and draws all entities, once for every camera viewport.
+func (rs *RenderSystem) draw(dt float32) {
+	canvasW, canvasH := engo.CanvasWidth(), engo.CanvasHeight()
+	game := engo.GameViewport()
+	// Scenes drawn over others on the scene stack are drawn without clearing what's below them
+	if !engo.Overlay() {
+		if game == (engo.AABB{Max: engo.Point{X: 1, Y: 1}}) {
+			engo.Gl.Clear(engo.Gl.COLOR_BUFFER_BIT)
+		} else {
+			clearLetterbox(game, canvasW, canvasH)
+		}
+	}
 
+	if len(rs.viewports) == 0 {
+		setViewport(game, canvasW, canvasH)
+		drawBackground()
+		rs.render(nil)
+	}
+	for _, vp := range rs.viewports {
+		if vp.update {
+			vp.Camera.Update(dt)
+		}
+		for _, shader := range shaders {
+			shader.SetCamera(vp.Camera)
+		}
+		setViewport(engo.AABB{
+			Min: engo.Point{X: game.Min.X + vp.Viewport.Min.X*(game.Max.X-game.Min.X), Y: game.Min.Y + vp.Viewport.Min.Y*(game.Max.Y-game.Min.Y)},
+			Max: engo.Point{X: game.Min.X + vp.Viewport.Max.X*(game.Max.X-game.Min.X), Y: game.Min.Y + vp.Viewport.Max.Y*(game.Max.Y-game.Min.Y)},
+		}, canvasW, canvasH)
+		viewportScale = engo.Point{X: vp.Viewport.Max.X - vp.Viewport.Min.X, Y: vp.Viewport.Max.Y - vp.Viewport.Min.Y}
+		drawBackground()
+		rs.render(nil)
+	}
+	viewportScale = engo.Point{X: 1, Y: 1}
+	engo.Gl.Viewport(0, 0, int(canvasW), int(canvasH))
+}
+
+// setViewport sets the OpenGL viewport to the part of the canvas, given as fractions of its size from its top-left
+// corner.
+func setViewport(vp engo.AABB, canvasW, canvasH float32) {
+	// OpenGL viewports start at the bottom-left corner
+	engo.Gl.Viewport(
+		int(vp.Min.X*canvasW),
+		int((1-vp.Max.Y)*canvasH),
+		int((vp.Max.X-vp.Min.X)*canvasW),
+		int((vp.Max.Y-vp.Min.Y)*canvasH),
+	)
+}
+
+// clearLetterbox clears the canvas to black, and the part of it the game is drawn in to the background.
+func clearLetterbox(game engo.AABB, canvasW, canvasH float32) {
+	setClearColor(color.Black)
+	engo.Gl.Clear(engo.Gl.COLOR_BUFFER_BIT)
+	setClearColor(background)
+
+	engo.Gl.Enable(engo.Gl.SCISSOR_TEST)
+	engo.Gl.Scissor(
+		int(game.Min.X*canvasW),
+		int((1-game.Max.Y)*canvasH),
+		int((game.Max.X-game.Min.X)*canvasW),
+		int((game.Max.Y-game.Min.Y)*canvasH),
+	)
 	engo.Gl.Clear(engo.Gl.COLOR_BUFFER_BIT)
+	engo.Gl.Disable(engo.Gl.SCISSOR_TEST)
+}
 
+// render draws all entities once, using the cameras currently set on the shaders. If target is not nil, only the
+// entities it includes are drawn.
+func (rs *RenderSystem) render(target *RenderTarget) {
 	preparedCullingShaders := make(map[CullingShader]struct{})
 	var cullingShader CullingShader // current culling shader
 	var prevShader Shader           // shader of the previous entity
 	var currentShader Shader        // currently "active" shader
+	var currentBlend BlendMode      // blend mode of the current batch
+	var currentMask *Mask           // mask of the current batch
 
 	// TODO: it's linear for now, but that might very well be a bad idea
 	for _, e := range rs.entities {
-		if e.RenderComponent.Hidden {
+		if e.RenderComponent.Hidden || rs.states.disabled(e.BasicEntity.ID()) {
 			continue // with other entities
 		}
+		if target != nil && !target.includes(e) {
+			continue
+		}
+
+		e.SpaceComponent.pivot = e.RenderComponent.Origin
 
 		// Retrieve a shader, may be the default one -- then use it if we aren't already using it
 		shader := e.RenderComponent.shader
@@ -376,16 +693,25 @@ func (rs *RenderSystem) Update(dt float32) {
 		}
 
 		if cullingShader != nil && !cullingShader.ShouldDraw(e.RenderComponent, e.SpaceComponent) {
+			frameStats.Culled++
 			continue
 		}
 
-		// Change Shader if we have to
-		if !compareShaders(shader, currentShader) {
+		// Change Shader if we have to, or start a new batch when the blend mode or mask changes
+		blend, mask := e.RenderComponent.BlendMode, e.RenderComponent.Mask
+		if !compareShaders(shader, currentShader) || blend != currentBlend || mask != currentMask {
 			if currentShader != nil {
 				currentShader.Post()
 			}
+			if mask != currentMask {
+				applyMask(mask)
+				currentMask = mask
+			}
 			shader.Pre()
 			currentShader = shader
+			// Shaders may leave blending as they like in Pre, so the blend mode is always set after it
+			setBlendMode(blend)
+			currentBlend = blend
 		}
 
 		// Setting default scale to 1
@@ -399,18 +725,48 @@ func (rs *RenderSystem) Update(dt float32) {
 		}
 
 		currentShader.Draw(e.RenderComponent, e.SpaceComponent)
+		frameStats.Sprites++
 	}
 
 	if currentShader != nil {
 		currentShader.Post()
 	}
+	if currentMask != nil {
+		applyMask(nil)
+	}
 }
 
-// SetBackground sets the OpenGL ClearColor to the provided color.
+// background is the color set with SetBackground.
+var background color.Color = color.Transparent
+
+// SetBackground sets the OpenGL ClearColor to the provided color, and removes the gradient set with
+// SetBackgroundGradient.
 func SetBackground(c color.Color) {
+	background = c
+	backgroundCorners = nil
+	setClearColor(c)
+}
+
+// setClearColor sets the OpenGL ClearColor, without changing the background.
+func setClearColor(c color.Color) {
 	if !engo.Headless() {
-		r, g, b, a := c.RGBA()
+		r, g, b, a := linearColor(c).RGBA()
 
 		engo.Gl.ClearColor(float32(r)/0xffff, float32(g)/0xffff, float32(b)/0xffff, float32(a)/0xffff)
 	}
 }
+
+// pivot returns the point the entity rotates and scales about in the world, which is its Origin within its
+// SpaceComponent, or within the drawn size w by h along the axes the SpaceComponent has no size.
+func pivot(ren *RenderComponent, space *SpaceComponent, w, h float32) engo.Point {
+	if space.Width != 0 {
+		w = space.Width
+	}
+	if space.Height != 0 {
+		h = space.Height
+	}
+	return engo.Point{
+		X: space.Position.X + ren.Origin.X*math.Abs(w),
+		Y: space.Position.Y + ren.Origin.Y*math.Abs(h),
+	}
+}
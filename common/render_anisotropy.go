@@ -0,0 +1,80 @@
+package common
+
+import (
+	"github.com/klopsch/engo"
+	"github.com/klopsch/gl"
+)
+
+const (
+	// textureMaxAnisotropy is GL_TEXTURE_MAX_ANISOTROPY_EXT
+	textureMaxAnisotropy = 0x84FE
+	// maxTextureMaxAnisotropy is GL_MAX_TEXTURE_MAX_ANISOTROPY_EXT
+	maxTextureMaxAnisotropy = 0x84FF
+)
+
+var (
+	// anisotropy sets the anisotropic filtering of textures, or is nil if the OpenGL context doesn't support it.
+	anisotropy glAnisotropy
+	// anisotropyContext is the OpenGL context anisotropy was checked for. A new context, such as after the window
+	// was recreated, is checked again.
+	anisotropyContext *gl.Context
+)
+
+// glAnisotropy sets float texture parameters and queries float state, which
+// anisotropic filtering needs. It's returned by newAnisotropy when the driver
+// supports the GL_EXT_texture_filter_anisotropic extension.
+type glAnisotropy interface {
+	TexParameterf(target, pname int, param float32)
+	GetFloatv(dst []float32, pname int)
+}
+
+// SetTextureAnisotropy sets the anisotropic filtering level of the drawable's
+// texture, overriding engo.RunOptions.Anisotropy. Anisotropy is part of the
+// texture, so it applies to every drawable sharing it, such as all cells of a
+// Spritesheet. A level of 1 disables it, and higher levels are capped to what
+// the driver supports. Nothing happens when the
+// GL_EXT_texture_filter_anisotropic extension isn't available. It's only
+// supported by the GLFW back end for now, and ignored by the others, including
+// WebGL and mobile even where their driver has the extension.
+func SetTextureAnisotropy(d Drawable, level float32) {
+	if engo.Headless() || d.Texture() == nil {
+		return
+	}
+	engo.Gl.BindTexture(engo.Gl.TEXTURE_2D, d.Texture())
+	applyAnisotropy(level)
+	engo.Gl.BindTexture(engo.Gl.TEXTURE_2D, nil)
+}
+
+// applyAnisotropy sets the anisotropic filtering level of the texture that is
+// bound to TEXTURE_2D.
+func applyAnisotropy(level float32) {
+	if anisotropyContext != engo.Gl {
+		anisotropyContext = engo.Gl
+		anisotropy = newAnisotropy()
+	}
+	a := anisotropy
+	if a == nil {
+		return
+	}
+
+	max := make([]float32, 1)
+	a.GetFloatv(max, maxTextureMaxAnisotropy)
+	if level, ok := clampAnisotropy(level, max[0]); ok {
+		a.TexParameterf(engo.Gl.TEXTURE_2D, textureMaxAnisotropy, level)
+	}
+}
+
+// clampAnisotropy caps level to the driver's max. ok is false if the driver
+// doesn't support anisotropic filtering.
+func clampAnisotropy(level, max float32) (float32, bool) {
+	if max <= 1 {
+		return 0, false
+	}
+	if level < 1 {
+		return 1, true
+	}
+	if level > max {
+		return max, true
+	}
+	return level, true
+}
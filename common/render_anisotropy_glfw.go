@@ -0,0 +1,41 @@
+//go:build (darwin || linux || windows) && !ios && !android && !js && !sdl && !headless && !vulkan
+// +build darwin linux windows
+// +build !ios
+// +build !android
+// +build !js
+// +build !sdl
+// +build !headless
+// +build !vulkan
+
+package common
+
+import (
+	"github.com/go-gl/gl/v2.1/gl"
+	"github.com/klopsch/engo"
+)
+
+// extAnisotropy sets anisotropic filtering with the GL_EXT_texture_filter_anisotropic extension, whose float calls
+// the contexts of github.com/klopsch/gl don't bind. Like arbInstancer, it calls go-gl directly, so it's only built for
+// the GLFW back end.
+type extAnisotropy struct{}
+
+func (extAnisotropy) TexParameterf(target, pname int, param float32) {
+	gl.TexParameterf(uint32(target), uint32(pname), param)
+}
+
+func (extAnisotropy) GetFloatv(dst []float32, pname int) {
+	gl.GetFloatv(uint32(pname), &dst[0])
+}
+
+// newAnisotropy returns the anisotropic filtering calls of the OpenGL context, or nil if its driver lacks the
+// extension. The ARB extension, core since OpenGL 4.6, uses the same enums.
+func newAnisotropy() glAnisotropy {
+	if engo.Headless() || engo.Gl == nil {
+		return nil
+	}
+	exts := glExtensions()
+	if !exts["GL_EXT_texture_filter_anisotropic"] && !exts["GL_ARB_texture_filter_anisotropic"] {
+		return nil
+	}
+	return extAnisotropy{}
+}
@@ -0,0 +1,9 @@
+//go:build (!darwin && !linux && !windows) || ios || android || js || sdl || headless || vulkan
+// +build !darwin,!linux,!windows ios android js sdl headless vulkan
+
+package common
+
+// newAnisotropy returns nil, as anisotropic filtering is only bound for the GLFW back end.
+func newAnisotropy() glAnisotropy {
+	return nil
+}
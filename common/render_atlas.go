@@ -0,0 +1,161 @@
+package common
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/klopsch/engo"
+	"github.com/klopsch/gl"
+)
+
+const (
+	// DefaultAtlasPageSize is the width and height of RuntimeAtlas pages when
+	// no PageSize is set.
+	DefaultAtlasPageSize = 1024
+	// atlasPadding is the number of transparent pixels kept between packed
+	// images, so linear filtering doesn't bleed neighbors into each other.
+	atlasPadding = 1
+)
+
+// RuntimeAtlas packs small images into shared textures, called pages, as they
+// are loaded. Sprites drawn from different image files then use the same
+// texture, so the RenderSystem can draw them in a single batch instead of
+// flushing every time the texture changes. Images are marked for packing with
+// PackTextures before they are loaded.
+//
+// Packed textures wrap around their whole page, so they can't be drawn with
+// the Repeat or MirroredRepeat modes. Unloading a packed image doesn't free its
+// space on the page.
+type RuntimeAtlas struct {
+	// PageSize is the width and height of each page in pixels. Defaults to
+	// DefaultAtlasPageSize.
+	PageSize int
+	// MaxSize is the largest width or height an image can have and still be
+	// packed. Larger images get a texture of their own. Defaults to a quarter
+	// of the PageSize.
+	MaxSize int
+
+	pages  []*atlasPage
+	marked map[string]struct{}
+}
+
+// DefaultAtlas is the RuntimeAtlas that images marked with PackTextures are
+// packed into. Its sizes can be changed before any image is loaded.
+var DefaultAtlas = &RuntimeAtlas{}
+
+// PackTextures marks the images at the given urls to be packed into the
+// DefaultAtlas. It has to be called before the images are loaded, usually in
+// the Preload of a Scene. Images too large for the atlas are loaded as usual.
+func PackTextures(urls ...string) {
+	if DefaultAtlas.marked == nil {
+		DefaultAtlas.marked = make(map[string]struct{})
+	}
+	for _, url := range urls {
+		DefaultAtlas.marked[url] = struct{}{}
+	}
+}
+
+// atlasPage is a single texture of a RuntimeAtlas, filled shelf by shelf from
+// the top left.
+type atlasPage struct {
+	img *image.NRGBA
+	id  *gl.Texture
+	// x and y are where the next image goes on the current shelf
+	x, y int
+	// shelf is the height of the current shelf
+	shelf int
+	// dirty is set when images were packed since the page was last uploaded
+	dirty bool
+}
+
+// fit reserves a w by h area on the page, returning its top left corner.
+func (p *atlasPage) fit(w, h int) (int, int, bool) {
+	size := p.img.Rect.Dx()
+	if p.x+w > size {
+		p.x = 0
+		p.y += p.shelf + atlasPadding
+		p.shelf = 0
+	}
+	if p.x+w > size || p.y+h > size {
+		return 0, 0, false
+	}
+
+	x, y := p.x, p.y
+	p.x += w + atlasPadding
+	if h > p.shelf {
+		p.shelf = h
+	}
+	return x, y, true
+}
+
+func (a *RuntimeAtlas) pageSize() int {
+	if a.PageSize <= 0 {
+		return DefaultAtlasPageSize
+	}
+	return a.PageSize
+}
+
+func (a *RuntimeAtlas) maxSize() int {
+	if a.MaxSize <= 0 {
+		return a.pageSize() / 4
+	}
+	return a.MaxSize
+}
+
+// packs reports whether the image at url was marked for packing.
+func (a *RuntimeAtlas) packs(url string) bool {
+	_, ok := a.marked[url]
+	return ok
+}
+
+// add packs img into the atlas and returns a TextureResource for its region
+// of the page. ok is false when the image is too large to be packed.
+func (a *RuntimeAtlas) add(img *image.NRGBA) (res TextureResource, ok bool) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > a.maxSize() || h > a.maxSize() || w > a.pageSize() || h > a.pageSize() {
+		return TextureResource{}, false
+	}
+
+	var page *atlasPage
+	var x, y int
+	if len(a.pages) > 0 {
+		page = a.pages[len(a.pages)-1]
+		x, y, ok = page.fit(w, h)
+	}
+	if !ok {
+		size := a.pageSize()
+		pageImg := image.NewNRGBA(image.Rect(0, 0, size, size))
+		page = &atlasPage{img: pageImg, id: UploadTexture(&ImageObject{pageImg})}
+		a.pages = append(a.pages, page)
+		x, y, _ = page.fit(w, h)
+	}
+
+	draw.Draw(page.img, image.Rect(x, y, x+w, y+h), img, b.Min, draw.Src)
+	page.dirty = true
+
+	size := float32(page.img.Rect.Dx())
+	viewport := engo.AABB{
+		Min: engo.Point{X: float32(x) / size, Y: float32(y) / size},
+		Max: engo.Point{X: float32(x+w) / size, Y: float32(y+h) / size},
+	}
+	return TextureResource{Texture: page.id, Width: float32(w), Height: float32(h), Viewport: &viewport}, true
+}
+
+// upload sends the pages images were packed into since the last upload to the
+// GPU. The RenderSystem calls it before drawing each frame.
+func (a *RuntimeAtlas) upload() {
+	uploaded := false
+	for _, page := range a.pages {
+		if !page.dirty {
+			continue
+		}
+		engo.Gl.BindTexture(engo.Gl.TEXTURE_2D, page.id)
+		engo.Gl.TexImage2D(engo.Gl.TEXTURE_2D, 0, textureFormat(), engo.Gl.RGBA, engo.Gl.UNSIGNED_BYTE, page.img)
+		page.dirty = false
+		uploaded = true
+	}
+	if uploaded {
+		engo.Gl.BindTexture(engo.Gl.TEXTURE_2D, nil)
+	}
+}
@@ -0,0 +1,74 @@
+package common
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuntimeAtlasPacking(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &CameraTestScene{})
+
+	a := &RuntimeAtlas{PageSize: 64, MaxSize: 32}
+	square := func(size int) *image.NRGBA {
+		img := image.NewNRGBA(image.Rect(0, 0, size, size))
+		img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+		return img
+	}
+	min := func(res TextureResource) engo.Point {
+		return engo.Point{X: res.Viewport.Min.X * 64, Y: res.Viewport.Min.Y * 64}
+	}
+
+	// Three 20px images fill the first shelf, with a pixel of padding between them
+	for i, x := range []float32{0, 21, 42} {
+		res, ok := a.add(square(20))
+		if assert.True(t, ok, "Image %d should be packed", i) {
+			assert.Equal(t, engo.Point{X: x, Y: 0}, min(res))
+			assert.Equal(t, float32(20), res.Width)
+		}
+	}
+
+	res, ok := a.add(square(20))
+	if assert.True(t, ok) {
+		assert.Equal(t, engo.Point{X: 0, Y: 21}, min(res), "A full shelf should start a new one")
+	}
+	assert.Equal(t, color.NRGBA{R: 255, A: 255}, a.pages[0].img.NRGBAAt(0, 21), "Pixels should be copied to the page")
+	assert.True(t, a.pages[0].dirty, "Packing should mark the page for upload")
+
+	res, ok = a.add(square(32))
+	if assert.True(t, ok) {
+		assert.Equal(t, engo.Point{X: 21, Y: 21}, min(res))
+		assert.Equal(t, engo.Point{X: 53.0 / 64, Y: 53.0 / 64}, res.Viewport.Max)
+	}
+
+	_, ok = a.add(square(33))
+	assert.False(t, ok, "Images larger than MaxSize should not be packed")
+
+	res, ok = a.add(square(32))
+	if assert.True(t, ok) {
+		assert.Len(t, a.pages, 2, "A full page should start a new one")
+		assert.Equal(t, engo.Point{X: 0, Y: 0}, min(res))
+	}
+}
+
+func TestPackTextures(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &CameraTestScene{})
+	defer func(atlas *RuntimeAtlas) { DefaultAtlas = atlas }(DefaultAtlas)
+	DefaultAtlas = &RuntimeAtlas{PageSize: 64}
+
+	PackTextures("small.png")
+	small := newLoadedTextureResource("small.png", image.NewNRGBA(image.Rect(0, 0, 8, 8)))
+	assert.NotNil(t, small.Viewport, "Marked images should be packed")
+	other := newLoadedTextureResource("other.png", image.NewNRGBA(image.Rect(0, 0, 8, 8)))
+	assert.Nil(t, other.Viewport, "Images that aren't marked should get their own texture")
+	assert.Len(t, DefaultAtlas.pages, 1)
+}
@@ -0,0 +1,66 @@
+package common
+
+import (
+	"image/color"
+
+	"github.com/klopsch/engo"
+)
+
+// backgroundCorners are the colors of the top left, top right, bottom right and bottom left corners of the
+// background, when it's a gradient.
+var backgroundCorners *[4]color.Color
+
+// SetBackgroundGradient draws a gradient from the top to the bottom of the screen behind everything, such as a sky. It
+// is drawn over the color set with SetBackground, which shows through where the colors are transparent, and doesn't
+// move with the camera. Calling SetBackground again removes it.
+func SetBackgroundGradient(top, bottom color.Color) {
+	SetBackgroundCorners(top, top, bottom, bottom)
+}
+
+// SetBackgroundCorners draws a gradient between the colors at the four corners of the screen behind everything, like
+// SetBackgroundGradient.
+func SetBackgroundCorners(topLeft, topRight, bottomRight, bottomLeft color.Color) {
+	backgroundCorners = &[4]color.Color{topLeft, topRight, bottomRight, bottomLeft}
+}
+
+// backgroundVertices returns the vertices of a quad of the given size, in the format of the legacyShader, with the
+// colors at its corners.
+func backgroundVertices(w, h float32, corners [4]color.Color) []float32 {
+	return []float32{
+		0, 0, tintToFloat32(corners[0], 1),
+		w, 0, tintToFloat32(corners[1], 1),
+		w, h, tintToFloat32(corners[2], 1),
+		0, h, tintToFloat32(corners[3], 1),
+	}
+}
+
+// drawBackground draws the gradient of the background over the current viewport, if there is one, unless the Scene
+// is drawn over another. It's drawn by the LegacyHUDShader, so the camera doesn't move it.
+func drawBackground() {
+	if backgroundCorners == nil || engo.Overlay() {
+		return
+	}
+	w, h := viewSize()
+	LegacyHUDShader.drawQuad(w, h, *backgroundCorners)
+}
+
+// drawQuad draws a quad of the given size at the top left of the view, with the colors at its corners. It must not be
+// used between Pre and Post.
+func (l *legacyShader) drawQuad(w, h float32, corners [4]color.Color) {
+	l.Pre()
+	if l.quadBuffer == nil {
+		l.quadBuffer = engo.Gl.CreateBuffer()
+	}
+	engo.Gl.BindBuffer(engo.Gl.ARRAY_BUFFER, l.quadBuffer)
+	engo.Gl.BufferData(engo.Gl.ARRAY_BUFFER, backgroundVertices(w, h, corners), engo.Gl.STREAM_DRAW)
+	engo.Gl.VertexAttribPointer(l.inPosition, 2, engo.Gl.FLOAT, false, 12, 0)
+	engo.Gl.VertexAttribPointer(l.inColor, 4, engo.Gl.UNSIGNED_BYTE, true, 12, 8)
+
+	identity := []float32{1, 0, 0, 0, 1, 0, 0, 0, 1}
+	engo.Gl.UniformMatrix3fv(l.matrixModel, false, identity)
+
+	engo.Gl.BindBuffer(engo.Gl.ELEMENT_ARRAY_BUFFER, l.indicesRectanglesVBO)
+	engo.Gl.DrawElements(engo.Gl.TRIANGLES, 6, engo.Gl.UNSIGNED_SHORT, 0)
+	frameStats.DrawCalls++
+	l.Post()
+}
@@ -0,0 +1,35 @@
+package common
+
+import "github.com/klopsch/engo"
+
+// BlendMode is how the pixels of an entity are combined with the pixels already drawn below it, by the blending of
+// OpenGL rather than by the shader, unlike the Color of the RenderComponent.
+type BlendMode uint8
+
+const (
+	// BlendNormal draws the entity over what's below it, according to its alpha.
+	BlendNormal BlendMode = iota
+	// BlendAdditive adds the color of the entity, times its alpha, to what's below it, which only gets brighter. It
+	// suits light, fire and magic effects.
+	BlendAdditive
+	// BlendMultiply multiplies what's below the entity by its color, which only gets darker, such as for shades.
+	BlendMultiply
+	// BlendScreen multiplies the inverse of what's below the entity by the inverse of its color, which only gets
+	// brighter like BlendAdditive, but never saturates.
+	BlendScreen
+)
+
+// setBlendMode sets the blend function of OpenGL for the mode. BlendMultiply and BlendScreen can't take the alpha of
+// the entity into account with textures that aren't premultiplied, so their transparent pixels must be black.
+func setBlendMode(mode BlendMode) {
+	switch mode {
+	case BlendAdditive:
+		engo.Gl.BlendFunc(engo.Gl.SRC_ALPHA, engo.Gl.ONE)
+	case BlendMultiply:
+		engo.Gl.BlendFunc(engo.Gl.DST_COLOR, engo.Gl.ONE_MINUS_SRC_ALPHA)
+	case BlendScreen:
+		engo.Gl.BlendFunc(engo.Gl.ONE, engo.Gl.ONE_MINUS_SRC_COLOR)
+	default:
+		engo.Gl.BlendFunc(engo.Gl.SRC_ALPHA, engo.Gl.ONE_MINUS_SRC_ALPHA)
+	}
+}
@@ -58,7 +58,7 @@ func (i *imageLoader) Load(url string, data io.Reader) error {
 		b := img.Bounds()
 		newm := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
 		draw.Draw(newm, newm.Bounds(), img, b.Min, draw.Src)
-		res = NewTextureResource(&ImageObject{newm})
+		res = i.newTextureResource(url, newm)
 	} else {
 		img, _, err := image.Decode(data)
 		if err != nil {
@@ -67,7 +67,7 @@ func (i *imageLoader) Load(url string, data io.Reader) error {
 		b := img.Bounds()
 		newm := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
 		draw.Draw(newm, newm.Bounds(), img, b.Min, draw.Src)
-		res = NewTextureResource(&ImageObject{newm})
+		res = i.newTextureResource(url, newm)
 	}
 	res.url = url
 	i.images[url] = res
@@ -89,6 +89,29 @@ func (i *imageLoader) Resource(url string) (engo.Resource, error) {
 	return texture, nil
 }
 
+// newTextureResource returns the TextureResource of the image loaded from url. When the image is loaded again,
+// such as when it's reloaded because its file changed, it's uploaded into the texture it had, so the Textures
+// already using it show the new image.
+func (i *imageLoader) newTextureResource(url string, img *image.NRGBA) TextureResource {
+	if prev, ok := i.images[url]; ok && prev.Viewport == nil && prev.Texture != nil {
+		uploadTextureData(prev.Texture, &ImageObject{img})
+		prev.Width, prev.Height = float32(img.Rect.Dx()), float32(img.Rect.Dy())
+		return prev
+	}
+	return newLoadedTextureResource(url, img)
+}
+
+// newLoadedTextureResource packs img into the DefaultAtlas if url was marked
+// with PackTextures and it fits, and gives it a texture of its own otherwise.
+func newLoadedTextureResource(url string, img *image.NRGBA) TextureResource {
+	if DefaultAtlas.packs(url) {
+		if res, ok := DefaultAtlas.add(img); ok {
+			return res
+		}
+	}
+	return NewTextureResource(&ImageObject{img})
+}
+
 // Image holds data and properties of an .jpg, .gif, or .png file
 type Image interface {
 	Data() interface{}
@@ -101,21 +124,30 @@ func UploadTexture(img Image) *gl.Texture {
 	var id *gl.Texture
 	if !engo.Headless() {
 		id = engo.Gl.CreateTexture()
+		uploadTextureData(id, img)
+	}
+	return id
+}
 
+// uploadTextureData sends the image to the GPU into the texture with the given id, replacing what it held.
+func uploadTextureData(id *gl.Texture, img Image) {
+	if !engo.Headless() {
 		engo.Gl.BindTexture(engo.Gl.TEXTURE_2D, id)
 
 		engo.Gl.TexParameteri(engo.Gl.TEXTURE_2D, engo.Gl.TEXTURE_WRAP_S, engo.Gl.CLAMP_TO_EDGE)
 		engo.Gl.TexParameteri(engo.Gl.TEXTURE_2D, engo.Gl.TEXTURE_WRAP_T, engo.Gl.CLAMP_TO_EDGE)
 		engo.Gl.TexParameteri(engo.Gl.TEXTURE_2D, engo.Gl.TEXTURE_MIN_FILTER, engo.Gl.LINEAR)
 		engo.Gl.TexParameteri(engo.Gl.TEXTURE_2D, engo.Gl.TEXTURE_MAG_FILTER, engo.Gl.NEAREST)
+		if engo.Anisotropy() > 1 {
+			applyAnisotropy(engo.Anisotropy())
+		}
 
 		if img.Data() == nil {
 			panic("Texture image data is nil.")
 		}
 
-		engo.Gl.TexImage2D(engo.Gl.TEXTURE_2D, 0, engo.Gl.RGBA, engo.Gl.RGBA, engo.Gl.UNSIGNED_BYTE, img.Data())
+		engo.Gl.TexImage2D(engo.Gl.TEXTURE_2D, 0, textureFormat(), engo.Gl.RGBA, engo.Gl.UNSIGNED_BYTE, img.Data())
 	}
-	return id
 }
 
 // NewTextureResource sends the image to the GPU and returns a `TextureResource` for easy access
@@ -212,6 +244,31 @@ func (t Texture) View() (float32, float32, float32, float32) {
 	return t.viewport.Min.X, t.viewport.Min.Y, t.viewport.Max.X, t.viewport.Max.Y
 }
 
+// NewTextureRegion returns a Texture showing the rectangle at x, y with width w and height h of tex, in pixels. The
+// region shares the OpenGL texture of tex, so regions of the same texture are batched together. An error is returned
+// if the rectangle is empty or doesn't fit within tex.
+func NewTextureRegion(tex Texture, x, y, w, h float32) (Texture, error) {
+	if w <= 0 || h <= 0 {
+		return Texture{}, fmt.Errorf("texture region must not be empty, got %vx%v", w, h)
+	}
+	if x < 0 || y < 0 || x+w > tex.width || y+h > tex.height {
+		return Texture{}, fmt.Errorf("texture region (%v, %v, %v, %v) is not within the %vx%v texture", x, y, w, h, tex.width, tex.height)
+	}
+
+	// The viewport of tex may already be a region of a larger texture
+	u, v, u2, v2 := tex.View()
+	du, dv := (u2-u)/tex.width, (v2-v)/tex.height
+	return Texture{
+		id:     tex.id,
+		width:  w,
+		height: h,
+		viewport: engo.AABB{
+			Min: engo.Point{X: u + x*du, Y: v + y*dv},
+			Max: engo.Point{X: u + (x+w)*du, Y: v + (y+h)*dv},
+		},
+	}, nil
+}
+
 // Close removes the Texture data from the GPU.
 func (t Texture) Close() {
 	if !engo.Headless() {
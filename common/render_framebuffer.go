@@ -14,8 +14,13 @@ type Framebuffer struct {
 	fbo    *gl.FrameBuffer
 	oldVP  [4]int32
 	isOpen bool
+	// oldHeight is the height of the framebuffer that was bound before this one was opened
+	oldHeight int
 }
 
+// boundHeight is the height of the open Framebuffer drawn into, or 0 while drawing into the window.
+var boundHeight int
+
 type RenderTexture struct {
 	tex           *gl.Texture
 	width, height float32
@@ -34,6 +39,20 @@ func CreateRenderBuffer(width, height int) *RenderBuffer {
 	return rbuf
 }
 
+// CreateMultisampleRenderBuffer creates a RenderBuffer which takes the given amount of samples of every pixel. It
+// can't be sampled by shaders; resolve it into a texture with Framebuffer.Blit first.
+func CreateMultisampleRenderBuffer(width, height, samples int) *RenderBuffer {
+	rbuf := &RenderBuffer{
+		rbo:    engo.Gl.CreateRenderBuffer(),
+		width:  width,
+		height: height,
+	}
+	engo.Gl.BindRenderBuffer(rbuf.rbo)
+	engo.Gl.RenderBufferStorageMultisample(samples, engo.Gl.RGBA8, width, height)
+	engo.Gl.BindRenderBuffer(nil)
+	return rbuf
+}
+
 func CreateRenderTexture(width, height int, depthBuffer bool) *RenderTexture {
 	texBuf := &RenderTexture{
 		width:  float32(width),
@@ -111,6 +130,7 @@ func (fb *Framebuffer) Open(width, height int) {
 	engo.Gl.BindFrameBuffer(fb.fbo)
 	fb.oldVP = engo.Gl.GetViewport()
 	engo.Gl.Viewport(0, 0, width, height)
+	fb.oldHeight, boundHeight = boundHeight, height
 	fb.isOpen = true
 }
 
@@ -120,9 +140,19 @@ func (fb *Framebuffer) Close() {
 	}
 	engo.Gl.BindFrameBuffer(nil)
 	engo.Gl.Viewport(int(fb.oldVP[0]), int(fb.oldVP[1]), int(fb.oldVP[2]), int(fb.oldVP[3]))
+	boundHeight = fb.oldHeight
 	fb.isOpen = false
 }
 
+// Blit copies the width by height pixels at the bottom left of the framebuffer into dst, resolving the samples of
+// a multi-sampled framebuffer. Both must be closed.
+func (fb *Framebuffer) Blit(dst *Framebuffer, width, height int) {
+	engo.Gl.BindReadFrameBuffer(fb.fbo)
+	engo.Gl.BindDrawFrameBuffer(dst.fbo)
+	engo.Gl.BlitFrameBuffer(0, 0, width, height, 0, 0, width, height, engo.Gl.COLOR_BUFFER_BIT, engo.Gl.NEAREST)
+	engo.Gl.BindFrameBuffer(nil)
+}
+
 func (fb *Framebuffer) Destroy() {
 	engo.Gl.DeleteFrameBuffer(fb.fbo)
 }
@@ -0,0 +1,51 @@
+//go:build (darwin || linux || windows) && !ios && !android && !js && !sdl && !headless && !vulkan
+// +build darwin linux windows
+// +build !ios
+// +build !android
+// +build !js
+// +build !sdl
+// +build !headless
+// +build !vulkan
+
+package common
+
+import (
+	"strings"
+
+	"github.com/go-gl/gl/v2.1/gl"
+	"github.com/klopsch/engo"
+)
+
+// arbInstancer draws instanced with the ARB_draw_instanced and ARB_instanced_arrays extensions of OpenGL 2.1, which
+// the contexts of github.com/klopsch/gl don't bind. It calls go-gl directly, which the GLFW context is built on and
+// initialized by, so it's only built for the GLFW back end.
+type arbInstancer struct{}
+
+func (arbInstancer) DrawArraysInstanced(mode, first, count, instances int) {
+	gl.DrawArraysInstancedARB(uint32(mode), int32(first), int32(count), int32(instances))
+}
+
+func (arbInstancer) VertexAttribDivisor(index, divisor int) {
+	gl.VertexAttribDivisorARB(uint32(index), uint32(divisor))
+}
+
+// newInstancer returns the instanced drawing calls of the OpenGL context, or nil if its driver lacks the extensions.
+func newInstancer() glInstancer {
+	if engo.Headless() || engo.Gl == nil {
+		return nil
+	}
+	exts := glExtensions()
+	if !exts["GL_ARB_draw_instanced"] || !exts["GL_ARB_instanced_arrays"] {
+		return nil
+	}
+	return arbInstancer{}
+}
+
+// glExtensions returns the names of the extensions supported by the driver of the OpenGL context.
+func glExtensions() map[string]bool {
+	exts := make(map[string]bool)
+	for _, ext := range strings.Fields(gl.GoStr(gl.GetString(gl.EXTENSIONS))) {
+		exts[ext] = true
+	}
+	return exts
+}
@@ -0,0 +1,10 @@
+//go:build (!darwin && !linux && !windows) || ios || android || js || sdl || headless || vulkan
+// +build !darwin,!linux,!windows ios android js sdl headless vulkan
+
+package common
+
+// newInstancer returns nil, as instanced drawing is only bound for the GLFW back end. The InstancedShader draws
+// like the DefaultShader then.
+func newInstancer() glInstancer {
+	return nil
+}
@@ -0,0 +1,81 @@
+package common
+
+import (
+	"image/color"
+
+	"github.com/klopsch/engo"
+)
+
+// Mask clips the entities using it to the area of a SpaceComponent, such as the entities of a scrollable panel to the
+// panel. The area follows the SpaceComponent as it moves, and rotates with it, unlike a scissor rectangle. Masks are
+// drawn into the stencil buffer of the screen, so they don't clip entities drawn into a RenderTarget or post-processed.
+//
+// Entities sharing a Mask are drawn in a batch, and every change of Mask between consecutive entities draws the Mask
+// into the stencil buffer again, so give the entities of a Mask consecutive Z-Indices.
+type Mask struct {
+	// Space is the area the entities are clipped to, usually the SpaceComponent of the entity acting as the Mask.
+	Space *SpaceComponent
+	// Parent is the Mask this Mask is nested in. Entities using the Mask are clipped to both, so to the
+	// intersection of all the Masks up to the outermost one.
+	Parent *Mask
+	// HUD is whether the Mask is in the coordinates of the screen, like entities drawn by the HUD shaders, rather
+	// than moving with the camera.
+	HUD bool
+
+	ren RenderComponent
+}
+
+// NewMask creates a Mask clipping entities to the area of the SpaceComponent, nested in parent if it's not nil.
+func NewMask(space *SpaceComponent, parent *Mask) *Mask {
+	return &Mask{Space: space, Parent: parent}
+}
+
+// depth returns the number of Masks up to the outermost one, including this one.
+func (m *Mask) depth() int {
+	d := 0
+	for ; m != nil; m = m.Parent {
+		d++
+	}
+	return d
+}
+
+// chain returns the Masks up to the outermost one, from the outermost to this one.
+func (m *Mask) chain() []*Mask {
+	chain := make([]*Mask, m.depth())
+	for i := len(chain) - 1; m != nil; i, m = i-1, m.Parent {
+		chain[i] = m
+	}
+	return chain
+}
+
+// applyMask sets the stencil buffer so only the area of the Mask and the Masks it's nested in is drawn, or disables
+// the stencil test if m is nil. Each Mask increments the stencil of the pixels within all the Masks before it, so the
+// pixels within all of them end up at the depth of m. It must not be used between Pre and Post.
+func applyMask(m *Mask) {
+	if m == nil {
+		engo.Gl.Disable(engo.Gl.STENCIL_TEST)
+		return
+	}
+
+	engo.Gl.Enable(engo.Gl.STENCIL_TEST)
+	engo.Gl.Clear(engo.Gl.STENCIL_BUFFER_BIT)
+	engo.Gl.ColorMask(false, false, false, false)
+	engo.Gl.StencilOp(engo.Gl.KEEP, engo.Gl.KEEP, engo.Gl.INCR)
+	chain := m.chain()
+	for i, mask := range chain {
+		if mask.ren.Drawable == nil {
+			mask.ren = RenderComponent{Drawable: Rectangle{}, Color: color.White, Scale: engo.Point{X: 1, Y: 1}}
+		}
+		shader := LegacyShader
+		if mask.HUD {
+			shader = LegacyHUDShader
+		}
+		engo.Gl.StencilFunc(engo.Gl.EQUAL, i, 0xff)
+		shader.Pre()
+		shader.Draw(&mask.ren, mask.Space)
+		shader.Post()
+	}
+	engo.Gl.ColorMask(true, true, true, true)
+	engo.Gl.StencilOp(engo.Gl.KEEP, engo.Gl.KEEP, engo.Gl.KEEP)
+	engo.Gl.StencilFunc(engo.Gl.EQUAL, len(chain), 0xff)
+}
@@ -0,0 +1,472 @@
+//go:build offscreen
+// +build offscreen
+
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"runtime"
+	"testing"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+)
+
+// These tests render real frames, so they need an OpenGL driver and a display.
+// See engo.RunOptions.Offscreen for how to run them on CI.
+
+type offscreenScene struct {
+	w *ecs.World
+}
+
+func (*offscreenScene) Preload() {}
+
+func (s *offscreenScene) Setup(u engo.Updater) {
+	s.w, _ = u.(*ecs.World)
+	s.w.AddSystem(&RenderSystem{})
+}
+
+func (*offscreenScene) Type() string { return "offscreenScene" }
+
+func TestOffscreenRender(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	s := &offscreenScene{}
+	engo.Run(engo.RunOptions{
+		NoRun:     true,
+		Offscreen: true,
+		Width:     64,
+		Height:    64,
+	}, s)
+	defer engo.DestroyWindow()
+	SetBackground(color.Black)
+
+	basic := ecs.NewBasic()
+	for _, sys := range s.w.Systems() {
+		if rs, ok := sys.(*RenderSystem); ok {
+			rs.Add(&basic, &RenderComponent{
+				Drawable: Rectangle{},
+				Color:    color.RGBA{255, 0, 0, 255},
+			}, &SpaceComponent{Width: 32, Height: 64})
+		}
+	}
+	s.w.Update(1)
+
+	tests := []struct {
+		name     string
+		x, y     int
+		expected [4]uint8
+	}{
+		{"pixel covered by the rectangle", 16, 32, [4]uint8{255, 0, 0, 255}},
+		{"pixel showing the background", 48, 32, [4]uint8{0, 0, 0, 255}},
+	}
+	for _, test := range tests {
+		img, err := ReadPixels(test.x, test.y, 1, 1)
+		if err != nil {
+			t.Fatalf("%s unable to read pixels: %v", test.name, err)
+		}
+		got := [4]uint8{img.Pix[0], img.Pix[1], img.Pix[2], img.Pix[3]}
+		if got != test.expected {
+			t.Errorf("%s expected=%v ; got=%v", test.name, test.expected, got)
+		}
+	}
+
+	img, err := Screenshot()
+	if err != nil {
+		t.Fatalf("unable to take a screenshot: %v", err)
+	}
+	if img.Rect.Dx() != 64 || img.Rect.Dy() != 64 {
+		t.Errorf("screenshot should cover the window expected=%v ; got=%v", image.Rect(0, 0, 64, 64), img.Rect)
+	}
+	if got := img.RGBAAt(16, 32); got != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("screenshot pixel covered by the rectangle expected=%v ; got=%v", color.RGBA{255, 0, 0, 255}, got)
+	}
+}
+
+// BenchmarkRuntimeAtlasDrawCalls draws sprites cycling through 16 small images,
+// which breaks the batch on every sprite unless the images are packed.
+func BenchmarkRuntimeAtlasDrawCalls(b *testing.B) {
+	for _, packed := range []bool{false, true} {
+		name := "separate"
+		if packed {
+			name = "packed"
+		}
+		b.Run(name, func(b *testing.B) {
+			runtime.LockOSThread()
+			defer runtime.UnlockOSThread()
+
+			s := &offscreenScene{}
+			engo.Run(engo.RunOptions{
+				NoRun:     true,
+				Offscreen: true,
+				Width:     256,
+				Height:    256,
+			}, s)
+			defer engo.DestroyWindow()
+			defer func(atlas *RuntimeAtlas) { DefaultAtlas = atlas }(DefaultAtlas)
+			DefaultAtlas = &RuntimeAtlas{}
+
+			var textures []*Texture
+			for i := 0; i < 16; i++ {
+				url := fmt.Sprintf("atlas_bench_%s_%d.png", name, i)
+				if packed {
+					PackTextures(url)
+				}
+				buf := &bytes.Buffer{}
+				if err := png.Encode(buf, image.NewNRGBA(image.Rect(0, 0, 16, 16))); err != nil {
+					b.Fatal(err)
+				}
+				if err := engo.Files.LoadReaderData(url, buf); err != nil {
+					b.Fatal(err)
+				}
+				tex, err := LoadedSprite(url)
+				if err != nil {
+					b.Fatal(err)
+				}
+				textures = append(textures, tex)
+			}
+
+			for _, sys := range s.w.Systems() {
+				if rs, ok := sys.(*RenderSystem); ok {
+					for i := 0; i < 256; i++ {
+						basic := ecs.NewBasic()
+						rs.Add(&basic, &RenderComponent{Drawable: textures[i%len(textures)]}, &SpaceComponent{
+							Position: engo.Point{X: float32(i%16) * 16, Y: float32(i/16) * 16},
+						})
+					}
+				}
+			}
+
+			frameStats.DrawCalls = 0
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				s.w.Update(1)
+			}
+			b.ReportMetric(float64(frameStats.DrawCalls)/float64(b.N), "draws/frame")
+		})
+	}
+}
+
+// BenchmarkInstancedSprites draws 10k identical sprites with the batching
+// DefaultShader and the InstancedShader. The instanced run fails without
+// instancing support in the OpenGL context, as it would measure the batching
+// path again.
+func BenchmarkInstancedSprites(b *testing.B) {
+	for _, shader := range []struct {
+		name   string
+		shader Shader
+	}{
+		{"batched", DefaultShader},
+		{"instanced", InstancedShader},
+	} {
+		b.Run(shader.name, func(b *testing.B) {
+			runtime.LockOSThread()
+			defer runtime.UnlockOSThread()
+
+			s := &offscreenScene{}
+			engo.Run(engo.RunOptions{
+				NoRun:     true,
+				Offscreen: true,
+				Width:     512,
+				Height:    512,
+			}, s)
+			defer engo.DestroyWindow()
+			if shader.shader == InstancedShader && !InstancingSupported() {
+				b.Fatal("the OpenGL context doesn't support instanced drawing")
+			}
+
+			tex := NewTextureSingle(NewImageObject(image.NewNRGBA(image.Rect(0, 0, 4, 4))))
+			for _, sys := range s.w.Systems() {
+				if rs, ok := sys.(*RenderSystem); ok {
+					for i := 0; i < 10000; i++ {
+						basic := ecs.NewBasic()
+						rc := &RenderComponent{Drawable: tex}
+						rc.SetShader(shader.shader)
+						rs.Add(&basic, rc, &SpaceComponent{
+							Position: engo.Point{X: float32(i%100) * 5, Y: float32(i/100) * 5},
+						})
+					}
+				}
+			}
+
+			frameStats.DrawCalls = 0
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				s.w.Update(1)
+			}
+			b.ReportMetric(float64(frameStats.DrawCalls)/float64(b.N), "draws/frame")
+		})
+	}
+}
+
+func TestOffscreenInstancingSupported(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	s := &offscreenScene{}
+	engo.Run(engo.RunOptions{
+		NoRun:     true,
+		Offscreen: true,
+		Width:     64,
+		Height:    64,
+	}, s)
+	defer engo.DestroyWindow()
+
+	supported := newInstancer() != nil
+	if supported != InstancingSupported() {
+		t.Errorf("InstancingSupported() = %v, but newInstancer found support: %v", InstancingSupported(), supported)
+	}
+	if (InstancedShader.instancer != nil) != supported {
+		t.Errorf("the InstancedShader should only draw instanced when the context supports it, supported: %v", supported)
+	}
+}
+
+func TestOffscreenPostProcess(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	s := &offscreenScene{}
+	engo.Run(engo.RunOptions{
+		NoRun:     true,
+		Offscreen: true,
+		Width:     64,
+		Height:    64,
+	}, s)
+	defer engo.DestroyWindow()
+	SetBackground(color.Black)
+
+	basic := ecs.NewBasic()
+	for _, sys := range s.w.Systems() {
+		if rs, ok := sys.(*RenderSystem); ok {
+			rs.Add(&basic, &RenderComponent{
+				Drawable: Rectangle{},
+				Color:    color.RGBA{255, 0, 0, 255},
+			}, &SpaceComponent{Width: 64, Height: 64})
+
+			// The vignette has to darken the grayscale image, not the red one
+			if err := rs.AddPostProcess(NewGrayscalePostProcess(1)); err != nil {
+				t.Fatal(err)
+			}
+			if err := rs.AddPostProcess(NewVignettePostProcess(1, 0.5, 0.3)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	s.w.Update(1)
+
+	img, err := Screenshot()
+	if err != nil {
+		t.Fatalf("unable to take a screenshot: %v", err)
+	}
+	center := img.RGBAAt(32, 32)
+	if center.R != center.G || center.G != center.B || center.R < 70 || center.R > 82 {
+		t.Errorf("center should be the gray of red expected=%v ; got=%v", color.RGBA{76, 76, 76, 255}, center)
+	}
+	if corner := img.RGBAAt(0, 0); corner.R > 8 || corner.G > 8 || corner.B > 8 {
+		t.Errorf("corner should be darkened by the vignette expected=%v ; got=%v", color.RGBA{0, 0, 0, 255}, corner)
+	}
+}
+
+// BenchmarkTileMesh draws a layer of a 100×100 map as an entity per tile, and
+// baked into a TileMesh drawn by a single entity.
+func BenchmarkTileMesh(b *testing.B) {
+	for _, baked := range []bool{false, true} {
+		name := "entities"
+		if baked {
+			name = "baked"
+		}
+		b.Run(name, func(b *testing.B) {
+			runtime.LockOSThread()
+			defer runtime.UnlockOSThread()
+
+			s := &offscreenScene{}
+			engo.Run(engo.RunOptions{
+				NoRun:     true,
+				Offscreen: true,
+				Width:     512,
+				Height:    512,
+			}, s)
+			defer engo.DestroyWindow()
+
+			tex := NewTextureSingle(NewImageObject(image.NewNRGBA(image.Rect(0, 0, 16, 16))))
+			layer := &TileLayer{Visible: true, Opacity: 1}
+			for i := 0; i < 100*100; i++ {
+				layer.Tiles = append(layer.Tiles, &Tile{
+					Point: engo.Point{X: float32(i%100) * 16, Y: float32(i/100) * 16},
+					Image: &tex,
+				})
+			}
+
+			for _, sys := range s.w.Systems() {
+				if rs, ok := sys.(*RenderSystem); ok {
+					if baked {
+						meshes, _ := BakeTileLayer(layer)
+						for _, mesh := range meshes {
+							basic := ecs.NewBasic()
+							rc := layer.RenderComponent(mesh, 0)
+							rs.Add(&basic, &rc, &SpaceComponent{Position: mesh.Position})
+						}
+					} else {
+						for _, tile := range layer.Tiles {
+							basic := ecs.NewBasic()
+							rc := layer.RenderComponent(tile.Image, 0)
+							rs.Add(&basic, &rc, &SpaceComponent{Position: tile.Point})
+						}
+					}
+				}
+			}
+
+			frameStats.DrawCalls = 0
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				s.w.Update(1)
+			}
+			b.ReportMetric(float64(frameStats.DrawCalls)/float64(b.N), "draws/frame")
+		})
+	}
+}
+
+func TestOffscreenBackgroundGradient(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	s := &offscreenScene{}
+	engo.Run(engo.RunOptions{
+		NoRun:     true,
+		Offscreen: true,
+		Width:     64,
+		Height:    64,
+	}, s)
+	defer engo.DestroyWindow()
+	SetBackground(color.Black)
+	SetBackgroundGradient(color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255})
+	defer SetBackground(color.Black)
+
+	// The camera shouldn't move the background
+	for _, sys := range s.w.Systems() {
+		if cam, ok := sys.(*CameraSystem); ok {
+			cam.moveToX(500)
+			cam.moveToY(500)
+		}
+	}
+	s.w.Update(1)
+
+	img, err := Screenshot()
+	if err != nil {
+		t.Fatalf("unable to take a screenshot: %v", err)
+	}
+	top, bottom := img.RGBAAt(32, 0), img.RGBAAt(32, 63)
+	if top.R < 240 || top.B > 15 {
+		t.Errorf("top of the gradient should be red, got=%v", top)
+	}
+	if bottom.B < 240 || bottom.R > 15 {
+		t.Errorf("bottom of the gradient should be blue, got=%v", bottom)
+	}
+}
+
+func TestOffscreenBlendAdditive(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	s := &offscreenScene{}
+	engo.Run(engo.RunOptions{
+		NoRun:     true,
+		Offscreen: true,
+		Width:     64,
+		Height:    64,
+	}, s)
+	defer engo.DestroyWindow()
+	SetBackground(color.Black)
+
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{100, 100, 100, 255})
+	tex := NewTextureSingle(NewImageObject(img))
+	for _, sys := range s.w.Systems() {
+		if rs, ok := sys.(*RenderSystem); ok {
+			// Two additive sprites over the left half, and two normal ones over the right half
+			for i, mode := range []BlendMode{BlendAdditive, BlendAdditive, BlendNormal, BlendNormal} {
+				basic := ecs.NewBasic()
+				rs.Add(&basic, &RenderComponent{
+					Drawable:  tex,
+					Scale:     engo.Point{X: 32, Y: 64},
+					BlendMode: mode,
+				}, &SpaceComponent{Position: engo.Point{X: float32(i / 2 * 32)}, Width: 32, Height: 64})
+			}
+		}
+	}
+	s.w.Update(1)
+
+	shot, err := Screenshot()
+	if err != nil {
+		t.Fatalf("unable to take a screenshot: %v", err)
+	}
+	if additive := shot.RGBAAt(16, 32); additive.R < 195 || additive.R > 205 {
+		t.Errorf("additive sprites should accumulate their brightness, got=%v", additive)
+	}
+	if normal := shot.RGBAAt(48, 32); normal.R < 95 || normal.R > 105 {
+		t.Errorf("normal sprites should cover each other, got=%v", normal)
+	}
+}
+
+func TestOffscreenMask(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	s := &offscreenScene{}
+	engo.Run(engo.RunOptions{
+		NoRun:     true,
+		Offscreen: true,
+		Width:     64,
+		Height:    64,
+	}, s)
+	defer engo.DestroyWindow()
+	SetBackground(color.Black)
+
+	outer := NewMask(&SpaceComponent{Width: 32, Height: 32}, nil)
+	inner := NewMask(&SpaceComponent{Position: engo.Point{X: 16, Y: 16}, Width: 32, Height: 32}, outer)
+	for _, sys := range s.w.Systems() {
+		if rs, ok := sys.(*RenderSystem); ok {
+			for i, e := range []struct {
+				mask  *Mask
+				color color.Color
+			}{
+				{outer, color.RGBA{0, 255, 0, 255}},
+				{inner, color.RGBA{255, 0, 0, 255}},
+			} {
+				basic := ecs.NewBasic()
+				rs.Add(&basic, &RenderComponent{
+					Drawable:    Rectangle{},
+					Color:       e.color,
+					Mask:        e.mask,
+					StartZIndex: float32(i),
+				}, &SpaceComponent{Width: 64, Height: 64})
+			}
+		}
+	}
+	s.w.Update(1)
+
+	shot, err := Screenshot()
+	if err != nil {
+		t.Fatalf("unable to take a screenshot: %v", err)
+	}
+	tests := []struct {
+		name     string
+		x, y     int
+		expected color.RGBA
+	}{
+		{"pixel only within the outer mask", 8, 8, color.RGBA{0, 255, 0, 255}},
+		{"pixel within both masks", 24, 24, color.RGBA{255, 0, 0, 255}},
+		{"pixel only within the inner mask", 40, 40, color.RGBA{0, 0, 0, 255}},
+		{"pixel outside the masks", 56, 8, color.RGBA{0, 0, 0, 255}},
+	}
+	for _, test := range tests {
+		if got := shot.RGBAAt(test.x, test.y); got != test.expected {
+			t.Errorf("%s expected=%v ; got=%v", test.name, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package common
+
+import (
+	"log"
+
+	"github.com/klopsch/engo"
+	"github.com/klopsch/gl"
+)
+
+const postProcessVertexShader = `
+attribute vec2 in_Position;
+
+varying vec2 var_TexCoords;
+
+void main() {
+  var_TexCoords = in_Position * 0.5 + 0.5;
+  gl_Position = vec4(in_Position, 0.0, 1.0);
+}
+`
+
+// postProcessQuad covers the whole screen, as a triangle strip in clip space.
+var (
+	postProcessQuad    = []float32{-1, -1, 1, -1, -1, 1, 1, 1}
+	postProcessQuadVBO *gl.Buffer
+)
+
+// PostProcess is a full-screen effect applied to the frame after the RenderSystem has drawn all entities, such as
+// color grading or a vignette. Its fragment shader gets the frame drawn so far as the sampler2D uf_Texture, and the
+// position on screen as the vec2 var_TexCoords, going from (0, 0) in the bottom-left to (1, 1) in the top-right
+// corner.
+//
+// Multiple PostProcesses are applied in the order they're added, each one to the output of the previous one.
+type PostProcess struct {
+	fragment string
+	uniforms map[string]float32
+
+	program    *gl.Program
+	inPosition int
+	locations  map[string]*gl.UniformLocation
+}
+
+// NewPostProcess creates a PostProcess from the source of its fragment shader. It's compiled when it's added to a
+// RenderSystem.
+func NewPostProcess(fragment string) *PostProcess {
+	return &PostProcess{
+		fragment:  fragment,
+		uniforms:  make(map[string]float32),
+		locations: make(map[string]*gl.UniformLocation),
+	}
+}
+
+// SetUniform sets the float uniform with the given name in the fragment shader. It can be changed at any time, and
+// is used from the next frame on.
+func (p *PostProcess) SetUniform(name string, value float32) {
+	p.uniforms[name] = value
+}
+
+// Uniform returns the value of the uniform with the given name, as set with SetUniform.
+func (p *PostProcess) Uniform(name string) float32 {
+	return p.uniforms[name]
+}
+
+// setup compiles the shader of the PostProcess, if it's not compiled yet.
+func (p *PostProcess) setup() error {
+	if p.program != nil {
+		return nil
+	}
+	var err error
+	if p.program, err = LoadShader(postProcessVertexShader, p.fragment); err != nil {
+		return err
+	}
+	p.inPosition = engo.Gl.GetAttribLocation(p.program, "in_Position")
+
+	if postProcessQuadVBO == nil {
+		postProcessQuadVBO = engo.Gl.CreateBuffer()
+		engo.Gl.BindBuffer(engo.Gl.ARRAY_BUFFER, postProcessQuadVBO)
+		engo.Gl.BufferData(engo.Gl.ARRAY_BUFFER, postProcessQuad, engo.Gl.STATIC_DRAW)
+		engo.Gl.BindBuffer(engo.Gl.ARRAY_BUFFER, nil)
+	}
+	return nil
+}
+
+// apply draws the texture over the whole viewport through the shader.
+func (p *PostProcess) apply(tex *gl.Texture) {
+	engo.Gl.UseProgram(p.program)
+	engo.Gl.BindTexture(engo.Gl.TEXTURE_2D, tex)
+
+	for name, value := range p.uniforms {
+		loc, ok := p.locations[name]
+		if !ok {
+			loc = engo.Gl.GetUniformLocation(p.program, name)
+			p.locations[name] = loc
+		}
+		engo.Gl.Uniform1f(loc, value)
+	}
+
+	engo.Gl.BindBuffer(engo.Gl.ARRAY_BUFFER, postProcessQuadVBO)
+	engo.Gl.EnableVertexAttribArray(p.inPosition)
+	engo.Gl.VertexAttribPointer(p.inPosition, 2, engo.Gl.FLOAT, false, 8, 0)
+	engo.Gl.DrawArrays(engo.Gl.TRIANGLE_STRIP, 0, len(postProcessQuad)/2)
+	frameStats.DrawCalls++
+	engo.Gl.DisableVertexAttribArray(p.inPosition)
+
+	engo.Gl.BindBuffer(engo.Gl.ARRAY_BUFFER, nil)
+	engo.Gl.BindTexture(engo.Gl.TEXTURE_2D, nil)
+}
+
+// AddPostProcess applies the PostProcess to every frame the RenderSystem draws, after the PostProcesses added
+// before it. It returns an error if its shader doesn't compile.
+func (rs *RenderSystem) AddPostProcess(p *PostProcess) error {
+	if !engo.Headless() {
+		if err := p.setup(); err != nil {
+			return err
+		}
+	}
+	rs.postProcesses = append(rs.postProcesses, p)
+	return nil
+}
+
+// RemovePostProcess stops applying the PostProcess.
+func (rs *RenderSystem) RemovePostProcess(p *PostProcess) {
+	processes := rs.postProcesses[:0]
+	for _, pp := range rs.postProcesses {
+		if pp != p {
+			processes = append(processes, pp)
+		}
+	}
+	rs.postProcesses = processes
+}
+
+// beginPostProcess redirects drawing into the first post-processing buffer, if there are any PostProcesses. It
+// returns whether it did.
+func (rs *RenderSystem) beginPostProcess() bool {
+	if len(rs.postProcesses) == 0 {
+		return false
+	}
+
+	w, h := int(engo.CanvasWidth()), int(engo.CanvasHeight())
+	for i, buf := range rs.postBuffers {
+		if buf == nil {
+			rs.postBuffers[i] = NewRenderTarget(w, h)
+		} else {
+			buf.Resize(w, h)
+		}
+	}
+
+	rs.postBuffers[0].fb.Open(w, h)
+	rs.postBuffers[0].tex.Bind()
+	rs.openMultisampled(w, h)
+	return true
+}
+
+// multisampledBuffer is drawn into instead of the first post-processing buffer when MSAA is on, as textures can't be
+// multi-sampled. It's resolved into that buffer before the PostProcesses sample it.
+type multisampledBuffer struct {
+	rb *RenderBuffer
+	fb *Framebuffer
+}
+
+func (b *multisampledBuffer) destroy() {
+	b.fb.Destroy()
+	b.rb.Destroy()
+}
+
+// openMultisampled redirects drawing into the multi-sampled buffer, creating it when needed, if MSAA is on. WebGL 1
+// can't resolve multi-sampled buffers, so there the post-processing buffer is drawn into directly, as it is when the
+// buffer couldn't be created.
+func (rs *RenderSystem) openMultisampled(w, h int) {
+	if engo.MSAA() <= 1 || engo.CurrentBackEnd == engo.BackEndWeb || rs.postMSAAFailed {
+		return
+	}
+	if rs.postMSAA != nil && (rs.postMSAA.rb.width != w || rs.postMSAA.rb.height != h) {
+		rs.postMSAA.destroy()
+		rs.postMSAA = nil
+	}
+	if rs.postMSAA == nil {
+		rb := CreateMultisampleRenderBuffer(w, h, engo.MSAA())
+		if err := engo.Gl.GetError(); err != 0 {
+			log.Printf("[WARNING] Unable to create a multi-sampled buffer, post-processes sample the frame without anti-aliasing. GL error: %d", err)
+			rb.Destroy()
+			rs.postMSAAFailed = true
+			return
+		}
+		rs.postMSAA = &multisampledBuffer{rb: rb, fb: CreateFramebuffer()}
+	}
+	rs.postMSAA.fb.Open(w, h)
+	rs.postMSAA.rb.Bind(engo.Gl.COLOR_ATTACHMENT0)
+}
+
+// endPostProcess applies the PostProcesses one after the other, switching between the two buffers, and draws the
+// result of the last one to the screen.
+func (rs *RenderSystem) endPostProcess() {
+	w, h := int(engo.CanvasWidth()), int(engo.CanvasHeight())
+	src := rs.postBuffers[0]
+	multisampled := rs.postMSAA != nil && rs.postMSAA.fb.isOpen
+	if multisampled {
+		rs.postMSAA.fb.Close()
+	}
+	src.fb.Close()
+	// Both buffers are closed before resolving the samples, as Blit requires
+	if multisampled {
+		rs.postMSAA.fb.Blit(src.fb, w, h)
+	}
+
+	for i, p := range rs.postProcesses {
+		if i == len(rs.postProcesses)-1 {
+			p.apply(src.Texture())
+			break
+		}
+		dst := rs.postBuffers[(i+1)%2]
+		dst.fb.Open(w, h)
+		dst.tex.Bind()
+		p.apply(src.Texture())
+		dst.fb.Close()
+		src = dst
+	}
+}
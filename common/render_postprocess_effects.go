@@ -0,0 +1,84 @@
+package common
+
+// IntensityUniform is the uniform controlling the strength of the built-in PostProcess effects, from 0 for no effect
+// to 1 for the full effect. Change it with SetUniform.
+const IntensityUniform = "uf_Intensity"
+
+const postProcessHeader = `
+#ifdef GL_ES
+precision mediump float;
+#endif
+
+varying vec2 var_TexCoords;
+
+uniform sampler2D uf_Texture;
+uniform float uf_Intensity;
+`
+
+const (
+	grayscaleFragmentShader = postProcessHeader + `
+void main() {
+  vec4 color = texture2D(uf_Texture, var_TexCoords);
+  float gray = dot(color.rgb, vec3(0.299, 0.587, 0.114));
+  gl_FragColor = vec4(mix(color.rgb, vec3(gray), uf_Intensity), color.a);
+}`
+
+	sepiaFragmentShader = postProcessHeader + `
+void main() {
+  vec4 color = texture2D(uf_Texture, var_TexCoords);
+  vec3 sepia = vec3(
+    dot(color.rgb, vec3(0.393, 0.769, 0.189)),
+    dot(color.rgb, vec3(0.349, 0.686, 0.168)),
+    dot(color.rgb, vec3(0.272, 0.534, 0.131)));
+  gl_FragColor = vec4(mix(color.rgb, min(sepia, 1.0), uf_Intensity), color.a);
+}`
+
+	invertFragmentShader = postProcessHeader + `
+void main() {
+  vec4 color = texture2D(uf_Texture, var_TexCoords);
+  gl_FragColor = vec4(mix(color.rgb, 1.0 - color.rgb, uf_Intensity), color.a);
+}`
+
+	vignetteFragmentShader = postProcessHeader + `
+uniform float uf_Radius;
+uniform float uf_Softness;
+
+void main() {
+  vec4 color = texture2D(uf_Texture, var_TexCoords);
+  float dist = distance(var_TexCoords, vec2(0.5)) * 1.41421356;
+  float vignette = 1.0 - smoothstep(uf_Radius, uf_Radius + uf_Softness, dist);
+  gl_FragColor = vec4(color.rgb * mix(1.0, vignette, uf_Intensity), color.a);
+}`
+)
+
+// NewGrayscalePostProcess creates a PostProcess that removes the colors from the screen.
+func NewGrayscalePostProcess(intensity float32) *PostProcess {
+	p := NewPostProcess(grayscaleFragmentShader)
+	p.SetUniform(IntensityUniform, intensity)
+	return p
+}
+
+// NewSepiaPostProcess creates a PostProcess that tints the screen brown, like an old photograph.
+func NewSepiaPostProcess(intensity float32) *PostProcess {
+	p := NewPostProcess(sepiaFragmentShader)
+	p.SetUniform(IntensityUniform, intensity)
+	return p
+}
+
+// NewInvertPostProcess creates a PostProcess that inverts the colors of the screen.
+func NewInvertPostProcess(intensity float32) *PostProcess {
+	p := NewPostProcess(invertFragmentShader)
+	p.SetUniform(IntensityUniform, intensity)
+	return p
+}
+
+// NewVignettePostProcess creates a PostProcess that darkens the corners of the screen. The vignette starts fading in
+// at radius, relative to the distance from the center to the corners, and is completely dark softness further out.
+// Both can be changed later as the "uf_Radius" and "uf_Softness" uniforms.
+func NewVignettePostProcess(intensity, radius, softness float32) *PostProcess {
+	p := NewPostProcess(vignetteFragmentShader)
+	p.SetUniform(IntensityUniform, intensity)
+	p.SetUniform("uf_Radius", radius)
+	p.SetUniform("uf_Softness", softness)
+	return p
+}
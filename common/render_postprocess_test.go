@@ -0,0 +1,43 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostProcessChain(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &CameraTestScene{})
+
+	gray := NewGrayscalePostProcess(1)
+	vignette := NewVignettePostProcess(0.5, 0.75, 0.25)
+	invert := NewInvertPostProcess(1)
+
+	rs := &RenderSystem{}
+	assert.NoError(t, rs.AddPostProcess(gray))
+	assert.NoError(t, rs.AddPostProcess(vignette))
+	assert.NoError(t, rs.AddPostProcess(invert))
+	assert.Equal(t, []*PostProcess{gray, vignette, invert}, rs.postProcesses, "PostProcesses should be applied in the order they're added")
+
+	rs.RemovePostProcess(vignette)
+	assert.Equal(t, []*PostProcess{gray, invert}, rs.postProcesses)
+	rs.RemovePostProcess(vignette)
+	assert.Len(t, rs.postProcesses, 2, "Removing a PostProcess twice should do nothing")
+}
+
+func TestPostProcessUniforms(t *testing.T) {
+	p := NewSepiaPostProcess(0.25)
+	assert.Equal(t, float32(0.25), p.Uniform(IntensityUniform))
+
+	p.SetUniform(IntensityUniform, 0.75)
+	assert.Equal(t, float32(0.75), p.Uniform(IntensityUniform))
+
+	v := NewVignettePostProcess(1, 0.5, 0.2)
+	assert.Equal(t, float32(0.5), v.Uniform("uf_Radius"))
+	assert.Equal(t, float32(0.2), v.Uniform("uf_Softness"))
+	assert.Equal(t, float32(0), v.Uniform("uf_Missing"), "Unset uniforms should be 0")
+}
@@ -29,18 +29,42 @@ var (
 	TextShader = &textShader{cameraEnabled: true}
 	// TextHUDShader is the shader used to draw fonts from a FontAtlas on the HUD.
 	TextHUDShader = &textShader{cameraEnabled: false}
+	// SDFTextShader is the shader used to draw signed distance field fonts, which are Fonts with an SDF style set.
+	SDFTextShader = &sdfTextShader{textShader: &textShader{cameraEnabled: true}}
+	// SDFTextHUDShader is the shader used to draw signed distance field fonts on the HUD.
+	SDFTextHUDShader = &sdfTextShader{textShader: &textShader{cameraEnabled: false}}
 	// BlendmapShader is a shader used to create blendmaps
 	BlendmapShader = &blendmapShader{cameraEnabled: true}
-	shadersSet     bool
-	atlasCache     = make(map[Font]FontAtlas)
-	shaders        = []Shader{
+	// InstancedShader draws large numbers of entities sharing the same drawable, such as tiles or particles, with
+	// instanced draw calls where the OpenGL context supports them. Otherwise it batches like the DefaultShader, see
+	// InstancingSupported.
+	InstancedShader = &instancedShader{basicShader: &basicShader{cameraEnabled: true}}
+	// DissolveShader draws sprites dissolving into noise, such as for death effects. How far each entity has
+	// dissolved is set with RenderComponent.SetUniform(DissolveThreshold, threshold).
+	DissolveShader = &dissolveShader{basicShader: &basicShader{cameraEnabled: true}}
+	// OutlineShader draws sprites with a colored outline around their opaque pixels, such as to highlight a
+	// selection. Set its color and thickness with SetOutline. Entities using it are not batched.
+	OutlineShader = &outlineShader{basicShader: &basicShader{cameraEnabled: true}}
+	// LightingShader draws sprites lit by the lights of the LightSystem, using the NormalMap of their
+	// RenderComponent. Without any lights, it draws them unlit like the DefaultShader.
+	LightingShader = &lightingShader{basicShader: &basicShader{cameraEnabled: true}}
+
+	shadersSet bool
+	atlasCache = make(map[Font]FontAtlas)
+	shaders    = []Shader{
 		DefaultShader,
 		HUDShader,
 		LegacyShader,
 		LegacyHUDShader,
 		TextShader,
 		TextHUDShader,
+		SDFTextShader,
+		SDFTextHUDShader,
 		BlendmapShader,
+		InstancedShader,
+		DissolveShader,
+		OutlineShader,
+		LightingShader,
 	}
 )
 
@@ -86,9 +110,17 @@ func setBufferValue(buffer []float32, index int, value float32, changed *bool) {
 	}
 }
 
-// colorToFloat32 returns the float32 representation of the given color
-func colorToFloat32(c color.Color) float32 {
-	colorR, colorG, colorB, colorA := c.RGBA()
+// tintToFloat32 returns the float32 representation of the given color, with
+// its alpha multiplied by opacity. The opacity is clamped to [0, 1]. The color
+// is converted to linear space when rendering in linear space.
+func tintToFloat32(c color.Color, opacity float32) float32 {
+	colorR, colorG, colorB, colorA := linearColor(c).RGBA()
+	if opacity < 1 {
+		if opacity < 0 {
+			opacity = 0
+		}
+		colorA = uint32(float32(colorA) * opacity)
+	}
 	colorR >>= 8
 	colorG >>= 8
 	colorB >>= 8
@@ -102,6 +134,16 @@ func colorToFloat32(c color.Color) float32 {
 	return math.Float32frombits((alpha | blue | green | red) & 0xfeffffff)
 }
 
+// colorComponents returns the non-premultiplied components of c between 0 and 1. A nil
+// color is transparent.
+func colorComponents(c color.Color) (r, g, b, a float32) {
+	if c == nil {
+		return 0, 0, 0, 0
+	}
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return float32(n.R) / 255, float32(n.G) / 255, float32(n.B) / 255, float32(n.A) / 255
+}
+
 // AddShader adds a shader to the list of shaders for initalization. They should
 // be added before the Rendersystem is added, such as in the scene's Preload.
 func AddShader(s Shader) {
@@ -133,31 +175,71 @@ func initShaders(w *ecs.World) error {
 // It will log possible compilation errors
 func LoadShader(vertSrc, fragSrc string) (*gl.Program, error) {
 	vertShader := engo.Gl.CreateShader(engo.Gl.VERTEX_SHADER)
+	defer engo.Gl.DeleteShader(vertShader)
 	engo.Gl.ShaderSource(vertShader, vertSrc)
 	engo.Gl.CompileShader(vertShader)
 	if !engo.Gl.GetShaderiv(vertShader, engo.Gl.COMPILE_STATUS) {
 		errorLog := engo.Gl.GetShaderInfoLog(vertShader)
 		return nil, VertexShaderCompilationError{errorLog}
 	}
-	defer engo.Gl.DeleteShader(vertShader)
 
 	fragShader := engo.Gl.CreateShader(engo.Gl.FRAGMENT_SHADER)
+	defer engo.Gl.DeleteShader(fragShader)
 	engo.Gl.ShaderSource(fragShader, fragSrc)
 	engo.Gl.CompileShader(fragShader)
 	if !engo.Gl.GetShaderiv(fragShader, engo.Gl.COMPILE_STATUS) {
 		errorLog := engo.Gl.GetShaderInfoLog(fragShader)
 		return nil, FragmentShaderCompilationError{errorLog}
 	}
-	defer engo.Gl.DeleteShader(fragShader)
 
 	program := engo.Gl.CreateProgram()
 	engo.Gl.AttachShader(program, vertShader)
 	engo.Gl.AttachShader(program, fragShader)
 	engo.Gl.LinkProgram(program)
+	if !engo.Gl.GetProgramiv(program, engo.Gl.LINK_STATUS) {
+		errorLog := engo.Gl.GetProgramInfoLog(program)
+		engo.Gl.DeleteProgram(program)
+		return nil, ShaderLinkError{errorLog}
+	}
 
 	return program, nil
 }
 
+// ReloadableShader is a Shader whose OpenGL program can be replaced while the game is running, with ReloadShader.
+// All the shaders of this package are.
+type ReloadableShader interface {
+	Shader
+	// SetProgram replaces the program of the shader with the given one, which is compiled and linked, and looks
+	// up its attributes and uniforms. It returns the program it replaced.
+	SetProgram(*gl.Program) *gl.Program
+}
+
+// ReloadShader compiles and links a new program for the shader from the given sources, such as edited versions of
+// its GLSL, and swaps it in so the next frame is drawn with it. This allows tweaking shaders without restarting the
+// game. The new program has to use the same attributes and uniforms as the one it replaces.
+//
+// If the sources don't compile or link, the error is returned and the shader keeps drawing with its previous
+// program. It has to be called on the OpenGL thread, such as from the Update of a System, after the shader was set
+// up. It does nothing while headless.
+func ReloadShader(s Shader, vertSrc, fragSrc string) error {
+	r, ok := s.(ReloadableShader)
+	if !ok {
+		return fmt.Errorf("shader %T can't be reloaded, as it doesn't implement ReloadableShader", s)
+	}
+	if engo.Headless() {
+		return nil
+	}
+
+	program, err := LoadShader(vertSrc, fragSrc)
+	if err != nil {
+		return err
+	}
+	if prev := r.SetProgram(program); prev != nil {
+		engo.Gl.DeleteProgram(prev)
+	}
+	return nil
+}
+
 func newCamera(w *ecs.World) {
 	shaderInitMutex.Lock()
 	defer shaderInitMutex.Unlock()
@@ -177,6 +259,18 @@ func newCamera(w *ecs.World) {
 	}
 }
 
+// viewSize returns the width and height of the area that is visible in the
+// current render pass, before applying the camera zoom.
+func viewSize() (float32, float32) {
+	var w, h float32
+	if engo.ScaleOnResize() {
+		w, h = engo.GameWidth(), engo.GameHeight()
+	} else {
+		w, h = engo.CanvasWidth()/engo.CanvasScale(), engo.CanvasHeight()/engo.CanvasScale()
+	}
+	return w * viewportScale.X, h * viewportScale.Y
+}
+
 // VertexShaderCompilationError is returned whenever the `LoadShader` method was unable to compile your Vertex-shader (GLSL)
 type VertexShaderCompilationError struct {
 	OpenGLError string
@@ -196,3 +290,14 @@ type FragmentShaderCompilationError struct {
 func (f FragmentShaderCompilationError) Error() string {
 	return fmt.Sprintf("an error occurred compiling the fragment shader: %s", strings.Trim(f.OpenGLError, "\r\n"))
 }
+
+// ShaderLinkError is returned whenever the `LoadShader` method was unable to link your shaders into a program, such
+// as when the varyings of the Vertex-shader and the Fragment-shader don't match
+type ShaderLinkError struct {
+	OpenGLError string
+}
+
+// Error implements the error interface.
+func (l ShaderLinkError) Error() string {
+	return fmt.Sprintf("an error occurred linking the shaders: %s", strings.Trim(l.OpenGLError, "\r\n"))
+}
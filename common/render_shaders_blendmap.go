@@ -163,6 +163,18 @@ func (s *blendmapShader) Setup(w *ecs.World) error {
 	engo.Gl.BindBuffer(engo.Gl.ELEMENT_ARRAY_BUFFER, s.indexBuffer)
 	engo.Gl.BufferData(engo.Gl.ELEMENT_ARRAY_BUFFER, s.indices, engo.Gl.STATIC_DRAW)
 
+	s.locations()
+
+	s.projectionMatrix = engo.IdentityMatrix()
+	s.viewMatrix = engo.IdentityMatrix()
+	s.modelMatrix = engo.IdentityMatrix()
+	s.cullingMatrix = engo.IdentityMatrix()
+
+	return nil
+}
+
+// locations looks up the attributes and uniforms of the program.
+func (s *blendmapShader) locations() {
 	s.inPosition = engo.Gl.GetAttribLocation(s.program, "in_Position")
 	s.inTexCoords = engo.Gl.GetAttribLocation(s.program, "in_TexCoords")
 	s.inColor = engo.Gl.GetAttribLocation(s.program, "in_Color")
@@ -179,13 +191,14 @@ func (s *blendmapShader) Setup(w *ecs.World) error {
 	s.uf_scaleR = engo.Gl.GetUniformLocation(s.program, "uf_scaleR")
 	s.uf_scaleG = engo.Gl.GetUniformLocation(s.program, "uf_scaleG")
 	s.uf_scaleB = engo.Gl.GetUniformLocation(s.program, "uf_scaleB")
+}
 
-	s.projectionMatrix = engo.IdentityMatrix()
-	s.viewMatrix = engo.IdentityMatrix()
-	s.modelMatrix = engo.IdentityMatrix()
-	s.cullingMatrix = engo.IdentityMatrix()
-
-	return nil
+// SetProgram replaces the program of the shader, and returns the previous one.
+func (s *blendmapShader) SetProgram(program *gl.Program) *gl.Program {
+	prev := s.program
+	s.program = program
+	s.locations()
+	return prev
 }
 
 func (s *blendmapShader) Pre() {
@@ -221,11 +234,8 @@ func (s *blendmapShader) Pre() {
 func (s *blendmapShader) PrepareCulling() {
 	// (Re)initialize the projection matrix.
 	s.projectionMatrix.Identity()
-	if engo.ScaleOnResize() {
-		s.projectionMatrix.Scale(1/(engo.GameWidth()/2), 1/(-engo.GameHeight()/2))
-	} else {
-		s.projectionMatrix.Scale(1/(engo.CanvasWidth()/(2*engo.CanvasScale())), 1/(-engo.CanvasHeight()/(2*engo.CanvasScale())))
-	}
+	viewW, viewH := viewSize()
+	s.projectionMatrix.Scale(1/(viewW/2), 1/(-viewH/2))
 	// (Re)initialize the view matrix
 	s.viewMatrix.Identity()
 	if s.cameraEnabled {
@@ -396,6 +406,8 @@ func (s *blendmapShader) flush() {
 	// We only want to draw the indicies up to the number of sprites in the current batch.
 	count := s.idx / 20 * 6
 	engo.Gl.DrawElements(engo.Gl.TRIANGLES, count, engo.Gl.UNSIGNED_SHORT, 0)
+	frameStats.DrawCalls++
+	frameStats.Batches++
 	s.idx = 0
 	// We need to reset the vertex buffer so that when we start drawing again, we don't accidentally use junk data.
 	// The "simpler" way to do this would be to just create a new slice with make(), however that would cause the
@@ -418,11 +430,16 @@ func (s *blendmapShader) makeModelMatrix(ren *RenderComponent, space *SpaceCompo
 	// Instead of creating a new model matrix every time, we instead store a global one as a struct member
 	// and just reset it for every sprite. This prevents us from allocating a bunch of new Matrix instances in memory
 	// ultimately saving on GC activity.
-	s.modelMatrix.Identity().Scale(engo.GetGlobalScale().X, engo.GetGlobalScale().Y).Translate(space.Position.X, space.Position.Y)
+	w, h := ren.Drawable.Width(), ren.Drawable.Height()
+	p := pivot(ren, space, w*ren.Scale.X, h*ren.Scale.Y)
+	s.modelMatrix.Identity().Scale(engo.GetGlobalScale().X, engo.GetGlobalScale().Y).Translate(p.X, p.Y)
 	if space.Rotation != 0 {
 		s.modelMatrix.Rotate(space.Rotation)
 	}
 	s.modelMatrix.Scale(ren.Scale.X, ren.Scale.Y)
+	if ren.Origin != (engo.Point{}) {
+		s.modelMatrix.Translate(-ren.Origin.X*w, -ren.Origin.Y*h)
+	}
 	return s.modelMatrix
 }
 
@@ -432,14 +449,14 @@ func (s *blendmapShader) generateBufferContent(ren *RenderComponent, space *Spac
 	w := ren.Drawable.Width()
 	h := ren.Drawable.Height()
 
-	tint := colorToFloat32(ren.Color)
+	tint := tintToFloat32(ren.Color, ren.Opacity)
 
 	u, v, u2, v2 := ren.Drawable.View()
 
 	if ren.Repeat != NoRepeat {
 		u2 = space.Width / (ren.Drawable.Width() * ren.Scale.X)
 		w *= u2
-		v2 = space.Width / (ren.Drawable.Height() * ren.Scale.Y)
+		v2 = space.Height / (ren.Drawable.Height() * ren.Scale.Y)
 		h *= v2
 	}
 
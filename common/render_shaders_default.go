@@ -51,6 +51,13 @@ const (
 type basicShader struct {
 	BatchSize int
 
+	// fragmentShader replaces the defaultFragmentShader, for shaders which
+	// batch sprites the same way but color them differently.
+	fragmentShader string
+	// vertexShader replaces the defaultVertexShader, for shaders which need
+	// more of the vertices in their fragmentShader.
+	vertexShader string
+
 	indices     []uint16
 	indexBuffer *gl.Buffer
 	program     *gl.Program
@@ -110,7 +117,14 @@ func (s *basicShader) Setup(w *ecs.World) error {
 		s.indices[i+5] = uint16(j + 3)
 	}
 	var err error
-	s.program, err = LoadShader(defaultVertexShader, defaultFragmentShader)
+	vertex, fragment := defaultVertexShader, defaultFragmentShader
+	if s.vertexShader != "" {
+		vertex = s.vertexShader
+	}
+	if s.fragmentShader != "" {
+		fragment = s.fragmentShader
+	}
+	s.program, err = LoadShader(vertex, fragment)
 	if err != nil {
 		return err
 	}
@@ -118,11 +132,7 @@ func (s *basicShader) Setup(w *ecs.World) error {
 	engo.Gl.BindBuffer(engo.Gl.ELEMENT_ARRAY_BUFFER, s.indexBuffer)
 	engo.Gl.BufferData(engo.Gl.ELEMENT_ARRAY_BUFFER, s.indices, engo.Gl.STATIC_DRAW)
 
-	s.inPosition = engo.Gl.GetAttribLocation(s.program, "in_Position")
-	s.inTexCoords = engo.Gl.GetAttribLocation(s.program, "in_TexCoords")
-	s.inColor = engo.Gl.GetAttribLocation(s.program, "in_Color")
-
-	s.matrixProjView = engo.Gl.GetUniformLocation(s.program, "matrixProjView")
+	s.locations()
 
 	s.projectionMatrix = engo.IdentityMatrix()
 	s.viewMatrix = engo.IdentityMatrix()
@@ -135,6 +145,24 @@ func (s *basicShader) Setup(w *ecs.World) error {
 	return nil
 }
 
+// locations looks up the attributes and uniforms of the program.
+func (s *basicShader) locations() {
+	s.inPosition = engo.Gl.GetAttribLocation(s.program, "in_Position")
+	s.inTexCoords = engo.Gl.GetAttribLocation(s.program, "in_TexCoords")
+	s.inColor = engo.Gl.GetAttribLocation(s.program, "in_Color")
+
+	s.matrixProjView = engo.Gl.GetUniformLocation(s.program, "matrixProjView")
+}
+
+// SetProgram replaces the program of the shader, and returns the previous one.
+func (s *basicShader) SetProgram(program *gl.Program) *gl.Program {
+	prev := s.program
+	s.program = program
+	s.locations()
+	s.projViewChange = true
+	return prev
+}
+
 func (s *basicShader) Pre() {
 	engo.Gl.Enable(engo.Gl.BLEND)
 	engo.Gl.BlendFunc(engo.Gl.SRC_ALPHA, engo.Gl.ONE_MINUS_SRC_ALPHA)
@@ -166,11 +194,8 @@ func (s *basicShader) PrepareCulling() {
 	s.projViewChange = true
 	// (Re)initialize the projection matrix.
 	s.projectionMatrix.Identity()
-	if engo.ScaleOnResize() {
-		s.projectionMatrix.Scale(1/(engo.GameWidth()/2), 1/(-engo.GameHeight()/2))
-	} else {
-		s.projectionMatrix.Scale(1/(engo.CanvasWidth()/(2*engo.CanvasScale())), 1/(-engo.CanvasHeight()/(2*engo.CanvasScale())))
-	}
+	viewW, viewH := viewSize()
+	s.projectionMatrix.Scale(1/(viewW/2), 1/(-viewH/2))
 	// (Re)initialize the view matrix
 	s.viewMatrix.Identity()
 	if s.cameraEnabled {
@@ -186,13 +211,7 @@ func (s *basicShader) PrepareCulling() {
 }
 
 func (s *basicShader) ShouldDraw(rc *RenderComponent, sc *SpaceComponent) bool {
-	tsc := SpaceComponent{
-		Position: sc.Position,
-		Width:    rc.Drawable.Width() * rc.Scale.X,
-		Height:   rc.Drawable.Height() * rc.Scale.Y,
-		Rotation: sc.Rotation,
-	}
-
+	tsc := drawnSpace(rc, sc)
 	c := tsc.Corners()
 	c[0].MultiplyMatrixVector(s.cullingMatrix)
 	c[1].MultiplyMatrixVector(s.cullingMatrix)
@@ -264,11 +283,111 @@ func (s *basicShader) Draw(ren *RenderComponent, space *SpaceComponent) {
 		s.lastMinFilter = ren.minFilter
 	}
 
+	// NinePatches, TileMeshes, Skeletons and Meshes consist of multiple sprites
+	switch d := ren.Drawable.(type) {
+	case *NinePatch:
+		s.drawNinePatch(d, ren, space)
+		return
+	case *TileMesh:
+		s.drawQuads(d.vertices, ren, space)
+		return
+	case *Skeleton:
+		s.drawQuads(d.vertices, ren, space)
+		return
+	case *Mesh:
+		s.drawQuads(d.vertices, ren, space)
+		return
+	}
+
 	// Update the vertex buffer data.
 	s.updateBuffer(ren, space)
 	s.idx += 20
 }
 
+// drawnSpace returns the space the Drawable of the entity is drawn in, which is the size of the Drawable after
+// applying the scale rather than the size of the SpaceComponent.
+func drawnSpace(rc *RenderComponent, sc *SpaceComponent) SpaceComponent {
+	tsc := SpaceComponent{
+		Position: sc.Position,
+		Width:    rc.Drawable.Width() * rc.Scale.X,
+		Height:   rc.Drawable.Height() * rc.Scale.Y,
+		Rotation: sc.Rotation,
+		pivot:    rc.Origin,
+	}
+	if _, ok := rc.Drawable.(*NinePatch); ok {
+		// NinePatches are stretched to the SpaceComponent
+		tsc.Width, tsc.Height = sc.Width, sc.Height
+	}
+	return tsc
+}
+
+// isSingleQuad returns whether the entity is drawn as a single quad of its Drawable, which isn't the case for
+// repeating textures, NinePatches, TileMeshes, Skeletons and Meshes.
+func isSingleQuad(ren *RenderComponent) bool {
+	switch ren.Drawable.(type) {
+	case *NinePatch, *TileMesh, *Skeleton, *Mesh:
+		return false
+	}
+	return ren.Repeat == NoRepeat
+}
+
+// drawNinePatch adds each non-empty region of the NinePatch to the batch as a separate sprite.
+func (s *basicShader) drawNinePatch(np *NinePatch, ren *RenderComponent, space *SpaceComponent) {
+	ren.Buffer = s.vertexBuffer
+
+	tint := tintToFloat32(ren.Color, ren.Opacity)
+	size := ninePatchSize(ren, space)
+	modelMatrix := s.makeModelMatrix(ren, space)
+
+	for _, r := range np.regions(size.X, size.Y) {
+		if r.x == r.x2 || r.y == r.y2 {
+			continue
+		}
+		if s.idx == len(s.vertices) {
+			s.flush()
+		}
+
+		buffer := s.vertices[s.idx : s.idx+20]
+		buffer[0], buffer[1], buffer[2], buffer[3], buffer[4] = r.x, r.y, r.u, r.v, tint
+		buffer[5], buffer[6], buffer[7], buffer[8], buffer[9] = r.x2, r.y, r.u2, r.v, tint
+		buffer[10], buffer[11], buffer[12], buffer[13], buffer[14] = r.x2, r.y2, r.u2, r.v2, tint
+		buffer[15], buffer[16], buffer[17], buffer[18], buffer[19] = r.x, r.y2, r.u, r.v2, tint
+
+		s.multModel(modelMatrix, buffer[:2])
+		s.multModel(modelMatrix, buffer[5:7])
+		s.multModel(modelMatrix, buffer[10:12])
+		s.multModel(modelMatrix, buffer[15:17])
+		s.idx += 20
+	}
+}
+
+// drawQuads adds each quad of the vertices, laid out like the tiles of a TileMesh, to the batch as a separate sprite.
+func (s *basicShader) drawQuads(vertices []float32, ren *RenderComponent, space *SpaceComponent) {
+	ren.Buffer = s.vertexBuffer
+
+	tint := tintToFloat32(ren.Color, ren.Opacity)
+	modelMatrix := s.makeModelMatrix(ren, space)
+
+	for i := 0; i < len(vertices); i += tileMeshVertices {
+		if s.idx == len(s.vertices) {
+			s.flush()
+		}
+
+		v := vertices[i : i+tileMeshVertices]
+		buffer := s.vertices[s.idx : s.idx+20]
+		buffer[0], buffer[1], buffer[2], buffer[3], buffer[4] = v[0], v[1], v[2], v[3], tint
+		buffer[5], buffer[6], buffer[7], buffer[8], buffer[9] = v[4], v[5], v[6], v[7], tint
+		buffer[10], buffer[11], buffer[12], buffer[13], buffer[14] = v[8], v[9], v[10], v[11], tint
+		buffer[15], buffer[16], buffer[17], buffer[18], buffer[19] = v[12], v[13], v[14], v[15], tint
+
+		s.multModel(modelMatrix, buffer[:2])
+		s.multModel(modelMatrix, buffer[5:7])
+		s.multModel(modelMatrix, buffer[10:12])
+		s.multModel(modelMatrix, buffer[15:17])
+		s.idx += 20
+	}
+}
+
 func (s *basicShader) Post() {
 	s.flush()
 	s.setTexture(nil)
@@ -302,6 +421,8 @@ func (s *basicShader) flush() {
 	// We only want to draw the indicies up to the number of sprites in the current batch.
 	count := s.idx / 20 * 6
 	engo.Gl.DrawElements(engo.Gl.TRIANGLES, count, engo.Gl.UNSIGNED_SHORT, 0)
+	frameStats.DrawCalls++
+	frameStats.Batches++
 	s.idx = 0
 	// We need to reset the vertex buffer so that when we start drawing again, we don't accidentally use junk data.
 	// The "simpler" way to do this would be to just create a new slice with make(), however that would cause the
@@ -324,21 +445,25 @@ func (s *basicShader) makeModelMatrix(ren *RenderComponent, space *SpaceComponen
 	// Instead of creating a new model matrix every time, we instead store a global one as a struct member
 	// and just reset it for every sprite. This prevents us from allocating a bunch of new Matrix instances in memory
 	// ultimately saving on GC activity.
-	// When a negative scale is used, we just want to flip the sprite, so this
-	// makes up for the translation that happens on the openGL side.
-	transX := space.Position.X
-	transY := space.Position.Y
+	w, h := ren.Drawable.Width(), ren.Drawable.Height()
+	p := pivot(ren, space, w*ren.Scale.X, h*ren.Scale.Y)
+	// anchor is the point of the Drawable drawn at the pivot. When a negative scale is used, we just want to flip
+	// the sprite, so it's measured from the other side to keep the sprite in place.
+	anchorX, anchorY := ren.Origin.X*w, ren.Origin.Y*h
 	if ren.Scale.X < 0 {
-		transX -= ren.Drawable.Width() * ren.Scale.X
+		anchorX = w - anchorX
 	}
 	if ren.Scale.Y < 0 {
-		transY -= ren.Drawable.Height() * ren.Scale.Y
+		anchorY = h - anchorY
 	}
-	s.modelMatrix.Identity().Scale(engo.GetGlobalScale().X, engo.GetGlobalScale().Y).Translate(transX, transY)
+	s.modelMatrix.Identity().Scale(engo.GetGlobalScale().X, engo.GetGlobalScale().Y).Translate(p.X, p.Y)
 	if space.Rotation != 0 {
 		s.modelMatrix.Rotate(space.Rotation)
 	}
 	s.modelMatrix.Scale(ren.Scale.X, ren.Scale.Y)
+	if anchorX != 0 || anchorY != 0 {
+		s.modelMatrix.Translate(-anchorX, -anchorY)
+	}
 	return s.modelMatrix
 }
 
@@ -348,17 +473,25 @@ func (s *basicShader) generateBufferContent(ren *RenderComponent, space *SpaceCo
 	w := ren.Drawable.Width()
 	h := ren.Drawable.Height()
 
-	tint := colorToFloat32(ren.Color)
+	tint := tintToFloat32(ren.Color, ren.Opacity)
 
 	u, v, u2, v2 := ren.Drawable.View()
 
 	if ren.Repeat != NoRepeat {
 		u2 = space.Width / (ren.Drawable.Width() * ren.Scale.X)
 		w *= u2
-		v2 = space.Width / (ren.Drawable.Height() * ren.Scale.Y)
+		v2 = space.Height / (ren.Drawable.Height() * ren.Scale.Y)
 		h *= v2
 	}
 
+	// Flipping mirrors the texture coordinates, so the vertices stay in place
+	if ren.FlipX {
+		u, u2 = u2, u
+	}
+	if ren.FlipY {
+		v, v2 = v2, v
+	}
+
 	var changed bool
 
 	//setBufferValue(buffer, 0, 0, &changed)
@@ -0,0 +1,211 @@
+package common
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/klopsch/gl"
+)
+
+const (
+	// DissolveThreshold is the uniform controlling how far an entity drawn by the DissolveShader has dissolved, from
+	// 0 for not at all to 1 for completely gone. Set it on the RenderComponent with SetUniform to animate it.
+	DissolveThreshold = "uf_Threshold"
+
+	// dissolveNoiseSize is the width and height of the default noise texture.
+	dissolveNoiseSize = 64
+)
+
+const dissolveFragmentShader = `
+#ifdef GL_ES
+#define LOWP lowp
+precision mediump float;
+#else
+#define LOWP
+#endif
+
+varying vec4 var_Color;
+varying vec2 var_TexCoords;
+
+uniform sampler2D uf_Texture;
+uniform sampler2D uf_Noise;
+uniform float uf_Threshold;
+uniform float uf_EdgeWidth;
+uniform vec4 uf_EdgeColor;
+
+void main (void) {
+  float noise = texture2D(uf_Noise, var_TexCoords).r;
+  if (noise < uf_Threshold || uf_Threshold >= 1.0) {
+    discard;
+  }
+
+  vec4 color = var_Color * texture2D(uf_Texture, var_TexCoords);
+  if (uf_EdgeWidth > 0.0 && uf_Threshold > 0.0) {
+    float edge = 1.0 - smoothstep(uf_Threshold, uf_Threshold + uf_EdgeWidth, noise);
+    color.rgb = mix(color.rgb, uf_EdgeColor.rgb, edge * uf_EdgeColor.a);
+  }
+  gl_FragColor = color;
+}
+`
+
+// dissolveShader draws sprites like the DefaultShader, but discards the parts
+// of them where a noise texture is below the DissolveThreshold of the entity.
+// Entities with different thresholds can't share a batch.
+type dissolveShader struct {
+	*basicShader
+
+	noise        Drawable
+	defaultNoise *Texture
+	edgeColor    color.Color
+	edgeWidth    float32
+
+	ufNoise       *gl.UniformLocation
+	ufThreshold   *gl.UniformLocation
+	ufEdgeWidth   *gl.UniformLocation
+	ufEdgeColor   *gl.UniformLocation
+	lastThreshold float32
+}
+
+// Setup compiles the dissolve program and creates the default noise texture.
+func (s *dissolveShader) Setup(w *ecs.World) error {
+	s.fragmentShader = dissolveFragmentShader
+	if err := s.basicShader.Setup(w); err != nil {
+		return err
+	}
+
+	s.locations()
+
+	tex := NewTextureSingle(NewImageObject(dissolveNoise(dissolveNoiseSize, 1)))
+	engo.Gl.BindTexture(engo.Gl.TEXTURE_2D, tex.Texture())
+	engo.Gl.TexParameteri(engo.Gl.TEXTURE_2D, engo.Gl.TEXTURE_WRAP_S, engo.Gl.REPEAT)
+	engo.Gl.TexParameteri(engo.Gl.TEXTURE_2D, engo.Gl.TEXTURE_WRAP_T, engo.Gl.REPEAT)
+	engo.Gl.TexParameteri(engo.Gl.TEXTURE_2D, engo.Gl.TEXTURE_MAG_FILTER, engo.Gl.LINEAR)
+	engo.Gl.BindTexture(engo.Gl.TEXTURE_2D, nil)
+	s.defaultNoise = &tex
+	return nil
+}
+
+// locations looks up the dissolve uniforms of the program.
+func (s *dissolveShader) locations() {
+	s.ufNoise = engo.Gl.GetUniformLocation(s.program, "uf_Noise")
+	s.ufThreshold = engo.Gl.GetUniformLocation(s.program, "uf_Threshold")
+	s.ufEdgeWidth = engo.Gl.GetUniformLocation(s.program, "uf_EdgeWidth")
+	s.ufEdgeColor = engo.Gl.GetUniformLocation(s.program, "uf_EdgeColor")
+}
+
+// SetProgram replaces the program of the shader, and returns the previous one.
+func (s *dissolveShader) SetProgram(program *gl.Program) *gl.Program {
+	prev := s.basicShader.SetProgram(program)
+	s.locations()
+	return prev
+}
+
+// SetNoise replaces the noise texture deciding in which order the pixels of
+// the entities dissolve. Its red channel is compared to the threshold, and it's
+// sampled at the same texture coordinates as the sprite. Pass nil to use the
+// default noise again.
+func (s *dissolveShader) SetNoise(noise Drawable) {
+	s.noise = noise
+}
+
+// SetEdge colors the pixels that are about to dissolve, within width of the
+// threshold, such as to make the entities look like they're burning away. A
+// width of 0 disables the edge.
+func (s *dissolveShader) SetEdge(c color.Color, width float32) {
+	s.edgeColor, s.edgeWidth = c, width
+}
+
+// Pre binds the noise texture and the edge color.
+func (s *dissolveShader) Pre() {
+	s.basicShader.Pre()
+
+	noise := Drawable(s.defaultNoise)
+	if s.noise != nil {
+		noise = s.noise
+	}
+	engo.Gl.Uniform1i(s.ufNoise, 1)
+	engo.Gl.ActiveTexture(engo.Gl.TEXTURE1)
+	engo.Gl.BindTexture(engo.Gl.TEXTURE_2D, noise.Texture())
+	engo.Gl.ActiveTexture(engo.Gl.TEXTURE0)
+
+	engo.Gl.Uniform1f(s.ufEdgeWidth, s.edgeWidth)
+	r, g, b, a := colorComponents(linearColor(s.edgeColor))
+	engo.Gl.Uniform4f(s.ufEdgeColor, r, g, b, a)
+
+	engo.Gl.Uniform1f(s.ufThreshold, 0)
+	s.lastThreshold = 0
+}
+
+// Draw adds the entity to the batch, drawing the batch first if the entity
+// dissolved to a different threshold.
+func (s *dissolveShader) Draw(ren *RenderComponent, space *SpaceComponent) {
+	threshold, _ := ren.Uniform(DissolveThreshold)
+	if threshold != s.lastThreshold {
+		s.flush()
+		engo.Gl.Uniform1f(s.ufThreshold, threshold)
+		s.lastThreshold = threshold
+	}
+	s.basicShader.Draw(ren, space)
+}
+
+// Post draws the remaining batch and unbinds the noise texture.
+func (s *dissolveShader) Post() {
+	s.basicShader.Post()
+
+	engo.Gl.ActiveTexture(engo.Gl.TEXTURE1)
+	engo.Gl.BindTexture(engo.Gl.TEXTURE_2D, nil)
+	engo.Gl.ActiveTexture(engo.Gl.TEXTURE0)
+}
+
+// dissolveNoise creates a tileable size by size image of smooth value noise in
+// its color channels, generated from the seed.
+func dissolveNoise(size int, seed int64) *image.NRGBA {
+	rnd := rand.New(rand.NewSource(seed))
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+
+	// Two octaves of value noise, so there are both large and small blotches
+	octaves := []struct {
+		cells  int
+		weight float32
+	}{{8, 0.65}, {16, 0.35}}
+	grids := make([][]float32, len(octaves))
+	for i, o := range octaves {
+		grids[i] = make([]float32, o.cells*o.cells)
+		for j := range grids[i] {
+			grids[i][j] = rnd.Float32()
+		}
+	}
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			var v float32
+			for i, o := range octaves {
+				v += o.weight * valueNoise(grids[i], o.cells, float32(x)/float32(size), float32(y)/float32(size))
+			}
+			c := uint8(v*255 + 0.5)
+			img.SetNRGBA(x, y, color.NRGBA{R: c, G: c, B: c, A: 255})
+		}
+	}
+	return img
+}
+
+// valueNoise interpolates the cells by cells grid at x, y between 0 and 1. The
+// grid wraps around, so the noise tiles.
+func valueNoise(grid []float32, cells int, x, y float32) float32 {
+	gx, gy := x*float32(cells), y*float32(cells)
+	x0, y0 := int(gx), int(gy)
+	tx, ty := smoothStep(gx-float32(x0)), smoothStep(gy-float32(y0))
+	x1, y1 := (x0+1)%cells, (y0+1)%cells
+	x0, y0 = x0%cells, y0%cells
+
+	top := grid[y0*cells+x0] + (grid[y0*cells+x1]-grid[y0*cells+x0])*tx
+	bottom := grid[y1*cells+x0] + (grid[y1*cells+x1]-grid[y1*cells+x0])*tx
+	return top + (bottom-top)*ty
+}
+
+func smoothStep(t float32) float32 {
+	return t * t * (3 - 2*t)
+}
@@ -0,0 +1,42 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDissolveNoise(t *testing.T) {
+	img := dissolveNoise(dissolveNoiseSize, 1)
+	assert.Equal(t, img.Pix, dissolveNoise(dissolveNoiseSize, 1).Pix, "The noise should be the same for the same seed")
+	assert.NotEqual(t, img.Pix, dissolveNoise(dissolveNoiseSize, 2).Pix)
+
+	min, max := uint8(255), uint8(0)
+	for i := 0; i < len(img.Pix); i += 4 {
+		if img.Pix[i] < min {
+			min = img.Pix[i]
+		}
+		if img.Pix[i] > max {
+			max = img.Pix[i]
+		}
+		assert.Equal(t, uint8(255), img.Pix[i+3], "The noise should be opaque")
+	}
+	assert.True(t, max-min > 128, "The noise should cover most thresholds, got %d to %d", min, max)
+
+	// The noise repeats, so the opposite edges should be close
+	for y := 0; y < dissolveNoiseSize; y++ {
+		left, right := int(img.NRGBAAt(0, y).R), int(img.NRGBAAt(dissolveNoiseSize-1, y).R)
+		assert.InDelta(t, left, right, 32, "The noise should tile at row %d", y)
+	}
+}
+
+func TestRenderComponentUniforms(t *testing.T) {
+	ren := &RenderComponent{}
+	_, ok := ren.Uniform(DissolveThreshold)
+	assert.False(t, ok)
+
+	ren.SetUniform(DissolveThreshold, 0.4)
+	v, ok := ren.Uniform(DissolveThreshold)
+	assert.True(t, ok)
+	assert.Equal(t, float32(0.4), v)
+}
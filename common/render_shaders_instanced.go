@@ -0,0 +1,290 @@
+package common
+
+import (
+	"log"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/klopsch/gl"
+)
+
+const (
+	instancedVertexShader = `
+	attribute vec2 in_Corner;
+	attribute vec3 in_TransformX;
+	attribute vec3 in_TransformY;
+	attribute vec4 in_Color;
+
+	uniform mat3 matrixProjView;
+	uniform vec2 uf_ViewMin;
+	uniform vec2 uf_ViewMax;
+
+	varying vec4 var_Color;
+	varying vec2 var_TexCoords;
+
+	void main() {
+	  var_Color = in_Color;
+	  var_TexCoords = mix(uf_ViewMin, uf_ViewMax, in_Corner);
+
+	  vec3 corner = vec3(in_Corner, 1.0);
+	  vec2 position = vec2(dot(in_TransformX, corner), dot(in_TransformY, corner));
+	  vec3 matr = matrixProjView * vec3(position, 1.0);
+	  gl_Position = vec4(matr.xy, 0, matr.z);
+	}
+`
+
+	// instanceSize is the number of floats uploaded per instance: two rows of
+	// the transform and the packed tint.
+	instanceSize = 7
+)
+
+// quadCorners are the two triangles of a sprite, shared by all instances.
+var quadCorners = []float32{0, 0, 1, 0, 1, 1, 0, 0, 1, 1, 0, 1}
+
+// glInstancer draws instanced, which OpenGL 2.1 and WebGL 1 only have as
+// extensions. newInstancer returns the one of the current OpenGL context, or
+// nil if it doesn't support instancing.
+type glInstancer interface {
+	DrawArraysInstanced(mode, first, count, instances int)
+	VertexAttribDivisor(index, divisor int)
+}
+
+// InstancingSupported returns whether the OpenGL context can draw instanced,
+// which the InstancedShader needs to draw with a single call per drawable.
+// Without it, the InstancedShader batches like the DefaultShader.
+func InstancingSupported() bool {
+	return newInstancer() != nil
+}
+
+// instancedShader draws many entities sharing the same drawable with a single
+// instanced draw call, uploading only a transform and tint per entity instead
+// of four full vertices. When the OpenGL context doesn't support instancing it
+// behaves exactly like the basicShader it embeds.
+type instancedShader struct {
+	*basicShader
+
+	instancer glInstancer
+
+	program        *gl.Program
+	cornerBuffer   *gl.Buffer
+	instanceBuffer *gl.Buffer
+	instances      []float32
+	count          int
+	lastView       [4]float32
+
+	inCorner     int
+	inTransformX int
+	inTransformY int
+	inColor      int
+
+	matrixProjView *gl.UniformLocation
+	viewMin        *gl.UniformLocation
+	viewMax        *gl.UniformLocation
+}
+
+// Setup prepares the batching fallback and, if the OpenGL context supports
+// it, the instanced program.
+func (s *instancedShader) Setup(w *ecs.World) error {
+	if err := s.basicShader.Setup(w); err != nil {
+		return err
+	}
+
+	if s.instancer = newInstancer(); s.instancer == nil {
+		log.Println("[WARNING] The OpenGL context doesn't support instanced drawing, the InstancedShader batches like the DefaultShader")
+		return nil
+	}
+
+	var err error
+	s.program, err = LoadShader(instancedVertexShader, defaultFragmentShader)
+	if err != nil {
+		return err
+	}
+
+	s.instances = make([]float32, s.BatchSize*instanceSize)
+	s.instanceBuffer = engo.Gl.CreateBuffer()
+	s.cornerBuffer = engo.Gl.CreateBuffer()
+	engo.Gl.BindBuffer(engo.Gl.ARRAY_BUFFER, s.cornerBuffer)
+	engo.Gl.BufferData(engo.Gl.ARRAY_BUFFER, quadCorners, engo.Gl.STATIC_DRAW)
+
+	s.locations()
+	return nil
+}
+
+// locations looks up the attributes and uniforms of the instanced program.
+func (s *instancedShader) locations() {
+	s.inCorner = engo.Gl.GetAttribLocation(s.program, "in_Corner")
+	s.inTransformX = engo.Gl.GetAttribLocation(s.program, "in_TransformX")
+	s.inTransformY = engo.Gl.GetAttribLocation(s.program, "in_TransformY")
+	s.inColor = engo.Gl.GetAttribLocation(s.program, "in_Color")
+
+	s.matrixProjView = engo.Gl.GetUniformLocation(s.program, "matrixProjView")
+	s.viewMin = engo.Gl.GetUniformLocation(s.program, "uf_ViewMin")
+	s.viewMax = engo.Gl.GetUniformLocation(s.program, "uf_ViewMax")
+}
+
+// SetProgram replaces the instanced program of the shader, and returns the previous one. When the OpenGL context
+// doesn't support instancing, it replaces the program of the batching fallback instead.
+func (s *instancedShader) SetProgram(program *gl.Program) *gl.Program {
+	if s.instancer == nil {
+		return s.basicShader.SetProgram(program)
+	}
+	prev := s.program
+	s.program = program
+	s.locations()
+	return prev
+}
+
+// Pre enables the instanced program and its attributes. The blend function is
+// left to the RenderSystem, which sets the BlendMode of the entity after Pre.
+func (s *instancedShader) Pre() {
+	if s.instancer == nil {
+		s.basicShader.Pre()
+		return
+	}
+
+	engo.Gl.Enable(engo.Gl.BLEND)
+	engo.Gl.UseProgram(s.program)
+
+	if s.projViewChange {
+		s.projViewMatrix = s.projectionMatrix.Multiply(s.viewMatrix)
+		s.projViewChange = false
+	}
+	engo.Gl.UniformMatrix3fv(s.matrixProjView, false, s.projViewMatrix.Val[:])
+
+	engo.Gl.BindBuffer(engo.Gl.ARRAY_BUFFER, s.cornerBuffer)
+	engo.Gl.EnableVertexAttribArray(s.inCorner)
+	engo.Gl.VertexAttribPointer(s.inCorner, 2, engo.Gl.FLOAT, false, 8, 0)
+
+	engo.Gl.BindBuffer(engo.Gl.ARRAY_BUFFER, s.instanceBuffer)
+	engo.Gl.EnableVertexAttribArray(s.inTransformX)
+	engo.Gl.EnableVertexAttribArray(s.inTransformY)
+	engo.Gl.EnableVertexAttribArray(s.inColor)
+	engo.Gl.VertexAttribPointer(s.inTransformX, 3, engo.Gl.FLOAT, false, instanceSize*4, 0)
+	engo.Gl.VertexAttribPointer(s.inTransformY, 3, engo.Gl.FLOAT, false, instanceSize*4, 12)
+	engo.Gl.VertexAttribPointer(s.inColor, 4, engo.Gl.UNSIGNED_BYTE, true, instanceSize*4, 24)
+	s.instancer.VertexAttribDivisor(s.inTransformX, 1)
+	s.instancer.VertexAttribDivisor(s.inTransformY, 1)
+	s.instancer.VertexAttribDivisor(s.inColor, 1)
+
+	s.setTexture(nil)
+}
+
+// Draw adds the entity as an instance of the current batch, which is drawn
+// when the texture or the region of it changes.
+func (s *instancedShader) Draw(ren *RenderComponent, space *SpaceComponent) {
+	if s.instancer == nil {
+		s.basicShader.Draw(ren, space)
+		return
+	}
+
+	// Repeating textures, NinePatches and TileMeshes don't map to a single
+	// quad, so they are drawn on their own by the batching shader.
+	if !isSingleQuad(ren) {
+		s.flush()
+		s.Post()
+		s.basicShader.Pre()
+		setBlendMode(ren.BlendMode)
+		s.basicShader.Draw(ren, space)
+		s.basicShader.Post()
+		s.Pre()
+		setBlendMode(ren.BlendMode)
+		return
+	}
+
+	u, v, u2, v2 := ren.Drawable.View()
+	view := [4]float32{u, v, u2, v2}
+	if s.lastTexture != ren.Drawable.Texture() || s.lastView != view {
+		s.flush()
+		engo.Gl.BindTexture(engo.Gl.TEXTURE_2D, ren.Drawable.Texture())
+		s.setTexture(ren.Drawable.Texture())
+		engo.Gl.Uniform2f(s.viewMin, u, v)
+		engo.Gl.Uniform2f(s.viewMax, u2, v2)
+		s.lastView = view
+	} else if s.count == s.BatchSize {
+		s.flush()
+	}
+
+	if s.lastMagFilter != ren.magFilter || s.lastMinFilter != ren.minFilter {
+		s.flush()
+		engo.Gl.TexParameteri(engo.Gl.TEXTURE_2D, engo.Gl.TEXTURE_MAG_FILTER, zoomFilterValue(ren.magFilter))
+		engo.Gl.TexParameteri(engo.Gl.TEXTURE_2D, engo.Gl.TEXTURE_MIN_FILTER, zoomFilterValue(ren.minFilter))
+		s.lastMagFilter, s.lastMinFilter = ren.magFilter, ren.minFilter
+	}
+
+	s.instanceContent(ren, space, s.instances[s.count*instanceSize:(s.count+1)*instanceSize])
+	s.count++
+}
+
+// instanceContent writes the transform of the unit quad to the entity's
+// position, size and rotation, and its tint, to buffer.
+func (s *instancedShader) instanceContent(ren *RenderComponent, space *SpaceComponent, buffer []float32) {
+	m := s.makeModelMatrix(ren, space).Val
+	w, h := ren.Drawable.Width(), ren.Drawable.Height()
+
+	// Columns of the model matrix, scaled by the size of the drawable
+	a, c := m[0]*w, m[1]*w
+	b, d := m[3]*h, m[4]*h
+	tx, ty := m[6], m[7]
+
+	// Flipping mirrors the quad within its bounds
+	if ren.FlipX {
+		tx, ty = tx+a, ty+c
+		a, c = -a, -c
+	}
+	if ren.FlipY {
+		tx, ty = tx+b, ty+d
+		b, d = -b, -d
+	}
+
+	buffer[0], buffer[1], buffer[2] = a, b, tx
+	buffer[3], buffer[4], buffer[5] = c, d, ty
+	buffer[6] = tintToFloat32(ren.Color, ren.Opacity)
+}
+
+// flush draws the instances of the current batch.
+func (s *instancedShader) flush() {
+	if s.instancer == nil {
+		s.basicShader.flush()
+		return
+	}
+	if s.count == 0 {
+		return
+	}
+	engo.Gl.BindBuffer(engo.Gl.ARRAY_BUFFER, s.instanceBuffer)
+	engo.Gl.BufferData(engo.Gl.ARRAY_BUFFER, s.instances[:s.count*instanceSize], engo.Gl.STATIC_DRAW)
+	s.instancer.DrawArraysInstanced(engo.Gl.TRIANGLES, 0, len(quadCorners)/2, s.count)
+	frameStats.DrawCalls++
+	frameStats.Batches++
+	s.count = 0
+}
+
+// Post draws the remaining instances and resets the attribute state.
+func (s *instancedShader) Post() {
+	if s.instancer == nil {
+		s.basicShader.Post()
+		return
+	}
+
+	s.flush()
+	s.setTexture(nil)
+
+	s.instancer.VertexAttribDivisor(s.inTransformX, 0)
+	s.instancer.VertexAttribDivisor(s.inTransformY, 0)
+	s.instancer.VertexAttribDivisor(s.inColor, 0)
+	engo.Gl.DisableVertexAttribArray(s.inCorner)
+	engo.Gl.DisableVertexAttribArray(s.inTransformX)
+	engo.Gl.DisableVertexAttribArray(s.inTransformY)
+	engo.Gl.DisableVertexAttribArray(s.inColor)
+
+	engo.Gl.BindTexture(engo.Gl.TEXTURE_2D, nil)
+	engo.Gl.BindBuffer(engo.Gl.ARRAY_BUFFER, nil)
+
+	engo.Gl.Disable(engo.Gl.BLEND)
+}
+
+func zoomFilterValue(f ZoomFilter) int {
+	if f == FilterLinear {
+		return engo.Gl.LINEAR
+	}
+	return engo.Gl.NEAREST
+}
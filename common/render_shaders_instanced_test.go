@@ -0,0 +1,57 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstancedShaderInstanceContent(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &CameraTestScene{})
+
+	s := &instancedShader{basicShader: &basicShader{modelMatrix: engo.IdentityMatrix()}}
+	ren := &RenderComponent{
+		Drawable: Texture{width: 10, height: 20},
+		Scale:    engo.Point{X: 1, Y: 1},
+		Opacity:  1,
+	}
+	space := &SpaceComponent{Position: engo.Point{X: 5, Y: 7}}
+	corner := func(buf []float32, x, y float32) engo.Point {
+		return engo.Point{
+			X: buf[0]*x + buf[1]*y + buf[2],
+			Y: buf[3]*x + buf[4]*y + buf[5],
+		}
+	}
+
+	buf := make([]float32, instanceSize)
+	s.instanceContent(ren, space, buf)
+	assert.Equal(t, engo.Point{X: 5, Y: 7}, corner(buf, 0, 0))
+	assert.Equal(t, engo.Point{X: 15, Y: 27}, corner(buf, 1, 1), "The quad should be scaled to the drawable")
+	assert.Equal(t, tintToFloat32(ren.Color, 1), buf[6])
+
+	ren.FlipX = true
+	s.instanceContent(ren, space, buf)
+	assert.Equal(t, engo.Point{X: 15, Y: 7}, corner(buf, 0, 0), "Flipping should mirror the quad within its bounds")
+	assert.Equal(t, engo.Point{X: 5, Y: 27}, corner(buf, 1, 1), "Flipping should mirror the quad within its bounds")
+
+	ren.FlipX = false
+	space.Rotation = 90
+	s.instanceContent(ren, space, buf)
+	c := corner(buf, 1, 0)
+	assert.InDelta(t, 5, c.X, 1e-4, "Rotation should turn the quad around its position")
+	assert.InDelta(t, 17, c.Y, 1e-4, "Rotation should turn the quad around its position")
+}
+
+func TestInstancingSupportedHeadless(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &CameraTestScene{})
+
+	assert.Nil(t, newInstancer(), "There's no OpenGL context to draw instanced with in headless mode")
+	assert.False(t, InstancingSupported())
+}
@@ -0,0 +1,279 @@
+package common
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/klopsch/gl"
+)
+
+// MaxLights is the maximum number of lights the LightingShader applies to a frame. Lights beyond it are left out.
+// It's compiled into the shader, so it has to be changed before the first RenderSystem is created.
+var MaxLights = 16
+
+// MaxOccluders is the maximum number of occluders the LightingShader tests for shadows in a frame, and so for every
+// light casting shadows. The LightSystem keeps the ones nearest to the lights. Every pixel tests the four edges of
+// each of them for each light casting shadows, so raising it makes shadows slower. It's compiled into the shader like
+// MaxLights.
+var MaxOccluders = 8
+
+const lightingVertexShader = `
+attribute vec2 in_Position;
+attribute vec2 in_TexCoords;
+attribute vec4 in_Color;
+
+uniform mat3 matrixProjView;
+
+varying vec4 var_Color;
+varying vec2 var_TexCoords;
+varying vec2 var_World;
+
+void main() {
+  var_Color = in_Color;
+  var_TexCoords = in_TexCoords;
+  var_World = in_Position;
+
+  vec3 matr = matrixProjView * vec3(in_Position, 1.0);
+  gl_Position = vec4(matr.xy, 0, matr.z);
+}
+`
+
+const lightingFragmentShader = `
+#ifdef GL_ES
+#define LOWP lowp
+precision mediump float;
+#else
+#define LOWP
+#endif
+
+#define MAX_LIGHTS %d
+#define MAX_EDGES %d
+
+varying vec4 var_Color;
+varying vec2 var_TexCoords;
+varying vec2 var_World;
+
+uniform sampler2D uf_Texture;
+uniform sampler2D uf_NormalMap;
+uniform int uf_LightCount;
+uniform vec3 uf_Ambient;
+uniform vec3 uf_LightPositions[MAX_LIGHTS];
+uniform vec4 uf_LightColors[MAX_LIGHTS];
+uniform vec2 uf_LightShadows[MAX_LIGHTS];
+uniform int uf_EdgeCount;
+uniform vec4 uf_Edges[MAX_EDGES];
+
+// shadowed returns 1 if an edge of an occluder facing away from the light crosses the line from the pixel to the
+// light. Edges facing the light are skipped, so occluders are lit on the side of the light instead of shadowing
+// themselves.
+float shadowed(vec2 from, vec2 to) {
+  vec2 d = to - from;
+  for (int i = 0; i < MAX_EDGES; i++) {
+    if (i >= uf_EdgeCount) {
+      break;
+    }
+    vec2 a = uf_Edges[i].xy;
+    vec2 e = uf_Edges[i].zw - a;
+    if (dot(to - a, vec2(e.y, -e.x)) > 0.0) {
+      continue;
+    }
+    float denom = d.x * e.y - d.y * e.x;
+    if (denom == 0.0) {
+      continue;
+    }
+    vec2 ap = a - from;
+    float t = (ap.x * e.y - ap.y * e.x) / denom;
+    float u = (ap.x * d.y - ap.y * d.x) / denom;
+    if (t > 0.0 && t < 1.0 && u >= 0.0 && u <= 1.0) {
+      return 1.0;
+    }
+  }
+  return 0.0;
+}
+
+void main (void) {
+  vec4 color = var_Color * texture2D(uf_Texture, var_TexCoords);
+  if (uf_LightCount == 0) {
+    gl_FragColor = color;
+    return;
+  }
+
+  // The y axis of normal maps points up, while the one of the world points down
+  vec3 normal = normalize(texture2D(uf_NormalMap, var_TexCoords).rgb * 2.0 - 1.0);
+  normal.y = -normal.y;
+
+  vec3 light = uf_Ambient;
+  for (int i = 0; i < MAX_LIGHTS; i++) {
+    if (i >= uf_LightCount) {
+      break;
+    }
+    vec3 toLight = vec3(uf_LightPositions[i].xy - var_World, uf_LightPositions[i].z);
+    float falloff = clamp(1.0 - length(toLight.xy) / uf_LightColors[i].a, 0.0, 1.0);
+    if (uf_LightShadows[i].x > 0.0 && falloff > 0.0) {
+      // Soft shadows average the shadows from both sides of the light, across the line to the pixel
+      vec2 center = uf_LightPositions[i].xy;
+      float shadow = shadowed(var_World, center);
+      if (uf_LightShadows[i].y > 0.0 && length(toLight.xy) > 0.0) {
+        vec2 side = normalize(vec2(-toLight.y, toLight.x)) * uf_LightShadows[i].y * 0.5;
+        shadow = (shadow + shadowed(var_World, center + side) + shadowed(var_World, center - side)) / 3.0;
+      }
+      falloff *= 1.0 - shadow;
+    }
+    light += uf_LightColors[i].rgb * max(dot(normal, normalize(toLight)), 0.0) * falloff * falloff;
+  }
+  gl_FragColor = vec4(color.rgb * light, color.a);
+}
+`
+
+// light is a light as it's passed to the LightingShader, in the coordinates of the vertices.
+type light struct {
+	x, y, height float32
+	r, g, b      float32
+	radius       float32
+	// shadows is whether occluders block the light, and size is the width of the light, which softens its shadows
+	shadows bool
+	size    float32
+}
+
+// edge is an edge of an occluder as it's passed to the LightingShader, in the coordinates of the vertices. Its
+// outside is on the left of the direction from a to b, when y points down.
+type edge struct {
+	ax, ay, bx, by float32
+}
+
+// lightingShader draws sprites like the DefaultShader, lit by the lights of the LightSystem with Lambert shading
+// plus the ambient light. The normals come from the NormalMap of the RenderComponents, or face the camera for
+// entities without one. Entities with different normal maps can't share a batch. Lights casting shadows are blocked
+// by the edges of the occluders, tested per pixel like 2D shadow volumes.
+type lightingShader struct {
+	*basicShader
+
+	ambient    [3]float32
+	lights     []light
+	edges      []edge
+	flatNormal *Texture
+	lastNormal *gl.Texture
+
+	ufNormalMap      *gl.UniformLocation
+	ufLightCount     *gl.UniformLocation
+	ufAmbient        *gl.UniformLocation
+	ufLightPositions []*gl.UniformLocation
+	ufLightColors    []*gl.UniformLocation
+	ufLightShadows   []*gl.UniformLocation
+	ufEdgeCount      *gl.UniformLocation
+	ufEdges          []*gl.UniformLocation
+}
+
+// Setup compiles the lighting program for MaxLights and MaxOccluders, and creates the normal map of flat sprites.
+func (s *lightingShader) Setup(w *ecs.World) error {
+	s.vertexShader = lightingVertexShader
+	s.fragmentShader = fmt.Sprintf(lightingFragmentShader, MaxLights, 4*MaxOccluders)
+	if err := s.basicShader.Setup(w); err != nil {
+		return err
+	}
+
+	s.locations()
+
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 128, G: 128, B: 255, A: 255})
+	s.flatNormal = &Texture{UploadLinearTexture(NewImageObject(img)), 1, 1, engo.AABB{Max: engo.Point{X: 1, Y: 1}}}
+	return nil
+}
+
+// locations looks up the lighting uniforms of the program.
+func (s *lightingShader) locations() {
+	s.ufNormalMap = engo.Gl.GetUniformLocation(s.program, "uf_NormalMap")
+	s.ufLightCount = engo.Gl.GetUniformLocation(s.program, "uf_LightCount")
+	s.ufAmbient = engo.Gl.GetUniformLocation(s.program, "uf_Ambient")
+	s.ufLightPositions = s.ufLightPositions[:0]
+	s.ufLightColors = s.ufLightColors[:0]
+	s.ufLightShadows = s.ufLightShadows[:0]
+	for i := 0; i < MaxLights; i++ {
+		s.ufLightPositions = append(s.ufLightPositions, engo.Gl.GetUniformLocation(s.program, fmt.Sprintf("uf_LightPositions[%d]", i)))
+		s.ufLightColors = append(s.ufLightColors, engo.Gl.GetUniformLocation(s.program, fmt.Sprintf("uf_LightColors[%d]", i)))
+		s.ufLightShadows = append(s.ufLightShadows, engo.Gl.GetUniformLocation(s.program, fmt.Sprintf("uf_LightShadows[%d]", i)))
+	}
+	s.ufEdgeCount = engo.Gl.GetUniformLocation(s.program, "uf_EdgeCount")
+	s.ufEdges = s.ufEdges[:0]
+	for i := 0; i < 4*MaxOccluders; i++ {
+		s.ufEdges = append(s.ufEdges, engo.Gl.GetUniformLocation(s.program, fmt.Sprintf("uf_Edges[%d]", i)))
+	}
+}
+
+// SetProgram replaces the program of the shader, and returns the previous one.
+func (s *lightingShader) SetProgram(program *gl.Program) *gl.Program {
+	prev := s.basicShader.SetProgram(program)
+	s.locations()
+	return prev
+}
+
+// setLights replaces the lights applied to the next frames, keeping the first MaxLights of them, and the edges of the
+// occluders casting their shadows, keeping the first 4*MaxOccluders of them.
+func (s *lightingShader) setLights(ambient color.Color, lights []light, edges []edge) {
+	if ambient == nil {
+		ambient = color.Black
+	}
+	s.ambient[0], s.ambient[1], s.ambient[2], _ = colorComponents(linearColor(ambient))
+	if len(lights) > MaxLights {
+		lights = lights[:MaxLights]
+	}
+	s.lights = append(s.lights[:0], lights...)
+	if len(edges) > 4*MaxOccluders {
+		edges = edges[:4*MaxOccluders]
+	}
+	s.edges = append(s.edges[:0], edges...)
+}
+
+// Pre binds the normal map of flat sprites and passes the lights and occluders to the program.
+func (s *lightingShader) Pre() {
+	s.basicShader.Pre()
+
+	engo.Gl.Uniform1i(s.ufNormalMap, 1)
+	s.bindNormalMap(s.flatNormal.Texture())
+
+	engo.Gl.Uniform1i(s.ufLightCount, len(s.lights))
+	engo.Gl.Uniform3f(s.ufAmbient, s.ambient[0], s.ambient[1], s.ambient[2])
+	for i, l := range s.lights {
+		engo.Gl.Uniform3f(s.ufLightPositions[i], l.x, l.y, l.height)
+		engo.Gl.Uniform4f(s.ufLightColors[i], l.r, l.g, l.b, l.radius)
+		shadows := float32(0)
+		if l.shadows {
+			shadows = 1
+		}
+		engo.Gl.Uniform2f(s.ufLightShadows[i], shadows, l.size)
+	}
+	engo.Gl.Uniform1i(s.ufEdgeCount, len(s.edges))
+	for i, e := range s.edges {
+		engo.Gl.Uniform4f(s.ufEdges[i], e.ax, e.ay, e.bx, e.by)
+	}
+}
+
+// bindNormalMap binds the normal map to the second texture unit.
+func (s *lightingShader) bindNormalMap(tex *gl.Texture) {
+	engo.Gl.ActiveTexture(engo.Gl.TEXTURE1)
+	engo.Gl.BindTexture(engo.Gl.TEXTURE_2D, tex)
+	engo.Gl.ActiveTexture(engo.Gl.TEXTURE0)
+	s.lastNormal = tex
+}
+
+// Draw adds the entity to the batch, drawing the batch first if the entity has a different normal map.
+func (s *lightingShader) Draw(ren *RenderComponent, space *SpaceComponent) {
+	normal := s.flatNormal.Texture()
+	if ren.NormalMap != nil {
+		normal = ren.NormalMap.Texture()
+	}
+	if normal != s.lastNormal {
+		s.flush()
+		s.bindNormalMap(normal)
+	}
+	s.basicShader.Draw(ren, space)
+}
+
+// Post draws the remaining batch and unbinds the normal map.
+func (s *lightingShader) Post() {
+	s.basicShader.Post()
+	s.bindNormalMap(nil)
+}
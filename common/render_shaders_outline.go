@@ -0,0 +1,175 @@
+package common
+
+import (
+	"image/color"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/klopsch/engo/math"
+	"github.com/klopsch/gl"
+)
+
+const (
+	// OutlineThickness is the uniform overriding the thickness of the outline the OutlineShader draws around an
+	// entity, in pixels of its texture. Set it on the RenderComponent with SetUniform.
+	OutlineThickness = "uf_Thickness"
+	// MaxOutlineThickness is the thickest outline the OutlineShader can draw, in pixels of the texture.
+	MaxOutlineThickness = 8
+)
+
+const outlineFragmentShader = `
+#ifdef GL_ES
+#define LOWP lowp
+precision mediump float;
+#else
+#define LOWP
+#endif
+
+#define MAX_THICKNESS 8
+
+varying vec4 var_Color;
+varying vec2 var_TexCoords;
+
+uniform sampler2D uf_Texture;
+uniform vec4 uf_Region;
+uniform vec2 uf_TexelSize;
+uniform float uf_Thickness;
+uniform vec4 uf_OutlineColor;
+
+// alphaAt is the alpha of the sprite at uv, which is transparent outside of
+// its region of the texture.
+float alphaAt(vec2 uv) {
+  if (uv.x < uf_Region.x || uv.y < uf_Region.y || uv.x > uf_Region.z || uv.y > uf_Region.w) {
+    return 0.0;
+  }
+  return texture2D(uf_Texture, uv).a;
+}
+
+void main (void) {
+  vec4 texel = texture2D(uf_Texture, var_TexCoords);
+  if (alphaAt(var_TexCoords) == 0.0) {
+    texel = vec4(0.0);
+  }
+  vec4 color = var_Color * texel;
+
+  // The outline covers every pixel within the thickness of an opaque one
+  float outline = 0.0;
+  for (int y = -MAX_THICKNESS; y <= MAX_THICKNESS; y++) {
+    for (int x = -MAX_THICKNESS; x <= MAX_THICKNESS; x++) {
+      if (float(x * x + y * y) > uf_Thickness * uf_Thickness) {
+        continue;
+      }
+      outline = max(outline, alphaAt(var_TexCoords + vec2(float(x), float(y)) * uf_TexelSize));
+    }
+  }
+
+  float outlineAlpha = uf_OutlineColor.a * var_Color.a * outline;
+  gl_FragColor = vec4(mix(uf_OutlineColor.rgb, color.rgb, color.a), max(color.a, outlineAlpha));
+}
+`
+
+// outlineShader draws sprites like the DefaultShader, with a colored border
+// around their opaque pixels. The quad of every sprite is grown by the
+// thickness, so the outline isn't cut off at the edges of the sprite. Every
+// entity is drawn on its own, because the region of the texture it shows is
+// passed as a uniform, so it doesn't batch.
+type outlineShader struct {
+	*basicShader
+
+	color     color.Color
+	thickness float32
+
+	ufRegion       *gl.UniformLocation
+	ufTexelSize    *gl.UniformLocation
+	ufThickness    *gl.UniformLocation
+	ufOutlineColor *gl.UniformLocation
+}
+
+// Setup compiles the outline program.
+func (s *outlineShader) Setup(w *ecs.World) error {
+	s.fragmentShader = outlineFragmentShader
+	if err := s.basicShader.Setup(w); err != nil {
+		return err
+	}
+
+	s.locations()
+	return nil
+}
+
+// locations looks up the outline uniforms of the program.
+func (s *outlineShader) locations() {
+	s.ufRegion = engo.Gl.GetUniformLocation(s.program, "uf_Region")
+	s.ufTexelSize = engo.Gl.GetUniformLocation(s.program, "uf_TexelSize")
+	s.ufThickness = engo.Gl.GetUniformLocation(s.program, "uf_Thickness")
+	s.ufOutlineColor = engo.Gl.GetUniformLocation(s.program, "uf_OutlineColor")
+}
+
+// SetProgram replaces the program of the shader, and returns the previous one.
+func (s *outlineShader) SetProgram(program *gl.Program) *gl.Program {
+	prev := s.basicShader.SetProgram(program)
+	s.locations()
+	return prev
+}
+
+// SetOutline sets the color and the thickness, in pixels of the texture, of
+// the outline. The thickness is limited to MaxOutlineThickness, and can be
+// changed per entity with the OutlineThickness uniform.
+func (s *outlineShader) SetOutline(c color.Color, thickness float32) {
+	s.color, s.thickness = c, thickness
+}
+
+// Pre sets the outline color.
+func (s *outlineShader) Pre() {
+	s.basicShader.Pre()
+
+	c := s.color
+	if c == nil {
+		c = color.Black
+	}
+	r, g, b, a := colorComponents(linearColor(c))
+	engo.Gl.Uniform4f(s.ufOutlineColor, r, g, b, a)
+}
+
+// Draw draws the entity with its outline.
+func (s *outlineShader) Draw(ren *RenderComponent, space *SpaceComponent) {
+	s.flush()
+
+	thickness := s.thickness
+	if t, ok := ren.Uniform(OutlineThickness); ok {
+		thickness = t
+	}
+	thickness = math.Clamp(thickness, 0, MaxOutlineThickness)
+
+	// Repeating textures, NinePatches and TileMeshes are drawn without an outline
+	if !isSingleQuad(ren) {
+		thickness = 0
+	}
+
+	u, v, u2, v2 := ren.Drawable.View()
+	engo.Gl.Uniform4f(s.ufRegion, math.Min(u, u2), math.Min(v, v2), math.Max(u, u2), math.Max(v, v2))
+	engo.Gl.Uniform2f(s.ufTexelSize, math.Abs(u2-u)/ren.Drawable.Width(), math.Abs(v2-v)/ren.Drawable.Height())
+	engo.Gl.Uniform1f(s.ufThickness, thickness)
+
+	s.basicShader.Draw(ren, space)
+	if thickness > 0 && s.idx >= 20 {
+		growQuad(s.vertices[s.idx-20:s.idx], ren.Drawable.Width(), ren.Drawable.Height(), thickness)
+	}
+}
+
+// growQuad moves the vertices of a sprite w by h texels large outwards by n
+// texels on every side, along with their texture coordinates, keeping the scale
+// and rotation of the sprite.
+func growQuad(buffer []float32, w, h, n float32) {
+	// Offsets of one texel along the edges, in position and in texture coordinates
+	px, py := (buffer[5]-buffer[0])/w, (buffer[6]-buffer[1])/w
+	qx, qy := (buffer[15]-buffer[0])/h, (buffer[16]-buffer[1])/h
+	du, dv := (buffer[7]-buffer[2])/w, (buffer[18]-buffer[3])/h
+
+	for i, corner := range [4][2]float32{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}} {
+		vertex := buffer[i*5 : i*5+4]
+		vertex[0] += n * (corner[0]*px + corner[1]*qx)
+		vertex[1] += n * (corner[0]*py + corner[1]*qy)
+		vertex[2] += n * corner[0] * du
+		vertex[3] += n * corner[1] * dv
+	}
+}
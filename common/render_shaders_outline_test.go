@@ -0,0 +1,56 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrowQuad(t *testing.T) {
+	// A 10x20 sprite drawn twice as large, using the right half of its texture
+	buffer := []float32{
+		0, 0, 0.5, 0, 0,
+		20, 0, 1, 0, 0,
+		20, 40, 1, 1, 0,
+		0, 40, 0.5, 1, 0,
+	}
+	growQuad(buffer, 10, 20, 2)
+
+	assert.Equal(t, []float32{
+		-4, -4, 0.4, -0.1, 0,
+		24, -4, 1.1, -0.1, 0,
+		24, 44, 1.1, 1.1, 0,
+		-4, 44, 0.4, 1.1, 0,
+	}, roundBuffer(buffer), "The quad should grow by 2 texels on every side")
+}
+
+func TestGrowQuadFlipped(t *testing.T) {
+	// FlipX swaps the texture coordinates, the quad should still grow outwards
+	buffer := []float32{
+		0, 0, 1, 0, 0,
+		10, 0, 0, 0, 0,
+		10, 10, 0, 1, 0,
+		0, 10, 1, 1, 0,
+	}
+	growQuad(buffer, 10, 10, 1)
+
+	assert.Equal(t, []float32{
+		-1, -1, 1.1, -0.1, 0,
+		11, -1, -0.1, -0.1, 0,
+		11, 11, -0.1, 1.1, 0,
+		-1, 11, 1.1, 1.1, 0,
+	}, roundBuffer(buffer))
+}
+
+// roundBuffer rounds the values to 4 decimals, to compare them without float errors.
+func roundBuffer(buffer []float32) []float32 {
+	rounded := make([]float32, len(buffer))
+	for i, v := range buffer {
+		if v < 0 {
+			rounded[i] = float32(int(v*10000-0.5)) / 10000
+		} else {
+			rounded[i] = float32(int(v*10000+0.5)) / 10000
+		}
+	}
+	return rounded
+}
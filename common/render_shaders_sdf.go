@@ -0,0 +1,190 @@
+package common
+
+import (
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/klopsch/gl"
+)
+
+// SDFSpread is the distance in pixels, at the Size of the Font, that the
+// distance field of a signed distance field Font reaches beyond the edges of its
+// glyphs. Outlines and glows can't be wider than this.
+const SDFSpread = 6
+
+const sdfFragmentShader = `
+#ifdef GL_ES
+#define LOWP lowp
+precision mediump float;
+#else
+#define LOWP
+#endif
+
+varying vec4 var_Color;
+varying vec2 var_TexCoords;
+
+uniform sampler2D uf_Texture;
+uniform float uf_Smoothing;
+uniform float uf_Outline;
+uniform vec4 uf_OutlineColor;
+uniform float uf_Glow;
+uniform vec4 uf_GlowColor;
+
+void main (void) {
+  vec4 texel = texture2D(uf_Texture, var_TexCoords);
+  float dist = texel.a;
+  vec3 fill = texel.rgb * var_Color.rgb;
+
+  float inside = smoothstep(0.5 - uf_Smoothing, 0.5 + uf_Smoothing, dist);
+  vec4 color = vec4(fill, var_Color.a * inside);
+
+  float edge = 0.5 - uf_Outline;
+  if (uf_Outline > 0.0) {
+    float outline = smoothstep(edge - uf_Smoothing, edge + uf_Smoothing, dist) * uf_OutlineColor.a;
+    color = vec4(mix(uf_OutlineColor.rgb, fill, inside), var_Color.a * mix(outline, 1.0, inside));
+  }
+
+  if (uf_Glow > 0.0) {
+    // The glow is drawn behind the glyph and its outline
+    float glow = smoothstep(edge - uf_Glow, edge, dist) * uf_GlowColor.a * var_Color.a;
+    float alpha = color.a + glow * (1.0 - color.a);
+    if (alpha > 0.0) {
+      color.rgb = (color.rgb * color.a + uf_GlowColor.rgb * glow * (1.0 - color.a)) / alpha;
+    }
+    color.a = alpha;
+  }
+
+  gl_FragColor = color;
+}`
+
+// sdfTextShader draws Text in signed distance field Fonts. It lays out and
+// buffers the Text just like the textShader, but turns the distances in the
+// FontAtlas into smooth edges, outlines and glows.
+type sdfTextShader struct {
+	*textShader
+
+	smoothing    *gl.UniformLocation
+	outline      *gl.UniformLocation
+	outlineColor *gl.UniformLocation
+	glow         *gl.UniformLocation
+	glowColor    *gl.UniformLocation
+
+	// style holds the values of the style uniforms, which are only set when
+	// they change. styled is false until they're set after Pre.
+	style  sdfUniforms
+	styled bool
+}
+
+// sdfUniforms are the values of the style uniforms of the sdfTextShader.
+type sdfUniforms struct {
+	smoothing, outline, glow float32
+	outlineColor, glowColor  [4]float32
+}
+
+// Setup compiles the SDF program and finds its style uniforms.
+func (l *sdfTextShader) Setup(w *ecs.World) error {
+	if err := l.setup(sdfFragmentShader); err != nil {
+		return err
+	}
+
+	l.locations()
+	return nil
+}
+
+// locations looks up the style uniforms of the program.
+func (l *sdfTextShader) locations() {
+	l.smoothing = engo.Gl.GetUniformLocation(l.program, "uf_Smoothing")
+	l.outline = engo.Gl.GetUniformLocation(l.program, "uf_Outline")
+	l.outlineColor = engo.Gl.GetUniformLocation(l.program, "uf_OutlineColor")
+	l.glow = engo.Gl.GetUniformLocation(l.program, "uf_Glow")
+	l.glowColor = engo.Gl.GetUniformLocation(l.program, "uf_GlowColor")
+}
+
+// SetProgram replaces the program of the shader, and returns the previous one.
+func (l *sdfTextShader) SetProgram(program *gl.Program) *gl.Program {
+	prev := l.textShader.SetProgram(program)
+	l.locations()
+	l.styled = false
+	return prev
+}
+
+// Pre prepares the textShader, and has the style uniforms set again by the
+// next Draw.
+func (l *sdfTextShader) Pre() {
+	l.textShader.Pre()
+	l.styled = false
+}
+
+// Draw sets the style of the Text's Font and draws it.
+func (l *sdfTextShader) Draw(ren *RenderComponent, space *SpaceComponent) {
+	txt, ok := ren.Drawable.(Text)
+	if !ok {
+		unsupportedType(ren.Drawable)
+		return
+	}
+
+	style := SDFStyle{}
+	if txt.Font.SDF != nil {
+		style = *txt.Font.SDF
+	}
+
+	scale := ren.Scale.X * engo.GetGlobalScale().X
+	if l.cameraEnabled && l.camera != nil {
+		scale /= l.camera.z
+	}
+	u := sdfUniforms{
+		smoothing: sdfSmoothing(scale),
+		outline:   sdfWidth(style.OutlineWidth),
+		glow:      sdfWidth(style.GlowWidth),
+	}
+	u.outlineColor[0], u.outlineColor[1], u.outlineColor[2], u.outlineColor[3] = colorComponents(linearColor(style.OutlineColor))
+	u.glowColor[0], u.glowColor[1], u.glowColor[2], u.glowColor[3] = colorComponents(linearColor(style.GlowColor))
+	l.setStyle(u)
+
+	l.textShader.Draw(ren, space)
+}
+
+// setStyle sets the style uniforms which differ from the ones the previous
+// Text was drawn with.
+func (l *sdfTextShader) setStyle(u sdfUniforms) {
+	if l.styled && u == l.style {
+		return
+	}
+	if !l.styled || u.smoothing != l.style.smoothing {
+		engo.Gl.Uniform1f(l.smoothing, u.smoothing)
+	}
+	if !l.styled || u.outline != l.style.outline {
+		engo.Gl.Uniform1f(l.outline, u.outline)
+	}
+	if !l.styled || u.outlineColor != l.style.outlineColor {
+		engo.Gl.Uniform4f(l.outlineColor, u.outlineColor[0], u.outlineColor[1], u.outlineColor[2], u.outlineColor[3])
+	}
+	if !l.styled || u.glow != l.style.glow {
+		engo.Gl.Uniform1f(l.glow, u.glow)
+	}
+	if !l.styled || u.glowColor != l.style.glowColor {
+		engo.Gl.Uniform4f(l.glowColor, u.glowColor[0], u.glowColor[1], u.glowColor[2], u.glowColor[3])
+	}
+	l.style, l.styled = u, true
+}
+
+// sdfSmoothing returns how far from the edge, in distance field units, the
+// glyphs are faded out to anti-alias them when drawn at the given scale. It
+// amounts to half a pixel on the screen.
+func sdfSmoothing(scale float32) float32 {
+	if scale <= 0 {
+		scale = 1
+	}
+	return 0.25 / (SDFSpread * scale)
+}
+
+// sdfWidth converts a width in pixels to distance field units, limited to the
+// SDFSpread.
+func sdfWidth(px float32) float32 {
+	if px <= 0 {
+		return 0
+	}
+	if px > SDFSpread {
+		px = SDFSpread
+	}
+	return px / (2 * SDFSpread)
+}
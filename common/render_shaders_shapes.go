@@ -28,6 +28,8 @@ type legacyShader struct {
 	cameraEnabled bool
 
 	lastBuffer *gl.Buffer
+	// quadBuffer holds the vertices of the quads drawn with drawQuad
+	quadBuffer *gl.Buffer
 }
 
 func (l *legacyShader) Setup(w *ecs.World) error {
@@ -72,14 +74,7 @@ void main (void) {
 	engo.Gl.BindBuffer(engo.Gl.ELEMENT_ARRAY_BUFFER, l.indicesRectanglesVBO)
 	engo.Gl.BufferData(engo.Gl.ELEMENT_ARRAY_BUFFER, l.indicesRectangles, engo.Gl.STATIC_DRAW)
 
-	// Define things that should be read from the texture buffer
-	l.inPosition = engo.Gl.GetAttribLocation(l.program, "in_Position")
-	l.inColor = engo.Gl.GetAttribLocation(l.program, "in_Color")
-
-	// Define things that should be set per draw
-	l.matrixProjection = engo.Gl.GetUniformLocation(l.program, "matrixProjection")
-	l.matrixView = engo.Gl.GetUniformLocation(l.program, "matrixView")
-	l.matrixModel = engo.Gl.GetUniformLocation(l.program, "matrixModel")
+	l.locations()
 
 	l.projectionMatrix = make([]float32, 9)
 	l.projectionMatrix[8] = 1
@@ -97,6 +92,26 @@ void main (void) {
 	return nil
 }
 
+// locations looks up the attributes and uniforms of the program.
+func (l *legacyShader) locations() {
+	// Define things that should be read from the texture buffer
+	l.inPosition = engo.Gl.GetAttribLocation(l.program, "in_Position")
+	l.inColor = engo.Gl.GetAttribLocation(l.program, "in_Color")
+
+	// Define things that should be set per draw
+	l.matrixProjection = engo.Gl.GetUniformLocation(l.program, "matrixProjection")
+	l.matrixView = engo.Gl.GetUniformLocation(l.program, "matrixView")
+	l.matrixModel = engo.Gl.GetUniformLocation(l.program, "matrixModel")
+}
+
+// SetProgram replaces the program of the shader, and returns the previous one.
+func (l *legacyShader) SetProgram(program *gl.Program) *gl.Program {
+	prev := l.program
+	l.program = program
+	l.locations()
+	return prev
+}
+
 func (l *legacyShader) Pre() {
 	engo.Gl.Enable(engo.Gl.BLEND)
 	engo.Gl.BlendFunc(engo.Gl.SRC_ALPHA, engo.Gl.ONE_MINUS_SRC_ALPHA)
@@ -106,13 +121,9 @@ func (l *legacyShader) Pre() {
 	engo.Gl.EnableVertexAttribArray(l.inPosition)
 	engo.Gl.EnableVertexAttribArray(l.inColor)
 
-	if engo.ScaleOnResize() {
-		l.projectionMatrix[0] = 1 / (engo.GameWidth() / 2)
-		l.projectionMatrix[4] = 1 / (-engo.GameHeight() / 2)
-	} else {
-		l.projectionMatrix[0] = 1 / (engo.CanvasWidth() / (2 * engo.CanvasScale()))
-		l.projectionMatrix[4] = 1 / (-engo.CanvasHeight() / (2 * engo.CanvasScale()))
-	}
+	viewW, viewH := viewSize()
+	l.projectionMatrix[0] = 1 / (viewW / 2)
+	l.projectionMatrix[4] = 1 / (-viewH / 2)
 
 	if l.cameraEnabled {
 		l.viewMatrix[1], l.viewMatrix[0] = math.Sincos(l.camera.angle * math.Pi / 180)
@@ -168,7 +179,7 @@ func (l *legacyShader) generateBufferContent(ren *RenderComponent, space *SpaceC
 
 	var changed bool
 
-	tint := colorToFloat32(ren.Color)
+	tint := tintToFloat32(ren.Color, ren.Opacity)
 
 	switch shape := ren.Drawable.(type) {
 	case Triangle:
@@ -187,7 +198,7 @@ func (l *legacyShader) generateBufferContent(ren *RenderComponent, space *SpaceC
 			setBufferValue(buffer, 8, tint, &changed)
 
 			if shape.BorderWidth > 0 {
-				borderTint := colorToFloat32(shape.BorderColor)
+				borderTint := tintToFloat32(shape.BorderColor, ren.Opacity)
 				b := shape.BorderWidth
 				s, c := math.Sincos(math.Atan(2 * h / w))
 
@@ -235,7 +246,7 @@ func (l *legacyShader) generateBufferContent(ren *RenderComponent, space *SpaceC
 			setBufferValue(buffer, 8, tint, &changed)
 
 			if shape.BorderWidth > 0 {
-				borderTint := colorToFloat32(shape.BorderColor)
+				borderTint := tintToFloat32(shape.BorderColor, ren.Opacity)
 				b := shape.BorderWidth
 
 				pts := [][]float32{
@@ -282,7 +293,7 @@ func (l *legacyShader) generateBufferContent(ren *RenderComponent, space *SpaceC
 		var borderTint float32
 		hasBorder := shape.BorderWidth > 0
 		if hasBorder {
-			borderTint = colorToFloat32(shape.BorderColor)
+			borderTint = tintToFloat32(shape.BorderColor, ren.Opacity)
 		}
 		setBufferValue(buffer, 0, w/2, &changed)
 		setBufferValue(buffer, 1, h/2, &changed)
@@ -333,7 +344,7 @@ func (l *legacyShader) generateBufferContent(ren *RenderComponent, space *SpaceC
 		setBufferValue(buffer, 17, tint, &changed)
 
 		if shape.BorderWidth > 0 {
-			borderTint := colorToFloat32(shape.BorderColor)
+			borderTint := tintToFloat32(shape.BorderColor, ren.Opacity)
 			b := shape.BorderWidth
 			pts := [][]float32{
 				//Top
@@ -383,7 +394,7 @@ func (l *legacyShader) generateBufferContent(ren *RenderComponent, space *SpaceC
 		}
 
 		if shape.BorderWidth > 0 {
-			borderTint := colorToFloat32(shape.BorderColor)
+			borderTint := tintToFloat32(shape.BorderColor, ren.Opacity)
 
 			for _, point := range shape.Points {
 				setBufferValue(buffer, index, point.X*w, &changed)
@@ -513,8 +524,12 @@ func (l *legacyShader) Draw(ren *RenderComponent, space *SpaceComponent) {
 		l.modelMatrix[4] = ren.Scale.Y * engo.GetGlobalScale().Y
 	}
 
-	l.modelMatrix[6] = space.Position.X * engo.GetGlobalScale().X
-	l.modelMatrix[7] = space.Position.Y * engo.GetGlobalScale().Y
+	// The anchor is the point of the shape drawn at the pivot
+	w, h := space.Width, space.Height
+	p := pivot(ren, space, w*ren.Scale.X, h*ren.Scale.Y)
+	anchorX, anchorY := ren.Origin.X*w, ren.Origin.Y*h
+	l.modelMatrix[6] = p.X*engo.GetGlobalScale().X - l.modelMatrix[0]*anchorX - l.modelMatrix[3]*anchorY
+	l.modelMatrix[7] = p.Y*engo.GetGlobalScale().Y - l.modelMatrix[1]*anchorX - l.modelMatrix[4]*anchorY
 
 	engo.Gl.UniformMatrix3fv(l.matrixModel, false, l.modelMatrix)
 
@@ -525,19 +540,24 @@ func (l *legacyShader) Draw(ren *RenderComponent, space *SpaceComponent) {
 			num = 21
 		}
 		engo.Gl.DrawArrays(engo.Gl.TRIANGLES, 0, num)
+		frameStats.DrawCalls++
 	case Rectangle:
 		num := 6
 		if shape.BorderWidth > 0 {
 			num = 30
 		}
 		engo.Gl.DrawArrays(engo.Gl.TRIANGLES, 0, num)
+		frameStats.DrawCalls++
 	case Circle:
 		if shape.BorderWidth > 0 {
 			engo.Gl.DrawArrays(engo.Gl.TRIANGLE_STRIP, 364, 722)
+			frameStats.DrawCalls++
 		}
 		engo.Gl.DrawArrays(engo.Gl.TRIANGLE_FAN, 0, 362)
+		frameStats.DrawCalls++
 	case ComplexTriangles:
 		engo.Gl.DrawArrays(engo.Gl.TRIANGLES, 0, len(shape.Points))
+		frameStats.DrawCalls++
 
 		if shape.BorderWidth > 0 {
 			borderWidth := shape.BorderWidth
@@ -546,9 +566,11 @@ func (l *legacyShader) Draw(ren *RenderComponent, space *SpaceComponent) {
 			}
 			engo.Gl.LineWidth(borderWidth)
 			engo.Gl.DrawArrays(engo.Gl.LINE_LOOP, len(shape.Points), len(shape.Points))
+			frameStats.DrawCalls++
 		}
 	case Curve:
 		engo.Gl.DrawArrays(engo.Gl.TRIANGLES, 0, 600)
+		frameStats.DrawCalls++
 	default:
 		unsupportedType(ren.Drawable)
 	}
@@ -59,6 +59,30 @@ func TestShaderCompilation(t *testing.T) {
 	assert.Error(t, err) // don't really care which one it is
 }
 
+// TestReloadShader tests whether `ReloadShader` swaps the program of a shader iff the new GLSL compiles, and keeps the
+// working one otherwise.
+func TestReloadShader(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &testScene{})
+	engo.CreateWindow("", 100, 100, false, 1)
+	defer engo.DestroyWindow()
+
+	s := &legacyShader{cameraEnabled: true}
+	w := &ecs.World{}
+	w.AddSystem(&CameraSystem{})
+	assert.NoError(t, s.Setup(w))
+	program := s.program
+
+	err := ReloadShader(s, correctVertShader, incorrectFragShader)
+	assert.IsType(t, FragmentShaderCompilationError{}, err)
+	assert.Equal(t, program, s.program, "A shader failing to compile should keep its program")
+
+	assert.NoError(t, ReloadShader(s, correctVertShader, correctFragShader))
+	assert.NotEqual(t, program, s.program, "A shader compiling should replace the program")
+}
+
 var correctVertShader = `
 attribute vec2 in_Position;
 attribute vec4 in_Color;
@@ -7,34 +7,8 @@ import (
 	"github.com/klopsch/gl"
 )
 
-type textShader struct {
-	program *gl.Program
-
-	indicesRectangles    []uint16
-	indicesRectanglesVBO *gl.Buffer
-
-	inPosition  int
-	inTexCoords int
-	inColor     int
-
-	matrixProjection *gl.UniformLocation
-	matrixView       *gl.UniformLocation
-	matrixModel      *gl.UniformLocation
-
-	projectionMatrix []float32
-	viewMatrix       []float32
-	modelMatrix      []float32
-
-	camera        *CameraSystem
-	cameraEnabled bool
-
-	lastBuffer  *gl.Buffer
-	lastTexture *gl.Texture
-}
-
-func (l *textShader) Setup(w *ecs.World) error {
-	var err error
-	l.program, err = LoadShader(`
+const (
+	textVertexShader = `
 attribute vec2 in_Position;
 attribute vec2 in_TexCoords;
 attribute vec4 in_Color;
@@ -53,7 +27,9 @@ void main() {
   vec3 matr = matrixProjection * matrixView * matrixModel * vec3(in_Position, 1.0);
   gl_Position = vec4(matr.xy, 0, matr.z);
 }
-`, `
+`
+
+	textFragmentShader = `
 #ifdef GL_ES
 #define LOWP lowp
 precision mediump float;
@@ -68,7 +44,43 @@ uniform sampler2D uf_Texture;
 
 void main (void) {
   gl_FragColor = var_Color * texture2D(uf_Texture, var_TexCoords);
-}`)
+}`
+)
+
+type textShader struct {
+	program *gl.Program
+
+	indicesRectangles    []uint16
+	indicesRectanglesVBO *gl.Buffer
+
+	inPosition  int
+	inTexCoords int
+	inColor     int
+
+	matrixProjection *gl.UniformLocation
+	matrixView       *gl.UniformLocation
+	matrixModel      *gl.UniformLocation
+
+	projectionMatrix []float32
+	viewMatrix       []float32
+	modelMatrix      []float32
+
+	camera        *CameraSystem
+	cameraEnabled bool
+
+	lastBuffer  *gl.Buffer
+	lastTexture *gl.Texture
+}
+
+func (l *textShader) Setup(w *ecs.World) error {
+	return l.setup(textFragmentShader)
+}
+
+// setup compiles the program from the text vertex shader and the given
+// fragment shader, and prepares the buffers and locations it uses.
+func (l *textShader) setup(fragment string) error {
+	var err error
+	l.program, err = LoadShader(textVertexShader, fragment)
 
 	if err != nil {
 		return err
@@ -88,15 +100,7 @@ void main (void) {
 	engo.Gl.BindBuffer(engo.Gl.ELEMENT_ARRAY_BUFFER, l.indicesRectanglesVBO)
 	engo.Gl.BufferData(engo.Gl.ELEMENT_ARRAY_BUFFER, l.indicesRectangles, engo.Gl.STATIC_DRAW)
 
-	// Define things that should be read from the texture buffer
-	l.inPosition = engo.Gl.GetAttribLocation(l.program, "in_Position")
-	l.inTexCoords = engo.Gl.GetAttribLocation(l.program, "in_TexCoords")
-	l.inColor = engo.Gl.GetAttribLocation(l.program, "in_Color")
-
-	// Define things that should be set per draw
-	l.matrixProjection = engo.Gl.GetUniformLocation(l.program, "matrixProjection")
-	l.matrixView = engo.Gl.GetUniformLocation(l.program, "matrixView")
-	l.matrixModel = engo.Gl.GetUniformLocation(l.program, "matrixModel")
+	l.locations()
 
 	l.projectionMatrix = make([]float32, 9)
 	l.projectionMatrix[8] = 1
@@ -114,6 +118,27 @@ void main (void) {
 	return nil
 }
 
+// locations looks up the attributes and uniforms of the program.
+func (l *textShader) locations() {
+	// Define things that should be read from the texture buffer
+	l.inPosition = engo.Gl.GetAttribLocation(l.program, "in_Position")
+	l.inTexCoords = engo.Gl.GetAttribLocation(l.program, "in_TexCoords")
+	l.inColor = engo.Gl.GetAttribLocation(l.program, "in_Color")
+
+	// Define things that should be set per draw
+	l.matrixProjection = engo.Gl.GetUniformLocation(l.program, "matrixProjection")
+	l.matrixView = engo.Gl.GetUniformLocation(l.program, "matrixView")
+	l.matrixModel = engo.Gl.GetUniformLocation(l.program, "matrixModel")
+}
+
+// SetProgram replaces the program of the shader, and returns the previous one.
+func (l *textShader) SetProgram(program *gl.Program) *gl.Program {
+	prev := l.program
+	l.program = program
+	l.locations()
+	return prev
+}
+
 func (l *textShader) Pre() {
 	engo.Gl.Enable(engo.Gl.BLEND)
 	engo.Gl.BlendFunc(engo.Gl.SRC_ALPHA, engo.Gl.ONE_MINUS_SRC_ALPHA)
@@ -125,13 +150,9 @@ func (l *textShader) Pre() {
 	engo.Gl.EnableVertexAttribArray(l.inTexCoords)
 	engo.Gl.EnableVertexAttribArray(l.inColor)
 
-	if engo.ScaleOnResize() {
-		l.projectionMatrix[0] = 1 / (engo.GameWidth() / 2)
-		l.projectionMatrix[4] = 1 / (-engo.GameHeight() / 2)
-	} else {
-		l.projectionMatrix[0] = 1 / (engo.CanvasWidth() / (2 * engo.CanvasScale()))
-		l.projectionMatrix[4] = 1 / (-engo.CanvasHeight() / (2 * engo.CanvasScale()))
-	}
+	viewW, viewH := viewSize()
+	l.projectionMatrix[0] = 1 / (viewW / 2)
+	l.projectionMatrix[4] = 1 / (-viewH / 2)
 
 	if l.cameraEnabled {
 		l.viewMatrix[1], l.viewMatrix[0] = math.Sincos(l.camera.angle * math.Pi / 180)
@@ -177,7 +198,7 @@ func (l *textShader) updateBuffer(ren *RenderComponent, space *SpaceComponent) {
 func (l *textShader) generateBufferContent(ren *RenderComponent, space *SpaceComponent, buffer []float32) bool {
 	var changed bool
 
-	tint := colorToFloat32(ren.Color)
+	tint := tintToFloat32(ren.Color, ren.Opacity)
 	txt, ok := ren.Drawable.(Text)
 	if !ok {
 		unsupportedType(ren.Drawable)
@@ -191,62 +212,48 @@ func (l *textShader) generateBufferContent(ren *RenderComponent, space *SpaceCom
 		atlasCache[*txt.Font] = atlas
 	}
 
-	var currentX float32
-	var currentY float32
-
-	var modifier float32 = 1
-	if txt.RightToLeft {
-		modifier = -1
-	}
-
-	letterSpace := float32(txt.Font.Size) * txt.LetterSpacing
-	lineSpace := txt.LineSpacing * atlas.Height['X']
-
-	for index, char := range txt.Text {
-		// TODO: this might not work for all characters
-		switch {
-		case char == '\n':
-			currentX = 0
-			currentY += atlas.Height['X'] + lineSpace
-			continue
-		case char < 32: // all system stuff should be ignored
-			continue
-		}
-
+	txt.layout(atlas, func(index int, char rune, currentX, currentY float32) {
 		offset := 20 * index
-		xoff := atlas.OffsetX[char]
-		yoff := atlas.OffsetY[char]
+		pad := atlas.Padding
+
+		// The quad covers the padding around the glyph as well
+		x0 := currentX + atlas.OffsetX[char] - pad
+		y0 := currentY + atlas.OffsetY[char] - pad
+		x1 := x0 + atlas.Width[char] + 2*pad
+		y1 := y0 + atlas.Height[char] + 2*pad
+		u0 := (atlas.XLocation[char] - pad) / atlas.TotalWidth
+		v0 := (atlas.YLocation[char] - pad) / atlas.TotalHeight
+		u1 := (atlas.XLocation[char] + atlas.Width[char] + pad) / atlas.TotalWidth
+		v1 := (atlas.YLocation[char] + atlas.Height[char] + pad) / atlas.TotalHeight
 
 		// These five are at 0, 0:
-		setBufferValue(buffer, 0+offset, currentX+xoff, &changed)
-		setBufferValue(buffer, 1+offset, currentY+yoff, &changed)
-		setBufferValue(buffer, 2+offset, atlas.XLocation[char]/atlas.TotalWidth, &changed)
-		setBufferValue(buffer, 3+offset, atlas.YLocation[char]/atlas.TotalHeight, &changed)
+		setBufferValue(buffer, 0+offset, x0, &changed)
+		setBufferValue(buffer, 1+offset, y0, &changed)
+		setBufferValue(buffer, 2+offset, u0, &changed)
+		setBufferValue(buffer, 3+offset, v0, &changed)
 		setBufferValue(buffer, 4+offset, tint, &changed)
 
 		// These five are at 1, 0:
-		setBufferValue(buffer, 5+offset, currentX+xoff+atlas.Width[char], &changed)
-		setBufferValue(buffer, 6+offset, currentY+yoff, &changed)
-		setBufferValue(buffer, 7+offset, (atlas.XLocation[char]+atlas.Width[char])/atlas.TotalWidth, &changed)
-		setBufferValue(buffer, 8+offset, atlas.YLocation[char]/atlas.TotalHeight, &changed)
+		setBufferValue(buffer, 5+offset, x1, &changed)
+		setBufferValue(buffer, 6+offset, y0, &changed)
+		setBufferValue(buffer, 7+offset, u1, &changed)
+		setBufferValue(buffer, 8+offset, v0, &changed)
 		setBufferValue(buffer, 9+offset, tint, &changed)
 
 		// These five are at 1, 1:
-		setBufferValue(buffer, 10+offset, currentX+xoff+atlas.Width[char], &changed)
-		setBufferValue(buffer, 11+offset, currentY+yoff+atlas.Height[char], &changed)
-		setBufferValue(buffer, 12+offset, (atlas.XLocation[char]+atlas.Width[char])/atlas.TotalWidth, &changed)
-		setBufferValue(buffer, 13+offset, (atlas.YLocation[char]+atlas.Height[char])/atlas.TotalHeight, &changed)
+		setBufferValue(buffer, 10+offset, x1, &changed)
+		setBufferValue(buffer, 11+offset, y1, &changed)
+		setBufferValue(buffer, 12+offset, u1, &changed)
+		setBufferValue(buffer, 13+offset, v1, &changed)
 		setBufferValue(buffer, 14+offset, tint, &changed)
 
 		// These five are at 0, 1:
-		setBufferValue(buffer, 15+offset, currentX+xoff, &changed)
-		setBufferValue(buffer, 16+offset, currentY+yoff+atlas.Height[char], &changed)
-		setBufferValue(buffer, 17+offset, atlas.XLocation[char]/atlas.TotalWidth, &changed)
-		setBufferValue(buffer, 18+offset, (atlas.YLocation[char]+atlas.Height[char])/atlas.TotalHeight, &changed)
+		setBufferValue(buffer, 15+offset, x0, &changed)
+		setBufferValue(buffer, 16+offset, y1, &changed)
+		setBufferValue(buffer, 17+offset, u0, &changed)
+		setBufferValue(buffer, 18+offset, v1, &changed)
 		setBufferValue(buffer, 19+offset, tint, &changed)
-
-		currentX += modifier * (atlas.Width[char] + letterSpace + xoff + atlas.RightSide[char])
-	}
+	})
 
 	return changed
 }
@@ -297,12 +304,20 @@ func (l *textShader) Draw(ren *RenderComponent, space *SpaceComponent) {
 		l.modelMatrix[4] = ren.Scale.Y * engo.GetGlobalScale().Y
 	}
 
-	l.modelMatrix[6] = space.Position.X * engo.GetGlobalScale().X
-	l.modelMatrix[7] = space.Position.Y * engo.GetGlobalScale().Y
+	// The anchor is the point of the Text drawn at the pivot, so the Text is only measured when there's an Origin
+	p, anchorX, anchorY := space.Position, float32(0), float32(0)
+	if ren.Origin != (engo.Point{}) {
+		w, h := txt.Width(), txt.Height()
+		p = pivot(ren, space, w*ren.Scale.X, h*ren.Scale.Y)
+		anchorX, anchorY = ren.Origin.X*w, ren.Origin.Y*h
+	}
+	l.modelMatrix[6] = p.X*engo.GetGlobalScale().X - l.modelMatrix[0]*anchorX - l.modelMatrix[3]*anchorY
+	l.modelMatrix[7] = p.Y*engo.GetGlobalScale().Y - l.modelMatrix[1]*anchorX - l.modelMatrix[4]*anchorY
 
 	engo.Gl.UniformMatrix3fv(l.matrixModel, false, l.modelMatrix)
 
 	engo.Gl.DrawElements(engo.Gl.TRIANGLES, 6*len(txt.Text), engo.Gl.UNSIGNED_SHORT, 0)
+	frameStats.DrawCalls++
 }
 
 func (l *textShader) Post() {
@@ -0,0 +1,50 @@
+package common
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/klopsch/engo"
+	"github.com/klopsch/gl"
+)
+
+// srgbToLinear converts a component of an sRGB color, from 0 to 1, to linear space.
+func srgbToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// linearColor returns the color converted to linear space, when the game renders in linear space with
+// engo.RunOptions.SRGB. Otherwise, or if it's nil, it's returned as it is.
+func linearColor(c color.Color) color.Color {
+	if c == nil || !engo.SRGB() {
+		return c
+	}
+	n := color.NRGBA64Model.Convert(c).(color.NRGBA64)
+	convert := func(v uint16) uint16 {
+		return uint16(srgbToLinear(float64(v)/0xffff)*0xffff + 0.5)
+	}
+	return color.NRGBA64{R: convert(n.R), G: convert(n.G), B: convert(n.B), A: n.A}
+}
+
+// textureFormat returns the internal format textures are uploaded with, which are decoded from sRGB when sampled if
+// the game renders in linear space.
+func textureFormat() int {
+	if engo.SRGB() {
+		return engo.Gl.SRGB8_ALPHA8
+	}
+	return engo.Gl.RGBA
+}
+
+// UploadLinearTexture sends the image to the GPU like UploadTexture, but it's never decoded from sRGB when sampled, for
+// images holding data rather than colors, such as normal maps, when the game renders in linear space.
+func UploadLinearTexture(img Image) *gl.Texture {
+	id := UploadTexture(img)
+	if engo.SRGB() && !engo.Headless() {
+		// UploadTexture leaves the texture bound
+		engo.Gl.TexImage2D(engo.Gl.TEXTURE_2D, 0, engo.Gl.RGBA, engo.Gl.RGBA, engo.Gl.UNSIGNED_BYTE, img.Data())
+	}
+	return id
+}
@@ -0,0 +1,195 @@
+package common
+
+import (
+	"image/color"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/klopsch/gl"
+)
+
+// RenderTarget is an offscreen texture the RenderSystem draws into, such as for in-game monitors, mirrors or
+// minimaps. It is a Drawable, so it can be used as the Drawable of other entities.
+//
+// Targets are drawn at the start of RenderSystem.Update, before the screen, so the contents of a target are from the
+// same frame as the screen. A target showing another target is one frame behind if it was added first.
+type RenderTarget struct {
+	// Camera is the camera the target is rendered through. When nil, the World's CameraSystem is used. Cameras
+	// which are not part of the World are initialized and updated by the RenderSystem.
+	Camera *CameraSystem
+	// Background is the color the target is cleared with before drawing. When nil, it's cleared to transparent.
+	Background color.Color
+
+	tex           *RenderTexture
+	fb            *Framebuffer
+	width, height int
+
+	// ids are the entities drawn to the target. When empty, all entities are drawn.
+	ids map[uint64]struct{}
+	// update is set when Camera is not part of the World, so the RenderSystem has to update it.
+	update bool
+}
+
+// NewRenderTarget creates a RenderTarget of the given size, in pixels. Each pixel shows one unit of the world when
+// the camera is not zoomed.
+func NewRenderTarget(width, height int) *RenderTarget {
+	rt := &RenderTarget{ids: make(map[uint64]struct{})}
+	rt.Resize(width, height)
+	return rt
+}
+
+// Resize changes the size of the target, in pixels. Its contents are lost until it's drawn again. Entities using the
+// target as their Drawable keep working, but their SpaceComponent and Scale are not changed.
+func (rt *RenderTarget) Resize(width, height int) {
+	if rt.tex != nil && rt.width == width && rt.height == height {
+		return
+	}
+	rt.Close()
+	rt.width, rt.height = width, height
+	if engo.Headless() {
+		return
+	}
+	rt.tex = CreateRenderTexture(width, height, false)
+	engo.Gl.TexParameteri(engo.Gl.TEXTURE_2D, engo.Gl.TEXTURE_MAG_FILTER, engo.Gl.LINEAR)
+	engo.Gl.TexParameteri(engo.Gl.TEXTURE_2D, engo.Gl.TEXTURE_MIN_FILTER, engo.Gl.LINEAR)
+	rt.fb = CreateFramebuffer()
+}
+
+// AddEntity makes the target draw the given entity. Once an entity is added, only the added entities are drawn
+// instead of all entities of the RenderSystem.
+func (rt *RenderTarget) AddEntity(basic *ecs.BasicEntity) {
+	if rt.ids == nil {
+		rt.ids = make(map[uint64]struct{})
+	}
+	rt.ids[basic.ID()] = struct{}{}
+}
+
+// RemoveEntity stops drawing the given entity to the target. When no entities are left, all entities of the
+// RenderSystem are drawn again.
+func (rt *RenderTarget) RemoveEntity(basic ecs.BasicEntity) {
+	delete(rt.ids, basic.ID())
+}
+
+// includes returns whether the entity is drawn to the target. Entities showing the target itself are never drawn
+// into it.
+func (rt *RenderTarget) includes(e renderEntity) bool {
+	if d, ok := e.RenderComponent.Drawable.(*RenderTarget); ok && d == rt {
+		return false
+	}
+	if len(rt.ids) == 0 {
+		return true
+	}
+	_, ok := rt.ids[e.BasicEntity.ID()]
+	return ok
+}
+
+// Texture returns the OpenGL ID of the texture the target is drawn to.
+func (rt *RenderTarget) Texture() *gl.Texture {
+	if rt.tex == nil {
+		return nil
+	}
+	return rt.tex.Texture()
+}
+
+// Width returns the width of the target, in pixels.
+func (rt *RenderTarget) Width() float32 {
+	return float32(rt.width)
+}
+
+// Height returns the height of the target, in pixels.
+func (rt *RenderTarget) Height() float32 {
+	return float32(rt.height)
+}
+
+// View returns the viewport properties of the target. The texture is flipped vertically, so it's shown the same
+// way as it's drawn on screen.
+func (rt *RenderTarget) View() (float32, float32, float32, float32) {
+	return 0, 1, 1, 0
+}
+
+// Close deletes the framebuffer and texture of the target. It can be drawn again after calling Resize.
+func (rt *RenderTarget) Close() {
+	if rt.fb != nil {
+		rt.fb.Destroy()
+		rt.fb = nil
+	}
+	if rt.tex != nil {
+		rt.tex.Close()
+		rt.tex = nil
+	}
+}
+
+// AddRenderTarget makes the RenderSystem draw into rt every frame.
+func (rs *RenderSystem) AddRenderTarget(rt *RenderTarget) {
+	rt.update = false
+	if rt.Camera != nil {
+		rt.update = true
+		if rs.world != nil {
+			for _, system := range rs.world.Systems() {
+				if system == rt.Camera {
+					rt.update = false
+				}
+			}
+		}
+		if rt.update && rt.Camera.longTasks == nil {
+			rt.Camera.setup()
+		}
+	}
+	rs.targets = append(rs.targets, rt)
+}
+
+// RemoveRenderTarget stops drawing into rt. It doesn't Close it.
+func (rs *RenderSystem) RemoveRenderTarget(rt *RenderTarget) {
+	targets := rs.targets[:0]
+	for _, t := range rs.targets {
+		if t != rt {
+			targets = append(targets, t)
+		}
+	}
+	rs.targets = targets
+}
+
+// drawTargets draws the entities into every RenderTarget.
+func (rs *RenderSystem) drawTargets(dt float32) {
+	if len(rs.targets) == 0 {
+		return
+	}
+
+	for _, rt := range rs.targets {
+		if rt.fb == nil {
+			continue
+		}
+		if rt.update {
+			rt.Camera.Update(dt)
+		}
+		if rt.Camera != nil {
+			for _, shader := range shaders {
+				shader.SetCamera(rt.Camera)
+			}
+		} else {
+			newCamera(rs.world)
+		}
+
+		// Each pixel of the target is one unit of the world
+		viewportScale = engo.Point{X: 1, Y: 1}
+		viewW, viewH := viewSize()
+		viewportScale = engo.Point{X: float32(rt.width) / viewW, Y: float32(rt.height) / viewH}
+
+		rt.fb.Open(rt.width, rt.height)
+		rt.tex.Bind()
+		if rt.Background != nil {
+			setClearColor(rt.Background)
+		} else {
+			setClearColor(color.Transparent)
+		}
+		engo.Gl.Clear(engo.Gl.COLOR_BUFFER_BIT)
+		rs.render(rt)
+		rt.fb.Close()
+	}
+
+	viewportScale = engo.Point{X: 1, Y: 1}
+	setClearColor(background)
+	if len(rs.viewports) == 0 {
+		newCamera(rs.world)
+	}
+}
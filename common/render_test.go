@@ -0,0 +1,329 @@
+package common
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSortingRenderSystem(n int) *RenderSystem {
+	rs := &RenderSystem{ids: make(map[uint64]struct{})}
+	for i := 0; i < n; i++ {
+		basic := ecs.NewBasic()
+		rs.Add(&basic, &RenderComponent{
+			Drawable:    Texture{},
+			StartZIndex: float32(i % 10),
+		}, &SpaceComponent{
+			Position: engo.Point{X: float32(i % 100), Y: float32(i / 100)},
+		})
+	}
+	return rs
+}
+
+func TestRenderSystemSortOnlyWhenDirty(t *testing.T) {
+	engo.Mailbox = &engo.MessageManager{}
+	rs := newSortingRenderSystem(10)
+	engo.Mailbox.Listen("renderChangeMessage", func(engo.Message) {
+		rs.MarkOrderDirty()
+	})
+
+	assert.True(t, rs.sortingNeeded, "Adding entities should require sorting")
+	rs.sortEntities()
+	assert.False(t, rs.sortingNeeded, "Sorting should reset the dirty flag")
+
+	rc := rs.entities[0].RenderComponent
+	rc.SetZIndex(rc.ZIndex())
+	assert.False(t, rs.sortingNeeded, "Setting the same Z-Index should not require sorting")
+
+	rc.SetZIndex(100)
+	assert.True(t, rs.sortingNeeded, "Changing the Z-Index should require sorting")
+	rs.sortEntities()
+	assert.Equal(t, rc, rs.entities[len(rs.entities)-1].RenderComponent, "The highest Z-Index should be drawn last")
+}
+
+// BenchmarkRenderSystem_SortStatic sorts a scene where nothing changes, which is skipped after the first frame.
+func BenchmarkRenderSystem_SortStatic(b *testing.B) {
+	rs := newSortingRenderSystem(10000)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		rs.sortEntities()
+	}
+}
+
+// BenchmarkRenderSystem_SortDirty sorts the same scene every frame, like happens when the order is always dirty.
+func BenchmarkRenderSystem_SortDirty(b *testing.B) {
+	rs := newSortingRenderSystem(10000)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		rs.MarkOrderDirty()
+		rs.sortEntities()
+	}
+}
+
+func TestBasicShaderFlip(t *testing.T) {
+	s := &basicShader{modelMatrix: engo.IdentityMatrix()}
+	ren := &RenderComponent{
+		Drawable: Texture{width: 10, height: 10, viewport: engo.AABB{Max: engo.Point{X: 1, Y: 1}}},
+		Scale:    engo.Point{X: 1, Y: 1},
+		Color:    color.White,
+	}
+	space := &SpaceComponent{}
+	buf := make([]float32, spriteSize)
+
+	s.generateBufferContent(ren, space, buf)
+	assert.Equal(t, []float32{0, 0, 1, 0, 1, 1, 0, 1}, []float32{buf[2], buf[3], buf[7], buf[8], buf[12], buf[13], buf[17], buf[18]}, "Texture coordinates should not be mirrored by default")
+
+	ren.FlipX = true
+	s.generateBufferContent(ren, space, buf)
+	assert.Equal(t, []float32{1, 0, 0, 0, 0, 1, 1, 1}, []float32{buf[2], buf[3], buf[7], buf[8], buf[12], buf[13], buf[17], buf[18]}, "FlipX should mirror the texture horizontally")
+
+	ren.FlipX, ren.FlipY = false, true
+	s.generateBufferContent(ren, space, buf)
+	assert.Equal(t, []float32{0, 1, 1, 1, 1, 0, 0, 0}, []float32{buf[2], buf[3], buf[7], buf[8], buf[12], buf[13], buf[17], buf[18]}, "FlipY should mirror the texture vertically")
+}
+
+func TestBasicShaderOpacity(t *testing.T) {
+	s := &basicShader{modelMatrix: engo.IdentityMatrix()}
+	ren := &RenderComponent{
+		Drawable: Texture{width: 10, height: 10, viewport: engo.AABB{Max: engo.Point{X: 1, Y: 1}}},
+		Scale:    engo.Point{X: 1, Y: 1},
+		Color:    color.White,
+		Opacity:  1,
+	}
+	space := &SpaceComponent{}
+	buf := make([]float32, spriteSize)
+	alpha := func() uint32 { return math.Float32bits(buf[4]) >> 24 }
+
+	s.generateBufferContent(ren, space, buf)
+	assert.Equal(t, uint32(0xff), alpha(), "An Opacity of 1 should keep the alpha of the Color")
+
+	ren.Opacity = 0.5
+	s.generateBufferContent(ren, space, buf)
+	assert.Equal(t, uint32(0x7f), alpha(), "An Opacity of 0.5 should halve the alpha")
+	assert.Equal(t, math.Float32bits(buf[4]), math.Float32bits(buf[9]), "Every vertex should use the same tint")
+
+	ren.Color = color.NRGBA{R: 255, G: 255, B: 255, A: 128}
+	s.generateBufferContent(ren, space, buf)
+	assert.Equal(t, uint32(0x40), alpha(), "Opacity should multiply the alpha of the Color")
+
+	ren.Opacity = 0
+	s.generateBufferContent(ren, space, buf)
+	assert.Equal(t, uint32(0), alpha(), "An Opacity of 0 should be fully transparent")
+}
+
+func TestRenderSystemDefaultOpacity(t *testing.T) {
+	rs := newSortingRenderSystem(0)
+	basic := ecs.NewBasic()
+	ren := &RenderComponent{Drawable: Texture{width: 10, height: 10}}
+	rs.Add(&basic, ren, &SpaceComponent{})
+	assert.Equal(t, float32(1), ren.Opacity, "Opacity should default to 1")
+}
+
+func TestNewTextureRegion(t *testing.T) {
+	tex := Texture{width: 100, height: 50, viewport: engo.AABB{Max: engo.Point{X: 1, Y: 1}}}
+
+	region, err := NewTextureRegion(tex, 25, 10, 50, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(50), region.Width())
+	assert.Equal(t, float32(20), region.Height())
+	u, v, u2, v2 := region.View()
+	assert.InDeltaSlice(t, []float32{0.25, 0.2, 0.75, 0.6}, []float32{u, v, u2, v2}, 1e-6, "The region should have its own texture coordinates")
+
+	// A region of a region is relative to the first region
+	sub, err := NewTextureRegion(region, 25, 10, 25, 10)
+	assert.NoError(t, err)
+	u, v, u2, v2 = sub.View()
+	assert.InDeltaSlice(t, []float32{0.5, 0.4, 0.75, 0.6}, []float32{u, v, u2, v2}, 1e-6, "A region of a region should be within the first region")
+
+	_, err = NewTextureRegion(tex, 60, 0, 50, 10)
+	assert.Error(t, err, "Regions outside of the texture should not be allowed")
+	_, err = NewTextureRegion(tex, -1, 0, 10, 10)
+	assert.Error(t, err, "Regions outside of the texture should not be allowed")
+	_, err = NewTextureRegion(tex, 0, 0, 0, 10)
+	assert.Error(t, err, "Empty regions should not be allowed")
+}
+
+func TestRenderTargetIncludes(t *testing.T) {
+	rt := &RenderTarget{width: 64, height: 32}
+	a, b := ecs.NewBasic(), ecs.NewBasic()
+	ea := renderEntity{&a, &RenderComponent{Drawable: Texture{}}, &SpaceComponent{}}
+	eb := renderEntity{&b, &RenderComponent{Drawable: rt}, &SpaceComponent{}}
+
+	assert.True(t, rt.includes(ea), "All entities should be drawn when none were added")
+	assert.False(t, rt.includes(eb), "Entities showing the target should not be drawn into it")
+
+	c := ecs.NewBasic()
+	rt.AddEntity(&c)
+	assert.False(t, rt.includes(ea), "Only added entities should be drawn")
+	assert.True(t, rt.includes(renderEntity{&c, &RenderComponent{}, &SpaceComponent{}}))
+
+	rt.RemoveEntity(c)
+	assert.True(t, rt.includes(ea), "All entities should be drawn again once every entity was removed")
+
+	assert.Equal(t, float32(64), rt.Width())
+	assert.Equal(t, float32(32), rt.Height())
+}
+
+func TestClampAnisotropy(t *testing.T) {
+	_, ok := clampAnisotropy(8, 0)
+	assert.False(t, ok, "Anisotropy should be skipped without driver support")
+	_, ok = clampAnisotropy(8, 1)
+	assert.False(t, ok, "Anisotropy should be skipped without driver support")
+
+	level, ok := clampAnisotropy(8, 16)
+	assert.True(t, ok)
+	assert.Equal(t, float32(8), level)
+
+	level, _ = clampAnisotropy(32, 16)
+	assert.Equal(t, float32(16), level, "Levels should be capped to the driver's max")
+
+	level, _ = clampAnisotropy(0, 16)
+	assert.Equal(t, float32(1), level, "Levels below 1 should disable anisotropic filtering")
+}
+
+func TestAnisotropyHeadless(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &CameraTestScene{})
+
+	assert.Nil(t, newAnisotropy(), "There's no OpenGL context to filter textures with in headless mode")
+
+	applyAnisotropy(4)
+	assert.True(t, anisotropyContext == engo.Gl, "The support of the context should be checked once, and kept")
+	assert.Nil(t, anisotropy)
+}
+
+type notReloadableShader struct {
+	Shader
+}
+
+func TestShadersReloadable(t *testing.T) {
+	for _, s := range shaders {
+		_, ok := s.(ReloadableShader)
+		assert.True(t, ok, "%T should be reloadable", s)
+	}
+	assert.Error(t, ReloadShader(notReloadableShader{DefaultShader}, "", ""), "Shaders which aren't reloadable should not be reloaded")
+}
+
+// cullingTestShader draws nothing, and culls the entities left of the origin.
+type cullingTestShader struct{}
+
+func (cullingTestShader) Setup(*ecs.World) error                 { return nil }
+func (cullingTestShader) Pre()                                   {}
+func (cullingTestShader) Draw(*RenderComponent, *SpaceComponent) {}
+func (cullingTestShader) Post()                                  {}
+func (cullingTestShader) SetCamera(*CameraSystem)                {}
+func (cullingTestShader) PrepareCulling()                        {}
+func (cullingTestShader) ShouldDraw(_ *RenderComponent, space *SpaceComponent) bool {
+	return space.Position.X >= 0
+}
+
+func TestRenderSystemStats(t *testing.T) {
+	engo.Mailbox = &engo.MessageManager{}
+	rs := &RenderSystem{ids: make(map[uint64]struct{})}
+	for _, x := range []float32{-10, 0, 10, 20} {
+		basic := ecs.NewBasic()
+		render := &RenderComponent{Drawable: Texture{}}
+		render.SetShader(cullingTestShader{})
+		rs.Add(&basic, render, &SpaceComponent{Position: engo.Point{X: x}})
+	}
+	hidden := ecs.NewBasic()
+	rs.Add(&hidden, &RenderComponent{Drawable: Texture{}, Hidden: true}, &SpaceComponent{})
+
+	start := frameStats
+	rs.render(nil)
+	stats := frameStats.sub(start)
+	assert.Equal(t, 3, stats.Sprites, "Drawn entities should be counted")
+	assert.Equal(t, 1, stats.Culled, "Culled entities should be counted")
+	assert.Equal(t, 0, stats.DrawCalls)
+}
+
+func TestBackgroundGradient(t *testing.T) {
+	defer SetBackground(color.Transparent)
+
+	top, bottom := color.RGBA{0, 0, 255, 255}, color.RGBA{255, 255, 255, 255}
+	SetBackgroundGradient(top, bottom)
+	if assert.NotNil(t, backgroundCorners) {
+		assert.Equal(t, [4]color.Color{top, top, bottom, bottom}, *backgroundCorners)
+	}
+
+	vertices := backgroundVertices(200, 100, *backgroundCorners)
+	assert.Equal(t, []float32{0, 0, 200, 0, 200, 100, 0, 100},
+		[]float32{vertices[0], vertices[1], vertices[3], vertices[4], vertices[6], vertices[7], vertices[9], vertices[10]},
+		"the quad should cover the view")
+	assert.Equal(t, tintToFloat32(top, 1), vertices[2])
+	assert.Equal(t, tintToFloat32(bottom, 1), vertices[11])
+
+	SetBackground(color.Black)
+	assert.Nil(t, backgroundCorners, "SetBackground should remove the gradient")
+}
+
+func TestLinearColor(t *testing.T) {
+	assert.InDelta(t, 0, srgbToLinear(0), 1e-9)
+	assert.InDelta(t, 0.2140, srgbToLinear(0.5), 1e-4)
+	assert.InDelta(t, 1, srgbToLinear(1), 1e-9)
+
+	gray := color.NRGBA{128, 128, 128, 128}
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &CameraTestScene{})
+	assert.Equal(t, gray, linearColor(gray), "colors should be left as they are when rendering in sRGB space")
+
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+		SRGB:         true,
+	}, &CameraTestScene{})
+	defer engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &CameraTestScene{})
+
+	linear := color.NRGBAModel.Convert(linearColor(gray)).(color.NRGBA)
+	assert.Equal(t, color.NRGBA{55, 55, 55, 128}, linear, "the alpha of the color should stay the same")
+	assert.Nil(t, linearColor(nil), "unset colors should stay unset")
+}
+
+func TestMaskChain(t *testing.T) {
+	outer := NewMask(&SpaceComponent{}, nil)
+	inner := NewMask(&SpaceComponent{}, outer)
+	innermost := NewMask(&SpaceComponent{}, inner)
+
+	assert.Equal(t, 1, outer.depth())
+	assert.Equal(t, 3, innermost.depth())
+	assert.Equal(t, []*Mask{outer, inner, innermost}, innermost.chain(), "Masks should be applied from the outermost")
+}
+
+func TestBasicShaderOrigin(t *testing.T) {
+	engo.SetGlobalScale(engo.Point{X: 1, Y: 1})
+	s := &basicShader{modelMatrix: engo.IdentityMatrix()}
+	ren := &RenderComponent{
+		Drawable: Texture{width: 10, height: 10, viewport: engo.AABB{Max: engo.Point{X: 1, Y: 1}}},
+		Scale:    engo.Point{X: 2, Y: 2},
+		Origin:   engo.Point{X: 0.5, Y: 0.5},
+	}
+	space := &SpaceComponent{Width: 20, Height: 20}
+	transformed := func(x, y float32, expected engo.Point) bool {
+		v := engo.MultiplyMatrixVector(s.makeModelMatrix(ren, space), []float32{x, y})
+		return expected.Equal(engo.Point{X: v[0], Y: v[1]})
+	}
+
+	assert.True(t, transformed(0, 0, engo.Point{}), "Without rotation, the sprite should stay within its space")
+	assert.True(t, transformed(5, 5, engo.Point{X: 10, Y: 10}), "The Origin of the sprite should be drawn at the pivot")
+
+	space.Rotation = 90
+	space.pivot = ren.Origin
+	assert.True(t, transformed(0, 0, space.Corners()[0]), "The sprite should rotate about its Origin like its SpaceComponent")
+
+	ren.Scale.X = -2
+	space.Rotation = 0
+	assert.True(t, transformed(10, 0, engo.Point{}), "A negative scale should flip the sprite in place")
+}
@@ -0,0 +1,119 @@
+package common
+
+import (
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/klopsch/engo/math"
+)
+
+// EnterViewMessage is sent by the RenderSystem when an entity comes into the view of a camera, if its ViewEvents are
+// enabled.
+type EnterViewMessage struct {
+	Entity *ecs.BasicEntity
+}
+
+// Type implements the engo.Message interface.
+func (EnterViewMessage) Type() string {
+	return "EnterViewMessage"
+}
+
+// ExitViewMessage is sent by the RenderSystem when an entity leaves the view of every camera, if its ViewEvents are
+// enabled.
+type ExitViewMessage struct {
+	Entity *ecs.BasicEntity
+}
+
+// Type implements the engo.Message interface.
+func (ExitViewMessage) Type() string {
+	return "ExitViewMessage"
+}
+
+// viewAreas returns the areas of the world shown by the cameras, extended by the ViewMargin.
+func (rs *RenderSystem) viewAreas() []engo.AABB {
+	viewW, viewH := viewSize()
+	var areas []engo.AABB
+	add := func(cam *CameraSystem, w, h float32) {
+		center, half := cam.visibleArea(w, h)
+		half.X += rs.ViewMargin
+		half.Y += rs.ViewMargin
+		areas = append(areas, engo.AABB{
+			Min: engo.Point{X: center.X - half.X, Y: center.Y - half.Y},
+			Max: engo.Point{X: center.X + half.X, Y: center.Y + half.Y},
+		})
+	}
+	if len(rs.viewports) == 0 {
+		if rs.camera != nil {
+			add(rs.camera, viewW, viewH)
+		}
+		return areas
+	}
+	for _, vp := range rs.viewports {
+		add(vp.Camera, viewW*(vp.Viewport.Max.X-vp.Viewport.Min.X), viewH*(vp.Viewport.Max.Y-vp.Viewport.Min.Y))
+	}
+	return areas
+}
+
+// updateView sends the EnterViewMessages and ExitViewMessages of the entities which came into or left the view since
+// the last frame. Entities drawn on the HUD are in view when they're on the screen.
+func (rs *RenderSystem) updateView() {
+	if rs.inView == nil {
+		rs.inView = make(map[uint64]struct{})
+	}
+
+	areas := rs.viewAreas()
+	scale := engo.GetGlobalScale()
+	viewW, viewH := viewSize()
+	hud := []engo.AABB{{
+		Min: engo.Point{X: -rs.ViewMargin, Y: -rs.ViewMargin},
+		Max: engo.Point{X: viewW/scale.X + rs.ViewMargin, Y: viewH/scale.Y + rs.ViewMargin},
+	}}
+
+	// The messages are sent once all entities are checked, as their handlers may remove entities
+	var msgs []engo.Message
+	for _, e := range rs.entities {
+		if e.RenderComponent.Drawable == nil {
+			continue
+		}
+		in := areas
+		if isHUD(e.RenderComponent) {
+			in = hud
+		}
+		visible := overlapsAny(drawnBounds(e.RenderComponent, e.SpaceComponent), in)
+
+		id := e.BasicEntity.ID()
+		_, wasVisible := rs.inView[id]
+		switch {
+		case visible && !wasVisible:
+			rs.inView[id] = struct{}{}
+			msgs = append(msgs, EnterViewMessage{Entity: e.BasicEntity})
+		case !visible && wasVisible:
+			delete(rs.inView, id)
+			msgs = append(msgs, ExitViewMessage{Entity: e.BasicEntity})
+		}
+	}
+	for _, msg := range msgs {
+		engo.Mailbox.Dispatch(msg)
+	}
+}
+
+// drawnBounds returns the bounding box of the space the Drawable of the entity is drawn in.
+func drawnBounds(rc *RenderComponent, sc *SpaceComponent) engo.AABB {
+	tsc := drawnSpace(rc, sc)
+	c := tsc.Corners()
+	b := engo.AABB{Min: c[0], Max: c[0]}
+	for _, p := range c[1:] {
+		b.Min.X, b.Min.Y = math.Min(b.Min.X, p.X), math.Min(b.Min.Y, p.Y)
+		b.Max.X, b.Max.Y = math.Max(b.Max.X, p.X), math.Max(b.Max.Y, p.Y)
+	}
+	return b
+}
+
+// overlapsAny returns whether b overlaps any of the areas, including touching edges.
+func overlapsAny(b engo.AABB, areas []engo.AABB) bool {
+	for _, a := range areas {
+		if b.Min.X <= a.Max.X && a.Min.X <= b.Max.X && b.Min.Y <= a.Max.Y && a.Min.Y <= b.Max.Y {
+			return true
+		}
+	}
+	return false
+}
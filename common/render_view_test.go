@@ -0,0 +1,55 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderSystemViewEvents(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:         true,
+		HeadlessMode:  true,
+		Width:         200,
+		Height:        100,
+		ScaleOnResize: true,
+	}, &cameraConversionScene{})
+	initialize()
+
+	var entered, exited []uint64
+	engo.Mailbox.Listen("EnterViewMessage", func(msg engo.Message) {
+		entered = append(entered, msg.(EnterViewMessage).Entity.ID())
+	})
+	engo.Mailbox.Listen("ExitViewMessage", func(msg engo.Message) {
+		exited = append(exited, msg.(ExitViewMessage).Entity.ID())
+	})
+
+	// The camera shows 50 to 250 horizontally, extended to 40 to 260 by the margin
+	rs := &RenderSystem{ViewEvents: true, ViewMargin: 10, ids: make(map[uint64]struct{}), camera: cam}
+	basic := ecs.NewBasic()
+	space := &SpaceComponent{Position: engo.Point{X: 300, Y: 150}}
+	rs.Add(&basic, &RenderComponent{Drawable: Texture{width: 10, height: 10}}, space)
+
+	rs.Update(1)
+	assert.Empty(t, entered, "Entities outside of the view should not enter it")
+
+	space.Position.X = 255
+	rs.Update(1)
+	assert.Equal(t, []uint64{basic.ID()}, entered, "Entities should enter the view within the margin")
+	rs.Update(1)
+	assert.Len(t, entered, 1, "Entities should only enter the view once")
+
+	space.Position.X = 400
+	rs.Update(1)
+	rs.Update(1)
+	assert.Equal(t, []uint64{basic.ID()}, exited, "Entities should exit the view once")
+
+	hud := ecs.NewBasic()
+	hudRender := &RenderComponent{Drawable: Texture{width: 10, height: 10}}
+	hudRender.SetShader(HUDShader)
+	rs.Add(&hud, hudRender, &SpaceComponent{Position: engo.Point{X: 20, Y: 20}})
+	rs.Update(1)
+	assert.Equal(t, []uint64{basic.ID(), hud.ID()}, entered, "HUD entities should be in view on the screen")
+}
@@ -0,0 +1,435 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io"
+	"reflect"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+)
+
+// ComponentCodec converts a component to and from JSON, for components with fields JSON can't hold, such as
+// textures. The component is always a pointer to the registered type.
+type ComponentCodec struct {
+	Marshal   func(component interface{}) ([]byte, error)
+	Unmarshal func(data []byte, component interface{}) error
+}
+
+var (
+	savedComponents = make(map[reflect.Type]savedComponent)
+	savedEntities   = make(map[reflect.Type]string)
+	savedEntityName = make(map[string]reflect.Type)
+	savedDrawables  = make(map[string]Drawable)
+	savedShaders    = make(map[string]Shader)
+)
+
+// savedComponent is a component type registered with RegisterComponent.
+type savedComponent struct {
+	name  string
+	codec *ComponentCodec
+}
+
+func init() {
+	RegisterComponent("SpaceComponent", SpaceComponent{}, nil)
+	RegisterComponent("CollisionComponent", CollisionComponent{}, nil)
+	RegisterComponent("RenderComponent", RenderComponent{}, &ComponentCodec{
+		Marshal:   marshalRenderComponent,
+		Unmarshal: unmarshalRenderComponent,
+	})
+}
+
+// RegisterComponent declares that the components of the type of component are saved by the SaveSystem, under name.
+// Components are converted with encoding/json unless a codec is given, so only their exported fields are saved; the
+// unexported ones are left as they're set by the entity type registered with RegisterEntity. The SpaceComponent,
+// CollisionComponent and RenderComponent are registered already, however the hitboxes of SpaceComponents aren't saved.
+func RegisterComponent(name string, component interface{}, codec *ComponentCodec) {
+	t := reflect.TypeOf(component)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	savedComponents[t] = savedComponent{name: name, codec: codec}
+}
+
+// RegisterEntity declares that the entities of the type of entity, which has to be a pointer to a struct, are saved by
+// the SaveSystem under name. The registered components which are fields of the struct, or of the structs embedded
+// in it, are saved along with it. Entities of other types are not saved.
+func RegisterEntity(name string, entity ecs.Identifier) {
+	t := reflect.TypeOf(entity)
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("unable to register %T, entities have to be pointers to structs", entity))
+	}
+	savedEntities[t] = name
+	savedEntityName[name] = t
+}
+
+// RegisterDrawable names a Drawable, such as a sprite of a loaded Spritesheet, so that the RenderComponents drawing it
+// are saved with the key and draw it again once loaded. Drawables are compared with ==, so Drawables which aren't
+// comparable, such as Spritesheets, can't be registered.
+func RegisterDrawable(key string, d Drawable) {
+	savedDrawables[key] = d
+}
+
+// RegisterShader names a custom Shader, so that the RenderComponents using it are saved with the name and use it again
+// once loaded. The built-in Shaders are known already.
+func RegisterShader(name string, s Shader) {
+	savedShaders[name] = s
+}
+
+// SaveSystem keeps track of the entities of a World to save them to JSON for save games, and to load them back.
+// Add it with World.AddSystemInterface using Saveable and NotSaveable so it tracks every entity.
+type SaveSystem struct {
+	world    *ecs.World
+	entities []Saveable
+}
+
+// New is called when the SaveSystem is added to the world.
+func (s *SaveSystem) New(w *ecs.World) {
+	s.world = w
+}
+
+// Add adds an entity to the SaveSystem. Only the entities of types registered with RegisterEntity are saved.
+func (s *SaveSystem) Add(entity Saveable) {
+	s.entities = append(s.entities, entity)
+}
+
+// AddByInterface adds any Saveable to the SaveSystem.
+func (s *SaveSystem) AddByInterface(i ecs.Identifier) {
+	o, _ := i.(Saveable)
+	s.Add(o)
+}
+
+// Remove removes an entity from the SaveSystem.
+func (s *SaveSystem) Remove(basic ecs.BasicEntity) {
+	delete := -1
+	for index, e := range s.entities {
+		if e.ID() == basic.ID() {
+			delete = index
+			break
+		}
+	}
+	if delete >= 0 {
+		s.entities = append(s.entities[:delete], s.entities[delete+1:]...)
+	}
+}
+
+// EntityCount returns the number of entities the SaveSystem holds.
+func (s *SaveSystem) EntityCount() int {
+	return len(s.entities)
+}
+
+// Update doesn't do anything, since entities are only saved and loaded when asked to.
+func (s *SaveSystem) Update(dt float32) {}
+
+// savedWorld is the JSON document written by SaveSystem.Save.
+type savedWorld struct {
+	Entities []savedEntity `json:"entities"`
+}
+
+type savedEntity struct {
+	Type       string                     `json:"type"`
+	Components map[string]json.RawMessage `json:"components"`
+}
+
+// Save writes the registered components of every entity of a registered type to w as JSON.
+func (s *SaveSystem) Save(w io.Writer) error {
+	world := savedWorld{Entities: []savedEntity{}}
+	for _, e := range s.entities {
+		name, ok := savedEntities[reflect.TypeOf(e)]
+		if !ok {
+			continue
+		}
+		saved := savedEntity{Type: name, Components: make(map[string]json.RawMessage)}
+		for _, c := range entityComponents(reflect.ValueOf(e).Elem(), false) {
+			data, err := c.marshal()
+			if err != nil {
+				return fmt.Errorf("unable to save %s of entity %d: %s", c.name, e.ID(), err)
+			}
+			saved.Components[c.name] = data
+		}
+		world.Entities = append(world.Entities, saved)
+	}
+	return json.NewEncoder(w).Encode(world)
+}
+
+// Load reads the JSON written by Save from r, and replaces the entities of registered types of the World with the
+// ones it holds. New entities of the registered types are created, with their registered components set to the
+// saved ones, and added to the World, so they're added to all its Systems. The entities get new IDs, so IDs kept
+// by the game from before saving aren't valid anymore.
+func (s *SaveSystem) Load(r io.Reader) error {
+	var world savedWorld
+	if err := json.NewDecoder(r).Decode(&world); err != nil {
+		return fmt.Errorf("unable to load save: %s", err)
+	}
+
+	loaded := make([]ecs.Identifier, 0, len(world.Entities))
+	for _, saved := range world.Entities {
+		t, ok := savedEntityName[saved.Type]
+		if !ok {
+			return fmt.Errorf("unable to load save: entity type %q isn't registered", saved.Type)
+		}
+		e := reflect.New(t.Elem())
+		for _, c := range entityComponents(e.Elem(), true) {
+			data, ok := saved.Components[c.name]
+			if !ok {
+				continue
+			}
+			if err := c.unmarshal(data); err != nil {
+				return fmt.Errorf("unable to load %s of %s: %s", c.name, saved.Type, err)
+			}
+		}
+		loaded = append(loaded, e.Interface().(ecs.Identifier))
+	}
+
+	// Removing entities from the World removes them from the SaveSystem as well
+	current := append([]Saveable(nil), s.entities...)
+	for _, e := range current {
+		if _, ok := savedEntities[reflect.TypeOf(e)]; ok {
+			s.world.RemoveEntity(*e.GetBasicEntity())
+		}
+	}
+	for _, e := range loaded {
+		s.world.AddEntity(e)
+	}
+	return nil
+}
+
+// entityComponent is a registered component of an entity.
+type entityComponent struct {
+	name      string
+	codec     *ComponentCodec
+	component interface{}
+}
+
+func (c entityComponent) marshal() ([]byte, error) {
+	if c.codec != nil {
+		return c.codec.Marshal(c.component)
+	}
+	return json.Marshal(c.component)
+}
+
+func (c entityComponent) unmarshal(data []byte) error {
+	if c.codec != nil {
+		return c.codec.Unmarshal(data, c.component)
+	}
+	return json.Unmarshal(data, c.component)
+}
+
+// entityComponents returns the registered components of the entity struct v, including the ones of embedded structs.
+// With create, nil pointers to components are set to new components, and the BasicEntity to a new one.
+func entityComponents(v reflect.Value, create bool) []entityComponent {
+	var components []entityComponent
+	for i := 0; i < v.NumField(); i++ {
+		f, field := v.Field(i), v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		t := f.Type()
+		ptr := t.Kind() == reflect.Ptr
+		if ptr {
+			t = t.Elem()
+		}
+
+		if t == reflect.TypeOf(ecs.BasicEntity{}) {
+			if create {
+				basic := ecs.NewBasic()
+				if ptr {
+					f.Set(reflect.ValueOf(&basic))
+				} else {
+					f.Set(reflect.ValueOf(basic))
+				}
+			}
+			continue
+		}
+
+		c, ok := savedComponents[t]
+		if !ok {
+			if t.Kind() == reflect.Struct && field.Anonymous && !(ptr && f.IsNil()) {
+				if ptr {
+					f = f.Elem()
+				}
+				components = append(components, entityComponents(f, create)...)
+			}
+			continue
+		}
+		if !ptr {
+			f = f.Addr()
+		} else if f.IsNil() {
+			if !create {
+				continue
+			}
+			f.Set(reflect.New(t))
+		}
+		components = append(components, entityComponent{name: c.name, codec: c.codec, component: f.Interface()})
+	}
+	return components
+}
+
+// savedRenderComponent is how a RenderComponent is saved, with its Drawable, NormalMap and Shader by name. Its Mask
+// refers to another entity's SpaceComponent, so entities with a Mask can't be saved.
+type savedRenderComponent struct {
+	Hidden      bool
+	Scale       engo.Point
+	Origin      engo.Point
+	Color       *color.NRGBA       `json:",omitempty"`
+	Opacity     *float32           `json:",omitempty"`
+	Drawable    string             `json:",omitempty"`
+	NormalMap   string             `json:",omitempty"`
+	Shader      string             `json:",omitempty"`
+	Repeat      TextureRepeating   `json:",omitempty"`
+	FlipX       bool               `json:",omitempty"`
+	FlipY       bool               `json:",omitempty"`
+	BlendMode   BlendMode          `json:",omitempty"`
+	ZIndex      float32            `json:",omitempty"`
+	StartZIndex float32            `json:",omitempty"`
+	MagFilter   ZoomFilter         `json:",omitempty"`
+	MinFilter   ZoomFilter         `json:",omitempty"`
+	Uniforms    map[string]float32 `json:",omitempty"`
+}
+
+func marshalRenderComponent(component interface{}) ([]byte, error) {
+	r := component.(*RenderComponent)
+	saved := savedRenderComponent{
+		Hidden:      r.Hidden,
+		Scale:       r.Scale,
+		Opacity:     &r.Opacity,
+		Repeat:      r.Repeat,
+		FlipX:       r.FlipX,
+		FlipY:       r.FlipY,
+		BlendMode:   r.BlendMode,
+		Origin:      r.Origin,
+		ZIndex:      r.zIndex,
+		StartZIndex: r.StartZIndex,
+		MagFilter:   r.magFilter,
+		MinFilter:   r.minFilter,
+		Uniforms:    r.uniforms,
+	}
+	if r.Color != nil {
+		c := color.NRGBAModel.Convert(r.Color).(color.NRGBA)
+		saved.Color = &c
+	}
+	if r.Drawable != nil {
+		key, ok := drawableKey(r.Drawable)
+		if !ok {
+			return nil, fmt.Errorf("drawable %T isn't registered with RegisterDrawable", r.Drawable)
+		}
+		saved.Drawable = key
+	}
+	if r.NormalMap != nil {
+		key, ok := drawableKey(r.NormalMap)
+		if !ok {
+			return nil, fmt.Errorf("normal map %T isn't registered with RegisterDrawable", r.NormalMap)
+		}
+		saved.NormalMap = key
+	}
+	if r.Mask != nil {
+		return nil, fmt.Errorf("entities with a Mask can't be saved")
+	}
+	if r.shader != nil {
+		name, ok := shaderName(r.shader)
+		if !ok {
+			return nil, fmt.Errorf("shader %T isn't registered with RegisterShader", r.shader)
+		}
+		saved.Shader = name
+	}
+	return json.Marshal(saved)
+}
+
+func unmarshalRenderComponent(data []byte, component interface{}) error {
+	var saved savedRenderComponent
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	r := component.(*RenderComponent)
+	r.Hidden = saved.Hidden
+	r.Scale = saved.Scale
+	if saved.Opacity != nil {
+		// A transparent entity stays transparent when it's added to the RenderSystem
+		r.Opacity = *saved.Opacity
+		r.keepOpacity = r.Opacity == 0
+	}
+	r.Repeat = saved.Repeat
+	r.FlipX, r.FlipY = saved.FlipX, saved.FlipY
+	r.BlendMode = saved.BlendMode
+	r.Origin = saved.Origin
+	r.zIndex = saved.ZIndex
+	r.StartZIndex = saved.StartZIndex
+	r.magFilter, r.minFilter = saved.MagFilter, saved.MinFilter
+	r.uniforms = saved.Uniforms
+	if saved.Color != nil {
+		r.Color = *saved.Color
+	}
+	if saved.Drawable != "" {
+		d, ok := savedDrawables[saved.Drawable]
+		if !ok {
+			return fmt.Errorf("drawable %q isn't registered with RegisterDrawable", saved.Drawable)
+		}
+		r.Drawable = d
+	}
+	if saved.NormalMap != "" {
+		d, ok := savedDrawables[saved.NormalMap]
+		if !ok {
+			return fmt.Errorf("normal map %q isn't registered with RegisterDrawable", saved.NormalMap)
+		}
+		r.NormalMap = d
+	}
+	if saved.Shader != "" {
+		s, ok := namedShaders()[saved.Shader]
+		if !ok {
+			return fmt.Errorf("shader %q isn't registered with RegisterShader", saved.Shader)
+		}
+		r.shader = s
+	}
+	return nil
+}
+
+// drawableKey returns the key d is registered with by RegisterDrawable.
+func drawableKey(d Drawable) (string, bool) {
+	for key, registered := range savedDrawables {
+		if sameValue(d, registered) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// shaderName returns the name of s, for the built-in Shaders and the ones registered with RegisterShader.
+func shaderName(s Shader) (string, bool) {
+	for name, known := range namedShaders() {
+		if sameValue(s, known) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// namedShaders returns the Shaders which can be saved by name.
+func namedShaders() map[string]Shader {
+	all := map[string]Shader{
+		"DefaultShader":    DefaultShader,
+		"HUDShader":        HUDShader,
+		"LegacyShader":     LegacyShader,
+		"LegacyHUDShader":  LegacyHUDShader,
+		"TextShader":       TextShader,
+		"TextHUDShader":    TextHUDShader,
+		"SDFTextShader":    SDFTextShader,
+		"SDFTextHUDShader": SDFTextHUDShader,
+		"BlendmapShader":   BlendmapShader,
+		"InstancedShader":  InstancedShader,
+		"DissolveShader":   DissolveShader,
+		"OutlineShader":    OutlineShader,
+		"LightingShader":   LightingShader,
+	}
+	for name, s := range savedShaders {
+		all[name] = s
+	}
+	return all
+}
+
+// sameValue returns whether a and b are equal, without panicking when they're not comparable.
+func sameValue(a, b interface{}) bool {
+	t := reflect.TypeOf(a)
+	return t == reflect.TypeOf(b) && t.Comparable() && a == b
+}
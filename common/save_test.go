@@ -0,0 +1,158 @@
+package common
+
+import (
+	"bytes"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+type saveTestEntity struct {
+	ecs.BasicEntity
+	RenderComponent
+	*SpaceComponent
+	Health int
+}
+
+type saveTestHealth struct {
+	Points int
+}
+
+type saveTestPlayer struct {
+	*ecs.BasicEntity
+	saveTestHealth
+	CollisionComponent
+}
+
+func newSaveTestWorld() (*ecs.World, *SaveSystem) {
+	w := &ecs.World{}
+	sys := &SaveSystem{}
+	var s *Saveable
+	var nots *NotSaveable
+	w.AddSystemInterface(sys, s, nots)
+	return w, sys
+}
+
+func TestSaveSystemRoundTrip(t *testing.T) {
+	engo.Mailbox = &engo.MessageManager{}
+	RegisterEntity("saveTestEntity", &saveTestEntity{})
+	RegisterEntity("saveTestPlayer", &saveTestPlayer{})
+	RegisterComponent("saveTestHealth", saveTestHealth{}, nil)
+	sprite := Texture{width: 16, height: 16}
+	RegisterDrawable("sprite", sprite)
+	normals := Texture{width: 16, height: 17}
+	RegisterDrawable("normals", normals)
+
+	w, sys := newSaveTestWorld()
+	e := &saveTestEntity{
+		BasicEntity: ecs.NewBasic(),
+		RenderComponent: RenderComponent{
+			Drawable:  sprite,
+			Scale:     engo.Point{X: 2, Y: 2},
+			Color:     color.RGBA{R: 255, A: 255},
+			Opacity:   0.5,
+			NormalMap: normals,
+			BlendMode: BlendAdditive,
+			Origin:    engo.Point{X: 0.5, Y: 1},
+		},
+		SpaceComponent: &SpaceComponent{Position: engo.Point{X: 10, Y: 20}, Width: 16, Height: 16},
+		Health:         3,
+	}
+	e.RenderComponent.SetShader(HUDShader)
+	e.RenderComponent.SetZIndex(5)
+	basic := ecs.NewBasic()
+	p := &saveTestPlayer{
+		BasicEntity:        &basic,
+		saveTestHealth:     saveTestHealth{Points: 7},
+		CollisionComponent: CollisionComponent{Main: 1, Group: 2},
+	}
+	w.AddEntity(e)
+	w.AddEntity(p)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, sys.Save(buf))
+	assert.NoError(t, sys.Load(bytes.NewReader(buf.Bytes())))
+	assert.Equal(t, 2, sys.EntityCount(), "The saved entities should replace the current ones")
+
+	var le *saveTestEntity
+	var lp *saveTestPlayer
+	for _, loaded := range sys.entities {
+		switch v := loaded.(type) {
+		case *saveTestEntity:
+			le = v
+		case *saveTestPlayer:
+			lp = v
+		}
+	}
+	if assert.NotNil(t, le) && assert.NotNil(t, lp) {
+		assert.False(t, le == e, "Loaded entities should be new entities")
+		assert.NotEqual(t, e.ID(), le.ID())
+		assert.Equal(t, *e.SpaceComponent, *le.SpaceComponent)
+		assert.Equal(t, sprite, le.Drawable)
+		assert.Equal(t, normals, le.NormalMap)
+		assert.Equal(t, BlendAdditive, le.BlendMode)
+		assert.Equal(t, e.Origin, le.Origin)
+		assert.Equal(t, e.Scale, le.Scale)
+		assert.Equal(t, color.NRGBA{R: 255, A: 255}, le.Color)
+		assert.Equal(t, float32(0.5), le.Opacity)
+		assert.Equal(t, float32(5), le.ZIndex())
+		assert.True(t, le.Shader() == HUDShader, "The shader should be loaded")
+		assert.Equal(t, 0, le.Health, "Fields which aren't registered components should not be saved")
+
+		assert.NotNil(t, lp.BasicEntity)
+		assert.Equal(t, 0, lp.Points, "Components of unexported embedded structs should not be saved")
+		assert.Equal(t, p.CollisionComponent, lp.CollisionComponent)
+	}
+
+	for _, shader := range shaders {
+		r := &RenderComponent{}
+		r.SetShader(shader)
+		data, err := marshalRenderComponent(r)
+		if assert.NoError(t, err, "Every built-in shader should be saved by name, including %T", shader) {
+			loaded := &RenderComponent{}
+			assert.NoError(t, unmarshalRenderComponent(data, loaded))
+			assert.True(t, loaded.Shader() == shader, "The shader %T should be loaded", shader)
+		}
+	}
+}
+
+func TestSaveTransparent(t *testing.T) {
+	r := &RenderComponent{}
+	data, err := marshalRenderComponent(r)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"Opacity":0`, "Transparent entities should be saved with their opacity")
+
+	loaded := &RenderComponent{}
+	assert.NoError(t, unmarshalRenderComponent(data, loaded))
+	rs := &RenderSystem{ids: make(map[uint64]struct{})}
+	basic := ecs.NewBasic()
+	rs.Add(&basic, loaded, &SpaceComponent{})
+	assert.Equal(t, float32(0), loaded.Opacity, "Transparent entities should stay transparent when loaded")
+
+	old := &RenderComponent{}
+	assert.NoError(t, unmarshalRenderComponent([]byte(`{"Hidden":false,"Scale":{"X":1,"Y":1}}`), old))
+	basic = ecs.NewBasic()
+	rs.Add(&basic, old, &SpaceComponent{})
+	assert.Equal(t, float32(1), old.Opacity, "Saves without an opacity should default to opaque")
+}
+
+func TestSaveSystemErrors(t *testing.T) {
+	engo.Mailbox = &engo.MessageManager{}
+	RegisterEntity("saveTestEntity", &saveTestEntity{})
+	w, sys := newSaveTestWorld()
+	w.AddEntity(&saveTestEntity{
+		BasicEntity:     ecs.NewBasic(),
+		RenderComponent: RenderComponent{Drawable: Rectangle{}},
+	})
+	assert.Error(t, sys.Save(&bytes.Buffer{}), "Drawables which aren't registered should not be saved")
+
+	_, err := marshalRenderComponent(&RenderComponent{Mask: &Mask{Space: &SpaceComponent{}}})
+	assert.Error(t, err, "Entities with a Mask should not be saved without it")
+
+	assert.Error(t, sys.Load(strings.NewReader(`{"entities":[{"type":"unknown"}]}`)), "Entity types which aren't registered should not be loaded")
+	assert.Equal(t, 1, sys.EntityCount(), "Failing to load should keep the current entities")
+}
@@ -0,0 +1,58 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/klopsch/engo"
+)
+
+// ReadPixels reads a w by h block of pixels from the framebuffer that is
+// currently bound, which is the window unless called while drawing a
+// RenderTarget. x and y are in framebuffer pixels, measured from the top left
+// corner like the returned image.
+//
+// The frame is only complete once the RenderSystem has drawn it and flushed
+// its shaders with Post, and the buffers are swapped right after all systems
+// have updated. Call it from a system with a lower priority than
+// RenderSystemPriority to read back the current frame. Reading pixels makes
+// the CPU wait for the GPU to finish all pending work, so it stalls the
+// pipeline and shouldn't be done every frame.
+func ReadPixels(x, y, w, h int) (*image.RGBA, error) {
+	if engo.Headless() {
+		return nil, errors.New("pixels can't be read back in headless mode")
+	}
+	if w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("invalid size %dx%d, width and height must be positive", w, h)
+	}
+
+	height := boundHeight
+	if height == 0 {
+		height = int(engo.CanvasHeight())
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	// OpenGL's origin is the bottom left corner, so the rows come back upside down
+	engo.Gl.ReadPixels(x, height-y-h, w, h, engo.Gl.RGBA, engo.Gl.UNSIGNED_BYTE, img.Pix)
+	flipRows(img)
+	return img, nil
+}
+
+// Screenshot captures the whole window. See ReadPixels for when to call it.
+func Screenshot() (*image.RGBA, error) {
+	return ReadPixels(0, 0, int(engo.CanvasWidth()), int(engo.CanvasHeight()))
+}
+
+// flipRows mirrors img vertically, in place.
+func flipRows(img *image.RGBA) {
+	h := img.Rect.Dy()
+	row := make([]uint8, img.Stride)
+	for top, bottom := 0, h-1; top < bottom; top, bottom = top+1, bottom-1 {
+		t := img.Pix[top*img.Stride : (top+1)*img.Stride]
+		b := img.Pix[bottom*img.Stride : (bottom+1)*img.Stride]
+		copy(row, t)
+		copy(t, b)
+		copy(b, row)
+	}
+}
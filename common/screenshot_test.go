@@ -0,0 +1,34 @@
+package common
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlipRows(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 3))
+	for y := 0; y < 3; y++ {
+		img.SetRGBA(0, y, color.RGBA{R: uint8(y), A: 255})
+	}
+
+	flipRows(img)
+	for y := 0; y < 3; y++ {
+		assert.Equal(t, uint8(2-y), img.RGBAAt(0, y).R, "Rows should be in reverse order")
+	}
+}
+
+func TestReadPixelsHeadless(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &CameraTestScene{})
+
+	_, err := ReadPixels(0, 0, 1, 1)
+	assert.Error(t, err, "Pixels can't be read without OpenGL")
+	_, err = Screenshot()
+	assert.Error(t, err, "Screenshots can't be taken without OpenGL")
+}
@@ -0,0 +1,87 @@
+package common
+
+import "github.com/klopsch/ecs"
+
+// ScriptSystemPriority is the priority of the ScriptSystem. It runs after the MouseSystem, the camera systems and the
+// InterpolationSystem, so scripts see the input and positions of the current frame, and before the systems without a
+// priority, such as the CollisionSystem, and the RenderSystem, so what scripts move is collided and drawn in the same
+// frame.
+const ScriptSystemPriority = 50
+
+// ScriptComponent runs a function every frame for an entity, for simple logic not worth a System of its own, such as
+// a door opening after a timer.
+type ScriptComponent struct {
+	// Update is called every frame with the time since the last one in seconds, unless it is nil or the entity is
+	// disabled.
+	Update func(dt float32)
+}
+
+type scriptEntity struct {
+	*ecs.BasicEntity
+	*ScriptComponent
+}
+
+// ScriptSystem calls the Update of the ScriptComponent of its entities every frame, in the order they were added.
+//
+// Scripts may add and remove entities from the World, including their own: an entity removed by a script isn't
+// updated anymore in that frame, and an entity added by a script is updated from the next frame on.
+type ScriptSystem struct {
+	entities []scriptEntity
+	// running holds the entities being updated, so scripts can add and remove entities while they run
+	running []scriptEntity
+	ids     map[uint64]struct{}
+	states  *entityStates
+}
+
+// Priority implements the ecs.Prioritizer interface.
+func (*ScriptSystem) Priority() int { return ScriptSystemPriority }
+
+// New initializes the ScriptSystem.
+func (s *ScriptSystem) New(w *ecs.World) {
+	s.states = worldEntityStates(w)
+}
+
+// Add starts tracking the given entity.
+func (s *ScriptSystem) Add(basic *ecs.BasicEntity, script *ScriptComponent) {
+	if s.ids == nil {
+		s.ids = make(map[uint64]struct{})
+	}
+	s.entities = append(s.entities, scriptEntity{basic, script})
+	s.ids[basic.ID()] = struct{}{}
+}
+
+// AddByInterface allows an Entity to be added directly using the Scriptable interface, which every entity containing
+// the BasicEntity and ScriptComponent anonymously automatically satisfies.
+func (s *ScriptSystem) AddByInterface(i ecs.Identifier) {
+	o, _ := i.(Scriptable)
+	s.Add(o.GetBasicEntity(), o.GetScriptComponent())
+}
+
+// Remove stops tracking the given entity.
+func (s *ScriptSystem) Remove(basic ecs.BasicEntity) {
+	for index, e := range s.entities {
+		if e.BasicEntity.ID() == basic.ID() {
+			s.entities = append(s.entities[:index], s.entities[index+1:]...)
+			delete(s.ids, basic.ID())
+			return
+		}
+	}
+}
+
+// EntityCount returns the number of entities the ScriptSystem holds.
+func (s *ScriptSystem) EntityCount() int {
+	return len(s.entities)
+}
+
+// Update calls the scripts of the enabled entities.
+func (s *ScriptSystem) Update(dt float32) {
+	s.running = append(s.running[:0], s.entities...)
+	for _, e := range s.running {
+		if _, ok := s.ids[e.BasicEntity.ID()]; !ok || s.states.disabled(e.BasicEntity.ID()) {
+			continue
+		}
+		if e.ScriptComponent.Update != nil {
+			e.ScriptComponent.Update(dt)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/klopsch/ecs"
+	"github.com/stretchr/testify/assert"
+)
+
+type scriptedEntity struct {
+	ecs.BasicEntity
+	ScriptComponent
+}
+
+func TestScriptSystem(t *testing.T) {
+	w := &ecs.World{}
+	sys := &ScriptSystem{}
+	var scriptable *Scriptable
+	var notScriptable *NotScriptable
+	w.AddSystemInterface(sys, scriptable, notScriptable)
+
+	door := &scriptedEntity{BasicEntity: ecs.NewBasic()}
+	var timer float32
+	opened := false
+	door.Update = func(dt float32) {
+		timer += dt
+		if timer >= 1 {
+			opened = true
+		}
+	}
+	w.AddEntity(door)
+
+	w.Update(0.5)
+	assert.False(t, opened)
+	w.Update(0.5)
+	assert.True(t, opened, "Scripts should be called every frame with the time since the last one")
+
+	SetEntityEnabled(w, &door.BasicEntity, false)
+	w.Update(1)
+	SetEntityEnabled(w, &door.BasicEntity, true)
+	assert.Equal(t, float32(1), timer, "Scripts of disabled entities shouldn't be called")
+}
+
+func TestScriptSystemRemove(t *testing.T) {
+	w := &ecs.World{}
+	sys := &ScriptSystem{}
+	var scriptable *Scriptable
+	var notScriptable *NotScriptable
+	w.AddSystemInterface(sys, scriptable, notScriptable)
+
+	var calls []string
+	a := &scriptedEntity{BasicEntity: ecs.NewBasic()}
+	b := &scriptedEntity{BasicEntity: ecs.NewBasic()}
+	c := &scriptedEntity{BasicEntity: ecs.NewBasic()}
+	a.Update = func(float32) {
+		calls = append(calls, "a")
+		w.RemoveEntity(a.BasicEntity)
+		w.RemoveEntity(b.BasicEntity)
+		w.AddEntity(c)
+	}
+	b.Update = func(float32) { calls = append(calls, "b") }
+	c.Update = func(float32) { calls = append(calls, "c") }
+	w.AddEntity(a)
+	w.AddEntity(b)
+
+	w.Update(1)
+	assert.Equal(t, []string{"a"}, calls, "Entities removed by a script shouldn't be updated, and added ones should wait a frame")
+	w.Update(1)
+	assert.Equal(t, []string{"a", "c"}, calls)
+	assert.Equal(t, 1, sys.EntityCount())
+}
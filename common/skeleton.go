@@ -0,0 +1,343 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/klopsch/engo"
+	"github.com/klopsch/engo/math"
+	"github.com/klopsch/gl"
+)
+
+// BoneTransform is the transform of a Bone relative to its parent, or of a Slot relative to its Bone.
+type BoneTransform struct {
+	// X and Y are the position, in the space of the parent.
+	X, Y float32
+	// Rotation is the angle in degrees, clockwise like the Rotation of a SpaceComponent.
+	Rotation float32
+	// ScaleX and ScaleY scale the children along the axes of the transform. A scale of 0 is a scale of 1, so the
+	// zero value is the identity.
+	ScaleX, ScaleY float32
+}
+
+// affine returns the transform as an affine matrix.
+func (t BoneTransform) affine() affine {
+	sx, sy := t.ScaleX, t.ScaleY
+	if sx == 0 {
+		sx = 1
+	}
+	if sy == 0 {
+		sy = 1
+	}
+	sin, cos := math.Sincos(t.Rotation * math.Pi / 180)
+	return affine{a: cos * sx, b: sin * sx, c: -sin * sy, d: cos * sy, x: t.X, y: t.Y}
+}
+
+// affine is a 2D affine transform, mapping (x, y) to (a*x + c*y + x, b*x + d*y + y).
+type affine struct {
+	a, b, c, d, x, y float32
+}
+
+// mul returns the transform applying n and then m.
+func (m affine) mul(n affine) affine {
+	return affine{
+		a: m.a*n.a + m.c*n.b,
+		b: m.b*n.a + m.d*n.b,
+		c: m.a*n.c + m.c*n.d,
+		d: m.b*n.c + m.d*n.d,
+		x: m.a*n.x + m.c*n.y + m.x,
+		y: m.b*n.x + m.d*n.y + m.y,
+	}
+}
+
+// apply transforms the point.
+func (m affine) apply(x, y float32) (float32, float32) {
+	return m.a*x + m.c*y + m.x, m.b*x + m.d*y + m.y
+}
+
+// inverse returns the transform undoing m, which must not have a scale of 0.
+func (m affine) inverse() affine {
+	det := m.a*m.d - m.b*m.c
+	inv := affine{a: m.d / det, b: -m.b / det, c: -m.c / det, d: m.a / det}
+	inv.x = -(inv.a*m.x + inv.c*m.y)
+	inv.y = -(inv.b*m.x + inv.d*m.y)
+	return inv
+}
+
+// Bone is a joint of a Skeleton, such as a hip or an elbow, which moves its children along with it.
+type Bone struct {
+	Name string
+	// Parent is the Bone this Bone is attached to, or nil for a root of the Skeleton.
+	Parent *Bone
+	// Setup is the transform of the Bone relative to its Parent in the setup pose, which the sprites and meshes of
+	// the Skeleton are bound in.
+	Setup BoneTransform
+	// Pose is the current transform of the Bone relative to its Parent, set by the SkeletonAnimations and free to
+	// change by hand.
+	Pose BoneTransform
+
+	// world is the transform from the Bone to the Skeleton in the current pose, and unbind is the transform from the
+	// Skeleton to the Bone in the setup pose
+	world, unbind affine
+}
+
+// BoneWeight is how much a Bone moves a vertex of a SlotMesh.
+type BoneWeight struct {
+	Bone   *Bone
+	Weight float32
+}
+
+// SlotMesh splits the sprite of a Slot into a grid whose vertices are moved by several bones, so the sprite bends
+// between them, such as the sleeve of an arm at the elbow. Each vertex is moved by the weighted average of the
+// movements of its bones from the setup pose, known as linear blend skinning.
+type SlotMesh struct {
+	// Columns and Rows are the number of cells of the grid across the sprite, each at least 1.
+	Columns, Rows int
+	// Weights are the bones moving each vertex of the grid, row by row from the top-left, so there are
+	// (Columns+1)*(Rows+1) of them. Vertices without weights follow the Bone of the Slot. The weights of a vertex
+	// should add up to 1.
+	Weights [][]BoneWeight
+
+	// bind holds the position of every vertex in the Skeleton in the setup pose
+	bind []engo.Point
+}
+
+// Slot attaches a sprite to a Bone of a Skeleton. The sprite follows the Bone rigidly, or is deformed by the bones of
+// its Mesh.
+type Slot struct {
+	Name string
+	// Bone is the Bone the sprite is attached to.
+	Bone *Bone
+	// Drawable is the sprite, which must come from the same texture as the other sprites of the Skeleton, such as
+	// a Spritesheet. It can be changed to another sprite of that texture, such as to open the eyes, or set to nil to
+	// hide the Slot.
+	Drawable Drawable
+	// Offset is the transform of the sprite relative to its Bone. Its position is where the Origin of the sprite is
+	// placed.
+	Offset BoneTransform
+	// Origin is the point of the sprite placed at the Offset, as a fraction of its size, like the Origin of a
+	// RenderComponent.
+	Origin engo.Point
+	// Mesh deforms the sprite with several bones, if it's not nil. Its grid is bound to the sprite set when the
+	// Skeleton is created.
+	Mesh *SlotMesh
+}
+
+// grid returns the points of the grid of the sprite of the slot, row by row from the top-left, and their texture
+// coordinates, relative to its Bone.
+func (s *Slot) grid(columns, rows int) (points, uvs []engo.Point) {
+	w, h := s.Drawable.Width(), s.Drawable.Height()
+	u, v, u2, v2 := s.Drawable.View()
+	offset := s.Offset.affine()
+	for j := 0; j <= rows; j++ {
+		fy := float32(j) / float32(rows)
+		for i := 0; i <= columns; i++ {
+			fx := float32(i) / float32(columns)
+			x, y := offset.apply((fx-s.Origin.X)*w, (fy-s.Origin.Y)*h)
+			points = append(points, engo.Point{X: x, Y: y})
+			uvs = append(uvs, engo.Point{X: u + (u2-u)*fx, Y: v + (v2-v)*fy})
+		}
+	}
+	return points, uvs
+}
+
+// Skeleton is a Drawable made of sprites attached to a hierarchy of bones, which SkeletonAnimations move with
+// keyframes, for characters too complex for frame animations. Its space starts at the top left of the SpaceComponent
+// of the entity drawing it, with y pointing down, and the sprites are drawn as a single entity by the DefaultShader or
+// HUDShader, in the order of the Slots. Keep the bones within positive coordinates, so the Skeleton isn't culled
+// while visible.
+//
+// This is the core runtime: skeletons are built in code, and importing them from a format such as the JSON of Spine
+// is left for later.
+type Skeleton struct {
+	Bones []*Bone
+	Slots []*Slot
+
+	texture       *gl.Texture
+	width, height float32
+	// vertices holds the quads of the sprites in the current pose, laid out like the tiles of a TileMesh
+	vertices []float32
+}
+
+// NewSkeleton creates a Skeleton from its bones, ordered so that every Bone comes after its Parent, and its slots,
+// in the order they're drawn. The current pose of the bones is reset to the setup pose, in which the meshes of the
+// slots are bound.
+func NewSkeleton(bones []*Bone, slots []*Slot) (*Skeleton, error) {
+	seen := make(map[*Bone]struct{}, len(bones))
+	for _, b := range bones {
+		if b.Parent != nil {
+			if _, ok := seen[b.Parent]; !ok {
+				return nil, fmt.Errorf("bone %q comes before its parent %q", b.Name, b.Parent.Name)
+			}
+		}
+		seen[b] = struct{}{}
+	}
+
+	s := &Skeleton{Bones: bones, Slots: slots}
+	for _, slot := range slots {
+		if _, ok := seen[slot.Bone]; !ok {
+			return nil, fmt.Errorf("bone of slot %q is not in the skeleton", slot.Name)
+		}
+		if slot.Drawable == nil {
+			continue
+		}
+		if s.texture == nil {
+			s.texture = slot.Drawable.Texture()
+		} else if slot.Drawable.Texture() != s.texture {
+			return nil, fmt.Errorf("sprite of slot %q isn't drawn from the texture of the other slots", slot.Name)
+		}
+	}
+
+	s.SetToSetupPose()
+	for _, b := range bones {
+		b.unbind = b.world.inverse()
+	}
+	for _, slot := range slots {
+		if err := slot.bind(seen); err != nil {
+			return nil, err
+		}
+	}
+	s.update()
+	return s, nil
+}
+
+// bind binds the grid of the Mesh to the sprite, in the setup pose. The bones weighting its vertices have to be
+// among the bones of the Skeleton.
+func (s *Slot) bind(bones map[*Bone]struct{}) error {
+	m := s.Mesh
+	if m == nil {
+		return nil
+	}
+	if m.Columns < 1 || m.Rows < 1 {
+		return fmt.Errorf("mesh of slot %q needs at least a column and a row", s.Name)
+	}
+	if len(m.Weights) != (m.Columns+1)*(m.Rows+1) {
+		return fmt.Errorf("mesh of slot %q needs weights for its %d vertices", s.Name, (m.Columns+1)*(m.Rows+1))
+	}
+	if s.Drawable == nil {
+		return fmt.Errorf("mesh of slot %q has no sprite to bind to", s.Name)
+	}
+	for _, weights := range m.Weights {
+		for _, w := range weights {
+			if _, ok := bones[w.Bone]; !ok {
+				return fmt.Errorf("mesh of slot %q is weighted by a bone which is not in the skeleton", s.Name)
+			}
+		}
+	}
+	points, _ := s.grid(m.Columns, m.Rows)
+	m.bind = m.bind[:0]
+	for _, p := range points {
+		x, y := s.Bone.world.apply(p.X, p.Y)
+		m.bind = append(m.bind, engo.Point{X: x, Y: y})
+	}
+	return nil
+}
+
+// Bone returns the Bone with the name, or nil if there is none.
+func (s *Skeleton) Bone(name string) *Bone {
+	for _, b := range s.Bones {
+		if b.Name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// Slot returns the Slot with the name, or nil if there is none.
+func (s *Skeleton) Slot(name string) *Slot {
+	for _, slot := range s.Slots {
+		if slot.Name == name {
+			return slot
+		}
+	}
+	return nil
+}
+
+// SetToSetupPose resets the current pose of the bones to the setup pose.
+func (s *Skeleton) SetToSetupPose() {
+	for _, b := range s.Bones {
+		b.Pose = b.Setup
+	}
+	s.updateBones()
+}
+
+// updateBones computes the transforms from the bones to the Skeleton in the current pose.
+func (s *Skeleton) updateBones() {
+	for _, b := range s.Bones {
+		b.world = b.Pose.affine()
+		if b.Parent != nil {
+			b.world = b.Parent.world.mul(b.world)
+		}
+	}
+}
+
+// update computes the quads of the sprites in the current pose of the bones, and the size they cover.
+func (s *Skeleton) update() {
+	s.updateBones()
+	s.vertices = s.vertices[:0]
+	for _, slot := range s.Slots {
+		if slot.Drawable == nil {
+			continue
+		}
+		columns, rows := 1, 1
+		if slot.Mesh != nil {
+			columns, rows = slot.Mesh.Columns, slot.Mesh.Rows
+		}
+		points, uvs := slot.grid(columns, rows)
+		for i, p := range points {
+			points[i] = slot.deform(i, p)
+		}
+		for j := 0; j < rows; j++ {
+			for i := 0; i < columns; i++ {
+				for _, k := range [4]int{j*(columns+1) + i, j*(columns+1) + i + 1, (j+1)*(columns+1) + i + 1, (j+1)*(columns+1) + i} {
+					s.vertices = append(s.vertices, points[k].X, points[k].Y, uvs[k].X, uvs[k].Y)
+				}
+			}
+		}
+	}
+
+	s.width, s.height = 0, 0
+	for i := 0; i < len(s.vertices); i += 4 {
+		s.width = math.Max(s.width, s.vertices[i])
+		s.height = math.Max(s.height, s.vertices[i+1])
+	}
+}
+
+// deform moves the point i of the grid of the sprite, at p relative to its Bone, into the Skeleton in the current
+// pose.
+func (s *Slot) deform(i int, p engo.Point) engo.Point {
+	if s.Mesh == nil || len(s.Mesh.Weights[i]) == 0 {
+		x, y := s.Bone.world.apply(p.X, p.Y)
+		return engo.Point{X: x, Y: y}
+	}
+	bind := s.Mesh.bind[i]
+	var deformed engo.Point
+	for _, w := range s.Mesh.Weights[i] {
+		x, y := w.Bone.world.mul(w.Bone.unbind).apply(bind.X, bind.Y)
+		deformed.X += x * w.Weight
+		deformed.Y += y * w.Weight
+	}
+	return deformed
+}
+
+// Texture returns the OpenGL ID of the texture the sprites are drawn from.
+func (s *Skeleton) Texture() *gl.Texture {
+	return s.texture
+}
+
+// Width returns the right-most point of the sprites in the current pose.
+func (s *Skeleton) Width() float32 {
+	return s.width
+}
+
+// Height returns the bottom-most point of the sprites in the current pose.
+func (s *Skeleton) Height() float32 {
+	return s.height
+}
+
+// View returns the whole texture, as every sprite has its own region of it.
+func (s *Skeleton) View() (float32, float32, float32, float32) {
+	return 0, 0, 1, 1
+}
+
+// Close does nothing, as the texture belongs to the sprites of the slots.
+func (s *Skeleton) Close() {}
@@ -0,0 +1,185 @@
+package common
+
+import (
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo/math"
+)
+
+// BoneKeyframe is the transform of a Bone at a point in time of a SkeletonAnimation.
+type BoneKeyframe struct {
+	// Time is the time in seconds from the start of the animation.
+	Time float32
+	BoneTransform
+}
+
+// BoneTimeline holds the keyframes of a Bone in a SkeletonAnimation, ordered by Time. Between two keyframes, the
+// transform of the Bone is interpolated linearly, rotating the shortest way, and before the first and after the
+// last one, it holds the transform of the nearest keyframe.
+type BoneTimeline struct {
+	Bone *Bone
+	Keys []BoneKeyframe
+}
+
+// at returns the transform of the Bone at the time.
+func (tl BoneTimeline) at(t float32) BoneTransform {
+	keys := tl.Keys
+	if t <= keys[0].Time {
+		return keys[0].BoneTransform
+	}
+	for i := 1; i < len(keys); i++ {
+		if t < keys[i].Time {
+			prev, next := keys[i-1], keys[i]
+			f := (t - prev.Time) / (next.Time - prev.Time)
+			return lerpBoneTransform(prev.BoneTransform, next.BoneTransform, f)
+		}
+	}
+	return keys[len(keys)-1].BoneTransform
+}
+
+// lerpBoneTransform interpolates linearly from a to b, rotating the shortest way, with the scales of 0 taken as 1.
+func lerpBoneTransform(a, b BoneTransform, f float32) BoneTransform {
+	scale := func(s float32) float32 {
+		if s == 0 {
+			return 1
+		}
+		return s
+	}
+	return BoneTransform{
+		X:        math.Lerp(a.X, b.X, f),
+		Y:        math.Lerp(a.Y, b.Y, f),
+		Rotation: math.LerpAngle(a.Rotation, b.Rotation, f),
+		ScaleX:   math.Lerp(scale(a.ScaleX), scale(b.ScaleX), f),
+		ScaleY:   math.Lerp(scale(a.ScaleY), scale(b.ScaleY), f),
+	}
+}
+
+// SkeletonAnimation moves the bones of a Skeleton with keyframes. Bones without a timeline keep their pose.
+type SkeletonAnimation struct {
+	Name string
+	// Duration is the length of the animation in seconds.
+	Duration float32
+	Loop     bool
+	// Timelines are the keyframes of the animated bones.
+	Timelines []BoneTimeline
+}
+
+// Apply sets the pose of the animated bones to their transform at the time.
+func (a *SkeletonAnimation) Apply(t float32) {
+	for _, tl := range a.Timelines {
+		if len(tl.Keys) > 0 {
+			tl.Bone.Pose = tl.at(t)
+		}
+	}
+}
+
+// SkeletonComponent plays the SkeletonAnimations of the Skeleton of an entity, which the SkeletonSystem draws through
+// its RenderComponent.
+type SkeletonComponent struct {
+	Skeleton *Skeleton
+	// Animations are the animations that can be played, by name.
+	Animations map[string]*SkeletonAnimation
+	// CurrentAnimation is the animation playing, or nil to hold the current pose.
+	CurrentAnimation *SkeletonAnimation
+	// Speed multiplies the time the animations advance by. It defaults to 1.
+	Speed float32
+
+	time float32
+}
+
+// NewSkeletonComponent creates a SkeletonComponent for the Skeleton, able to play the animations.
+func NewSkeletonComponent(skeleton *Skeleton, animations ...*SkeletonAnimation) SkeletonComponent {
+	sc := SkeletonComponent{Skeleton: skeleton, Animations: make(map[string]*SkeletonAnimation), Speed: 1}
+	for _, a := range animations {
+		sc.Animations[a.Name] = a
+	}
+	return sc
+}
+
+// Play starts the animation with the name from its beginning. Bones it doesn't animate keep their pose, so reset
+// them with Skeleton.SetToSetupPose first if needed.
+func (sc *SkeletonComponent) Play(name string) {
+	sc.CurrentAnimation = sc.Animations[name]
+	sc.time = 0
+}
+
+// Time returns the time in seconds since the current animation started, wrapped around its Duration if it loops.
+func (sc *SkeletonComponent) Time() float32 {
+	return sc.time
+}
+
+// advance moves the current animation forward by dt, stopping it at its end unless it loops.
+func (sc *SkeletonComponent) advance(dt float32) {
+	a := sc.CurrentAnimation
+	if a == nil {
+		return
+	}
+	sc.time += dt * sc.Speed
+	if sc.time >= a.Duration {
+		if a.Loop && a.Duration > 0 {
+			sc.time = math.Mod(sc.time, a.Duration)
+		} else {
+			sc.time = a.Duration
+			sc.CurrentAnimation = nil
+		}
+	}
+	a.Apply(sc.time)
+}
+
+type skeletonEntity struct {
+	*SkeletonComponent
+	*RenderComponent
+}
+
+// SkeletonSystem advances the animations of the entities with a SkeletonComponent, and poses their Skeleton, which
+// it sets as the Drawable of their RenderComponent.
+type SkeletonSystem struct {
+	entities map[uint64]skeletonEntity
+	states   *entityStates
+}
+
+// New initializes the SkeletonSystem.
+func (s *SkeletonSystem) New(w *ecs.World) {
+	s.states = worldEntityStates(w)
+}
+
+// Add starts tracking the given entity.
+func (s *SkeletonSystem) Add(basic *ecs.BasicEntity, skeleton *SkeletonComponent, render *RenderComponent) {
+	if s.entities == nil {
+		s.entities = make(map[uint64]skeletonEntity)
+	}
+	if skeleton.Speed == 0 {
+		skeleton.Speed = 1
+	}
+	render.Drawable = skeleton.Skeleton
+	s.entities[basic.ID()] = skeletonEntity{skeleton, render}
+}
+
+// AddByInterface allows an Entity to be added directly using the Skeletonable interface, which every entity
+// containing the BasicEntity, SkeletonComponent and RenderComponent anonymously automatically satisfies.
+func (s *SkeletonSystem) AddByInterface(i ecs.Identifier) {
+	o, _ := i.(Skeletonable)
+	s.Add(o.GetBasicEntity(), o.GetSkeletonComponent(), o.GetRenderComponent())
+}
+
+// Remove stops tracking the given entity.
+func (s *SkeletonSystem) Remove(basic ecs.BasicEntity) {
+	delete(s.entities, basic.ID())
+}
+
+// EntityCount returns the number of entities the SkeletonSystem holds.
+func (s *SkeletonSystem) EntityCount() int {
+	return len(s.entities)
+}
+
+// Update advances the animations of all tracked entities, and poses their skeletons, including the changes made to
+// the poses of their bones by hand.
+func (s *SkeletonSystem) Update(dt float32) {
+	for id, e := range s.entities {
+		if s.states.disabled(id) {
+			continue
+		}
+		e.SkeletonComponent.advance(dt)
+		e.Skeleton.update()
+		e.RenderComponent.Drawable = e.Skeleton
+	}
+}
@@ -0,0 +1,105 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func skeletonSprite(w, h float32) Texture {
+	return Texture{width: w, height: h, viewport: engo.AABB{Max: engo.Point{X: 1, Y: 1}}}
+}
+
+func TestSkeletonAnimation(t *testing.T) {
+	root := &Bone{Name: "root", Setup: BoneTransform{X: 10, Y: 10}}
+	arm := &Bone{Name: "arm", Parent: root, Setup: BoneTransform{X: 10}}
+	skeleton, err := NewSkeleton([]*Bone{root, arm}, []*Slot{
+		{Name: "arm", Bone: arm, Drawable: skeletonSprite(10, 2), Origin: engo.Point{Y: 0.5}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.InDeltaSlice(t, []float32{20, 9, 0, 0, 30, 9, 1, 0, 30, 11, 1, 1, 20, 11, 0, 1}, skeleton.vertices, 1e-4,
+		"Sprites should follow the transforms of their bone and its parents")
+	assert.Equal(t, float32(30), skeleton.Width())
+	assert.Equal(t, float32(11), skeleton.Height())
+
+	raise := &SkeletonAnimation{Name: "raise", Duration: 1, Timelines: []BoneTimeline{
+		{Bone: arm, Keys: []BoneKeyframe{
+			{Time: 0, BoneTransform: BoneTransform{X: 10}},
+			{Time: 1, BoneTransform: BoneTransform{X: 10, Rotation: 90}},
+		}},
+	}}
+	sc := NewSkeletonComponent(skeleton, raise)
+	ren := &RenderComponent{}
+	sys := &SkeletonSystem{}
+	basic := ecs.NewBasic()
+	sys.Add(&basic, &sc, ren)
+	assert.Equal(t, skeleton, ren.Drawable, "The Skeleton should be drawn by the RenderComponent")
+
+	sc.Play("raise")
+	sys.Update(0.5)
+	assert.InDelta(t, 45, arm.Pose.Rotation, 1e-4, "Keyframes should be interpolated")
+
+	sys.Update(1)
+	assert.Nil(t, sc.CurrentAnimation, "Animations which don't loop should stop at their end")
+	assert.InDeltaSlice(t, []float32{21, 10, 0, 0, 21, 20, 1, 0, 19, 20, 1, 1, 19, 10, 0, 1}, skeleton.vertices, 1e-4,
+		"Sprites should rotate with their bone")
+}
+
+func TestSkeletonRotationShortestWay(t *testing.T) {
+	tl := BoneTimeline{Keys: []BoneKeyframe{
+		{Time: 0, BoneTransform: BoneTransform{Rotation: 350}},
+		{Time: 1, BoneTransform: BoneTransform{Rotation: 10}},
+	}}
+	assert.InDelta(t, 360, tl.at(0.5).Rotation, 1e-4)
+	assert.Equal(t, float32(10), tl.at(2).Rotation, "The last keyframe should hold after the timeline")
+}
+
+func TestSkeletonMesh(t *testing.T) {
+	a := &Bone{Name: "a", Setup: BoneTransform{Y: 10}}
+	b := &Bone{Name: "b", Parent: a, Setup: BoneTransform{X: 10}}
+	half := []BoneWeight{{a, 0.5}, {b, 0.5}}
+	skeleton, err := NewSkeleton([]*Bone{a, b}, []*Slot{{
+		Name:     "sleeve",
+		Bone:     a,
+		Drawable: skeletonSprite(20, 2),
+		Origin:   engo.Point{Y: 0.5},
+		Mesh: &SlotMesh{Columns: 2, Rows: 1, Weights: [][]BoneWeight{
+			{{a, 1}}, half, {{b, 1}},
+			{{a, 1}}, half, {{b, 1}},
+		}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, skeleton.vertices, 2*tileMeshVertices, "Every cell of a mesh should be a quad")
+
+	b.Pose.Rotation = 90
+	skeleton.update()
+	// The second quad starts with the top of the middle of the sprite, then its top right
+	assert.InDeltaSlice(t, []float32{10.5, 9.5}, skeleton.vertices[16:18], 1e-4, "Vertices should blend the movements of their bones")
+	assert.InDeltaSlice(t, []float32{11, 20}, skeleton.vertices[20:22], 1e-4, "Vertices should follow the bones they're bound to")
+	assert.InDeltaSlice(t, []float32{0, 9}, skeleton.vertices[0:2], 1e-4, "Vertices of bones which didn't move should stay in place")
+}
+
+func TestNewSkeletonErrors(t *testing.T) {
+	root := &Bone{Name: "root"}
+	child := &Bone{Name: "child", Parent: root}
+	_, err := NewSkeleton([]*Bone{child, root}, nil)
+	assert.Error(t, err, "Bones should come after their parent")
+
+	_, err = NewSkeleton([]*Bone{root}, []*Slot{{Name: "slot", Bone: child}})
+	assert.Error(t, err, "Slots should be attached to bones of the skeleton")
+
+	_, err = NewSkeleton([]*Bone{root}, []*Slot{{Name: "slot", Bone: root, Drawable: skeletonSprite(1, 1), Mesh: &SlotMesh{Columns: 1, Rows: 1}}})
+	assert.Error(t, err, "Meshes should have weights for all their vertices")
+
+	one := []BoneWeight{{child, 1}}
+	_, err = NewSkeleton([]*Bone{root}, []*Slot{{Name: "slot", Bone: root, Drawable: skeletonSprite(1, 1), Mesh: &SlotMesh{
+		Columns: 1, Rows: 1, Weights: [][]BoneWeight{one, one, one, one},
+	}}})
+	assert.Error(t, err, "Meshes should be weighted by bones of the skeleton")
+}
@@ -204,6 +204,38 @@ func generateSymmetricSpriteRegions(totalWidth, totalHeight float32, cellWidth,
 	return spriteRegions
 }
 
+// SliceGrid slices tex into cells of cellWidth by cellHeight pixels, returning them in order from left to right and top
+// to bottom, ready to be used as the Drawables of an AnimationComponent. The margin is the space around the grid, and
+// spacing the space in between the cells, both in pixels. If the size of tex is not an exact multiple of the cell
+// size, the partial cells of the trailing row and column are left out.
+func SliceGrid(tex Texture, cellWidth, cellHeight, spacing, margin int) []Drawable {
+	if cellWidth <= 0 || cellHeight <= 0 {
+		return nil
+	}
+
+	var drawables []Drawable
+	for y := margin; y+cellHeight <= int(tex.Height())-margin; y += cellHeight + spacing {
+		for x := margin; x+cellWidth <= int(tex.Width())-margin; x += cellWidth + spacing {
+			cell, err := NewTextureRegion(tex, float32(x), float32(y), float32(cellWidth), float32(cellHeight))
+			if err != nil {
+				// Cannot happen, since the loops only produce cells within tex
+				panic(err)
+			}
+			drawables = append(drawables, cell)
+		}
+	}
+	return drawables
+}
+
+// SliceGridFromFile slices the preloaded texture at url into a grid of cells, see SliceGrid.
+func SliceGridFromFile(url string, cellWidth, cellHeight, spacing, margin int) ([]Drawable, error) {
+	tex, err := LoadedSprite(url)
+	if err != nil {
+		return nil, err
+	}
+	return SliceGrid(*tex, cellWidth, cellHeight, spacing, margin), nil
+}
+
 /*
 type Sprite struct {
 	Position *Point
@@ -0,0 +1,28 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSliceGrid(t *testing.T) {
+	tex := Texture{width: 70, height: 45, viewport: engo.AABB{Max: engo.Point{X: 1, Y: 1}}}
+
+	// 2px margin and 1px spacing leave room for three 20px columns and two 20px rows
+	cells := SliceGrid(tex, 20, 20, 1, 2)
+	assert.Len(t, cells, 6, "Partial cells should be left out")
+
+	for _, c := range cells {
+		assert.Equal(t, float32(20), c.Width())
+		assert.Equal(t, float32(20), c.Height())
+	}
+
+	u, v, _, _ := cells[4].View()
+	assert.InDelta(t, float32(23)/70, u, 1e-6, "Cells should be ordered left to right, top to bottom")
+	assert.InDelta(t, float32(23)/45, v, 1e-6, "Cells should be ordered left to right, top to bottom")
+
+	assert.Empty(t, SliceGrid(tex, 100, 20, 0, 0), "Cells larger than the texture should be left out")
+	assert.Empty(t, SliceGrid(tex, 0, 20, 0, 0), "Empty cells should not be sliced")
+}
@@ -0,0 +1,336 @@
+package common
+
+import (
+	"sync"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+)
+
+// TextInputSystemPriority is the priority of the TextInputSystem. It's higher than the priorities of the camera
+// Systems handling the keyboard, such as the KeyboardScroller, so the editing keys are consumed before those see them.
+const TextInputSystemPriority = 135
+
+const (
+	// TextInputRepeatDelay is how long, in seconds, an editing key such as Backspace has to be held before it
+	// repeats.
+	TextInputRepeatDelay float32 = 0.5
+	// TextInputRepeatInterval is the time, in seconds, between the repeats of a held editing key.
+	TextInputRepeatInterval float32 = 0.05
+)
+
+// TextInputComponent holds the editable string of a text field, along with the position of its caret. The
+// TextInputSystem types into it while it's focused, and draws it through the Text of the RenderComponent of the
+// entity.
+type TextInputComponent struct {
+	// Focused is whether typing goes to this text field. Focusing and unfocusing text fields, such as when they're
+	// clicked, is up to the game.
+	Focused bool
+	// MaxLength is the maximum number of characters of the value. Zero means no limit.
+	MaxLength int
+	// CaretChar is the character drawn at the caret while the text field is focused. It defaults to '|'.
+	CaretChar rune
+	// OnSubmit is called with the value when Enter is pressed while the text field is focused.
+	OnSubmit func(value string)
+
+	value []rune
+	// caret is the index of the character in value the caret is in front of
+	caret int
+	// composition is the text the user is composing with an input method editor
+	composition string
+}
+
+// Value returns the text typed into the text field.
+func (c *TextInputComponent) Value() string {
+	return string(c.value)
+}
+
+// SetValue replaces the text of the text field, and moves the caret to its end.
+func (c *TextInputComponent) SetValue(value string) {
+	c.value = []rune(value)
+	if c.MaxLength > 0 && len(c.value) > c.MaxLength {
+		c.value = c.value[:c.MaxLength]
+	}
+	c.caret = len(c.value)
+}
+
+// Caret returns the position of the caret, as the number of characters in front of it.
+func (c *TextInputComponent) Caret() int {
+	return c.caret
+}
+
+// SetCaret moves the caret in front of the character at the index, staying within the value.
+func (c *TextInputComponent) SetCaret(index int) {
+	switch {
+	case index < 0:
+		c.caret = 0
+	case index > len(c.value):
+		c.caret = len(c.value)
+	default:
+		c.caret = index
+	}
+}
+
+// MoveCaret moves the caret by n characters, to the right if n is positive and to the left if it's negative.
+func (c *TextInputComponent) MoveCaret(n int) {
+	c.SetCaret(c.caret + n)
+}
+
+// Composition returns the text the user is composing with an input method editor, which isn't part of the value
+// yet.
+func (c *TextInputComponent) Composition() string {
+	return c.composition
+}
+
+// Insert types the text at the caret, and moves the caret behind it. Control characters are skipped, and so are
+// the characters past MaxLength.
+func (c *TextInputComponent) Insert(text string) {
+	for _, char := range text {
+		if char < 32 || char == 127 {
+			continue
+		}
+		if c.MaxLength > 0 && len(c.value) >= c.MaxLength {
+			return
+		}
+		c.value = append(c.value, 0)
+		copy(c.value[c.caret+1:], c.value[c.caret:])
+		c.value[c.caret] = char
+		c.caret++
+	}
+}
+
+// Backspace removes the character in front of the caret.
+func (c *TextInputComponent) Backspace() {
+	if c.caret == 0 {
+		return
+	}
+	c.value = append(c.value[:c.caret-1], c.value[c.caret:]...)
+	c.caret--
+}
+
+// Delete removes the character behind the caret.
+func (c *TextInputComponent) Delete() {
+	if c.caret == len(c.value) {
+		return
+	}
+	c.value = append(c.value[:c.caret], c.value[c.caret+1:]...)
+}
+
+// Submit calls OnSubmit with the value, if it's set.
+func (c *TextInputComponent) Submit() {
+	if c.OnSubmit != nil {
+		c.OnSubmit(c.Value())
+	}
+}
+
+// display returns the text drawn for the text field: the value, with the composition and the caret at the caret
+// while it's focused.
+func (c *TextInputComponent) display() string {
+	if !c.Focused {
+		return string(c.value)
+	}
+	caret := c.CaretChar
+	if caret == 0 {
+		caret = '|'
+	}
+	return string(c.value[:c.caret]) + c.composition + string(caret) + string(c.value[c.caret:])
+}
+
+// textInputKeys are the editing keys handled by the TextInputSystem, which repeat while they're held.
+var textInputKeys = []engo.Key{engo.KeyBackspace, engo.KeyDelete, engo.KeyArrowLeft, engo.KeyArrowRight, engo.KeyHome, engo.KeyEnd}
+
+type textInputEntity struct {
+	*ecs.BasicEntity
+	*TextInputComponent
+	*RenderComponent
+}
+
+// TextInputSystem types the characters from TextMessages into the focused TextInputComponents, and handles the
+// editing keys: Backspace, Delete, the left and right arrows, Home and End. Pressing Enter submits the text fields,
+// Ctrl+C copies their value to the engo.Clipboard, and Ctrl+V pastes into them. While a text field is focused, the
+// editing keys and Enter are consumed with engo.InputManager.ConsumeKey, so the Buttons and actions bound to them
+// don't fire in the Systems updated after it.
+//
+// The value is drawn through the Text of the RenderComponent of the entity, so the Drawable has to be a Text with a
+// Font. Other Drawables are left alone.
+type TextInputSystem struct {
+	entities []textInputEntity
+
+	// typed and edit are the TextMessages and the last TextEditMessage received since the last Update
+	mutex sync.Mutex
+	typed []rune
+	edit  *engo.TextEditMessage
+
+	// held is the editing key being held, for heldTime seconds
+	held     engo.Key
+	heldTime float32
+
+	subs []*engo.Subscription
+}
+
+// New listens for the typed text. Call Close to stop listening once the TextInputSystem isn't used anymore.
+func (t *TextInputSystem) New(*ecs.World) {
+	t.Close()
+	typed := engo.Mailbox.Subscribe("TextMessage", func(msg engo.Message) {
+		m, ok := msg.(engo.TextMessage)
+		if !ok {
+			return
+		}
+		t.mutex.Lock()
+		t.typed = append(t.typed, m.Char)
+		t.mutex.Unlock()
+	})
+	edit := engo.Mailbox.Subscribe("TextEditMessage", func(msg engo.Message) {
+		m, ok := msg.(engo.TextEditMessage)
+		if !ok {
+			return
+		}
+		t.mutex.Lock()
+		t.edit = &m
+		t.mutex.Unlock()
+	})
+	t.subs = []*engo.Subscription{typed, edit}
+}
+
+// Close stops listening for the typed text, such as when the TextInputSystem is removed from its World.
+func (t *TextInputSystem) Close() {
+	for _, sub := range t.subs {
+		sub.Unsubscribe()
+	}
+	t.subs = nil
+}
+
+// Priority returns TextInputSystemPriority, so the editing keys are consumed before most Systems see them.
+func (*TextInputSystem) Priority() int { return TextInputSystemPriority }
+
+// Add starts tracking the given entity.
+func (t *TextInputSystem) Add(basic *ecs.BasicEntity, input *TextInputComponent, render *RenderComponent) {
+	t.entities = append(t.entities, textInputEntity{basic, input, render})
+}
+
+// AddByInterface allows an Entity to be added directly using the TextInputable interface, which every entity
+// containing the BasicEntity, TextInputComponent and RenderComponent anonymously automatically satisfies.
+func (t *TextInputSystem) AddByInterface(i ecs.Identifier) {
+	o, _ := i.(TextInputable)
+	t.Add(o.GetBasicEntity(), o.GetTextInputComponent(), o.GetRenderComponent())
+}
+
+// Remove stops tracking the given entity.
+func (t *TextInputSystem) Remove(basic ecs.BasicEntity) {
+	delete := -1
+	for index, e := range t.entities {
+		if e.BasicEntity.ID() == basic.ID() {
+			delete = index
+			break
+		}
+	}
+	if delete >= 0 {
+		t.entities = append(t.entities[:delete], t.entities[delete+1:]...)
+	}
+}
+
+// EntityCount returns the number of entities the TextInputSystem holds.
+func (t *TextInputSystem) EntityCount() int {
+	return len(t.entities)
+}
+
+// Update types into the focused text fields, and updates the Text of every text field.
+func (t *TextInputSystem) Update(dt float32) {
+	shortcut := engo.Input.Modifier&(engo.Control|engo.Super) != 0
+	if shortcut && engo.Input.Key(engo.KeyV).JustPressed() {
+		t.paste()
+	}
+	copied := shortcut && engo.Input.Key(engo.KeyC).JustPressed()
+
+	t.mutex.Lock()
+	typed, edit := string(t.typed), t.edit
+	t.typed, t.edit = t.typed[:0], nil
+	t.mutex.Unlock()
+
+	keys := t.pressed(dt)
+	submit := engo.Input.Key(engo.KeyEnter).JustPressed()
+
+	focused := false
+	for _, e := range t.entities {
+		if e.Focused {
+			focused = true
+			if edit != nil {
+				e.composition = edit.Text
+			}
+			if typed != "" {
+				// Committing the composition types it
+				e.composition = ""
+				e.Insert(typed)
+			}
+			for _, k := range keys {
+				switch k {
+				case engo.KeyBackspace:
+					e.Backspace()
+				case engo.KeyDelete:
+					e.Delete()
+				case engo.KeyArrowLeft:
+					e.MoveCaret(-1)
+				case engo.KeyArrowRight:
+					e.MoveCaret(1)
+				case engo.KeyHome:
+					e.SetCaret(0)
+				case engo.KeyEnd:
+					e.SetCaret(len(e.value))
+				}
+			}
+			if copied {
+				engo.Clipboard.Set(e.Value())
+			}
+			if submit {
+				e.Submit()
+			}
+		}
+
+		if txt, ok := e.Drawable.(Text); ok {
+			if display := e.display(); txt.Text != display {
+				txt.Text = display
+				e.Drawable = txt
+			}
+		}
+	}
+
+	if focused {
+		for _, k := range textInputKeys {
+			engo.Input.ConsumeKey(k)
+		}
+		engo.Input.ConsumeKey(engo.KeyEnter)
+	}
+}
+
+// paste types the text on the clipboard into the focused text fields. On the web, the clipboard is read
+// asynchronously, so the text is typed during a later Update.
+func (t *TextInputSystem) paste() {
+	engo.Clipboard.GetAsync(func(text string, err error) {
+		if err != nil {
+			return
+		}
+		t.mutex.Lock()
+		t.typed = append(t.typed, []rune(text)...)
+		t.mutex.Unlock()
+	})
+}
+
+// pressed returns the editing keys pressed during the current frame, including the repeats of the held key.
+func (t *TextInputSystem) pressed(dt float32) []engo.Key {
+	var keys []engo.Key
+	for _, k := range textInputKeys {
+		state := engo.Input.Key(k)
+		switch {
+		case state.JustPressed():
+			keys = append(keys, k)
+			t.held, t.heldTime = k, 0
+		case state.Down() && k == t.held:
+			t.heldTime += dt
+			if t.heldTime >= TextInputRepeatDelay {
+				keys = append(keys, k)
+				t.heldTime -= TextInputRepeatInterval
+			}
+		}
+	}
+	return keys
+}
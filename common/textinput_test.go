@@ -0,0 +1,168 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextInputComponentEditing(t *testing.T) {
+	c := &TextInputComponent{}
+	c.Insert("helo")
+	assert.Equal(t, "helo", c.Value(), "Inserted text should be typed")
+	assert.Equal(t, 4, c.Caret(), "The caret should be behind the inserted text")
+
+	c.MoveCaret(-1)
+	c.Insert("l")
+	assert.Equal(t, "hello", c.Value(), "Text should be inserted at the caret")
+	assert.Equal(t, 4, c.Caret())
+
+	c.Delete()
+	assert.Equal(t, "hell", c.Value(), "Delete should remove the character behind the caret")
+	c.Delete()
+	assert.Equal(t, "hell", c.Value(), "Delete at the end should do nothing")
+
+	c.SetCaret(1)
+	c.Backspace()
+	assert.Equal(t, "ell", c.Value(), "Backspace should remove the character in front of the caret")
+	assert.Equal(t, 0, c.Caret())
+	c.Backspace()
+	assert.Equal(t, "ell", c.Value(), "Backspace at the start should do nothing")
+
+	c.MoveCaret(-5)
+	assert.Equal(t, 0, c.Caret(), "The caret should stay within the value")
+	c.SetCaret(10)
+	assert.Equal(t, 3, c.Caret(), "The caret should stay within the value")
+
+	c.SetValue("héllo")
+	c.MoveCaret(-3)
+	c.Backspace()
+	assert.Equal(t, "hllo", c.Value(), "Editing should work on characters, not bytes")
+
+	c.Insert("\b\n\t")
+	assert.Equal(t, "hllo", c.Value(), "Control characters should not be typed")
+
+	c = &TextInputComponent{MaxLength: 3}
+	c.Insert("abcd")
+	assert.Equal(t, "abc", c.Value(), "Text should not be longer than MaxLength")
+	c.SetValue("abcdef")
+	assert.Equal(t, "abc", c.Value(), "Text should not be longer than MaxLength")
+}
+
+func TestTextInputSystem(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &CameraTestScene{})
+
+	type textField struct {
+		ecs.BasicEntity
+		TextInputComponent
+		RenderComponent
+	}
+	focused := &textField{BasicEntity: ecs.NewBasic()}
+	focused.Focused = true
+	focused.Drawable = Text{Font: &Font{}}
+	other := &textField{BasicEntity: ecs.NewBasic()}
+	other.Drawable = Text{Font: &Font{}}
+
+	sys := &TextInputSystem{}
+	sys.New(nil)
+	sys.Add(&focused.BasicEntity, &focused.TextInputComponent, &focused.RenderComponent)
+	sys.Add(&other.BasicEntity, &other.TextInputComponent, &other.RenderComponent)
+
+	for _, char := range "hi" {
+		engo.Mailbox.Dispatch(engo.TextMessage{Char: char})
+	}
+	sys.Update(0)
+	assert.Equal(t, "hi", focused.Value(), "The focused text field should be typed into")
+	assert.Equal(t, "", other.Value(), "Other text fields should not be typed into")
+	assert.Equal(t, "hi|", focused.Drawable.(Text).Text, "The focused text field should be drawn with its caret")
+
+	engo.Mailbox.Dispatch(engo.TextEditMessage{Text: "かな"})
+	sys.Update(0)
+	assert.Equal(t, "hi", focused.Value(), "Composed text should not be typed yet")
+	assert.Equal(t, "hiかな|", focused.Drawable.(Text).Text, "Composed text should be drawn at the caret")
+
+	engo.Mailbox.Dispatch(engo.TextEditMessage{})
+	engo.Mailbox.Dispatch(engo.TextMessage{Char: '仮'})
+	engo.Mailbox.Dispatch(engo.TextMessage{Char: '名'})
+	sys.Update(0)
+	assert.Equal(t, "hi仮名", focused.Value(), "Committed text should be typed")
+	assert.Equal(t, "", focused.Composition(), "Committed text should end the composition")
+
+	focused.Focused = false
+	sys.Update(0)
+	assert.Equal(t, "hi仮名", focused.Drawable.(Text).Text, "Unfocused text fields should be drawn without a caret")
+
+	var submitted string
+	focused.OnSubmit = func(value string) { submitted = value }
+	focused.Submit()
+	assert.Equal(t, "hi仮名", submitted, "Submitting should pass the value")
+}
+
+func TestTextInputSystemPaste(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &CameraTestScene{})
+
+	field := &TextInputComponent{Focused: true}
+	field.SetValue("ab")
+	field.MoveCaret(-1)
+	sys := &TextInputSystem{}
+	sys.New(nil)
+	sys.Add(&ecs.BasicEntity{}, field, &RenderComponent{})
+
+	engo.Clipboard.Set("xyz")
+	sys.paste()
+	sys.Update(0)
+	assert.Equal(t, "axyzb", field.Value(), "The clipboard should be pasted at the caret")
+}
+
+func TestTextInputSystemClose(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &CameraTestScene{})
+
+	field := &TextInputComponent{Focused: true}
+	sys := &TextInputSystem{}
+	sys.New(nil)
+	sys.New(nil)
+	sys.Add(&ecs.BasicEntity{}, field, &RenderComponent{})
+
+	engo.Mailbox.Dispatch(engo.TextMessage{Char: 'a'})
+	sys.Update(0)
+	assert.Equal(t, "a", field.Value(), "Setting the system up again should not type twice")
+
+	sys.Close()
+	engo.Mailbox.Dispatch(engo.TextMessage{Char: 'b'})
+	sys.Update(0)
+	assert.Equal(t, "a", field.Value(), "Closed systems should not be typed into")
+}
+
+func TestTextInputSystemConsumesKeys(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &CameraTestScene{})
+	engo.Input.RegisterButton("textInputErase", engo.KeyBackspace)
+	engo.Input.RegisterAction("textInputConfirm", engo.ActionKey{Key: engo.KeyEnter})
+
+	field := &TextInputComponent{}
+	sys := &TextInputSystem{}
+	sys.New(nil)
+	defer sys.Close()
+	sys.Add(&ecs.BasicEntity{}, field, &RenderComponent{})
+
+	sys.Update(0)
+	assert.False(t, engo.Input.Consumed("textInputErase"), "Editing keys should not be consumed without a focused text field")
+
+	field.Focused = true
+	sys.Update(0)
+	assert.True(t, engo.Input.Consumed("textInputErase"), "Buttons bound to editing keys should be consumed while typing")
+	assert.True(t, engo.Input.Consumed("textInputConfirm"), "Actions bound to Enter should be consumed while typing")
+}
@@ -0,0 +1,135 @@
+package common
+
+import (
+	"github.com/klopsch/engo"
+	"github.com/klopsch/gl"
+)
+
+// tileMeshVertices is the number of floats of a tile in a TileMesh: the position and texture coordinates of its four
+// corners.
+const tileMeshVertices = 16
+
+// TileMesh is a Drawable made of many tiles sharing a texture, such as the static tiles of a TileLayer baked with
+// BakeTileLayer. A single entity draws all of them, so a large map doesn't need an entity per tile, which the
+// RenderSystem would sort and cull every frame, and the tiles are batched into as few draw calls as the batch size
+// allows.
+//
+// TileMeshes are drawn by the DefaultShader and HUDShader.
+type TileMesh struct {
+	// Position is the top left of the tiles, where the SpaceComponent of the entity drawing the TileMesh is placed
+	// to draw the tiles where they are in the level.
+	Position engo.Point
+
+	texture       *gl.Texture
+	width, height float32
+	// vertices holds the corners of the tiles relative to Position, each as x, y, u, v, in the order the basicShader
+	// expects them
+	vertices []float32
+}
+
+// Texture returns the OpenGL ID of the texture the tiles are drawn from.
+func (m *TileMesh) Texture() *gl.Texture {
+	return m.texture
+}
+
+// Width returns the width of the area covered by the tiles.
+func (m *TileMesh) Width() float32 {
+	return m.width
+}
+
+// Height returns the height of the area covered by the tiles.
+func (m *TileMesh) Height() float32 {
+	return m.height
+}
+
+// View returns the whole texture, as every tile has its own region of it.
+func (m *TileMesh) View() (float32, float32, float32, float32) {
+	return 0, 0, 1, 1
+}
+
+// Close removes the texture data from the GPU.
+func (m *TileMesh) Close() {
+	if !engo.Headless() {
+		engo.Gl.DeleteTexture(m.texture)
+	}
+}
+
+// TileCount returns the number of tiles in the TileMesh.
+func (m *TileMesh) TileCount() int {
+	return len(m.vertices) / tileMeshVertices
+}
+
+// addTile adds the tile to the mesh, at its point in the level and rotated by its rotation.
+func (m *TileMesh) addTile(tile *Tile, model *engo.Matrix) {
+	w, h := tile.Image.Width(), tile.Image.Height()
+	u, v, u2, v2 := tile.Image.View()
+	model.Identity().Translate(tile.X, tile.Y)
+	if tile.Rotation != 0 {
+		model.Rotate(tile.Rotation)
+	}
+	for _, c := range [4][4]float32{{0, 0, u, v}, {w, 0, u2, v}, {w, h, u2, v2}, {0, h, u, v2}} {
+		p := engo.MultiplyMatrixVector(model, c[:2])
+		m.vertices = append(m.vertices, p[0], p[1], c[2], c[3])
+	}
+}
+
+// fit moves Position to the top left of the tiles, and sets the size of the mesh to the area they cover.
+func (m *TileMesh) fit() {
+	if len(m.vertices) == 0 {
+		return
+	}
+	min := engo.Point{X: m.vertices[0], Y: m.vertices[1]}
+	max := min
+	for i := 0; i < len(m.vertices); i += 4 {
+		x, y := m.vertices[i], m.vertices[i+1]
+		if x < min.X {
+			min.X = x
+		}
+		if y < min.Y {
+			min.Y = y
+		}
+		if x > max.X {
+			max.X = x
+		}
+		if y > max.Y {
+			max.Y = y
+		}
+	}
+	for i := 0; i < len(m.vertices); i += 4 {
+		m.vertices[i] -= min.X
+		m.vertices[i+1] -= min.Y
+	}
+	m.Position = min
+	m.width = max.X - min.X
+	m.height = max.Y - min.Y
+}
+
+// BakeTileLayer bakes the static tiles of the layer into a TileMesh per texture they're drawn from, in the order the
+// textures first appear. Draw each of them with an entity whose SpaceComponent is at the Position of the TileMesh.
+// Animated tiles can't be baked, and are returned to be added as entities of their own.
+//
+// Changing the tiles of the layer afterwards doesn't change the TileMeshes; bake it again instead.
+func BakeTileLayer(layer *TileLayer) (meshes []*TileMesh, animated []*Tile) {
+	byTexture := make(map[*gl.Texture]*TileMesh)
+	model := engo.IdentityMatrix()
+	for _, tile := range layer.Tiles {
+		if tile.Image == nil {
+			continue
+		}
+		if len(tile.Drawables) > 0 {
+			animated = append(animated, tile)
+			continue
+		}
+		mesh, ok := byTexture[tile.Image.Texture()]
+		if !ok {
+			mesh = &TileMesh{texture: tile.Image.Texture()}
+			byTexture[mesh.texture] = mesh
+			meshes = append(meshes, mesh)
+		}
+		mesh.addTile(tile, model)
+	}
+	for _, mesh := range meshes {
+		mesh.fit()
+	}
+	return meshes, animated
+}
@@ -0,0 +1,73 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBakeTileLayer(t *testing.T) {
+	tex := &Texture{width: 16, height: 16, viewport: engo.AABB{Min: engo.Point{X: 0.5, Y: 0}, Max: engo.Point{X: 1, Y: 0.5}}}
+	animated := &Tile{Point: engo.Point{X: 32, Y: 0}, Image: tex, Drawables: []Drawable{tex, tex}}
+	layer := &TileLayer{Tiles: []*Tile{
+		{Point: engo.Point{X: 16, Y: 16}, Image: tex},
+		{Point: engo.Point{X: 48, Y: 32}, Image: tex},
+		animated,
+		{Point: engo.Point{X: 64, Y: 64}},
+	}}
+
+	meshes, anim := BakeTileLayer(layer)
+	if !assert.Len(t, meshes, 1, "Tiles sharing a texture should be baked into a single mesh") {
+		return
+	}
+	assert.Equal(t, []*Tile{animated}, anim, "Animated tiles should not be baked")
+
+	m := meshes[0]
+	assert.Equal(t, 2, m.TileCount(), "Static tiles with an image should be baked")
+	assert.Equal(t, engo.Point{X: 16, Y: 16}, m.Position, "The mesh should start at its top left tile")
+	assert.Equal(t, float32(48), m.Width(), "The mesh should cover its tiles")
+	assert.Equal(t, float32(32), m.Height(), "The mesh should cover its tiles")
+	assert.Equal(t, []float32{
+		0, 0, 0.5, 0,
+		16, 0, 1, 0,
+		16, 16, 1, 0.5,
+		0, 16, 0.5, 0.5,
+	}, m.vertices[:tileMeshVertices], "Tiles should be baked relative to the mesh with their texture region")
+	assert.Equal(t, []float32{32, 16, 48, 16, 48, 32, 32, 32}, []float32{
+		m.vertices[16], m.vertices[17], m.vertices[20], m.vertices[21],
+		m.vertices[24], m.vertices[25], m.vertices[28], m.vertices[29],
+	}, "Tiles should be baked at their point in the level")
+}
+
+func TestBakeTileLayerRotation(t *testing.T) {
+	tex := &Texture{width: 16, height: 8, viewport: engo.AABB{Max: engo.Point{X: 1, Y: 1}}}
+	layer := &TileLayer{Tiles: []*Tile{{Point: engo.Point{X: 16, Y: 0}, Image: tex, Rotation: 90}}}
+
+	meshes, _ := BakeTileLayer(layer)
+	m := meshes[0]
+	// Rotating by 90 degrees around the top left turns the tile to the left of its point
+	assert.InDelta(t, 8, m.Position.X, 0.001, "Rotated tiles should be baked rotated around their point")
+	assert.InDelta(t, 0, m.Position.Y, 0.001, "Rotated tiles should be baked rotated around their point")
+	assert.InDelta(t, 8, m.Width(), 0.001, "Rotated tiles should swap their width and height")
+	assert.InDelta(t, 16, m.Height(), 0.001, "Rotated tiles should swap their width and height")
+}
+
+func newBakeBenchmarkLayer() *TileLayer {
+	tex := &Texture{width: 16, height: 16, viewport: engo.AABB{Max: engo.Point{X: 1, Y: 1}}}
+	layer := &TileLayer{}
+	for i := 0; i < 100*100; i++ {
+		layer.Tiles = append(layer.Tiles, &Tile{Point: engo.Point{X: float32(i%100) * 16, Y: float32(i/100) * 16}, Image: tex})
+	}
+	return layer
+}
+
+// BenchmarkBakeTileLayer bakes a layer of a 100×100 map, as done once when loading it.
+func BenchmarkBakeTileLayer(b *testing.B) {
+	layer := newBakeBenchmarkLayer()
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		BakeTileLayer(layer)
+	}
+}
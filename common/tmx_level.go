@@ -1,10 +1,17 @@
 package common
 
 import (
+	"bytes"
+	"encoding/hex"
+	"encoding/xml"
 	"errors"
+	"fmt"
+	"image/color"
 	"io"
+	"io/ioutil"
 	"path"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -12,13 +19,144 @@ import (
 	"github.com/Noofbiz/tmx"
 )
 
+var (
+	// externalTileset matches the elements of tilesets stored in their own file, and captures their source
+	externalTileset = regexp.MustCompile(`<tileset\s([^>]*?)\bsource="([^"]*)"([^>]*?)/>`)
+	// tilesetStart matches the start of the root element of a tileset file
+	tilesetStart = regexp.MustCompile(`<tileset\b`)
+)
+
+// inlineTilesets replaces the tilesets of the tmx data that are stored in their own file, by the contents of that
+// file opened through engo.Files.
+func inlineTilesets(data []byte, tmxURL string) ([]byte, error) {
+	var err error
+	data = externalTileset.ReplaceAllFunc(data, func(m []byte) []byte {
+		if err != nil {
+			return m
+		}
+		sub := externalTileset.FindSubmatch(m)
+		url := path.Join(path.Dir(tmxURL), string(sub[2]))
+		f, openErr := engo.Files.Open(url)
+		if openErr != nil {
+			err = fmt.Errorf("unable to open tileset %q: %s", url, openErr)
+			return m
+		}
+		defer f.Close()
+		tsx, readErr := ioutil.ReadAll(f)
+		if readErr != nil {
+			err = fmt.Errorf("unable to read tileset %q: %s", url, readErr)
+			return m
+		}
+		loc := tilesetStart.FindIndex(tsx)
+		if loc == nil {
+			err = fmt.Errorf("no tileset found in %q", url)
+			return m
+		}
+		// Keep the attributes of the element in the tmx, such as the firstgid, along with the ones of the file
+		attrs := bytes.TrimSpace(append(append([]byte{}, sub[1]...), sub[3]...))
+		inlined := append([]byte("<tileset "), attrs...)
+		return append(inlined, tsx[loc[1]:]...)
+	})
+	return data, err
+}
+
+// tilesetRegions returns the regions of the tiles of the tileset within its image of width by height pixels, in the
+// order of their ids. Like in Tiled, the tiles start margin pixels from the top left of the image, spacing pixels
+// apart. It returns an error when the tileset declares more tiles than the image holds.
+func tilesetRegions(ts tmx.Tileset, width, height float32) ([]SpriteRegion, error) {
+	if ts.TileWidth <= 0 || ts.TileHeight <= 0 {
+		return nil, fmt.Errorf("tileset %q has tiles of invalid size %dx%d", ts.Name, ts.TileWidth, ts.TileHeight)
+	}
+	margin := int(ts.Margin)
+	columns := (int(width) - margin + ts.Spacing) / (ts.TileWidth + ts.Spacing)
+	rows := (int(height) - margin + ts.Spacing) / (ts.TileHeight + ts.Spacing)
+	if columns < 0 || rows < 0 {
+		columns, rows = 0, 0
+	}
+	if ts.Columns != 0 && ts.Columns != columns {
+		return nil, fmt.Errorf("tileset %q declares %d columns, but its image holds %d", ts.Name, ts.Columns, columns)
+	}
+	count := columns * rows
+	if ts.TileCount > count {
+		return nil, fmt.Errorf("tileset %q declares %d tiles, but its image holds %d", ts.Name, ts.TileCount, count)
+	}
+	if ts.TileCount != 0 {
+		count = ts.TileCount
+	}
+
+	regions := make([]SpriteRegion, count)
+	for i := range regions {
+		x, y := i%columns, i/columns
+		regions[i] = SpriteRegion{
+			Position: engo.Point{
+				X: float32(margin + x*(ts.TileWidth+ts.Spacing)),
+				Y: float32(margin + y*(ts.TileHeight+ts.Spacing)),
+			},
+			Width:  ts.TileWidth,
+			Height: ts.TileHeight,
+		}
+	}
+	return regions, nil
+}
+
+// layerTints returns the tint colors of the tile layers of the tmx data in order, which the tmx package doesn't parse.
+// Layers without a tint have a nil color.
+func layerTints(data []byte) ([]color.Color, error) {
+	var m struct {
+		Layers []struct {
+			TintColor string `xml:"tintcolor,attr"`
+		} `xml:"layer"`
+	}
+	if err := xml.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	tints := make([]color.Color, len(m.Layers))
+	for i, l := range m.Layers {
+		if l.TintColor == "" {
+			continue
+		}
+		c, err := parseTiledColor(l.TintColor)
+		if err != nil {
+			return nil, err
+		}
+		tints[i] = c
+	}
+	return tints, nil
+}
+
+// parseTiledColor parses a color as written by Tiled, either #RRGGBB or #AARRGGBB.
+func parseTiledColor(s string) (color.Color, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "#"))
+	if err != nil || (len(b) != 3 && len(b) != 4) {
+		return nil, fmt.Errorf("invalid color %q", s)
+	}
+	if len(b) == 3 {
+		return color.NRGBA{R: b[0], G: b[1], B: b[2], A: 0xff}, nil
+	}
+	return color.NRGBA{R: b[1], G: b[2], B: b[3], A: b[0]}, nil
+}
+
 // createLevelFromTmx unmarshalls and unpacks tmx data into a Level
 func createLevelFromTmx(r io.Reader, tmxURL string, root string) (*Level, error) {
 	if root == "" {
 		return nil, errors.New("createLevelFromTmx should be called with a real root")
 	}
 	tmx.TMXURL = filepath.Join(root, tmxURL)
-	tmxLevel, err := tmx.Parse(r)
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	// The tmx package opens external tilesets from the file system, so they're inlined beforehand, to be found
+	// within the mounted archive as well
+	data, err = inlineTilesets(data, tmxURL)
+	if err != nil {
+		return nil, err
+	}
+	tmxLevel, err := tmx.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	tints, err := layerTints(data)
 	if err != nil {
 		return nil, err
 	}
@@ -27,6 +165,8 @@ func createLevelFromTmx(r io.Reader, tmxURL string, root string) (*Level, error)
 	level.resourceMap = make(map[uint32]Texture)
 	level.pointMap = make(map[mapPoint]*Tile)
 	level.framesMap = make(map[uint32][]uint32)
+	level.durationMap = make(map[uint32][]float32)
+	level.propertyMap = make(map[uint32][]Property)
 
 	// get a map of the gids to textures from the tilesets
 	for _, ts := range tmxLevel.Tilesets {
@@ -52,26 +192,38 @@ func createLevelFromTmx(r io.Reader, tmxURL string, root string) (*Level, error)
 				}
 			}
 			frames := []uint32{}
+			durations := []float32{}
 			for _, f := range t.AnimationFrames {
 				frames = append(frames, ts.FirstGID+f.TileID)
+				// Tiled stores frame durations in milliseconds
+				durations = append(durations, float32(f.Duration)/1000)
 			}
 			level.framesMap[ts.FirstGID+t.ID] = frames
+			level.durationMap[ts.FirstGID+t.ID] = durations
+			level.propertyMap[ts.FirstGID+t.ID] = getProperties(t.Properties)
 		}
 		for _, i := range ts.Image {
 			if i.Source != "" {
-				_, err := LoadedSprite(path.Join(path.Dir(tmxURL), i.Source))
+				tex, err := LoadedSprite(path.Join(path.Dir(tmxURL), i.Source))
 				if err != nil {
 					if strings.HasPrefix(err.Error(), "resource not loaded") {
 						err = engo.Files.Load(path.Join(path.Dir(tmxURL), i.Source))
 						if err != nil {
 							return nil, err
 						}
-						_, err = LoadedSprite(path.Join(path.Dir(tmxURL), i.Source))
+						tex, err = LoadedSprite(path.Join(path.Dir(tmxURL), i.Source))
+						if err != nil {
+							return nil, err
+						}
 					} else {
 						return nil, err
 					}
 				}
-				ss := NewSpritesheetWithBorderFromFile(path.Join(path.Dir(tmxURL), i.Source), ts.TileWidth, ts.TileHeight, ts.Spacing, ts.Spacing)
+				regions, err := tilesetRegions(ts, tex.Width(), tex.Height())
+				if err != nil {
+					return nil, err
+				}
+				ss := NewAsymmetricSpritesheetFromFile(path.Join(path.Dir(tmxURL), i.Source), regions)
 				for i, tex := range ss.Cells() {
 					level.resourceMap[ts.FirstGID+uint32(i)] = tex
 				}
@@ -89,8 +241,11 @@ func createLevelFromTmx(r io.Reader, tmxURL string, root string) (*Level, error)
 	level.Properties = getProperties(tmxLevel.Properties)
 
 	// tile layers
-	for _, l := range tmxLevel.Layers {
+	for idx, l := range tmxLevel.Layers {
 		tl := &TileLayer{}
+		if idx < len(tints) {
+			tl.Tint = tints[idx]
+		}
 		tl.Name = l.Name
 		tl.X = float32(l.X)
 		tl.OffSetX = float32(l.OffsetX)
@@ -110,6 +265,11 @@ func createLevelFromTmx(r io.Reader, tmxURL string, root string) (*Level, error)
 		}
 		tl.Properties = getProperties(l.Properties)
 		tl.Tiles = level.unpackTiles(0, 0, tl.Width, tl.Height, l.Data)
+		if min, max, ok := chunkBounds(l.Data); ok {
+			tl.Width = max.X - min.X
+			tl.Height = max.Y - min.Y
+			level.grow(min, max)
+		}
 		level.TileLayers = append(level.TileLayers, tl)
 	}
 
@@ -287,19 +447,19 @@ func (l *Level) unpackTiles(x, y, w, h int, d []tmx.Data) []*Tile {
 				}
 			}
 		}
+		// Chunk data is always stored left to right, top to bottom, starting at
+		// the chunk's offset, which may be negative on infinite maps.
 		for _, c := range data.Chunks {
-			x = c.X
-			y = c.Y
-			switch l.RenderOrder {
-			case ru:
-				y += c.Height - 1
-			case ld:
-				x += c.Width - 1
-			case lu:
-				x += c.Width - 1
-				y += c.Height - 1
+			if c.Width <= 0 {
+				continue
 			}
-			for _, t := range c.Tiles {
+			for i, t := range c.Tiles {
+				// Chunks of sparse maps are mostly empty, so only occupied
+				// cells get a tile
+				if t.GID == 0 {
+					continue
+				}
+				x, y := c.X+i%c.Width, c.Y+i/c.Width
 				tile := l.tileFromGID(t.GID, l.screenPoint(engo.Point{
 					X: float32(x),
 					Y: float32(y),
@@ -307,38 +467,40 @@ func (l *Level) unpackTiles(x, y, w, h int, d []tmx.Data) []*Tile {
 				tile.Rotation = convertFlipToRotation(t.Flipping)
 				ret = append(ret, tile)
 				l.pointMap[mapPoint{X: x, Y: y}] = tile
-				switch l.RenderOrder {
-				case rd:
-					x++
-					if x >= c.X+c.Width {
-						x = c.X
-						y++
-					}
-				case ru:
-					x++
-					if x >= c.X+c.Width {
-						x = c.X
-						y--
-					}
-				case ld:
-					x--
-					if x < c.X {
-						x = c.X + c.Width - 1
-						y++
-					}
-				case lu:
-					x--
-					if x < c.Width {
-						x = c.X + c.Width - 1
-						y--
-					}
-				}
 			}
 		}
 	}
 	return ret
 }
 
+// chunkBounds returns the area covered by the chunks in d, in tiles. ok is
+// false if the data isn't chunked.
+func chunkBounds(d []tmx.Data) (min, max mapPoint, ok bool) {
+	for _, data := range d {
+		for _, c := range data.Chunks {
+			if !ok {
+				min = mapPoint{X: c.X, Y: c.Y}
+				max = mapPoint{X: c.X + c.Width, Y: c.Y + c.Height}
+				ok = true
+				continue
+			}
+			if c.X < min.X {
+				min.X = c.X
+			}
+			if c.Y < min.Y {
+				min.Y = c.Y
+			}
+			if c.X+c.Width > max.X {
+				max.X = c.X + c.Width
+			}
+			if c.Y+c.Height > max.Y {
+				max.Y = c.Y + c.Height
+			}
+		}
+	}
+	return
+}
+
 func (l *Level) imageTiles(tmxURL string, imgs []tmx.Image, x, y float32) ([]*Tile, error) {
 	ret := make([]*Tile, 0)
 	for _, i := range imgs {
@@ -374,6 +536,7 @@ func (l *Level) tileFromGID(gid uint32, pt engo.Point) *Tile {
 	tex := l.resourceMap[gid]
 	ret.Image = &tex
 	ret.Point = pt
+	ret.Properties = l.propertyMap[gid]
 
 	drawables, frames := []Drawable{}, []int{}
 	for i, id := range l.framesMap[gid] {
@@ -381,7 +544,7 @@ func (l *Level) tileFromGID(gid uint32, pt engo.Point) *Tile {
 		frames = append(frames, i)
 	}
 	ret.Drawables = drawables
-	ret.Animation = &Animation{Name: "Tile", Frames: frames, Loop: true}
+	ret.Animation = &Animation{Name: "Tile", Frames: frames, Loop: true, Durations: l.durationMap[gid]}
 
 	return ret
 }
@@ -1,6 +1,15 @@
 package common
 
-import "testing"
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"image/color"
+	"testing"
+
+	"github.com/Noofbiz/tmx"
+	"github.com/klopsch/engo"
+)
 
 func TestConvertFlipToRotation(t *testing.T) {
 	tests := []struct {
@@ -38,3 +47,134 @@ func TestConvertFlipToRotation(t *testing.T) {
 		}
 	}
 }
+
+func TestUnpackTilesChunks(t *testing.T) {
+	l := &Level{
+		Orientation: orth,
+		RenderOrder: "left-up",
+		TileWidth:   16,
+		TileHeight:  16,
+		width:       3,
+		height:      3,
+		resourceMap: make(map[uint32]Texture),
+		pointMap:    make(map[mapPoint]*Tile),
+	}
+	data := []tmx.Data{{
+		Chunks: []tmx.Chunk{
+			{X: -16, Y: -16, Width: 2, Height: 2, Tiles: []tmx.TileData{{GID: 0}, {GID: 1}, {GID: 2}, {GID: 0}}},
+			{X: 32, Y: 0, Width: 2, Height: 2, Tiles: []tmx.TileData{{GID: 3}, {}, {}, {}}},
+		},
+	}}
+
+	tiles := l.unpackTiles(0, 0, 3, 3, data)
+	if len(tiles) != 3 {
+		t.Fatalf("empty chunk cells should not get tiles expected=%d ; got=%d", 3, len(tiles))
+	}
+
+	tests := []struct {
+		name     string
+		point    engo.Point
+		expected engo.Point
+	}{
+		{
+			name:     "tile in a chunk with negative coordinates",
+			point:    engo.Point{X: -15*16 + 1, Y: -16*16 + 1},
+			expected: engo.Point{X: -15 * 16, Y: -16 * 16},
+		},
+		{
+			name:     "tile on the second row of a chunk",
+			point:    engo.Point{X: -16*16 + 1, Y: -15*16 + 1},
+			expected: engo.Point{X: -16 * 16, Y: -15 * 16},
+		},
+		{
+			name:     "tile in a chunk far from the origin",
+			point:    engo.Point{X: 32*16 + 1, Y: 1},
+			expected: engo.Point{X: 32 * 16, Y: 0},
+		},
+	}
+	for _, test := range tests {
+		tile := l.GetTile(test.point)
+		if tile == nil {
+			t.Errorf("%s expected a tile at %v", test.name, test.point)
+			continue
+		}
+		if tile.Point != test.expected {
+			t.Errorf("%s expected=%v ; got=%v", test.name, test.expected, tile.Point)
+		}
+	}
+	if l.GetTile(engo.Point{X: -16*16 + 1, Y: -16*16 + 1}) != nil {
+		t.Errorf("empty chunk cells should not get tiles")
+	}
+
+	min, max, ok := chunkBounds(data)
+	if !ok {
+		t.Fatalf("chunked data should have bounds")
+	}
+	l.grow(min, max)
+	bounds := l.Bounds()
+	expected := engo.AABB{Min: engo.Point{X: -16 * 16, Y: -16 * 16}, Max: engo.Point{X: 34 * 16, Y: 3 * 16}}
+	if bounds != expected {
+		t.Errorf("bounds should cover all chunks and the map expected=%v ; got=%v", expected, bounds)
+	}
+}
+
+func TestInlineTilesets(t *testing.T) {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	w, _ := zw.Create("maps/tiles.tsx")
+	w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.2" name="tiles" tilewidth="16" tileheight="16" tilecount="4" columns="2">
+ <image source="tiles.png" width="32" height="32"/>
+</tileset>
+`))
+	zw.Close()
+	if err := engo.Files.MountArchiveData(buf.Bytes()); err != nil {
+		t.Fatalf("Unable to mount the archive: %v", err)
+	}
+	defer engo.Files.UnmountArchive()
+
+	data := []byte(`<map version="1.2"><tileset firstgid="5" source="tiles.tsx"/><layer name="ground"/></map>`)
+	inlined, err := inlineTilesets(data, "maps/level.tmx")
+	if err != nil {
+		t.Fatalf("Unable to inline the tileset: %v", err)
+	}
+	level := tmx.Map{}
+	if err := xml.Unmarshal(inlined, &level); err != nil {
+		t.Fatalf("Unable to parse the inlined tileset: %v\n%s", err, inlined)
+	}
+	if len(level.Tilesets) != 1 {
+		t.Fatalf("Expected 1 tileset, got %d", len(level.Tilesets))
+	}
+	ts := level.Tilesets[0]
+	if ts.FirstGID != 5 || ts.Name != "tiles" || ts.TileWidth != 16 || ts.Source != "" {
+		t.Errorf("The tileset was not inlined properly: %+v", ts)
+	}
+	if len(ts.Image) != 1 || ts.Image[0].Source != "tiles.png" {
+		t.Errorf("The image of the tileset was not inlined: %+v", ts.Image)
+	}
+
+	if _, err := inlineTilesets([]byte(`<map><tileset firstgid="1" source="missing.tsx"/></map>`), "level.tmx"); err == nil {
+		t.Error("Inlining a tileset that doesn't exist should fail")
+	}
+}
+
+func TestLayerTints(t *testing.T) {
+	data := []byte(`<map version="1.4"><layer name="ground"/><layer name="water" tintcolor="#8000ff80"/><layer name="fog" tintcolor="#ff0000"/></map>`)
+	tints, err := layerTints(data)
+	if err != nil {
+		t.Fatalf("Unable to parse the layer tints: %v", err)
+	}
+	exp := []color.Color{nil, color.NRGBA{R: 0, G: 0xff, B: 0x80, A: 0x80}, color.NRGBA{R: 0xff, A: 0xff}}
+	if len(tints) != len(exp) {
+		t.Fatalf("Tints were not parsed for every layer\nWanted: %v\nGot: %v", exp, tints)
+	}
+	for i := range exp {
+		if tints[i] != exp[i] {
+			t.Errorf("Tint of layer %v was not parsed correctly\nWanted: %v\nGot: %v", i, exp[i], tints[i])
+		}
+	}
+
+	if _, err := layerTints([]byte(`<map><layer tintcolor="#12345"/></map>`)); err == nil {
+		t.Error("Invalid tint colors should return an error")
+	}
+}
@@ -26,6 +26,10 @@ var testTMXtmpl = `
     <property name="walkable" type="bool" value="false"/>
    </properties>
    <image width="132" height="99" source="test.png{{ .BadExtensions }}"/>
+   <animation>
+    <frame tileid="0" duration="100"/>
+    <frame tileid="1" duration="250"/>
+   </animation>
   </tile>
   <tile id="1">
    <image width="132" height="99" source="test.png{{ .BadExtensions }}"/>
@@ -340,6 +344,53 @@ func TestTMXTileImages(t *testing.T) {
 	}
 }
 
+func TestTMXAnimationDurations(t *testing.T) {
+	imgbuf := bytes.NewBuffer([]byte{})
+	img := image.NewRGBA(image.Rect(0, 0, 132, 99))
+	err := png.Encode(imgbuf, img)
+	if err != nil {
+		t.Errorf("Unable to encode png from image")
+	}
+	err = engo.Files.LoadReaderData("test.png", imgbuf)
+	if err != nil {
+		t.Errorf("Unable to load test png. Error was: %v", err)
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	tmpl, err := template.New("test").Parse(testTMXtmpl)
+	if err != nil {
+		t.Error("Error parsing tmx template")
+	}
+	err = tmpl.Execute(buf, tmxData{
+		Orientation: "orthogonal",
+		RenderOrder: "right-down",
+		Tiles:       true,
+	})
+	if err != nil {
+		t.Error("Error executing tmx template")
+	}
+
+	err = engo.Files.LoadReaderData("test.tmx", buf)
+	if err != nil {
+		t.Fatalf("Unable to load tmx file for testing. Error was: %v", err)
+	}
+	res, err := engo.Files.Resource("test.tmx")
+	if err != nil {
+		t.Fatalf("Unable to retrieve tmx resource. Error was: %v", err)
+	}
+
+	tile := res.(TMXResource).Level.tileFromGID(1, engo.Point{})
+	exp := []float32{0.1, 0.25}
+	if len(tile.Animation.Durations) != len(exp) {
+		t.Fatalf("Wrong number of frame durations\nWanted: %v\nGot: %v", exp, tile.Animation.Durations)
+	}
+	for i := range exp {
+		if tile.Animation.Durations[i] != exp[i] {
+			t.Errorf("Wrong frame duration\nWanted: %v\nGot: %v", exp, tile.Animation.Durations)
+		}
+	}
+}
+
 func TestTMXTileImagesNotLoadedFileNotExist(t *testing.T) {
 	buf := bytes.NewBuffer([]byte{})
 	tmpl, err := template.New("test").Parse(testTMXtmpl)
@@ -721,3 +772,83 @@ func TestTMXLevelIsometric(t *testing.T) {
 		t.Errorf("Tile was not returned correctly\nWanted: %v\nGot: %v", expTile, tile.Point)
 	}
 }
+
+// testTMXSpacedTileset is a 30x20 image of 8x8 tiles in 3 columns and 2 rows, with a margin of 1px around them and a
+// spacing of 2px between them.
+var testTMXSpacedTileset = `
+<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.0" tiledversion="1.1.5" orientation="orthogonal" renderorder="right-down" width="2" height="2" tilewidth="8" tileheight="8" infinite="0" nextobjectid="1">
+ <tileset firstgid="1" name="spaced" tilewidth="8" tileheight="8" spacing="2" margin="1" tilecount="{{ .TileCount }}" columns="3">
+  <image source="spaced.png" width="30" height="20"/>
+ </tileset>
+ <layer name="Tile Layer 1" width="2" height="2">
+  <data encoding="csv">
+1,2,
+5,6
+  </data>
+ </layer>
+</map>
+`
+
+func loadSpacedTileset(tileCount int) error {
+	imgbuf := bytes.NewBuffer([]byte{})
+	if err := png.Encode(imgbuf, image.NewRGBA(image.Rect(0, 0, 30, 20))); err != nil {
+		return err
+	}
+	if err := engo.Files.LoadReaderData("spaced.png", imgbuf); err != nil {
+		return err
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	tmpl, err := template.New("test").Parse(testTMXSpacedTileset)
+	if err != nil {
+		return err
+	}
+	if err = tmpl.Execute(buf, struct{ TileCount int }{tileCount}); err != nil {
+		return err
+	}
+	return engo.Files.LoadReaderData("spaced.tmx", buf)
+}
+
+func TestTMXTilesetMarginSpacing(t *testing.T) {
+	if err := loadSpacedTileset(6); err != nil {
+		t.Fatalf("Unable to load tmx file for testing. Error was: %v", err)
+	}
+	defer engo.Files.Unload("spaced.tmx")
+
+	resource, err := engo.Files.Resource("spaced.tmx")
+	if err != nil {
+		t.Fatalf("Unable to retrieve resource. Error was: %v", err)
+	}
+	level := resource.(TMXResource).Level
+
+	if len(level.resourceMap) != 6 {
+		t.Errorf("Tileset was not sliced into its tiles\nWanted: %v\nGot: %v", 6, len(level.resourceMap))
+	}
+	// The tiles are at 1, 11 and 21 pixels horizontally, and 1 and 11 vertically
+	exp := map[uint32][4]float32{
+		1: {1, 1, 9, 9},
+		2: {11, 1, 19, 9},
+		3: {21, 1, 29, 9},
+		5: {11, 11, 19, 19},
+		6: {21, 11, 29, 19},
+	}
+	for gid, e := range exp {
+		tex := level.resourceMap[gid]
+		u, v, u2, v2 := tex.View()
+		want := [4]float32{e[0] / 30, e[1] / 20, e[2] / 30, e[3] / 20}
+		if got := [4]float32{u, v, u2, v2}; got != want {
+			t.Errorf("Tile %v was not extracted correctly\nWanted: %v\nGot: %v", gid, want, got)
+		}
+		if tex.Width() != 8 || tex.Height() != 8 {
+			t.Errorf("Tile %v size was not extracted correctly\nWanted: 8x8\nGot: %vx%v", gid, tex.Width(), tex.Height())
+		}
+	}
+}
+
+func TestTMXTilesetTileCountMismatch(t *testing.T) {
+	if err := loadSpacedTileset(7); err == nil {
+		engo.Files.Unload("spaced.tmx")
+		t.Error("Loading a tileset declaring more tiles than its image holds should fail")
+	}
+}
@@ -0,0 +1,238 @@
+package common
+
+import (
+	"image"
+	"image/color"
+	"time"
+
+	"github.com/klopsch/engo"
+)
+
+var (
+	// lastRenderSystem is the RenderSystem that drew the most recent frame.
+	lastRenderSystem *RenderSystem
+	activeTransition *transition
+	// whiteTexture is a single white pixel, used to draw solid colors.
+	whiteTexture *Texture
+)
+
+// Transition is an effect played when switching scenes with SetSceneWithTransition. It's drawn on top of the
+// incoming scene, which has already been drawn, for its Duration.
+type Transition interface {
+	// Duration returns how long the transition takes.
+	Duration() time.Duration
+	// Draw draws the transition. from is the last frame of the outgoing scene, and progress goes from 0 at the start
+	// of the transition to 1 at the end. DrawFullscreen can be used to draw it.
+	Draw(from Drawable, progress float32)
+}
+
+type transition struct {
+	Transition
+	from    *RenderTexture
+	target  *RenderSystem
+	elapsed float32
+}
+
+// transitionStartMessage is dispatched to the incoming scene when a transition starts, for its RenderSystem to
+// draw it.
+type transitionStartMessage struct{}
+
+func (transitionStartMessage) Type() string {
+	return "transitionStartMessage"
+}
+
+// SetSceneWithTransition switches to the Scene like engo.SetScene, and plays the Transition from the last frame of the
+// current scene into the new scene. Input is ignored while the transition plays. The new scene needs a RenderSystem
+// for the transition to be played, otherwise, as in headless mode, the scene is switched without a transition.
+// Switching scenes again ends the transition, as does CancelTransition.
+func SetSceneWithTransition(s engo.Scene, forceNewWorld bool, t Transition) {
+	if engo.Headless() || lastRenderSystem == nil {
+		engo.SetScene(s, forceNewWorld)
+		return
+	}
+
+	from := lastRenderSystem.capture()
+	engo.SetScene(s, forceNewWorld)
+	startTransition(t, from)
+}
+
+// startTransition plays the Transition in the current scene, or ends it right away if the scene has no RenderSystem
+// to draw it.
+func startTransition(t Transition, from *RenderTexture) {
+	endTransition()
+	activeTransition = &transition{
+		Transition: t,
+		from:       from,
+	}
+	engo.Input.SetIgnored(true)
+
+	engo.Mailbox.Dispatch(transitionStartMessage{})
+	if activeTransition != nil && activeTransition.target == nil {
+		endTransition()
+	}
+}
+
+// CancelTransition stops the transition started by SetSceneWithTransition, if one is playing, so the new scene is
+// shown and gets input right away.
+func CancelTransition() {
+	endTransition()
+}
+
+// endTransition discards the active transition, and stops ignoring input.
+func endTransition() {
+	t := activeTransition
+	if t == nil {
+		return
+	}
+	if t.from != nil {
+		t.from.Close()
+	}
+	activeTransition = nil
+	engo.Input.SetIgnored(false)
+}
+
+// listenTransitions makes the RenderSystem draw the transitions into its scene, until its scene is left.
+func (rs *RenderSystem) listenTransitions() {
+	engo.Mailbox.Listen("transitionStartMessage", func(engo.Message) {
+		if activeTransition != nil {
+			activeTransition.target = rs
+		}
+	})
+	engo.Mailbox.Listen("SceneSwitchMessage", func(engo.Message) {
+		if activeTransition != nil && activeTransition.target == rs {
+			endTransition()
+		}
+	})
+}
+
+// capture draws the RenderSystem to a texture the size of the canvas.
+func (rs *RenderSystem) capture() *RenderTexture {
+	w, h := int(engo.CanvasWidth()), int(engo.CanvasHeight())
+	tex := CreateRenderTexture(w, h, false)
+	fb := CreateFramebuffer()
+	fb.Open(w, h)
+	tex.Bind()
+	rs.draw(0)
+	fb.Close()
+	fb.Destroy()
+	return tex
+}
+
+// drawTransition advances the active transition and draws it on top of the scene.
+func (rs *RenderSystem) drawTransition(dt float32) {
+	t := activeTransition
+	if t == nil || rs != t.target {
+		// The outgoing scene finishes its last frame without the transition
+		return
+	}
+
+	t.elapsed += dt
+	progress := float32(1)
+	if d := float32(t.Duration().Seconds()); d > 0 {
+		progress = t.elapsed / d
+	}
+	if progress >= 1 {
+		endTransition()
+		return
+	}
+	t.Draw(t.from, progress)
+}
+
+// DrawFullscreen draws the Drawable stretched over the whole screen, tinted by the color and moved by the offset in
+// HUD coordinates. RenderTextures are drawn upside down from how OpenGL stores them, so they appear as they were
+// drawn. If d is nil, the color itself is drawn. It's meant to be used by Transitions.
+func DrawFullscreen(d Drawable, c color.Color, offset engo.Point) {
+	if d == nil {
+		d = solidTexture()
+	}
+	_, flip := d.(*RenderTexture)
+
+	w, h := viewSize()
+	ren := &RenderComponent{
+		Drawable: d,
+		Color:    c,
+		Opacity:  1,
+		Scale:    engo.Point{X: w / d.Width(), Y: h / d.Height()},
+		FlipY:    flip,
+	}
+	space := &SpaceComponent{Position: offset, Width: w, Height: h}
+
+	HUDShader.PrepareCulling()
+	HUDShader.Pre()
+	HUDShader.Draw(ren, space)
+	HUDShader.Post()
+}
+
+// solidTexture returns a single white pixel, which is tinted to draw solid colors.
+func solidTexture() *Texture {
+	if whiteTexture == nil {
+		img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+		img.Set(0, 0, color.White)
+		tex := NewTextureSingle(NewImageObject(img))
+		whiteTexture = &tex
+	}
+	return whiteTexture
+}
+
+// straightAlpha returns the color with its alpha set to a, without premultiplying it. The shaders expect colors with
+// straight alpha.
+func straightAlpha(c color.Color, a float32) color.Color {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return color.RGBA{R: n.R, G: n.G, B: n.B, A: uint8(a * 255)}
+}
+
+// FadeTransition fades between scenes. Without a color, the outgoing scene dissolves into the incoming scene.
+// With a color, the outgoing scene fades to the color during the first half, and the incoming scene fades in from the
+// color during the second half.
+type FadeTransition struct {
+	duration time.Duration
+	color    color.Color
+}
+
+// NewFadeTransition creates a FadeTransition taking the given duration. Pass nil as the color to dissolve directly
+// into the incoming scene, or a color such as color.Black to fade through it.
+func NewFadeTransition(d time.Duration, c color.Color) *FadeTransition {
+	return &FadeTransition{duration: d, color: c}
+}
+
+// Duration returns how long the transition takes.
+func (f *FadeTransition) Duration() time.Duration {
+	return f.duration
+}
+
+// Draw draws the transition.
+func (f *FadeTransition) Draw(from Drawable, progress float32) {
+	if f.color == nil {
+		DrawFullscreen(from, straightAlpha(color.White, 1-progress), engo.Point{})
+		return
+	}
+	if progress < 0.5 {
+		DrawFullscreen(from, color.White, engo.Point{})
+		DrawFullscreen(nil, straightAlpha(f.color, progress*2), engo.Point{})
+		return
+	}
+	DrawFullscreen(nil, straightAlpha(f.color, (1-progress)*2), engo.Point{})
+}
+
+// SlideTransition slides the outgoing scene off the screen, revealing the incoming scene.
+type SlideTransition struct {
+	duration  time.Duration
+	direction engo.Point
+}
+
+// NewSlideTransition creates a SlideTransition taking the given duration. The direction is the direction the outgoing
+// scene moves in, such as engo.Point{X: -1} to slide it to the left.
+func NewSlideTransition(d time.Duration, direction engo.Point) *SlideTransition {
+	return &SlideTransition{duration: d, direction: direction}
+}
+
+// Duration returns how long the transition takes.
+func (s *SlideTransition) Duration() time.Duration {
+	return s.duration
+}
+
+// Draw draws the transition.
+func (s *SlideTransition) Draw(from Drawable, progress float32) {
+	w, h := viewSize()
+	DrawFullscreen(from, color.White, engo.Point{X: s.direction.X * w * progress, Y: s.direction.Y * h * progress})
+}
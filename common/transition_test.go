@@ -0,0 +1,66 @@
+package common
+
+import (
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStraightAlpha(t *testing.T) {
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 127}, straightAlpha(color.White, 0.5), "The color should not be premultiplied")
+	assert.Equal(t, color.RGBA{R: 255, G: 0, B: 0, A: 255}, straightAlpha(color.NRGBA{R: 255, A: 64}, 1), "The alpha of the color should be replaced")
+	assert.Equal(t, color.RGBA{A: 0}, straightAlpha(color.Black, 0))
+}
+
+func TestTransitionDuration(t *testing.T) {
+	assert.Equal(t, time.Second, NewFadeTransition(time.Second, color.Black).Duration())
+	assert.Equal(t, 2*time.Second, NewSlideTransition(2*time.Second, engo.Point{X: -1}).Duration())
+}
+
+type transitionTestScene struct {
+	name   string
+	render bool
+}
+
+func (*transitionTestScene) Preload() {}
+
+func (s *transitionTestScene) Setup(u engo.Updater) {
+	if w, ok := u.(*ecs.World); ok && s.render {
+		w.AddSystem(&RenderSystem{})
+	}
+}
+
+func (s *transitionTestScene) Type() string { return s.name }
+
+func TestTransitionInputIgnored(t *testing.T) {
+	engo.Run(engo.RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &transitionTestScene{name: "transitionFrom"})
+	defer engo.Input.SetIgnored(false)
+	fade := NewFadeTransition(time.Second, nil)
+
+	engo.SetScene(&transitionTestScene{name: "transitionNoRender"}, false)
+	startTransition(fade, nil)
+	assert.Nil(t, activeTransition, "Scenes without a RenderSystem should be shown without the transition")
+	assert.False(t, engo.Input.Ignored(), "Input should not be ignored without a transition to wait for")
+
+	engo.SetScene(&transitionTestScene{name: "transitionRender", render: true}, false)
+	startTransition(fade, nil)
+	if assert.NotNil(t, activeTransition) {
+		assert.NotNil(t, activeTransition.target, "The RenderSystem of the new scene should draw the transition")
+	}
+	assert.True(t, engo.Input.Ignored(), "Input should be ignored while the transition plays")
+	CancelTransition()
+	assert.Nil(t, activeTransition)
+	assert.False(t, engo.Input.Ignored(), "Cancelling the transition should stop ignoring input")
+
+	startTransition(fade, nil)
+	engo.SetScene(&transitionTestScene{name: "transitionNoRender"}, false)
+	assert.Nil(t, activeTransition, "Leaving the scene should end its transition")
+	assert.False(t, engo.Input.Ignored(), "Leaving the scene should stop ignoring input")
+}
@@ -0,0 +1,265 @@
+package common
+
+import (
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/klopsch/engo/math"
+)
+
+// Easing maps the linear progress of a Tween, from 0 to 1, to the eased
+// progress. Easings may overshoot [0, 1], such as EaseOutElastic.
+type Easing func(t float32) float32
+
+// EaseLinear progresses at a constant rate.
+func EaseLinear(t float32) float32 {
+	return t
+}
+
+// EaseInQuad starts slow and accelerates.
+func EaseInQuad(t float32) float32 {
+	return t * t
+}
+
+// EaseOutQuad starts fast and decelerates.
+func EaseOutQuad(t float32) float32 {
+	return t * (2 - t)
+}
+
+// EaseInOutQuad accelerates until halfway, then decelerates.
+func EaseInOutQuad(t float32) float32 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return -1 + (4-2*t)*t
+}
+
+// EaseInCubic starts slow and accelerates, more sharply than EaseInQuad.
+func EaseInCubic(t float32) float32 {
+	return t * t * t
+}
+
+// EaseOutCubic starts fast and decelerates, more sharply than EaseOutQuad.
+func EaseOutCubic(t float32) float32 {
+	t--
+	return t*t*t + 1
+}
+
+// EaseInOutCubic accelerates until halfway, then decelerates.
+func EaseInOutCubic(t float32) float32 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	t = 2*t - 2
+	return t*t*t/2 + 1
+}
+
+// EaseOutElastic overshoots the end and springs back to it.
+func EaseOutElastic(t float32) float32 {
+	if t <= 0 || t >= 1 {
+		return math.Clamp(t, 0, 1)
+	}
+	return math.Pow(2, -10*t)*math.Sin((t-0.075)*(2*math.Pi)/0.3) + 1
+}
+
+// EaseOutBounce bounces against the end a few times before settling on it.
+func EaseOutBounce(t float32) float32 {
+	const n, d = 7.5625, 2.75
+	switch {
+	case t < 1/d:
+		return n * t * t
+	case t < 2/d:
+		t -= 1.5 / d
+		return n*t*t + 0.75
+	case t < 2.5/d:
+		t -= 2.25 / d
+		return n*t*t + 0.9375
+	default:
+		t -= 2.625 / d
+		return n*t*t + 0.984375
+	}
+}
+
+// Tween changes a value over time. Step is called every update with the eased
+// progress, which goes from 0 to 1 over Duration seconds. Use TweenFloat and
+// TweenPoint to animate a value from one point to another.
+type Tween struct {
+	// Duration is how long the tween takes, in seconds.
+	Duration float32
+	// Easing is the curve used to progress. Defaults to EaseLinear.
+	Easing Easing
+	// Step applies the eased progress to the tweened value.
+	Step func(progress float32)
+	// OnComplete is called once the tween has finished, if set.
+	OnComplete func()
+	// Next is started once the tween has finished, if set.
+	Next *Tween
+
+	elapsed float32
+}
+
+// TweenFloat returns a Tween that animates target from `from` to `to` over
+// duration seconds.
+func TweenFloat(target *float32, from, to, duration float32, easing Easing) *Tween {
+	return &Tween{
+		Duration: duration,
+		Easing:   easing,
+		Step: func(progress float32) {
+			*target = math.Lerp(from, to, progress)
+		},
+	}
+}
+
+// TweenPoint returns a Tween that animates target from `from` to `to` over
+// duration seconds.
+func TweenPoint(target *engo.Point, from, to engo.Point, duration float32, easing Easing) *Tween {
+	return &Tween{
+		Duration: duration,
+		Easing:   easing,
+		Step: func(progress float32) {
+			*target = engo.LerpPoint(from, to, progress)
+		},
+	}
+}
+
+// Then starts next once the last tween in the chain of t has finished. It
+// returns next, so sequences can be chained as a.Then(b).Then(c).
+func (t *Tween) Then(next *Tween) *Tween {
+	last := t
+	for last.Next != nil {
+		last = last.Next
+	}
+	last.Next = next
+	return next
+}
+
+// Sequence chains the given tweens so they're played one after another, and
+// returns the first one.
+func Sequence(tweens ...*Tween) *Tween {
+	if len(tweens) == 0 {
+		return nil
+	}
+	for i := 1; i < len(tweens); i++ {
+		tweens[i-1].Then(tweens[i])
+	}
+	return tweens[0]
+}
+
+// Finished returns whether the tween has run for its whole Duration. It does
+// not take Next into account.
+func (t *Tween) Finished() bool {
+	return t.elapsed >= t.Duration
+}
+
+// Reset rewinds the tween, so it can be played again.
+func (t *Tween) Reset() {
+	t.elapsed = 0
+}
+
+// advance progresses the tween by dt seconds and returns the time left over
+// once it has finished, or a negative value if it's still running.
+func (t *Tween) advance(dt float32) float32 {
+	t.elapsed += dt
+	progress := float32(1)
+	if t.Duration > 0 {
+		progress = math.Min(t.elapsed/t.Duration, 1)
+	}
+	easing := t.Easing
+	if easing == nil {
+		easing = EaseLinear
+	}
+	if t.Step != nil {
+		t.Step(easing(progress))
+	}
+	if t.elapsed < t.Duration {
+		return -1
+	}
+	if t.OnComplete != nil {
+		t.OnComplete()
+	}
+	return t.elapsed - t.Duration
+}
+
+// TweenComponent holds the tweens that are playing on an entity. Each tween,
+// together with the ones chained to it through Next, plays independently of the
+// others. Finished tweens are removed by the TweenSystem.
+type TweenComponent struct {
+	Tweens []*Tween
+}
+
+// Add starts playing the given tween on the entity.
+func (tc *TweenComponent) Add(t *Tween) {
+	tc.Tweens = append(tc.Tweens, t)
+}
+
+// Playing returns whether any tween is still playing on the entity.
+func (tc *TweenComponent) Playing() bool {
+	return len(tc.Tweens) > 0
+}
+
+// TweenSystem advances the tweens of every TweenComponent.
+type TweenSystem struct {
+	entities []tweenEntity
+}
+
+type tweenEntity struct {
+	*ecs.BasicEntity
+	*TweenComponent
+}
+
+// Add starts tracking the given entity.
+func (t *TweenSystem) Add(basic *ecs.BasicEntity, tween *TweenComponent) {
+	t.entities = append(t.entities, tweenEntity{basic, tween})
+}
+
+// AddByInterface allows an Entity to be added directly using the Tweenable
+// interface, which every entity containing the BasicEntity and TweenComponent
+// anonymously automatically satisfies.
+func (t *TweenSystem) AddByInterface(i ecs.Identifier) {
+	o, _ := i.(Tweenable)
+	t.Add(o.GetBasicEntity(), o.GetTweenComponent())
+}
+
+// Remove stops tracking the given entity.
+func (t *TweenSystem) Remove(basic ecs.BasicEntity) {
+	delete := -1
+	for index, e := range t.entities {
+		if e.BasicEntity.ID() == basic.ID() {
+			delete = index
+			break
+		}
+	}
+	if delete >= 0 {
+		t.entities = append(t.entities[:delete], t.entities[delete+1:]...)
+	}
+}
+
+// EntityCount returns the number of entities the TweenSystem holds.
+func (t *TweenSystem) EntityCount() int {
+	return len(t.entities)
+}
+
+// Update advances the tweens of all tracked entities by dt seconds.
+func (t *TweenSystem) Update(dt float32) {
+	for _, e := range t.entities {
+		// Tweens added by an OnComplete callback end up in e.Tweens, and only
+		// start playing on the next update.
+		tweens := e.Tweens
+		e.Tweens = nil
+		playing := tweens[:0]
+		for _, tween := range tweens {
+			left := tween.advance(dt)
+			// Time left over from a finished tween carries on to the next one
+			for left >= 0 && tween.Next != nil {
+				tween = tween.Next
+				left = tween.advance(left)
+			}
+			if left < 0 {
+				playing = append(playing, tween)
+			}
+		}
+		for i := len(playing); i < len(tweens); i++ {
+			tweens[i] = nil
+		}
+		e.Tweens = append(playing, e.Tweens...)
+	}
+}
@@ -0,0 +1,66 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/klopsch/ecs"
+	"github.com/klopsch/engo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEasings(t *testing.T) {
+	easings := map[string]Easing{
+		"Linear":     EaseLinear,
+		"InQuad":     EaseInQuad,
+		"OutQuad":    EaseOutQuad,
+		"InOutQuad":  EaseInOutQuad,
+		"InCubic":    EaseInCubic,
+		"OutCubic":   EaseOutCubic,
+		"InOutCubic": EaseInOutCubic,
+		"OutElastic": EaseOutElastic,
+		"OutBounce":  EaseOutBounce,
+	}
+	for name, easing := range easings {
+		assert.InDelta(t, 0, easing(0), 1e-5, "%s should start at 0", name)
+		assert.InDelta(t, 1, easing(1), 1e-5, "%s should end at 1", name)
+	}
+	assert.InDelta(t, 0.25, EaseInQuad(0.5), 1e-5)
+	assert.InDelta(t, 0.875, EaseOutCubic(0.5), 1e-5)
+	assert.InDelta(t, 0.5, EaseInOutCubic(0.5), 1e-5)
+}
+
+func TestTweenSystem(t *testing.T) {
+	var value float32
+	var position engo.Point
+	completed := 0
+
+	fade := TweenFloat(&value, 0, 1, 1, EaseLinear)
+	fade.OnComplete = func() { completed++ }
+	move := Sequence(
+		TweenPoint(&position, engo.Point{}, engo.Point{X: 10}, 0.5, EaseLinear),
+		TweenPoint(&position, engo.Point{X: 10}, engo.Point{X: 10, Y: 10}, 0.5, EaseLinear),
+	)
+
+	basic := ecs.NewBasic()
+	tc := &TweenComponent{}
+	tc.Add(fade)
+	tc.Add(move)
+	sys := &TweenSystem{}
+	sys.Add(&basic, tc)
+
+	sys.Update(0.25)
+	assert.InDelta(t, 0.25, value, 1e-5)
+	assert.InDelta(t, 5, position.X, 1e-5)
+	assert.Equal(t, float32(0), position.Y)
+
+	sys.Update(0.5)
+	assert.InDelta(t, 0.75, value, 1e-5)
+	assert.Equal(t, float32(10), position.X, "The first tween of the sequence should have finished")
+	assert.InDelta(t, 5, position.Y, 1e-5, "Time left over should carry on to the next tween")
+
+	sys.Update(0.5)
+	assert.Equal(t, float32(1), value)
+	assert.Equal(t, engo.Point{X: 10, Y: 10}, position)
+	assert.Equal(t, 1, completed, "OnComplete should be called once")
+	assert.False(t, tc.Playing(), "Finished tweens should be removed")
+}
@@ -1,5 +1,7 @@
 package engo
 
+import "image"
+
 // Cursor is a reference to standard cursors, to be used in conjunction with `SetCursor`. What they look like, is
 // different for each platform.
 type Cursor uint8
@@ -20,3 +22,31 @@ const (
 	// CursorVResize represents a VResize cursor
 	CursorVResize
 )
+
+// cursorGrabbed is whether the cursor is grabbed, as set with SetCursorGrabbed
+var cursorGrabbed bool
+
+// SetCustomCursor replaces the pointer of the mouse with the image, until SetCursor is called. The hotspot at hotX,
+// hotY is the pixel of the image that points at the position of the mouse, such as the tip of an arrow. Keep the
+// image small, 32x32 pixels is supported everywhere, as platforms limit the size of cursors.
+func SetCustomCursor(img image.Image, hotX, hotY int) {
+	if !opts.HeadlessMode {
+		setCustomCursor(img, hotX, hotY)
+	}
+}
+
+// SetCursorGrabbed grabs the cursor, hiding it and keeping it at the center of the window, or releases it. While
+// it's grabbed, Input.Mouse.X and Y stay at the center, and only DeltaX and DeltaY report how far the mouse moved,
+// which is what first-person cameras need. On the web, browsers only allow grabbing the cursor in response to a key
+// press or a click, and release it when the player presses Escape. CursorGrabbed tells whether that happened.
+func SetCursorGrabbed(grabbed bool) {
+	cursorGrabbed = grabbed
+	if !opts.HeadlessMode {
+		setCursorGrabbed(grabbed)
+	}
+}
+
+// CursorGrabbed returns whether the cursor is grabbed.
+func CursorGrabbed() bool {
+	return cursorGrabbed
+}
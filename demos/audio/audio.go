@@ -48,7 +48,7 @@ func (s *DefaultScene) Setup(u engo.Updater) {
 	}
 	birds.AudioComponent = common.AudioComponent{Player: birdPlayer}
 	birdPlayer.Play()
-	birdPlayer.Repeat = true
+	birdPlayer.SetLoop(true)
 
 	// Let's add our birds to the appropriate systems
 	for _, system := range w.Systems() {
@@ -4,7 +4,7 @@ package main
 
 import (
 	"image/color"
-	"sync"
+	"log"
 
 	"github.com/klopsch/ecs"
 	"github.com/klopsch/engo"
@@ -19,13 +19,18 @@ type MyLabel struct {
 	common.SpaceComponent
 }
 
+type MyTextField struct {
+	ecs.BasicEntity
+	common.RenderComponent
+	common.SpaceComponent
+	common.TextInputComponent
+}
+
 func (*DefaultScene) Preload() {
 	err := engo.Files.Load("Roboto-Regular.ttf")
 	if err != nil {
 		panic(err)
 	}
-	engo.Input.RegisterButton("backspace", engo.KeyBackspace)
-	engo.Input.RegisterButton("enter", engo.KeyEnter)
 }
 
 // Setup is called before the main loop is started
@@ -34,7 +39,7 @@ func (*DefaultScene) Setup(u engo.Updater) {
 
 	common.SetBackground(color.White)
 	w.AddSystem(&common.RenderSystem{})
-	w.AddSystem(&TypingSystem{})
+	w.AddSystem(&common.TextInputSystem{})
 
 	fnt := &common.Font{
 		URL:  "Roboto-Regular.ttf",
@@ -52,85 +57,28 @@ func (*DefaultScene) Setup(u engo.Updater) {
 		Text: "Start Typing to add text!",
 	}
 
-	for _, system := range w.Systems() {
-		switch sys := system.(type) {
-		case *common.RenderSystem:
-			sys.Add(&label1.BasicEntity, &label1.RenderComponent, &label1.SpaceComponent)
-		}
-	}
-}
-
-func (*DefaultScene) Type() string { return "Game" }
-
-type TypingSystem struct {
-	label             MyLabel
-	runeLock          sync.Mutex
-	runesToAdd        []rune
-	timeSinceDeletion float32
-}
-
-func (t *TypingSystem) New(w *ecs.World) {
-	fnt := &common.Font{
-		URL:  "Roboto-Regular.ttf",
-		FG:   color.Black,
-		Size: 64,
-	}
-	err := fnt.CreatePreloaded()
-	if err != nil {
-		panic(err)
-	}
-
-	t.label = MyLabel{BasicEntity: ecs.NewBasic()}
-	t.label.SpaceComponent.Position.Set(0, 75)
-	t.label.RenderComponent.Drawable = common.Text{
+	field := MyTextField{BasicEntity: ecs.NewBasic()}
+	field.SpaceComponent.Position.Set(0, 75)
+	field.RenderComponent.Drawable = common.Text{
 		Font: fnt,
-		Text: "",
+	}
+	field.TextInputComponent.Focused = true
+	field.TextInputComponent.OnSubmit = func(value string) {
+		log.Printf("Submitted %q", value)
 	}
 
 	for _, system := range w.Systems() {
 		switch sys := system.(type) {
 		case *common.RenderSystem:
-			sys.Add(&t.label.BasicEntity, &t.label.RenderComponent, &t.label.SpaceComponent)
+			sys.Add(&label1.BasicEntity, &label1.RenderComponent, &label1.SpaceComponent)
+			sys.Add(&field.BasicEntity, &field.RenderComponent, &field.SpaceComponent)
+		case *common.TextInputSystem:
+			sys.Add(&field.BasicEntity, &field.TextInputComponent, &field.RenderComponent)
 		}
 	}
-
-	engo.Mailbox.Listen("TextMessage", func(msg engo.Message) {
-		m, ok := msg.(engo.TextMessage)
-		if !ok {
-			return
-		}
-		t.runeLock.Lock()
-		t.runesToAdd = append(t.runesToAdd, m.Char)
-		t.runeLock.Unlock()
-	})
 }
 
-func (*TypingSystem) Remove(ecs.BasicEntity) {}
-
-func (t *TypingSystem) Update(dt float32) {
-	t.timeSinceDeletion += dt
-	str := ""
-	txt := t.label.Drawable.(common.Text)
-	if engo.Input.Button("backspace").Down() && len(txt.Text) > 0 {
-		if t.timeSinceDeletion > 0.2 {
-			t.timeSinceDeletion = 0
-			txt.Text = txt.Text[:len(txt.Text)-1]
-			t.label.Drawable = txt
-		}
-		return
-	}
-	t.runeLock.Lock()
-	if len(t.runesToAdd) != 0 {
-		str = string(t.runesToAdd)
-	}
-	t.runesToAdd = make([]rune, 0)
-	t.runeLock.Unlock()
-	txt.Text += str
-	if engo.Input.Button("enter").JustPressed() {
-		txt.Text += "\n"
-	}
-	t.label.Drawable = txt
-}
+func (*DefaultScene) Type() string { return "Game" }
 
 func main() {
 	opts := engo.RunOptions{
@@ -50,29 +50,35 @@ func (game *GameWorld) Setup(u engo.Updater) {
 	tileComponents := make([]*Tile, 0)
 
 	for idx, tileLayer := range levelData.TileLayers {
-		for _, tileElement := range tileLayer.Tiles {
-			if tileElement.Image != nil {
+		// The static tiles of the layer are baked into a TileMesh per tileset, each drawn by a single entity
+		meshes, animated := common.BakeTileLayer(tileLayer)
+		for _, mesh := range meshes {
+			tile := &Tile{BasicEntity: ecs.NewBasic()}
+			// The opacity, tint and visibility of the layer are applied to its tiles
+			tile.RenderComponent = tileLayer.RenderComponent(mesh, float32(idx))
+			tile.SpaceComponent = common.SpaceComponent{
+				Position: mesh.Position,
+			}
 
-				tile := &Tile{BasicEntity: ecs.NewBasic()}
-				if len(tileElement.Drawables) > 0 {
-					tile.AnimationComponent = common.NewAnimationComponent(
-						tileElement.Drawables, 0.5,
-					)
-					tile.AnimationComponent.AddDefaultAnimation(tileElement.Animation)
-				}
-				tile.RenderComponent = common.RenderComponent{
-					Drawable:    tileElement.Image,
-					Scale:       engo.Point{X: 1, Y: 1},
-					StartZIndex: float32(idx),
-				}
-				tile.SpaceComponent = common.SpaceComponent{
-					Position: tileElement.Point,
-					Width:    0,
-					Height:   0,
-				}
+			tileComponents = append(tileComponents, tile)
+		}
 
-				tileComponents = append(tileComponents, tile)
+		// Animated tiles stay entities of their own
+		for _, tileElement := range animated {
+			tile := &Tile{BasicEntity: ecs.NewBasic()}
+			// The rate is only used for frames without a duration set in Tiled
+			tile.AnimationComponent = common.NewAnimationComponent(
+				tileElement.Drawables, 0.5,
+			)
+			tile.AnimationComponent.AddDefaultAnimation(tileElement.Animation)
+			tile.RenderComponent = tileLayer.RenderComponent(tileElement.Image, float32(idx))
+			tile.SpaceComponent = common.SpaceComponent{
+				Position: tileElement.Point,
+				Width:    0,
+				Height:   0,
 			}
+
+			tileComponents = append(tileComponents, tile)
 		}
 	}
 
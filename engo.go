@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"sync"
+	"time"
 
 	"github.com/klopsch/ecs"
 )
@@ -48,6 +49,7 @@ var (
 	canvasWidth, canvasHeight float32
 	headlessWidth             = 800
 	headlessHeight            = 800
+	msaaSamples               = 1
 
 	// CurrentBackEnd is the current back end used for window management
 	CurrentBackEnd BackEnd
@@ -71,6 +73,24 @@ const (
 	DefaultMouseXAxis = "mouse x"
 	// DefaultMouseYAxis is the name of the default vertical mouse axis
 	DefaultMouseYAxis = "mouse y"
+
+	// NoFPSLimit is the FPSLimit that lets the main loop run as fast as it can
+	NoFPSLimit = -1
+)
+
+// ScaleMode is how the game is scaled when the window is resized.
+type ScaleMode uint8
+
+const (
+	// ScaleExpand keeps things at the same size, so a larger window shows more of the world.
+	ScaleExpand ScaleMode = iota
+	// ScaleStretch keeps the game at the same size, stretching it to fill the window. Its aspect ratio changes
+	// along with the one of the window.
+	ScaleStretch
+	// ScaleLetterbox keeps the game at the same size, scaling it to fit the window while keeping its aspect ratio.
+	// The rest of the window is filled with black bars, at the top and bottom of the game when the window is too
+	// tall (letterbox), or at its sides when it's too wide (pillarbox).
+	ScaleLetterbox
 )
 
 // RunOptions are the options used to Run engo
@@ -84,9 +104,23 @@ type RunOptions struct {
 	// HeadlessMode indicates whether or not OpenGL calls should be made
 	HeadlessMode bool
 
+	// Offscreen renders into a hidden window instead of a visible one. Unlike HeadlessMode, OpenGL calls are made as
+	// usual, so the RenderSystem draws every frame and the result can be read back, which allows golden-image tests of
+	// shaders and levels. Combined with NoRun, the scene is set up and Run returns without destroying the window, so
+	// tests can call Update on the World themselves and DestroyWindow when done. All of this must happen on the same
+	// goroutine, locked to its thread with runtime.LockOSThread, as the OpenGL context is bound to it.
+	//
+	// Offscreen still needs an OpenGL 2.1 driver and a display server, and is only supported by the GLFW and SDL
+	// backends. Machines without a GPU, such as CI runners, can use Mesa's software renderer on a virtual X server:
+	// `LIBGL_ALWAYS_SOFTWARE=1 xvfb-run go test -tags offscreen ./...`. It is ignored when HeadlessMode is set.
+	Offscreen bool
+
 	// Fullscreen indicates the game should run in fullscreen mode if run on a desktop
 	Fullscreen bool
 
+	// FullscreenMode is how the game fills the screen while it's fullscreen. Defaults to FullscreenExclusive.
+	FullscreenMode FullscreenMode
+
 	Width, Height int
 
 	// GlobalScale scales all size/render components by the scale factor
@@ -102,12 +136,45 @@ type RunOptions struct {
 	// Resizable indicates whether or not the Window should be resizable.  Defaults to `false`.
 	NotResizable bool
 
-	// ScaleOnResize indicates whether or not engo should make things larger/smaller whenever the screen resizes
+	// ScaleOnResize indicates whether or not engo should make things larger/smaller whenever the screen resizes. It's
+	// the same as a ScaleMode of ScaleStretch.
 	ScaleOnResize bool
 
-	// FPSLimit indicates the maximum number of frames per second
+	// ScaleMode is how the game is scaled when the window is resized. Defaults to ScaleExpand, or to ScaleStretch
+	// when ScaleOnResize is set.
+	ScaleMode ScaleMode
+
+	// Anisotropy is the anisotropic filtering level given to textures as they're uploaded, which keeps scaled and
+	// rotated sprites sharp when seen at oblique angles. Values of 1 or less disable it, and higher values are capped
+	// to what the driver supports. It needs the GL_EXT_texture_filter_anisotropic extension, and is silently ignored
+	// when that isn't available. It's only supported by the GLFW back end for now, and ignored by the others,
+	// including WebGL and mobile even where their driver has the extension.
+	Anisotropy float32
+
+	// SRGB renders in linear space, which makes alpha blending, and anything else mixing colors, look the way it
+	// would with real light. Textures are treated as sRGB, and converted to linear space when sampled, as are the
+	// colors of the RenderComponents, shapes, text and the background. The frame is converted back to sRGB when
+	// written to an sRGB framebuffer. It changes the look of existing games, as blending gets brighter, so it's off
+	// by default. It's only supported by the GLFW and SDL back ends, and ignored with a warning by the others.
+	SRGB bool
+
+	// FPSLimit is the maximum number of frames per second. The main loop waits out the remainder of every frame, so
+	// the game doesn't keep the CPU busy without VSync. Defaults to 60, use NoFPSLimit to run as fast as possible,
+	// such as when VSync already paces the loop. The dt passed to the systems is the time that actually passed since
+	// the previous frame, so it stays correct whatever the limit. It's ignored with WebGL, where the browser paces
+	// the frames with requestAnimationFrame.
 	FPSLimit int
 
+	// FixedStep is the timestep with which systems implementing FixedUpdater are updated. Each frame, FixedUpdate is
+	// called as many times as FixedStep fits in the time that passed, so these systems always get the same dt. If
+	// left at 0, FixedUpdate is never called.
+	FixedStep time.Duration
+
+	// MaxFixedSteps is the maximum number of times FixedUpdate is called in a single frame. When the game can't keep
+	// up, the remaining time is dropped rather than catching up in the next frames, which would only make it slower.
+	// Defaults to 5.
+	MaxFixedSteps int
+
 	// OverrideCloseAction indicates that (when true) engo will never close whenever the gamer wants to close the
 	// game - that will be your responsibility
 	OverrideCloseAction bool
@@ -116,16 +183,19 @@ type RunOptions struct {
 	// <ENTER>.
 	StandardInputs bool
 
-	// MSAA indicates the amount of samples that should be taken. Leaving it blank will default to 1, and you may
-	// use any positive value you wish. It may be possible that the operating system / environment doesn't support
-	// the requested amount. In that case, GLFW will (hopefully) pick the highest supported sampling count. The higher
-	// the value, the bigger the performance cost.
+	// MSAA is the amount of samples taken of every pixel for multi-sampling anti-aliasing: 0 or 1 to disable it, or
+	// 2, 4 or 8. Other values are lowered to the closest supported one, with a warning. The higher the value, the
+	// bigger the performance cost. When the operating system / environment doesn't support the requested amount, the
+	// window is created with as many samples as it does support, which is logged and returned by MSAA().
 	//
 	// Our `RenderSystem` automatically calls `gl.Enable(gl.MULTISAMPLE)` (which is required to make use of it), but
-	// if you're going to use your own rendering `System` instead, you will have to call it yourself.
+	// if you're going to use your own rendering `System` instead, you will have to call it yourself. When the
+	// `RenderSystem` has post-processes, the frame is drawn into a multi-sampled buffer which is resolved before
+	// the post-processes sample it, as textures can't be multi-sampled.
 	//
-	// Also note that this value is entirely ignored in WebGL - most browsers enable it by default when available, and
-	// none of them (at time of writing) allow you to tune it.
+	// In WebGL, the amount can't be chosen: any value above 1 asks the browser to anti-alias the canvas, with the
+	// amount of samples it picks, and post-processes sample the frame without anti-aliasing, as WebGL 1 can't resolve
+	// multi-sampled buffers.
 	//
 	// More info at https://www.opengl.org/wiki/Multisampling
 	// "With multisampling, each pixel at the edge of a polygon is sampled multiple times."
@@ -139,6 +209,17 @@ type RunOptions struct {
 	// use any subfolder-structure within that `assets` directory.
 	AssetsRoot string
 
+	// AssetsArchive is the path of an archive packed with PackArchive, which resources are loaded from when it
+	// exists. Resources which aren't in it, or all of them if there's no such archive, are loaded from the
+	// AssetsRoot. Leaving this at empty-string, will default this to the AssetsRoot followed by `.zip`.
+	AssetsArchive string
+
+	// HotReload checks the files of the loaded resources for changes, and loads them again when they change, which
+	// allows tweaking assets without restarting the game. An AssetReloadedMessage is dispatched for each of them.
+	// Only files on the file system are checked, not the ones within archives. It's meant for development, and
+	// should be left off in released games.
+	HotReload bool
+
 	// MobileWidth and MobileHeight are the width and height given from the Android/iOS OpenGL Surface used for Gomobile bind
 	MobileWidth, MobileHeight int
 
@@ -159,20 +240,28 @@ func Run(o RunOptions, defaultScene Scene) {
 	// Setting defaults
 	if o.FPSLimit == 0 {
 		o.FPSLimit = 60
+	} else if o.FPSLimit < 0 {
+		o.FPSLimit = NoFPSLimit
 	}
 
 	if o.MSAA < 0 {
 		panic("MSAA has to be greater or equal to 0")
 	}
 
-	if o.MSAA == 0 {
-		o.MSAA = 1
+	o.MSAA = supportedMSAA(o.MSAA)
+
+	if o.MaxFixedSteps <= 0 {
+		o.MaxFixedSteps = 5
 	}
 
 	if len(o.AssetsRoot) == 0 {
 		o.AssetsRoot = "assets"
 	}
 
+	if len(o.AssetsArchive) == 0 {
+		o.AssetsArchive = o.AssetsRoot + ".zip"
+	}
+
 	if o.Update == nil {
 		o.Update = &ecs.World{}
 	}
@@ -181,7 +270,14 @@ func Run(o RunOptions, defaultScene Scene) {
 		o.GlobalScale = Point{X: 1, Y: 1}
 	}
 
+	if o.ScaleMode != ScaleExpand {
+		o.ScaleOnResize = true
+	} else if o.ScaleOnResize {
+		o.ScaleMode = ScaleStretch
+	}
+
 	opts = o
+	msaaSamples = o.MSAA
 
 	// Create input
 	Input = NewInputManager()
@@ -199,6 +295,13 @@ func Run(o RunOptions, defaultScene Scene) {
 	}
 
 	Files.SetRoot(opts.AssetsRoot)
+	Files.UnmountArchive()
+	if f, err := openFile(opts.AssetsArchive); err == nil {
+		// The archive is optional, so it's only an issue when it exists but can't be read
+		if err := Files.mountArchive(f); err != nil {
+			log.Println("[WARNING] Unable to mount the assets archive:", err)
+		}
+	}
 	currentUpdater = opts.Update
 
 	// And run the game
@@ -222,7 +325,19 @@ func Run(o RunOptions, defaultScene Scene) {
 			SetScene(defaultScene, true)
 		}
 	} else {
+		if opts.Offscreen {
+			if opts.Width == 0 {
+				opts.Width = headlessWidth
+			}
+			if opts.Height == 0 {
+				opts.Height = headlessHeight
+			}
+		}
 		CreateWindow(opts.Title, opts.Width, opts.Height, opts.Fullscreen, opts.MSAA)
+		if opts.Offscreen && opts.NoRun {
+			SetScene(defaultScene, true)
+			return
+		}
 		defer DestroyWindow()
 
 		if !opts.NoRun {
@@ -232,8 +347,19 @@ func Run(o RunOptions, defaultScene Scene) {
 }
 
 // SetScaleOnResize can be used to change the value in the given `RunOpts` after already having called `engo.Run`.
+// It sets the ScaleMode to ScaleStretch if true, and to ScaleExpand if false.
 func SetScaleOnResize(b bool) {
-	opts.ScaleOnResize = b
+	if b {
+		SetScaleMode(ScaleStretch)
+	} else {
+		SetScaleMode(ScaleExpand)
+	}
+}
+
+// SetScaleMode can be used to change the value in the given `RunOpts` after already having called `engo.Run`.
+func SetScaleMode(mode ScaleMode) {
+	opts.ScaleMode = mode
+	opts.ScaleOnResize = mode != ScaleExpand
 }
 
 // SetOverrideCloseAction can be used to change the value in the given `RunOpts` after already having called `engo.Run`.
@@ -242,8 +368,9 @@ func SetOverrideCloseAction(value bool) {
 }
 
 // SetFPSLimit can be used to change the value in the given `RunOpts` after already having called `engo.Run`.
+// Pass NoFPSLimit to remove the limit.
 func SetFPSLimit(limit int) error {
-	if limit <= 0 {
+	if limit <= 0 && limit != NoFPSLimit {
 		return fmt.Errorf("FPS Limit out of bounds. Requires > 0")
 	}
 	opts.FPSLimit = limit
@@ -251,11 +378,78 @@ func SetFPSLimit(limit int) error {
 	return nil
 }
 
+// newTicker creates the tickers of loopTicker, so the tests can tick the main loop themselves.
+var newTicker = time.NewTicker
+
+// loopTicker returns the ticker pacing the main loop to the FPSLimit. Without a limit it ticks as often as the loop
+// can keep up with.
+func loopTicker() *time.Ticker {
+	if opts.FPSLimit <= 0 {
+		return newTicker(time.Nanosecond)
+	}
+	return newTicker(time.Second / time.Duration(opts.FPSLimit))
+}
+
 // Headless indicates whether or not OpenGL-calls should be made
 func Headless() bool {
 	return opts.HeadlessMode
 }
 
+// Offscreen indicates whether the game renders into a hidden window
+func Offscreen() bool {
+	return opts.Offscreen && !opts.HeadlessMode
+}
+
+// Anisotropy is the anisotropic filtering level textures get when they're uploaded
+func Anisotropy() float32 {
+	return opts.Anisotropy
+}
+
+// SRGB indicates whether the game renders in linear space, with sRGB textures and an sRGB framebuffer
+func SRGB() bool {
+	return opts.SRGB
+}
+
+// srgbUnsupported turns off rendering in linear space, for the back ends which don't support it.
+func srgbUnsupported() {
+	if opts.SRGB {
+		log.Println("[WARNING] SRGB is not supported by this back end, rendering in sRGB space")
+		opts.SRGB = false
+	}
+}
+
+// MSAA returns the amount of samples per pixel the window got for multi-sampling anti-aliasing, which is 1 when
+// it's disabled. It may be lower than RunOptions.MSAA when the requested amount isn't supported.
+func MSAA() int {
+	return msaaSamples
+}
+
+// supportedMSAA returns the closest amount of samples to n which can be requested, which is 1 to disable
+// multi-sampling, or 2, 4 or 8.
+func supportedMSAA(n int) int {
+	supported := 1
+	for _, s := range []int{2, 4, 8} {
+		if s <= n {
+			supported = s
+		}
+	}
+	if n > 1 && n != supported {
+		log.Printf("[WARNING] MSAA of %d is not supported, using %d instead", n, supported)
+	}
+	return supported
+}
+
+// setMSAA records the amount of samples the window got, warning when it's less than requested.
+func setMSAA(got int) {
+	if got < 1 {
+		got = 1
+	}
+	if got < opts.MSAA {
+		log.Printf("[WARNING] MSAA of %d is not available, using %d instead", opts.MSAA, got)
+	}
+	msaaSamples = got
+}
+
 // ScaleOnResize indicates whether or not the screen should resize (i.e. make things look smaller/bigger) whenever
 // the window resized. If `false`, then the size of the screen does not affect the size of the things drawn - it just
 // makes less/more objects visible
@@ -263,6 +457,35 @@ func ScaleOnResize() bool {
 	return opts.ScaleOnResize
 }
 
+// GetScaleMode returns how the game is scaled when the window is resized.
+func GetScaleMode() ScaleMode {
+	return opts.ScaleMode
+}
+
+// GameViewport returns the part of the canvas the game is drawn in, as fractions of the size of the canvas with
+// (0, 0) being its top-left corner. It's the whole canvas, unless the ScaleMode is ScaleLetterbox and the aspect
+// ratio of the window differs from the one of the game.
+func GameViewport() AABB {
+	full := AABB{Max: Point{X: 1, Y: 1}}
+	if opts.ScaleMode != ScaleLetterbox {
+		return full
+	}
+	canvasW, canvasH := CanvasWidth(), CanvasHeight()
+	gameW, gameH := GameWidth(), GameHeight()
+	if canvasW <= 0 || canvasH <= 0 || gameW <= 0 || gameH <= 0 {
+		return full
+	}
+
+	canvasRatio, gameRatio := canvasW/canvasH, gameW/gameH
+	if canvasRatio > gameRatio {
+		// Bars at the sides
+		w := gameRatio / canvasRatio
+		return AABB{Min: Point{X: (1 - w) / 2}, Max: Point{X: (1 + w) / 2, Y: 1}}
+	}
+	h := canvasRatio / gameRatio
+	return AABB{Min: Point{Y: (1 - h) / 2}, Max: Point{X: 1, Y: (1 + h) / 2}}
+}
+
 // Exit is the safest way to close your game, as `engo` will correctly attempt to close all windows, handlers and contexts
 func Exit() {
 	closeGameOnce.Do(func() {
@@ -300,6 +523,21 @@ func runHeadless(defaultScene Scene) {
 	runLoop(defaultScene, true)
 }
 
+// updateScene is called by the back ends every frame. It replays the recorded input and reloads the changed files if
+// needed, runs the fixed updates, draws the Scenes kept drawn on the scene stack, and updates the current Scene.
+func updateScene(dt float32) {
+	if Input != nil {
+		Input.resetConsumed()
+		dt = Input.replayFrame(dt)
+	}
+	if opts.HotReload {
+		Files.hotReload()
+	}
+	fixedUpdate(dt)
+	drawStack(dt)
+	updateCurrentScene(dt)
+}
+
 // GetGlobalScale returns the GlobalScale factor set in the RunOptions or via
 // SetGlobalScale()
 func GetGlobalScale() Point {
@@ -4,12 +4,12 @@
 package engo
 
 import (
+	"image"
 	"io"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/klopsch/gl"
 )
@@ -45,7 +45,7 @@ func SetTitle(title string) {
 // RunIteration runs one iteration per frame
 func RunIteration() {
 	Time.Tick()
-	currentUpdater.Update(Time.Delta())
+	updateScene(Time.Delta())
 }
 
 // RunPreparation is called automatically when calling Open. It should only be called once.
@@ -64,7 +64,7 @@ func runLoop(defaultScene Scene, headless bool) {
 	}()
 
 	RunPreparation(defaultScene)
-	ticker := time.NewTicker(time.Duration(int(time.Second) / opts.FPSLimit))
+	ticker := loopTicker()
 
 	// Start tick, minimize the delta
 	Time.Tick()
@@ -75,7 +75,7 @@ func runLoop(defaultScene Scene, headless bool) {
 			RunIteration()
 		case <-resetLoopTicker:
 			ticker.Stop()
-			ticker = time.NewTicker(time.Duration(int(time.Second) / opts.FPSLimit))
+			ticker = loopTicker()
 		case <-closeGame:
 			ticker.Stop()
 			closeEvent()
@@ -128,6 +128,12 @@ func SetVSync(enabled bool) {}
 // SetCursorVisibility does nothing since there's no headless cursor
 func SetCursorVisibility(visible bool) {}
 
+// setCustomCursor does nothing since there's no headless cursor
+func setCustomCursor(img image.Image, hotX, hotY int) {}
+
+// setCursorGrabbed does nothing since there's no headless cursor
+func setCursorGrabbed(grabbed bool) {}
+
 // openFile is the desktop-specific way of opening a file
 func openFile(url string) (io.ReadCloser, error) {
 	return os.Open(url)
@@ -137,3 +143,26 @@ func openFile(url string) (io.ReadCloser, error) {
 func IsAndroidChrome() bool {
 	return false
 }
+
+// clipboardGet returns errClipboardUnsupported since there's no headless clipboard
+func clipboardGet() (string, error) {
+	return "", errClipboardUnsupported
+}
+
+// clipboardGetAsync calls the callback with errClipboardUnsupported since there's no headless clipboard
+func clipboardGetAsync(callback func(string, error)) {
+	callback("", errClipboardUnsupported)
+}
+
+// clipboardSet returns errClipboardUnsupported since there's no headless clipboard
+func clipboardSet(text string) error {
+	return errClipboardUnsupported
+}
+
+// Monitors returns nil since there are no headless monitors
+func Monitors() []Monitor {
+	return nil
+}
+
+// setFullscreen does nothing since there's no headless window
+func setFullscreen(fullscreen bool) {}
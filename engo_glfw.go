@@ -10,13 +10,13 @@
 package engo
 
 import (
+	"image"
 	"io"
 	"log"
 	"os"
 	"os/signal"
 	"runtime"
 	"syscall"
-	"time"
 
 	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/klopsch/gl"
@@ -34,6 +34,11 @@ var (
 	cursorHand      *glfw.Cursor
 	cursorHResize   *glfw.Cursor
 	cursorVResize   *glfw.Cursor
+	cursorCustom    *glfw.Cursor
+
+	// lastCursorX and lastCursorY are the last position of the cursor reported by GLFW, which keeps changing while
+	// the cursor is grabbed
+	lastCursorX, lastCursorY float64
 
 	scale = float32(1)
 )
@@ -87,7 +92,7 @@ func CreateWindow(title string, width, height int, fullscreen bool, msaa int) {
 	gameWidth = float32(width)
 	gameHeight = float32(height)
 
-	if opts.HeadlessMode {
+	if opts.HeadlessMode || opts.Offscreen {
 		glfw.WindowHint(glfw.Visible, glfw.False)
 	}
 	if opts.NotResizable {
@@ -98,6 +103,10 @@ func CreateWindow(title string, width, height int, fullscreen bool, msaa int) {
 	glfw.WindowHint(glfw.ContextVersionMinor, 1)
 
 	glfw.WindowHint(glfw.Samples, msaa)
+	glfw.WindowHint(glfw.StencilBits, 8)
+	if opts.SRGB {
+		glfw.WindowHint(glfw.SRGBCapable, glfw.True)
+	}
 
 	if opts.HeadlessMode {
 		Gl = gl.NewContext()
@@ -118,6 +127,9 @@ func CreateWindow(title string, width, height int, fullscreen bool, msaa int) {
 	SetVSync(opts.VSync)
 
 	Gl = gl.NewContext()
+	if msaa > 1 {
+		setMSAA(Gl.GetInteger(Gl.SAMPLES))
+	}
 
 	width, height = Window.GetSize()
 	windowWidth, windowHeight = float32(width), float32(height)
@@ -132,7 +144,7 @@ func CreateWindow(title string, width, height int, fullscreen bool, msaa int) {
 	Window.SetFramebufferSizeCallback(func(Window *glfw.Window, w, h int) {
 		Gl.Viewport(0, 0, w, h)
 		width, height = Window.GetSize()
-		windowWidth, windowHeight = float32(width), float32(width)
+		windowWidth, windowHeight = float32(width), float32(height)
 
 		oldCanvasW, oldCanvasH := canvasWidth, canvasHeight
 
@@ -146,8 +158,14 @@ func CreateWindow(title string, width, height int, fullscreen bool, msaa int) {
 		}
 	})
 
+	lastCursorX, lastCursorY = Window.GetCursorPos()
 	Window.SetCursorPosCallback(func(Window *glfw.Window, x, y float64) {
-		Input.Mouse.X, Input.Mouse.Y = float32(x)/opts.GlobalScale.X, float32(y)/opts.GlobalScale.Y
+		dx, dy := x-lastCursorX, y-lastCursorY
+		lastCursorX, lastCursorY = x, y
+		Input.Mouse.moveCursor(
+			float32(x)/opts.GlobalScale.X, float32(y)/opts.GlobalScale.Y,
+			float32(dx)/opts.GlobalScale.X, float32(dy)/opts.GlobalScale.Y,
+		)
 		if Input.Mouse.Action != Release && Input.Mouse.Action != Press {
 			Input.Mouse.Action = Move
 		}
@@ -155,7 +173,7 @@ func CreateWindow(title string, width, height int, fullscreen bool, msaa int) {
 
 	Window.SetMouseButtonCallback(func(Window *glfw.Window, b glfw.MouseButton, a glfw.Action, m glfw.ModifierKey) {
 		x, y := Window.GetCursorPos()
-		Input.Mouse.X, Input.Mouse.Y = float32(x)/(opts.GlobalScale.X), float32(y)/(opts.GlobalScale.Y)
+		Input.Mouse.moveCursor(float32(x)/(opts.GlobalScale.X), float32(y)/(opts.GlobalScale.Y), 0, 0)
 
 		// this is only valid because we use an internal structure that is
 		// 100% compatible with glfw3.h
@@ -164,14 +182,16 @@ func CreateWindow(title string, width, height int, fullscreen bool, msaa int) {
 
 		if a == glfw.Press {
 			Input.Mouse.Action = Press
+			Input.Mouse.setButton(Input.Mouse.Button, true)
 		} else {
 			Input.Mouse.Action = Release
+			Input.Mouse.setButton(Input.Mouse.Button, false)
 		}
 	})
 
 	Window.SetScrollCallback(func(Window *glfw.Window, xoff, yoff float64) {
-		Input.Mouse.ScrollX = float32(xoff)
-		Input.Mouse.ScrollY = float32(yoff)
+		Input.Mouse.ScrollX += float32(xoff)
+		Input.Mouse.ScrollY += float32(yoff)
 	})
 
 	Window.SetKeyCallback(func(Window *glfw.Window, k glfw.Key, s int, a glfw.Action, m glfw.ModifierKey) {
@@ -204,6 +224,7 @@ func CreateWindow(title string, width, height int, fullscreen bool, msaa int) {
 			gameWidth, gameHeight = float32(widthInt), float32(heightInt)
 		}
 
+		message.GameWidth, message.GameHeight = gameWidth, gameHeight
 		Mailbox.Dispatch(message)
 	})
 
@@ -272,12 +293,13 @@ func RunIteration() {
 	}
 
 	// Then update the world and all Systems
-	currentUpdater.Update(Time.Delta())
+	updateScene(Time.Delta())
 
 	// Lastly, forget keypresses and swap buffers
 	if !opts.HeadlessMode {
 		// reset values to avoid catching the same "signal" twice
 		Input.Mouse.ScrollX, Input.Mouse.ScrollY = 0, 0
+		Input.Mouse.DeltaX, Input.Mouse.DeltaY = 0, 0
 		Input.Mouse.Action = Neutral
 
 		Window.SwapBuffers()
@@ -300,7 +322,7 @@ func runLoop(defaultScene Scene, headless bool) {
 	}()
 
 	RunPreparation(defaultScene)
-	ticker := time.NewTicker(time.Duration(int(time.Second) / opts.FPSLimit))
+	ticker := loopTicker()
 
 	// Start tick, minimize the delta
 	Time.Tick()
@@ -311,7 +333,7 @@ func runLoop(defaultScene Scene, headless bool) {
 			RunIteration()
 		case <-resetLoopTicker:
 			ticker.Stop()
-			ticker = time.NewTicker(time.Duration(int(time.Second) / opts.FPSLimit))
+			ticker = loopTicker()
 		case <-closeGame:
 			ticker.Stop()
 			closeEvent()
@@ -518,3 +540,118 @@ func GetKeyName(k Key) string {
 	}
 	return glfw.GetKeyName(m[k], 0)
 }
+
+// clipboardGet returns the text on the clipboard of the window. It's empty if the clipboard doesn't hold text.
+func clipboardGet() (string, error) {
+	return Window.GetClipboardString(), nil
+}
+
+// clipboardGetAsync calls the callback right away, since the clipboard can be read synchronously
+func clipboardGetAsync(callback func(string, error)) {
+	callback(clipboardGet())
+}
+
+// clipboardSet puts the text on the clipboard of the window
+func clipboardSet(text string) error {
+	Window.SetClipboardString(text)
+	return nil
+}
+
+// windowedBounds are the position and size of the window before it went fullscreen, to restore them afterwards
+var windowedBounds struct {
+	x, y, width, height int
+}
+
+// Monitors returns the monitors connected to the computer, with the video modes they support.
+func Monitors() []Monitor {
+	if opts.HeadlessMode {
+		return nil
+	}
+	primary := glfw.GetPrimaryMonitor()
+	var monitors []Monitor
+	for _, m := range glfw.GetMonitors() {
+		monitor := Monitor{
+			Name:    m.GetName(),
+			Primary: m == primary,
+			Current: glfwVideoMode(m.GetVideoMode()),
+		}
+		for _, mode := range m.GetVideoModes() {
+			monitor.Modes = append(monitor.Modes, glfwVideoMode(mode))
+		}
+		monitors = append(monitors, monitor)
+	}
+	return monitors
+}
+
+func glfwVideoMode(mode *glfw.VidMode) VideoMode {
+	return VideoMode{Width: mode.Width, Height: mode.Height, RefreshRate: mode.RefreshRate}
+}
+
+// setFullscreen moves the window onto the monitor, or back to where it was. GLFW keeps the OpenGL context, and
+// calls the size callbacks as usual.
+func setFullscreen(fullscreen bool) {
+	if !fullscreen {
+		if Window.GetMonitor() == nil {
+			return
+		}
+		b := windowedBounds
+		if b.width == 0 || b.height == 0 {
+			// The game started fullscreen
+			b.width, b.height = opts.Width, opts.Height
+			if mode := Window.GetMonitor().GetVideoMode(); mode != nil {
+				b.x, b.y = (mode.Width-b.width)/2, (mode.Height-b.height)/2
+			}
+		}
+		Window.SetMonitor(nil, b.x, b.y, b.width, b.height, 0)
+		return
+	}
+
+	monitors := glfw.GetMonitors()
+	monitor := glfw.GetPrimaryMonitor()
+	if fullscreenMonitor < len(monitors) {
+		monitor = monitors[fullscreenMonitor]
+	}
+	if monitor == nil {
+		return
+	}
+	if Window.GetMonitor() == nil {
+		b := &windowedBounds
+		b.x, b.y = Window.GetPos()
+		b.width, b.height = Window.GetSize()
+	}
+
+	// A fullscreen window in the current video mode of the monitor is borderless
+	current := monitor.GetVideoMode()
+	mode := VideoMode{Width: current.Width, Height: current.Height, RefreshRate: current.RefreshRate}
+	if opts.FullscreenMode == FullscreenExclusive && fullscreenVideoMode != (VideoMode{}) {
+		mode = fullscreenVideoMode
+	}
+	Window.SetMonitor(monitor, 0, 0, mode.Width, mode.Height, mode.RefreshRate)
+}
+
+// setCustomCursor creates a cursor from the image, and replaces the previous custom cursor with it
+func setCustomCursor(img image.Image, hotX, hotY int) {
+	cursor := glfw.CreateCursor(img, hotX, hotY)
+	Window.SetCursor(cursor)
+	if cursorCustom != nil {
+		cursorCustom.Destroy()
+	}
+	cursorCustom = cursor
+}
+
+// setCursorGrabbed disables the cursor, which GLFW hides and keeps within the window while still reporting its
+// movement. Where it's supported, the movement isn't accelerated, as first-person cameras expect.
+func setCursorGrabbed(grabbed bool) {
+	if grabbed {
+		Window.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+		if glfw.RawMouseMotionSupported() {
+			Window.SetInputMode(glfw.RawMouseMotion, glfw.True)
+		}
+	} else {
+		if glfw.RawMouseMotionSupported() {
+			Window.SetInputMode(glfw.RawMouseMotion, glfw.False)
+		}
+		Window.SetInputMode(glfw.CursorMode, glfw.CursorNormal)
+	}
+	lastCursorX, lastCursorY = Window.GetCursorPos()
+}
@@ -5,8 +5,11 @@ package engo
 
 import (
 	"bytes"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"image"
+	"image/png"
 	"io"
 	"log"
 	"math"
@@ -15,6 +18,7 @@ import (
 	"sync"
 	"syscall/js"
 	"time"
+	"unicode/utf8"
 
 	"github.com/klopsch/gl"
 )
@@ -58,7 +62,15 @@ func CreateWindow(title string, width, height int, fullscreen bool, msaa int) {
 
 	document.Set("title", title)
 
-	Gl, _ = gl.NewContext(canvas, nil)
+	srgbUnsupported()
+
+	attrs := gl.DefaultAttributes()
+	attrs.Antialias = msaa > 1
+	attrs.Stencil = true
+	Gl, _ = gl.NewContext(canvas, attrs)
+	if msaa > 1 {
+		setMSAA(Gl.GetInteger(Gl.SAMPLES))
+	}
 
 	Gl.GetExtension("OES_texture_float")
 
@@ -102,9 +114,12 @@ func CreateWindow(title string, width, height int, fullscreen bool, msaa int) {
 		if k == KeyArrowUp || k == KeyArrowDown || k == KeyArrowLeft || k == KeyArrowRight || k == KeyTab || k == KeyBackspace || k == KeySpace {
 			event.Call("preventDefault")
 		}
+		// Named keys such as "Enter" aren't typed characters, nor are shortcuts such as Ctrl+C
 		char := event.Get("key").String()
-		if len(char) == 1 {
-			Mailbox.Dispatch(TextMessage{[]rune(char)[0]})
+		shortcut := event.Get("ctrlKey").Bool() || event.Get("metaKey").Bool()
+		if !shortcut && utf8.RuneCountInString(char) == 1 {
+			r, _ := utf8.DecodeRuneInString(char)
+			Mailbox.Dispatch(TextMessage{r})
 		}
 
 		checkModifiers(event)
@@ -141,29 +156,86 @@ func CreateWindow(title string, width, height int, fullscreen bool, msaa int) {
 		return nil
 	}))
 
+	// Pasting into the page is the only time browsers hand out the clipboard synchronously
+	document.Call("addEventListener", "paste", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		data := args[0].Get("clipboardData")
+		if !data.IsNull() && !data.IsUndefined() {
+			Clipboard.store(data.Call("getData", "text").String())
+		}
+		return nil
+	}))
+
 	canvas.Call("addEventListener", "mousemove", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		event := args[0]
 		mmX, mmY := event.Get("clientX").Int(), event.Get("clientY").Int()
-		Input.Mouse.X = float32(mmX) / opts.GlobalScale.X
-		Input.Mouse.Y = float32(mmY) / opts.GlobalScale.Y
+		dX, dY := event.Get("movementX").Int(), event.Get("movementY").Int()
+		Input.Mouse.moveCursor(
+			float32(mmX)/opts.GlobalScale.X, float32(mmY)/opts.GlobalScale.Y,
+			float32(dX)/opts.GlobalScale.X, float32(dY)/opts.GlobalScale.Y,
+		)
+		return nil
+	}))
+
+	// The browser releases the pointer lock when the player presses Escape
+	document.Call("addEventListener", "pointerlockchange", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		if document.Get("pointerLockElement").IsNull() {
+			cursorGrabbed = false
+		}
 		return nil
 	}))
 
 	canvas.Call("addEventListener", "mousedown", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		event := args[0]
 		mmX, mmY := event.Get("clientX").Int(), event.Get("clientY").Int()
-		Input.Mouse.X = float32(mmX) / opts.GlobalScale.X
-		Input.Mouse.Y = float32(mmY) / opts.GlobalScale.Y
+		Input.Mouse.moveCursor(float32(mmX)/opts.GlobalScale.X, float32(mmY)/opts.GlobalScale.Y, 0, 0)
+		Input.Mouse.Button = jsMouseButton(event.Get("button").Int())
 		Input.Mouse.Action = Press
+		Input.Mouse.setButton(Input.Mouse.Button, true)
 		return nil
 	}))
 
 	canvas.Call("addEventListener", "mouseup", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		event := args[0]
 		mmX, mmY := event.Get("clientX").Int(), event.Get("clientY").Int()
-		Input.Mouse.X = float32(mmX) / opts.GlobalScale.X
-		Input.Mouse.Y = float32(mmY) / opts.GlobalScale.Y
+		Input.Mouse.moveCursor(float32(mmX)/opts.GlobalScale.X, float32(mmY)/opts.GlobalScale.Y, 0, 0)
+		Input.Mouse.Button = jsMouseButton(event.Get("button").Int())
 		Input.Mouse.Action = Release
+		Input.Mouse.setButton(Input.Mouse.Button, false)
+		return nil
+	}))
+
+	touchHandler := func(phase TouchPhase) js.Func {
+		return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			event := args[0]
+			touches := event.Get("changedTouches")
+			for i := 0; i < touches.Length(); i++ {
+				t := touches.Index(i)
+				x, y := float32(t.Get("clientX").Float()), float32(t.Get("clientY").Float())
+				Input.touchEvent(t.Get("identifier").Int(), x/opts.GlobalScale.X, y/opts.GlobalScale.Y, phase)
+			}
+			// Don't let the browser emulate the mouse as well
+			event.Call("preventDefault")
+			return nil
+		})
+	}
+	canvas.Call("addEventListener", "touchstart", touchHandler(TouchBegan))
+	canvas.Call("addEventListener", "touchmove", touchHandler(TouchMoved))
+	canvas.Call("addEventListener", "touchend", touchHandler(TouchEnded))
+	canvas.Call("addEventListener", "touchcancel", touchHandler(TouchEnded))
+
+	canvas.Call("addEventListener", "wheel", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		event := args[0]
+		// Browsers scroll down with positive values, and report the delta in pixels, lines or pages.
+		scale := float32(1)
+		switch event.Get("deltaMode").Int() {
+		case 0: // pixels
+			scale = 1.0 / 100
+		case 1: // lines
+			scale = 1.0 / 3
+		}
+		Input.Mouse.ScrollX -= float32(event.Get("deltaX").Float()) * scale
+		Input.Mouse.ScrollY -= float32(event.Get("deltaY").Float()) * scale
+		event.Call("preventDefault")
 		return nil
 	}))
 
@@ -295,23 +367,26 @@ func rafPolyfill() {
 	}
 }
 
+// jsMouseButton converts the button of a javascript MouseEvent to a MouseButton.
+func jsMouseButton(button int) MouseButton {
+	switch button {
+	case 1:
+		return MouseButtonMiddle
+	case 2:
+		return MouseButtonRight
+	}
+	return MouseButton(button)
+}
+
 // RunIteration runs one iteration per frame
 func RunIteration() {
 	Time.Tick()
 	Input.update()
 	jsPollKeys()
-	currentUpdater.Update(Time.Delta())
+	updateScene(Time.Delta())
+	Input.Mouse.ScrollX, Input.Mouse.ScrollY = 0, 0
+	Input.Mouse.DeltaX, Input.Mouse.DeltaY = 0, 0
 	Input.Mouse.Action = Neutral
-	// TODO: this may not work, and sky-rocket the FPS
-	//  requestAnimationFrame(func(dt float32) {
-	// 	currentWorld.Update(Time.Delta())
-	// 	keysUpdate()
-	// 	if !headless {
-	// 		// TODO: does this require !headless?
-	// 		Mouse.ScrollX, Mouse.ScrollY = 0, 0
-	// 	}
-	// 	Time.Tick()
-	// })
 }
 
 // jsPollKeys polls the keys collected by the javascript callback
@@ -344,9 +419,8 @@ func checkModifiers(event js.Value) {
 		}
 	}
 }
-func requestAnimationFrame(callback func(float32)) int {
-	//return dom.GetWindow().RequestAnimationFrame(callback)
-	return js.Global().Call("requestAnimationFrame", callback).Int()
+func requestAnimationFrame(callback js.Func) int {
+	return window.Call("requestAnimationFrame", callback).Int()
 }
 
 func cancelAnimationFrame(id int) {
@@ -368,7 +442,11 @@ func RunPreparation() {
 func runLoop(defaultScene Scene, headless bool) {
 	SetScene(defaultScene, false)
 	RunPreparation()
-	ticker := time.NewTicker(time.Duration(int(time.Second) / opts.FPSLimit))
+	if !headless {
+		runAnimationFrames()
+		return
+	}
+	ticker := loopTicker()
 
 	// Start tick, minimize the delta
 	Time.Tick()
@@ -379,7 +457,7 @@ func runLoop(defaultScene Scene, headless bool) {
 			RunIteration()
 		case <-resetLoopTicker:
 			ticker.Stop()
-			ticker = time.NewTicker(time.Duration(int(time.Second) / opts.FPSLimit))
+			ticker = loopTicker()
 		case <-closeGame:
 			ticker.Stop()
 			closeEvent()
@@ -388,6 +466,34 @@ func runLoop(defaultScene Scene, headless bool) {
 	}
 }
 
+// runAnimationFrames runs an iteration every time the browser is about to paint, until the game is closed. The
+// browser paces the frames to the display, so the FPSLimit is ignored.
+func runAnimationFrames() {
+	frames := make(chan struct{}, 1)
+	callback := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		frames <- struct{}{}
+		return nil
+	})
+	defer callback.Release()
+	id := requestAnimationFrame(callback)
+
+	// Start tick, minimize the delta
+	Time.Tick()
+
+	for {
+		select {
+		case <-frames:
+			RunIteration()
+			id = requestAnimationFrame(callback)
+		case <-resetLoopTicker:
+		case <-closeGame:
+			cancelAnimationFrame(id)
+			closeEvent()
+			return
+		}
+	}
+}
+
 func openFile(url string) (io.ReadCloser, error) {
 	if Headless() { // Headless would be node.js
 		return os.Open(url)
@@ -444,11 +550,39 @@ func (n noCloseReadCloser) Read(p []byte) (int, error) {
 
 // SetCursor changes the cursor
 func SetCursor(c Cursor) {
+	cursor := "default"
 	switch c {
-	case CursorNone:
-		document.Get("body").Get("style").Set("cursor", "default")
+	case CursorCrosshair:
+		cursor = "crosshair"
 	case CursorHand:
-		document.Get("body").Get("style").Set("cursor", "hand")
+		cursor = "pointer"
+	case CursorIBeam:
+		cursor = "text"
+	case CursorHResize:
+		cursor = "ew-resize"
+	case CursorVResize:
+		cursor = "ns-resize"
+	}
+	document.Get("body").Get("style").Set("cursor", cursor)
+}
+
+// setCustomCursor sets the cursor of the page to the image, as a PNG data URL
+func setCustomCursor(img image.Image, hotX, hotY int) {
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		log.Println("[WARNING] Unable to create the cursor:", err)
+		return
+	}
+	url := "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+	document.Get("body").Get("style").Set("cursor", fmt.Sprintf("url(%s) %d %d, auto", url, hotX, hotY))
+}
+
+// setCursorGrabbed requests or exits the pointer lock of the canvas
+func setCursorGrabbed(grabbed bool) {
+	if grabbed {
+		canvas.Call("requestPointerLock")
+	} else {
+		document.Call("exitPointerLock")
 	}
 }
 
@@ -482,3 +616,84 @@ func GetKeyName(k Key) string {
 	}
 	return js.Global().Get("String").Call("fromCharCode", k).String()
 }
+
+// clipboardGet returns errClipboardUnsupported, since browsers only allow reading the clipboard asynchronously. The
+// ClipboardManager returns the text last set or pasted into the page instead.
+func clipboardGet() (string, error) {
+	return "", errClipboardUnsupported
+}
+
+// clipboardGetAsync reads the clipboard using the asynchronous clipboard API of the browser. The browser may ask the
+// player for permission first.
+func clipboardGetAsync(callback func(string, error)) {
+	// The clipboard API is only available to secure pages
+	clipboard := js.Global().Get("navigator").Get("clipboard")
+	if clipboard.IsUndefined() {
+		callback("", errClipboardUnsupported)
+		return
+	}
+	var resolve, reject js.Func
+	resolve = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		resolve.Release()
+		reject.Release()
+		text := args[0].String()
+		Clipboard.store(text)
+		callback(text, nil)
+		return nil
+	})
+	reject = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		resolve.Release()
+		reject.Release()
+		callback("", ErrClipboardDenied)
+		return nil
+	})
+	clipboard.Call("readText").Call("then", resolve, reject)
+}
+
+// clipboardSet writes the clipboard using the asynchronous clipboard API of the browser. Since writing finishes
+// after it returns, a denied write is only logged.
+func clipboardSet(text string) error {
+	clipboard := js.Global().Get("navigator").Get("clipboard")
+	if clipboard.IsUndefined() {
+		return errClipboardUnsupported
+	}
+	var resolve, reject js.Func
+	resolve = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		resolve.Release()
+		reject.Release()
+		return nil
+	})
+	reject = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		resolve.Release()
+		reject.Release()
+		log.Println("[WARNING] Unable to write the clipboard:", ErrClipboardDenied)
+		return nil
+	})
+	clipboard.Call("writeText", text).Call("then", resolve, reject)
+	return nil
+}
+
+// Monitors returns the screen the browser is on. Browsers don't tell about other screens, nor which video modes
+// they support.
+func Monitors() []Monitor {
+	screen := window.Get("screen")
+	return []Monitor{{
+		Name:    "screen",
+		Primary: true,
+		Current: VideoMode{Width: screen.Get("width").Int(), Height: screen.Get("height").Int()},
+	}}
+}
+
+// setFullscreen asks the browser to show the page fullscreen, or to leave fullscreen. Browsers only allow it in
+// response to a key press or a click.
+func setFullscreen(fullscreen bool) {
+	if fullscreen {
+		if document.Get("fullscreenElement").IsNull() && !document.Get("documentElement").Get("requestFullscreen").IsUndefined() {
+			document.Get("documentElement").Call("requestFullscreen")
+		}
+		return
+	}
+	if !document.Get("fullscreenElement").IsNull() && !document.Get("exitFullscreen").IsUndefined() {
+		document.Call("exitFullscreen")
+	}
+}
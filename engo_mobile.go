@@ -5,6 +5,7 @@
 package engo
 
 import (
+	"image"
 	"io"
 	"os"
 	"os/signal"
@@ -35,6 +36,7 @@ func CreateWindow(title string, width, height int, fullscreen bool, msaa int) {
 	gameWidth = float32(width)
 	gameHeight = float32(height)
 	msaaPreference = msaa
+	srgbUnsupported()
 }
 
 // WindowSize returns the width and height of the current window
@@ -98,7 +100,7 @@ func runLoop(defaultScene Scene, headless bool) {
 					Gl = gl.NewContext(e.DrawContext)
 					RunPreparation(defaultScene)
 
-					ticker = time.NewTicker(time.Duration(int(time.Second) / opts.FPSLimit))
+					ticker = loopTicker()
 					// Start tick, minimize the delta
 					Time.Tick()
 
@@ -132,7 +134,7 @@ func runLoop(defaultScene Scene, headless bool) {
 					RunIteration()
 				case <-resetLoopTicker:
 					ticker.Stop()
-					ticker = time.NewTicker(time.Duration(int(time.Second) / opts.FPSLimit))
+					ticker = loopTicker()
 				}
 
 				Input.Mouse.Action = Neutral
@@ -142,25 +144,15 @@ func runLoop(defaultScene Scene, headless bool) {
 				// after this one is shown. - FPS is ignored here!
 				a.Send(paint.Event{})
 			case touch.Event:
-				Input.Mouse.X = e.X / opts.GlobalScale.X
-				Input.Mouse.Y = e.Y / opts.GlobalScale.Y
+				x, y := e.X/opts.GlobalScale.X, e.Y/opts.GlobalScale.Y
 				id := int(e.Sequence)
 				switch e.Type {
 				case touch.TypeBegin:
-					Input.Mouse.Action = Press
-					Input.Touches[id] = Point{
-						X: float32(e.X) / opts.GlobalScale.X,
-						Y: float32(e.Y) / opts.GlobalScale.Y,
-					}
+					Input.touchEvent(id, x, y, TouchBegan)
 				case touch.TypeMove:
-					Input.Mouse.Action = Move
-					Input.Touches[id] = Point{
-						X: float32(e.X) / opts.GlobalScale.X,
-						Y: float32(e.Y) / opts.GlobalScale.Y,
-					}
+					Input.touchEvent(id, x, y, TouchMoved)
 				case touch.TypeEnd:
-					Input.Mouse.Action = Release
-					delete(Input.Touches, id)
+					Input.touchEvent(id, x, y, TouchEnded)
 				}
 			}
 		}
@@ -183,7 +175,7 @@ func RunIteration() {
 	}
 
 	// Then update the world and all Systems
-	currentUpdater.Update(Time.Delta())
+	updateScene(Time.Delta())
 }
 
 // SetCursor changes the cursor - not yet implemented
@@ -196,6 +188,12 @@ func SetCursor(Cursor) {
 // Does nothing in mobile since there's no visible cursor to begin with
 func SetCursorVisibility(visible bool) {}
 
+// setCustomCursor does nothing in mobile since there's no visible cursor to begin with
+func setCustomCursor(img image.Image, hotX, hotY int) {}
+
+// setCursorGrabbed does nothing in mobile since there's no cursor to grab
+func setCursorGrabbed(grabbed bool) {}
+
 // SetTitle has no effect on mobile
 func SetTitle(title string) {}
 
@@ -220,3 +218,26 @@ func IsAndroidChrome() bool {
 func GetKeyName(k Key) string {
 	return ""
 }
+
+// clipboardGet returns errClipboardUnsupported - not yet implemented
+func clipboardGet() (string, error) {
+	return "", errClipboardUnsupported
+}
+
+// clipboardGetAsync calls the callback with errClipboardUnsupported - not yet implemented
+func clipboardGetAsync(callback func(string, error)) {
+	callback("", errClipboardUnsupported)
+}
+
+// clipboardSet returns errClipboardUnsupported - not yet implemented
+func clipboardSet(text string) error {
+	return errClipboardUnsupported
+}
+
+// Monitors returns nil - not yet implemented
+func Monitors() []Monitor {
+	return nil
+}
+
+// setFullscreen does nothing since mobile games are always fullscreen
+func setFullscreen(fullscreen bool) {}
@@ -5,6 +5,7 @@ package engo
 
 import (
 	"errors"
+	"image"
 	"io"
 	"runtime"
 	"time"
@@ -33,6 +34,7 @@ func CreateWindow(title string, width, height int, fullscreen bool, msaa int) {
 	gameWidth = float32(width)
 	gameHeight = float32(height)
 	msaaPreference = msaa
+	srgbUnsupported()
 }
 
 // WindowSize returns the width and height of the current window
@@ -128,6 +130,12 @@ func SetCursor(Cursor) {
 // Does nothing in mobile since there's no visible cursor to begin with
 func SetCursorVisibility(visible bool) {}
 
+// setCustomCursor does nothing in mobile since there's no visible cursor to begin with
+func setCustomCursor(img image.Image, hotX, hotY int) {}
+
+// setCursorGrabbed does nothing in mobile since there's no cursor to grab
+func setCursorGrabbed(grabbed bool) {}
+
 // SetTitle has no effect on mobile
 func SetTitle(title string) {}
 
@@ -150,7 +158,7 @@ func mobileDraw(defaultScene Scene) {
 
 	if !initalized {
 		RunPreparation(defaultScene)
-		ticker = time.NewTicker(time.Duration(int(time.Second) / opts.FPSLimit))
+		ticker = loopTicker()
 		initalized = true
 	}
 
@@ -158,14 +166,14 @@ func mobileDraw(defaultScene Scene) {
 	case <-ticker.C:
 	case <-resetLoopTicker:
 		ticker.Stop()
-		ticker = time.NewTicker(time.Duration(int(time.Second) / opts.FPSLimit))
+		ticker = loopTicker()
 	}
 	Time.Tick()
 	if !opts.HeadlessMode {
 		Input.update()
 	}
 	// Then update the world and all Systems
-	currentUpdater.Update(Time.Delta())
+	updateScene(Time.Delta())
 	Input.Mouse.Action = Neutral
 }
 
@@ -188,3 +196,26 @@ func IsAndroidChrome() bool {
 func GetKeyName(k Key) string {
 	return ""
 }
+
+// clipboardGet returns errClipboardUnsupported - not yet implemented
+func clipboardGet() (string, error) {
+	return "", errClipboardUnsupported
+}
+
+// clipboardGetAsync calls the callback with errClipboardUnsupported - not yet implemented
+func clipboardGetAsync(callback func(string, error)) {
+	callback("", errClipboardUnsupported)
+}
+
+// clipboardSet returns errClipboardUnsupported - not yet implemented
+func clipboardSet(text string) error {
+	return errClipboardUnsupported
+}
+
+// Monitors returns nil - not yet implemented
+func Monitors() []Monitor {
+	return nil
+}
+
+// setFullscreen does nothing since mobile games are always fullscreen
+func setFullscreen(fullscreen bool) {}
@@ -11,23 +11,13 @@ import "C"
 
 // TouchEvent handles the touch events sent from ios and puts them in the InputManager
 func TouchEvent(x, y, id, action int) {
-	Input.Mouse.X = float32(x) / opts.GlobalScale.X
-	Input.Mouse.Y = float32(y) / opts.GlobalScale.Y
+	fx, fy := float32(x)/opts.GlobalScale.X, float32(y)/opts.GlobalScale.Y
 	switch action {
-	case C.UITouchPhaseBegan, C.UITouchPhaseStationary:
-		Input.Mouse.Action = Press
-		Input.Touches[id] = Point{
-			X: float32(x) / opts.GlobalScale.X,
-			Y: float32(y) / opts.GlobalScale.Y,
-		}
+	case C.UITouchPhaseBegan:
+		Input.touchEvent(id, fx, fy, TouchBegan)
 	case C.UITouchPhaseEnded, C.UITouchPhaseCancelled:
-		Input.Mouse.Action = Release
-		delete(Input.Touches, id)
+		Input.touchEvent(id, fx, fy, TouchEnded)
 	case C.UITouchPhaseMoved:
-		Input.Mouse.Action = Move
-		Input.Touches[id] = Point{
-			X: float32(x) / opts.GlobalScale.X,
-			Y: float32(y) / opts.GlobalScale.Y,
-		}
+		Input.touchEvent(id, fx, fy, TouchMoved)
 	}
 }
@@ -5,13 +5,15 @@ package engo
 
 import (
 	"bytes"
+	"image"
+	"image/draw"
 	"io"
 	"log"
 	"os"
 	"os/signal"
 	"runtime"
 	"syscall"
-	"time"
+	"unsafe"
 
 	"github.com/klopsch/gl"
 
@@ -28,6 +30,7 @@ var (
 	cursorIBeam     *sdl.Cursor
 	cursorCrosshair *sdl.Cursor
 	cursorHand      *sdl.Cursor
+	cursorCustom    *sdl.Cursor
 	cursorHResize   *sdl.Cursor
 	cursorVResize   *sdl.Cursor
 
@@ -67,25 +70,47 @@ func CreateWindow(title string, width, height int, fullscreen bool, msaa int) {
 
 	sdl.GLSetAttribute(sdl.GL_CONTEXT_MAJOR_VERSION, 2)
 	sdl.GLSetAttribute(sdl.GL_CONTEXT_MINOR_VERSION, 1)
+	// The stencil buffer is used to clip entities to masks
+	sdl.GLSetAttribute(sdl.GL_STENCIL_SIZE, 8)
 
-	if msaa > 0 {
+	if msaa > 1 {
 		sdl.GLSetAttribute(sdl.GL_MULTISAMPLEBUFFERS, 1)
 		sdl.GLSetAttribute(sdl.GL_MULTISAMPLESAMPLES, msaa)
 	}
 
+	if opts.SRGB {
+		sdl.GLSetAttribute(sdl.GL_FRAMEBUFFER_SRGB_CAPABLE, 1)
+	}
+
 	SetVSync(opts.VSync)
 
+	var flags uint32 = sdl.WINDOW_OPENGL
+	if opts.Offscreen {
+		flags |= sdl.WINDOW_HIDDEN
+	}
+
 	Window, err = sdl.CreateWindow(title, sdl.WINDOWPOS_UNDEFINED,
-		sdl.WINDOWPOS_UNDEFINED, int32(width), int32(height), sdl.WINDOW_OPENGL)
+		sdl.WINDOWPOS_UNDEFINED, int32(width), int32(height), flags)
 	fatalErr(err)
 
 	sdlGLContext, err = Window.GLCreateContext()
 	fatalErr(err)
 
 	Gl = gl.NewContext()
+	if msaa > 1 {
+		samples, err := sdl.GLGetAttribute(sdl.GL_MULTISAMPLESAMPLES)
+		if err != nil {
+			samples = 1
+		}
+		setMSAA(samples)
+	}
 
 	if fullscreen {
-		Window.SetFullscreen(sdl.WINDOW_FULLSCREEN)
+		if opts.FullscreenMode == FullscreenBorderless {
+			Window.SetFullscreen(sdl.WINDOW_FULLSCREEN_DESKTOP)
+		} else {
+			Window.SetFullscreen(sdl.WINDOW_FULLSCREEN)
+		}
 	}
 	if opts.NotResizable {
 		Window.SetResizable(false)
@@ -155,10 +180,10 @@ func RunIteration() {
 				}
 
 			case *sdl.MouseWheelEvent:
-				Input.Mouse.ScrollX = float32(e.X)
-				Input.Mouse.ScrollY = float32(e.Y)
+				Input.Mouse.ScrollX += float32(e.X)
+				Input.Mouse.ScrollY += float32(e.Y)
 			case *sdl.MouseButtonEvent:
-				Input.Mouse.X, Input.Mouse.Y = float32(e.X)/(opts.GlobalScale.X), float32(e.Y)/(opts.GlobalScale.Y)
+				Input.Mouse.moveCursor(float32(e.X)/(opts.GlobalScale.X), float32(e.Y)/(opts.GlobalScale.Y), 0, 0)
 
 				switch e.Button {
 				case sdl.BUTTON_LEFT:
@@ -177,16 +202,23 @@ func RunIteration() {
 
 				if e.State == sdl.PRESSED {
 					Input.Mouse.Action = Press
+					Input.Mouse.setButton(Input.Mouse.Button, true)
 				} else {
 					Input.Mouse.Action = Release
+					Input.Mouse.setButton(Input.Mouse.Button, false)
 				}
 			case *sdl.MouseMotionEvent:
-				Input.Mouse.X, Input.Mouse.Y = float32(e.X)/opts.GlobalScale.X, float32(e.Y)/opts.GlobalScale.Y
+				Input.Mouse.moveCursor(
+					float32(e.X)/opts.GlobalScale.X, float32(e.Y)/opts.GlobalScale.Y,
+					float32(e.XRel)/opts.GlobalScale.X, float32(e.YRel)/opts.GlobalScale.Y,
+				)
 				if Input.Mouse.Action != Release && Input.Mouse.Action != Press {
 					Input.Mouse.Action = Move
 				}
 			case *sdl.WindowEvent:
-				if e.Event == sdl.WINDOWEVENT_RESIZED {
+				// Unlike WINDOWEVENT_RESIZED, WINDOWEVENT_SIZE_CHANGED is also sent for changes made by the game,
+				// such as going fullscreen
+				if e.Event == sdl.WINDOWEVENT_SIZE_CHANGED {
 
 					w, h := Window.GetSize()
 					fw, fh := Window.GLGetDrawableSize()
@@ -216,25 +248,34 @@ func RunIteration() {
 						scale = canvasWidth / windowWidth
 					}
 
+					message.GameWidth, message.GameHeight = gameWidth, gameHeight
 					Mailbox.Dispatch(message)
 				}
 			case *sdl.TextInputEvent:
 				n := bytes.IndexByte(e.Text[:], 0)
-				s := string(e.Text[:n])
-				if len(s) == 1 {
-					Mailbox.Dispatch(TextMessage{[]rune(s)[0]})
+				// Input methods commit whole words at once
+				for _, char := range string(e.Text[:n]) {
+					Mailbox.Dispatch(TextMessage{char})
 				}
+			case *sdl.TextEditingEvent:
+				n := bytes.IndexByte(e.Text[:], 0)
+				Mailbox.Dispatch(TextEditMessage{
+					Text:   string(e.Text[:n]),
+					Start:  int(e.Start),
+					Length: int(e.Length),
+				})
 			}
 		}
 	}
 
 	// Then update the world and all Systems
-	currentUpdater.Update(Time.Delta())
+	updateScene(Time.Delta())
 
 	// Lastly, forget keypresses and swap buffers
 	if !opts.HeadlessMode {
 		// reset values to avoid catching the same "signal" twice
 		Input.Mouse.ScrollX, Input.Mouse.ScrollY = 0, 0
+		Input.Mouse.DeltaX, Input.Mouse.DeltaY = 0, 0
 		Input.Mouse.Action = Neutral
 		sdlMojaveFix.UpdateNSGLContext(sdlGLContext)
 		Window.GLSwap()
@@ -257,7 +298,7 @@ func runLoop(defaultScene Scene, headless bool) {
 	}()
 
 	RunPreparation(defaultScene)
-	ticker := time.NewTicker(time.Duration(int(time.Second) / opts.FPSLimit))
+	ticker := loopTicker()
 
 	// Start tick, minimize the delta
 	Time.Tick()
@@ -268,7 +309,7 @@ func runLoop(defaultScene Scene, headless bool) {
 			RunIteration()
 		case <-resetLoopTicker:
 			ticker.Stop()
-			ticker = time.NewTicker(time.Duration(int(time.Second) / opts.FPSLimit))
+			ticker = loopTicker()
 		case <-closeGame:
 			ticker.Stop()
 			closeEvent()
@@ -479,3 +520,124 @@ func GetKeyName(k Key) string {
 	}
 	return sdl.GetKeyName(m[k])
 }
+
+// clipboardGet returns the text on the clipboard. It's empty if the clipboard doesn't hold text.
+func clipboardGet() (string, error) {
+	if !sdl.HasClipboardText() {
+		return "", nil
+	}
+	return sdl.GetClipboardText()
+}
+
+// clipboardGetAsync calls the callback right away, since the clipboard can be read synchronously
+func clipboardGetAsync(callback func(string, error)) {
+	callback(clipboardGet())
+}
+
+// clipboardSet puts the text on the clipboard
+func clipboardSet(text string) error {
+	return sdl.SetClipboardText(text)
+}
+
+// Monitors returns the displays connected to the computer, with the display modes they support.
+func Monitors() []Monitor {
+	if opts.HeadlessMode {
+		return nil
+	}
+	n, err := sdl.GetNumVideoDisplays()
+	if err != nil {
+		return nil
+	}
+	var monitors []Monitor
+	for i := 0; i < n; i++ {
+		name, _ := sdl.GetDisplayName(i)
+		current, _ := sdl.GetDesktopDisplayMode(i)
+		monitor := Monitor{
+			Name: name,
+			// SDL has no primary display, but the first one is at the origin of the desktop
+			Primary: i == 0,
+			Current: sdlVideoMode(current),
+		}
+		modes, _ := sdl.GetNumDisplayModes(i)
+		for j := 0; j < modes; j++ {
+			mode, err := sdl.GetDisplayMode(i, j)
+			if err != nil {
+				continue
+			}
+			monitor.Modes = append(monitor.Modes, sdlVideoMode(mode))
+		}
+		monitors = append(monitors, monitor)
+	}
+	return monitors
+}
+
+func sdlVideoMode(mode sdl.DisplayMode) VideoMode {
+	return VideoMode{Width: int(mode.W), Height: int(mode.H), RefreshRate: int(mode.RefreshRate)}
+}
+
+// setFullscreen moves the window onto the display and makes it fullscreen, or back to a window. SDL keeps the
+// OpenGL context, and sends a WINDOWEVENT_SIZE_CHANGED as usual.
+func setFullscreen(fullscreen bool) {
+	if !fullscreen {
+		Window.SetFullscreen(0)
+		return
+	}
+
+	display := fullscreenMonitor
+	if n, err := sdl.GetNumVideoDisplays(); err != nil || display >= n {
+		display = 0
+	}
+	if Window.GetFlags()&sdl.WINDOW_FULLSCREEN == 0 {
+		if bounds, err := sdl.GetDisplayBounds(display); err == nil {
+			Window.SetPosition(bounds.X, bounds.Y)
+		}
+	}
+
+	if opts.FullscreenMode == FullscreenBorderless {
+		Window.SetFullscreen(sdl.WINDOW_FULLSCREEN_DESKTOP)
+		return
+	}
+	mode, err := sdl.GetDesktopDisplayMode(display)
+	if err != nil {
+		return
+	}
+	if fullscreenVideoMode != (VideoMode{}) {
+		mode.W, mode.H = int32(fullscreenVideoMode.Width), int32(fullscreenVideoMode.Height)
+		mode.RefreshRate = int32(fullscreenVideoMode.RefreshRate)
+	}
+	// The display mode only applies while the window is fullscreen, so leave it first
+	Window.SetFullscreen(0)
+	Window.SetDisplayMode(&mode)
+	Window.SetFullscreen(sdl.WINDOW_FULLSCREEN)
+}
+
+// setCustomCursor creates a color cursor from the image, and replaces the previous custom cursor with it
+func setCustomCursor(img image.Image, hotX, hotY int) {
+	b := img.Bounds()
+	rgba := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+
+	surface, err := sdl.CreateRGBSurfaceWithFormatFrom(unsafe.Pointer(&rgba.Pix[0]), int32(b.Dx()), int32(b.Dy()), 32,
+		int32(rgba.Stride), sdl.PIXELFORMAT_RGBA32)
+	if err != nil {
+		log.Println("[WARNING] Unable to create the cursor:", err)
+		return
+	}
+	defer surface.Free()
+
+	cursor := sdl.CreateColorCursor(surface, int32(hotX), int32(hotY))
+	if cursor == nil {
+		log.Println("[WARNING] Unable to create the cursor:", sdl.GetError())
+		return
+	}
+	sdl.SetCursor(cursor)
+	if cursorCustom != nil {
+		sdl.FreeCursor(cursorCustom)
+	}
+	cursorCustom = cursor
+}
+
+// setCursorGrabbed switches to the relative mouse mode, in which SDL hides the cursor and only reports its movement
+func setCursorGrabbed(grabbed bool) {
+	sdl.SetRelativeMouseMode(grabbed)
+}
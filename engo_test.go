@@ -3,7 +3,9 @@ package engo
 import (
 	"bytes"
 	"log"
+	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -77,6 +79,56 @@ func TestSetScaleOnResize(t *testing.T) {
 	}
 }
 
+func TestSetScaleMode(t *testing.T) {
+	Run(RunOptions{
+		HeadlessMode:  true,
+		NoRun:         true,
+		ScaleOnResize: true,
+	}, &testScene{})
+	if GetScaleMode() != ScaleStretch {
+		t.Error("ScaleOnResize should default the ScaleMode to ScaleStretch.")
+	}
+	SetScaleMode(ScaleLetterbox)
+	if GetScaleMode() != ScaleLetterbox || !ScaleOnResize() {
+		t.Error("SetScaleMode didn't set properly.")
+	}
+	SetScaleMode(ScaleExpand)
+	if ScaleOnResize() {
+		t.Error("ScaleExpand should not scale on resize.")
+	}
+}
+
+func TestGameViewport(t *testing.T) {
+	Run(RunOptions{
+		HeadlessMode: true,
+		NoRun:        true,
+		Width:        800,
+		Height:       600,
+		ScaleMode:    ScaleLetterbox,
+	}, &testScene{})
+	full := AABB{Max: Point{X: 1, Y: 1}}
+	if vp := GameViewport(); vp != full {
+		t.Errorf("same aspect ratio expected=%v ; got=%v", full, vp)
+	}
+
+	canvasWidth, canvasHeight = 1000, 600
+	expected := AABB{Min: Point{X: 0.1}, Max: Point{X: 0.9, Y: 1}}
+	if vp := GameViewport(); !vp.Min.Equal(expected.Min) || !vp.Max.Equal(expected.Max) {
+		t.Errorf("wider window expected=%v ; got=%v", expected, vp)
+	}
+
+	canvasWidth, canvasHeight = 800, 1200
+	expected = AABB{Min: Point{Y: 0.25}, Max: Point{X: 1, Y: 0.75}}
+	if vp := GameViewport(); !vp.Min.Equal(expected.Min) || !vp.Max.Equal(expected.Max) {
+		t.Errorf("taller window expected=%v ; got=%v", expected, vp)
+	}
+
+	SetScaleMode(ScaleStretch)
+	if vp := GameViewport(); vp != full {
+		t.Errorf("stretched expected=%v ; got=%v", full, vp)
+	}
+}
+
 func TestSetOverrideCloseAction(t *testing.T) {
 	Run(RunOptions{
 		HeadlessMode: true,
@@ -161,6 +213,38 @@ func TestRunNegativeMSAAPanic(t *testing.T) {
 	}, &testScene{})
 }
 
+func TestRunMSAA(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	for _, c := range []struct {
+		msaa, expected int
+		warning        bool
+	}{
+		{0, 1, false},
+		{1, 1, false},
+		{2, 2, false},
+		{3, 2, true},
+		{4, 4, false},
+		{8, 8, false},
+		{16, 8, true},
+	} {
+		buf.Reset()
+		Run(RunOptions{
+			NoRun:        true,
+			HeadlessMode: true,
+			MSAA:         c.msaa,
+		}, &testScene{})
+		if opts.MSAA != c.expected || MSAA() != c.expected {
+			t.Errorf("MSAA of %d was not changed to %d, got: %d", c.msaa, c.expected, opts.MSAA)
+		}
+		if warned := strings.Contains(buf.String(), "[WARNING] MSAA"); warned != c.warning {
+			t.Errorf("MSAA of %d logged a warning: %v, wanted: %v", c.msaa, warned, c.warning)
+		}
+	}
+}
+
 func TestRunStandardInputs(t *testing.T) {
 	var buf bytes.Buffer
 	log.SetOutput(&buf)
@@ -297,6 +381,22 @@ func TestSceneSwitching(t *testing.T) {
 	}
 }
 
+func TestSceneSwitchMessage(t *testing.T) {
+	Run(RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &testScene{})
+	var to Scene
+	Mailbox.Listen("SceneSwitchMessage", func(msg Message) {
+		to = msg.(SceneSwitchMessage).To
+	})
+	next := &testScene2{}
+	SetScene(next, false)
+	if to != next {
+		t.Errorf("SceneSwitchMessage should be dispatched to the scene being left. was: %v, expected: %v", to, next)
+	}
+}
+
 func TestUtils(t *testing.T) {
 	var buf bytes.Buffer
 	log.SetOutput(&buf)
@@ -349,3 +449,190 @@ func TestGetApplicationVersion(t *testing.T) {
 		t.Errorf("Application version did not match. Wanted: %v.%v.%v \n Got: %v.%v.%v\n", 1, 2, 3, ver[0], ver[1], ver[2])
 	}
 }
+
+type preloadScene struct {
+	preloads, setups, shows, unloads int
+	mailbox                          *MessageManager
+}
+
+func (p *preloadScene) Preload() { p.preloads++ }
+
+func (p *preloadScene) Setup(Updater) {
+	p.setups++
+	p.mailbox = Mailbox
+}
+
+func (*preloadScene) Type() string { return "preloadScene" }
+
+func (p *preloadScene) Show() { p.shows++ }
+
+func (p *preloadScene) Unload() { p.unloads++ }
+
+func TestPreloadScene(t *testing.T) {
+	Run(RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &testScene{})
+	mailbox := Mailbox
+
+	s := &preloadScene{}
+	PreloadScene(s)
+	if s.preloads != 1 || s.setups != 1 {
+		t.Errorf("PreloadScene did not set up the scene. preloads: %v, setups: %v", s.preloads, s.setups)
+	}
+	if CurrentScene().Type() != "testScene" || Mailbox != mailbox {
+		t.Error("PreloadScene should not switch scenes")
+	}
+	if s.mailbox == mailbox {
+		t.Error("The preloaded scene should be set up with its own Mailbox")
+	}
+	if !ScenePreloaded("preloadScene") {
+		t.Error("ScenePreloaded should return true after PreloadScene")
+	}
+
+	PreloadScene(s)
+	SetScene(s, false)
+	if s.preloads != 1 || s.setups != 1 || s.shows != 0 {
+		t.Errorf("Switching to a preloaded scene should not set it up again. preloads: %v, setups: %v, shows: %v", s.preloads, s.setups, s.shows)
+	}
+	if Mailbox != s.mailbox {
+		t.Error("Switching to a preloaded scene should use its Mailbox")
+	}
+	if err := FreeScene("preloadScene"); err == nil {
+		t.Error("No error when freeing the current scene")
+	}
+
+	SetSceneByName("testScene", false)
+	if err := FreeScene("preloadScene"); err != nil {
+		t.Errorf("Error when freeing a scene: %v", err)
+	}
+	if s.unloads != 1 || ScenePreloaded("preloadScene") {
+		t.Error("FreeScene did not free the scene")
+	}
+	SetScene(s, false)
+	if s.preloads != 2 || s.setups != 2 {
+		t.Errorf("A freed scene should be set up again. preloads: %v, setups: %v", s.preloads, s.setups)
+	}
+	SetSceneByName("testScene", false)
+
+	// Forcing a new world replaces the preloaded one, so the scene is shown normally afterwards
+	if err := FreeScene("preloadScene"); err != nil {
+		t.Errorf("Error when freeing a scene: %v", err)
+	}
+	PreloadScene(s)
+	SetScene(s, true)
+	SetSceneByName("testScene", false)
+	shows := s.shows
+	SetScene(s, false)
+	if s.shows != shows+1 {
+		t.Errorf("A scene preloaded before forcing a new world should be shown again. shows: %v", s.shows-shows)
+	}
+	SetSceneByName("testScene", false)
+}
+
+type testDeltaScene struct {
+	frames int
+	deltas []float32
+}
+
+func (*testDeltaScene) Preload() {}
+
+func (t *testDeltaScene) Setup(u Updater) {
+	w, _ := u.(*ecs.World)
+	w.AddSystem(&testDeltaSystem{scene: t})
+}
+
+func (*testDeltaScene) Type() string { return "testDeltaScene" }
+
+type testDeltaSystem struct {
+	scene *testDeltaScene
+}
+
+func (*testDeltaSystem) Remove(ecs.BasicEntity) {}
+
+func (t *testDeltaSystem) Update(dt float32) {
+	t.scene.deltas = append(t.scene.deltas, dt)
+	if len(t.scene.deltas) >= t.scene.frames {
+		Exit()
+	}
+}
+
+func TestFPSLimitDelta(t *testing.T) {
+	defer func() {
+		newTicker = time.NewTicker
+		theTimer = realTime{}
+	}()
+	data := []struct {
+		limit  int
+		period time.Duration
+	}{
+		{20, 50 * time.Millisecond},
+		{60, time.Second / 60},
+		{NoFPSLimit, time.Nanosecond},
+	}
+	for _, d := range data {
+		// Earlier tests already exited the loop, and may have left a reset of the ticker behind
+		closeGame = make(chan struct{})
+		closeGameOnce = sync.Once{}
+		select {
+		case <-resetLoopTicker:
+		default:
+		}
+
+		// The loop is ticked by hand, with the clock moved forward by the period of its ticker each frame
+		ticks := make(chan time.Time)
+		periods := make(chan time.Duration, 1)
+		newTicker = func(period time.Duration) *time.Ticker {
+			periods <- period
+			ticker := time.NewTicker(time.Hour)
+			ticker.C = ticks
+			return ticker
+		}
+		now := int64(0)
+		theTimer = testTime{now}
+
+		scene := &testDeltaScene{frames: 3}
+		testChan := make(chan struct{})
+		go func() {
+			Run(RunOptions{
+				HeadlessMode:        true,
+				OverrideCloseAction: true,
+				FPSLimit:            d.limit,
+			}, scene)
+			testChan <- struct{}{}
+		}()
+		period := <-periods
+		if period != d.period {
+			t.Errorf("The loop should tick every %v with an FPSLimit of %v, got: %v", d.period, d.limit, period)
+		}
+		for i := 0; i < scene.frames; i++ {
+			now += int64(period)
+			theTimer = testTime{now}
+			ticks <- time.Time{}
+		}
+		<-testChan
+
+		for i, dt := range scene.deltas {
+			if want := float32(period.Seconds()); dt != want {
+				t.Errorf("dt of frame %v with an FPSLimit of %v should be %v, got %v", i, d.limit, want, dt)
+			}
+		}
+	}
+}
+
+func TestSetFPSLimitNoLimit(t *testing.T) {
+	Run(RunOptions{
+		HeadlessMode: true,
+		NoRun:        true,
+	}, &testScene{})
+	select {
+	case <-resetLoopTicker:
+	default:
+	}
+	if err := SetFPSLimit(NoFPSLimit); err != nil {
+		t.Errorf("SetFPSLimit should accept NoFPSLimit, got: %v", err)
+	}
+	if opts.FPSLimit != NoFPSLimit {
+		t.Error("SetFPSLimit didn't set NoFPSLimit properly.")
+	}
+}
@@ -0,0 +1,59 @@
+package engo
+
+import "github.com/klopsch/ecs"
+
+var (
+	fixedAccumulator float32
+	fixedAlpha       float32
+)
+
+// FixedUpdater is an optional interface a System can implement, to be updated
+// with a fixed timestep set by RunOptions.FixedStep. This is useful for
+// physics and other logic that has to be deterministic. Update is still called
+// once every frame as well.
+type FixedUpdater interface {
+	// FixedUpdate is called zero or more times per frame, always with the
+	// FixedStep in seconds as dt.
+	FixedUpdate(dt float32)
+}
+
+// FixedStepAlpha returns how far the current frame is between the previous and
+// the next fixed step, from 0 to 1. Systems drawing the results of fixed steps
+// can use it to interpolate between the last two steps, so movement looks
+// smooth even when the frame rate differs from the fixed step.
+func FixedStepAlpha() float32 {
+	return fixedAlpha
+}
+
+// fixedUpdate calls FixedUpdate on the systems of the current Scene as many
+// times as the fixed step fits in the time that passed.
+func fixedUpdate(dt float32) {
+	if opts.FixedStep <= 0 {
+		return
+	}
+	step := float32(opts.FixedStep.Seconds())
+
+	fixedAccumulator += dt
+	for n := 0; fixedAccumulator >= step; n++ {
+		if n == opts.MaxFixedSteps {
+			// Drop the time the game can't keep up with
+			fixedAccumulator -= float32(int(fixedAccumulator/step)) * step
+			break
+		}
+		fixedAccumulator -= step
+
+		w, ok := currentUpdater.(*ecs.World)
+		if !ok {
+			if f, ok := currentUpdater.(FixedUpdater); ok && shouldUpdate(currentUpdater) {
+				f.FixedUpdate(step)
+			}
+			continue
+		}
+		for _, system := range w.Systems() {
+			if f, ok := system.(FixedUpdater); ok && shouldUpdate(system) {
+				f.FixedUpdate(step)
+			}
+		}
+	}
+	fixedAlpha = fixedAccumulator / step
+}
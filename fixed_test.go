@@ -0,0 +1,62 @@
+package engo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/klopsch/ecs"
+)
+
+type fixedSystem struct {
+	steps, updates int
+	dt             float32
+}
+
+func (f *fixedSystem) Update(float32) { f.updates++ }
+
+func (f *fixedSystem) FixedUpdate(dt float32) {
+	f.steps++
+	f.dt = dt
+}
+
+func (*fixedSystem) Remove(ecs.BasicEntity) {}
+
+func TestFixedUpdate(t *testing.T) {
+	w := &ecs.World{}
+	sys := &fixedSystem{}
+	w.AddSystem(sys)
+	currentUpdater = w
+
+	oldOpts := opts
+	defer func() { opts = oldOpts }()
+	opts.FixedStep = 250 * time.Millisecond
+	opts.MaxFixedSteps = 3
+	fixedAccumulator = 0
+
+	for _, c := range []struct {
+		dt    float32
+		steps int
+		alpha float32
+	}{
+		{0.5, 2, 0},
+		{0.125, 2, 0.5},
+		{0.125, 3, 0},
+		// Only MaxFixedSteps are done, the rest is dropped
+		{2, 6, 0},
+		{0.3, 7, 0.2},
+	} {
+		updateScene(c.dt)
+		if sys.steps != c.steps {
+			t.Errorf("Steps after dt=%f expected=%d ; got=%d", c.dt, c.steps, sys.steps)
+		}
+		if !FloatEqualThreshold(FixedStepAlpha(), c.alpha, 1e-4) {
+			t.Errorf("Alpha after dt=%f expected=%f ; got=%f", c.dt, c.alpha, FixedStepAlpha())
+		}
+	}
+	if sys.dt != 0.25 {
+		t.Errorf("FixedUpdate dt expected=%f ; got=%f", 0.25, sys.dt)
+	}
+	if sys.updates != 5 {
+		t.Errorf("Update should be called once every frame, expected=%d ; got=%d", 5, sys.updates)
+	}
+}
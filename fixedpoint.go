@@ -0,0 +1,283 @@
+package engo
+
+import (
+	"math"
+	"math/bits"
+	"strconv"
+)
+
+// fixedShift is the number of fractional bits of a Fixed.
+const fixedShift = 16
+
+const (
+	// FixedOne is 1 as a Fixed.
+	FixedOne Fixed = 1 << fixedShift
+	// FixedPi is π as a Fixed.
+	FixedPi Fixed = 205887
+	// FixedMax is the largest Fixed.
+	FixedMax Fixed = math.MaxInt64
+	// FixedMin is the smallest Fixed.
+	FixedMin Fixed = math.MinInt64
+
+	fixedHalfPi Fixed = 102944
+	fixedTwoPi  Fixed = 411775
+)
+
+// Fixed is a signed fixed-point number, with 48 bits for the integer part and 16 bits for the fractional part. Unlike
+// float32, the results of its operations only use integer math, and are the same on every platform and with every
+// compiler, which makes it suited for lockstep multiplayer, where every peer has to simulate the game in exactly the
+// same way. Add and subtract them with + and -, and compare them with < and >.
+//
+// Converting floats to Fixed is deterministic as well, but the floats themselves may not be, so games should only
+// convert constants and loaded values, and do all their simulation math with Fixed.
+type Fixed int64
+
+// FixedInt returns i as a Fixed.
+func FixedInt(i int) Fixed {
+	return Fixed(i) << fixedShift
+}
+
+// FixedFloat returns f rounded to the nearest Fixed.
+func FixedFloat(f float32) Fixed {
+	return Fixed(math.Round(float64(f) * float64(FixedOne)))
+}
+
+// FixedRatio returns a / b as a Fixed, such as FixedRatio(1, 3) for a third, without going through floats.
+func FixedRatio(a, b int) Fixed {
+	return FixedInt(a).Div(FixedInt(b))
+}
+
+// Float32 returns f as a float32, for drawing the results of the simulation.
+func (f Fixed) Float32() float32 {
+	return float32(float64(f) / float64(FixedOne))
+}
+
+// Int returns the integer part of f, rounded down.
+func (f Fixed) Int() int {
+	return int(f >> fixedShift)
+}
+
+// String returns f as a decimal number.
+func (f Fixed) String() string {
+	return strconv.FormatFloat(float64(f)/float64(FixedOne), 'f', -1, 64)
+}
+
+// Abs returns the absolute value of f.
+func (f Fixed) Abs() Fixed {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
+
+// Mul returns f * g, rounded toward zero.
+func (f Fixed) Mul(g Fixed) Fixed {
+	hi, lo := bits.Mul64(uint64(f.Abs()), uint64(g.Abs()))
+	r := Fixed(hi<<(64-fixedShift) | lo>>fixedShift)
+	if (f < 0) != (g < 0) {
+		return -r
+	}
+	return r
+}
+
+// Div returns f / g, rounded toward zero. Results too large for a Fixed are clamped to FixedMax and FixedMin, and
+// dividing by zero panics like integer division does.
+func (f Fixed) Div(g Fixed) Fixed {
+	a, b := uint64(f.Abs()), uint64(g.Abs())
+	neg := (f < 0) != (g < 0)
+	hi, lo := a>>(64-fixedShift), a<<fixedShift
+	if b != 0 && hi >= b {
+		if neg {
+			return FixedMin
+		}
+		return FixedMax
+	}
+	q, _ := bits.Div64(hi, lo, b)
+	if neg {
+		return -Fixed(q)
+	}
+	return Fixed(q)
+}
+
+// Sqrt returns the square root of f, rounded down. The square root of negative numbers is 0.
+func (f Fixed) Sqrt() Fixed {
+	if f <= 0 {
+		return 0
+	}
+	if f < 1<<(63-fixedShift) {
+		return Fixed(isqrt(uint64(f) << fixedShift))
+	}
+	// f << fixedShift would overflow, lose half the fractional bits instead
+	return Fixed(isqrt(uint64(f)) << (fixedShift / 2))
+}
+
+// isqrt returns the integer square root of n, rounded down.
+func isqrt(n uint64) uint64 {
+	var r uint64
+	bit := uint64(1) << 62
+	for bit > n {
+		bit >>= 2
+	}
+	for bit != 0 {
+		if n >= r+bit {
+			n -= r + bit
+			r = r>>1 + bit
+		} else {
+			r >>= 1
+		}
+		bit >>= 2
+	}
+	return r
+}
+
+// FixedSin returns the sine of the angle in radians.
+func FixedSin(angle Fixed) Fixed {
+	// Bring the angle within [-π/2, π/2], where the polynomial is accurate
+	x := angle % fixedTwoPi
+	if x > FixedPi {
+		x -= fixedTwoPi
+	} else if x < -FixedPi {
+		x += fixedTwoPi
+	}
+	if x > fixedHalfPi {
+		x = FixedPi - x
+	} else if x < -fixedHalfPi {
+		x = -FixedPi - x
+	}
+
+	// Taylor series up to x⁹: x(1 - x²/6(1 - x²/20(1 - x²/42(1 - x²/72))))
+	x2 := x.Mul(x)
+	r := FixedOne - x2/72
+	r = FixedOne - x2.Mul(r)/42
+	r = FixedOne - x2.Mul(r)/20
+	r = FixedOne - x2.Mul(r)/6
+	return x.Mul(r)
+}
+
+// FixedCos returns the cosine of the angle in radians.
+func FixedCos(angle Fixed) Fixed {
+	return FixedSin(angle%fixedTwoPi + fixedHalfPi)
+}
+
+// FixedDegrees returns the angle in degrees, such as the Rotation of a SpaceComponent, in radians.
+func FixedDegrees(degrees Fixed) Fixed {
+	return degrees.Mul(FixedPi) / 180
+}
+
+// FixedPoint is a Point made of Fixed numbers, for deterministic movement.
+type FixedPoint struct {
+	X, Y Fixed
+}
+
+// FixedPointFrom returns p as a FixedPoint.
+func FixedPointFrom(p Point) FixedPoint {
+	return FixedPoint{X: FixedFloat(p.X), Y: FixedFloat(p.Y)}
+}
+
+// Point returns p as a Point, for drawing the results of the simulation.
+func (p FixedPoint) Point() Point {
+	return Point{X: p.X.Float32(), Y: p.Y.Float32()}
+}
+
+// Set sets the coordinates of p to x and y
+func (p *FixedPoint) Set(x, y Fixed) *FixedPoint {
+	p.X = x
+	p.Y = y
+	return p
+}
+
+// Add sets the components of p to the pointwise summation of p + p2
+func (p *FixedPoint) Add(p2 FixedPoint) *FixedPoint {
+	p.X += p2.X
+	p.Y += p2.Y
+	return p
+}
+
+// Subtract sets the components of p to the pointwise difference of p - p2
+func (p *FixedPoint) Subtract(p2 FixedPoint) *FixedPoint {
+	p.X -= p2.X
+	p.Y -= p2.Y
+	return p
+}
+
+// MultiplyScalar multiplies each component of p by s
+func (p *FixedPoint) MultiplyScalar(s Fixed) *FixedPoint {
+	p.X = p.X.Mul(s)
+	p.Y = p.Y.Mul(s)
+	return p
+}
+
+// Equal indicates whether two points have the same value, which is exact for FixedPoints
+func (p *FixedPoint) Equal(p2 FixedPoint) bool {
+	return p.X == p2.X && p.Y == p2.Y
+}
+
+// Length returns the length of the vector from the origin to p
+func (p *FixedPoint) Length() Fixed {
+	return FixedDotProduct(*p, *p).Sqrt()
+}
+
+// PointDistance returns the euclidean distance between p and p2
+func (p *FixedPoint) PointDistance(p2 FixedPoint) Fixed {
+	d := FixedPoint{X: p.X - p2.X, Y: p.Y - p2.Y}
+	return d.Length()
+}
+
+// Normalize returns the unit vector from p, and its magnitude.
+// if you try to normalize the null vector, the return value will be null values
+func (p *FixedPoint) Normalize() (FixedPoint, Fixed) {
+	length := p.Length()
+	if length == 0 {
+		return FixedPoint{}, 0
+	}
+	return FixedPoint{X: p.X.Div(length), Y: p.Y.Div(length)}, length
+}
+
+// Rotate rotates p clockwise around the origin by the angle in radians, like the Rotation of a SpaceComponent
+func (p *FixedPoint) Rotate(angle Fixed) *FixedPoint {
+	sin, cos := FixedSin(angle), FixedCos(angle)
+	p.X, p.Y = p.X.Mul(cos)-p.Y.Mul(sin), p.X.Mul(sin)+p.Y.Mul(cos)
+	return p
+}
+
+// FixedDotProduct returns the dot product between two FixedPoints
+func FixedDotProduct(this, that FixedPoint) Fixed {
+	return this.X.Mul(that.X) + this.Y.Mul(that.Y)
+}
+
+// FixedCrossProduct returns the 2 dimensional cross product of two FixedPoints
+func FixedCrossProduct(this, that FixedPoint) Fixed {
+	return this.X.Mul(that.Y) - this.Y.Mul(that.X)
+}
+
+// FixedAABB is an AABB made of FixedPoints, for deterministic collision detection.
+type FixedAABB struct {
+	Min, Max FixedPoint
+}
+
+// Overlaps returns whether the boxes overlap, excluding touching edges, and the minimum translation to move a by so
+// they stop overlapping, like the solid collisions of the CollisionSystem.
+func (a FixedAABB) Overlaps(b FixedAABB) (bool, FixedPoint) {
+	if a.Max.X <= b.Min.X || b.Max.X <= a.Min.X || a.Max.Y <= b.Min.Y || b.Max.Y <= a.Min.Y {
+		return false, FixedPoint{}
+	}
+	left, right := b.Min.X-a.Max.X, b.Max.X-a.Min.X
+	up, down := b.Min.Y-a.Max.Y, b.Max.Y-a.Min.Y
+	var mtd FixedPoint
+	if -left < right {
+		mtd.X = left
+	} else {
+		mtd.X = right
+	}
+	if -up < down {
+		mtd.Y = up
+	} else {
+		mtd.Y = down
+	}
+	if mtd.X.Abs() < mtd.Y.Abs() {
+		mtd.Y = 0
+	} else {
+		mtd.X = 0
+	}
+	return true, mtd
+}
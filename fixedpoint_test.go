@@ -0,0 +1,91 @@
+package engo
+
+import "testing"
+
+// The expected values are raw Fixed values, which have to be exactly the same on every platform.
+
+func TestFixedArithmetic(t *testing.T) {
+	data := []struct {
+		name     string
+		got      Fixed
+		expected Fixed
+	}{
+		{"FixedInt(3)", FixedInt(3), 196608},
+		{"FixedFloat(1.5)", FixedFloat(1.5), 98304},
+		{"FixedRatio(1, 3)", FixedRatio(1, 3), 21845},
+		{"1.5 * -2.25", FixedFloat(1.5).Mul(FixedFloat(-2.25)), -221184},
+		{"7 / -2", FixedInt(7).Div(FixedInt(-2)), -229376},
+		{"sqrt(2)", FixedInt(2).Sqrt(), 92681},
+		{"sqrt(2^40)", FixedInt(1 << 40).Sqrt(), 68719476736},
+		{"sqrt(-1)", FixedInt(-1).Sqrt(), 0},
+		{"2^46 / 0.25", FixedInt(1 << 46).Div(FixedRatio(1, 4)), FixedMax},
+	}
+	for _, d := range data {
+		if d.got != d.expected {
+			t.Errorf("%s: expected=%d ; got=%d", d.name, d.expected, d.got)
+		}
+	}
+}
+
+func TestFixedTrigonometry(t *testing.T) {
+	data := []struct {
+		angle    Fixed
+		sin, cos Fixed
+	}{
+		{0, 0, 65536},
+		{FixedPi / 6, 32767, 56755},
+		{FixedPi / 4, 46340, 46341},
+		{FixedPi / 2, 65536, 0},
+		{FixedPi, 0, -65536},
+		{-FixedPi / 3, -56755, 32768},
+		{FixedInt(10), -35652, -54989},
+		{FixedInt(-100), 33187, 56512},
+	}
+	for _, d := range data {
+		if sin := FixedSin(d.angle); sin != d.sin {
+			t.Errorf("sin(%v): expected=%d ; got=%d", d.angle, d.sin, sin)
+		}
+		if cos := FixedCos(d.angle); cos != d.cos {
+			t.Errorf("cos(%v): expected=%d ; got=%d", d.angle, d.cos, cos)
+		}
+	}
+}
+
+func TestFixedPoint(t *testing.T) {
+	p := FixedPoint{FixedInt(3), FixedInt(4)}
+	if l := p.Length(); l != FixedInt(5) {
+		t.Errorf("Length: expected=%v ; got=%v", FixedInt(5), l)
+	}
+	n, l := p.Normalize()
+	if n.X != 39321 || n.Y != 52428 || l != FixedInt(5) {
+		t.Errorf("Normalize: expected={39321 52428} 327680 ; got=%d %d", n, l)
+	}
+
+	q := FixedPoint{FixedOne, 0}
+	q.Rotate(FixedPi / 2)
+	if !q.Equal(FixedPoint{0, FixedOne}) {
+		t.Errorf("Rotate: expected={0 65536} ; got=%d", q)
+	}
+
+	p.Add(FixedPoint{FixedOne, FixedOne}).MultiplyScalar(FixedRatio(1, 2))
+	if !p.Equal(FixedPoint{FixedInt(2), FixedFloat(2.5)}) {
+		t.Errorf("Add and MultiplyScalar: expected={2 2.5} ; got=%v", p)
+	}
+	if pt := p.Point(); pt != (Point{X: 2, Y: 2.5}) {
+		t.Errorf("Point: expected={2 2.5} ; got=%v", pt)
+	}
+}
+
+func TestFixedAABBOverlaps(t *testing.T) {
+	a := FixedAABB{Max: FixedPoint{FixedInt(10), FixedInt(10)}}
+	b := FixedAABB{Min: FixedPoint{FixedInt(8), FixedInt(2)}, Max: FixedPoint{FixedInt(20), FixedInt(20)}}
+	overlaps, mtd := a.Overlaps(b)
+	if !overlaps || !mtd.Equal(FixedPoint{X: FixedInt(-2)}) {
+		t.Errorf("expected=true {-2 0} ; got=%v %v", overlaps, mtd)
+	}
+
+	b.Min.X = FixedInt(10)
+	if overlaps, _ := a.Overlaps(b); overlaps {
+		t.Errorf("touching edges should not overlap")
+	}
+}
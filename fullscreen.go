@@ -0,0 +1,104 @@
+package engo
+
+import "fmt"
+
+// FullscreenMode is how the game fills the screen while it's fullscreen.
+type FullscreenMode uint8
+
+const (
+	// FullscreenExclusive gives the game the monitor, switching it to the VideoMode set with SetVideoMode. Switching
+	// to and from other windows may be slow, as the monitor switches back and forth.
+	FullscreenExclusive FullscreenMode = iota
+	// FullscreenBorderless covers the monitor with a borderless window, keeping the resolution of the monitor.
+	FullscreenBorderless
+)
+
+// VideoMode is a resolution and refresh rate a monitor supports.
+type VideoMode struct {
+	Width, Height int
+	// RefreshRate is in Hz
+	RefreshRate int
+}
+
+// String returns the VideoMode as it's commonly shown in settings menus, such as "1920x1080 @ 60Hz".
+func (m VideoMode) String() string {
+	return fmt.Sprintf("%dx%d @ %dHz", m.Width, m.Height, m.RefreshRate)
+}
+
+// Monitor is a monitor connected to the computer, as returned by Monitors.
+type Monitor struct {
+	Name string
+	// Primary is whether it's the primary monitor of the computer
+	Primary bool
+	// Current is the VideoMode the monitor is in
+	Current VideoMode
+	// Modes are the VideoModes the monitor supports, for FullscreenExclusive
+	Modes []VideoMode
+}
+
+var (
+	// fullscreenMonitor is the index, in Monitors, of the monitor the game goes fullscreen on
+	fullscreenMonitor int
+	// fullscreenVideoMode is the VideoMode used by FullscreenExclusive. Zero means the current one of the monitor.
+	fullscreenVideoMode VideoMode
+)
+
+// IsFullscreen returns whether the game is fullscreen.
+func IsFullscreen() bool {
+	return opts.Fullscreen
+}
+
+// SetFullscreen switches the game to or from fullscreen, on the monitor and in the FullscreenMode and VideoMode
+// set with SetFullscreenMode and SetVideoMode. The window is resized accordingly, which dispatches a
+// WindowResizeMessage like any other resize. On the web, browsers only allow going fullscreen in response to a key
+// press or a click, and it's borderless regardless of the FullscreenMode. It does nothing on mobile and while
+// headless.
+func SetFullscreen(fullscreen bool) {
+	opts.Fullscreen = fullscreen
+	if !opts.HeadlessMode {
+		setFullscreen(fullscreen)
+	}
+}
+
+// GetFullscreenMode returns how the game fills the screen while it's fullscreen.
+func GetFullscreenMode() FullscreenMode {
+	return opts.FullscreenMode
+}
+
+// SetFullscreenMode can be used to change the value in the given `RunOpts` after already having called `engo.Run`.
+// If the game is fullscreen, it switches to the new mode right away.
+func SetFullscreenMode(mode FullscreenMode) {
+	opts.FullscreenMode = mode
+	if opts.Fullscreen {
+		SetFullscreen(true)
+	}
+}
+
+// SetVideoMode sets the monitor the game goes fullscreen on, by its index in Monitors, along with the VideoMode it
+// uses with FullscreenExclusive. The zero VideoMode keeps the current one of the monitor. If the game is
+// fullscreen, it switches right away. It returns an error if there's no such monitor, or if it doesn't support the
+// VideoMode.
+func SetVideoMode(monitor int, mode VideoMode) error {
+	monitors := Monitors()
+	if monitor < 0 || monitor >= len(monitors) {
+		return fmt.Errorf("monitor %d not found, there are %d monitors", monitor, len(monitors))
+	}
+	if mode != (VideoMode{}) {
+		supported := false
+		for _, m := range monitors[monitor].Modes {
+			if m == mode {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return fmt.Errorf("monitor %q doesn't support the video mode %v", monitors[monitor].Name, mode)
+		}
+	}
+
+	fullscreenMonitor, fullscreenVideoMode = monitor, mode
+	if opts.Fullscreen {
+		SetFullscreen(true)
+	}
+	return nil
+}
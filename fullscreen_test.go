@@ -0,0 +1,41 @@
+package engo
+
+import "testing"
+
+// Test switching to and from fullscreen while headless, where there are no monitors.
+func TestFullscreen(t *testing.T) {
+	Run(RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &testScene{})
+
+	if IsFullscreen() {
+		t.Error("The game should not start fullscreen")
+	}
+	SetFullscreen(true)
+	if !IsFullscreen() {
+		t.Error("SetFullscreen(true) should make the game fullscreen")
+	}
+	SetFullscreenMode(FullscreenBorderless)
+	if GetFullscreenMode() != FullscreenBorderless {
+		t.Error("SetFullscreenMode didn't set properly")
+	}
+	SetFullscreen(false)
+	if IsFullscreen() {
+		t.Error("SetFullscreen(false) should leave fullscreen")
+	}
+
+	if len(Monitors()) != 0 {
+		t.Errorf("There should be no monitors while headless, got %v", Monitors())
+	}
+	if err := SetVideoMode(0, VideoMode{}); err == nil {
+		t.Error("SetVideoMode should fail for monitors that don't exist")
+	}
+}
+
+func TestVideoModeString(t *testing.T) {
+	mode := VideoMode{Width: 1920, Height: 1080, RefreshRate: 144}
+	if s := mode.String(); s != "1920x1080 @ 144Hz" {
+		t.Errorf("String expected=%q ; got=%q", "1920x1080 @ 144Hz", s)
+	}
+}
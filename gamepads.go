@@ -1,6 +1,9 @@
 package engo
 
-import "sync"
+import (
+	"sort"
+	"sync"
+)
 
 // Gamepadbutton is a button on a Gamepad.
 type GamepadButton struct {
@@ -71,6 +74,111 @@ func (gm *GamepadManager) GetGamepad(name string) *Gamepad {
 	return gm.gamepads[name]
 }
 
+// Connected returns the names of the registered gamepads that are connected, in alphabetical order.
+func (gm *GamepadManager) Connected() []string {
+	gm.mutex.RLock()
+	defer gm.mutex.RUnlock()
+	names := []string{}
+	for name, gamepad := range gm.gamepads {
+		if gamepad.connected {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (gm *GamepadManager) update() {
-	gm.updateImpl()
+	connected, disconnected := gm.updateImpl()
+	if Mailbox == nil {
+		return
+	}
+	for _, name := range connected {
+		Mailbox.Dispatch(GamepadConnectedMessage{Name: name})
+	}
+	for _, name := range disconnected {
+		Mailbox.Dispatch(GamepadDisconnectedMessage{Name: name})
+	}
+}
+
+// Connected returns whether a controller is connected to the Gamepad.
+func (g *Gamepad) Connected() bool {
+	return g.connected
+}
+
+// SetDeadzone sets the Deadzone of all axes of the Gamepad.
+func (g *Gamepad) SetDeadzone(deadzone float32) {
+	for _, axis := range []*AxisGamepad{&g.LeftX, &g.LeftY, &g.RightX, &g.RightY, &g.LeftTrigger, &g.RightTrigger} {
+		axis.Deadzone = deadzone
+	}
+}
+
+// release sets all buttons of the Gamepad up and all axes to neutral, once its controller is unplugged, so none of
+// them stay held.
+func (g *Gamepad) release() {
+	for id := GamepadA; id <= GamepadRightThumb; id++ {
+		*g.Button(id) = GamepadButton{}
+	}
+	for _, axis := range []*AxisGamepad{&g.LeftX, &g.LeftY, &g.RightX, &g.RightY, &g.LeftTrigger, &g.RightTrigger} {
+		axis.set(0)
+	}
+}
+
+// GamepadButtonID identifies one of the buttons of a Gamepad.
+type GamepadButtonID uint8
+
+// The buttons of a Gamepad.
+const (
+	GamepadA GamepadButtonID = iota
+	GamepadB
+	GamepadX
+	GamepadY
+	GamepadBack
+	GamepadStart
+	GamepadGuide
+	GamepadDpadUp
+	GamepadDpadRight
+	GamepadDpadDown
+	GamepadDpadLeft
+	GamepadLeftBumper
+	GamepadRightBumper
+	GamepadLeftThumb
+	GamepadRightThumb
+)
+
+// Button returns the button of the Gamepad with the given id, or nil if there's no such button.
+func (g *Gamepad) Button(id GamepadButtonID) *GamepadButton {
+	switch id {
+	case GamepadA:
+		return &g.A
+	case GamepadB:
+		return &g.B
+	case GamepadX:
+		return &g.X
+	case GamepadY:
+		return &g.Y
+	case GamepadBack:
+		return &g.Back
+	case GamepadStart:
+		return &g.Start
+	case GamepadGuide:
+		return &g.Guide
+	case GamepadDpadUp:
+		return &g.DpadUp
+	case GamepadDpadRight:
+		return &g.DpadRight
+	case GamepadDpadDown:
+		return &g.DpadDown
+	case GamepadDpadLeft:
+		return &g.DpadLeft
+	case GamepadLeftBumper:
+		return &g.LeftBumper
+	case GamepadRightBumper:
+		return &g.RightBumper
+	case GamepadLeftThumb:
+		return &g.LeftThumb
+	case GamepadRightThumb:
+		return &g.RightThumb
+	}
+	return nil
 }
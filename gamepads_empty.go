@@ -19,10 +19,12 @@ type Gamepad struct {
 	LeftX, LeftY                          AxisGamepad
 	RightX, RightY                        AxisGamepad
 	LeftTrigger, RightTrigger             AxisGamepad
+
+	connected bool
 }
 
 func (gm *GamepadManager) registerGamepadImpl(name string) error {
 	return errors.New("Gamepads are not available on this platform!")
 }
 
-func (gm *GamepadManager) updateImpl() {}
+func (gm *GamepadManager) updateImpl() (connected, disconnected []string) { return nil, nil }
@@ -42,10 +42,8 @@ var joys = []glfw.Joystick{
 
 var usedjoys = []glfw.Joystick{}
 
-func (gm *GamepadManager) registerGamepadImpl(name string) error {
-	found := false
-	gm.mutex.Lock()
-	defer gm.mutex.Unlock()
+// unusedJoystick returns a joystick that can be used as a gamepad and isn't used by another Gamepad.
+func unusedJoystick() (glfw.Joystick, bool) {
 joyLoop:
 	for _, joy := range joys {
 		for _, u := range usedjoys {
@@ -54,31 +52,54 @@ joyLoop:
 			}
 		}
 		if joy.IsGamepad() {
-			gm.gamepads[name] = &Gamepad{
-				joystick:  joy,
-				id:        joy.GetGUID(),
-				connected: true,
-			}
-			found = true
-			usedjoys = append(usedjoys, joy)
-			break joyLoop
+			return joy, true
+		}
+	}
+	return 0, false
+}
+
+// releaseJoystick allows the joystick to be used by another Gamepad.
+func releaseJoystick(joy glfw.Joystick) {
+	for i, u := range usedjoys {
+		if joy == u {
+			usedjoys = append(usedjoys[:i], usedjoys[i+1:]...)
+			return
 		}
 	}
+}
+
+func (gm *GamepadManager) registerGamepadImpl(name string) error {
+	gm.mutex.Lock()
+	defer gm.mutex.Unlock()
+	joy, found := unusedJoystick()
 	if !found {
 		warning("Unable to locate any usable gamepads.")
 		gm.gamepads[name] = &Gamepad{id: "", connected: false}
 		return errors.New("unable to locate any usable gamepads \ngamepad will be added when a new one is plugged in")
 	}
+	gm.gamepads[name] = &Gamepad{
+		joystick:  joy,
+		id:        joy.GetGUID(),
+		connected: true,
+	}
+	usedjoys = append(usedjoys, joy)
 	return nil
 }
 
-func (gm *GamepadManager) updateImpl() {
+func (gm *GamepadManager) updateImpl() (connected, disconnected []string) {
 	gm.mutex.Lock()
 	defer gm.mutex.Unlock()
 	for name, gamepad := range gm.gamepads {
 		if !gamepad.connected {
-			warning("Gamepad " + name + " was not available for update!")
-			continue
+			joy, found := unusedJoystick()
+			if !found {
+				continue
+			}
+			gamepad.joystick = joy
+			gamepad.id = joy.GetGUID()
+			gamepad.connected = true
+			usedjoys = append(usedjoys, joy)
+			connected = append(connected, name)
 		}
 		if gamepad.joystick.Present() {
 			state := gamepad.joystick.GetGamepadState()
@@ -181,7 +202,11 @@ func (gm *GamepadManager) updateImpl() {
 			gamepad.RightTrigger.set(state.Axes[glfw.AxisRightTrigger])
 		} else {
 			gamepad.connected = false
-			warning("Gamepad " + name + " was not available to update!")
+			gamepad.release()
+			releaseJoystick(gamepad.joystick)
+			disconnected = append(disconnected, name)
+			warning("Gamepad " + name + " was disconnected!")
 		}
 	}
+	return connected, disconnected
 }
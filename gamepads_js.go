@@ -3,7 +3,10 @@
 
 package engo
 
-import "errors"
+import (
+	"errors"
+	"syscall/js"
+)
 
 // Gampad is a configuration of a joystick that is able to be mapped to the
 // SDL_GameControllerDB.
@@ -51,17 +54,22 @@ func (gm *GamepadManager) registerGamepadImpl(name string) error {
 	return nil
 }
 
-func (gm *GamepadManager) updateImpl() {
+func (gm *GamepadManager) updateImpl() (connected, disconnected []string) {
 	if window.IsUndefined() || window.Get("navigator").IsUndefined() {
-		return // node for testing
+		return nil, nil // node for testing
 	}
 	gpds := window.Get("navigator").Call("getGamepads")
 	gm.mutex.Lock()
 	defer gm.mutex.Unlock()
 	for name, gamepad := range gm.gamepads {
 		if !gamepad.connected {
-			warning("Gamepad " + name + " was not available for update!")
-			continue
+			gpid, found := gm.unusedGamepad(gpds)
+			if !found {
+				continue
+			}
+			gamepad.id = gpid
+			gamepad.connected = true
+			connected = append(connected, name)
 		}
 		for i := 0; i < gpds.Length(); i++ {
 			if gpds.Index(i).IsNull() {
@@ -101,9 +109,31 @@ func (gm *GamepadManager) updateImpl() {
 					gamepad.RightY.set(float32(gpds.Index(i).Get("axes").Index(3).Float()))
 				} else {
 					gamepad.connected = false
-					warning("Gamepad " + name + " was not available to update!")
+					gamepad.release()
+					disconnected = append(disconnected, name)
+					warning("Gamepad " + name + " was disconnected!")
 				}
 			}
 		}
 	}
+	return connected, disconnected
+}
+
+// unusedGamepad returns the id of a connected gamepad with the standard mapping that isn't used by another Gamepad.
+func (gm *GamepadManager) unusedGamepad(gpds js.Value) (string, bool) {
+gpdLoop:
+	for i := 0; i < gpds.Length(); i++ {
+		gpd := gpds.Index(i)
+		if gpd.IsNull() || !gpd.Get("connected").Bool() || gpd.Get("mapping").String() != "standard" {
+			continue
+		}
+		gpid := gpd.Get("id").String()
+		for _, gamepad := range gm.gamepads {
+			if gamepad.connected && gamepad.id == gpid {
+				continue gpdLoop
+			}
+		}
+		return gpid, true
+	}
+	return "", false
 }
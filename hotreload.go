@@ -0,0 +1,85 @@
+package engo
+
+import (
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// hotReloadInterval is how often the files of the loaded resources are checked for changes, with HotReload.
+const hotReloadInterval = 500 * time.Millisecond
+
+// lastHotReload is when the files of the loaded resources were last checked for changes.
+var lastHotReload time.Time
+
+// watch records when the file of the resource at url was last modified, to reload it once it changes.
+func (formats *Formats) watch(url string) {
+	info, ok := formats.fileInfo(url)
+	if !ok {
+		return
+	}
+	if formats.watched == nil {
+		formats.watched = make(map[string]time.Time)
+	}
+	formats.watched[url] = info.ModTime()
+}
+
+// unwatch stops checking the file of the resource at url for changes.
+func (formats *Formats) unwatch(url string) {
+	delete(formats.watched, url)
+}
+
+// fileInfo returns the info of the file the resource at url is loaded from, in the same order as Open. It returns
+// false if the file isn't on the file system, such as files within archives.
+func (formats *Formats) fileInfo(url string) (os.FileInfo, bool) {
+	clean := path.Clean(filepath.ToSlash(url))
+	for _, m := range formats.mounts {
+		rel := clean
+		if m.prefix != "" {
+			if !strings.HasPrefix(clean, m.prefix+"/") {
+				continue
+			}
+			rel = clean[len(m.prefix)+1:]
+		}
+		if m.archive != nil {
+			if _, ok := m.archive[rel]; ok {
+				return nil, false
+			}
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(m.root, rel)); err == nil {
+			return info, true
+		}
+	}
+	if _, ok := formats.archive[clean]; ok {
+		return nil, false
+	}
+	info, err := os.Stat(filepath.Join(formats.root, url))
+	return info, err == nil
+}
+
+// hotReload loads the resources whose file changed again, and dispatches an AssetReloadedMessage for each of them.
+// If loading one fails, such as when it's saved halfway, the previous one is kept and it's tried again once the file
+// changes again.
+func (formats *Formats) hotReload() {
+	if time.Since(lastHotReload) < hotReloadInterval {
+		return
+	}
+	lastHotReload = time.Now()
+
+	for url, modified := range formats.watched {
+		info, ok := formats.fileInfo(url)
+		if !ok || info.ModTime().Equal(modified) {
+			continue
+		}
+		formats.watched[url] = info.ModTime()
+		if err := formats.load(url); err != nil {
+			log.Printf("[WARNING] Unable to reload %q: %s", url, err)
+			continue
+		}
+		Mailbox.Dispatch(AssetReloadedMessage{URL: url})
+	}
+}
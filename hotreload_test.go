@@ -0,0 +1,98 @@
+package engo
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// reloadLoader records the content of the files it loaded, and fails to load empty files.
+type reloadLoader struct {
+	testLoader
+	content map[string]string
+}
+
+func (l *reloadLoader) Load(url string, data io.Reader) error {
+	b, err := ioutil.ReadAll(data)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return errors.New("empty file")
+	}
+	l.content[url] = string(b)
+	return nil
+}
+
+// Test reloading resources once their file changes.
+func TestFilesHotReload(t *testing.T) {
+	Run(RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+		HotReload:    true,
+	}, &assetTestScene{})
+	defer Run(RunOptions{NoRun: true, HeadlessMode: true}, &assetTestScene{})
+	loader := &reloadLoader{content: make(map[string]string)}
+	Files.Register(".reload", loader)
+
+	dir, err := ioutil.TempDir(".", "testing")
+	if err != nil {
+		t.Fatalf("failed to create temp directory for testing, error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	Files.SetRoot(dir)
+
+	file := filepath.Join(dir, "a.reload")
+	if err = ioutil.WriteFile(file, []byte("first"), 0666); err != nil {
+		t.Fatalf("failed to create temp file for testing, error: %v", err)
+	}
+	if err = Files.Load("a.reload"); err != nil {
+		t.Fatalf("could not load test file, error: %v", err)
+	}
+
+	var reloaded []string
+	Mailbox.Listen("AssetReloadedMessage", func(msg Message) {
+		reloaded = append(reloaded, msg.(AssetReloadedMessage).URL)
+	})
+	change := func(content string, modified time.Time) {
+		if err := ioutil.WriteFile(file, []byte(content), 0666); err != nil {
+			t.Fatalf("failed to write temp file for testing, error: %v", err)
+		}
+		if err := os.Chtimes(file, modified, modified); err != nil {
+			t.Fatalf("failed to change the modification time, error: %v", err)
+		}
+		lastHotReload = time.Time{}
+		Files.hotReload()
+	}
+
+	lastHotReload = time.Time{}
+	Files.hotReload()
+	if len(reloaded) != 0 {
+		t.Errorf("unchanged files should not be reloaded, got %v", reloaded)
+	}
+
+	change("second", time.Now().Add(time.Minute))
+	if loader.content["a.reload"] != "second" {
+		t.Errorf("changed files should be reloaded, expected=%q ; got=%q", "second", loader.content["a.reload"])
+	}
+	if len(reloaded) != 1 || reloaded[0] != "a.reload" {
+		t.Errorf("reloading should dispatch an AssetReloadedMessage, got %v", reloaded)
+	}
+
+	change("", time.Now().Add(2*time.Minute))
+	if loader.content["a.reload"] != "second" || len(reloaded) != 1 {
+		t.Error("files failing to reload should keep the previous resource")
+	}
+
+	if err = Files.Unload("a.reload"); err != nil {
+		t.Fatalf("could not unload test file, error: %v", err)
+	}
+	change("third", time.Now().Add(3*time.Minute))
+	if len(reloaded) != 1 {
+		t.Error("unloaded files should not be reloaded")
+	}
+}
@@ -1,5 +1,10 @@
 package engo
 
+import (
+	"reflect"
+	"sync"
+)
+
 const (
 	// AxisMax is the maximum value a joystick or keypress axis will reach
 	AxisMax float32 = 1
@@ -12,11 +17,15 @@ const (
 // NewInputManager holds onto anything input related for engo
 func NewInputManager() *InputManager {
 	return &InputManager{
-		Touches:  make(map[int]Point),
-		axes:     make(map[string]Axis),
-		buttons:  make(map[string]Button),
-		keys:     NewKeyManager(),
-		gamepads: NewGamepadManager(),
+		Touches:             make(map[int]Point),
+		TouchMouseEmulation: true,
+		axes:                make(map[string]Axis),
+		buttons:             make(map[string]Button),
+		actions:             make(map[string]InputAction),
+		keys:                NewKeyManager(),
+		gamepads:            NewGamepadManager(),
+		touches:             make(map[int]Touch),
+		consumed:            make(map[string]bool),
 	}
 }
 
@@ -29,19 +38,111 @@ type InputManager struct {
 	Modifier Modifier
 
 	// Touches is the touches on the screen. There can be up to 5 recorded in Android,
-	// and up to 4 on iOS. GLFW can also keep track of the touches. The latest touch is also
-	// recorded in the Mouse so that touches readily work with the common.MouseSystem
+	// and up to 4 on iOS. The first touch is also recorded in the Mouse so that touches
+	// readily work with the common.MouseSystem, see TouchMouseEmulation. Use TouchPoints
+	// to also know what happened to the touches during the frame.
 	Touches map[int]Point
+	// TouchMouseEmulation sets whether the first finger on the screen is also
+	// used as the left mouse button, for games that only handle the mouse. It
+	// is enabled by default.
+	TouchMouseEmulation bool
 
 	axes     map[string]Axis
 	buttons  map[string]Button
+	actions  map[string]InputAction
 	keys     *KeyManager
 	gamepads *GamepadManager
+
+	touchMutex         sync.Mutex
+	touches            map[int]Touch
+	pendingTouches     []Touch
+	pinch              Pinch
+	primaryTouch       int
+	primaryTouchActive bool
+
+	ignored bool
+
+	// consumed are the names of the Buttons and actions, and the mouse
+	// buttons, that were handled during the current frame
+	consumed map[string]bool
+
+	replay inputReplay
+}
+
+// SetIgnored sets whether input is ignored. While ignored, all Buttons, Axes
+// and actions act as if nothing is pressed, and the common.MouseSystem only
+// clears the clicks and other events of the last frame from the
+// MouseComponents. The raw Mouse and Touches are still tracked.
+func (im *InputManager) SetIgnored(ignored bool) {
+	im.ignored = ignored
+}
+
+// Ignored returns whether input is ignored.
+func (im *InputManager) Ignored() bool {
+	return im.ignored
+}
+
+// Consume marks the Button or action with the given name as handled for the rest of the current frame, so Systems
+// updated after the current one see it as not pressed. This allows a HUD to react to a key or click before the world
+// does, without the world reacting as well. Systems are updated in the order of their priorities, so the System
+// handling the HUD needs a higher priority than the ones handling the world.
+func (im *InputManager) Consume(name string) {
+	if im.consumed == nil {
+		im.consumed = make(map[string]bool)
+	}
+	im.consumed[name] = true
+}
+
+// ConsumeKey consumes the Buttons and the actions bound to the key, so Systems updated after the current one see them
+// as not pressed, such as while the key edits a text field. Key itself still returns the state of the key.
+func (im *InputManager) ConsumeKey(k Key) {
+	for name, b := range im.buttons {
+		for _, trigger := range b.Triggers {
+			if trigger == k {
+				im.Consume(name)
+			}
+		}
+	}
+	for name, a := range im.actions {
+		for _, binding := range a.Bindings {
+			if key, ok := binding.(ActionKey); ok && key.Key == k {
+				im.Consume(name)
+			}
+		}
+	}
+}
+
+// Consumed returns whether the Button or action with the given name was handled during the current frame.
+func (im *InputManager) Consumed(name string) bool {
+	return im.consumed[name]
+}
+
+// ConsumeMouse marks the mouse button as handled for the rest of the current frame. Unlike Buttons, the raw Mouse
+// isn't changed, except for Mouse.Drag being inactive, so Systems handling clicks have to check MouseConsumed
+// themselves. The common.MouseSystem does, and consumes the buttons clicked on its entities.
+func (im *InputManager) ConsumeMouse(button MouseButton) {
+	if button >= 0 && button <= MouseButtonLast {
+		im.Mouse.consumed[button] = true
+	}
+}
+
+// MouseConsumed returns whether the mouse button was handled during the current frame.
+func (im *InputManager) MouseConsumed(button MouseButton) bool {
+	return button >= 0 && button <= MouseButtonLast && im.Mouse.consumed[button]
+}
+
+// resetConsumed forgets which inputs were handled, at the start of a frame.
+func (im *InputManager) resetConsumed() {
+	for name := range im.consumed {
+		delete(im.consumed, name)
+	}
+	im.Mouse.consumed = [MouseButtonLast + 1]bool{}
 }
 
 func (im *InputManager) update() {
 	im.keys.update()
 	im.gamepads.update()
+	im.updateTouches()
 }
 
 // RegisterAxis registers a new axis which can be used to retrieve inputs which are spectrums.
@@ -60,6 +161,52 @@ func (im *InputManager) RegisterButton(name string, keys ...Key) {
 	}
 }
 
+// RegisterAction registers a new action triggered by any of the bindings. If the action was already registered, its
+// bindings are replaced, which allows the controls to be changed while the game is running.
+func (im *InputManager) RegisterAction(name string, bindings ...ActionBinding) {
+	im.actions[name] = InputAction{
+		Name:     name,
+		Bindings: bindings,
+	}
+}
+
+// BindAction adds a binding to an action, registering the action if needed.
+func (im *InputManager) BindAction(name string, binding ActionBinding) {
+	action := im.actions[name]
+	action.Name = name
+	action.Bindings = append(action.Bindings, binding)
+	im.actions[name] = action
+}
+
+// UnbindAction removes a binding from an action.
+func (im *InputManager) UnbindAction(name string, binding ActionBinding) {
+	action, ok := im.actions[name]
+	if !ok {
+		return
+	}
+	bindings := make([]ActionBinding, 0, len(action.Bindings))
+	for _, b := range action.Bindings {
+		if !sameBinding(b, binding) {
+			bindings = append(bindings, b)
+		}
+	}
+	action.Bindings = bindings
+	im.actions[name] = action
+}
+
+// sameBinding reports whether the bindings are the same. Bindings are compared by their values, so custom bindings
+// of types that can't be compared with ==, such as ones holding a slice, don't panic. Pointers are compared as they
+// are, as they're the identity of the binding.
+func sameBinding(a, b ActionBinding) bool {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return false
+	}
+	if a == nil || reflect.TypeOf(a).Kind() == reflect.Ptr {
+		return a == b
+	}
+	return reflect.DeepEqual(a, b)
+}
+
 // RegisterGamepad registers a new gamepad for use. It starts with joystick0
 // and continues until it finds one that can be used. If it does not find a
 // suitable gamepad, an error will be returned.
@@ -77,6 +224,26 @@ func (im *InputManager) Button(name string) Button {
 	return im.buttons[name]
 }
 
+// Key retrieves the state of a single key, without registering a Button for it. This is meant for Systems that
+// handle keys which aren't part of the controls of the game, such as the arrow keys of a text field. While input
+// is ignored, every key is up.
+func (im *InputManager) Key(k Key) KeyState {
+	if im.ignored {
+		return KeyState{}
+	}
+	return im.keys.Get(k)
+}
+
+// Action retrieves an InputAction with a specified name.
+func (im *InputManager) Action(name string) InputAction {
+	return im.actions[name]
+}
+
+// Gamepads returns the names of the registered gamepads that are connected.
+func (im *InputManager) Gamepads() []string {
+	return im.gamepads.Connected()
+}
+
 // Gamepad retrieves a Gamepad with a specified name.
 func (im *InputManager) Gamepad(name string) *Gamepad {
 	return im.gamepads.GetGamepad(name)
@@ -84,9 +251,78 @@ func (im *InputManager) Gamepad(name string) *Gamepad {
 
 // Mouse represents the mouse
 type Mouse struct {
-	X, Y             float32
+	X, Y float32
+	// ScrollX and ScrollY are the amount of scrolling done with the mouse wheel
+	// during the current frame. All scrolling between frames is added up.
 	ScrollX, ScrollY float32
-	Action           Action
-	Button           MouseButton
-	Modifer          Modifier
+	// DeltaX and DeltaY are how far the mouse moved during the current frame,
+	// in the same units as X and Y. Unlike X and Y, they keep changing while
+	// the cursor is grabbed with SetCursorGrabbed.
+	DeltaX, DeltaY float32
+	Action         Action
+	Button         MouseButton
+	Modifer        Modifier
+
+	buttons [MouseButtonLast + 1]mouseButtonState
+	// consumed are the buttons handled during the current frame, with InputManager.ConsumeMouse
+	consumed [MouseButtonLast + 1]bool
+}
+
+// moveCursor records that the cursor moved by dx, dy to x, y. While the
+// cursor is grabbed, it stays at the center of the window instead.
+func (m *Mouse) moveCursor(x, y, dx, dy float32) {
+	m.DeltaX += dx
+	m.DeltaY += dy
+	if cursorGrabbed {
+		x, y = WindowWidth()/2/opts.GlobalScale.X, WindowHeight()/2/opts.GlobalScale.Y
+	}
+	m.X, m.Y = x, y
+}
+
+// mouseButtonState is whether a mouse button is being held down, and where it
+// was pressed.
+type mouseButtonState struct {
+	down  bool
+	start Point
+}
+
+// MouseDrag is a drag with one of the mouse buttons.
+type MouseDrag struct {
+	// Active is whether the button is being held down. The other fields are
+	// only set when the drag is active.
+	Active bool
+	// Start is where the button was pressed.
+	Start Point
+	// Current is where the mouse is now.
+	Current Point
+	// Delta is how far the mouse moved since the button was pressed.
+	Delta Point
+}
+
+// setButton records that the button was pressed or released at the current
+// position of the mouse.
+func (m *Mouse) setButton(button MouseButton, down bool) {
+	if button < 0 || button > MouseButtonLast {
+		return
+	}
+	m.buttons[button] = mouseButtonState{
+		down:  down,
+		start: Point{X: m.X, Y: m.Y},
+	}
+}
+
+// Drag returns the drag with the given button, which is active as long as the
+// button is held down. It's inactive during the frames the button is consumed
+// with InputManager.ConsumeMouse, such as while an entity is dragged with it.
+func (m *Mouse) Drag(button MouseButton) MouseDrag {
+	if button < 0 || button > MouseButtonLast || !m.buttons[button].down || m.consumed[button] {
+		return MouseDrag{}
+	}
+	start := m.buttons[button].start
+	return MouseDrag{
+		Active:  true,
+		Start:   start,
+		Current: Point{X: m.X, Y: m.Y},
+		Delta:   Point{X: m.X - start.X, Y: m.Y - start.Y},
+	}
 }
@@ -0,0 +1,191 @@
+package engo
+
+// KeyEvent is a key being pressed or released, in an InputRecording.
+type KeyEvent struct {
+	Key  Key
+	Down bool
+}
+
+// RecordedMouse is the state of the Mouse during a frame of an InputRecording.
+type RecordedMouse struct {
+	X, Y             float32
+	ScrollX, ScrollY float32
+	DeltaX, DeltaY   float32
+	Action           Action
+	Button           MouseButton
+	Modifier         Modifier
+	// Down is whether each mouse button is held down, and Start where it was pressed
+	Down  [MouseButtonLast + 1]bool
+	Start [MouseButtonLast + 1]Point
+}
+
+// InputFrame is the input of a single frame of an InputRecording.
+type InputFrame struct {
+	// Dt is the duration of the frame in seconds, which the Systems are updated with during playback.
+	Dt float32
+	// Keys are the keys pressed and released during the frame, in order.
+	Keys []KeyEvent `json:",omitempty"`
+	// Mouse is the state of the Mouse, or nil if it's the same as during the previous frame.
+	Mouse *RecordedMouse `json:",omitempty"`
+	// Modifier is the Modifier of the InputManager.
+	Modifier Modifier `json:",omitempty"`
+}
+
+// InputRecording is the input of every frame between InputManager.StartRecording and StopRecording. It only holds
+// exported fields, so it can be saved with encoding/json or encoding/gob, and attached to bug reports.
+type InputRecording struct {
+	Frames []InputFrame
+}
+
+// inputReplay is the state of recording or playing back an InputRecording.
+type inputReplay struct {
+	recording *InputRecording
+	playing   *InputRecording
+	frame     int
+	// mouse is the state of the Mouse during the last played frame
+	mouse RecordedMouse
+	// recorded is the state of the Mouse during the last recorded frame
+	recorded RecordedMouse
+}
+
+// StartRecording starts recording the keys, the Mouse and the Modifier of every frame, along with how long the frames
+// last. Touches, gamepads and the TextMessages of typed text are not recorded.
+func (im *InputManager) StartRecording() {
+	im.replay.recording = &InputRecording{}
+	im.keys.mutex.Lock()
+	im.keys.recording = true
+	im.keys.events = nil
+	im.keys.mutex.Unlock()
+}
+
+// StopRecording stops recording and returns the recording, or nil if the input isn't being recorded.
+func (im *InputManager) StopRecording() *InputRecording {
+	rec := im.replay.recording
+	im.replay.recording = nil
+	im.keys.mutex.Lock()
+	im.keys.recording = false
+	im.keys.events = nil
+	im.keys.mutex.Unlock()
+	return rec
+}
+
+// Recording returns whether the input is being recorded.
+func (im *InputManager) Recording() bool {
+	return im.replay.recording != nil
+}
+
+// Play plays back the recording, one frame of it per frame of the game, starting with the next one. During playback,
+// the real input of the keys, the Mouse and the Modifier is suppressed, and the Systems are updated with the
+// duration of the recorded frames rather than of the real ones, so a game whose logic is deterministic runs exactly as
+// it did while recording. The keys held on the keyboard are released when playback starts, so only the recording
+// presses keys. Once the last frame is played, the real input is used again.
+func (im *InputManager) Play(rec *InputRecording) {
+	if im.replay.playing == nil {
+		im.keys.suppress()
+	}
+	im.replay.playing = rec
+	im.replay.frame = 0
+	im.replay.mouse = mouseRecord(im.Mouse)
+}
+
+// StopPlaying stops the playback started with Play, and uses the real input again. The keys are pressed and released
+// to match the keyboard, so the ones the recording left held down are released, and the ones held on the keyboard
+// during playback are down. The mouse buttons the recording left held down are released.
+func (im *InputManager) StopPlaying() {
+	if im.replay.playing == nil {
+		return
+	}
+	im.replay.playing = nil
+	im.keys.unsuppress()
+	for b, down := range im.replay.mouse.Down {
+		if down {
+			im.Mouse.setButton(MouseButton(b), false)
+		}
+	}
+}
+
+// Playing returns whether a recording is being played back.
+func (im *InputManager) Playing() bool {
+	return im.replay.playing != nil
+}
+
+// replayFrame replaces the input of the current frame with the one of the recording being played back, and records
+// it. It returns the duration to update the Systems with.
+func (im *InputManager) replayFrame(dt float32) float32 {
+	if rec := im.replay.playing; rec != nil {
+		if im.replay.frame < len(rec.Frames) {
+			frame := rec.Frames[im.replay.frame]
+			im.replay.frame++
+			for _, e := range frame.Keys {
+				im.keys.set(e.Key, e.Down)
+			}
+			im.keys.mutex.Lock()
+			if im.keys.recording {
+				im.keys.events = append(im.keys.events, frame.Keys...)
+			}
+			im.keys.mutex.Unlock()
+			if frame.Mouse != nil {
+				im.replay.mouse = *frame.Mouse
+			}
+			im.Mouse = im.replay.mouse.mouse()
+			im.Modifier = frame.Modifier
+			dt = frame.Dt
+		} else {
+			im.StopPlaying()
+		}
+	}
+
+	if rec := im.replay.recording; rec != nil {
+		im.keys.mutex.Lock()
+		frame := InputFrame{Dt: dt, Keys: im.keys.events, Modifier: im.Modifier}
+		im.keys.events = nil
+		im.keys.mutex.Unlock()
+
+		mouse := mouseRecord(im.Mouse)
+		if len(rec.Frames) == 0 || mouse != im.replay.recorded {
+			frame.Mouse = &mouse
+			im.replay.recorded = mouse
+		}
+		rec.Frames = append(rec.Frames, frame)
+	}
+	return dt
+}
+
+// mouseRecord returns the state of the mouse to record.
+func mouseRecord(m Mouse) RecordedMouse {
+	r := RecordedMouse{
+		X:        m.X,
+		Y:        m.Y,
+		ScrollX:  m.ScrollX,
+		ScrollY:  m.ScrollY,
+		DeltaX:   m.DeltaX,
+		DeltaY:   m.DeltaY,
+		Action:   m.Action,
+		Button:   m.Button,
+		Modifier: m.Modifer,
+	}
+	for i, b := range m.buttons {
+		r.Down[i] = b.down
+		r.Start[i] = b.start
+	}
+	return r
+}
+
+// mouse returns the recorded state as a Mouse.
+func (r RecordedMouse) mouse() Mouse {
+	m := Mouse{
+		X:       r.X,
+		Y:       r.Y,
+		ScrollX: r.ScrollX,
+		ScrollY: r.ScrollY,
+		DeltaX:  r.DeltaX,
+		DeltaY:  r.DeltaY,
+		Action:  r.Action,
+		Button:  r.Button,
+		Modifer: r.Modifier,
+	}
+	for i := range m.buttons {
+		m.buttons[i] = mouseButtonState{down: r.Down[i], start: r.Start[i]}
+	}
+	return m
+}
@@ -0,0 +1,129 @@
+package engo
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestInputRecording(t *testing.T) {
+	Input = NewInputManager()
+	Input.StartRecording()
+	if !Input.Recording() {
+		t.Error("Input should be recording")
+	}
+
+	Input.update()
+	Input.keys.Set(KeyA, true)
+	Input.Mouse.X, Input.Mouse.Y = 5, 10
+	Input.Mouse.setButton(MouseButtonLeft, true)
+	Input.replayFrame(0.1)
+
+	Input.update()
+	Input.keys.Set(KeyA, false)
+	Input.replayFrame(0.2)
+
+	rec := Input.StopRecording()
+	if Input.Recording() || len(rec.Frames) != 2 {
+		t.Fatalf("Recording should have stopped with 2 frames; got=%d", len(rec.Frames))
+	}
+	if rec.Frames[1].Mouse != nil {
+		t.Error("The mouse should only be recorded when it changes")
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatalf("Unable to marshal recording: %s", err)
+	}
+	rec = &InputRecording{}
+	if err = json.Unmarshal(data, rec); err != nil {
+		t.Fatalf("Unable to unmarshal recording: %s", err)
+	}
+
+	Input = NewInputManager()
+	Input.Play(rec)
+	Input.update()
+	Input.keys.Set(KeyB, true)
+	Input.Mouse.X = 100
+	if dt := Input.replayFrame(1); dt != 0.1 {
+		t.Errorf("The recorded dt should be used. expected=%v ; got=%v", 0.1, dt)
+	}
+	if !Input.Key(KeyA).JustPressed() {
+		t.Error("Recorded keys should be played back")
+	}
+	if Input.Key(KeyB).JustPressed() {
+		t.Error("Real keys should be suppressed during playback")
+	}
+	if Input.Mouse.X != 5 || Input.Mouse.Y != 10 {
+		t.Errorf("The recorded mouse should be played back. expected=5, 10 ; got=%v, %v", Input.Mouse.X, Input.Mouse.Y)
+	}
+	if !Input.Mouse.Drag(MouseButtonLeft).Active {
+		t.Error("The recorded mouse buttons should be played back")
+	}
+
+	Input.update()
+	Input.Mouse.X = 100
+	Input.replayFrame(1)
+	if !Input.Key(KeyA).JustReleased() {
+		t.Error("Recorded keys should be released")
+	}
+	if Input.Mouse.X != 5 {
+		t.Errorf("The mouse should stay as recorded. expected=5 ; got=%v", Input.Mouse.X)
+	}
+
+	if dt := Input.replayFrame(1); dt != 1 || Input.Playing() {
+		t.Errorf("Playback should stop after the last frame. expected=1 ; got=%v", dt)
+	}
+	Input.keys.Set(KeyB, true)
+	if !Input.keys.Get(KeyB).currentState {
+		t.Error("Real keys should be used after playback")
+	}
+}
+
+func TestInputStopPlaying(t *testing.T) {
+	Input = NewInputManager()
+	Input.Play(&InputRecording{Frames: []InputFrame{
+		{Dt: 0.1, Keys: []KeyEvent{{Key: KeyA, Down: true}, {Key: KeyB, Down: true}, {Key: KeyB, Down: false}}},
+		{Dt: 0.1},
+	}})
+	Input.update()
+	Input.replayFrame(1)
+	if !Input.Key(KeyA).JustPressed() {
+		t.Fatal("Recorded keys should be played back")
+	}
+
+	Input.StopPlaying()
+	if !Input.Key(KeyA).JustReleased() {
+		t.Error("Keys held by the recording should be released when playback stops")
+	}
+	Input.update()
+	if !Input.Key(KeyA).Up() {
+		t.Error("Keys held by the recording should stay released")
+	}
+}
+
+func TestInputPlayRealKeys(t *testing.T) {
+	Input = NewInputManager()
+	Input.keys.Set(KeyC, true)
+	Input.update()
+
+	Input.Play(&InputRecording{Frames: []InputFrame{{Dt: 0.1}, {Dt: 0.1}}})
+	if !Input.Key(KeyC).JustReleased() {
+		t.Error("Keys held on the keyboard should be released when playback starts")
+	}
+	Input.update()
+	Input.keys.Set(KeyC, false)
+	Input.keys.Set(KeyD, true)
+	Input.replayFrame(1)
+	if !Input.Key(KeyC).Up() || !Input.Key(KeyD).Up() {
+		t.Error("Real keys should be suppressed during playback")
+	}
+
+	Input.StopPlaying()
+	Input.update()
+	if !Input.Key(KeyC).Up() {
+		t.Error("Keys released on the keyboard during playback should stay released")
+	}
+	if !Input.Key(KeyD).Down() {
+		t.Error("Keys pressed on the keyboard during playback should be down once it stops")
+	}
+}
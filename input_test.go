@@ -0,0 +1,186 @@
+package engo
+
+import "testing"
+
+// Test dragging with the mouse buttons.
+func TestMouseDrag(t *testing.T) {
+	Input = NewInputManager()
+
+	if Input.Mouse.Drag(MouseButtonLeft).Active {
+		t.Error("Drag should not be active before pressing the button")
+	}
+
+	Input.Mouse.X, Input.Mouse.Y = 10, 20
+	Input.Mouse.setButton(MouseButtonLeft, true)
+	Input.Mouse.X, Input.Mouse.Y = 15, 10
+
+	drag := Input.Mouse.Drag(MouseButtonLeft)
+	expected := MouseDrag{
+		Active:  true,
+		Start:   Point{X: 10, Y: 20},
+		Current: Point{X: 15, Y: 10},
+		Delta:   Point{X: 5, Y: -10},
+	}
+	if drag != expected {
+		t.Errorf("Drag expected=%v ; got=%v", expected, drag)
+	}
+	if Input.Mouse.Drag(MouseButtonRight).Active {
+		t.Error("Drag with other buttons should not be active")
+	}
+
+	Input.ConsumeMouse(MouseButtonLeft)
+	if Input.Mouse.Drag(MouseButtonLeft).Active {
+		t.Error("Drag should not be active while the button is consumed")
+	}
+	Input.resetConsumed()
+	if !Input.Mouse.Drag(MouseButtonLeft).Active {
+		t.Error("Drag should be active again in the next frame")
+	}
+
+	Input.Mouse.setButton(MouseButtonLeft, false)
+	if Input.Mouse.Drag(MouseButtonLeft).Active {
+		t.Error("Drag should not be active after releasing the button")
+	}
+	if Input.Mouse.Drag(MouseButtonLast + 1).Active {
+		t.Error("Drag with unknown buttons should not be active")
+	}
+}
+
+// Test moving the mouse, with and without grabbing the cursor.
+func TestMouseMoveCursor(t *testing.T) {
+	Run(RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+		Width:        800,
+		Height:       600,
+	}, &testScene{})
+	Input = NewInputManager()
+
+	Input.Mouse.moveCursor(10, 20, 10, 20)
+	Input.Mouse.moveCursor(15, 10, 5, -10)
+	if Input.Mouse.X != 15 || Input.Mouse.Y != 10 {
+		t.Errorf("Position expected=(15, 10) ; got=(%v, %v)", Input.Mouse.X, Input.Mouse.Y)
+	}
+	if Input.Mouse.DeltaX != 15 || Input.Mouse.DeltaY != 10 {
+		t.Errorf("Deltas should add up during a frame, expected=(15, 10) ; got=(%v, %v)", Input.Mouse.DeltaX, Input.Mouse.DeltaY)
+	}
+
+	SetCursorGrabbed(true)
+	defer SetCursorGrabbed(false)
+	if !CursorGrabbed() {
+		t.Error("SetCursorGrabbed(true) should grab the cursor")
+	}
+	Input.Mouse.DeltaX, Input.Mouse.DeltaY = 0, 0
+	Input.Mouse.moveCursor(700, 500, 3, 4)
+	if Input.Mouse.X != 400 || Input.Mouse.Y != 300 {
+		t.Errorf("A grabbed cursor should stay at the center, expected=(400, 300) ; got=(%v, %v)", Input.Mouse.X, Input.Mouse.Y)
+	}
+	if Input.Mouse.DeltaX != 3 || Input.Mouse.DeltaY != 4 {
+		t.Errorf("Deltas of a grabbed cursor expected=(3, 4) ; got=(%v, %v)", Input.Mouse.DeltaX, Input.Mouse.DeltaY)
+	}
+
+	SetCursorGrabbed(false)
+	if CursorGrabbed() {
+		t.Error("SetCursorGrabbed(false) should release the cursor")
+	}
+}
+
+// Test ignoring input.
+func TestInputIgnored(t *testing.T) {
+	Input = NewInputManager()
+	Input.RegisterButton("jump", KeySpace)
+	Input.RegisterAxis("horizontal", AxisKeyPair{KeyA, KeyD})
+	Input.RegisterAction("Fire", ActionKey{KeyF})
+
+	Input.update()
+	Input.keys.Set(KeySpace, true)
+	Input.keys.Set(KeyD, true)
+	Input.keys.Set(KeyF, true)
+
+	Input.SetIgnored(true)
+	if !Input.Ignored() {
+		t.Error("Ignored should return true after SetIgnored(true)")
+	}
+	if Input.Button("jump").JustPressed() || Input.Axis("horizontal").Value() != AxisNeutral || Input.Action("Fire").JustPressed() {
+		t.Error("Input should be ignored")
+	}
+
+	Input.SetIgnored(false)
+	if !Input.Button("jump").JustPressed() || Input.Axis("horizontal").Value() != AxisMax || !Input.Action("Fire").JustPressed() {
+		t.Error("Input should no longer be ignored")
+	}
+}
+
+// Test consuming input.
+func TestInputConsumed(t *testing.T) {
+	Input = NewInputManager()
+	Input.RegisterButton("jump", KeySpace)
+	Input.RegisterAction("Fire", ActionKey{KeyF})
+	Input.RegisterAction("Reload", ActionKey{KeyR})
+
+	Input.update()
+	Input.keys.Set(KeySpace, true)
+	Input.keys.Set(KeyF, true)
+	Input.keys.Set(KeyR, true)
+
+	Input.Consume("jump")
+	Input.Consume("Fire")
+	Input.ConsumeMouse(MouseButtonLeft)
+	if !Input.Consumed("jump") || !Input.Consumed("Fire") || Input.Consumed("Reload") {
+		t.Error("Consumed should only return true for consumed inputs")
+	}
+	if Input.Button("jump").JustPressed() || Input.Button("jump").Down() {
+		t.Error("Consumed buttons should not be pressed")
+	}
+	if Input.Action("Fire").JustPressed() || Input.Action("Fire").Down() {
+		t.Error("Consumed actions should not be pressed")
+	}
+	if !Input.Action("Reload").JustPressed() {
+		t.Error("Actions that weren't consumed should still be pressed")
+	}
+	if !Input.MouseConsumed(MouseButtonLeft) || Input.MouseConsumed(MouseButtonRight) || Input.MouseConsumed(MouseButtonLast+1) {
+		t.Error("MouseConsumed should only return true for consumed mouse buttons")
+	}
+
+	Input.RegisterButton("reload", KeyR)
+	Input.ConsumeKey(KeyR)
+	if !Input.Consumed("reload") || !Input.Consumed("Reload") {
+		t.Error("ConsumeKey should consume the Buttons and actions bound to the key")
+	}
+	if !Input.Key(KeyR).JustPressed() {
+		t.Error("ConsumeKey should not change the state of the key itself")
+	}
+
+	// Consumption only lasts for the current frame
+	Input.resetConsumed()
+	if Input.Consumed("jump") || Input.MouseConsumed(MouseButtonLeft) {
+		t.Error("Inputs should not be consumed in the next frame")
+	}
+	if !Input.Button("jump").Down() || !Input.Action("Fire").Down() {
+		t.Error("Inputs should be pressed again in the next frame")
+	}
+}
+
+// Test retrieving the state of keys without a Button.
+func TestInputKey(t *testing.T) {
+	Input = NewInputManager()
+
+	Input.update()
+	Input.keys.Set(KeyHome, true)
+	if !Input.Key(KeyHome).JustPressed() {
+		t.Error("Key should be just pressed after pressing it")
+	}
+	if Input.Key(KeyEnd).JustPressed() {
+		t.Error("Other keys should not be pressed")
+	}
+
+	Input.update()
+	if !Input.Key(KeyHome).Down() {
+		t.Error("Key should be down while it's held")
+	}
+
+	Input.SetIgnored(true)
+	if Input.Key(KeyHome).Down() {
+		t.Error("Key should be up while input is ignored")
+	}
+}
@@ -28,11 +28,35 @@ type KeyManager struct {
 	dirtmap map[Key]Key
 	mapper  map[Key]KeyState
 	mutex   sync.RWMutex
+
+	// recording is whether the changes of the keys are added to events
+	recording bool
+	events    []KeyEvent
+	// suppressed ignores Set, while a recording is played back
+	suppressed bool
+	// real is whether each key is held on the keyboard, tracked while suppressed
+	real map[Key]bool
 }
 
 // Set is used for updating whether or not a key is held down, or not held down.
 func (km *KeyManager) Set(k Key, state bool) {
 	km.mutex.Lock()
+	if km.suppressed {
+		km.real[k] = state
+		km.mutex.Unlock()
+		return
+	}
+	if km.recording {
+		km.events = append(km.events, KeyEvent{Key: k, Down: state})
+	}
+	km.mutex.Unlock()
+
+	km.set(k, state)
+}
+
+// set updates the state of the key, even while a recording is played back.
+func (km *KeyManager) set(k Key, state bool) {
+	km.mutex.Lock()
 
 	ks := km.mapper[k]
 	ks.set(state)
@@ -42,6 +66,50 @@ func (km *KeyManager) Set(k Key, state bool) {
 	km.mutex.Unlock()
 }
 
+// suppress starts ignoring Set, and releases the keys held on the keyboard. Set keeps track of which keys are held
+// while suppressed, for unsuppress.
+func (km *KeyManager) suppress() {
+	km.mutex.Lock()
+	km.suppressed = true
+	km.real = make(map[Key]bool)
+	var held []Key
+	for k, ks := range km.mapper {
+		if ks.currentState {
+			km.real[k] = true
+			held = append(held, k)
+		}
+	}
+	km.mutex.Unlock()
+
+	for _, k := range held {
+		km.set(k, false)
+	}
+}
+
+// unsuppress stops ignoring Set, and presses or releases the keys so they're as held on the keyboard.
+func (km *KeyManager) unsuppress() {
+	km.mutex.Lock()
+	km.suppressed = false
+	var changed []Key
+	for k, ks := range km.mapper {
+		if ks.currentState != km.real[k] {
+			changed = append(changed, k)
+		}
+	}
+	for k, down := range km.real {
+		if _, ok := km.mapper[k]; !ok && down {
+			changed = append(changed, k)
+		}
+	}
+	real := km.real
+	km.real = nil
+	km.mutex.Unlock()
+
+	for _, k := range changed {
+		km.set(k, real[k])
+	}
+}
+
 // Get retrieves a keys state.
 func (km *KeyManager) Get(k Key) KeyState {
 	km.mutex.RLock()
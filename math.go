@@ -393,6 +393,11 @@ func CrossProduct(this, that Point) float32 {
 	return this.X*that.Y - this.Y*that.X
 }
 
+// LerpPoint returns the linear interpolation between a and b at t
+func LerpPoint(a, b Point, t float32) Point {
+	return Point{X: math.Lerp(a.X, b.X, t), Y: math.Lerp(a.Y, b.Y, t)}
+}
+
 // LineIntersection returns the point where the line segments one and two
 // intersect and true if there is intersection, nil and false when line
 // segments one and two do not intersect
@@ -0,0 +1,29 @@
+package math
+
+// Lerp returns the linear interpolation between a and b at t. A t of 0 returns
+// a and a t of 1 returns b; values outside of [0, 1] extrapolate.
+func Lerp(a, b, t float32) float32 {
+	return a + (b-a)*t
+}
+
+// InverseLerp returns the t at which Lerp(a, b, t) equals v. If a equals b, it
+// returns 0.
+func InverseLerp(a, b, v float32) float32 {
+	if a == b {
+		return 0
+	}
+	return (v - a) / (b - a)
+}
+
+// LerpAngle returns the linear interpolation between the angles a and b in
+// degrees at t, going the shortest way around the circle, such as from 350 to
+// 10 through 0 rather than through 180.
+func LerpAngle(a, b, t float32) float32 {
+	d := Mod(b-a, 360)
+	if d > 180 {
+		d -= 360
+	} else if d < -180 {
+		d += 360
+	}
+	return a + d*t
+}
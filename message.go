@@ -2,11 +2,15 @@ package engo
 
 import (
 	"sync"
+	"sync/atomic"
 )
 
 // A MessageHandler is used to dispatch a message to the subscribed handler.
 type MessageHandler func(msg Message)
 
+// A MessageFilter decides whether a message is passed to a handler.
+type MessageFilter func(msg Message) bool
+
 // MessageHandlerId is used to track handlers, each handler will get a unique ID
 type MessageHandlerId uint64
 
@@ -17,8 +21,7 @@ func init() {
 }
 
 func getNewHandlerID() MessageHandlerId {
-	currentHandlerID++
-	return currentHandlerID
+	return MessageHandlerId(atomic.AddUint64((*uint64)(&currentHandlerID), 1))
 }
 
 type HandlerIDPair struct {
@@ -39,6 +42,8 @@ type MessageManager struct {
 	sync.RWMutex
 	listeners        map[string][]HandlerIDPair
 	handlersToRemove map[string][]MessageHandlerId
+	// sceneSubscriptions are the Subscriptions made with SubscribeScene
+	sceneSubscriptions []*Subscription
 }
 
 // Dispatch sends a message to all subscribed handlers of the message's type
@@ -47,14 +52,14 @@ type MessageManager struct {
 // semaphores, or any other method necessary to ensure the memory is not altered by multiple
 // functions simultaneously.
 func (mm *MessageManager) Dispatch(message Message) {
-	mm.RLock()
+	mm.Lock()
 	mm.clearRemovedHandlers()
 	handlers := make([]MessageHandler, len(mm.listeners[message.Type()]))
 	pairs := mm.listeners[message.Type()]
 	for i := range pairs {
 		handlers[i] = pairs[i].MessageHandler
 	}
-	mm.RUnlock()
+	mm.Unlock()
 
 	for _, handler := range handlers {
 		handler(message)
@@ -64,34 +69,149 @@ func (mm *MessageManager) Dispatch(message Message) {
 
 // Listen subscribes to the specified message type and calls the specified handler when fired
 func (mm *MessageManager) Listen(messageType string, handler MessageHandler) MessageHandlerId {
+	handlerID := getNewHandlerID()
+	mm.addHandler(messageType, handlerID, handler)
+	return handlerID
+}
+
+// addHandler adds the handler with the given ID to the listener queue.
+func (mm *MessageManager) addHandler(messageType string, handlerID MessageHandlerId, handler MessageHandler) {
 	mm.Lock()
 	defer mm.Unlock()
 	if mm.listeners == nil {
 		mm.listeners = make(map[string][]HandlerIDPair)
 	}
-	handlerID := getNewHandlerID()
 	newHandlerIDPair := HandlerIDPair{MessageHandlerId: handlerID, MessageHandler: handler}
 	mm.listeners[messageType] = append(mm.listeners[messageType], newHandlerIDPair)
-	return handlerID
 }
 
 // ListenOnce is a convenience wrapper around StopListen() to only listen to a specified message once
-func (mm *MessageManager) ListenOnce(messageType string, handler MessageHandler) {
-	handlerID := MessageHandlerId(0)
-	handlerID = mm.Listen(messageType, func(msg Message) {
-		handler(msg)
+func (mm *MessageManager) ListenOnce(messageType string, handler MessageHandler) MessageHandlerId {
+	return mm.listenOnce(messageType, nil, handler)
+}
+
+// ListenFilter subscribes to the specified message type and calls the specified handler only for the messages the
+// filter returns true for, such as collisions with a specific entity. The filter is called from the goroutine
+// dispatching the message, before the handler, and must not block.
+func (mm *MessageManager) ListenFilter(messageType string, filter MessageFilter, handler MessageHandler) MessageHandlerId {
+	return mm.Listen(messageType, filterHandler(filter, handler))
+}
+
+// ListenOnceFilter is like ListenFilter, but stops listening after the handler was called once, such as to wait for
+// the first collision with a specific entity.
+func (mm *MessageManager) ListenOnceFilter(messageType string, filter MessageFilter, handler MessageHandler) MessageHandlerId {
+	return mm.listenOnce(messageType, filter, handler)
+}
+
+// listenOnce adds a handler that stops listening after the first message passing the filter, if there is one.
+func (mm *MessageManager) listenOnce(messageType string, filter MessageFilter, handler MessageHandler) MessageHandlerId {
+	var fired uint32
+	handlerID := getNewHandlerID()
+	mm.addHandler(messageType, handlerID, func(msg Message) {
+		if filter != nil && !filter(msg) {
+			return
+		}
+		// The handler may be called again before it's removed, such as by a
+		// Dispatch from within the handler itself
+		if !atomic.CompareAndSwapUint32(&fired, 0, 1) {
+			return
+		}
 		mm.StopListen(messageType, handlerID)
+		handler(msg)
 	})
+	return handlerID
 }
 
 // StopListen removes a previously added handler from the listener queue
 func (mm *MessageManager) StopListen(messageType string, handlerID MessageHandlerId) {
+	mm.Lock()
+	defer mm.Unlock()
 	if mm.handlersToRemove == nil {
 		mm.handlersToRemove = make(map[string][]MessageHandlerId)
 	}
 	mm.handlersToRemove[messageType] = append(mm.handlersToRemove[messageType], handlerID)
 }
 
+// Subscribe is like Listen, but returns a Subscription to stop listening with, instead of the ID of the handler.
+func (mm *MessageManager) Subscribe(messageType string, handler MessageHandler) *Subscription {
+	return &Subscription{
+		mailbox:     mm,
+		messageType: messageType,
+		id:          mm.Listen(messageType, handler),
+	}
+}
+
+// SubscribeOnce is like ListenOnce, but returns a Subscription to stop listening with before the message arrives.
+func (mm *MessageManager) SubscribeOnce(messageType string, handler MessageHandler) *Subscription {
+	return &Subscription{
+		mailbox:     mm,
+		messageType: messageType,
+		id:          mm.ListenOnce(messageType, handler),
+	}
+}
+
+// SubscribeFilter is like ListenFilter, but returns a Subscription to stop listening with.
+func (mm *MessageManager) SubscribeFilter(messageType string, filter MessageFilter, handler MessageHandler) *Subscription {
+	return &Subscription{
+		mailbox:     mm,
+		messageType: messageType,
+		id:          mm.ListenFilter(messageType, filter, handler),
+	}
+}
+
+// SubscribeScene is like Subscribe, for handlers of the Scene the MessageManager belongs to, such as the ones a Scene
+// adds in its Setup or Show methods. They're removed by UnsubscribeScene, unlike the handlers of its Systems.
+func (mm *MessageManager) SubscribeScene(messageType string, handler MessageHandler) *Subscription {
+	s := mm.Subscribe(messageType, handler)
+	mm.Lock()
+	mm.sceneSubscriptions = append(mm.sceneSubscriptions, s)
+	mm.Unlock()
+	return s
+}
+
+// unsubscribeScene removes the handlers added with SubscribeScene.
+func (mm *MessageManager) unsubscribeScene() {
+	mm.Lock()
+	subscriptions := mm.sceneSubscriptions
+	mm.sceneSubscriptions = nil
+	mm.Unlock()
+	for _, s := range subscriptions {
+		s.Unsubscribe()
+	}
+}
+
+// Subscription is a handler listening to a MessageManager, as returned by Subscribe.
+type Subscription struct {
+	mailbox     *MessageManager
+	messageType string
+	id          MessageHandlerId
+}
+
+// Unsubscribe stops calling the handler of the Subscription. It's safe to call it more than once, and from within the
+// handler itself.
+func (s *Subscription) Unsubscribe() {
+	s.mailbox.StopListen(s.messageType, s.id)
+}
+
+// MessageType returns the type of the messages the Subscription listens to.
+func (s *Subscription) MessageType() string {
+	return s.messageType
+}
+
+// ID returns the ID of the handler of the Subscription, which can also be passed to StopListen.
+func (s *Subscription) ID() MessageHandlerId {
+	return s.id
+}
+
+// filterHandler wraps the handler so it's only called for messages passing the filter.
+func filterHandler(filter MessageFilter, handler MessageHandler) MessageHandler {
+	return func(msg Message) {
+		if filter(msg) {
+			handler(msg)
+		}
+	}
+}
+
 // Will deleted all queued handlers that are scheduled for removal due to StopListen()
 func (mm *MessageManager) clearRemovedHandlers() {
 	for messageType, handlerList := range mm.handlersToRemove {
@@ -122,6 +242,9 @@ func (mm *MessageManager) removeHandler(messageType string, handlerID MessageHan
 type WindowResizeMessage struct {
 	OldWidth, OldHeight int
 	NewWidth, NewHeight int
+	// GameWidth and GameHeight are the size of the game after the resize, as returned by GameWidth and GameHeight.
+	// It only changes along with the window when the ScaleMode is ScaleExpand.
+	GameWidth, GameHeight float32
 }
 
 // Type returns the type of the current object "WindowResizeMessage"
@@ -136,3 +259,55 @@ type TextMessage struct {
 
 // Type returns the type of the message, "TextMessage"
 func (TextMessage) Type() string { return "TextMessage" }
+
+// TextEditMessage is a message that is dispatched while the user composes text with an input method editor (IME),
+// such as when typing Chinese or Japanese. Text is the text being composed, which isn't typed yet; once the user
+// commits it, its characters are dispatched as TextMessages. An empty Text means the composition ended. Start and
+// Length select the part of Text being edited. It's only dispatched on platforms that support it.
+type TextEditMessage struct {
+	Text          string
+	Start, Length int
+}
+
+// Type returns the type of the message, "TextEditMessage"
+func (TextEditMessage) Type() string { return "TextEditMessage" }
+
+// GamepadConnectedMessage is sent when a controller is plugged in for a registered Gamepad that wasn't connected.
+type GamepadConnectedMessage struct {
+	// Name is the name the Gamepad was registered with
+	Name string
+}
+
+// Type returns the type of the message, "GamepadConnectedMessage"
+func (GamepadConnectedMessage) Type() string { return "GamepadConnectedMessage" }
+
+// GamepadDisconnectedMessage is sent when the controller of a registered Gamepad is unplugged. The Gamepad stays
+// registered, and is connected again when a new controller is plugged in.
+type GamepadDisconnectedMessage struct {
+	// Name is the name the Gamepad was registered with
+	Name string
+}
+
+// Type returns the type of the message, "GamepadDisconnectedMessage"
+func (GamepadDisconnectedMessage) Type() string { return "GamepadDisconnectedMessage" }
+
+// AssetReloadedMessage is dispatched when a resource was loaded again because its file changed, with the HotReload
+// of the RunOptions. The resource is reloaded in place where the FileLoader allows it, such as textures, but Systems
+// that copied something out of the resource, such as the tiles of a TMX level, should get it again.
+type AssetReloadedMessage struct {
+	// URL is the url the resource was loaded with
+	URL string
+}
+
+// Type returns the type of the message, "AssetReloadedMessage"
+func (AssetReloadedMessage) Type() string { return "AssetReloadedMessage" }
+
+// SceneSwitchMessage is dispatched to the Mailbox of the current Scene right before another Scene replaces it, with
+// SetScene, PushScene or PopScene.
+type SceneSwitchMessage struct {
+	// To is the Scene becoming the current one
+	To Scene
+}
+
+// Type returns the type of the message, "SceneSwitchMessage"
+func (SceneSwitchMessage) Type() string { return "SceneSwitchMessage" }
@@ -109,3 +109,146 @@ func TestMessageRemoveNotExistAndMultipleMessageTypes(t *testing.T) {
 		t.Error("Message counter should be 1. Only one message was dispatched to it")
 	}
 }
+
+func TestMessageSubscription(t *testing.T) {
+	mailbox := &MessageManager{}
+	msg := testMessageCounter{}
+	var sub *Subscription
+	sub = mailbox.Subscribe("testMessageCounter", func(message Message) {
+		message.(*testMessageCounter).counter++
+		sub.Unsubscribe()
+	})
+	other := mailbox.Subscribe("testMessageCounter", func(message Message) {
+		message.(*testMessageCounter).counter2++
+	})
+	if sub.MessageType() != "testMessageCounter" {
+		t.Errorf("Subscription should listen to testMessageCounter, got %v", sub.MessageType())
+	}
+	if sub.ID() == other.ID() {
+		t.Error("Subscriptions should have different IDs")
+	}
+
+	mailbox.Dispatch(&msg)
+	mailbox.Dispatch(&msg)
+	if msg.counter != 1 {
+		t.Errorf("Message should have been received exactly 1 times since it unsubscribed itself, got %v", msg.counter)
+	}
+	if msg.counter2 != 2 {
+		t.Errorf("Message should have been received 2 times by the second subscription, got %v", msg.counter2)
+	}
+
+	other.Unsubscribe()
+	other.Unsubscribe()
+	mailbox.Dispatch(&msg)
+	if msg.counter2 != 2 {
+		t.Errorf("Message should not be received after unsubscribing, got %v", msg.counter2)
+	}
+}
+
+func TestUnsubscribeScene(t *testing.T) {
+	if err := UnsubscribeScene("notRegisteredScene"); err == nil {
+		t.Error("Unsubscribing a scene that isn't registered should fail")
+	}
+
+	s := &testScene2{}
+	RegisterScene(s)
+	wrapper := getSceneWrapper(s)
+	wrapper.mailbox = &MessageManager{}
+	defer func() { wrapper.mailbox = nil }()
+
+	msg := testMessageCounter{}
+	wrapper.mailbox.SubscribeScene("testMessageCounter", func(message Message) {
+		message.(*testMessageCounter).counter++
+	})
+	// Systems listen without SubscribeScene
+	wrapper.mailbox.Listen("testMessageCounter", func(message Message) {
+		message.(*testMessageCounter).counter2++
+	})
+	if err := UnsubscribeScene(s.Type()); err != nil {
+		t.Fatalf("Unsubscribing a registered scene should not fail, got %v", err)
+	}
+	wrapper.mailbox.Dispatch(&msg)
+	if msg.counter != 0 {
+		t.Errorf("Message should not be received after the scene unsubscribed, got %v", msg.counter)
+	}
+	if msg.counter2 != 1 {
+		t.Errorf("Handlers of the Systems should be kept, got %v", msg.counter2)
+	}
+}
+
+func TestMessageListenFilter(t *testing.T) {
+	mailbox := &MessageManager{}
+	even := func(message Message) bool {
+		return message.(*testMessageCounter).counter2%2 == 0
+	}
+	msg := testMessageCounter{}
+	mailbox.ListenFilter("testMessageCounter", even, func(message Message) {
+		message.(*testMessageCounter).counter++
+	})
+	for i := 0; i < 4; i++ {
+		msg.counter2 = i
+		mailbox.Dispatch(&msg)
+	}
+	if msg.counter != 2 {
+		t.Errorf("Message should have been received 2 times since only even messages pass the filter, got %v", msg.counter)
+	}
+}
+
+func TestMessageListenOnceFilter(t *testing.T) {
+	mailbox := &MessageManager{}
+	msg := testMessageCounter{}
+	mailbox.ListenOnceFilter("testMessageCounter", func(message Message) bool {
+		return message.(*testMessageCounter).counter2 == 2
+	}, func(message Message) {
+		message.(*testMessageCounter).counter++
+	})
+	for i := 0; i < 4; i++ {
+		msg.counter2 = i
+		mailbox.Dispatch(&msg)
+	}
+	if msg.counter != 1 {
+		t.Errorf("Message should have been received exactly 1 times, the first time it passed the filter, got %v", msg.counter)
+	}
+	msg.counter2 = 2
+	mailbox.Dispatch(&msg)
+	if msg.counter != 1 {
+		t.Errorf("Message should not be received again after it passed the filter once, got %v", msg.counter)
+	}
+}
+
+func TestMessageListenOnceNested(t *testing.T) {
+	mailbox := &MessageManager{}
+	msg := testMessageCounter{}
+	mailbox.ListenOnce("testMessageCounter", func(message Message) {
+		message.(*testMessageCounter).counter++
+		mailbox.Dispatch(message)
+	})
+	mailbox.Dispatch(&msg)
+	if msg.counter != 1 {
+		t.Errorf("Message should have been received exactly 1 times, even when dispatched from the handler, got %v", msg.counter)
+	}
+}
+
+func TestMessageSubscribeOnce(t *testing.T) {
+	mailbox := &MessageManager{}
+	msg := testMessageCounter{}
+	sub := mailbox.SubscribeOnce("testMessageCounter", func(message Message) {
+		message.(*testMessageCounter).counter++
+	})
+	filtered := mailbox.SubscribeFilter("testMessageCounter", func(Message) bool { return true }, func(message Message) {
+		message.(*testMessageCounter).counter2++
+	})
+	sub.Unsubscribe()
+	mailbox.Dispatch(&msg)
+	if msg.counter != 0 {
+		t.Errorf("Message should not be received after unsubscribing before it arrived, got %v", msg.counter)
+	}
+	if msg.counter2 != 1 {
+		t.Errorf("Message should have been received 1 times by the filtered subscription, got %v", msg.counter2)
+	}
+	filtered.Unsubscribe()
+	mailbox.Dispatch(&msg)
+	if msg.counter2 != 1 {
+		t.Errorf("Message should not be received after unsubscribing, got %v", msg.counter2)
+	}
+}
@@ -0,0 +1,124 @@
+package engo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestFiles writes the files, by path relative to dir, with their content.
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+			t.Fatalf("failed to create temp directory for testing, error: %v", err)
+		}
+		if err := ioutil.WriteFile(file, []byte(content), 0666); err != nil {
+			t.Fatalf("failed to create temp file for testing, file: %v, error: %v", file, err)
+		}
+	}
+}
+
+// readTestFile returns the content of the file opened through Files, or the error opening it.
+func readTestFile(url string) string {
+	f, err := Files.Open(url)
+	if err != nil {
+		return err.Error()
+	}
+	defer f.Close()
+	content, _ := ioutil.ReadAll(f)
+	return string(content)
+}
+
+// Test overriding the files of the game with mounts, and falling through to the game.
+func TestFilesMount(t *testing.T) {
+	Run(RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, &assetTestScene{})
+
+	dir, err := ioutil.TempDir(".", "testing")
+	if err != nil {
+		t.Fatalf("failed to create temp directory for testing, error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir, map[string]string{
+		"game/a.txt":   "game a",
+		"game/b.txt":   "game b",
+		"game/c.txt":   "game c",
+		"mod/a.txt":    "mod a",
+		"mod/b.txt":    "mod b",
+		"patch/a.txt":  "patch a",
+		"dlc/x.txt":    "dlc x",
+		"pack/c.txt":   "pack c",
+		"pack/sub/d.t": "pack d",
+	})
+
+	Files.SetRoot(filepath.Join(dir, "game"))
+	defer func() {
+		for _, root := range []string{"mod", "patch", "dlc", "pack.zip"} {
+			Files.Unmount(filepath.Join(dir, root))
+		}
+	}()
+
+	if err = Files.Mount(filepath.Join(dir, "patch"), "", 0); err != nil {
+		t.Fatalf("could not mount a directory, error: %v", err)
+	}
+	if err = Files.Mount(filepath.Join(dir, "mod"), "", 0); err != nil {
+		t.Fatalf("could not mount a directory, error: %v", err)
+	}
+	if got := readTestFile("a.txt"); got != "mod a" {
+		t.Errorf("the last mount with the same priority should win, expected=%q ; got=%q", "mod a", got)
+	}
+	if got := readTestFile("b.txt"); got != "mod b" {
+		t.Errorf("mounts should override the game, expected=%q ; got=%q", "mod b", got)
+	}
+	if got := readTestFile("c.txt"); got != "game c" {
+		t.Errorf("files missing from the mounts should fall through to the game, expected=%q ; got=%q", "game c", got)
+	}
+
+	if err = Files.Mount(filepath.Join(dir, "patch"), "", 1); err != nil {
+		t.Fatalf("could not mount a directory, error: %v", err)
+	}
+	if got := readTestFile("a.txt"); got != "patch a" {
+		t.Errorf("mounts with a higher priority should win, expected=%q ; got=%q", "patch a", got)
+	}
+
+	if err = Files.Mount(filepath.Join(dir, "dlc"), "dlc", 0); err != nil {
+		t.Fatalf("could not mount a directory, error: %v", err)
+	}
+	if got := readTestFile("dlc/x.txt"); got != "dlc x" {
+		t.Errorf("prefixed urls should be resolved within the mount, expected=%q ; got=%q", "dlc x", got)
+	}
+	if got := readTestFile("x.txt"); got == "dlc x" {
+		t.Error("urls without the prefix should not be resolved within the mount")
+	}
+
+	archive, err := os.Create(filepath.Join(dir, "pack.zip"))
+	if err != nil {
+		t.Fatalf("failed to create temp file for testing, error: %v", err)
+	}
+	err = PackArchive(archive, filepath.Join(dir, "pack"))
+	archive.Close()
+	if err != nil {
+		t.Fatalf("could not pack the archive, error: %v", err)
+	}
+	if err = Files.Mount(filepath.Join(dir, "pack.zip"), "", 0); err != nil {
+		t.Fatalf("could not mount an archive, error: %v", err)
+	}
+	if got := readTestFile("c.txt"); got != "pack c" {
+		t.Errorf("archive mounts should override the game, expected=%q ; got=%q", "pack c", got)
+	}
+	if got := readTestFile("sub/d.t"); got != "pack d" {
+		t.Errorf("files in subdirectories of archives should be found, expected=%q ; got=%q", "pack d", got)
+	}
+
+	Files.Unmount(filepath.Join(dir, "pack.zip"))
+	if got := readTestFile("c.txt"); got != "game c" {
+		t.Errorf("unmounted archives should not be used, expected=%q ; got=%q", "game c", got)
+	}
+	if err = Files.Mount(filepath.Join(dir, "missing.zip"), "", 0); err == nil {
+		t.Error("mounting an archive that doesn't exist should fail")
+	}
+}
@@ -0,0 +1,63 @@
+package engo
+
+import "github.com/klopsch/ecs"
+
+var paused bool
+
+// Pauser is an optional interface a System can implement, to tell whether it
+// should stop being updated while the game is paused. Systems that don't
+// implement it are paused, so only systems that have to keep running, such as
+// the rendering or the user interface of a pause menu, need it.
+type Pauser interface {
+	// Pausable returns whether the System stops being updated while the game
+	// is paused.
+	Pausable() bool
+}
+
+// SetPaused pauses or resumes the game. While paused, only the systems of the
+// current Scene that implement Pauser and return false from Pausable are
+// updated, so the frozen frame is still drawn. They are passed the same dt as
+// when the game isn't paused, the time spent paused is never made up for.
+//
+// If the Updater of the Scene isn't an *ecs.World, it's only updated while
+// paused if it implements Pauser itself.
+func SetPaused(p bool) {
+	paused = p
+}
+
+// Paused returns whether the game is paused.
+func Paused() bool {
+	return paused
+}
+
+// shouldUpdate returns whether a System or Updater should be updated, taking
+// into account whether the game is paused.
+func shouldUpdate(u interface{}) bool {
+	if !paused {
+		return true
+	}
+	p, ok := u.(Pauser)
+	return ok && !p.Pausable()
+}
+
+// updateCurrentScene updates the Updater of the current Scene, taking into
+// account whether the game is paused.
+func updateCurrentScene(dt float32) {
+	if !paused {
+		currentUpdater.Update(dt)
+		return
+	}
+
+	w, ok := currentUpdater.(*ecs.World)
+	if !ok {
+		if shouldUpdate(currentUpdater) {
+			currentUpdater.Update(dt)
+		}
+		return
+	}
+	for _, system := range w.Systems() {
+		if shouldUpdate(system) {
+			system.Update(dt)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package engo
+
+import (
+	"testing"
+
+	"github.com/klopsch/ecs"
+)
+
+type countingSystem struct {
+	updates  int
+	pausable bool
+}
+
+func (c *countingSystem) Update(float32) { c.updates++ }
+
+func (*countingSystem) Remove(ecs.BasicEntity) {}
+
+func (c *countingSystem) Pausable() bool { return c.pausable }
+
+type gameplaySystem struct {
+	updates int
+}
+
+func (g *gameplaySystem) Update(float32) { g.updates++ }
+
+func (*gameplaySystem) Remove(ecs.BasicEntity) {}
+
+func TestSetPaused(t *testing.T) {
+	w := &ecs.World{}
+	gameplay := &gameplaySystem{}
+	marked := &countingSystem{pausable: true}
+	ui := &countingSystem{pausable: false}
+	w.AddSystem(gameplay)
+	w.AddSystem(marked)
+	w.AddSystem(ui)
+	currentUpdater = w
+	defer SetPaused(false)
+
+	updateScene(0.1)
+	if gameplay.updates != 1 || marked.updates != 1 || ui.updates != 1 {
+		t.Error("All systems should be updated when not paused")
+	}
+
+	SetPaused(true)
+	if !Paused() {
+		t.Error("Paused should return true after SetPaused(true)")
+	}
+	updateScene(0.1)
+	if gameplay.updates != 1 || marked.updates != 1 {
+		t.Error("Pausable systems should not be updated while paused")
+	}
+	if ui.updates != 2 {
+		t.Error("Systems that are not pausable should be updated while paused")
+	}
+
+	SetPaused(false)
+	updateScene(0.1)
+	if gameplay.updates != 2 || marked.updates != 2 || ui.updates != 3 {
+		t.Error("All systems should be updated after resuming")
+	}
+}
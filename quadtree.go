@@ -311,29 +311,37 @@ func (qt *Quadtree) Remove(item AABBer) {
 
 // Retrieve returns all objects that could collide with the given bounding box
 func (qt *quadtreeNode) Retrieve(pRect AABB) []AABBer {
-	index := qt.getIndex(pRect)
+	return qt.retrieve(pRect, nil)
+}
 
-	// Array with all detected objects
-	result := make([]AABBer, len(qt.Objects))
-	for i, o := range qt.Objects {
-		result[i] = o.Value
+// retrieve appends all objects that could collide with the given bounding box to result
+func (qt *quadtreeNode) retrieve(pRect AABB, result []AABBer) []AABBer {
+	for _, o := range qt.Objects {
+		result = append(result, o.Value)
 	}
-
-	//if we have subnodes ...
-	if qt.hasNodes {
-		//if pRect fits into a subnode ..
-		if index != -1 {
-			result = append(result, qt.Nodes[index].Retrieve(pRect)...)
-		} else {
-			//if pRect does not fit into a subnode, check it against all subnodes
-			for i := 0; i < 4; i++ {
-				result = append(result, qt.Nodes[i].Retrieve(pRect)...)
-			}
-		}
+	if !qt.hasNodes {
+		return result
 	}
 
+	// The objects of a subnode are strictly on its side of both midpoints, so only the subnodes on the sides
+	// pRect reaches can hold objects colliding with it
+	horzMidpoint := qt.Bounds.Min.X + (aabbWidth(qt.Bounds) / 2)
+	vertMidpoint := qt.Bounds.Min.Y + (aabbHeight(qt.Bounds) / 2)
+	left, right := pRect.Min.X < horzMidpoint, pRect.Max.X > horzMidpoint
+	top, bottom := pRect.Min.Y < vertMidpoint, pRect.Max.Y > vertMidpoint
+	if right && top {
+		result = qt.Nodes[0].retrieve(pRect, result)
+	}
+	if left && top {
+		result = qt.Nodes[1].retrieve(pRect, result)
+	}
+	if left && bottom {
+		result = qt.Nodes[2].retrieve(pRect, result)
+	}
+	if right && bottom {
+		result = qt.Nodes[3].retrieve(pRect, result)
+	}
 	return result
-
 }
 
 // Retrieve returns all objects that could collide with the given bounding box and passing the given filter function.
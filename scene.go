@@ -45,6 +45,15 @@ type Exiter interface {
 	Exit()
 }
 
+// Disposable is an optional interface a Scene can implement, to be freed like with FreeScene as soon as it's left
+// with SetScene or PopScene, which unloads the resources loaded for it. Other Scenes keep their world and resources
+// when they're left, so switching back to them is instant and calls their Show method, until they're freed with
+// FreeScene.
+type Disposable interface {
+	// Disposable returns whether the Scene is freed when it's left.
+	Disposable() bool
+}
+
 // Updater is an interface for what handles your game's Update during each frame.
 // typically, this will be an *ecs.World, but you can implement your own Updater
 // and use engo without using engo's ecs
@@ -52,10 +61,56 @@ type Updater interface {
 	Update(float32)
 }
 
+// Unloader is an optional interface a Scene can implement, indicating it'll have custom behavior
+// whenever the Scene is freed with FreeScene.
+type Unloader interface {
+	// Unload is called when the Scene is freed, and should release the resources it loaded without Files, as
+	// the ones loaded with Files are unloaded once it returns
+	Unload()
+}
+
 type sceneWrapper struct {
 	scene   Scene
 	update  Updater
 	mailbox *MessageManager
+	// preloaded is true when the Scene was set up by PreloadScene, and hasn't been shown yet
+	preloaded bool
+}
+
+// create creates a new Updater and MessageManager for the Scene.
+func (w *sceneWrapper) create() {
+	t := reflect.Indirect(reflect.ValueOf(currentUpdater)).Type()
+	v := reflect.New(t)
+	w.update = v.Interface().(Updater)
+	w.mailbox = &MessageManager{}
+}
+
+// setup calls Preload and Setup of the Scene, with the resources they load belonging to it. The Mailbox has to be
+// the one of the Scene.
+func (w *sceneWrapper) setup() {
+	Files.loadIn(w.scene.Type(), func() {
+		w.scene.Preload()
+
+		w.mailbox.listeners = make(map[string][]HandlerIDPair)
+
+		w.scene.Setup(w.update)
+	})
+}
+
+// getSceneWrapper returns the wrapper of the Scene, registering it if needed.
+func getSceneWrapper(s Scene) *sceneWrapper {
+	sceneMutex.RLock()
+	wrapper, registered := scenes[s.Type()]
+	sceneMutex.RUnlock()
+
+	if !registered {
+		RegisterScene(s)
+
+		sceneMutex.RLock()
+		wrapper = scenes[s.Type()]
+		sceneMutex.RUnlock()
+	}
+	return wrapper
 }
 
 // CurrentScene returns the SceneWorld that is currently active
@@ -65,35 +120,48 @@ func CurrentScene() Scene {
 
 // SetScene sets the currentScene to the given Scene, and
 // optionally forcing to create a new ecs.World that goes with it.
+// The Scenes on the scene stack are removed from it, but keep their worlds.
+// The previous Scene keeps its world and resources as well, until it's freed
+// with FreeScene. The Disposable ones among them are freed right away.
 func SetScene(s Scene, forceNewWorld bool) {
+	left := SceneStack()
+	sceneStack = nil
+	switchScene(s, forceNewWorld)
+	for _, scene := range left {
+		leaveScene(scene)
+	}
+}
+
+// leaveScene frees the Scene, which isn't shown anymore, if it's Disposable and isn't the current Scene again.
+func leaveScene(s Scene) {
+	if currentScene != nil && s.Type() == currentScene.Type() {
+		return
+	}
+	if d, ok := s.(Disposable); ok && d.Disposable() {
+		FreeScene(s.Type())
+	}
+}
+
+// switchScene hides the current Scene and makes the given Scene the current one, without changing the scene stack.
+func switchScene(s Scene, forceNewWorld bool) {
 	// Break down currentScene
 	if currentScene != nil {
+		if Mailbox != nil {
+			Mailbox.Dispatch(SceneSwitchMessage{To: s})
+		}
 		if hider, ok := currentScene.(Hider); ok {
 			hider.Hide()
 		}
 	}
 
 	// Register Scene if needed
-	sceneMutex.RLock()
-	wrapper, registered := scenes[s.Type()]
-	sceneMutex.RUnlock()
-
-	if !registered {
-		RegisterScene(s)
-
-		sceneMutex.RLock()
-		wrapper = scenes[s.Type()]
-		sceneMutex.RUnlock()
-	}
+	wrapper := getSceneWrapper(s)
 
 	// Initialize new Scene / World if needed
 	var doSetup bool
 
 	if wrapper.update == nil || forceNewWorld {
-		t := reflect.Indirect(reflect.ValueOf(currentUpdater)).Type()
-		v := reflect.New(t)
-		wrapper.update = v.Interface().(Updater)
-		wrapper.mailbox = &MessageManager{}
+		wrapper.create()
 
 		doSetup = true
 	}
@@ -105,11 +173,12 @@ func SetScene(s Scene, forceNewWorld bool) {
 
 	// doSetup is true whenever we're (re)initializing the Scene
 	if doSetup {
-		s.Preload()
-
-		wrapper.mailbox.listeners = make(map[string][]HandlerIDPair)
-
-		s.Setup(wrapper.update)
+		// A preloaded world was replaced, so the new one is shown like any other
+		wrapper.preloaded = false
+		wrapper.setup()
+	} else if wrapper.preloaded {
+		// The Scene is shown for the first time, so it isn't shown again
+		wrapper.preloaded = false
 	} else {
 		if shower, ok := currentScene.(Shower); ok {
 			shower.Show()
@@ -117,6 +186,84 @@ func SetScene(s Scene, forceNewWorld bool) {
 	}
 }
 
+// PreloadScene calls Preload and Setup of the Scene without making it the current Scene, so switching to it later
+// with SetScene (without forcing a new world) is instant. Nothing happens if the Scene was already set up. As loading
+// resources may send them to the GPU, it has to be called from the main loop, for example from a System or while
+// showing a loading screen.
+func PreloadScene(s Scene) {
+	wrapper := getSceneWrapper(s)
+	if wrapper.update != nil {
+		return
+	}
+
+	wrapper.create()
+
+	// The Scene listens to its own Mailbox during Setup
+	current := Mailbox
+	Mailbox = wrapper.mailbox
+	wrapper.setup()
+	Mailbox = current
+
+	wrapper.preloaded = true
+}
+
+// ScenePreloaded returns whether the Scene with the given name is set up, so switching to it is instant.
+func ScenePreloaded(name string) bool {
+	sceneMutex.RLock()
+	defer sceneMutex.RUnlock()
+	wrapper, ok := scenes[name]
+	return ok && wrapper.update != nil
+}
+
+// FreeScene discards the world of the Scene with the given name, and calls its Unload method if it's an Unloader.
+// The resources loaded for it, while it was set up or current, are unloaded as well, except the ones loaded with
+// Files.LoadGlobal, kept with Files.Keep, or loaded for another Scene too.
+// The next time the Scene is set, it's set up again. The current Scene and the Scenes on the scene stack can't be
+// freed. SetScene and PopScene free the Disposable Scenes they leave already.
+func FreeScene(name string) error {
+	sceneMutex.RLock()
+	wrapper, ok := scenes[name]
+	sceneMutex.RUnlock()
+	if !ok {
+		return fmt.Errorf("scene not registered: %s", name)
+	}
+	if wrapper.scene == currentScene {
+		return fmt.Errorf("the current scene can't be freed: %s", name)
+	}
+	if stacked(wrapper.scene) {
+		return fmt.Errorf("a scene on the scene stack can't be freed: %s", name)
+	}
+	if wrapper.update == nil {
+		return nil
+	}
+
+	wrapper.update = nil
+	wrapper.mailbox.unsubscribeScene()
+	wrapper.mailbox = nil
+	wrapper.preloaded = false
+	if unloader, ok := wrapper.scene.(Unloader); ok {
+		unloader.Unload()
+	}
+	Files.unloadScope(name)
+	return nil
+}
+
+// UnsubscribeScene removes the handlers the Scene with the given name added to its Mailbox with SubscribeScene, so
+// none of them are called anymore, even if something still dispatches to it. The handlers of its Systems are kept, so
+// the Scene keeps a working world, but has to subscribe again if it's shown again, for example in its Show method.
+func UnsubscribeScene(name string) error {
+	sceneMutex.RLock()
+	wrapper, ok := scenes[name]
+	sceneMutex.RUnlock()
+	if !ok {
+		return fmt.Errorf("scene not registered: %s", name)
+	}
+	if wrapper.mailbox != nil {
+		wrapper.mailbox.unsubscribeScene()
+	}
+	return nil
+}
+
 // RegisterScene registers the `Scene`, so it can later be used by `SetSceneByName`
 func RegisterScene(s Scene) {
 	sceneMutex.RLock()
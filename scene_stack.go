@@ -0,0 +1,123 @@
+package engo
+
+import (
+	"errors"
+
+	"github.com/klopsch/ecs"
+)
+
+// Drawer is an optional interface a System can implement, to tell whether it draws the Scene. When a Scene is kept
+// drawn below the current one on the scene stack, only its Systems that implement Drawer and return true from Draws
+// are updated.
+type Drawer interface {
+	// Draws returns whether the System draws the Scene.
+	Draws() bool
+}
+
+// stackedScene is a Scene below the current one on the scene stack.
+type stackedScene struct {
+	wrapper *sceneWrapper
+	// drawn is whether the Scene keeps being drawn below the Scene above it
+	drawn bool
+}
+
+var (
+	// sceneStack holds the Scenes below the current one, the lowest first.
+	sceneStack []stackedScene
+	// overlay is set while updating a Scene which is drawn over the Scenes below it.
+	overlay bool
+)
+
+// PushScene makes s the current Scene, like SetScene without forcing a new world, but keeps the current Scene on the
+// scene stack, so PopScene returns to it as it was, such as for a pause menu over the game. The current Scene is
+// hidden, and Scenes on the stack are frozen: they aren't updated and their Mailbox doesn't receive anything, so their
+// worlds and the resources they loaded are kept as they are.
+//
+// If drawBelow is true, the current Scene keeps being drawn below s, such as for a translucent pause menu over the
+// frozen game, by updating only its Systems that are a Drawer. A Scene lower on the stack is only drawn when the
+// Scenes above it are drawn as well, and the Scenes are drawn from the lowest to the current one, which is drawn
+// over them.
+func PushScene(s Scene, drawBelow bool) {
+	if currentScene != nil {
+		sceneStack = append(sceneStack, stackedScene{wrapper: getSceneWrapper(currentScene), drawn: drawBelow})
+	}
+	switchScene(s, false)
+}
+
+// PopScene hides the current Scene, and makes the Scene below it on the scene stack the current Scene again, which is
+// shown like it is by SetScene. The popped Scene keeps its world, so pushing it again is instant; free it with
+// FreeScene if it isn't used anymore, or make it Disposable. It returns an error if there is no Scene on the stack.
+func PopScene() error {
+	if len(sceneStack) == 0 {
+		return errors.New("no scene below the current scene")
+	}
+	popped := currentScene
+	below := sceneStack[len(sceneStack)-1]
+	sceneStack = sceneStack[:len(sceneStack)-1]
+	switchScene(below.wrapper.scene, false)
+	leaveScene(popped)
+	return nil
+}
+
+// SceneStack returns the Scenes on the scene stack, from the lowest to the current Scene.
+func SceneStack() []Scene {
+	stack := make([]Scene, 0, len(sceneStack)+1)
+	for _, s := range sceneStack {
+		stack = append(stack, s.wrapper.scene)
+	}
+	if currentScene != nil {
+		stack = append(stack, currentScene)
+	}
+	return stack
+}
+
+// Overlay returns whether the Scene being updated is drawn over the Scenes below it on the scene stack, in which case
+// it shouldn't clear the screen before drawing.
+func Overlay() bool {
+	return overlay
+}
+
+// stacked returns whether the Scene is on the scene stack below the current one.
+func stacked(s Scene) bool {
+	for _, below := range sceneStack {
+		if below.wrapper.scene == s {
+			return true
+		}
+	}
+	return false
+}
+
+// drawStack draws the Scenes on the stack which are kept drawn below the current one, from the lowest, and leaves
+// overlay set if the current Scene is drawn over them.
+func drawStack(dt float32) {
+	first := len(sceneStack)
+	for first > 0 && sceneStack[first-1].drawn {
+		first--
+	}
+
+	current := Mailbox
+	for i, below := range sceneStack[first:] {
+		overlay = i > 0
+		Mailbox = below.wrapper.mailbox
+		drawScene(below.wrapper.update, dt)
+	}
+	Mailbox = current
+	overlay = first < len(sceneStack)
+}
+
+// drawScene updates the Systems of the Updater which are a Drawer, or the Updater itself if it isn't an *ecs.World
+// but is a Drawer.
+func drawScene(u Updater, dt float32) {
+	w, ok := u.(*ecs.World)
+	if !ok {
+		if d, ok := u.(Drawer); ok && d.Draws() {
+			u.Update(dt)
+		}
+		return
+	}
+	for _, system := range w.Systems() {
+		if d, ok := system.(Drawer); ok && d.Draws() {
+			system.Update(dt)
+		}
+	}
+}
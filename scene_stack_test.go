@@ -0,0 +1,108 @@
+package engo
+
+import (
+	"testing"
+
+	"github.com/klopsch/ecs"
+)
+
+type drawingSystem struct {
+	updates  int
+	overlaid []bool
+}
+
+func (d *drawingSystem) Update(float32) {
+	d.updates++
+	d.overlaid = append(d.overlaid, Overlay())
+}
+
+func (*drawingSystem) Remove(ecs.BasicEntity) {}
+
+func (*drawingSystem) Draws() bool { return true }
+
+type stackScene struct {
+	name     string
+	drawing  *drawingSystem
+	gameplay *gameplaySystem
+	shown    int
+}
+
+func (*stackScene) Preload() {}
+
+func (s *stackScene) Setup(u Updater) {
+	w := u.(*ecs.World)
+	s.drawing = &drawingSystem{}
+	s.gameplay = &gameplaySystem{}
+	w.AddSystem(s.drawing)
+	w.AddSystem(s.gameplay)
+}
+
+func (s *stackScene) Type() string { return s.name }
+
+func (s *stackScene) Show() { s.shown++ }
+
+func TestSceneStack(t *testing.T) {
+	game := &stackScene{name: "stackGame"}
+	Run(RunOptions{
+		NoRun:        true,
+		HeadlessMode: true,
+	}, game)
+	defer SetScene(&testScene{}, false)
+
+	pause := &stackScene{name: "stackPause"}
+	PushScene(pause, true)
+	if CurrentScene() != pause {
+		t.Error("PushScene should make the pushed scene the current scene")
+	}
+	if stack := SceneStack(); len(stack) != 2 || stack[0] != game || stack[1] != pause {
+		t.Errorf("SceneStack should hold the game below the pause menu, got: %v", stack)
+	}
+
+	updateScene(0.1)
+	if game.gameplay.updates != 0 {
+		t.Error("Scenes on the stack should be frozen")
+	}
+	if game.drawing.updates != 1 || game.drawing.overlaid[0] {
+		t.Error("The drawers of a scene kept drawn should be updated, without being drawn over another scene")
+	}
+	if pause.gameplay.updates != 1 || pause.drawing.updates != 1 || !pause.drawing.overlaid[0] {
+		t.Error("The current scene should be updated, and drawn over the scene below it")
+	}
+
+	if err := FreeScene("stackGame"); err == nil {
+		t.Error("A scene on the stack should not be freed")
+	}
+
+	options := &stackScene{name: "stackOptions"}
+	PushScene(options, false)
+	updateScene(0.1)
+	if game.drawing.updates != 1 || pause.drawing.updates != 1 {
+		t.Error("Scenes below a scene which isn't kept drawn should not be drawn")
+	}
+	if options.drawing.overlaid[0] {
+		t.Error("A scene over a scene which isn't kept drawn should not be drawn over it")
+	}
+
+	if err := PopScene(); err != nil {
+		t.Errorf("PopScene should return to the pause menu, got: %v", err)
+	}
+	if CurrentScene() != pause || pause.shown != 1 {
+		t.Error("PopScene should show the scene below the popped one again")
+	}
+	if err := PopScene(); err != nil || CurrentScene() != game {
+		t.Error("PopScene should return to the game")
+	}
+	updateScene(0.1)
+	if game.gameplay.updates != 1 || game.drawing.overlaid[1] {
+		t.Error("The game should be updated again once it's the current scene")
+	}
+	if err := PopScene(); err == nil {
+		t.Error("PopScene should fail without a scene on the stack")
+	}
+
+	PushScene(pause, true)
+	SetScene(options, false)
+	if len(SceneStack()) != 1 {
+		t.Error("SetScene should clear the scene stack")
+	}
+}
@@ -0,0 +1,169 @@
+package engo
+
+import (
+	"sort"
+
+	"github.com/klopsch/engo/math"
+)
+
+// QuadraticBezier returns the point at t, from 0 to 1, on the quadratic Bezier curve from p0 to p2 with the control
+// point p1.
+func QuadraticBezier(p0, p1, p2 Point, t float32) Point {
+	u := 1 - t
+	return Point{
+		X: u*u*p0.X + 2*u*t*p1.X + t*t*p2.X,
+		Y: u*u*p0.Y + 2*u*t*p1.Y + t*t*p2.Y,
+	}
+}
+
+// QuadraticBezierTangent returns the derivative at t of the curve described by QuadraticBezier. It points in the
+// direction the curve is going, and its length is the speed at t.
+func QuadraticBezierTangent(p0, p1, p2 Point, t float32) Point {
+	u := 1 - t
+	return Point{
+		X: 2*u*(p1.X-p0.X) + 2*t*(p2.X-p1.X),
+		Y: 2*u*(p1.Y-p0.Y) + 2*t*(p2.Y-p1.Y),
+	}
+}
+
+// CubicBezier returns the point at t, from 0 to 1, on the cubic Bezier curve from p0 to p3 with the control points
+// p1 and p2.
+func CubicBezier(p0, p1, p2, p3 Point, t float32) Point {
+	u := 1 - t
+	a, b, c, d := u*u*u, 3*u*u*t, 3*u*t*t, t*t*t
+	return Point{
+		X: a*p0.X + b*p1.X + c*p2.X + d*p3.X,
+		Y: a*p0.Y + b*p1.Y + c*p2.Y + d*p3.Y,
+	}
+}
+
+// CubicBezierTangent returns the derivative at t of the curve described by CubicBezier.
+func CubicBezierTangent(p0, p1, p2, p3 Point, t float32) Point {
+	u := 1 - t
+	a, b, c := 3*u*u, 6*u*t, 3*t*t
+	return Point{
+		X: a*(p1.X-p0.X) + b*(p2.X-p1.X) + c*(p3.X-p2.X),
+		Y: a*(p1.Y-p0.Y) + b*(p2.Y-p1.Y) + c*(p3.Y-p2.Y),
+	}
+}
+
+// CatmullRom returns the point at t, from 0 to 1, on the Catmull-Rom spline segment from p1 to p2. The points p0 and
+// p3 before and after the segment shape its tangents, so consecutive segments join smoothly.
+func CatmullRom(p0, p1, p2, p3 Point, t float32) Point {
+	t2, t3 := t*t, t*t*t
+	return Point{
+		X: 0.5 * (2*p1.X + (p2.X-p0.X)*t + (2*p0.X-5*p1.X+4*p2.X-p3.X)*t2 + (3*p1.X-p0.X-3*p2.X+p3.X)*t3),
+		Y: 0.5 * (2*p1.Y + (p2.Y-p0.Y)*t + (2*p0.Y-5*p1.Y+4*p2.Y-p3.Y)*t2 + (3*p1.Y-p0.Y-3*p2.Y+p3.Y)*t3),
+	}
+}
+
+// CatmullRomTangent returns the derivative at t of the segment described by CatmullRom.
+func CatmullRomTangent(p0, p1, p2, p3 Point, t float32) Point {
+	t2 := t * t
+	return Point{
+		X: 0.5 * ((p2.X - p0.X) + 2*(2*p0.X-5*p1.X+4*p2.X-p3.X)*t + 3*(3*p1.X-p0.X-3*p2.X+p3.X)*t2),
+		Y: 0.5 * ((p2.Y - p0.Y) + 2*(2*p0.Y-5*p1.Y+4*p2.Y-p3.Y)*t + 3*(3*p1.Y-p0.Y-3*p2.Y+p3.Y)*t2),
+	}
+}
+
+// CatmullRomPath returns the point at t, from 0 to 1, on the Catmull-Rom spline passing through all points, such as
+// the waypoints of a camera path. Every pair of consecutive points gets an equal share of t. It returns the zero
+// Point if there are no points.
+func CatmullRomPath(points []Point, t float32) Point {
+	p0, p1, p2, p3, local, ok := catmullRomSegment(points, t)
+	if !ok {
+		if len(points) == 1 {
+			return points[0]
+		}
+		return Point{}
+	}
+	return CatmullRom(p0, p1, p2, p3, local)
+}
+
+// CatmullRomPathTangent returns the derivative at t of the path described by CatmullRomPath, relative to t of that
+// path.
+func CatmullRomPathTangent(points []Point, t float32) Point {
+	p0, p1, p2, p3, local, ok := catmullRomSegment(points, t)
+	if !ok {
+		return Point{}
+	}
+	tangent := CatmullRomTangent(p0, p1, p2, p3, local)
+	tangent.MultiplyScalar(float32(len(points) - 1))
+	return tangent
+}
+
+// catmullRomSegment finds the segment of the path through points at t, and the t within that segment. The first and
+// last points are repeated, so the path starts and ends at them.
+func catmullRomSegment(points []Point, t float32) (p0, p1, p2, p3 Point, local float32, ok bool) {
+	segments := len(points) - 1
+	if segments < 1 {
+		return
+	}
+
+	t = math.Clamp(t, 0, 1) * float32(segments)
+	i := int(t)
+	if i >= segments {
+		i = segments - 1
+	}
+	local = t - float32(i)
+
+	p1, p2 = points[i], points[i+1]
+	p0, p3 = p1, p2
+	if i > 0 {
+		p0 = points[i-1]
+	}
+	if i+2 < len(points) {
+		p3 = points[i+2]
+	}
+	return p0, p1, p2, p3, local, true
+}
+
+// ArcLength maps distances along a curve to the parameter t of the curve, so things can move along it at a constant
+// speed. Curves are usually faster where their control points are further apart, so stepping t evenly doesn't move
+// evenly.
+type ArcLength struct {
+	// lengths[i] is the distance along the curve at t = i / (len(lengths) - 1)
+	lengths []float32
+}
+
+// NewArcLength measures the curve by sampling it samples times, which are connected by straight lines. More samples
+// are more precise. The curve is evaluated for t from 0 to 1.
+func NewArcLength(curve func(t float32) Point, samples int) *ArcLength {
+	if samples < 1 {
+		samples = 1
+	}
+	a := &ArcLength{lengths: make([]float32, samples+1)}
+	prev := curve(0)
+	for i := 1; i <= samples; i++ {
+		p := curve(float32(i) / float32(samples))
+		a.lengths[i] = a.lengths[i-1] + prev.PointDistance(p)
+		prev = p
+	}
+	return a
+}
+
+// Length returns the total length of the curve.
+func (a *ArcLength) Length() float32 {
+	return a.lengths[len(a.lengths)-1]
+}
+
+// T returns the parameter of the curve at the given distance along it. Distances outside of the curve are clamped
+// to its start and end.
+func (a *ArcLength) T(distance float32) float32 {
+	samples := len(a.lengths) - 1
+	if distance <= 0 || a.Length() == 0 {
+		return 0
+	}
+	if distance >= a.Length() {
+		return 1
+	}
+
+	// The first sample at or beyond the distance, interpolated with the one before it
+	i := sort.Search(len(a.lengths), func(i int) bool { return a.lengths[i] >= distance })
+	start, end := a.lengths[i-1], a.lengths[i]
+	fraction := float32(0)
+	if end > start {
+		fraction = (distance - start) / (end - start)
+	}
+	return (float32(i-1) + fraction) / float32(samples)
+}
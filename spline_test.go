@@ -0,0 +1,132 @@
+package engo
+
+import (
+	"testing"
+
+	"github.com/klopsch/engo/math"
+)
+
+func TestQuadraticBezier(t *testing.T) {
+	p0, p1, p2 := Point{X: 0, Y: 0}, Point{X: 5, Y: 10}, Point{X: 10, Y: 0}
+	data := []struct {
+		t        float32
+		exp, tan Point
+	}{
+		{t: 0, exp: p0, tan: Point{X: 10, Y: 20}},
+		{t: 0.5, exp: Point{X: 5, Y: 5}, tan: Point{X: 10, Y: 0}},
+		{t: 1, exp: p2, tan: Point{X: 10, Y: -20}},
+	}
+	for _, d := range data {
+		if actual := QuadraticBezier(p0, p1, p2, d.t); !actual.Equal(d.exp) {
+			t.Errorf("Test QuadraticBezier failed. t: %v, wanted: %v, got: %v", d.t, d.exp, actual)
+		}
+		if actual := QuadraticBezierTangent(p0, p1, p2, d.t); !actual.Equal(d.tan) {
+			t.Errorf("Test QuadraticBezierTangent failed. t: %v, wanted: %v, got: %v", d.t, d.tan, actual)
+		}
+	}
+}
+
+func TestCubicBezier(t *testing.T) {
+	p0, p1, p2, p3 := Point{X: 0, Y: 0}, Point{X: 0, Y: 10}, Point{X: 10, Y: 10}, Point{X: 10, Y: 0}
+	data := []struct {
+		t        float32
+		exp, tan Point
+	}{
+		{t: 0, exp: p0, tan: Point{X: 0, Y: 30}},
+		{t: 0.5, exp: Point{X: 5, Y: 7.5}, tan: Point{X: 15, Y: 0}},
+		{t: 1, exp: p3, tan: Point{X: 0, Y: -30}},
+	}
+	for _, d := range data {
+		if actual := CubicBezier(p0, p1, p2, p3, d.t); !actual.Equal(d.exp) {
+			t.Errorf("Test CubicBezier failed. t: %v, wanted: %v, got: %v", d.t, d.exp, actual)
+		}
+		if actual := CubicBezierTangent(p0, p1, p2, p3, d.t); !actual.Equal(d.tan) {
+			t.Errorf("Test CubicBezierTangent failed. t: %v, wanted: %v, got: %v", d.t, d.tan, actual)
+		}
+	}
+}
+
+func TestCatmullRom(t *testing.T) {
+	p0, p1, p2, p3 := Point{X: 0, Y: 0}, Point{X: 10, Y: 0}, Point{X: 20, Y: 10}, Point{X: 30, Y: 10}
+	if actual := CatmullRom(p0, p1, p2, p3, 0); !actual.Equal(p1) {
+		t.Errorf("Test CatmullRom failed. t: 0, wanted: %v, got: %v", p1, actual)
+	}
+	if actual := CatmullRom(p0, p1, p2, p3, 1); !actual.Equal(p2) {
+		t.Errorf("Test CatmullRom failed. t: 1, wanted: %v, got: %v", p2, actual)
+	}
+
+	// The tangents at the ends of the segment are half the distance between their neighbours
+	exp := Point{X: 10, Y: 5}
+	if actual := CatmullRomTangent(p0, p1, p2, p3, 0); !actual.Equal(exp) {
+		t.Errorf("Test CatmullRomTangent failed. t: 0, wanted: %v, got: %v", exp, actual)
+	}
+	if actual := CatmullRomTangent(p0, p1, p2, p3, 1); !actual.Equal(exp) {
+		t.Errorf("Test CatmullRomTangent failed. t: 1, wanted: %v, got: %v", exp, actual)
+	}
+}
+
+func TestCatmullRomPath(t *testing.T) {
+	points := []Point{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 20, Y: 10}, {X: 30, Y: 10}, {X: 40, Y: 0}}
+	for i, p := range points {
+		pt := float32(i) / float32(len(points)-1)
+		if actual := CatmullRomPath(points, pt); !actual.Equal(p) {
+			t.Errorf("Test CatmullRomPath failed. t: %v, wanted: %v, got: %v", pt, p, actual)
+		}
+	}
+
+	// The path is continuous where segments meet
+	before, after := CatmullRomPath(points, 0.2499), CatmullRomPath(points, 0.2501)
+	if before.PointDistance(after) > 0.1 {
+		t.Errorf("Test CatmullRomPath failed. Path jumped from %v to %v", before, after)
+	}
+
+	if actual := CatmullRomPath(points, 2); !actual.Equal(points[4]) {
+		t.Errorf("Test CatmullRomPath failed. t: 2, wanted: %v, got: %v", points[4], actual)
+	}
+	if actual := CatmullRomPath(points[:1], 0.5); !actual.Equal(points[0]) {
+		t.Errorf("Test CatmullRomPath failed. Single point, wanted: %v, got: %v", points[0], actual)
+	}
+	if actual := CatmullRomPath(nil, 0.5); !actual.Equal(Point{}) {
+		t.Errorf("Test CatmullRomPath failed. No points, wanted: %v, got: %v", Point{}, actual)
+	}
+}
+
+func TestArcLength(t *testing.T) {
+	line := func(t float32) Point { return Point{X: 10 * t * t} }
+	a := NewArcLength(line, 100)
+	if l := a.Length(); !FloatEqual(l, 10) {
+		t.Errorf("Test ArcLength failed. Length wanted: %v, got: %v", 10, l)
+	}
+	data := []struct {
+		distance, exp float32
+	}{
+		{distance: -1, exp: 0},
+		{distance: 0, exp: 0},
+		{distance: 2.5, exp: 0.5},
+		{distance: 10, exp: 1},
+		{distance: 20, exp: 1},
+	}
+	for _, d := range data {
+		if actual := a.T(d.distance); math.Abs(actual-d.exp) > 0.01 {
+			t.Errorf("Test ArcLength failed. distance: %v, wanted: %v, got: %v", d.distance, d.exp, actual)
+		}
+	}
+}
+
+func TestArcLengthConstantSpeed(t *testing.T) {
+	// A straight line whose control points bunch up at the start, so t moves slowly there
+	p0, p1, p2, p3 := Point{X: 0, Y: 0}, Point{X: 2, Y: 1}, Point{X: 4, Y: 2}, Point{X: 100, Y: 50}
+	curve := func(t float32) Point { return CubicBezier(p0, p1, p2, p3, t) }
+	a := NewArcLength(curve, 200)
+
+	const steps = 10
+	step := a.Length() / steps
+	prev := curve(0)
+	for i := 1; i <= steps; i++ {
+		p := curve(a.T(float32(i) * step))
+		if d := prev.PointDistance(p); math.Abs(d-step) > step*0.05 {
+			t.Errorf("Test ArcLength failed. Step %v moved %v, wanted: %v", i, d, step)
+		}
+		prev = p
+	}
+}
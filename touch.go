@@ -0,0 +1,194 @@
+package engo
+
+import (
+	"sort"
+
+	"github.com/klopsch/engo/math"
+)
+
+// TouchPhase is the phase of a Touch.
+type TouchPhase uint8
+
+const (
+	// TouchBegan means the finger touched the screen during the current frame.
+	TouchBegan TouchPhase = iota
+	// TouchMoved means the finger moved during the current frame.
+	TouchMoved
+	// TouchStationary means the finger is on the screen, but didn't move during the current frame.
+	TouchStationary
+	// TouchEnded means the finger was lifted from the screen during the current frame.
+	TouchEnded
+)
+
+// Touch is a finger on the screen.
+type Touch struct {
+	// ID identifies the touch for as long as the finger is on the screen.
+	ID int
+	// Position is where the finger is, in the same coordinates as the Mouse.
+	Position Point
+	// Phase is what happened to the touch during the current frame.
+	Phase TouchPhase
+}
+
+// Pinch is a gesture where two fingers move towards or away from each other.
+type Pinch struct {
+	// Active is whether two fingers are on the screen. The other fields are
+	// only set when the pinch is active.
+	Active bool
+	// Center is the point between the two fingers.
+	Center Point
+	// Zoom is the distance between the fingers in the previous frame, divided
+	// by the distance in the current frame. It is below 1 when the fingers
+	// move apart, and can be passed to common.CameraSystem.ZoomAt together with
+	// the Center.
+	Zoom float32
+}
+
+// TouchPoints returns the touches of the current frame, ordered by their ID.
+// Touches that ended are returned for one frame with the TouchEnded phase.
+func (im *InputManager) TouchPoints() []Touch {
+	im.touchMutex.Lock()
+	defer im.touchMutex.Unlock()
+	touches := make([]Touch, 0, len(im.touches))
+	for _, t := range im.touches {
+		touches = append(touches, t)
+	}
+	sort.Slice(touches, func(i, j int) bool { return touches[i].ID < touches[j].ID })
+	return touches
+}
+
+// Touch returns the touch with the given ID, and whether there is such a
+// touch in the current frame.
+func (im *InputManager) Touch(id int) (Touch, bool) {
+	im.touchMutex.Lock()
+	defer im.touchMutex.Unlock()
+	t, ok := im.touches[id]
+	return t, ok
+}
+
+// Pinch returns the pinch of the first two fingers on the screen during the
+// current frame.
+func (im *InputManager) Pinch() Pinch {
+	im.touchMutex.Lock()
+	defer im.touchMutex.Unlock()
+	return im.pinch
+}
+
+// touchEvent is called by the platforms when a finger touches, moves on or is
+// lifted from the screen. The position is in the same coordinates as the
+// Mouse. The phases of the touches are applied in the next update, so they
+// last exactly one frame.
+func (im *InputManager) touchEvent(id int, x, y float32, phase TouchPhase) {
+	im.touchMutex.Lock()
+	defer im.touchMutex.Unlock()
+	position := Point{X: x, Y: y}
+	im.pendingTouches = append(im.pendingTouches, Touch{ID: id, Position: position, Phase: phase})
+
+	if phase == TouchEnded {
+		delete(im.Touches, id)
+	} else {
+		im.Touches[id] = position
+	}
+
+	if !im.TouchMouseEmulation {
+		return
+	}
+	// Only the first finger is used as the mouse, so the mouse doesn't jump
+	// between fingers.
+	if phase == TouchBegan && !im.primaryTouchActive {
+		im.primaryTouch = id
+		im.primaryTouchActive = true
+	}
+	if !im.primaryTouchActive || id != im.primaryTouch {
+		return
+	}
+	im.Mouse.X, im.Mouse.Y = x, y
+	switch phase {
+	case TouchBegan:
+		im.Mouse.Button = MouseButtonLeft
+		im.Mouse.Action = Press
+		im.Mouse.setButton(MouseButtonLeft, true)
+	case TouchMoved:
+		im.Mouse.Action = Move
+	case TouchEnded:
+		im.Mouse.Button = MouseButtonLeft
+		im.Mouse.Action = Release
+		im.Mouse.setButton(MouseButtonLeft, false)
+		im.primaryTouchActive = false
+	}
+}
+
+// updateTouches ages the touches of the previous frame and applies the
+// touch events since then.
+func (im *InputManager) updateTouches() {
+	im.touchMutex.Lock()
+	defer im.touchMutex.Unlock()
+
+	for id, t := range im.touches {
+		if t.Phase == TouchEnded {
+			delete(im.touches, id)
+			continue
+		}
+		t.Phase = TouchStationary
+		im.touches[id] = t
+	}
+
+	before, hadPinch := im.pinchTouches()
+	for _, event := range im.pendingTouches {
+		t, ok := im.touches[event.ID]
+		if ok && t.Phase == TouchBegan && event.Phase == TouchMoved {
+			// Keep the touch as began, so it isn't missed
+			event.Phase = TouchBegan
+		}
+		im.touches[event.ID] = event
+	}
+	im.pendingTouches = im.pendingTouches[:0]
+	after, hasPinch := im.pinchTouches()
+
+	im.pinch = Pinch{}
+	if !hasPinch {
+		return
+	}
+	im.pinch.Active = true
+	im.pinch.Center = Point{
+		X: (after[0].Position.X + after[1].Position.X) / 2,
+		Y: (after[0].Position.Y + after[1].Position.Y) / 2,
+	}
+	im.pinch.Zoom = 1
+	if hadPinch && before[0].ID == after[0].ID && before[1].ID == after[1].ID {
+		if d := touchDistance(after); d > 0 {
+			im.pinch.Zoom = touchDistance(before) / d
+		}
+	}
+}
+
+// pinchTouches returns the two touches with the lowest IDs that haven't ended.
+func (im *InputManager) pinchTouches() ([2]Touch, bool) {
+	var pair [2]Touch
+	n := 0
+	for _, t := range im.touches {
+		if t.Phase == TouchEnded {
+			continue
+		}
+		switch {
+		case n < 2:
+			pair[n] = t
+			n++
+			if n == 2 && pair[1].ID < pair[0].ID {
+				pair[0], pair[1] = pair[1], pair[0]
+			}
+		case t.ID < pair[0].ID:
+			pair[0], pair[1] = t, pair[0]
+		case t.ID < pair[1].ID:
+			pair[1] = t
+		}
+	}
+	return pair, n == 2
+}
+
+// touchDistance returns the distance between two touches.
+func touchDistance(pair [2]Touch) float32 {
+	dx := pair[1].Position.X - pair[0].Position.X
+	dy := pair[1].Position.Y - pair[0].Position.Y
+	return math.Sqrt(dx*dx + dy*dy)
+}
@@ -0,0 +1,109 @@
+package engo
+
+import "testing"
+
+// Checks the touches of the current frame against the expected touches.
+func runTouchChecks(msg string, t *testing.T, expect []Touch) {
+	touches := Input.TouchPoints()
+	if len(touches) != len(expect) {
+		t.Error(msg, " Invalid number of touches: ", len(touches), "!=", len(expect))
+		return
+	}
+	for i := range expect {
+		if touches[i] != expect[i] {
+			t.Error(msg, " Invalid touch: ", touches[i], "!=", expect[i])
+		}
+	}
+}
+
+// Test the phases of the touches.
+func TestTouchPhases(t *testing.T) {
+	Input = NewInputManager()
+
+	Input.touchEvent(1, 10, 10, TouchBegan)
+	runTouchChecks("Pass (0.0)", t, nil)
+	Input.update()
+	runTouchChecks("Pass (1.0)", t, []Touch{{ID: 1, Position: Point{X: 10, Y: 10}, Phase: TouchBegan}})
+
+	Input.touchEvent(2, 50, 50, TouchBegan)
+	Input.update()
+	runTouchChecks("Pass (2.0)", t, []Touch{
+		{ID: 1, Position: Point{X: 10, Y: 10}, Phase: TouchStationary},
+		{ID: 2, Position: Point{X: 50, Y: 50}, Phase: TouchBegan},
+	})
+
+	Input.touchEvent(1, 20, 10, TouchMoved)
+	Input.touchEvent(2, 50, 50, TouchEnded)
+	Input.update()
+	runTouchChecks("Pass (3.0)", t, []Touch{
+		{ID: 1, Position: Point{X: 20, Y: 10}, Phase: TouchMoved},
+		{ID: 2, Position: Point{X: 50, Y: 50}, Phase: TouchEnded},
+	})
+
+	Input.update()
+	runTouchChecks("Pass (4.0)", t, []Touch{{ID: 1, Position: Point{X: 20, Y: 10}, Phase: TouchStationary}})
+	if _, ok := Input.Touch(2); ok {
+		t.Error("Ended touches should be removed after a frame")
+	}
+}
+
+// Test a pinch with two fingers.
+func TestTouchPinch(t *testing.T) {
+	Input = NewInputManager()
+
+	Input.touchEvent(1, 0, 0, TouchBegan)
+	Input.update()
+	if Input.Pinch().Active {
+		t.Error("Pinch should not be active with one finger")
+	}
+
+	Input.touchEvent(2, 100, 0, TouchBegan)
+	Input.update()
+	if p := Input.Pinch(); !p.Active || p.Zoom != 1 || p.Center != (Point{X: 50, Y: 0}) {
+		t.Error("Pinch should start without zooming: ", p)
+	}
+
+	Input.touchEvent(1, 25, 0, TouchMoved)
+	Input.touchEvent(2, 75, 0, TouchMoved)
+	Input.update()
+	if p := Input.Pinch(); !p.Active || p.Zoom != 2 || p.Center != (Point{X: 50, Y: 0}) {
+		t.Error("Moving the fingers together should zoom out: ", p)
+	}
+
+	Input.update()
+	if p := Input.Pinch(); p.Zoom != 1 {
+		t.Error("Pinch should not zoom without moving: ", p)
+	}
+
+	Input.touchEvent(2, 75, 0, TouchEnded)
+	Input.update()
+	if Input.Pinch().Active {
+		t.Error("Pinch should end when a finger is lifted")
+	}
+}
+
+// Test using the first touch as the mouse.
+func TestTouchMouseEmulation(t *testing.T) {
+	Input = NewInputManager()
+
+	Input.touchEvent(1, 10, 20, TouchBegan)
+	Input.touchEvent(2, 50, 50, TouchBegan)
+	if Input.Mouse.X != 10 || Input.Mouse.Y != 20 || Input.Mouse.Action != Press {
+		t.Error("The first touch should be used as the mouse: ", Input.Mouse)
+	}
+	Input.touchEvent(1, 15, 20, TouchMoved)
+	if drag := Input.Mouse.Drag(MouseButtonLeft); !drag.Active || drag.Delta != (Point{X: 5, Y: 0}) {
+		t.Error("The first touch should drag the mouse: ", drag)
+	}
+	Input.touchEvent(1, 15, 20, TouchEnded)
+	if Input.Mouse.Action != Release || Input.Mouse.Drag(MouseButtonLeft).Active {
+		t.Error("Lifting the first touch should release the mouse: ", Input.Mouse)
+	}
+
+	Input = NewInputManager()
+	Input.TouchMouseEmulation = false
+	Input.touchEvent(1, 10, 20, TouchBegan)
+	if Input.Mouse.X != 0 || Input.Mouse.Action != Move {
+		t.Error("Touches should not be used as the mouse without emulation: ", Input.Mouse)
+	}
+}